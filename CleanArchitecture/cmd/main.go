@@ -45,9 +45,14 @@ func main() {
 	fmt.Println("✅ Dependencies initialized successfully")
 	
 	// ШАГ 3: Настраиваем graceful shutdown
-	_, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 	
+	// Запускаем сохранение статистики кликов в фоне
+	if err := container.StartBackgroundJobs(ctx); err != nil {
+		log.Fatalf("❌ Failed to start click tracking: %v", err)
+	}
+	
 	// Канал для получения сигналов ОС
 	signalChan := make(chan os.Signal, 1)
 	signal.Notify(signalChan, os.Interrupt, syscall.SIGTERM)
@@ -87,6 +92,13 @@ func main() {
 			fmt.Println("✅ HTTP server stopped gracefully")
 		}
 		
+		// Дожидаемся сохранения кликов из очереди событий
+		if err := container.Shutdown(shutdownCtx); err != nil {
+			log.Printf("⚠️ Error during click events drain: %v", err)
+		} else {
+			fmt.Println("✅ Click events drained")
+		}
+		
 		// Отменяем контекст приложения
 		cancel()
 	}
@@ -108,12 +120,14 @@ func main() {
 //    - Подключается к базе данных
 //    - Выполняет миграции
 //    - Создает все репозитории
+//    - Создает шину событий кликов (память или Redis Streams)
 //    - Инициализирует Use Cases
 //    - Настраивает контроллеры и middleware
 //    - Создает HTTP сервер
-// 3. Запускается HTTP сервер
+// 3. Запускается фоновое сохранение кликов и HTTP сервер
 // 4. Приложение ждет сигналов завершения
-// 5. При получении сигнала выполняется graceful shutdown
+// 5. При получении сигнала выполняется graceful shutdown:
+//    сервер перестает принимать запросы, очередь кликов дочитывается в базу
 
 // АРХИТЕКТУРНЫЕ ПРЕИМУЩЕСТВА:
 // 1. Все зависимости явно объявлены
@@ -9,10 +9,16 @@ import (
 
 // Config содержит всю конфигурацию приложения
 type Config struct {
-	Database DatabaseConfig `json:"database"`
-	Server   ServerConfig   `json:"server"`
-	Auth     AuthConfig     `json:"auth"`
-	App      AppConfig      `json:"app"`
+	Database  DatabaseConfig  `json:"database"`
+	Server    ServerConfig    `json:"server"`
+	Auth      AuthConfig      `json:"auth"`
+	App       AppConfig       `json:"app"`
+	Events    EventsConfig    `json:"events"`
+	GeoIP     GeoIPConfig     `json:"geoip"`
+	Links     LinksConfig     `json:"links"`
+	RateLimit RateLimitConfig `json:"rate_limit"`
+	Safety    SafetyConfig    `json:"safety"`
+	QR        QRConfig        `json:"qr"`
 }
 
 // DatabaseConfig конфигурация базы данных
@@ -40,6 +46,10 @@ type AuthConfig struct {
 	JWTIssuer    string        `json:"jwt_issuer"`
 	JWTExpiry    time.Duration `json:"jwt_expiry"`
 	BcryptCost   int           `json:"bcrypt_cost"`
+
+	RefreshExpiry         time.Duration `json:"refresh_expiry"`          // время жизни refresh токена (сессии без активности)
+	DenylistRefresh       time.Duration `json:"denylist_refresh"`        // как часто перечитываются отозванные токены
+	TokensCleanupInterval time.Duration `json:"tokens_cleanup_interval"` // как часто удаляются истекшие refresh токены
 }
 
 // AppConfig общие настройки приложения
@@ -49,6 +59,66 @@ type AppConfig struct {
 	LogLevel    string `json:"log_level"`   // debug, info, warn, error
 }
 
+// EventsConfig конфигурация шины событий кликов
+type EventsConfig struct {
+	Driver        string        `json:"driver"`         // memory, redis
+	BufferSize    int           `json:"buffer_size"`    // емкость буфера публикации
+	BatchSize     int           `json:"batch_size"`     // событий в одной транзакции
+	FlushInterval time.Duration `json:"flush_interval"` // сохранение неполной пачки
+	MaxAttempts   int           `json:"max_attempts"`   // попыток до dead-letter
+	RetryDelay    time.Duration `json:"retry_delay"`
+	DrainTimeout  time.Duration `json:"drain_timeout"` // ожидание очереди при остановке
+	RedisAddr     string        `json:"redis_addr"`
+	RedisPassword string        `json:"redis_password"`
+	RedisStream   string        `json:"redis_stream"`
+	RedisGroup    string        `json:"redis_group"`
+}
+
+// GeoIPConfig конфигурация геолокации кликов
+type GeoIPConfig struct {
+	DBPath         string        `json:"db_path"`         // файл .mmdb; пусто - геолокация выключена
+	CacheSize      int           `json:"cache_size"`      // IP адресов в LRU кеше
+	ReloadInterval time.Duration `json:"reload_interval"` // проверка обновления файла
+	Language       string        `json:"language"`        // язык названий городов
+	AnonymizeIP    bool          `json:"anonymize_ip"`    // хранить только усеченный IP
+}
+
+// LinksConfig настройки жизненного цикла ссылок
+type LinksConfig struct {
+	CleanupInterval  time.Duration `json:"cleanup_interval"`  // как часто удаляются истекшие ссылки
+	ExpiredRetention time.Duration `json:"expired_retention"` // сколько истекшая ссылка отвечает 410 Gone
+}
+
+// RateLimitConfig лимиты частоты запросов
+// Лимиты задаются как "запросов/период", например "20/1m"; "off" - без лимита
+type RateLimitConfig struct {
+	Driver      string `json:"driver"`       // memory, redis (общие лимиты для всех экземпляров)
+	TrustProxy  bool   `json:"trust_proxy"`  // брать IP клиента из заголовков прокси
+	CreateLink  string `json:"create_link"`  // создание ссылки, на пользователя
+	BatchCreate string `json:"batch_create"` // пакетное создание, на пользователя
+	Redirect    string `json:"redirect"`     // переходы по ссылкам, на IP
+	QR          string `json:"qr"`           // генерация QR кодов, на пользователя
+}
+
+// SafetyConfig проверка адресов назначения по локальным спискам блокировки
+type SafetyConfig struct {
+	DomainListPath  string        `json:"domain_list_path"`  // домены, по одному в строке
+	HashPrefixPath  string        `json:"hash_prefix_path"`  // hex префиксы SHA-256 в стиле Safe Browsing
+	RulesPath       string        `json:"rules_path"`        // "block|flag <regexp>"
+	OwnHosts        string        `json:"own_hosts"`         // свои хосты через запятую, кроме BASE_URL
+	ReloadInterval  time.Duration `json:"reload_interval"`   // проверка изменения файлов
+	FollowRedirects bool          `json:"follow_redirects"`  // проверять цепочку редиректов
+	MaxRedirects    int           `json:"max_redirects"`     // больше - ссылка подозрительна
+	RedirectTimeout time.Duration `json:"redirect_timeout"`  // на всю цепочку
+	ScanInterval    time.Duration `json:"scan_interval"`     // перепроверка существующих ссылок
+}
+
+// QRConfig отрисовка QR кодов коротких ссылок
+type QRConfig struct {
+	LogoPath  string `json:"logo_path"`  // PNG или JPEG логотип для ?logo=true; пусто - без логотипа
+	CacheSize int    `json:"cache_size"` // готовых изображений в LRU кеше
+}
+
 // LoadConfig загружает конфигурацию из переменных окружения
 func LoadConfig() (*Config, error) {
 	config := &Config{
@@ -70,14 +140,65 @@ func LoadConfig() (*Config, error) {
 		Auth: AuthConfig{
 			JWTSecret:  getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 			JWTIssuer:  getEnv("JWT_ISSUER", "url-shortener"),
-			JWTExpiry:  getEnvDuration("JWT_EXPIRY", 24*time.Hour),
+			JWTExpiry:  getEnvDuration("JWT_EXPIRY", 15*time.Minute),
 			BcryptCost: getEnvInt("BCRYPT_COST", 12),
+
+			RefreshExpiry:         getEnvDuration("REFRESH_TOKEN_EXPIRY", 30*24*time.Hour),
+			DenylistRefresh:       getEnvDuration("TOKEN_DENYLIST_REFRESH", 10*time.Second),
+			TokensCleanupInterval: getEnvDuration("TOKENS_CLEANUP_INTERVAL", time.Hour),
 		},
 		App: AppConfig{
 			BaseURL:     getEnv("BASE_URL", "http://localhost:8080"),
 			Environment: getEnv("ENVIRONMENT", "development"),
 			LogLevel:    getEnv("LOG_LEVEL", "info"),
 		},
+		Events: EventsConfig{
+			Driver:        getEnv("EVENTS_DRIVER", "memory"),
+			BufferSize:    getEnvInt("EVENTS_BUFFER_SIZE", 10000),
+			BatchSize:     getEnvInt("EVENTS_BATCH_SIZE", 100),
+			FlushInterval: getEnvDuration("EVENTS_FLUSH_INTERVAL", time.Second),
+			MaxAttempts:   getEnvInt("EVENTS_MAX_ATTEMPTS", 5),
+			RetryDelay:    getEnvDuration("EVENTS_RETRY_DELAY", time.Second),
+			DrainTimeout:  getEnvDuration("EVENTS_DRAIN_TIMEOUT", 10*time.Second),
+			RedisAddr:     getEnv("REDIS_ADDR", "localhost:6379"),
+			RedisPassword: getEnv("REDIS_PASSWORD", ""),
+			RedisStream:   getEnv("EVENTS_REDIS_STREAM", "link_clicks"),
+			RedisGroup:    getEnv("EVENTS_REDIS_GROUP", "stats"),
+		},
+		GeoIP: GeoIPConfig{
+			DBPath:         getEnv("GEOIP_DB_PATH", ""),
+			CacheSize:      getEnvInt("GEOIP_CACHE_SIZE", 10000),
+			ReloadInterval: getEnvDuration("GEOIP_RELOAD_INTERVAL", time.Minute),
+			Language:       getEnv("GEOIP_LANGUAGE", "en"),
+			AnonymizeIP:    getEnvBool("PRIVACY_ANONYMIZE_IP", false),
+		},
+		Links: LinksConfig{
+			CleanupInterval:  getEnvDuration("LINKS_CLEANUP_INTERVAL", time.Hour),
+			ExpiredRetention: getEnvDuration("LINKS_EXPIRED_RETENTION", 7*24*time.Hour),
+		},
+		RateLimit: RateLimitConfig{
+			Driver:      getEnv("RATE_LIMIT_DRIVER", "memory"),
+			TrustProxy:  getEnvBool("RATE_LIMIT_TRUST_PROXY", false),
+			CreateLink:  getEnv("RATE_LIMIT_CREATE_LINK", "30/1m"),
+			BatchCreate: getEnv("RATE_LIMIT_BATCH_CREATE", "5/1m"),
+			Redirect:    getEnv("RATE_LIMIT_REDIRECT", "600/1m"),
+			QR:          getEnv("RATE_LIMIT_QR", "60/1m"),
+		},
+		Safety: SafetyConfig{
+			DomainListPath:  getEnv("SAFETY_DOMAIN_LIST", ""),
+			HashPrefixPath:  getEnv("SAFETY_HASH_PREFIXES", ""),
+			RulesPath:       getEnv("SAFETY_RULES", ""),
+			OwnHosts:        getEnv("SAFETY_OWN_HOSTS", ""),
+			ReloadInterval:  getEnvDuration("SAFETY_RELOAD_INTERVAL", time.Minute),
+			FollowRedirects: getEnvBool("SAFETY_FOLLOW_REDIRECTS", true),
+			MaxRedirects:    getEnvInt("SAFETY_MAX_REDIRECTS", 5),
+			RedirectTimeout: getEnvDuration("SAFETY_REDIRECT_TIMEOUT", 5*time.Second),
+			ScanInterval:    getEnvDuration("SAFETY_SCAN_INTERVAL", 24*time.Hour),
+		},
+		QR: QRConfig{
+			LogoPath:  getEnv("QR_LOGO_PATH", ""),
+			CacheSize: getEnvInt("QR_CACHE_SIZE", 256),
+		},
 	}
 
 	// Валидируем конфигурацию
@@ -114,6 +235,27 @@ func (c *Config) Validate() error {
 	if c.Auth.BcryptCost < 4 || c.Auth.BcryptCost > 31 {
 		return fmt.Errorf("invalid bcrypt cost: %d (must be between 4 and 31)", c.Auth.BcryptCost)
 	}
+	if c.Events.Driver != "memory" && c.Events.Driver != "redis" {
+		return fmt.Errorf("invalid events driver: %q (must be memory or redis)", c.Events.Driver)
+	}
+	if c.Events.BufferSize <= 0 || c.Events.BatchSize <= 0 {
+		return fmt.Errorf("events buffer and batch sizes must be positive")
+	}
+	if c.RateLimit.Driver != "memory" && c.RateLimit.Driver != "redis" {
+		return fmt.Errorf("invalid rate limit driver: %q (must be memory or redis)", c.RateLimit.Driver)
+	}
+	if c.Safety.ScanInterval <= 0 || c.Safety.MaxRedirects <= 0 {
+		return fmt.Errorf("safety scan interval and max redirects must be positive")
+	}
+	if c.Auth.JWTExpiry <= 0 || c.Auth.RefreshExpiry <= c.Auth.JWTExpiry {
+		return fmt.Errorf("JWT expiry must be positive and shorter than refresh token expiry")
+	}
+	if c.Auth.DenylistRefresh <= 0 || c.Auth.TokensCleanupInterval <= 0 {
+		return fmt.Errorf("token denylist refresh and cleanup intervals must be positive")
+	}
+	if c.Links.CleanupInterval <= 0 || c.Links.ExpiredRetention < 0 {
+		return fmt.Errorf("links cleanup interval must be positive and retention non-negative")
+	}
 
 	return nil
 }
@@ -148,6 +290,16 @@ func getEnvInt(key string, defaultValue int) int {
 	return defaultValue
 }
 
+// getEnvBool возвращает логическое значение переменной окружения
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := os.Getenv(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 // getEnvDuration возвращает duration из переменной окружения
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
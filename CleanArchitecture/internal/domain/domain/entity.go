@@ -0,0 +1,145 @@
+package domain
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net"
+	"strings"
+	"time"
+)
+
+// Domain представляет доменную модель пользовательского домена коротких ссылок
+//
+// Ссылки на домене сервиса (BASE_URL) общие для всех пользователей.
+// Пользовательский домен (например, go.brand.com) принадлежит одному
+// пользователю, и короткие коды на нем не пересекаются с кодами других
+// доменов. Домен начинает обслуживать ссылки только после того, как
+// владелец подтвердит его TXT записью в DNS.
+type Domain struct {
+	// ID - уникальный идентификатор домена
+	ID uint
+
+	// UserID - владелец домена
+	UserID uint
+
+	// Host - имя хоста в нижнем регистре без точки в конце (go.brand.com)
+	Host string
+
+	// VerificationToken - случайное значение, которое владелец публикует в DNS
+	VerificationToken string
+
+	// VerifiedAt - когда домен подтвержден (nil - еще не подтвержден)
+	VerifiedAt *time.Time
+
+	// CreatedAt - время добавления домена
+	CreatedAt time.Time
+}
+
+// Параметры TXT записи подтверждения
+const (
+	// VerificationRecordPrefix - поддомен, на котором ищется TXT запись
+	VerificationRecordPrefix = "_shortener-verification"
+
+	// VerificationValuePrefix - начало значения TXT записи
+	VerificationValuePrefix = "shortener-verification="
+)
+
+// Доменные ошибки
+var (
+	ErrInvalidHost = errors.New("invalid domain name")
+)
+
+// NewDomain создает неподтвержденный домен пользователя
+func NewDomain(host string, userID uint) (*Domain, error) {
+	normalized, err := NormalizeHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+
+	return &Domain{
+		UserID:            userID,
+		Host:              normalized,
+		VerificationToken: hex.EncodeToString(raw),
+		CreatedAt:         time.Now(),
+	}, nil
+}
+
+// NormalizeHost приводит имя хоста к виду, в котором оно хранится
+// Допускаются только DNS имена из двух и более меток: без схемы, порта,
+// пути и IP адресов. Интернационализированные имена - в punycode (xn--)
+func NormalizeHost(host string) (string, error) {
+	host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+	if host == "" || len(host) > 253 || net.ParseIP(host) != nil {
+		return "", ErrInvalidHost
+	}
+
+	labels := strings.Split(host, ".")
+	if len(labels) < 2 {
+		return "", ErrInvalidHost
+	}
+	for _, label := range labels {
+		if !isValidLabel(label) {
+			return "", ErrInvalidHost
+		}
+	}
+	return host, nil
+}
+
+// isValidLabel проверяет одну метку DNS имени (RFC 1123)
+func isValidLabel(label string) bool {
+	if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		if !((r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+// VerificationRecordName имя, на котором владелец создает TXT запись
+func (d *Domain) VerificationRecordName() string {
+	return VerificationRecordPrefix + "." + d.Host
+}
+
+// VerificationRecordValue значение TXT записи
+func (d *Domain) VerificationRecordValue() string {
+	return VerificationValuePrefix + d.VerificationToken
+}
+
+// MatchesTXT проверяет, есть ли среди TXT записей ожидаемое значение
+// На имени может быть несколько записей, лишние не мешают
+func (d *Domain) MatchesTXT(records []string) bool {
+	want := d.VerificationRecordValue()
+	for _, record := range records {
+		if strings.TrimSpace(record) == want {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkVerified отмечает домен подтвержденным
+func (d *Domain) MarkVerified(at time.Time) {
+	if d.VerifiedAt == nil {
+		d.VerifiedAt = &at
+	}
+}
+
+// IsVerified проверяет, подтвержден ли домен
+// Неподтвержденный домен не обслуживает ссылки
+func (d *Domain) IsVerified() bool {
+	return d.VerifiedAt != nil
+}
+
+// IsOwner проверяет, принадлежит ли домен указанному пользователю
+func (d *Domain) IsOwner(userID uint) bool {
+	return d.UserID == userID
+}
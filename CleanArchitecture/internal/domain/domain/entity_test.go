@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNormalizeHost(t *testing.T) {
+	tests := []struct {
+		host string
+		want string
+		err  error
+	}{
+		{"go.brand.com", "go.brand.com", nil},
+		{"  Go.Brand.COM.  ", "go.brand.com", nil},
+		{"xn--80ak6aa92e.com", "xn--80ak6aa92e.com", nil},
+		{"localhost", "", ErrInvalidHost},
+		{"https://go.brand.com", "", ErrInvalidHost},
+		{"go.brand.com:8080", "", ErrInvalidHost},
+		{"go.brand.com/path", "", ErrInvalidHost},
+		{"-bad.brand.com", "", ErrInvalidHost},
+		{"bad..com", "", ErrInvalidHost},
+		{"10.0.0.1", "", ErrInvalidHost},
+		{"", "", ErrInvalidHost},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeHost(tt.host)
+		if got != tt.want || err != tt.err {
+			t.Errorf("NormalizeHost(%q) = %q, %v; want %q, %v", tt.host, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestDomainVerification(t *testing.T) {
+	d, err := NewDomain("Go.Brand.com", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Host != "go.brand.com" || d.IsVerified() || len(d.VerificationToken) != 32 {
+		t.Fatalf("NewDomain() = %+v", d)
+	}
+	if name := d.VerificationRecordName(); name != "_shortener-verification.go.brand.com" {
+		t.Errorf("VerificationRecordName() = %q", name)
+	}
+
+	// Значение ищется среди всех записей имени
+	if d.MatchesTXT([]string{"v=spf1 -all", "shortener-verification=wrong"}) {
+		t.Error("MatchesTXT() accepted a wrong token")
+	}
+	if !d.MatchesTXT([]string{"v=spf1 -all", d.VerificationRecordValue()}) {
+		t.Error("MatchesTXT() rejected the expected record")
+	}
+
+	first := time.Now()
+	d.MarkVerified(first)
+	d.MarkVerified(first.Add(time.Hour))
+	if !d.IsVerified() || !d.VerifiedAt.Equal(first) {
+		t.Errorf("VerifiedAt = %v, want %v", d.VerifiedAt, first)
+	}
+}
@@ -0,0 +1,25 @@
+package domain
+
+import "context"
+
+// Repository определяет интерфейс для хранения пользовательских доменов
+type Repository interface {
+	// Save сохраняет домен
+	// Если ID = 0, создает новый домен и устанавливает ID
+	// Если ID != 0, обновляет статус подтверждения
+	Save(ctx context.Context, d *Domain) error
+
+	// FindByID находит домен по ID
+	// Возвращает nil, если домен не найден
+	FindByID(ctx context.Context, id uint) (*Domain, error)
+
+	// FindByHost находит домен по нормализованному имени хоста
+	// Используется при каждом редиректе, поэтому поиск должен быть быстрым
+	FindByHost(ctx context.Context, host string) (*Domain, error)
+
+	// FindByUserID возвращает все домены пользователя по порядку добавления
+	FindByUserID(ctx context.Context, userID uint) ([]*Domain, error)
+
+	// Delete удаляет домен по ID
+	Delete(ctx context.Context, id uint) error
+}
@@ -17,8 +17,12 @@ type Link struct {
 	OriginalURL string
 
 	// ShortCode - короткий код для ссылки (например, "abc123")
+	// Уникален в пределах домена ссылки
 	ShortCode string
 
+	// DomainID - пользовательский домен ссылки (0 - домен сервиса по умолчанию)
+	DomainID uint
+
 	// UserID - ID пользователя, создавшего ссылку
 	UserID uint
 
@@ -30,14 +34,61 @@ type Link struct {
 
 	// ClicksCount - количество переходов по ссылке
 	ClicksCount uint
+
+	// ActivatesAt - время, с которого ссылка начинает работать (nil - сразу)
+	ActivatesAt *time.Time
+
+	// ExpiresAt - время, после которого ссылка перестает работать (nil - бессрочно)
+	ExpiresAt *time.Time
+
+	// MaxClicks - максимальное количество переходов (0 - без ограничения)
+	MaxClicks uint
+
+	// PasswordHash - хеш пароля доступа к ссылке (пусто - без пароля)
+	// Хеширование - задача usecase слоя, домен хранит только результат
+	PasswordHash string
+
+	// SafetyStatus - результат проверки адреса назначения на угрозы
+	SafetyStatus SafetyStatus
+
+	// SafetyReason - какое правило сработало (пусто для SafetyOK)
+	SafetyReason string
+
+	// SafetyUpdatedAt - когда статус безопасности последний раз менялся
+	SafetyUpdatedAt *time.Time
 }
 
+// SafetyStatus статус безопасности адреса назначения ссылки
+type SafetyStatus string
+
+const (
+	// SafetyOK - угроз не найдено
+	SafetyOK SafetyStatus = "ok"
+
+	// SafetyFlagged - адрес подозрительный: ссылка работает, но отмечена для проверки
+	SafetyFlagged SafetyStatus = "flagged"
+
+	// SafetyBlocked - адрес вредоносный: переход по ссылке отключен
+	SafetyBlocked SafetyStatus = "blocked"
+)
+
 // Доменные ошибки
 var (
 	ErrInvalidURL       = errors.New("invalid URL format")
 	ErrEmptyURL         = errors.New("URL cannot be empty")
 	ErrInvalidShortCode = errors.New("invalid short code")
 	ErrEmptyShortCode   = errors.New("short code cannot be empty")
+	ErrInvalidSchedule  = errors.New("link expiration must be in the future and after activation")
+)
+
+// Ошибки доступности ссылки при переходе
+var (
+	ErrLinkNotActive     = errors.New("link is not active yet")
+	ErrLinkExpired       = errors.New("link has expired")
+	ErrClickLimitReached = errors.New("link click limit reached")
+	ErrPasswordRequired  = errors.New("link is password protected")
+	ErrInvalidPassword   = errors.New("invalid link password")
+	ErrLinkBlocked       = errors.New("link has been disabled as unsafe")
 )
 
 // NewLink создает новую ссылку с автоматически сгенерированным коротким кодом
@@ -62,6 +113,8 @@ func NewLink(originalURL string, userID uint) (*Link, error) {
 		CreatedAt:   now,
 		UpdatedAt:   now,
 		ClicksCount: 0,
+
+		SafetyStatus: SafetyOK,
 	}, nil
 }
 
@@ -86,6 +139,8 @@ func NewLinkWithCustomCode(originalURL, shortCode string, userID uint) (*Link, e
 		CreatedAt:   now,
 		UpdatedAt:   now,
 		ClicksCount: 0,
+
+		SafetyStatus: SafetyOK,
 	}, nil
 }
 
@@ -118,6 +173,90 @@ func (l *Link) IncrementClicks() {
 	l.UpdatedAt = time.Now()
 }
 
+// SetSchedule задает период действия ссылки
+// nil в любой из границ означает отсутствие ограничения
+func (l *Link) SetSchedule(activatesAt, expiresAt *time.Time) error {
+	if expiresAt != nil {
+		if !expiresAt.After(time.Now()) {
+			return ErrInvalidSchedule
+		}
+		if activatesAt != nil && !expiresAt.After(*activatesAt) {
+			return ErrInvalidSchedule
+		}
+	}
+
+	l.ActivatesAt = activatesAt
+	l.ExpiresAt = expiresAt
+	l.UpdatedAt = time.Now()
+	return nil
+}
+
+// SetDomain переносит ссылку на пользовательский домен (0 - домен по умолчанию)
+// Владение и подтверждение домена проверяет usecase слой
+func (l *Link) SetDomain(domainID uint) {
+	l.DomainID = domainID
+	l.UpdatedAt = time.Now()
+}
+
+// SetMaxClicks ограничивает количество переходов (0 - без ограничения)
+func (l *Link) SetMaxClicks(maxClicks uint) {
+	l.MaxClicks = maxClicks
+	l.UpdatedAt = time.Now()
+}
+
+// SetPasswordHash защищает ссылку паролем (пустой хеш снимает защиту)
+func (l *Link) SetPasswordHash(passwordHash string) {
+	l.PasswordHash = passwordHash
+	l.UpdatedAt = time.Now()
+}
+
+// IsPasswordProtected проверяет, нужен ли пароль для перехода
+func (l *Link) IsPasswordProtected() bool {
+	return l.PasswordHash != ""
+}
+
+// IsExpired проверяет, истек ли срок действия ссылки к моменту now
+func (l *Link) IsExpired(now time.Time) bool {
+	return l.ExpiresAt != nil && !now.Before(*l.ExpiresAt)
+}
+
+// SetSafety сохраняет результат проверки адреса назначения
+// Возвращает false, если статус и причина не изменились
+func (l *Link) SetSafety(status SafetyStatus, reason string, at time.Time) bool {
+	if l.SafetyStatus == status && l.SafetyReason == reason {
+		return false
+	}
+
+	l.SafetyStatus = status
+	l.SafetyReason = reason
+	l.SafetyUpdatedAt = &at
+	return true
+}
+
+// IsBlocked проверяет, отключена ли ссылка как вредоносная
+func (l *Link) IsBlocked() bool {
+	return l.SafetyStatus == SafetyBlocked
+}
+
+// CheckAvailability проверяет, можно ли перейти по ссылке в момент now
+// Это доменное правило: безопасность, период действия и лимит переходов.
+// Пароль проверяется отдельно - для этого нужен hasher из usecase слоя
+func (l *Link) CheckAvailability(now time.Time) error {
+	if l.IsBlocked() {
+		return ErrLinkBlocked
+	}
+	if l.ActivatesAt != nil && now.Before(*l.ActivatesAt) {
+		return ErrLinkNotActive
+	}
+	if l.IsExpired(now) {
+		return ErrLinkExpired
+	}
+	if l.MaxClicks > 0 && l.ClicksCount >= l.MaxClicks {
+		return ErrClickLimitReached
+	}
+	return nil
+}
+
 // IsOwner проверяет, принадлежит ли ссылка указанному пользователю
 func (l *Link) IsOwner(userID uint) bool {
 	return l.UserID == userID
@@ -0,0 +1,78 @@
+package link
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLinkCheckAvailability(t *testing.T) {
+	now := time.Now()
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	tests := []struct {
+		name string
+		link Link
+		want error
+	}{
+		{"без ограничений", Link{}, nil},
+		{"еще не активна", Link{ActivatesAt: &future}, ErrLinkNotActive},
+		{"уже активна", Link{ActivatesAt: &past, ExpiresAt: &future}, nil},
+		{"истекла", Link{ExpiresAt: &past}, ErrLinkExpired},
+		{"истекает ровно сейчас", Link{ExpiresAt: &now}, ErrLinkExpired},
+		{"лимит не исчерпан", Link{MaxClicks: 3, ClicksCount: 2}, nil},
+		{"лимит исчерпан", Link{MaxClicks: 3, ClicksCount: 3}, ErrClickLimitReached},
+		{"отмечена как подозрительная", Link{SafetyStatus: SafetyFlagged}, nil},
+		{"заблокирована", Link{SafetyStatus: SafetyBlocked, ExpiresAt: &past}, ErrLinkBlocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.link.CheckAvailability(now); got != tt.want {
+				t.Errorf("CheckAvailability() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinkSetSchedule(t *testing.T) {
+	now := time.Now()
+	past, soon, later := now.Add(-time.Hour), now.Add(time.Hour), now.Add(2*time.Hour)
+
+	l := &Link{}
+	if err := l.SetSchedule(&soon, &later); err != nil {
+		t.Fatalf("SetSchedule() error = %v", err)
+	}
+	if l.ActivatesAt != &soon || l.ExpiresAt != &later {
+		t.Error("SetSchedule() did not store bounds")
+	}
+	if err := l.SetSchedule(nil, &past); err != ErrInvalidSchedule {
+		t.Errorf("SetSchedule(expired) error = %v, want %v", err, ErrInvalidSchedule)
+	}
+	if err := l.SetSchedule(&later, &soon); err != ErrInvalidSchedule {
+		t.Errorf("SetSchedule(expires before activation) error = %v, want %v", err, ErrInvalidSchedule)
+	}
+}
+
+func TestLinkSetSafety(t *testing.T) {
+	now := time.Now()
+
+	l, err := NewLink("https://example.com", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.SafetyStatus != SafetyOK {
+		t.Errorf("SafetyStatus = %q, want %q", l.SafetyStatus, SafetyOK)
+	}
+
+	if l.SetSafety(SafetyOK, "", now) {
+		t.Error("SetSafety() with same status reported a change")
+	}
+	if !l.SetSafety(SafetyBlocked, "domain is blocklisted", now) || !l.IsBlocked() {
+		t.Error("SetSafety(blocked) did not block the link")
+	}
+	if l.SafetyUpdatedAt == nil || !l.SafetyUpdatedAt.Equal(now) {
+		t.Errorf("SafetyUpdatedAt = %v, want %v", l.SafetyUpdatedAt, now)
+	}
+	if !l.SetSafety(SafetyOK, "", now) || l.IsBlocked() {
+		t.Error("SetSafety(ok) did not unblock the link")
+	}
+}
@@ -1,6 +1,9 @@
 package link
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository определяет интерфейс для работы со ссылками в базе данных
 type Repository interface {
@@ -12,26 +15,51 @@ type Repository interface {
 	// FindByID находит ссылку по ID
 	FindByID(ctx context.Context, id uint) (*Link, error)
 
-	// FindByShortCode находит ссылку по короткому коду
+	// FindByShortCode находит ссылку по короткому коду на домене domainID
+	// (0 - домен сервиса по умолчанию)
 	// Это основной метод для редиректа по короткой ссылке
-	FindByShortCode(ctx context.Context, shortCode string) (*Link, error)
+	FindByShortCode(ctx context.Context, domainID uint, shortCode string) (*Link, error)
 
 	// FindByUserID находит все ссылки пользователя с пагинацией
+	// Порядок стабилен: сначала новые, при равном времени - по убыванию ID
 	FindByUserID(ctx context.Context, userID uint, limit, offset int) ([]*Link, error)
 
 	// Delete удаляет ссылку по ID
 	Delete(ctx context.Context, id uint) error
 
 	// ExistsByShortCode проверяет существование ссылки с указанным коротким кодом
-	// Используется для проверки уникальности короткого кода
-	ExistsByShortCode(ctx context.Context, shortCode string) (bool, error)
+	// на домене domainID. Используется для проверки уникальности короткого кода:
+	// один и тот же код может быть занят на разных доменах
+	ExistsByShortCode(ctx context.Context, domainID uint, shortCode string) (bool, error)
 
 	// CountByUserID возвращает общее количество ссылок пользователя
 	CountByUserID(ctx context.Context, userID uint) (int, error)
 
+	// CountByDomainID возвращает количество ссылок на пользовательском домене
+	CountByDomainID(ctx context.Context, domainID uint) (int, error)
+
 	// IncrementClicks увеличивает счетчик переходов по ссылке
 	// Это может быть отдельной операцией для оптимизации производительности
+	// Если у ссылки задан MaxClicks и он исчерпан, счетчик не меняется и
+	// возвращается ErrClickLimitReached - проверка и увеличение атомарны
 	IncrementClicks(ctx context.Context, linkID uint) error
+
+	// RunInTx выполняет fn в одной транзакции
+	// Все операции через repo, переданный в fn, атомарны: ошибка fn
+	// откатывает их целиком. Используется для пакетного создания ссылок
+	RunInTx(ctx context.Context, fn func(repo Repository) error) error
+
+	// DeleteExpired удаляет ссылки, срок действия которых истек до before
+	// Возвращает количество удаленных ссылок
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+
+	// FindAfterID возвращает до limit ссылок с ID больше afterID по возрастанию ID
+	// Используется фоновыми задачами, которые обходят все ссылки
+	FindAfterID(ctx context.Context, afterID uint, limit int) ([]*Link, error)
+
+	// UpdateSafety сохраняет статус безопасности ссылки
+	// (SafetyStatus, SafetyReason, SafetyUpdatedAt), не трогая остальные поля
+	UpdateSafety(ctx context.Context, link *Link) error
 }
 
 // ПРИМЕЧАНИЕ: Этот интерфейс находится в domain слое, потому что:
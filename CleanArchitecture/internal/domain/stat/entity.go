@@ -1,7 +1,10 @@
 package stat
 
 import (
+	"errors"
 	"net"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -23,6 +26,10 @@ type Stat struct {
 	// Referer - страница, с которой пришел пользователь
 	Referer string
 
+	// Source - метка канала из адреса ссылки (utm_source), например "qr"
+	// Пусто - переход без метки
+	Source string
+
 	// Country - страна пользователя (определяется по IP)
 	Country string
 
@@ -44,6 +51,32 @@ func NewStat(linkID uint, userAgent, ipAddress, referer string) *Stat {
 	}
 }
 
+// MaxSourceLength максимальная длина метки канала
+const MaxSourceLength = 32
+
+// SetSource устанавливает метку канала перехода
+// Метка приходит из адреса ссылки, то есть от посетителя: в статистику
+// попадают только короткие значения из латиницы, цифр, "-" и "_"
+// в нижнем регистре, остальное сохраняется как переход без метки
+func (s *Stat) SetSource(source string) {
+	s.Source = NormalizeSource(source)
+}
+
+// NormalizeSource приводит метку канала к виду, в котором она хранится
+// Недопустимая метка превращается в пустую строку
+func NormalizeSource(source string) string {
+	source = strings.ToLower(strings.TrimSpace(source))
+	if len(source) > MaxSourceLength {
+		return ""
+	}
+	for _, r := range source {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return ""
+		}
+	}
+	return source
+}
+
 // SetLocation устанавливает географическую информацию
 // Эта информация может быть получена через внешний сервис геолокации
 func (s *Stat) SetLocation(country, city string) {
@@ -65,14 +98,16 @@ func (s *Stat) GetAnonymizedIP() string {
 		return ""
 	}
 
-	if ip.To4() != nil {
+	if ip4 := ip.To4(); ip4 != nil {
 		// IPv4 - заменяем последний октет на 0
-		ip[3] = 0
-	} else {
-		// IPv6 - заменяем последние 80 бит на 0
-		for i := 6; i < 16; i++ {
-			ip[i] = 0
-		}
+		// ParseIP возвращает 16 байт, октеты IPv4 - в последних четырех
+		ip4[3] = 0
+		return ip4.String()
+	}
+
+	// IPv6 - заменяем последние 80 бит на 0
+	for i := 6; i < 16; i++ {
+		ip[i] = 0
 	}
 
 	return ip.String()
@@ -98,6 +133,9 @@ type LinkStatistics struct {
 	// TopReferers - топ источников трафика
 	TopReferers []RefererStat
 
+	// ClicksBySource - переходы по меткам канала (utm_source); без метки не учитываются
+	ClicksBySource map[string]int
+
 	// Period - период, за который собрана статистика
 	Period Period
 }
@@ -147,4 +185,38 @@ func Last30Days() Period {
 		StartDate: now.Add(-30 * 24 * time.Hour),
 		EndDate:   now,
 	}
+}
+
+// ErrInvalidPeriod возвращается при неверной записи периода
+var ErrInvalidPeriod = errors.New("invalid period")
+
+// MaxPeriod самый длинный период, за который отдается статистика
+const MaxPeriod = 365 * 24 * time.Hour
+
+// ParsePeriod разбирает период вида "7d", "30d" или "24h"
+// Период отсчитывается назад от текущего момента
+func ParsePeriod(value string) (Period, error) {
+	value = strings.TrimSpace(value)
+
+	var length time.Duration
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return Period{}, ErrInvalidPeriod
+		}
+		length = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return Period{}, ErrInvalidPeriod
+		}
+		length = d
+	}
+
+	if length <= 0 || length > MaxPeriod {
+		return Period{}, ErrInvalidPeriod
+	}
+
+	now := time.Now()
+	return NewPeriod(now.Add(-length), now), nil
 }
\ No newline at end of file
@@ -0,0 +1,35 @@
+package stat
+
+import "testing"
+
+func TestGetAnonymizedIP(t *testing.T) {
+	tests := map[string]string{
+		"81.2.69.160":                          "81.2.69.0",
+		"2001:db8:85a3:8d3:1319:8a2e:370:7348": "2001:db8:85a3::",
+		"not-an-ip":                            "",
+	}
+	for ip, want := range tests {
+		s := NewStat(1, "", ip, "")
+		if got := s.GetAnonymizedIP(); got != want {
+			t.Errorf("GetAnonymizedIP(%s) = %q, want %q", ip, got, want)
+		}
+	}
+}
+
+func TestNormalizeSource(t *testing.T) {
+	tests := map[string]string{
+		"qr":                                "qr",
+		" QR ":                              "qr",
+		"spring_sale-2024":                  "spring_sale-2024",
+		"":                                  "",
+		"<script>":                          "",
+		"qr code":                           "",
+		"очень":                             "",
+		"a-very-long-campaign-name-over-32": "",
+	}
+	for source, want := range tests {
+		if got := NormalizeSource(source); got != want {
+			t.Errorf("NormalizeSource(%q) = %q, want %q", source, got, want)
+		}
+	}
+}
@@ -1,12 +1,19 @@
 package stat
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // Repository определяет интерфейс для работы со статистикой в базе данных
 type Repository interface {
 	// Save сохраняет запись статистики в базе данных
 	Save(ctx context.Context, stat *Stat) error
 
+	// SaveBatch сохраняет несколько записей одной транзакцией:
+	// либо сохраняются все, либо ни одной
+	SaveBatch(ctx context.Context, stats []*Stat) error
+
 	// FindByLinkID находит все записи статистики для указанной ссылки
 	// с возможностью фильтрации по периоду времени
 	FindByLinkID(ctx context.Context, linkID uint, period Period) ([]*Stat, error)
@@ -35,6 +42,9 @@ type Repository interface {
 	// GetTopReferers возвращает топ источников трафика
 	GetTopReferers(ctx context.Context, linkID uint, period Period, limit int) ([]RefererStat, error)
 
+	// GetClicksBySource возвращает переходы по меткам канала (utm_source)
+	GetClicksBySource(ctx context.Context, linkID uint, period Period) (map[string]int, error)
+
 	// DeleteByLinkID удаляет всю статистику для указанной ссылки
 	// Используется при удалении ссылки
 	DeleteByLinkID(ctx context.Context, linkID uint) error
@@ -48,6 +58,4 @@ type Repository interface {
 // 1. Определения правил работы с данными статистики
 // 2. Использования в Use Case слое
 // 3. Реализации в Infrastructure слое
-// 4. Обеспечения инверсии зависимостей
-
-import "time"
\ No newline at end of file
+// 4. Обеспечения инверсии зависимостей
\ No newline at end of file
@@ -0,0 +1,102 @@
+package token
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"time"
+)
+
+// RefreshToken представляет доменную модель refresh токена
+//
+// Токен одноразовый: при обмене на новую пару токенов он помечается
+// использованным, а новый токен попадает в то же семейство (FamilyID).
+// Семейство - это одна сессия входа. Повторное предъявление
+// использованного токена означает, что он утек, и отзывается все семейство.
+type RefreshToken struct {
+	// ID - уникальный идентификатор записи
+	ID uint
+
+	// UserID - владелец токена
+	UserID uint
+
+	// FamilyID - идентификатор сессии, общий для всех токенов ротации
+	FamilyID string
+
+	// TokenHash - SHA-256 от значения токена (само значение не храним)
+	TokenHash string
+
+	// ExpiresAt - время истечения токена
+	ExpiresAt time.Time
+
+	// CreatedAt - время выпуска токена
+	CreatedAt time.Time
+
+	// UsedAt - когда токен был обменян на новый (nil - еще не использован)
+	UsedAt *time.Time
+
+	// RevokedAt - когда семейство токена было отозвано (nil - действует)
+	RevokedAt *time.Time
+}
+
+// Доменные ошибки
+var (
+	ErrInvalidTTL = errors.New("token lifetime must be positive")
+)
+
+// NewRefreshToken создает токен в семействе familyID
+// Возвращает модель для сохранения и значение токена для клиента:
+// значение больше нигде не хранится
+func NewRefreshToken(userID uint, familyID string, ttl time.Duration) (*RefreshToken, string, error) {
+	if ttl <= 0 {
+		return nil, "", ErrInvalidTTL
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", err
+	}
+	value := base64.RawURLEncoding.EncodeToString(raw)
+
+	now := time.Now()
+	return &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: Hash(value),
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}, value, nil
+}
+
+// NewFamilyID генерирует идентификатор новой сессии
+func NewFamilyID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// Hash вычисляет хеш значения токена для поиска в хранилище
+// У токена 256 бит энтропии, поэтому медленный хеш (bcrypt) не нужен
+func Hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsExpired проверяет, истек ли токен к моменту now
+func (t *RefreshToken) IsExpired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+// IsUsed проверяет, был ли токен уже обменян
+func (t *RefreshToken) IsUsed() bool {
+	return t.UsedAt != nil
+}
+
+// IsRevoked проверяет, отозвано ли семейство токена
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -0,0 +1,27 @@
+package token
+
+import (
+	"context"
+	"time"
+)
+
+// Repository определяет интерфейс для хранения refresh токенов
+type Repository interface {
+	// Save сохраняет новый токен и устанавливает ID
+	Save(ctx context.Context, t *RefreshToken) error
+
+	// FindByHash находит токен по хешу значения
+	// Возвращает nil, если токен не найден
+	FindByHash(ctx context.Context, tokenHash string) (*RefreshToken, error)
+
+	// MarkUsed атомарно помечает токен использованным
+	// Возвращает false, если токен уже использован или отозван -
+	// так два параллельных обмена одного токена не пройдут оба
+	MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error)
+
+	// RevokeFamily отзывает все токены семейства (сессии)
+	RevokeFamily(ctx context.Context, familyID string, at time.Time) error
+
+	// DeleteExpired удаляет токены, истекшие до before
+	DeleteExpired(ctx context.Context, before time.Time) (int64, error)
+}
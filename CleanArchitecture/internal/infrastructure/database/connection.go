@@ -18,10 +18,21 @@ type Config struct {
 	SSLMode  string
 }
 
+// Dialect SQL диалект базы данных
+// Основная база - PostgreSQL; SQLite используется как встроенная база
+// в тестах и для локального запуска без сервера БД
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite3"
+)
+
 // DB обертка над sql.DB с дополнительными методами
 type DB struct {
 	*sql.DB
-	config Config
+	config  Config
+	dialect Dialect
 }
 
 // NewConnection создает новое подключение к PostgreSQL базе данных
@@ -54,11 +65,27 @@ func NewConnection(config Config) (*DB, error) {
 	}
 
 	return &DB{
-		DB:     sqlDB,
-		config: config,
+		DB:      sqlDB,
+		config:  config,
+		dialect: DialectPostgres,
 	}, nil
 }
 
+// NewEmbedded оборачивает уже открытое подключение к встроенной базе
+// Драйвер ("sqlite3") регистрирует вызывающий код, для ":memory:" нужен
+// sqlDB.SetMaxOpenConns(1) - каждое соединение получает свою базу
+func NewEmbedded(sqlDB *sql.DB) *DB {
+	return &DB{
+		DB:      sqlDB,
+		dialect: DialectSQLite,
+	}
+}
+
+// Dialect возвращает SQL диалект подключения
+func (db *DB) Dialect() Dialect {
+	return db.dialect
+}
+
 // Close закрывает подключение к базе данных
 func (db *DB) Close() error {
 	return db.DB.Close()
@@ -77,6 +104,20 @@ func (db *DB) RunMigrations() error {
 		createLinksTable,
 		createStatsTable,
 		createIndexes,
+		createStatsIndexes,
+		createDeadLettersTable,
+		createLinkRestrictions,
+		createLinkExpiresIndex,
+		createRefreshTokensTable,
+		createRevokedTokensTable,
+		createTokenIndexes,
+		createLinkSafety,
+		createDomainsTable,
+		createLinkDomains,
+		createStatSource,
+	}
+	if db.dialect == DialectSQLite {
+		migrations = sqliteMigrations
 	}
 
 	for _, migration := range migrations {
@@ -134,6 +175,182 @@ CREATE INDEX IF NOT EXISTS idx_stats_created_at ON stats(created_at);
 CREATE INDEX IF NOT EXISTS idx_stats_ip_address ON stats(ip_address);
 `
 
+// createStatsIndexes индексы для агрегатов статистики за период:
+// все запросы StatRepository фильтруют по (link_id, created_at)
+const createStatsIndexes = `
+CREATE INDEX IF NOT EXISTS idx_stats_link_created_at ON stats(link_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_stats_link_country ON stats(link_id, country);
+`
+
+// createDeadLettersTable события кликов, которые не удалось сохранить
+// Событие хранится целиком в JSON, чтобы его можно было переотправить
+const createDeadLettersTable = `
+CREATE TABLE IF NOT EXISTS dead_letter_events (
+    id BIGSERIAL PRIMARY KEY,
+    event JSONB NOT NULL,
+    error TEXT NOT NULL,
+    failed_at TIMESTAMPTZ NOT NULL
+);
+`
+
+// createLinkRestrictions ограничения ссылки: период действия, лимит
+// переходов и пароль. ALTER, чтобы обновить уже созданные таблицы
+const createLinkRestrictions = `
+ALTER TABLE links ADD COLUMN IF NOT EXISTS activates_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE links ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE links ADD COLUMN IF NOT EXISTS max_clicks INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE links ADD COLUMN IF NOT EXISTS password_hash VARCHAR(255) NOT NULL DEFAULT '';
+`
+
+// createLinkExpiresIndex индекс для фоновой очистки истекших ссылок
+const createLinkExpiresIndex = `
+CREATE INDEX IF NOT EXISTS idx_links_expires_at ON links(expires_at) WHERE expires_at IS NOT NULL;
+`
+
+// createRefreshTokensTable refresh токены: хранится только SHA-256 значения
+const createRefreshTokensTable = `
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id VARCHAR(64) NOT NULL,
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE
+);`
+
+// createRevokedTokensTable denylist отозванных access токенов и сессий
+// Запись нужна только до истечения токена, потом ее можно удалить
+const createRevokedTokensTable = `
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);`
+
+// createLinkSafety результат проверки адреса назначения на угрозы
+// Индекс нужен для выборки отключенных и отмеченных ссылок
+const createLinkSafety = `
+ALTER TABLE links ADD COLUMN IF NOT EXISTS safety_status VARCHAR(16) NOT NULL DEFAULT 'ok';
+ALTER TABLE links ADD COLUMN IF NOT EXISTS safety_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE links ADD COLUMN IF NOT EXISTS safety_updated_at TIMESTAMP WITH TIME ZONE;
+CREATE INDEX IF NOT EXISTS idx_links_safety_status ON links(safety_status) WHERE safety_status <> 'ok';
+`
+
+// createDomainsTable пользовательские домены коротких ссылок
+const createDomainsTable = `
+CREATE TABLE IF NOT EXISTS domains (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    host VARCHAR(253) UNIQUE NOT NULL,
+    verification_token VARCHAR(64) NOT NULL,
+    verified_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_domains_user_id ON domains(user_id);
+`
+
+// createLinkDomains привязка ссылок к доменам
+// Короткий код уникален в пределах домена: глобальное ограничение
+// UNIQUE(short_code) заменяется уникальным индексом по паре, где
+// NULL (домен по умолчанию) считается доменом 0
+const createLinkDomains = `
+ALTER TABLE links ADD COLUMN IF NOT EXISTS domain_id INTEGER REFERENCES domains(id);
+ALTER TABLE links DROP CONSTRAINT IF EXISTS links_short_code_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_links_domain_short_code ON links((COALESCE(domain_id, 0)), short_code);
+CREATE INDEX IF NOT EXISTS idx_links_domain_id ON links(domain_id) WHERE domain_id IS NOT NULL;
+`
+
+// createStatSource метка канала перехода (utm_source), например "qr"
+// Частичный индекс: большинство переходов приходит без метки
+const createStatSource = `
+ALTER TABLE stats ADD COLUMN IF NOT EXISTS source VARCHAR(32) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_stats_link_source ON stats(link_id, source) WHERE source <> '';
+`
+
+const createTokenIndexes = `
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_expires_at ON refresh_tokens(expires_at);
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+`
+
+// sqliteMigrations та же схема для встроенной базы SQLite
+var sqliteMigrations = []string{`
+CREATE TABLE IF NOT EXISTS users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    email TEXT UNIQUE NOT NULL,
+    hashed_password TEXT NOT NULL,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`, `
+CREATE TABLE IF NOT EXISTS domains (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    host TEXT UNIQUE NOT NULL,
+    verification_token TEXT NOT NULL,
+    verified_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_domains_user_id ON domains(user_id);`, `
+CREATE TABLE IF NOT EXISTS links (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    original_url TEXT NOT NULL,
+    short_code TEXT NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    clicks_count INTEGER DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    activates_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    max_clicks INTEGER NOT NULL DEFAULT 0,
+    password_hash TEXT NOT NULL DEFAULT '',
+    safety_status TEXT NOT NULL DEFAULT 'ok',
+    safety_reason TEXT NOT NULL DEFAULT '',
+    safety_updated_at TIMESTAMP,
+    domain_id INTEGER REFERENCES domains(id)
+);`, `
+CREATE TABLE IF NOT EXISTS stats (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    link_id INTEGER NOT NULL REFERENCES links(id) ON DELETE CASCADE,
+    user_agent TEXT,
+    ip_address TEXT,
+    referer TEXT,
+    country TEXT,
+    city TEXT,
+    source TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);`,
+	createIndexes,
+	createStatsIndexes, `
+CREATE TABLE IF NOT EXISTS dead_letter_events (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    event TEXT NOT NULL,
+    error TEXT NOT NULL,
+    failed_at TIMESTAMP NOT NULL
+);`,
+	createLinkExpiresIndex, `
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    family_id TEXT NOT NULL,
+    token_hash TEXT UNIQUE NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);`, `
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    id TEXT PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL
+);`,
+	createTokenIndexes, `
+CREATE INDEX IF NOT EXISTS idx_links_safety_status ON links(safety_status) WHERE safety_status <> 'ok';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_links_domain_short_code ON links((COALESCE(domain_id, 0)), short_code);
+CREATE INDEX IF NOT EXISTS idx_links_domain_id ON links(domain_id) WHERE domain_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_stats_link_source ON stats(link_id, source) WHERE source <> '';`,
+}
+
 // ПРИНЦИПЫ INFRASTRUCTURE СЛОЯ:
 // 1. Содержит технические детали (SQL, драйверы БД)
 // 2. Реализует интерфейсы из domain слоя
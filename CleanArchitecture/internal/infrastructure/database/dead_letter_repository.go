@@ -0,0 +1,70 @@
+package database
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	statUC "clean-url-shortener/internal/usecase/stat"
+)
+
+// DeadLetterRepository реализует интерфейс stat.DeadLetterStore
+// События, исчерпавшие попытки, сохраняются в таблицу dead_letter_events
+type DeadLetterRepository struct {
+	db *DB
+}
+
+// NewDeadLetterRepository создает новый репозиторий dead-letter событий
+func NewDeadLetterRepository(db *DB) statUC.DeadLetterStore {
+	return &DeadLetterRepository{
+		db: db,
+	}
+}
+
+// SaveDeadLetter сохраняет событие вместе с последней ошибкой
+func (r *DeadLetterRepository) SaveDeadLetter(ctx context.Context, letter statUC.DeadLetter) error {
+	event, err := json.Marshal(letter.Event)
+	if err != nil {
+		return err
+	}
+	if letter.FailedAt.IsZero() {
+		letter.FailedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO dead_letter_events (event, error, failed_at)
+		VALUES ($1, $2, $3)`
+
+	_, err = r.db.ExecContext(ctx, query, string(event), letter.Error, normalizeTime(letter.FailedAt))
+	return err
+}
+
+// ListDeadLetters возвращает последние limit событий, новые первыми
+func (r *DeadLetterRepository) ListDeadLetters(ctx context.Context, limit int) ([]statUC.DeadLetter, error) {
+	query := `
+		SELECT event, error, failed_at
+		FROM dead_letter_events
+		ORDER BY failed_at DESC, id DESC
+		LIMIT $1`
+
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var letters []statUC.DeadLetter
+	for rows.Next() {
+		var event []byte
+		var letter statUC.DeadLetter
+		if err := rows.Scan(&event, &letter.Error, &letter.FailedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(event, &letter.Event); err != nil {
+			return nil, err
+		}
+		letters = append(letters, letter)
+	}
+
+	return letters, rows.Err()
+}
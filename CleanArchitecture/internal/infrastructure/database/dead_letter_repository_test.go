@@ -0,0 +1,43 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	statUC "clean-url-shortener/internal/usecase/stat"
+)
+
+func TestDeadLetterRepositoryRoundTrip(t *testing.T) {
+	repo := NewDeadLetterRepository(newTestDB(t))
+	ctx := context.Background()
+
+	failedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	for i, linkID := range []uint{1, 2} {
+		err := repo.SaveDeadLetter(ctx, statUC.DeadLetter{
+			Event: statUC.LinkClickedEvent{
+				ID:        "1-0",
+				LinkID:    linkID,
+				IPAddress: "10.0.0.1",
+				Timestamp: "2026-10-01T11:59:59Z",
+				Attempt:   5,
+			},
+			Error:    "db down",
+			FailedAt: failedAt.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatalf("SaveDeadLetter() error = %v", err)
+		}
+	}
+
+	letters, err := repo.ListDeadLetters(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListDeadLetters() error = %v", err)
+	}
+	if len(letters) != 2 || letters[0].Event.LinkID != 2 || letters[0].Event.Attempt != 5 || letters[0].Error != "db down" {
+		t.Errorf("ListDeadLetters() = %+v", letters)
+	}
+	if !letters[1].FailedAt.Equal(failedAt) {
+		t.Errorf("FailedAt = %v, want %v", letters[1].FailedAt, failedAt)
+	}
+}
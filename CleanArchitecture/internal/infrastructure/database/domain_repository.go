@@ -0,0 +1,127 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+
+	"clean-url-shortener/internal/domain/domain"
+)
+
+// DomainRepository реализует интерфейс domain.Repository
+type DomainRepository struct {
+	db *DB
+}
+
+// NewDomainRepository создает новый репозиторий пользовательских доменов
+func NewDomainRepository(db *DB) domain.Repository {
+	return &DomainRepository{
+		db: db,
+	}
+}
+
+// Save сохраняет домен
+func (r *DomainRepository) Save(ctx context.Context, d *domain.Domain) error {
+	if d.ID != 0 {
+		// Из изменяемых полей у домена только статус подтверждения
+		query := `UPDATE domains SET verified_at = $1 WHERE id = $2`
+		_, err := r.db.ExecContext(ctx, query, nullTime(d.VerifiedAt), d.ID)
+		return err
+	}
+
+	query := `
+		INSERT INTO domains (user_id, host, verification_token, verified_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		d.UserID,
+		d.Host,
+		d.VerificationToken,
+		nullTime(d.VerifiedAt),
+		normalizeTime(d.CreatedAt),
+	).Scan(&d.ID)
+}
+
+// FindByID находит домен по ID
+func (r *DomainRepository) FindByID(ctx context.Context, id uint) (*domain.Domain, error) {
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE id = $1`
+	return r.findOne(ctx, query, id)
+}
+
+// FindByHost находит домен по имени хоста
+func (r *DomainRepository) FindByHost(ctx context.Context, host string) (*domain.Domain, error) {
+	query := `SELECT ` + domainColumns + ` FROM domains WHERE host = $1`
+	return r.findOne(ctx, query, host)
+}
+
+// FindByUserID возвращает все домены пользователя
+func (r *DomainRepository) FindByUserID(ctx context.Context, userID uint) ([]*domain.Domain, error) {
+	query := `
+		SELECT ` + domainColumns + `
+		FROM domains
+		WHERE user_id = $1
+		ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var domains []*domain.Domain
+	for rows.Next() {
+		d, err := scanDomain(rows)
+		if err != nil {
+			return nil, err
+		}
+		domains = append(domains, d)
+	}
+
+	return domains, rows.Err()
+}
+
+// Delete удаляет домен по ID
+func (r *DomainRepository) Delete(ctx context.Context, id uint) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM domains WHERE id = $1`, id)
+	return err
+}
+
+// findOne выполняет запрос одного домена; nil, если записи нет
+func (r *DomainRepository) findOne(ctx context.Context, query string, arg interface{}) (*domain.Domain, error) {
+	d, err := scanDomain(r.db.QueryRowContext(ctx, query, arg))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// domainColumns колонки домена в порядке, который ожидает scanDomain
+const domainColumns = `id, user_id, host, verification_token, verified_at, created_at`
+
+// scanDomain читает домен из строки результата
+func scanDomain(row rowScanner) (*domain.Domain, error) {
+	d := &domain.Domain{}
+	var verifiedAt sql.NullTime
+
+	err := row.Scan(
+		&d.ID,
+		&d.UserID,
+		&d.Host,
+		&d.VerificationToken,
+		&verifiedAt,
+		&d.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if verifiedAt.Valid {
+		d.VerifiedAt = &verifiedAt.Time
+	}
+	return d, nil
+}
@@ -0,0 +1,114 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"clean-url-shortener/internal/domain/domain"
+	"clean-url-shortener/internal/domain/link"
+)
+
+func TestDomainRepository(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewDomainRepository(db)
+	ctx := context.Background()
+
+	owner := newTestLink(t, db, "owner@example.com", "seed").UserID
+
+	d, err := domain.NewDomain("go.brand.example", owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, d); err != nil || d.ID == 0 {
+		t.Fatalf("Save() = %v, ID = %d", err, d.ID)
+	}
+
+	found, err := repo.FindByHost(ctx, "go.brand.example")
+	if err != nil || found == nil || found.ID != d.ID || found.VerificationToken != d.VerificationToken {
+		t.Fatalf("FindByHost() = %+v, %v", found, err)
+	}
+	if found.IsVerified() {
+		t.Error("new domain is verified")
+	}
+	if missing, err := repo.FindByHost(ctx, "other.example"); missing != nil || err != nil {
+		t.Errorf("FindByHost(missing) = %+v, %v; want nil, nil", missing, err)
+	}
+
+	d.MarkVerified(time.Now())
+	if err := repo.Save(ctx, d); err != nil {
+		t.Fatalf("Save(verified) error = %v", err)
+	}
+	if found, _ := repo.FindByID(ctx, d.ID); found == nil || !found.IsVerified() {
+		t.Errorf("FindByID() after verification = %+v", found)
+	}
+
+	domains, err := repo.FindByUserID(ctx, owner)
+	if err != nil || len(domains) != 1 {
+		t.Fatalf("FindByUserID() = %v, %v", domains, err)
+	}
+
+	if err := repo.Delete(ctx, d.ID); err != nil {
+		t.Fatal(err)
+	}
+	if found, _ := repo.FindByID(ctx, d.ID); found != nil {
+		t.Error("domain was not deleted")
+	}
+}
+
+func TestLinkRepositoryShortCodePerDomain(t *testing.T) {
+	db := newTestDB(t)
+	linkRepo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	// Код "sale" на домене по умолчанию
+	defaultLink := newTestLink(t, db, "owner@example.com", "sale")
+
+	d, err := domain.NewDomain("go.brand.example", defaultLink.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewDomainRepository(db).Save(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+
+	// Тот же код на пользовательском домене - другая ссылка
+	branded, err := link.NewLinkWithCustomCode("https://brand.example/sale", "sale", defaultLink.UserID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	branded.SetDomain(d.ID)
+	if err := linkRepo.Save(ctx, branded); err != nil {
+		t.Fatalf("Save(same code on custom domain) error = %v", err)
+	}
+
+	// Повтор кода в пределах домена запрещен уникальным индексом
+	duplicate, _ := link.NewLinkWithCustomCode("https://brand.example/other", "sale", defaultLink.UserID)
+	duplicate.SetDomain(d.ID)
+	if err := linkRepo.Save(ctx, duplicate); err == nil {
+		t.Error("Save(duplicate code on the same domain) succeeded")
+	}
+
+	for _, tt := range []struct {
+		domainID uint
+		want     *link.Link
+	}{
+		{0, defaultLink},
+		{d.ID, branded},
+	} {
+		found, err := linkRepo.FindByShortCode(ctx, tt.domainID, "sale")
+		if err != nil || found == nil || found.ID != tt.want.ID || found.DomainID != tt.domainID {
+			t.Errorf("FindByShortCode(%d, sale) = %+v, %v; want link %d", tt.domainID, found, err, tt.want.ID)
+		}
+		if exists, _ := linkRepo.ExistsByShortCode(ctx, tt.domainID, "sale"); !exists {
+			t.Errorf("ExistsByShortCode(%d, sale) = false", tt.domainID)
+		}
+	}
+	if found, _ := linkRepo.FindByShortCode(ctx, d.ID+1, "sale"); found != nil {
+		t.Errorf("FindByShortCode(other domain) = %+v, want nil", found)
+	}
+
+	if count, err := linkRepo.CountByDomainID(ctx, d.ID); err != nil || count != 1 {
+		t.Errorf("CountByDomainID() = %d, %v; want 1", count, err)
+	}
+}
@@ -3,19 +3,51 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
+
 	"clean-url-shortener/internal/domain/link"
 )
 
 // LinkRepository реализует интерфейс link.Repository для PostgreSQL
 type LinkRepository struct {
 	db *DB
+	q  sqlExecutor // db или транзакция внутри RunInTx
+}
+
+// sqlExecutor общий интерфейс *sql.DB и *sql.Tx для запросов репозитория
+type sqlExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
 }
 
 // NewLinkRepository создает новый репозиторий ссылок
 func NewLinkRepository(db *DB) link.Repository {
 	return &LinkRepository{
 		db: db,
+		q:  db,
+	}
+}
+
+// RunInTx выполняет fn в одной транзакции
+// Репозиторий, переданный в fn, работает внутри транзакции; если fn
+// вернула ошибку, все изменения откатываются
+func (r *LinkRepository) RunInTx(ctx context.Context, fn func(repo link.Repository) error) error {
+	if _, inTx := r.q.(*sql.Tx); inTx {
+		// Уже внутри транзакции - вложенные транзакции не нужны
+		return fn(r)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(&LinkRepository{db: r.db, q: tx}); err != nil {
+		return err
 	}
+	return tx.Commit()
 }
 
 // Save сохраняет ссылку в базе данных
@@ -29,11 +61,13 @@ func (r *LinkRepository) Save(ctx context.Context, l *link.Link) error {
 // create создает новую ссылку
 func (r *LinkRepository) create(ctx context.Context, l *link.Link) error {
 	query := `
-		INSERT INTO links (original_url, short_code, user_id, clicks_count, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO links (original_url, short_code, user_id, clicks_count, created_at, updated_at,
+			activates_at, expires_at, max_clicks, password_hash,
+			safety_status, safety_reason, safety_updated_at, domain_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 		RETURNING id`
 
-	err := r.db.QueryRowContext(
+	err := r.q.QueryRowContext(
 		ctx,
 		query,
 		l.OriginalURL,
@@ -42,25 +76,41 @@ func (r *LinkRepository) create(ctx context.Context, l *link.Link) error {
 		l.ClicksCount,
 		l.CreatedAt,
 		l.UpdatedAt,
+		nullTime(l.ActivatesAt),
+		nullTime(l.ExpiresAt),
+		l.MaxClicks,
+		l.PasswordHash,
+		safetyStatus(l.SafetyStatus),
+		l.SafetyReason,
+		nullTime(l.SafetyUpdatedAt),
+		nullID(l.DomainID),
 	).Scan(&l.ID)
 
 	return err
 }
 
 // update обновляет существующую ссылку
+// Статус безопасности меняется только через UpdateSafety
 func (r *LinkRepository) update(ctx context.Context, l *link.Link) error {
 	query := `
 		UPDATE links 
-		SET original_url = $1, short_code = $2, clicks_count = $3, updated_at = $4
-		WHERE id = $5`
+		SET original_url = $1, short_code = $2, clicks_count = $3, updated_at = $4,
+			activates_at = $5, expires_at = $6, max_clicks = $7, password_hash = $8,
+			domain_id = $9
+		WHERE id = $10`
 
-	_, err := r.db.ExecContext(
+	_, err := r.q.ExecContext(
 		ctx,
 		query,
 		l.OriginalURL,
 		l.ShortCode,
 		l.ClicksCount,
 		l.UpdatedAt,
+		nullTime(l.ActivatesAt),
+		nullTime(l.ExpiresAt),
+		l.MaxClicks,
+		l.PasswordHash,
+		nullID(l.DomainID),
 		l.ID,
 	)
 
@@ -70,20 +120,11 @@ func (r *LinkRepository) update(ctx context.Context, l *link.Link) error {
 // FindByID находит ссылку по ID
 func (r *LinkRepository) FindByID(ctx context.Context, id uint) (*link.Link, error) {
 	query := `
-		SELECT id, original_url, short_code, user_id, clicks_count, created_at, updated_at
+		SELECT `+linkColumns+`
 		FROM links
 		WHERE id = $1`
 
-	l := &link.Link{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&l.ID,
-		&l.OriginalURL,
-		&l.ShortCode,
-		&l.UserID,
-		&l.ClicksCount,
-		&l.CreatedAt,
-		&l.UpdatedAt,
-	)
+	l, err := scanLink(r.q.QueryRowContext(ctx, query, id))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -95,23 +136,15 @@ func (r *LinkRepository) FindByID(ctx context.Context, id uint) (*link.Link, err
 	return l, nil
 }
 
-// FindByShortCode находит ссылку по короткому коду
-func (r *LinkRepository) FindByShortCode(ctx context.Context, shortCode string) (*link.Link, error) {
+// FindByShortCode находит ссылку по короткому коду на домене
+// COALESCE совпадает с выражением уникального индекса, поэтому индекс используется
+func (r *LinkRepository) FindByShortCode(ctx context.Context, domainID uint, shortCode string) (*link.Link, error) {
 	query := `
-		SELECT id, original_url, short_code, user_id, clicks_count, created_at, updated_at
+		SELECT `+linkColumns+`
 		FROM links
-		WHERE short_code = $1`
+		WHERE COALESCE(domain_id, 0) = $1 AND short_code = $2`
 
-	l := &link.Link{}
-	err := r.db.QueryRowContext(ctx, query, shortCode).Scan(
-		&l.ID,
-		&l.OriginalURL,
-		&l.ShortCode,
-		&l.UserID,
-		&l.ClicksCount,
-		&l.CreatedAt,
-		&l.UpdatedAt,
-	)
+	l, err := scanLink(r.q.QueryRowContext(ctx, query, domainID, shortCode))
 
 	if err == sql.ErrNoRows {
 		return nil, nil
@@ -126,13 +159,13 @@ func (r *LinkRepository) FindByShortCode(ctx context.Context, shortCode string)
 // FindByUserID находит все ссылки пользователя с пагинацией
 func (r *LinkRepository) FindByUserID(ctx context.Context, userID uint, limit, offset int) ([]*link.Link, error) {
 	query := `
-		SELECT id, original_url, short_code, user_id, clicks_count, created_at, updated_at
+		SELECT `+linkColumns+`
 		FROM links
 		WHERE user_id = $1
-		ORDER BY created_at DESC
+		ORDER BY created_at DESC, id DESC
 		LIMIT $2 OFFSET $3`
 
-	rows, err := r.db.QueryContext(ctx, query, userID, limit, offset)
+	rows, err := r.q.QueryContext(ctx, query, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -140,38 +173,29 @@ func (r *LinkRepository) FindByUserID(ctx context.Context, userID uint, limit, o
 
 	var links []*link.Link
 	for rows.Next() {
-		l := &link.Link{}
-		err := rows.Scan(
-			&l.ID,
-			&l.OriginalURL,
-			&l.ShortCode,
-			&l.UserID,
-			&l.ClicksCount,
-			&l.CreatedAt,
-			&l.UpdatedAt,
-		)
+		l, err := scanLink(rows)
 		if err != nil {
 			return nil, err
 		}
 		links = append(links, l)
 	}
 
-	return links, nil
+	return links, rows.Err()
 }
 
 // Delete удаляет ссылку по ID
 func (r *LinkRepository) Delete(ctx context.Context, id uint) error {
 	query := `DELETE FROM links WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, id)
+	_, err := r.q.ExecContext(ctx, query, id)
 	return err
 }
 
-// ExistsByShortCode проверяет существование ссылки с указанным коротким кодом
-func (r *LinkRepository) ExistsByShortCode(ctx context.Context, shortCode string) (bool, error) {
-	query := `SELECT EXISTS(SELECT 1 FROM links WHERE short_code = $1)`
+// ExistsByShortCode проверяет существование ссылки с указанным коротким кодом на домене
+func (r *LinkRepository) ExistsByShortCode(ctx context.Context, domainID uint, shortCode string) (bool, error) {
+	query := `SELECT EXISTS(SELECT 1 FROM links WHERE COALESCE(domain_id, 0) = $1 AND short_code = $2)`
 
 	var exists bool
-	err := r.db.QueryRowContext(ctx, query, shortCode).Scan(&exists)
+	err := r.q.QueryRowContext(ctx, query, domainID, shortCode).Scan(&exists)
 	return exists, err
 }
 
@@ -180,13 +204,177 @@ func (r *LinkRepository) CountByUserID(ctx context.Context, userID uint) (int, e
 	query := `SELECT COUNT(*) FROM links WHERE user_id = $1`
 
 	var count int
-	err := r.db.QueryRowContext(ctx, query, userID).Scan(&count)
+	err := r.q.QueryRowContext(ctx, query, userID).Scan(&count)
+	return count, err
+}
+
+// CountByDomainID возвращает количество ссылок на пользовательском домене
+func (r *LinkRepository) CountByDomainID(ctx context.Context, domainID uint) (int, error) {
+	query := `SELECT COUNT(*) FROM links WHERE domain_id = $1`
+
+	var count int
+	err := r.q.QueryRowContext(ctx, query, domainID).Scan(&count)
 	return count, err
 }
 
 // IncrementClicks увеличивает счетчик переходов по ссылке
+// Условие на max_clicks в самом UPDATE не дает параллельным
+// переходам превысить лимит
 func (r *LinkRepository) IncrementClicks(ctx context.Context, linkID uint) error {
-	query := `UPDATE links SET clicks_count = clicks_count + 1, updated_at = NOW() WHERE id = $1`
-	_, err := r.db.ExecContext(ctx, query, linkID)
+	query := `
+		UPDATE links SET clicks_count = clicks_count + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND (max_clicks = 0 OR clicks_count < max_clicks)`
+
+	result, err := r.q.ExecContext(ctx, query, linkID)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		// Ссылка либо удалена, либо исчерпала лимит
+		exists, err := r.exists(ctx, linkID)
+		if err != nil {
+			return err
+		}
+		if exists {
+			return link.ErrClickLimitReached
+		}
+	}
+	return nil
+}
+
+// DeleteExpired удаляет ссылки, срок действия которых истек до before
+func (r *LinkRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM links WHERE expires_at IS NOT NULL AND expires_at < $1`
+
+	result, err := r.q.ExecContext(ctx, query, normalizeTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// FindAfterID возвращает до limit ссылок с ID больше afterID
+func (r *LinkRepository) FindAfterID(ctx context.Context, afterID uint, limit int) ([]*link.Link, error) {
+	query := `
+		SELECT `+linkColumns+`
+		FROM links
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	rows, err := r.q.QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []*link.Link
+	for rows.Next() {
+		l, err := scanLink(rows)
+		if err != nil {
+			return nil, err
+		}
+		links = append(links, l)
+	}
+
+	return links, rows.Err()
+}
+
+// UpdateSafety сохраняет статус безопасности ссылки
+// Отдельный UPDATE не затирает счетчик переходов, изменившийся во время проверки
+func (r *LinkRepository) UpdateSafety(ctx context.Context, l *link.Link) error {
+	query := `
+		UPDATE links SET safety_status = $1, safety_reason = $2, safety_updated_at = $3
+		WHERE id = $4`
+
+	_, err := r.q.ExecContext(ctx, query, safetyStatus(l.SafetyStatus), l.SafetyReason, nullTime(l.SafetyUpdatedAt), l.ID)
 	return err
-}
\ No newline at end of file
+}
+
+// exists проверяет существование ссылки по ID
+func (r *LinkRepository) exists(ctx context.Context, id uint) (bool, error) {
+	var exists bool
+	err := r.q.QueryRowContext(ctx, `SELECT EXISTS(SELECT 1 FROM links WHERE id = $1)`, id).Scan(&exists)
+	return exists, err
+}
+
+// linkColumns колонки ссылки в порядке, который ожидает scanLink
+const linkColumns = `id, original_url, short_code, user_id, clicks_count, created_at, updated_at,
+		activates_at, expires_at, max_clicks, password_hash,
+		safety_status, safety_reason, safety_updated_at, domain_id`
+
+// rowScanner общий интерфейс *sql.Row и *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// scanLink читает ссылку из строки результата
+func scanLink(row rowScanner) (*link.Link, error) {
+	l := &link.Link{}
+	var activatesAt, expiresAt, safetyUpdatedAt sql.NullTime
+	var status string
+	var domainID sql.NullInt64
+
+	err := row.Scan(
+		&l.ID,
+		&l.OriginalURL,
+		&l.ShortCode,
+		&l.UserID,
+		&l.ClicksCount,
+		&l.CreatedAt,
+		&l.UpdatedAt,
+		&activatesAt,
+		&expiresAt,
+		&l.MaxClicks,
+		&l.PasswordHash,
+		&status,
+		&l.SafetyReason,
+		&safetyUpdatedAt,
+		&domainID,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	l.DomainID = uint(domainID.Int64)
+
+	l.SafetyStatus = link.SafetyStatus(status)
+	if safetyUpdatedAt.Valid {
+		l.SafetyUpdatedAt = &safetyUpdatedAt.Time
+	}
+
+	if activatesAt.Valid {
+		l.ActivatesAt = &activatesAt.Time
+	}
+	if expiresAt.Valid {
+		l.ExpiresAt = &expiresAt.Time
+	}
+	return l, nil
+}
+
+// safetyStatus статус для базы: ссылка без проверки считается безопасной
+func safetyStatus(status link.SafetyStatus) string {
+	if status == "" {
+		return string(link.SafetyOK)
+	}
+	return string(status)
+}
+
+// nullTime превращает необязательное время в значение для базы
+// Время приводится к UTC, чтобы сравнения работали и в SQLite
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: normalizeTime(*t), Valid: true}
+}
+// nullID превращает необязательную ссылку на запись в значение для базы
+// 0 означает отсутствие связи (NULL)
+func nullID(id uint) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id != 0}
+}
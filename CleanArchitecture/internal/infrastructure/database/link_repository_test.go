@@ -0,0 +1,175 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"clean-url-shortener/internal/domain/link"
+)
+
+func TestLinkRepositoryRestrictions(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	l := newTestLink(t, db, "owner@example.com", "limited")
+	activatesAt := time.Now().Add(-time.Hour)
+	expiresAt := time.Now().Add(time.Hour)
+	if err := l.SetSchedule(&activatesAt, &expiresAt); err != nil {
+		t.Fatal(err)
+	}
+	l.SetMaxClicks(2)
+	l.SetPasswordHash("hash")
+	if err := repo.Save(ctx, l); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	found, err := repo.FindByShortCode(ctx, 0, "limited")
+	if err != nil || found == nil {
+		t.Fatalf("FindByShortCode() = %v, %v", found, err)
+	}
+	if found.ActivatesAt == nil || !found.ActivatesAt.Equal(normalizeTime(activatesAt)) ||
+		found.ExpiresAt == nil || !found.ExpiresAt.Equal(normalizeTime(expiresAt)) {
+		t.Errorf("schedule = %v - %v, want %v - %v", found.ActivatesAt, found.ExpiresAt, activatesAt, expiresAt)
+	}
+	if found.MaxClicks != 2 || found.PasswordHash != "hash" {
+		t.Errorf("MaxClicks, PasswordHash = %d, %q", found.MaxClicks, found.PasswordHash)
+	}
+
+	// Лимит проверяется в самом UPDATE
+	for i := 0; i < 2; i++ {
+		if err := repo.IncrementClicks(ctx, l.ID); err != nil {
+			t.Fatalf("IncrementClicks() #%d error = %v", i+1, err)
+		}
+	}
+	if err := repo.IncrementClicks(ctx, l.ID); err != link.ErrClickLimitReached {
+		t.Errorf("IncrementClicks() over limit error = %v, want %v", err, link.ErrClickLimitReached)
+	}
+	if found, _ := repo.FindByID(ctx, l.ID); found.ClicksCount != 2 {
+		t.Errorf("ClicksCount = %d, want 2", found.ClicksCount)
+	}
+
+	// Удаленная ссылка - не ошибка лимита
+	if err := repo.IncrementClicks(ctx, 9999); err != nil {
+		t.Errorf("IncrementClicks(missing) error = %v", err)
+	}
+}
+
+func TestLinkRepositoryDeleteExpired(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	expired := newTestLink(t, db, "a@example.com", "expired")
+	active := newTestLink(t, db, "b@example.com", "active")
+	permanent := newTestLink(t, db, "c@example.com", "forever")
+
+	// Срок действия задаем в прошлом напрямую - через домен это запрещено
+	expiredAt := time.Now().Add(-48 * time.Hour)
+	expired.ExpiresAt = &expiredAt
+	activeUntil := time.Now().Add(time.Hour)
+	active.ExpiresAt = &activeUntil
+	for _, l := range []*link.Link{expired, active} {
+		if err := repo.Save(ctx, l); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Ссылка, истекшая недавно, переживает очистку с запасом
+	deleted, err := repo.DeleteExpired(ctx, time.Now().Add(-72*time.Hour))
+	if err != nil || deleted != 0 {
+		t.Fatalf("DeleteExpired(72h ago) = %d, %v; want 0", deleted, err)
+	}
+
+	deleted, err = repo.DeleteExpired(ctx, time.Now().Add(-24*time.Hour))
+	if err != nil || deleted != 1 {
+		t.Fatalf("DeleteExpired(24h ago) = %d, %v; want 1", deleted, err)
+	}
+	for _, l := range []*link.Link{active, permanent} {
+		if found, _ := repo.FindByID(ctx, l.ID); found == nil {
+			t.Errorf("link %q was deleted", l.ShortCode)
+		}
+	}
+	if found, _ := repo.FindByID(ctx, expired.ID); found != nil {
+		t.Error("expired link was not deleted")
+	}
+}
+
+func TestLinkRepositoryRunInTxRollback(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	owner := newTestLink(t, db, "owner@example.com", "first")
+	errAbort := errors.New("abort")
+
+	err := repo.RunInTx(ctx, func(tx link.Repository) error {
+		l, err := link.NewLinkWithCustomCode("https://example.com/tx", "intx", owner.UserID)
+		if err != nil {
+			return err
+		}
+		if err := tx.Save(ctx, l); err != nil {
+			return err
+		}
+
+		// Внутри транзакции ссылка уже видна
+		if exists, err := tx.ExistsByShortCode(ctx, 0, "intx"); err != nil || !exists {
+			t.Errorf("ExistsByShortCode() in tx = %v, %v; want true", exists, err)
+		}
+		return errAbort
+	})
+	if err != errAbort {
+		t.Fatalf("RunInTx() error = %v, want %v", err, errAbort)
+	}
+
+	if exists, _ := repo.ExistsByShortCode(ctx, 0, "intx"); exists {
+		t.Error("link saved in rolled back transaction")
+	}
+}
+
+func TestLinkRepositorySafety(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewLinkRepository(db)
+	ctx := context.Background()
+
+	first := newTestLink(t, db, "a@example.com", "first")
+	second := newTestLink(t, db, "b@example.com", "second")
+	third := newTestLink(t, db, "c@example.com", "third")
+
+	// Постраничный обход по возрастанию ID
+	page, err := repo.FindAfterID(ctx, 0, 2)
+	if err != nil || len(page) != 2 || page[0].ID != first.ID || page[1].ID != second.ID {
+		t.Fatalf("FindAfterID(0, 2) = %v, %v", page, err)
+	}
+	page, err = repo.FindAfterID(ctx, second.ID, 2)
+	if err != nil || len(page) != 1 || page[0].ID != third.ID {
+		t.Fatalf("FindAfterID(second, 2) = %v, %v", page, err)
+	}
+	if page[0].SafetyStatus != link.SafetyOK {
+		t.Errorf("SafetyStatus of new link = %q, want %q", page[0].SafetyStatus, link.SafetyOK)
+	}
+
+	second.SetSafety(link.SafetyBlocked, "domain evil.example is blocklisted", time.Now())
+	if err := repo.UpdateSafety(ctx, second); err != nil {
+		t.Fatalf("UpdateSafety() error = %v", err)
+	}
+
+	// Обычное сохранение ссылки не сбрасывает результат проверки
+	second.SafetyStatus = link.SafetyOK
+	if err := repo.Save(ctx, second); err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := repo.FindByShortCode(ctx, 0, "second")
+	if err != nil || found == nil {
+		t.Fatalf("FindByShortCode() = %v, %v", found, err)
+	}
+	if !found.IsBlocked() || found.SafetyReason != "domain evil.example is blocklisted" || found.SafetyUpdatedAt == nil {
+		t.Errorf("safety = %q, %q, %v", found.SafetyStatus, found.SafetyReason, found.SafetyUpdatedAt)
+	}
+	if err := found.CheckAvailability(time.Now()); err != link.ErrLinkBlocked {
+		t.Errorf("CheckAvailability() error = %v, want %v", err, link.ErrLinkBlocked)
+	}
+}
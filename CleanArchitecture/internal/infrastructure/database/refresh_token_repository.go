@@ -0,0 +1,106 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"clean-url-shortener/internal/domain/token"
+)
+
+// RefreshTokenRepository реализует интерфейс token.Repository
+type RefreshTokenRepository struct {
+	db *DB
+}
+
+// NewRefreshTokenRepository создает новый репозиторий refresh токенов
+func NewRefreshTokenRepository(db *DB) token.Repository {
+	return &RefreshTokenRepository{
+		db: db,
+	}
+}
+
+// Save сохраняет новый токен
+func (r *RefreshTokenRepository) Save(ctx context.Context, t *token.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		t.UserID,
+		t.FamilyID,
+		t.TokenHash,
+		normalizeTime(t.ExpiresAt),
+		normalizeTime(t.CreatedAt),
+	).Scan(&t.ID)
+}
+
+// FindByHash находит токен по хешу значения
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*token.RefreshToken, error) {
+	query := `
+		SELECT id, user_id, family_id, token_hash, expires_at, created_at, used_at, revoked_at
+		FROM refresh_tokens
+		WHERE token_hash = $1`
+
+	t := &token.RefreshToken{}
+	var usedAt, revokedAt sql.NullTime
+	err := r.db.QueryRowContext(ctx, query, tokenHash).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.CreatedAt,
+		&usedAt,
+		&revokedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if usedAt.Valid {
+		t.UsedAt = &usedAt.Time
+	}
+	if revokedAt.Valid {
+		t.RevokedAt = &revokedAt.Time
+	}
+	return t, nil
+}
+
+// MarkUsed атомарно помечает токен использованным
+func (r *RefreshTokenRepository) MarkUsed(ctx context.Context, id uint, at time.Time) (bool, error) {
+	query := `
+		UPDATE refresh_tokens SET used_at = $1
+		WHERE id = $2 AND used_at IS NULL AND revoked_at IS NULL`
+
+	result, err := r.db.ExecContext(ctx, query, normalizeTime(at), id)
+	if err != nil {
+		return false, err
+	}
+
+	updated, err := result.RowsAffected()
+	return updated == 1, err
+}
+
+// RevokeFamily отзывает все токены семейства
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string, at time.Time) error {
+	query := `UPDATE refresh_tokens SET revoked_at = $1 WHERE family_id = $2 AND revoked_at IS NULL`
+	_, err := r.db.ExecContext(ctx, query, normalizeTime(at), familyID)
+	return err
+}
+
+// DeleteExpired удаляет токены, истекшие до before
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context, before time.Time) (int64, error) {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM refresh_tokens WHERE expires_at < $1`, normalizeTime(before))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
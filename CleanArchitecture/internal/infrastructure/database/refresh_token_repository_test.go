@@ -0,0 +1,103 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"clean-url-shortener/internal/domain/token"
+)
+
+func TestRefreshTokenRepositoryRotation(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewRefreshTokenRepository(db)
+	ctx := context.Background()
+
+	owner := newTestLink(t, db, "tokens@example.com", "tokens").UserID
+	first, value, err := token.NewRefreshToken(owner, "family", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	second, _, _ := token.NewRefreshToken(owner, "family", time.Hour)
+	if err := repo.Save(ctx, second); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	found, err := repo.FindByHash(ctx, token.Hash(value))
+	if err != nil || found == nil || found.ID != first.ID || found.IsUsed() || found.IsRevoked() {
+		t.Fatalf("FindByHash() = %+v, %v", found, err)
+	}
+
+	// Токен обменивается ровно один раз
+	if marked, err := repo.MarkUsed(ctx, first.ID, time.Now()); err != nil || !marked {
+		t.Fatalf("MarkUsed() = %v, %v, want true", marked, err)
+	}
+	if marked, err := repo.MarkUsed(ctx, first.ID, time.Now()); err != nil || marked {
+		t.Errorf("second MarkUsed() = %v, %v, want false", marked, err)
+	}
+
+	// Отзыв семейства закрывает и неиспользованные токены
+	if err := repo.RevokeFamily(ctx, "family", time.Now()); err != nil {
+		t.Fatalf("RevokeFamily() error = %v", err)
+	}
+	if marked, _ := repo.MarkUsed(ctx, second.ID, time.Now()); marked {
+		t.Error("MarkUsed() succeeded for revoked token")
+	}
+	if found, _ := repo.FindByHash(ctx, second.TokenHash); found == nil || !found.IsRevoked() {
+		t.Errorf("token after RevokeFamily = %+v, want revoked", found)
+	}
+
+	if deleted, err := repo.DeleteExpired(ctx, time.Now().Add(2*time.Hour)); err != nil || deleted != 2 {
+		t.Errorf("DeleteExpired() = %d, %v, want 2", deleted, err)
+	}
+	if found, _ := repo.FindByHash(ctx, first.TokenHash); found != nil {
+		t.Errorf("FindByHash() after DeleteExpired = %+v, want nil", found)
+	}
+}
+
+func TestTokenDenylistSharedAcrossInstances(t *testing.T) {
+	db := newTestDB(t)
+	ctx := context.Background()
+
+	local, err := NewTokenDenylist(ctx, db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote, err := NewTokenDenylist(ctx, db, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := local.Revoke(ctx, "session", time.Now().Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke() error = %v", err)
+	}
+	// Истекший токен отзывать незачем
+	if err := local.Revoke(ctx, "stale", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatalf("Revoke(expired) error = %v", err)
+	}
+
+	if revoked, err := local.IsRevoked(ctx, "", "jti", "session"); err != nil || !revoked {
+		t.Errorf("local IsRevoked() = %v, %v, want true", revoked, err)
+	}
+	if revoked, _ := local.IsRevoked(ctx, "stale", "other"); revoked {
+		t.Error("IsRevoked() = true for expired or unknown IDs")
+	}
+
+	// Другой экземпляр видит отзыв после перечитывания кеша
+	time.Sleep(2 * time.Millisecond)
+	if revoked, err := remote.IsRevoked(ctx, "session"); err != nil || !revoked {
+		t.Errorf("remote IsRevoked() = %v, %v, want true", revoked, err)
+	}
+
+	// Новый экземпляр загружает отзывы при создании
+	restarted, err := NewTokenDenylist(ctx, db, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := restarted.IsRevoked(ctx, "session"); !revoked {
+		t.Error("restarted IsRevoked() = false, want true")
+	}
+}
@@ -0,0 +1,421 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"clean-url-shortener/internal/domain/stat"
+)
+
+// StatRepository реализует интерфейс stat.Repository для PostgreSQL и SQLite
+//
+// Все агрегаты считаются в базе (GROUP BY по индексу link_id, created_at),
+// в память приложения попадают только готовые числа
+type StatRepository struct {
+	db *DB
+}
+
+// NewStatRepository создает новый репозиторий статистики
+func NewStatRepository(db *DB) stat.Repository {
+	return &StatRepository{
+		db: db,
+	}
+}
+
+// Значения по умолчанию для пустых полей в агрегатах
+const (
+	UnknownCountry = "unknown"
+	DirectReferer  = "direct"
+
+	// topReferersLimit количество источников в GetLinkStatistics
+	topReferersLimit = 10
+)
+
+// Save сохраняет запись статистики в базе данных
+func (r *StatRepository) Save(ctx context.Context, s *stat.Stat) error {
+	return insertStat(ctx, r.db, s)
+}
+
+// SaveBatch сохраняет записи статистики одной транзакцией
+func (r *StatRepository) SaveBatch(ctx context.Context, stats []*stat.Stat) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, s := range stats {
+		if err := insertStat(ctx, tx, s); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// queryRower общий интерфейс *sql.DB и *sql.Tx для вставки
+type queryRower interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// insertStat вставляет одну запись и заполняет ее ID
+func insertStat(ctx context.Context, q queryRower, s *stat.Stat) error {
+	if s.CreatedAt.IsZero() {
+		s.CreatedAt = time.Now()
+	}
+	s.CreatedAt = normalizeTime(s.CreatedAt)
+
+	// Невалидный IP храним как NULL: колонка INET в PostgreSQL его не примет,
+	// а в уникальных кликах такой переход все равно не посчитать
+	var ip interface{}
+	if s.IsValidIP() {
+		ip = s.IPAddress
+	}
+
+	query := `
+		INSERT INTO stats (link_id, user_agent, ip_address, referer, country, city, source, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id`
+
+	return q.QueryRowContext(
+		ctx,
+		query,
+		s.LinkID,
+		s.UserAgent,
+		ip,
+		s.Referer,
+		s.Country,
+		s.City,
+		s.Source,
+		s.CreatedAt,
+	).Scan(&s.ID)
+}
+
+// FindByLinkID находит записи статистики ссылки за период, новые первыми
+func (r *StatRepository) FindByLinkID(ctx context.Context, linkID uint, period stat.Period) ([]*stat.Stat, error) {
+	where, args := periodFilter(linkID, period)
+	query := `
+		SELECT id, link_id, COALESCE(user_agent, ''), COALESCE(` + r.ipText() + `, ''),
+			COALESCE(referer, ''), COALESCE(country, ''), COALESCE(city, ''), source, created_at
+		FROM stats
+		WHERE ` + where + `
+		ORDER BY created_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*stat.Stat
+	for rows.Next() {
+		s := &stat.Stat{}
+		err := rows.Scan(
+			&s.ID,
+			&s.LinkID,
+			&s.UserAgent,
+			&s.IPAddress,
+			&s.Referer,
+			&s.Country,
+			&s.City,
+			&s.Source,
+			&s.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+
+	return stats, rows.Err()
+}
+
+// GetLinkStatistics собирает все агрегаты ссылки за период
+func (r *StatRepository) GetLinkStatistics(ctx context.Context, linkID uint, period stat.Period) (*stat.LinkStatistics, error) {
+	where, args := periodFilter(linkID, period)
+
+	var total int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM stats WHERE `+where, args...).Scan(&total)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count clicks: %w", err)
+	}
+
+	unique, err := r.CountUniqueClicks(ctx, linkID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	byCountry, err := r.GetClicksByCountry(ctx, linkID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	byHour, err := r.GetClicksByHour(ctx, linkID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	referers, err := r.GetTopReferers(ctx, linkID, period, topReferersLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	bySource, err := r.GetClicksBySource(ctx, linkID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return &stat.LinkStatistics{
+		LinkID:          linkID,
+		TotalClicks:     total,
+		UniqueClicks:    unique,
+		ClicksByCountry: byCountry,
+		ClicksByHour:    byHour,
+		TopReferers:     referers,
+		ClicksBySource:  bySource,
+		Period:          period,
+	}, nil
+}
+
+// GetUserLinkStatistics возвращает статистику по каждой ссылке пользователя
+func (r *StatRepository) GetUserLinkStatistics(ctx context.Context, userID uint, period stat.Period) ([]*stat.LinkStatistics, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id FROM links WHERE user_id = $1 ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var linkIDs []uint
+	for rows.Next() {
+		var id uint
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		linkIDs = append(linkIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Запросы выполняем после закрытия rows: встроенная база может
+	// работать с одним соединением
+	result := make([]*stat.LinkStatistics, 0, len(linkIDs))
+	for _, id := range linkIDs {
+		s, err := r.GetLinkStatistics(ctx, id, period)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+
+	return result, nil
+}
+
+// CountTotalClicks возвращает общее количество кликов по ссылке
+func (r *StatRepository) CountTotalClicks(ctx context.Context, linkID uint) (int, error) {
+	query := `SELECT COUNT(*) FROM stats WHERE link_id = $1`
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, linkID).Scan(&count)
+	return count, err
+}
+
+// CountUniqueClicks возвращает количество уникальных IP за период
+func (r *StatRepository) CountUniqueClicks(ctx context.Context, linkID uint, period stat.Period) (int, error) {
+	where, args := periodFilter(linkID, period)
+	query := `SELECT COUNT(DISTINCT ip_address) FROM stats WHERE ` + where
+
+	var count int
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
+	return count, err
+}
+
+// GetClicksByCountry возвращает клики по странам; без страны - "unknown"
+func (r *StatRepository) GetClicksByCountry(ctx context.Context, linkID uint, period stat.Period) (map[string]int, error) {
+	where, args := periodFilter(linkID, period)
+	query := `
+		SELECT COALESCE(NULLIF(country, ''), '` + UnknownCountry + `') AS c, COUNT(*)
+		FROM stats
+		WHERE ` + where + `
+		GROUP BY c`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var country string
+		var count int
+		if err := rows.Scan(&country, &count); err != nil {
+			return nil, err
+		}
+		result[country] = count
+	}
+
+	return result, rows.Err()
+}
+
+// GetClicksByHour возвращает 24 значения - клики по часу суток в UTC
+func (r *StatRepository) GetClicksByHour(ctx context.Context, linkID uint, period stat.Period) ([]int, error) {
+	where, args := periodFilter(linkID, period)
+	query := `
+		SELECT ` + r.hourExpr() + ` AS h, COUNT(*)
+		FROM stats
+		WHERE ` + where + `
+		GROUP BY h`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]int, 24)
+	for rows.Next() {
+		var hour, count int
+		if err := rows.Scan(&hour, &count); err != nil {
+			return nil, err
+		}
+		if hour >= 0 && hour < 24 {
+			result[hour] = count
+		}
+	}
+
+	return result, rows.Err()
+}
+
+// GetTopReferers возвращает limit самых частых источников; без источника - "direct"
+func (r *StatRepository) GetTopReferers(ctx context.Context, linkID uint, period stat.Period, limit int) ([]stat.RefererStat, error) {
+	if limit <= 0 {
+		limit = topReferersLimit
+	}
+
+	where, args := periodFilter(linkID, period)
+	args = append(args, limit)
+	query := fmt.Sprintf(`
+		SELECT COALESCE(NULLIF(referer, ''), '`+DirectReferer+`') AS ref, COUNT(*) AS cnt
+		FROM stats
+		WHERE %s
+		GROUP BY ref
+		ORDER BY cnt DESC, ref
+		LIMIT $%d`, where, len(args))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make([]stat.RefererStat, 0, limit)
+	for rows.Next() {
+		var rs stat.RefererStat
+		if err := rows.Scan(&rs.Referer, &rs.Count); err != nil {
+			return nil, err
+		}
+		result = append(result, rs)
+	}
+
+	return result, rows.Err()
+}
+
+// GetClicksBySource возвращает клики по меткам канала; переходы без метки не входят
+func (r *StatRepository) GetClicksBySource(ctx context.Context, linkID uint, period stat.Period) (map[string]int, error) {
+	where, args := periodFilter(linkID, period)
+	query := `
+		SELECT source, COUNT(*)
+		FROM stats
+		WHERE ` + where + ` AND source <> ''
+		GROUP BY source`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	result := make(map[string]int)
+	for rows.Next() {
+		var source string
+		var count int
+		if err := rows.Scan(&source, &count); err != nil {
+			return nil, err
+		}
+		result[source] = count
+	}
+
+	return result, rows.Err()
+}
+
+// DeleteByLinkID удаляет всю статистику ссылки
+func (r *StatRepository) DeleteByLinkID(ctx context.Context, linkID uint) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM stats WHERE link_id = $1`, linkID)
+	return err
+}
+
+// CleanupOldStats удаляет записи старше olderThan
+func (r *StatRepository) CleanupOldStats(ctx context.Context, olderThan time.Time) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM stats WHERE created_at < $1`, normalizeTime(olderThan))
+	return err
+}
+
+// hourExpr выражение часа суток (UTC) для текущего диалекта
+func (r *StatRepository) hourExpr() string {
+	if r.db.Dialect() == DialectSQLite {
+		return `CAST(strftime('%H', created_at) AS INTEGER)`
+	}
+	return `CAST(EXTRACT(HOUR FROM created_at AT TIME ZONE 'UTC') AS INTEGER)`
+}
+
+// ipText приводит ip_address к строке: в PostgreSQL это тип INET
+func (r *StatRepository) ipText() string {
+	if r.db.Dialect() == DialectSQLite {
+		return `ip_address`
+	}
+	return `host(ip_address)`
+}
+
+// periodFilter условие WHERE по ссылке и периоду
+// Нулевая граница периода означает отсутствие ограничения,
+// конец периода не включается
+func periodFilter(linkID uint, period stat.Period) (string, []interface{}) {
+	conditions := []string{"link_id = $1"}
+	args := []interface{}{linkID}
+
+	if !period.StartDate.IsZero() {
+		args = append(args, normalizeTime(period.StartDate))
+		conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)))
+	}
+	if !period.EndDate.IsZero() {
+		args = append(args, normalizeTime(period.EndDate))
+		conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)))
+	}
+
+	return strings.Join(conditions, " AND "), args
+}
+
+// normalizeTime приводит время к UTC с точностью до микросекунд
+// Так хранит время PostgreSQL, а в SQLite время - строка, и сравнение
+// строк корректно только в одном часовом поясе
+func normalizeTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
+
+// Проверка, что StatRepository реализует интерфейс домена
+var _ stat.Repository = (*StatRepository)(nil)
+
+// ПРИНЦИПЫ РЕПОЗИТОРИЯ СТАТИСТИКИ:
+// 1. Агрегаты (страны, часы, источники, метки канала) считает база, а не Go код
+// 2. Пустые страна и источник сводятся к "unknown" и "direct"
+// 3. Разница диалектов спрятана в hourExpr/ipText, остальной SQL общий
+// 4. Use case получает готовый stat.LinkStatistics и не знает о SQL
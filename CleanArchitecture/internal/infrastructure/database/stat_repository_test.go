@@ -0,0 +1,173 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"clean-url-shortener/internal/domain/link"
+	"clean-url-shortener/internal/domain/stat"
+	"clean-url-shortener/internal/domain/user"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestDB поднимает встроенную SQLite базу с миграциями
+func newTestDB(t *testing.T) *DB {
+	t.Helper()
+
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Каждое соединение к :memory: - отдельная база
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db := NewEmbedded(sqlDB)
+	if err := db.RunMigrations(); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+	return db
+}
+
+// newTestLink создает пользователя и ссылку для статистики
+func newTestLink(t *testing.T, db *DB, email, code string) *link.Link {
+	t.Helper()
+	ctx := context.Background()
+
+	u, err := user.NewUser(email, "Tester")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.HashedPassword = "hash"
+	if err := NewUserRepository(db).Save(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	l, err := link.NewLinkWithCustomCode("https://example.com/"+code, code, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := NewLinkRepository(db).Save(ctx, l); err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestStatRepositoryAggregates(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewStatRepository(db)
+	ctx := context.Background()
+	l := newTestLink(t, db, "stats@example.com", "stats1")
+
+	base := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	clicks := []struct {
+		ip, referer, country, source string
+		at                           time.Time
+	}{
+		{"10.0.0.1", "https://google.com", "RU", "", base.Add(9 * time.Hour)},
+		{"10.0.0.1", "https://google.com", "RU", "", base.Add(9*time.Hour + 30*time.Minute)},
+		{"10.0.0.2", "", "DE", "qr", base.Add(14 * time.Hour)},
+		{"10.0.0.3", "https://t.me", "", "", base.Add(23 * time.Hour)},
+		{"not-an-ip", "https://google.com", "RU", "newsletter", base.Add(14 * time.Hour)},
+		// Вне периода
+		{"10.0.0.9", "https://old.example", "US", "qr", base.Add(-48 * time.Hour)},
+	}
+	for _, c := range clicks {
+		s := stat.NewStat(l.ID, "test-agent", c.ip, c.referer)
+		s.SetLocation(c.country, "")
+		s.SetSource(c.source)
+		// Время в другом поясе должно сохраниться в UTC
+		s.CreatedAt = c.at.In(time.FixedZone("MSK", 3*60*60))
+		if err := repo.Save(ctx, s); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+		if s.ID == 0 {
+			t.Fatal("Save() did not set ID")
+		}
+	}
+
+	period := stat.NewPeriod(base, base.Add(24*time.Hour))
+	got, err := repo.GetLinkStatistics(ctx, l.ID, period)
+	if err != nil {
+		t.Fatalf("GetLinkStatistics() error = %v", err)
+	}
+
+	if got.TotalClicks != 5 || got.UniqueClicks != 3 {
+		t.Errorf("TotalClicks = %d, UniqueClicks = %d; want 5 and 3", got.TotalClicks, got.UniqueClicks)
+	}
+	if got.ClicksByCountry["RU"] != 3 || got.ClicksByCountry["DE"] != 1 || got.ClicksByCountry[UnknownCountry] != 1 {
+		t.Errorf("ClicksByCountry = %v", got.ClicksByCountry)
+	}
+	if len(got.ClicksByHour) != 24 || got.ClicksByHour[9] != 2 || got.ClicksByHour[14] != 2 || got.ClicksByHour[23] != 1 {
+		t.Errorf("ClicksByHour = %v", got.ClicksByHour)
+	}
+	wantReferers := []stat.RefererStat{
+		{Referer: "https://google.com", Count: 3},
+		{Referer: DirectReferer, Count: 1},
+		{Referer: "https://t.me", Count: 1},
+	}
+	if len(got.TopReferers) != len(wantReferers) {
+		t.Fatalf("TopReferers = %v, want %v", got.TopReferers, wantReferers)
+	}
+	for i, want := range wantReferers {
+		if got.TopReferers[i] != want {
+			t.Errorf("TopReferers[%d] = %v, want %v", i, got.TopReferers[i], want)
+		}
+	}
+
+	if len(got.ClicksBySource) != 2 || got.ClicksBySource["qr"] != 1 || got.ClicksBySource["newsletter"] != 1 {
+		t.Errorf("ClicksBySource = %v", got.ClicksBySource)
+	}
+
+	total, err := repo.CountTotalClicks(ctx, l.ID)
+	if err != nil || total != 6 {
+		t.Errorf("CountTotalClicks() = %d, %v; want 6", total, err)
+	}
+
+	found, err := repo.FindByLinkID(ctx, l.ID, period)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 5 || !found[0].CreatedAt.Equal(base.Add(23*time.Hour)) || found[0].IPAddress != "10.0.0.3" {
+		t.Errorf("FindByLinkID() = %d records, first = %+v", len(found), found[0])
+	}
+}
+
+func TestStatRepositoryCleanupAndUserStatistics(t *testing.T) {
+	db := newTestDB(t)
+	repo := NewStatRepository(db)
+	ctx := context.Background()
+	l := newTestLink(t, db, "cleanup@example.com", "clean1")
+
+	now := time.Now()
+	for _, at := range []time.Time{now.Add(-40 * 24 * time.Hour), now.Add(-time.Hour)} {
+		s := stat.NewStat(l.ID, "agent", "192.168.1.1", "")
+		s.CreatedAt = at
+		if err := repo.Save(ctx, s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := repo.CleanupOldStats(ctx, now.Add(-30*24*time.Hour)); err != nil {
+		t.Fatalf("CleanupOldStats() error = %v", err)
+	}
+
+	// Нулевой период - вся история
+	all, err := repo.GetUserLinkStatistics(ctx, l.UserID, stat.Period{})
+	if err != nil {
+		t.Fatalf("GetUserLinkStatistics() error = %v", err)
+	}
+	if len(all) != 1 || all[0].LinkID != l.ID || all[0].TotalClicks != 1 {
+		t.Errorf("GetUserLinkStatistics() = %+v, want one link with 1 click", all)
+	}
+
+	if err := repo.DeleteByLinkID(ctx, l.ID); err != nil {
+		t.Fatal(err)
+	}
+	if total, _ := repo.CountTotalClicks(ctx, l.ID); total != 0 {
+		t.Errorf("CountTotalClicks() after DeleteByLinkID = %d", total)
+	}
+}
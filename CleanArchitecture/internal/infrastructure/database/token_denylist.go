@@ -0,0 +1,147 @@
+package database
+
+import (
+	"context"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"clean-url-shortener/internal/usecase/auth"
+)
+
+// Проверка реализации интерфейса из usecase слоя
+var _ auth.TokenDenylist = (*TokenDenylist)(nil)
+
+// TokenDenylist список отозванных токенов в таблице revoked_tokens
+// с копией в памяти
+//
+// Проверка выполняется на каждом запросе, поэтому она не ходит в базу:
+// записи читаются из кеша, а кеш перечитывается раз в refreshInterval.
+// Отзыв на этом экземпляре виден сразу, на остальных - в пределах
+// refreshInterval.
+type TokenDenylist struct {
+	db              *DB
+	refreshInterval time.Duration
+
+	mu       sync.RWMutex
+	entries  map[string]time.Time // ID -> до какого момента отозван
+	loadedAt time.Time
+
+	refreshing atomic.Bool
+}
+
+// NewTokenDenylist создает denylist и загружает текущие записи
+func NewTokenDenylist(ctx context.Context, db *DB, refreshInterval time.Duration) (*TokenDenylist, error) {
+	if refreshInterval <= 0 {
+		refreshInterval = 10 * time.Second
+	}
+
+	d := &TokenDenylist{
+		db:              db,
+		refreshInterval: refreshInterval,
+		entries:         make(map[string]time.Time),
+	}
+	if err := d.reload(ctx); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// Revoke отзывает токен или сессию по ID до момента until
+func (d *TokenDenylist) Revoke(ctx context.Context, id string, until time.Time) error {
+	if id == "" || !until.After(time.Now()) {
+		// Токен уже истек - отзывать нечего
+		return nil
+	}
+
+	query := `
+		INSERT INTO revoked_tokens (id, expires_at) VALUES ($1, $2)
+		ON CONFLICT (id) DO UPDATE SET expires_at = excluded.expires_at`
+	if _, err := d.db.ExecContext(ctx, query, id, normalizeTime(until)); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	d.entries[id] = until
+	d.mu.Unlock()
+	return nil
+}
+
+// IsRevoked проверяет, отозван ли хотя бы один из ID
+// Пустые ID пропускаются
+func (d *TokenDenylist) IsRevoked(ctx context.Context, ids ...string) (bool, error) {
+	d.refreshIfStale(ctx)
+
+	now := time.Now()
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	for _, id := range ids {
+		if id == "" {
+			continue
+		}
+		if until, ok := d.entries[id]; ok && now.Before(until) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// refreshIfStale перечитывает записи, если кеш устарел
+// Перечитывает один запрос, остальные пока используют текущий кеш.
+// При ошибке базы кеш остается прежним: уже известные отзывы действуют
+func (d *TokenDenylist) refreshIfStale(ctx context.Context) {
+	d.mu.RLock()
+	stale := time.Since(d.loadedAt) >= d.refreshInterval
+	d.mu.RUnlock()
+
+	if !stale || !d.refreshing.CompareAndSwap(false, true) {
+		return
+	}
+	defer d.refreshing.Store(false)
+
+	if err := d.reload(ctx); err != nil {
+		log.Printf("Failed to refresh token denylist, using cached entries: %v", err)
+	}
+}
+
+// reload удаляет истекшие записи и загружает действующие
+func (d *TokenDenylist) reload(ctx context.Context) error {
+	now := normalizeTime(time.Now())
+
+	if _, err := d.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= $1`, now); err != nil {
+		return err
+	}
+
+	rows, err := d.db.QueryContext(ctx, `SELECT id, expires_at FROM revoked_tokens WHERE expires_at > $1`, now)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	entries := make(map[string]time.Time)
+	for rows.Next() {
+		var id string
+		var until time.Time
+		if err := rows.Scan(&id, &until); err != nil {
+			return err
+		}
+		entries[id] = until
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	// Отзывы, сделанные во время загрузки, не должны потеряться
+	for id, until := range d.entries {
+		if _, ok := entries[id]; !ok && until.After(now) {
+			entries[id] = until
+		}
+	}
+	d.entries = entries
+	d.loadedAt = time.Now()
+	d.mu.Unlock()
+	return nil
+}
@@ -0,0 +1,127 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	statUC "clean-url-shortener/internal/usecase/stat"
+)
+
+// ШИНА СОБЫТИЙ КЛИКОВ
+//
+// Redirect публикует событие через link.EventPublisher, TrackClickUseCase
+// читает его через stat.EventSubscriber. Обе реализации (MemoryBus и
+// RedisStreamBus) реализуют оба интерфейса:
+//
+//	RedirectUseCase → PublishLinkClicked → очередь → SubscribeLinkClicked → TrackClickUseCase
+//
+// Публикация никогда не блокирует redirect: если буфер заполнен, событие
+// отбрасывается с ErrBufferFull, а redirect продолжается.
+
+// Ошибки шины событий
+var (
+	ErrBufferFull        = errors.New("event buffer is full")
+	ErrBusClosed         = errors.New("event bus is closed")
+	ErrAlreadySubscribed = errors.New("event bus already has a subscriber")
+)
+
+// Config общие настройки шины
+type Config struct {
+	// BufferSize - емкость очереди в памяти
+	BufferSize int
+
+	// MaxAttempts - сколько раз событие обрабатывается до dead-letter
+	MaxAttempts int
+
+	// RetryDelay - задержка перед повтором, растет с номером попытки
+	RetryDelay time.Duration
+}
+
+// withDefaults заполняет незаданные настройки
+func (c Config) withDefaults() Config {
+	if c.BufferSize <= 0 {
+		c.BufferSize = 10000
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = time.Second
+	}
+	return c
+}
+
+// newLinkClickedEvent создает событие с временем клика
+func newLinkClickedEvent(linkID uint, userAgent, ipAddress, referer, source string) statUC.LinkClickedEvent {
+	return statUC.LinkClickedEvent{
+		LinkID:    linkID,
+		UserAgent: userAgent,
+		IPAddress: ipAddress,
+		Referer:   referer,
+		Source:    source,
+		Timestamp: time.Now().UTC().Format(time.RFC3339Nano),
+	}
+}
+
+// saveDeadLetter сохраняет событие в dead-letter хранилище
+func saveDeadLetter(store statUC.DeadLetterStore, event statUC.LinkClickedEvent, cause error) error {
+	letter := statUC.DeadLetter{
+		Event:    event,
+		Error:    "unknown error",
+		FailedAt: time.Now(),
+	}
+	if cause != nil {
+		letter.Error = cause.Error()
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := store.SaveDeadLetter(ctx, letter); err != nil {
+		log.Printf("Failed to save dead letter for link %d: %v", event.LinkID, err)
+		return err
+	}
+	return nil
+}
+
+// MemoryDeadLetterStore dead-letter хранилище в памяти
+// Используется в тестах и при запуске без базы данных
+type MemoryDeadLetterStore struct {
+	mu      sync.Mutex
+	letters []statUC.DeadLetter
+}
+
+// NewMemoryDeadLetterStore создает пустое хранилище
+func NewMemoryDeadLetterStore() *MemoryDeadLetterStore {
+	return &MemoryDeadLetterStore{}
+}
+
+// SaveDeadLetter сохраняет событие
+func (s *MemoryDeadLetterStore) SaveDeadLetter(ctx context.Context, letter statUC.DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.letters = append(s.letters, letter)
+	return nil
+}
+
+// ListDeadLetters возвращает последние limit событий, новые первыми
+func (s *MemoryDeadLetterStore) ListDeadLetters(ctx context.Context, limit int) ([]statUC.DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]statUC.DeadLetter, 0, limit)
+	for i := len(s.letters) - 1; i >= 0 && len(result) < limit; i-- {
+		result = append(result, s.letters[i])
+	}
+	return result, nil
+}
+
+// memoryEventID идентификатор доставки для событий в памяти
+func memoryEventID(seq uint64) string {
+	return "mem-" + strconv.FormatUint(seq, 10)
+}
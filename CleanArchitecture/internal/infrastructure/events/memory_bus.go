@@ -0,0 +1,189 @@
+package events
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	linkUC "clean-url-shortener/internal/usecase/link"
+	statUC "clean-url-shortener/internal/usecase/stat"
+)
+
+// Проверка реализации интерфейсов use case слоя
+var (
+	_ linkUC.EventPublisher  = (*MemoryBus)(nil)
+	_ statUC.EventSubscriber = (*MemoryBus)(nil)
+)
+
+// MemoryBus шина событий внутри процесса на ограниченном буфере
+//
+// Гарантии:
+//   - событие, по которому вызван Nack, доставляется повторно с задержкой
+//     RetryDelay * попытка, после MaxAttempts попыток - в dead-letter
+//   - Drain перестает принимать события, дожидается повторов и закрывает
+//     канал подписчика; подписчик дочитывает очередь до конца
+//
+// События живут только в памяти процесса: при аварийном завершении
+// необработанные клики теряются. Для надежной доставки - RedisStreamBus.
+type MemoryBus struct {
+	config      Config
+	deadLetters statUC.DeadLetterStore
+
+	queue chan statUC.LinkClickedEvent
+	seq   atomic.Uint64
+
+	mu         sync.Mutex
+	closed     bool // новые события не принимаются
+	drained    bool // канал подписчика закрыт
+	subscribed bool
+	pending    int // запланированные повторы
+
+	retryDone chan struct{} // сигнал о завершении повтора
+	abort     chan struct{} // отмена ожидающих повторов
+	abortOnce sync.Once
+
+	dropped atomic.Uint64
+}
+
+// NewMemoryBus создает шину событий в памяти
+func NewMemoryBus(config Config, deadLetters statUC.DeadLetterStore) *MemoryBus {
+	config = config.withDefaults()
+	return &MemoryBus{
+		config:      config,
+		deadLetters: deadLetters,
+		queue:       make(chan statUC.LinkClickedEvent, config.BufferSize),
+		retryDone:   make(chan struct{}, 1),
+		abort:       make(chan struct{}),
+	}
+}
+
+// PublishLinkClicked ставит событие в очередь без ожидания
+func (b *MemoryBus) PublishLinkClicked(linkID uint, userAgent, ipAddress, referer, source string) error {
+	event := newLinkClickedEvent(linkID, userAgent, ipAddress, referer, source)
+	event.ID = memoryEventID(b.seq.Add(1))
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBusClosed
+	}
+
+	select {
+	case b.queue <- event:
+		return nil
+	default:
+		b.dropped.Add(1)
+		return ErrBufferFull
+	}
+}
+
+// SubscribeLinkClicked возвращает канал событий; подписчик может быть один
+func (b *MemoryBus) SubscribeLinkClicked() (<-chan statUC.LinkClickedEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.subscribed {
+		return nil, ErrAlreadySubscribed
+	}
+	b.subscribed = true
+	return b.queue, nil
+}
+
+// Ack подтверждает обработку: в памяти подтверждать нечего
+func (b *MemoryBus) Ack(events ...statUC.LinkClickedEvent) error {
+	return nil
+}
+
+// Nack планирует повтор события или отправляет его в dead-letter
+func (b *MemoryBus) Nack(event statUC.LinkClickedEvent, cause error) error {
+	event.Attempt++
+	if event.Attempt >= b.config.MaxAttempts {
+		return saveDeadLetter(b.deadLetters, event, cause)
+	}
+
+	b.mu.Lock()
+	if b.drained {
+		// Очередь уже закрыта - повторить событие некому
+		b.mu.Unlock()
+		return saveDeadLetter(b.deadLetters, event, cause)
+	}
+	b.pending++
+	b.mu.Unlock()
+
+	delay := b.config.RetryDelay * time.Duration(event.Attempt)
+	time.AfterFunc(delay, func() {
+		select {
+		case b.queue <- event:
+		case <-b.abort:
+			saveDeadLetter(b.deadLetters, event, cause)
+		}
+
+		b.mu.Lock()
+		b.pending--
+		b.mu.Unlock()
+
+		select {
+		case b.retryDone <- struct{}{}:
+		default:
+		}
+	})
+	return nil
+}
+
+// Drain корректно останавливает шину
+//
+// Публикация прекращается сразу, запланированные повторы попадают в
+// очередь, после чего канал подписчика закрывается. Если ctx истекает
+// раньше, оставшиеся повторы сохраняются в dead-letter.
+func (b *MemoryBus) Drain(ctx context.Context) error {
+	b.mu.Lock()
+	if b.drained {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	b.mu.Unlock()
+
+	var err error
+	done := ctx.Done()
+	for {
+		b.mu.Lock()
+		if b.drained {
+			// Очередь закрыл параллельный вызов Drain
+			b.mu.Unlock()
+			return err
+		}
+		if b.pending == 0 {
+			b.drained = true
+			close(b.queue)
+			b.mu.Unlock()
+			return err
+		}
+		b.mu.Unlock()
+
+		select {
+		case <-b.retryDone:
+		case <-done:
+			err = ctx.Err()
+			b.abortOnce.Do(func() { close(b.abort) })
+			done = nil
+		}
+	}
+}
+
+// Close останавливает шину без ожидания повторов
+func (b *MemoryBus) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Drain(ctx); err != nil && err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+// Dropped возвращает количество событий, отброшенных из-за полного буфера
+func (b *MemoryBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
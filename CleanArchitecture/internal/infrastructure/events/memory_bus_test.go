@@ -0,0 +1,164 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"clean-url-shortener/internal/domain/stat"
+	statUC "clean-url-shortener/internal/usecase/stat"
+)
+
+// receive читает событие из канала с таймаутом
+func receive(t *testing.T, ch <-chan statUC.LinkClickedEvent) statUC.LinkClickedEvent {
+	t.Helper()
+	select {
+	case event, ok := <-ch:
+		if !ok {
+			t.Fatal("channel closed")
+		}
+		return event
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for event")
+	}
+	return statUC.LinkClickedEvent{}
+}
+
+func TestMemoryBusRetriesThenDeadLetters(t *testing.T) {
+	deadLetters := NewMemoryDeadLetterStore()
+	bus := NewMemoryBus(Config{MaxAttempts: 2, RetryDelay: time.Millisecond}, deadLetters)
+	ch, err := bus.SubscribeLinkClicked()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := bus.PublishLinkClicked(7, "agent", "10.0.0.1", "https://t.me", "qr"); err != nil {
+		t.Fatalf("PublishLinkClicked() error = %v", err)
+	}
+
+	first := receive(t, ch)
+	if first.ID == "" || first.LinkID != 7 || first.Source != "qr" || first.Timestamp == "" {
+		t.Fatalf("event = %+v", first)
+	}
+	if err := bus.Nack(first, errors.New("db down")); err != nil {
+		t.Fatal(err)
+	}
+
+	retried := receive(t, ch)
+	if retried.ID != first.ID || retried.Attempt != 1 || retried.Timestamp != first.Timestamp {
+		t.Fatalf("retried = %+v, want same event with Attempt 1", retried)
+	}
+	if err := bus.Nack(retried, errors.New("db down")); err != nil {
+		t.Fatal(err)
+	}
+
+	letters, _ := deadLetters.ListDeadLetters(context.Background(), 10)
+	if len(letters) != 1 || letters[0].Event.LinkID != 7 || letters[0].Error != "db down" {
+		t.Errorf("dead letters = %+v", letters)
+	}
+}
+
+func TestMemoryBusDropsWhenFullAndDrains(t *testing.T) {
+	bus := NewMemoryBus(Config{BufferSize: 2}, NewMemoryDeadLetterStore())
+	ch, _ := bus.SubscribeLinkClicked()
+
+	for i := uint(1); i <= 2; i++ {
+		if err := bus.PublishLinkClicked(i, "", "", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := bus.PublishLinkClicked(3, "", "", "", ""); !errors.Is(err, ErrBufferFull) {
+		t.Errorf("PublishLinkClicked() on full buffer = %v, want ErrBufferFull", err)
+	}
+
+	if err := bus.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if err := bus.PublishLinkClicked(4, "", "", "", ""); !errors.Is(err, ErrBusClosed) {
+		t.Errorf("PublishLinkClicked() after Drain = %v, want ErrBusClosed", err)
+	}
+
+	var got []uint
+	for event := range ch {
+		got = append(got, event.LinkID)
+	}
+	if len(got) != 2 || bus.Dropped() != 1 {
+		t.Errorf("drained %v, dropped %d; want 2 events and 1 dropped", got, bus.Dropped())
+	}
+}
+
+// flakyStatRepository сохраняет клики в памяти и отказывает для одной ссылки
+type flakyStatRepository struct {
+	stat.Repository
+	mu       sync.Mutex
+	saved    []*stat.Stat
+	failLink uint
+}
+
+func (r *flakyStatRepository) Save(ctx context.Context, s *stat.Stat) error {
+	return r.SaveBatch(ctx, []*stat.Stat{s})
+}
+
+func (r *flakyStatRepository) SaveBatch(ctx context.Context, stats []*stat.Stat) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range stats {
+		if s.LinkID == r.failLink {
+			return errors.New("constraint violation")
+		}
+	}
+	r.saved = append(r.saved, stats...)
+	return nil
+}
+
+func TestTrackClickUseCaseDrainsMemoryBus(t *testing.T) {
+	repo := &flakyStatRepository{failLink: 13}
+	deadLetters := NewMemoryDeadLetterStore()
+	bus := NewMemoryBus(Config{MaxAttempts: 3, RetryDelay: time.Millisecond}, deadLetters)
+	tracker := statUC.NewTrackClickUseCase(repo, nil, bus, statUC.TrackingConfig{
+		BatchSize:     10,
+		FlushInterval: time.Hour, // пачки сохраняются только по размеру и при остановке
+	})
+	if err := tracker.StartTracking(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, linkID := range []uint{1, 2, 13, 3} {
+		if err := bus.PublishLinkClicked(linkID, "agent", "10.0.0.1", "", "QR"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := bus.Drain(ctx); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	select {
+	case <-tracker.Done():
+	case <-ctx.Done():
+		t.Fatal("tracker did not stop after Drain")
+	}
+
+	repo.mu.Lock()
+	saved := repo.saved
+	repo.mu.Unlock()
+	if len(saved) != 3 {
+		t.Errorf("saved %d stats, want 3", len(saved))
+	}
+	// Метка канала из события нормализуется и сохраняется
+	for _, s := range saved {
+		if s.Source != "qr" {
+			t.Errorf("stat for link %d has source %q, want qr", s.LinkID, s.Source)
+		}
+	}
+
+	// Последняя пачка обработана после закрытия очереди - повторять
+	// ее некому, поэтому событие сразу попадает в dead-letter
+	letters, _ := deadLetters.ListDeadLetters(context.Background(), 10)
+	if len(letters) != 1 || letters[0].Event.LinkID != 13 {
+		t.Errorf("dead letters = %+v, want link 13", letters)
+	}
+}
@@ -0,0 +1,488 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	linkUC "clean-url-shortener/internal/usecase/link"
+	statUC "clean-url-shortener/internal/usecase/stat"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Проверка реализации интерфейсов use case слоя
+var (
+	_ linkUC.EventPublisher  = (*RedisStreamBus)(nil)
+	_ statUC.EventSubscriber = (*RedisStreamBus)(nil)
+)
+
+// eventField поле сообщения потока с JSON события
+const eventField = "event"
+
+// RedisConfig настройки шины на Redis Streams
+type RedisConfig struct {
+	Config
+
+	// Stream - имя потока, Group - группа потребителей
+	Stream string
+	Group  string
+
+	// Consumer - имя потребителя в группе, уникальное для процесса
+	Consumer string
+
+	// BatchSize - сколько событий отправляется одним pipeline и читается за раз
+	BatchSize int
+
+	// FlushInterval - как часто отправляется неполная пачка
+	FlushInterval time.Duration
+
+	// Block - сколько ждать новые сообщения в XREADGROUP
+	Block time.Duration
+
+	// ClaimMinIdle - через сколько неподтвержденное сообщение забирается
+	// у упавшего потребителя (XAUTOCLAIM)
+	ClaimMinIdle time.Duration
+
+	// MaxLen - примерная длина потока, старые сообщения обрезаются
+	MaxLen int64
+}
+
+// withDefaults заполняет незаданные настройки
+func (c RedisConfig) withDefaults() RedisConfig {
+	c.Config = c.Config.withDefaults()
+	if c.Stream == "" {
+		c.Stream = "link_clicks"
+	}
+	if c.Group == "" {
+		c.Group = "stats"
+	}
+	if c.Consumer == "" {
+		c.Consumer = "consumer-1"
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = 100 * time.Millisecond
+	}
+	if c.Block <= 0 {
+		c.Block = time.Second
+	}
+	if c.ClaimMinIdle <= 0 {
+		c.ClaimMinIdle = time.Minute
+	}
+	if c.MaxLen <= 0 {
+		c.MaxLen = 1000000
+	}
+	return c
+}
+
+// RedisStreamBus шина событий на Redis Streams с группой потребителей
+//
+// Публикация:
+//
+//	PublishLinkClicked → буфер в памяти → пачка XADD одним pipeline
+//
+// Redirect не ждет сети: событие кладется в буфер, отправкой занимается
+// фоновая горутина. Если Redis недоступен, пачка повторяется MaxAttempts
+// раз, затем события сохраняются в dead-letter.
+//
+// Чтение и подтверждение:
+//
+//	XREADGROUP ">" → подписчик → Ack (XACK) | Nack (XADD attempt+1, XACK)
+//
+// Сообщение, прочитанное, но не подтвержденное (процесс упал), остается
+// в pending списке группы и через ClaimMinIdle забирается XAUTOCLAIM
+// любым потребителем - так обеспечивается доставка "хотя бы один раз".
+type RedisStreamBus struct {
+	client      redis.UniversalClient
+	config      RedisConfig
+	deadLetters statUC.DeadLetterStore
+
+	outbox      chan statUC.LinkClickedEvent
+	publishDone chan struct{}
+	abort       chan struct{}
+	abortOnce   sync.Once
+
+	mu         sync.Mutex
+	closed     bool
+	subscribed bool
+	readCancel context.CancelFunc
+	readDone   chan struct{}
+
+	dropped atomic.Uint64
+}
+
+// NewRedisStreamBus создает шину и запускает отправку событий в поток
+func NewRedisStreamBus(client redis.UniversalClient, config RedisConfig, deadLetters statUC.DeadLetterStore) *RedisStreamBus {
+	config = config.withDefaults()
+	b := &RedisStreamBus{
+		client:      client,
+		config:      config,
+		deadLetters: deadLetters,
+		outbox:      make(chan statUC.LinkClickedEvent, config.BufferSize),
+		publishDone: make(chan struct{}),
+		abort:       make(chan struct{}),
+	}
+	go b.publishLoop()
+	return b
+}
+
+// PublishLinkClicked ставит событие в буфер отправки без ожидания
+func (b *RedisStreamBus) PublishLinkClicked(linkID uint, userAgent, ipAddress, referer, source string) error {
+	event := newLinkClickedEvent(linkID, userAgent, ipAddress, referer, source)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBusClosed
+	}
+
+	select {
+	case b.outbox <- event:
+		return nil
+	default:
+		b.dropped.Add(1)
+		return ErrBufferFull
+	}
+}
+
+// publishLoop отправляет события из буфера пачками
+func (b *RedisStreamBus) publishLoop() {
+	defer close(b.publishDone)
+
+	batch := make([]statUC.LinkClickedEvent, 0, b.config.BatchSize)
+	ticker := time.NewTicker(b.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-b.outbox:
+			if !ok {
+				b.sendBatch(batch)
+				return
+			}
+			batch = append(batch, event)
+			if len(batch) >= b.config.BatchSize {
+				b.sendBatch(batch)
+				batch = batch[:0]
+			}
+
+		case <-ticker.C:
+			if len(batch) > 0 {
+				b.sendBatch(batch)
+				batch = batch[:0]
+			}
+		}
+	}
+}
+
+// sendBatch отправляет пачку с повторами; неотправленное - в dead-letter
+func (b *RedisStreamBus) sendBatch(batch []statUC.LinkClickedEvent) {
+	remaining := batch
+	var lastErr error
+
+	for attempt := 1; attempt <= b.config.MaxAttempts && len(remaining) > 0; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-time.After(b.config.RetryDelay * time.Duration(attempt-1)):
+			case <-b.abort:
+				attempt = b.config.MaxAttempts
+				continue
+			}
+		}
+		remaining, lastErr = b.add(remaining)
+	}
+
+	if len(remaining) > 0 {
+		log.Printf("Failed to publish %d click events to %s: %v", len(remaining), b.config.Stream, lastErr)
+		for _, event := range remaining {
+			saveDeadLetter(b.deadLetters, event, fmt.Errorf("publish: %w", lastErr))
+		}
+	}
+}
+
+// add выполняет XADD пачки одним pipeline и возвращает неотправленные события
+func (b *RedisStreamBus) add(batch []statUC.LinkClickedEvent) ([]statUC.LinkClickedEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pipe := b.client.Pipeline()
+	cmds := make([]*redis.StringCmd, len(batch))
+	for i, event := range batch {
+		cmds[i] = b.xadd(ctx, pipe, event)
+	}
+	_, err := pipe.Exec(ctx)
+	if err == nil {
+		return nil, nil
+	}
+
+	var failed []statUC.LinkClickedEvent
+	for i, cmd := range cmds {
+		if cmd.Err() != nil {
+			failed = append(failed, batch[i])
+		}
+	}
+	return failed, err
+}
+
+// xadd добавляет команду XADD для события; ID назначает Redis
+func (b *RedisStreamBus) xadd(ctx context.Context, pipe redis.Pipeliner, event statUC.LinkClickedEvent) *redis.StringCmd {
+	event.ID = ""
+	data, _ := json.Marshal(event)
+
+	return pipe.XAdd(ctx, &redis.XAddArgs{
+		Stream: b.config.Stream,
+		MaxLen: b.config.MaxLen,
+		Approx: true,
+		Values: map[string]interface{}{eventField: data},
+	})
+}
+
+// SubscribeLinkClicked создает группу потребителей и начинает чтение потока
+func (b *RedisStreamBus) SubscribeLinkClicked() (<-chan statUC.LinkClickedEvent, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, ErrBusClosed
+	}
+	if b.subscribed {
+		return nil, ErrAlreadySubscribed
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Группа читает поток с начала, чтобы не потерять события,
+	// опубликованные до первого запуска потребителя
+	err := b.client.XGroupCreateMkStream(ctx, b.config.Stream, b.config.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return nil, fmt.Errorf("failed to create consumer group: %w", err)
+	}
+
+	readCtx, readCancel := context.WithCancel(context.Background())
+	out := make(chan statUC.LinkClickedEvent, b.config.BatchSize)
+
+	b.subscribed = true
+	b.readCancel = readCancel
+	b.readDone = make(chan struct{})
+	go b.readLoop(readCtx, out)
+
+	return out, nil
+}
+
+// readLoop читает новые и зависшие сообщения группы
+func (b *RedisStreamBus) readLoop(ctx context.Context, out chan<- statUC.LinkClickedEvent) {
+	defer close(b.readDone)
+	defer close(out)
+
+	var lastClaim time.Time
+	for ctx.Err() == nil {
+		// Зависшие сообщения упавших потребителей проверяем не чаще,
+		// чем раз в половину ClaimMinIdle
+		if time.Since(lastClaim) >= b.config.ClaimMinIdle/2 {
+			lastClaim = time.Now()
+			if !b.claim(ctx, out) {
+				return
+			}
+		}
+
+		streams, err := b.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    b.config.Group,
+			Consumer: b.config.Consumer,
+			Streams:  []string{b.config.Stream, ">"},
+			Count:    int64(b.config.BatchSize),
+			Block:    b.config.Block,
+		}).Result()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			log.Printf("Failed to read click events from %s: %v", b.config.Stream, err)
+			b.sleep(ctx, b.config.RetryDelay)
+			continue
+		}
+
+		for _, stream := range streams {
+			if !b.deliver(ctx, out, stream.Messages) {
+				return
+			}
+		}
+	}
+}
+
+// claim забирает сообщения, не подтвержденные дольше ClaimMinIdle
+func (b *RedisStreamBus) claim(ctx context.Context, out chan<- statUC.LinkClickedEvent) bool {
+	start := "0-0"
+	for {
+		messages, next, err := b.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   b.config.Stream,
+			Group:    b.config.Group,
+			Consumer: b.config.Consumer,
+			MinIdle:  b.config.ClaimMinIdle,
+			Start:    start,
+			Count:    int64(b.config.BatchSize),
+		}).Result()
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Printf("Failed to claim pending click events from %s: %v", b.config.Stream, err)
+			}
+			return ctx.Err() == nil
+		}
+
+		if !b.deliver(ctx, out, messages) {
+			return false
+		}
+		if next == "0-0" || len(messages) == 0 {
+			return true
+		}
+		start = next
+	}
+}
+
+// deliver декодирует сообщения и передает их подписчику
+// Возвращает false, если чтение остановлено
+func (b *RedisStreamBus) deliver(ctx context.Context, out chan<- statUC.LinkClickedEvent, messages []redis.XMessage) bool {
+	for _, msg := range messages {
+		event, err := decodeEvent(msg)
+		if err != nil {
+			// Нечитаемое сообщение не исправится повтором
+			saveDeadLetter(b.deadLetters, event, err)
+			b.ack(msg.ID)
+			continue
+		}
+
+		select {
+		case out <- event:
+		case <-ctx.Done():
+			// Сообщение остается в pending и будет забрано после перезапуска
+			return false
+		}
+	}
+	return true
+}
+
+// decodeEvent восстанавливает событие из сообщения потока
+func decodeEvent(msg redis.XMessage) (statUC.LinkClickedEvent, error) {
+	event := statUC.LinkClickedEvent{ID: msg.ID}
+
+	raw, ok := msg.Values[eventField].(string)
+	if !ok {
+		return event, fmt.Errorf("message %s has no %q field", msg.ID, eventField)
+	}
+	if err := json.Unmarshal([]byte(raw), &event); err != nil {
+		return event, fmt.Errorf("message %s: %w", msg.ID, err)
+	}
+	event.ID = msg.ID
+	return event, nil
+}
+
+// Ack подтверждает обработку сообщений (XACK)
+func (b *RedisStreamBus) Ack(events ...statUC.LinkClickedEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	ids := make([]string, len(events))
+	for i, event := range events {
+		ids[i] = event.ID
+	}
+	return b.ack(ids...)
+}
+
+// ack выполняет XACK
+func (b *RedisStreamBus) ack(ids ...string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	return b.client.XAck(ctx, b.config.Stream, b.config.Group, ids...).Err()
+}
+
+// Nack переотправляет событие в поток со следующим номером попытки
+// или, после MaxAttempts, сохраняет его в dead-letter
+func (b *RedisStreamBus) Nack(event statUC.LinkClickedEvent, cause error) error {
+	event.Attempt++
+	if event.Attempt >= b.config.MaxAttempts {
+		if err := saveDeadLetter(b.deadLetters, event, cause); err != nil {
+			// Без подтверждения сообщение вернется через XAUTOCLAIM
+			return err
+		}
+		return b.ack(event.ID)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// Новая копия и подтверждение старой - атомарно в MULTI
+	_, err := b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		b.xadd(ctx, pipe, event)
+		pipe.XAck(ctx, b.config.Stream, b.config.Group, event.ID)
+		return nil
+	})
+	return err
+}
+
+// Drain корректно останавливает шину
+//
+// Прекращает прием событий, отправляет буфер в поток и останавливает
+// чтение; канал подписчика закрывается после текущего XREADGROUP.
+// Redis клиент не закрывается: подписчик еще подтверждает последнюю пачку.
+func (b *RedisStreamBus) Drain(ctx context.Context) error {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.outbox)
+	}
+	readCancel, readDone := b.readCancel, b.readDone
+	b.mu.Unlock()
+
+	var err error
+	select {
+	case <-b.publishDone:
+	case <-ctx.Done():
+		err = ctx.Err()
+		// Оставшиеся события уйдут в dead-letter без повторов
+		b.abortOnce.Do(func() { close(b.abort) })
+		<-b.publishDone
+	}
+
+	if readCancel != nil {
+		readCancel()
+		<-readDone
+	}
+	return err
+}
+
+// Close останавливает шину без ожидания повторов отправки
+func (b *RedisStreamBus) Close() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := b.Drain(ctx); err != nil && err != context.Canceled {
+		return err
+	}
+	return nil
+}
+
+// Dropped возвращает количество событий, отброшенных из-за полного буфера
+func (b *RedisStreamBus) Dropped() uint64 {
+	return b.dropped.Load()
+}
+
+// sleep ждет d или отмены ctx
+func (b *RedisStreamBus) sleep(ctx context.Context, d time.Duration) {
+	select {
+	case <-time.After(d):
+	case <-ctx.Done():
+	}
+}
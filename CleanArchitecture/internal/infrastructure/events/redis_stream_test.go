@@ -0,0 +1,107 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// newTestRedis поднимает Redis в памяти процесса
+func newTestRedis(t *testing.T) *redis.Client {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func testRedisConfig(consumer string) RedisConfig {
+	return RedisConfig{
+		Config:        Config{MaxAttempts: 3, RetryDelay: 10 * time.Millisecond},
+		Stream:        "clicks_test",
+		Group:         "stats",
+		Consumer:      consumer,
+		FlushInterval: 5 * time.Millisecond,
+		Block:         50 * time.Millisecond,
+	}
+}
+
+func TestRedisStreamBusAckAndNack(t *testing.T) {
+	client := newTestRedis(t)
+	deadLetters := NewMemoryDeadLetterStore()
+	bus := NewRedisStreamBus(client, testRedisConfig("a"), deadLetters)
+	defer bus.Close()
+
+	ch, err := bus.SubscribeLinkClicked()
+	if err != nil {
+		t.Fatalf("SubscribeLinkClicked() error = %v", err)
+	}
+	for _, linkID := range []uint{1, 2} {
+		if err := bus.PublishLinkClicked(linkID, "agent", "10.0.0.1", "", ""); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	first, second := receive(t, ch), receive(t, ch)
+	if first.LinkID != 1 || second.LinkID != 2 || first.ID == "" {
+		t.Fatalf("events = %+v, %+v", first, second)
+	}
+	if err := bus.Ack(first); err != nil {
+		t.Fatalf("Ack() error = %v", err)
+	}
+	if err := bus.Nack(second, errors.New("db down")); err != nil {
+		t.Fatalf("Nack() error = %v", err)
+	}
+
+	retried := receive(t, ch)
+	if retried.LinkID != 2 || retried.Attempt != 1 || retried.Timestamp != second.Timestamp {
+		t.Fatalf("retried = %+v, want link 2 with Attempt 1", retried)
+	}
+	if err := bus.Ack(retried); err != nil {
+		t.Fatal(err)
+	}
+
+	pending, err := client.XPending(context.Background(), "clicks_test", "stats").Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending.Count != 0 {
+		t.Errorf("pending = %d, want 0 after ack", pending.Count)
+	}
+}
+
+func TestRedisStreamBusClaimsUnackedMessages(t *testing.T) {
+	client := newTestRedis(t)
+
+	crashed := NewRedisStreamBus(client, testRedisConfig("a"), NewMemoryDeadLetterStore())
+	ch, err := crashed.SubscribeLinkClicked()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := crashed.PublishLinkClicked(5, "agent", "10.0.0.1", "", ""); err != nil {
+		t.Fatal(err)
+	}
+	lost := receive(t, ch)
+	// Потребитель "упал", не подтвердив сообщение
+	crashed.Close()
+
+	config := testRedisConfig("b")
+	config.ClaimMinIdle = 20 * time.Millisecond
+	time.Sleep(2 * config.ClaimMinIdle)
+
+	bus := NewRedisStreamBus(client, config, NewMemoryDeadLetterStore())
+	defer bus.Close()
+	ch, err = bus.SubscribeLinkClicked()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claimed := receive(t, ch)
+	if claimed.ID != lost.ID || claimed.LinkID != 5 {
+		t.Errorf("claimed = %+v, want %+v", claimed, lost)
+	}
+}
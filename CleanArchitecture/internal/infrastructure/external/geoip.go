@@ -0,0 +1,226 @@
+package external
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	statUC "clean-url-shortener/internal/usecase/stat"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// Проверка реализации интерфейса из usecase слоя
+var _ statUC.GeoLocationService = (*MMDBGeoLocationService)(nil)
+
+// GeoIPConfig настройки геолокации по локальной базе MaxMind
+type GeoIPConfig struct {
+	// Path - путь к файлу .mmdb (GeoLite2-City, GeoIP2-City и т.п.)
+	Path string
+
+	// CacheSize - сколько IP адресов хранится в LRU кеше
+	CacheSize int
+
+	// ReloadInterval - как часто проверяется изменение файла
+	// 0 - файл не перечитывается
+	ReloadInterval time.Duration
+
+	// Language - язык названия города
+	Language string
+}
+
+// MMDBGeoLocationService реализует интерфейс GeoLocationService
+// по файлу в формате MaxMind DB без обращения к внешним сервисам
+//
+// Страна возвращается ISO кодом ("RU"), город - названием на Language
+// (или на английском, если перевода нет). Результаты кешируются в LRU;
+// при замене файла (обновление базы) он перечитывается, а кеш очищается.
+type MMDBGeoLocationService struct {
+	config GeoIPConfig
+	cache  *lru.Cache[string, location]
+
+	mu      sync.RWMutex
+	reader  *maxminddb.Reader
+	gen     uint64 // номер загрузки базы, защищает кеш от старых ответов
+	modTime time.Time
+	size    int64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// location результат поиска для кеша
+type location struct {
+	country string
+	city    string
+}
+
+// cityRecord поля записи GeoIP2/GeoLite2 City, которые нам нужны
+type cityRecord struct {
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+}
+
+// NewMMDBGeoLocationService открывает базу и запускает отслеживание изменений файла
+func NewMMDBGeoLocationService(config GeoIPConfig) (*MMDBGeoLocationService, error) {
+	if config.CacheSize <= 0 {
+		config.CacheSize = 10000
+	}
+	if config.Language == "" {
+		config.Language = "en"
+	}
+
+	cache, err := lru.New[string, location](config.CacheSize)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &MMDBGeoLocationService{
+		config: config,
+		cache:  cache,
+		stop:   make(chan struct{}),
+	}
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	if config.ReloadInterval > 0 {
+		s.wg.Add(1)
+		go s.watch()
+	}
+	return s, nil
+}
+
+// GetLocation определяет страну и город по IP адресу
+// Адрес, которого нет в базе, не ошибка: возвращаются пустые строки
+func (s *MMDBGeoLocationService) GetLocation(ipAddress string) (country, city string, err error) {
+	ip := net.ParseIP(ipAddress)
+	if ip == nil {
+		return "", "", fmt.Errorf("invalid IP address: %q", ipAddress)
+	}
+
+	key := ip.String()
+	if cached, ok := s.cache.Get(key); ok {
+		return cached.country, cached.city, nil
+	}
+
+	var record cityRecord
+	s.mu.RLock()
+	err = s.reader.Lookup(ip, &record)
+	gen := s.gen
+	s.mu.RUnlock()
+	if err != nil {
+		return "", "", err
+	}
+
+	loc := location{
+		country: record.Country.ISOCode,
+		city:    record.City.Names[s.config.Language],
+	}
+	if loc.city == "" {
+		loc.city = record.City.Names["en"]
+	}
+
+	// Пока мы искали, базу могли перечитать - такой ответ не кешируем
+	s.mu.RLock()
+	if s.gen == gen {
+		s.cache.Add(key, loc)
+	}
+	s.mu.RUnlock()
+
+	return loc.country, loc.city, nil
+}
+
+// IsValidIP проверяет валидность IP адреса
+func (s *MMDBGeoLocationService) IsValidIP(ipAddress string) bool {
+	return net.ParseIP(ipAddress) != nil
+}
+
+// Reload перечитывает файл базы, если он изменился
+func (s *MMDBGeoLocationService) Reload() error {
+	info, err := os.Stat(s.config.Path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	unchanged := info.ModTime().Equal(s.modTime) && info.Size() == s.size
+	s.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	return s.load()
+}
+
+// load открывает файл и подменяет текущую базу
+func (s *MMDBGeoLocationService) load() error {
+	info, err := os.Stat(s.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+
+	reader, err := maxminddb.Open(s.config.Path)
+	if err != nil {
+		return fmt.Errorf("failed to open GeoIP database: %w", err)
+	}
+	if reader.Metadata.NodeCount == 0 {
+		reader.Close()
+		return errors.New("failed to open GeoIP database: empty search tree")
+	}
+
+	s.mu.Lock()
+	old := s.reader
+	s.reader = reader
+	s.gen++
+	s.modTime = info.ModTime()
+	s.size = info.Size()
+	// Закешированные ответы относятся к старой базе
+	s.cache.Purge()
+	s.mu.Unlock()
+
+	if old != nil {
+		old.Close()
+	}
+	return nil
+}
+
+// watch периодически проверяет, не заменили ли файл базы
+func (s *MMDBGeoLocationService) watch() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+			// Файл мог быть заменен не до конца - оставляем старую базу
+			// и пробуем на следующем тике
+			if err := s.Reload(); err != nil {
+				log.Printf("GeoIP reload failed, keeping previous database: %v", err)
+			}
+		}
+	}
+}
+
+// Close останавливает отслеживание файла и закрывает базу
+func (s *MMDBGeoLocationService) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.reader.Close()
+}
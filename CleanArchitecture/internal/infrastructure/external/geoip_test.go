@@ -0,0 +1,200 @@
+package external
+
+import (
+	"bytes"
+	"encoding/binary"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// Минимальный writer формата MaxMind DB для тестов: IPv4 дерево с
+// record_size 24 и записями вида {"country": {...}, "city": {...}}
+
+type testCity struct {
+	network string
+	country string
+	names   map[string]string
+}
+
+type trieNode struct {
+	child [2]*trieNode
+	leaf  bool
+	data  int // смещение записи в секции данных
+}
+
+func mmdbControl(typ, size int) []byte {
+	var out []byte
+	if typ <= 7 {
+		out = append(out, byte(typ<<5))
+	} else {
+		// Расширенный тип: тип 0 в управляющем байте, затем байт (тип - 7)
+		out = append(out, 0, byte(typ-7))
+	}
+	if size < 29 {
+		out[0] |= byte(size)
+		return out
+	}
+	out[0] |= 29
+	return append(out, byte(size-29))
+}
+
+func mmdbString(s string) []byte {
+	return append(mmdbControl(2, len(s)), s...)
+}
+
+func mmdbUint(typ int, v uint64) []byte {
+	var raw []byte
+	for ; v > 0; v >>= 8 {
+		raw = append([]byte{byte(v)}, raw...)
+	}
+	return append(mmdbControl(typ, len(raw)), raw...)
+}
+
+func mmdbMap(keys []string, values [][]byte) []byte {
+	out := mmdbControl(7, len(keys))
+	for i, key := range keys {
+		out = append(out, mmdbString(key)...)
+		out = append(out, values[i]...)
+	}
+	return out
+}
+
+func mmdbNames(names map[string]string) []byte {
+	var keys []string
+	var values [][]byte
+	for lang, name := range names {
+		keys = append(keys, lang)
+		values = append(values, mmdbString(name))
+	}
+	return mmdbMap(keys, values)
+}
+
+// writeTestMMDB записывает базу с городами cities в path
+func writeTestMMDB(t *testing.T, path string, cities []testCity) {
+	t.Helper()
+
+	var data []byte
+	root := &trieNode{}
+	for _, c := range cities {
+		_, network, err := net.ParseCIDR(c.network)
+		if err != nil {
+			t.Fatal(err)
+		}
+		offset := len(data)
+		data = append(data, mmdbMap(
+			[]string{"country", "city"},
+			[][]byte{
+				mmdbMap([]string{"iso_code"}, [][]byte{mmdbString(c.country)}),
+				mmdbMap([]string{"names"}, [][]byte{mmdbNames(c.names)}),
+			},
+		)...)
+
+		ones, _ := network.Mask.Size()
+		ip := network.IP.To4()
+		node := root
+		for i := 0; i < ones; i++ {
+			bit := (ip[i/8] >> (7 - i%8)) & 1
+			if node.child[bit] == nil {
+				node.child[bit] = &trieNode{}
+			}
+			node = node.child[bit]
+		}
+		node.leaf, node.data = true, offset
+	}
+
+	// Нумеруем внутренние узлы обходом в ширину
+	var nodes []*trieNode
+	ids := map[*trieNode]int{}
+	for queue := []*trieNode{root}; len(queue) > 0; queue = queue[1:] {
+		n := queue[0]
+		ids[n] = len(nodes)
+		nodes = append(nodes, n)
+		for _, child := range n.child {
+			if child != nil && !child.leaf {
+				queue = append(queue, child)
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	nodeCount := len(nodes)
+	for _, n := range nodes {
+		for _, child := range n.child {
+			record := nodeCount // пустая запись
+			switch {
+			case child == nil:
+			case child.leaf:
+				record = nodeCount + 16 + child.data
+			default:
+				record = ids[child]
+			}
+			var b [4]byte
+			binary.BigEndian.PutUint32(b[:], uint32(record))
+			buf.Write(b[1:])
+		}
+	}
+	buf.Write(make([]byte, 16))
+	buf.Write(data)
+	buf.WriteString("\xAB\xCD\xEFMaxMind.com")
+	buf.Write(mmdbMap(
+		[]string{"node_count", "record_size", "ip_version", "database_type",
+			"binary_format_major_version", "binary_format_minor_version", "build_epoch"},
+		[][]byte{
+			mmdbUint(6, uint64(nodeCount)), mmdbUint(5, 24), mmdbUint(5, 4), mmdbString("Test-City"),
+			mmdbUint(5, 2), mmdbUint(5, 0), mmdbUint(9, 1700000000),
+		},
+	))
+
+	// Замена файла целиком, как при обновлении базы
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf.Bytes(), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMMDBGeoLocationServiceLookupAndReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "city.mmdb")
+	writeTestMMDB(t, path, []testCity{
+		{"81.2.69.0/24", "RU", map[string]string{"en": "Moscow", "ru": "Москва"}},
+		{"89.160.0.0/16", "SE", map[string]string{"en": "Linkoping"}},
+	})
+
+	geo, err := NewMMDBGeoLocationService(GeoIPConfig{Path: path, CacheSize: 16, Language: "ru"})
+	if err != nil {
+		t.Fatalf("NewMMDBGeoLocationService() error = %v", err)
+	}
+	defer geo.Close()
+
+	tests := []struct {
+		ip, country, city string
+	}{
+		{"81.2.69.160", "RU", "Москва"},
+		{"89.160.20.112", "SE", "Linkoping"}, // нет перевода - английское название
+		{"10.0.0.1", "", ""},                 // нет в базе
+	}
+	for _, tt := range tests {
+		country, city, err := geo.GetLocation(tt.ip)
+		if err != nil || country != tt.country || city != tt.city {
+			t.Errorf("GetLocation(%s) = %q, %q, %v; want %q, %q", tt.ip, country, city, err, tt.country, tt.city)
+		}
+	}
+	if _, _, err := geo.GetLocation("not-an-ip"); err == nil {
+		t.Error("GetLocation(not-an-ip) error = nil")
+	}
+
+	// Обновленная база: адрес переехал, закешированный ответ должен сброситься
+	writeTestMMDB(t, path, []testCity{
+		{"81.2.69.0/24", "GB", map[string]string{"en": "London", "ru": "Лондон"}},
+	})
+	if err := geo.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if country, city, _ := geo.GetLocation("81.2.69.160"); country != "GB" || city != "Лондон" {
+		t.Errorf("GetLocation() after reload = %q, %q; want GB, Лондон", country, city)
+	}
+}
@@ -1,6 +1,8 @@
 package external
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"time"
 	
@@ -18,7 +20,7 @@ type JWTTokenGenerator struct {
 // NewJWTTokenGenerator создает новый JWT генератор
 func NewJWTTokenGenerator(secretKey, issuer string, expiry time.Duration) auth.TokenGenerator {
 	if expiry == 0 {
-		expiry = 24 * time.Hour // По умолчанию 24 часа
+		expiry = 15 * time.Minute // По умолчанию 15 минут: дольше живет refresh токен
 	}
 	
 	return &JWTTokenGenerator{
@@ -30,24 +32,34 @@ func NewJWTTokenGenerator(secretKey, issuer string, expiry time.Duration) auth.T
 
 // Claims структура для JWT claims
 type Claims struct {
-	UserID uint   `json:"user_id"`
-	Email  string `json:"email"`
+	UserID    uint   `json:"user_id"`
+	Email     string `json:"email"`
+	SessionID string `json:"sid,omitempty"` // семейство refresh токенов
 	jwt.RegisteredClaims
 }
 
 // Generate создает JWT токен для пользователя
-func (g *JWTTokenGenerator) Generate(userID uint, email string) (string, error) {
+func (g *JWTTokenGenerator) Generate(userID uint, email, sessionID string) (*auth.AccessToken, error) {
 	now := time.Now()
+	expiresAt := now.Add(g.expiry)
+	
+	// Уникальный ID токена (jti) - по нему токен можно отозвать
+	tokenID, err := newTokenID()
+	if err != nil {
+		return nil, err
+	}
 	
 	// Создаем claims
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		Email:     email,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID,
 			Issuer:    g.issuer,
 			Subject:   fmt.Sprintf("%d", userID),
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(g.expiry)),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
@@ -58,10 +70,14 @@ func (g *JWTTokenGenerator) Generate(userID uint, email string) (string, error)
 	// Подписываем токен
 	tokenString, err := token.SignedString(g.secretKey)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
+		return nil, fmt.Errorf("failed to sign token: %w", err)
 	}
 	
-	return tokenString, nil
+	return &auth.AccessToken{
+		Token:     tokenString,
+		ID:        tokenID,
+		ExpiresAt: expiresAt,
+	}, nil
 }
 
 // Validate проверяет валидность токена и возвращает данные
@@ -91,10 +107,25 @@ func (g *JWTTokenGenerator) Validate(tokenString string) (*auth.TokenData, error
 	}
 	
 	// Возвращаем данные из токена
-	return &auth.TokenData{
-		UserID: claims.UserID,
-		Email:  claims.Email,
-	}, nil
+	data := &auth.TokenData{
+		UserID:    claims.UserID,
+		Email:     claims.Email,
+		TokenID:   claims.ID,
+		SessionID: claims.SessionID,
+	}
+	if claims.ExpiresAt != nil {
+		data.ExpiresAt = claims.ExpiresAt.Time
+	}
+	return data, nil
+}
+
+// newTokenID генерирует случайный идентификатор токена
+func newTokenID() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
 }
 
 // ПРИНЦИПЫ:
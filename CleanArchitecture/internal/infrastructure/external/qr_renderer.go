@@ -0,0 +1,308 @@
+package external
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg" // логотип может быть в JPEG
+	"image/png"
+	"os"
+	"strconv"
+	"strings"
+
+	linkUC "clean-url-shortener/internal/usecase/link"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/skip2/go-qrcode"
+)
+
+// Проверка реализации интерфейса из usecase слоя
+var _ linkUC.QRCodeRenderer = (*QRRenderer)(nil)
+
+// QRConfig настройки отрисовки QR кодов
+type QRConfig struct {
+	// LogoPath - PNG или JPEG логотип для наложения в центр; пусто - без логотипа
+	LogoPath string
+
+	// CacheSize - сколько готовых изображений хранится в LRU кеше
+	CacheSize int
+}
+
+// qrLogoShare доля стороны изображения (с рамкой), которую занимает подложка логотипа
+// Это ~6% модулей кода: уровень коррекции Q или H их восстанавливает
+const qrLogoShare = 0.2
+
+// QRRenderer реализует интерфейс QRCodeRenderer на чистом Go
+//
+// Матрицу модулей строит github.com/skip2/go-qrcode, а PNG и SVG
+// рисуются здесь: так поддерживаются свои цвета и логотип. Готовые
+// изображения кешируются по (адрес, параметры) - один и тот же код
+// для листовки запрашивают многократно.
+type QRRenderer struct {
+	cache   *lru.Cache[string, []byte]
+	logo    image.Image // nil - логотип не настроен
+	logoPNG []byte      // логотип для встраивания в SVG
+}
+
+// NewQRRenderer создает отрисовщик и загружает логотип
+func NewQRRenderer(config QRConfig) (*QRRenderer, error) {
+	if config.CacheSize <= 0 {
+		config.CacheSize = 256
+	}
+
+	cache, err := lru.New[string, []byte](config.CacheSize)
+	if err != nil {
+		return nil, err
+	}
+	r := &QRRenderer{cache: cache}
+
+	if config.LogoPath != "" {
+		if err := r.loadLogo(config.LogoPath); err != nil {
+			return nil, fmt.Errorf("failed to load QR logo: %w", err)
+		}
+	}
+	return r, nil
+}
+
+// loadLogo читает логотип и готовит его PNG копию для SVG
+func (r *QRRenderer) loadLogo(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	logo, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, logo); err != nil {
+		return err
+	}
+	r.logo = logo
+	r.logoPNG = buf.Bytes()
+	return nil
+}
+
+// Render возвращает изображение QR кода из кеша или рисует его
+func (r *QRRenderer) Render(content string, options linkUC.QROptions) ([]byte, error) {
+	if options.Logo && r.logo == nil {
+		return nil, linkUC.ErrQRLogoNotAvailable
+	}
+
+	key := qrCacheKey(content, options)
+	if data, ok := r.cache.Get(key); ok {
+		return data, nil
+	}
+
+	// ШАГ 1: Строим матрицу модулей вместе с обязательной рамкой
+	code, err := qrcode.New(content, qrRecoveryLevel(options.ECC))
+	if err != nil {
+		return nil, err
+	}
+	modules := code.Bitmap()
+
+	// ШАГ 2: Рисуем в нужном формате
+	var data []byte
+	if options.Format == linkUC.QRFormatSVG {
+		data = r.renderSVG(modules, options)
+	} else {
+		data, err = r.renderPNG(modules, options)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	r.cache.Add(key, data)
+	return data, nil
+}
+
+// renderPNG рисует модули целым числом пикселей и центрирует код
+// Нецелый масштаб дает модули разной ширины, которые хуже сканируются
+func (r *QRRenderer) renderPNG(modules [][]bool, options linkUC.QROptions) ([]byte, error) {
+	n := len(modules)
+	scale := options.Size / n
+	if scale < 1 {
+		scale = 1
+	}
+	size := options.Size
+	if n*scale > size {
+		size = n * scale
+	}
+	offset := (size - n*scale) / 2
+
+	// Без логотипа достаточно палитры из двух цветов (0 - фон) - файл меньше
+	palette := color.Palette{options.Background, options.Foreground}
+	img := image.NewPaletted(image.Rect(0, 0, size, size), palette)
+	for py := offset; py < offset+n*scale; py++ {
+		row := modules[(py-offset)/scale]
+		for px := offset; px < offset+n*scale; px++ {
+			if row[(px-offset)/scale] {
+				img.SetColorIndex(px, py, 1)
+			}
+		}
+	}
+
+	var out image.Image = img
+	if options.Logo {
+		rgba := image.NewRGBA(img.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), img, image.Point{}, draw.Src)
+
+		box := qrLogoBox(n)
+		pad := image.Rect(box.Min.X*scale, box.Min.Y*scale, box.Max.X*scale, box.Max.Y*scale).Add(image.Pt(offset, offset))
+		draw.Draw(rgba, pad, &image.Uniform{C: options.Background}, image.Point{}, draw.Src)
+
+		// Сам логотип - внутри подложки с отступом в один модуль
+		inner := pad.Inset(scale)
+		drawScaled(rgba, fitRect(inner, r.logo.Bounds()), r.logo)
+		out = rgba
+	}
+
+	var buf bytes.Buffer
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&buf, out); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// renderSVG рисует код в единицах модулей: viewBox масштабирует его до size
+// Каждая строка - набор горизонтальных отрезков из темных модулей
+func (r *QRRenderer) renderSVG(modules [][]bool, options linkUC.QROptions) []byte {
+	n := len(modules)
+
+	var logoBox image.Rectangle
+	if options.Logo {
+		logoBox = qrLogoBox(n)
+	}
+
+	var path strings.Builder
+	for y, row := range modules {
+		for x := 0; x < n; {
+			if !row[x] || image.Pt(x, y).In(logoBox) {
+				x++
+				continue
+			}
+			start := x
+			for x < n && row[x] && !image.Pt(x, y).In(logoBox) {
+				x++
+			}
+			fmt.Fprintf(&path, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" shape-rendering="crispEdges">`,
+		options.Size, options.Size, n, n)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`, n, n, hexColor(options.Background))
+	fmt.Fprintf(&buf, `<path fill="%s" d="%s"/>`, hexColor(options.Foreground), path.String())
+
+	if options.Logo {
+		inner := logoBox.Inset(1)
+		fmt.Fprintf(&buf, `<image x="%d" y="%d" width="%d" height="%d" preserveAspectRatio="xMidYMid meet" href="data:image/png;base64,%s"/>`,
+			inner.Min.X, inner.Min.Y, inner.Dx(), inner.Dy(), base64.StdEncoding.EncodeToString(r.logoPNG))
+	}
+	buf.WriteString("</svg>")
+	return buf.Bytes()
+}
+
+// qrLogoBox квадрат в центре кода (в модулях), который закрывает логотип с подложкой
+// Сторона той же четности, что и код, чтобы квадрат стоял ровно по центру
+func qrLogoBox(n int) image.Rectangle {
+	side := int(float64(n) * qrLogoShare)
+	if side%2 != n%2 {
+		side++
+	}
+	start := (n - side) / 2
+	return image.Rect(start, start, start+side, start+side)
+}
+
+// fitRect вписывает src в dst с сохранением пропорций и по центру
+func fitRect(dst, src image.Rectangle) image.Rectangle {
+	if src.Dx() == 0 || src.Dy() == 0 {
+		return image.Rectangle{}
+	}
+	w, h := dst.Dx(), dst.Dx()*src.Dy()/src.Dx()
+	if h > dst.Dy() {
+		w, h = dst.Dy()*src.Dx()/src.Dy(), dst.Dy()
+	}
+	origin := dst.Min.Add(image.Pt((dst.Dx()-w)/2, (dst.Dy()-h)/2))
+	return image.Rectangle{Min: origin, Max: origin.Add(image.Pt(w, h))}
+}
+
+// drawScaled рисует src в прямоугольник dst с усреднением пикселей
+// Стандартная библиотека не масштабирует изображения, а логотип обычно
+// нужно уменьшить в несколько раз - ближайший сосед дал бы "лесенку"
+func drawScaled(dst *image.RGBA, rect image.Rectangle, src image.Image) {
+	sb := src.Bounds()
+	if rect.Empty() || sb.Empty() {
+		return
+	}
+
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		// Область исходного изображения, которая попадает в пиксель (x, y)
+		sy0 := sb.Min.Y + (y-rect.Min.Y)*sb.Dy()/rect.Dy()
+		sy1 := sb.Min.Y + (y-rect.Min.Y+1)*sb.Dy()/rect.Dy()
+		if sy1 <= sy0 {
+			sy1 = sy0 + 1
+		}
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			sx0 := sb.Min.X + (x-rect.Min.X)*sb.Dx()/rect.Dx()
+			sx1 := sb.Min.X + (x-rect.Min.X+1)*sb.Dx()/rect.Dx()
+			if sx1 <= sx0 {
+				sx1 = sx0 + 1
+			}
+
+			var r, g, b, a, count uint32
+			for sy := sy0; sy < sy1; sy++ {
+				for sx := sx0; sx < sx1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+pr, g+pg, b+pb, a+pa
+					count++
+				}
+			}
+			r, g, b, a = r/count, g/count, b/count, a/count
+
+			// Прозрачные части логотипа показывают подложку (цвета уже
+			// умножены на альфу, поэтому смешивание - сложение)
+			dr, dg, db, da := dst.At(x, y).RGBA()
+			keep := 0xffff - a
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r + dr*keep/0xffff),
+				G: uint16(g + dg*keep/0xffff),
+				B: uint16(b + db*keep/0xffff),
+				A: uint16(a + da*keep/0xffff),
+			})
+		}
+	}
+}
+
+// qrRecoveryLevel переводит уровень коррекции L/M/Q/H в константу библиотеки
+func qrRecoveryLevel(ecc string) qrcode.RecoveryLevel {
+	switch ecc {
+	case linkUC.QRLevelLow:
+		return qrcode.Low
+	case linkUC.QRLevelQuarter:
+		return qrcode.High
+	case linkUC.QRLevelHigh:
+		return qrcode.Highest
+	default:
+		return qrcode.Medium
+	}
+}
+
+// qrCacheKey ключ кеша: все, от чего зависит изображение
+func qrCacheKey(content string, o linkUC.QROptions) string {
+	return strings.Join([]string{
+		content, o.Format, strconv.Itoa(o.Size), o.ECC,
+		hexColor(o.Foreground), hexColor(o.Background), strconv.FormatBool(o.Logo),
+	}, "|")
+}
+
+// hexColor записывает цвет как "#rrggbb"
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
@@ -0,0 +1,152 @@
+package external
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	linkUC "clean-url-shortener/internal/usecase/link"
+
+	"github.com/skip2/go-qrcode"
+)
+
+const testQRContent = "https://short.test/sale?utm_source=qr"
+
+func testQROptions(format string) linkUC.QROptions {
+	return linkUC.QROptions{
+		Format:     format,
+		Size:       300,
+		ECC:        linkUC.QRLevelMedium,
+		Foreground: color.RGBA{R: 0x1a, G: 0x23, B: 0x7e, A: 0xff},
+		Background: color.RGBA{R: 0xff, G: 0xf8, B: 0xe1, A: 0xff},
+	}
+}
+
+// decodeQRPNG декодирует PNG и возвращает изображение
+func decodeQRPNG(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("png.Decode() error = %v", err)
+	}
+	return img
+}
+
+func sameColor(a color.Color, b color.RGBA) bool {
+	r, g, bl, _ := a.RGBA()
+	return uint8(r>>8) == b.R && uint8(g>>8) == b.G && uint8(bl>>8) == b.B
+}
+
+func TestQRRendererPNGMatchesModules(t *testing.T) {
+	r, err := NewQRRenderer(QRConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	options := testQROptions(linkUC.QRFormatPNG)
+
+	data, err := r.Render(testQRContent, options)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	img := decodeQRPNG(t, data)
+	if b := img.Bounds(); b.Dx() != options.Size || b.Dy() != options.Size {
+		t.Fatalf("image size = %v, want %dx%d", b, options.Size, options.Size)
+	}
+
+	// Центр каждого модуля должен совпадать с матрицей кодировщика
+	code, _ := qrcode.New(testQRContent, qrcode.Medium)
+	modules := code.Bitmap()
+	n := len(modules)
+	scale := options.Size / n
+	offset := (options.Size - n*scale) / 2
+	for y, row := range modules {
+		for x, dark := range row {
+			want := options.Background
+			if dark {
+				want = options.Foreground
+			}
+			px := img.At(offset+x*scale+scale/2, offset+y*scale+scale/2)
+			if !sameColor(px, want) {
+				t.Fatalf("module (%d, %d) = %v, want %v", x, y, px, want)
+			}
+		}
+	}
+}
+
+func TestQRRendererSVG(t *testing.T) {
+	r, _ := NewQRRenderer(QRConfig{})
+
+	data, err := r.Render(testQRContent, testQROptions(linkUC.QRFormatSVG))
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	svg := string(data)
+	for _, want := range []string{`width="300"`, `fill="#fff8e1"`, `<path fill="#1a237e" d="M`, `</svg>`} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("SVG does not contain %s:\n%s", want, svg)
+		}
+	}
+	if strings.Contains(svg, "<image") {
+		t.Error("SVG contains a logo that was not requested")
+	}
+}
+
+func TestQRRendererLogoAndCache(t *testing.T) {
+	// Логотип - красный квадрат
+	logo := image.NewRGBA(image.Rect(0, 0, 40, 40))
+	for i := range logo.Pix {
+		logo.Pix[i] = []byte{0xff, 0, 0, 0xff}[i%4]
+	}
+	var buf bytes.Buffer
+	png.Encode(&buf, logo)
+	logoPath := filepath.Join(t.TempDir(), "logo.png")
+	if err := os.WriteFile(logoPath, buf.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewQRRenderer(QRConfig{LogoPath: filepath.Join(t.TempDir(), "missing.png")}); err == nil {
+		t.Error("NewQRRenderer() with missing logo succeeded")
+	}
+	withoutLogo, _ := NewQRRenderer(QRConfig{})
+	options := testQROptions(linkUC.QRFormatPNG)
+	options.ECC, options.Logo = linkUC.QRLevelHigh, true
+	if _, err := withoutLogo.Render(testQRContent, options); !errors.Is(err, linkUC.ErrQRLogoNotAvailable) {
+		t.Errorf("Render(logo) without logo = %v, want ErrQRLogoNotAvailable", err)
+	}
+
+	r, err := NewQRRenderer(QRConfig{LogoPath: logoPath, CacheSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := r.Render(testQRContent, options)
+	if err != nil {
+		t.Fatalf("Render(logo) error = %v", err)
+	}
+	img := decodeQRPNG(t, data)
+	center := img.At(options.Size/2, options.Size/2)
+	if !sameColor(center, color.RGBA{R: 0xff}) {
+		t.Errorf("center pixel = %v, want logo color", center)
+	}
+
+	// Повторный запрос с теми же параметрами отдается из кеша
+	again, _ := r.Render(testQRContent, options)
+	if &again[0] != &data[0] {
+		t.Error("second Render() was not served from cache")
+	}
+	options.Size = 400
+	if other, _ := r.Render(testQRContent, options); bytes.Equal(other, data) {
+		t.Error("Render() with another size returned the cached image")
+	}
+
+	options.Format = linkUC.QRFormatSVG
+	svg, _ := r.Render(testQRContent, options)
+	if !strings.Contains(string(svg), `<image x=`) || !strings.Contains(string(svg), "data:image/png;base64,") {
+		t.Errorf("SVG logo is missing:\n%s", svg)
+	}
+}
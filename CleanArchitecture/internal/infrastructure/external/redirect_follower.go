@@ -0,0 +1,140 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+)
+
+// redirectFollower возвращает следующий адрес в цепочке редиректов
+// nil без ошибки - адрес конечный
+type redirectFollower interface {
+	Next(ctx context.Context, u *url.URL) (*url.URL, error)
+}
+
+// errPrivateAddress запрет обращаться к внутренней сети
+var errPrivateAddress = errors.New("redirect target resolves to a non-public address")
+
+// httpRedirectFollower проходит редиректы HEAD запросами
+//
+// Адреса назначения задают пользователи, поэтому соединения разрешены
+// только с публичными IP: иначе через проверку ссылки можно было бы
+// обращаться к сервисам внутренней сети (SSRF). Адрес проверяется
+// после разрешения DNS, в момент соединения.
+type httpRedirectFollower struct {
+	client *http.Client
+}
+
+// newHTTPRedirectFollower создает проход редиректов с таймаутом на запрос
+func newHTTPRedirectFollower(timeout time.Duration) *httpRedirectFollower {
+	dialer := &net.Dialer{
+		Timeout: timeout,
+		Control: denyPrivateNetworks,
+	}
+	transport := &http.Transport{
+		Proxy:                 nil, // прокси обошел бы проверку адресов
+		DialContext:           dialer.DialContext,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	return &httpRedirectFollower{
+		client: &http.Client{
+			Transport: transport,
+			Timeout:   timeout,
+			// Редиректы проходим сами, чтобы проверить каждый адрес
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}
+}
+
+// Next запрашивает адрес и возвращает цель редиректа
+func (f *httpRedirectFollower) Next(ctx context.Context, u *url.URL) (*url.URL, error) {
+	resp, err := f.do(ctx, http.MethodHead, u)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		// Не все серверы поддерживают HEAD
+		resp, err = f.do(ctx, http.MethodGet, u)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	location := resp.Header.Get("Location")
+	if resp.StatusCode < 300 || resp.StatusCode >= 400 || location == "" {
+		return nil, nil
+	}
+	return u.Parse(location)
+}
+
+// do выполняет запрос без тела ответа
+func (f *httpRedirectFollower) do(ctx context.Context, method string, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", "url-shortener-safety-check/1.0")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	resp.Body.Close()
+	return resp, nil
+}
+
+// denyPrivateNetworks запрещает соединения с непубличными адресами
+func denyPrivateNetworks(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", errPrivateAddress, host)
+	}
+	return nil
+}
+
+// specialNetworks сети, которые не покрывают методы net.IP, но и в интернет
+// не ведут
+var specialNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),     // "этот" хост и сеть (RFC 791), 0.x.x.x уходит на localhost
+	mustParseCIDR("100.64.0.0/10"), // операторский NAT (RFC 6598)
+	mustParseCIDR("192.0.0.0/24"),  // служебные адреса IETF (RFC 6890)
+	mustParseCIDR("198.18.0.0/15"), // стенды для бенчмарков (RFC 2544)
+	mustParseCIDR("64:ff9b::/96"),  // NAT64 (RFC 6052): шлюз ведет на любой IPv4, в том числе внутренний
+}
+
+func mustParseCIDR(cidr string) *net.IPNet {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// isPublicIP проверяет, что адрес маршрутизируется в интернете
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range specialNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,234 @@
+package external
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"clean-url-shortener/internal/domain/link"
+)
+
+// urlBlocklist списки блокировки, загруженные из файлов
+//
+// Форматы файлов (строки с # и пустые строки пропускаются):
+//   - домены: по одному в строке; блокируются и все поддомены
+//   - hash-префиксы: hex префиксы SHA-256 выражений URL, как в Safe Browsing
+//     (от 4 до 32 байт); полный хеш блокирует, короткий префикс отмечает ссылку
+//   - правила: "block <regexp>" или "flag <regexp>", регулярное выражение
+//     применяется ко всему URL; без действия правило блокирует
+type urlBlocklist struct {
+	domains    map[string]struct{}
+	prefixes   map[string]struct{} // hex префиксы в нижнем регистре
+	prefixLens []int               // длины префиксов в hex символах
+	rules      []urlRule
+}
+
+// urlRule правило на регулярном выражении
+type urlRule struct {
+	status link.SafetyStatus
+	re     *regexp.Regexp
+}
+
+// fullHashLen длина полного SHA-256 в hex
+const fullHashLen = sha256.Size * 2
+
+// loadURLBlocklist читает списки; пустой путь - список не используется
+func loadURLBlocklist(domainsPath, prefixesPath, rulesPath string) (*urlBlocklist, error) {
+	b := &urlBlocklist{
+		domains:  make(map[string]struct{}),
+		prefixes: make(map[string]struct{}),
+	}
+
+	err := readListFile(domainsPath, func(line string) error {
+		domain := strings.TrimPrefix(strings.TrimPrefix(strings.ToLower(line), "*."), ".")
+		b.domains[strings.TrimSuffix(domain, ".")] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	lens := make(map[int]bool)
+	err = readListFile(prefixesPath, func(line string) error {
+		prefix := strings.ToLower(line)
+		if _, err := hex.DecodeString(prefix); err != nil || len(prefix) < 8 || len(prefix) > fullHashLen {
+			return fmt.Errorf("invalid hash prefix %q", line)
+		}
+		b.prefixes[prefix] = struct{}{}
+		lens[len(prefix)] = true
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for n := range lens {
+		b.prefixLens = append(b.prefixLens, n)
+	}
+	sort.Ints(b.prefixLens)
+
+	err = readListFile(rulesPath, func(line string) error {
+		rule := urlRule{status: link.SafetyBlocked}
+		if action, expr, ok := strings.Cut(line, " "); ok && (action == "block" || action == "flag") {
+			if action == "flag" {
+				rule.status = link.SafetyFlagged
+			}
+			line = strings.TrimSpace(expr)
+		}
+
+		re, err := regexp.Compile(line)
+		if err != nil {
+			return fmt.Errorf("invalid rule %q: %w", line, err)
+		}
+		rule.re = re
+		b.rules = append(b.rules, rule)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// readListFile вызывает fn для каждой значимой строки файла
+func readListFile(path string, fn func(line string) error) error {
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open blocklist: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := fn(line); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, lineNo, err)
+		}
+	}
+	return scanner.Err()
+}
+
+// matchDomain ищет хост или любой из его родительских доменов в списке
+func (b *urlBlocklist) matchDomain(host string) (string, bool) {
+	for h := host; h != ""; {
+		if _, ok := b.domains[h]; ok {
+			return h, true
+		}
+		i := strings.IndexByte(h, '.')
+		if i < 0 {
+			break
+		}
+		h = h[i+1:]
+	}
+	return "", false
+}
+
+// matchHashPrefix ищет хеши выражений URL в списке префиксов
+// full = true, если совпал полный хеш, а не только префикс
+func (b *urlBlocklist) matchHashPrefix(u *url.URL) (expr string, full bool, ok bool) {
+	if len(b.prefixes) == 0 {
+		return "", false, false
+	}
+
+	for _, e := range urlExpressions(u) {
+		sum := sha256.Sum256([]byte(e))
+		hash := hex.EncodeToString(sum[:])
+
+		// Длинные префиксы проверяем первыми: полное совпадение важнее
+		for i := len(b.prefixLens) - 1; i >= 0; i-- {
+			n := b.prefixLens[i]
+			if _, found := b.prefixes[hash[:n]]; found {
+				return e, n == fullHashLen, true
+			}
+		}
+	}
+	return "", false, false
+}
+
+// matchRule возвращает самое строгое из сработавших правил
+func (b *urlBlocklist) matchRule(rawURL string) (urlRule, bool) {
+	var matched urlRule
+	found := false
+	for _, rule := range b.rules {
+		if !rule.re.MatchString(rawURL) {
+			continue
+		}
+		if rule.status == link.SafetyBlocked {
+			return rule, true
+		}
+		if !found {
+			matched, found = rule, true
+		}
+	}
+	return matched, found
+}
+
+// urlExpressions выражения "хост/путь" для поиска по хешам, как в Safe Browsing
+// Для http://a.b.c/1/2.html?p=1 это a.b.c/1/2.html?p=1, a.b.c/1/2.html,
+// a.b.c/, a.b.c/1/ и те же пути для b.c
+func urlExpressions(u *url.URL) []string {
+	host := canonicalHost(u)
+
+	// Хост целиком и до 4 суффиксов из последних 5 компонентов
+	hosts := []string{host}
+	if net.ParseIP(host) == nil {
+		labels := strings.Split(host, ".")
+		start := len(labels) - 5
+		if start < 1 {
+			start = 1
+		}
+		for i := start; i <= len(labels)-2; i++ {
+			hosts = append(hosts, strings.Join(labels[i:], "."))
+		}
+	}
+
+	// Путь с запросом, путь целиком, корень и до 3 префиксов каталогов
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	var paths []string
+	if u.RawQuery != "" {
+		paths = append(paths, path+"?"+u.RawQuery)
+	}
+	paths = append(paths, path)
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	prefix := "/"
+	for i := 0; i < 4; i++ {
+		if prefix != path {
+			paths = append(paths, prefix)
+		}
+		if i >= len(segments)-1 {
+			break
+		}
+		prefix += segments[i] + "/"
+	}
+
+	expressions := make([]string, 0, len(hosts)*len(paths))
+	for _, h := range hosts {
+		for _, p := range paths {
+			expressions = append(expressions, h+p)
+		}
+	}
+	return expressions
+}
+
+// canonicalHost хост URL в нижнем регистре без порта и точки в конце
+func canonicalHost(u *url.URL) string {
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
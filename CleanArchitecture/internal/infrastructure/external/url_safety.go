@@ -0,0 +1,417 @@
+package external
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"clean-url-shortener/internal/domain/link"
+	linkUC "clean-url-shortener/internal/usecase/link"
+)
+
+// Проверка реализации интерфейсов из usecase слоя
+var (
+	_ linkUC.URLValidator  = (*URLSafetyChecker)(nil)
+	_ linkUC.SafetyChecker = (*URLSafetyChecker)(nil)
+)
+
+// ErrUnsupportedURL адрес назначения не http(s) или без хоста
+var ErrUnsupportedURL = errors.New("only http and https URLs with a host are allowed")
+
+// defaultShorteners известные сервисы коротких ссылок
+var defaultShorteners = []string{
+	"bit.ly", "bitly.com", "t.co", "tinyurl.com", "goo.gl", "ow.ly", "is.gd",
+	"buff.ly", "rebrand.ly", "cutt.ly", "shorturl.at", "tiny.cc", "bl.ink",
+	"t.ly", "rb.gy", "s.id", "v.gd", "lnkd.in", "clck.ru", "qr.ae",
+}
+
+// URLSafetyConfig настройки проверки адресов назначения
+type URLSafetyConfig struct {
+	// OwnHosts - хосты самого сервиса: ссылка на них образует петлю
+	OwnHosts []string
+
+	// IsOwnHost - проверка хостов, которые меняются во время работы
+	// (подтвержденные пользовательские домены); nil - только OwnHosts
+	IsOwnHost func(host string) bool
+
+	// Файлы списков блокировки (пустой путь - список не используется)
+	DomainListPath string
+	HashPrefixPath string
+	RulesPath      string
+
+	// ReloadInterval - как часто проверяется изменение файлов списков
+	// 0 - файлы не перечитываются
+	ReloadInterval time.Duration
+
+	// Shorteners - домены сервисов коротких ссылок; nil - список по умолчанию
+	Shorteners []string
+
+	// FollowRedirects - проходить цепочку редиректов адреса назначения
+	FollowRedirects bool
+
+	// MaxRedirects - сколько редиректов проходить, больше - ссылка подозрительна
+	MaxRedirects int
+
+	// RedirectTimeout - ограничение времени на всю цепочку редиректов
+	RedirectTimeout time.Duration
+}
+
+// URLSafetyChecker проверяет адреса назначения по локальным спискам
+//
+// Адрес и каждый шаг его цепочки редиректов проверяются на:
+//   - хосты самого сервиса (короткая ссылка на короткую ссылку - петля)
+//   - списки доменов, hash-префиксов и регулярных правил
+//   - цепочки из нескольких сервисов коротких ссылок, скрывающие цель
+//
+// Реализует URLValidator (проверка при создании) и SafetyChecker
+// (периодическая перепроверка). Файлы списков перечитываются при изменении.
+type URLSafetyChecker struct {
+	config     URLSafetyConfig
+	ownHosts   map[string]struct{}
+	shorteners map[string]struct{}
+	follower   redirectFollower // nil - редиректы не проходятся
+
+	mu        sync.RWMutex
+	lists     *urlBlocklist
+	signature string // версии файлов загруженных списков
+
+	stop     chan struct{}
+	stopOnce sync.Once
+	wg       sync.WaitGroup
+}
+
+// NewURLSafetyChecker загружает списки и запускает отслеживание файлов
+func NewURLSafetyChecker(config URLSafetyConfig) (*URLSafetyChecker, error) {
+	if config.MaxRedirects <= 0 {
+		config.MaxRedirects = 5
+	}
+	if config.RedirectTimeout <= 0 {
+		config.RedirectTimeout = 5 * time.Second
+	}
+	if config.Shorteners == nil {
+		config.Shorteners = defaultShorteners
+	}
+
+	c := &URLSafetyChecker{
+		config:     config,
+		ownHosts:   hostSet(config.OwnHosts),
+		shorteners: hostSet(config.Shorteners),
+		stop:       make(chan struct{}),
+	}
+	if config.FollowRedirects {
+		c.follower = newHTTPRedirectFollower(config.RedirectTimeout)
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+
+	if config.ReloadInterval > 0 {
+		c.wg.Add(1)
+		go c.watch()
+	}
+	return c, nil
+}
+
+// Validate проверяет, что URL можно сократить
+func (c *URLSafetyChecker) Validate(rawURL string) error {
+	_, err := parseHTTPURL(rawURL)
+	return err
+}
+
+// IsSafe проверяет URL при создании ссылки
+// Подозрительные (SafetyFlagged) ссылки создаются: их отметит перепроверка
+func (c *URLSafetyChecker) IsSafe(ctx context.Context, rawURL string) (bool, error) {
+	verdict, err := c.Check(ctx, rawURL)
+	if err != nil {
+		return false, err
+	}
+	return verdict.Status != link.SafetyBlocked, nil
+}
+
+// IsSafeLocal проверяет URL по спискам, не переходя по редиректам
+func (c *URLSafetyChecker) IsSafeLocal(ctx context.Context, rawURL string) (bool, error) {
+	verdict, err := c.check(ctx, rawURL, nil)
+	if err != nil {
+		return false, err
+	}
+	return verdict.Status != link.SafetyBlocked, nil
+}
+
+// Check проверяет URL и его цепочку редиректов
+func (c *URLSafetyChecker) Check(ctx context.Context, rawURL string) (linkUC.SafetyVerdict, error) {
+	return c.check(ctx, rawURL, c.follower)
+}
+
+// check проверяет URL; follower == nil - без перехода по редиректам
+func (c *URLSafetyChecker) check(ctx context.Context, rawURL string, follower redirectFollower) (linkUC.SafetyVerdict, error) {
+	u, err := parseHTTPURL(rawURL)
+	if err != nil {
+		return linkUC.SafetyVerdict{Status: link.SafetyBlocked, Reason: err.Error()}, nil
+	}
+
+	c.mu.RLock()
+	lists := c.lists
+	c.mu.RUnlock()
+
+	// ШАГ 1: Сам адрес
+	verdict := c.checkHop(lists, u)
+	if verdict.Status == link.SafetyBlocked {
+		return verdict, nil
+	}
+
+	// ШАГ 2: Куда он на самом деле ведет
+	redirects := c.checkRedirects(ctx, lists, u, follower)
+	verdict = worseVerdict(verdict, redirects)
+	if redirects.Inconclusive {
+		// Неполная проверка цепочки остается неполной и после объединения
+		verdict.Inconclusive = true
+		if verdict.Reason == "" {
+			verdict.Reason = redirects.Reason
+		}
+	}
+	return verdict, nil
+}
+
+// checkHop проверяет один адрес без перехода по нему
+func (c *URLSafetyChecker) checkHop(lists *urlBlocklist, u *url.URL) linkUC.SafetyVerdict {
+	host := canonicalHost(u)
+	if _, ok := c.ownHosts[host]; ok {
+		return blocked("URL points back to this shortener")
+	}
+	if c.config.IsOwnHost != nil && c.config.IsOwnHost(host) {
+		return blocked("URL points back to this shortener")
+	}
+
+	if domain, ok := lists.matchDomain(host); ok {
+		return blocked(fmt.Sprintf("domain %s is blocklisted", domain))
+	}
+
+	verdict := linkUC.SafetyVerdict{Status: link.SafetyOK}
+	if expr, full, ok := lists.matchHashPrefix(u); ok {
+		if full {
+			return blocked(fmt.Sprintf("%s is on the threat list", expr))
+		}
+		// Совпал только префикс - возможен ложный сигнал
+		verdict = flagged(fmt.Sprintf("%s matches a threat list hash prefix", expr))
+	}
+
+	if rule, ok := lists.matchRule(u.String()); ok {
+		verdict = worseVerdict(verdict, linkUC.SafetyVerdict{
+			Status: rule.status,
+			Reason: fmt.Sprintf("URL matches rule %q", rule.re.String()),
+		})
+	}
+	return verdict
+}
+
+// checkRedirects проходит цепочку редиректов и проверяет каждый шаг
+// Если шаг пройти не удалось, вердикт неокончательный: дальше по цепочке
+// могла быть угроза, поэтому "угроз не найдено" не значит "безопасно"
+func (c *URLSafetyChecker) checkRedirects(ctx context.Context, lists *urlBlocklist, u *url.URL, follower redirectFollower) linkUC.SafetyVerdict {
+	shortenerHops := 0
+	if c.isShortener(u) {
+		shortenerHops++
+	}
+
+	if follower == nil {
+		if shortenerHops > 0 {
+			return flagged("URL points to another shortener, destination is unknown")
+		}
+		return linkUC.SafetyVerdict{Status: link.SafetyOK}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.RedirectTimeout)
+	defer cancel()
+
+	verdict := linkUC.SafetyVerdict{Status: link.SafetyOK}
+	seen := map[string]bool{u.String(): true}
+	for hop := 0; ; hop++ {
+		next, err := follower.Next(ctx, u)
+		if err != nil {
+			if verdict.Status == link.SafetyOK {
+				verdict.Reason = fmt.Sprintf("failed to follow redirect from %s: %v", u.Host, err)
+			}
+			verdict.Inconclusive = true
+			return verdict
+		}
+		if next == nil {
+			break
+		}
+		if hop == c.config.MaxRedirects {
+			return worseVerdict(verdict, flagged(fmt.Sprintf("more than %d redirects", c.config.MaxRedirects)))
+		}
+		if next.Scheme != "http" && next.Scheme != "https" {
+			return worseVerdict(verdict, flagged(fmt.Sprintf("redirects to a non-HTTP URL (%s:)", next.Scheme)))
+		}
+		if seen[next.String()] {
+			return blocked(fmt.Sprintf("redirect loop at %s", next.Host))
+		}
+		seen[next.String()] = true
+
+		hopVerdict := c.checkHop(lists, next)
+		if hopVerdict.Status != link.SafetyOK {
+			hopVerdict.Reason = fmt.Sprintf("redirects to %s: %s", next.Host, hopVerdict.Reason)
+			verdict = worseVerdict(verdict, hopVerdict)
+			if verdict.Status == link.SafetyBlocked {
+				return verdict
+			}
+		}
+
+		if c.isShortener(next) {
+			shortenerHops++
+		}
+		u = next
+	}
+
+	if shortenerHops > 1 {
+		verdict = worseVerdict(verdict, flagged(fmt.Sprintf("chain of %d URL shorteners hides the destination", shortenerHops)))
+	}
+	return verdict
+}
+
+// isShortener проверяет, ведет ли адрес на сервис коротких ссылок
+func (c *URLSafetyChecker) isShortener(u *url.URL) bool {
+	_, ok := c.shorteners[strings.TrimPrefix(canonicalHost(u), "www.")]
+	return ok
+}
+
+// Reload перечитывает списки, если какой-то из файлов изменился
+func (c *URLSafetyChecker) Reload() error {
+	signature, err := c.filesSignature()
+	if err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	unchanged := signature == c.signature
+	c.mu.RUnlock()
+	if unchanged {
+		return nil
+	}
+	return c.load()
+}
+
+// load читает все списки и подменяет текущие
+func (c *URLSafetyChecker) load() error {
+	signature, err := c.filesSignature()
+	if err != nil {
+		return err
+	}
+
+	lists, err := loadURLBlocklist(c.config.DomainListPath, c.config.HashPrefixPath, c.config.RulesPath)
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.lists = lists
+	c.signature = signature
+	c.mu.Unlock()
+	return nil
+}
+
+// filesSignature версии файлов списков: время изменения и размер
+func (c *URLSafetyChecker) filesSignature() (string, error) {
+	var sb strings.Builder
+	for _, path := range []string{c.config.DomainListPath, c.config.HashPrefixPath, c.config.RulesPath} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to open blocklist: %w", err)
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d;", path, info.ModTime().UnixNano(), info.Size())
+	}
+	return sb.String(), nil
+}
+
+// watch периодически проверяет, не изменились ли файлы списков
+func (c *URLSafetyChecker) watch() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			// Файл с ошибкой не применяется - действуют прежние списки
+			if err := c.Reload(); err != nil {
+				log.Printf("Blocklist reload failed, keeping previous lists: %v", err)
+			}
+		}
+	}
+}
+
+// Close останавливает отслеживание файлов
+func (c *URLSafetyChecker) Close() error {
+	c.stopOnce.Do(func() { close(c.stop) })
+	c.wg.Wait()
+	return nil
+}
+
+// parseHTTPURL разбирает абсолютный http(s) URL
+func parseHTTPURL(rawURL string) (*url.URL, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return nil, ErrUnsupportedURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return nil, ErrUnsupportedURL
+	}
+	return u, nil
+}
+
+// hostSet множество хостов в нижнем регистре
+func hostSet(hosts []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(hosts))
+	for _, h := range hosts {
+		if h = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(h)), "."); h != "" {
+			set[h] = struct{}{}
+		}
+	}
+	return set
+}
+
+// worseVerdict возвращает более строгий вердикт (при равенстве - первый)
+func worseVerdict(a, b linkUC.SafetyVerdict) linkUC.SafetyVerdict {
+	if safetySeverity(b.Status) > safetySeverity(a.Status) {
+		return b
+	}
+	return a
+}
+
+func safetySeverity(status link.SafetyStatus) int {
+	switch status {
+	case link.SafetyBlocked:
+		return 2
+	case link.SafetyFlagged:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func blocked(reason string) linkUC.SafetyVerdict {
+	return linkUC.SafetyVerdict{Status: link.SafetyBlocked, Reason: reason}
+}
+
+func flagged(reason string) linkUC.SafetyVerdict {
+	return linkUC.SafetyVerdict{Status: link.SafetyFlagged, Reason: reason}
+}
+
+// ПРИНЦИПЫ ПРОВЕРКИ БЕЗОПАСНОСТИ:
+// 1. Все списки локальные: проверка не отправляет адреса сторонним сервисам
+// 2. Проверяется не только адрес, но и то, куда он перенаправляет
+// 3. Неуверенный сигнал (префикс хеша, длинная цепочка) отмечает ссылку, а не блокирует
+// 4. Сбой при проходе цепочки - не повод ни блокировать, ни снимать блокировку
@@ -0,0 +1,277 @@
+package external
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-url-shortener/internal/domain/link"
+)
+
+// mapFollower редиректы из таблицы вместо HTTP запросов
+// Пустая цель - адрес недоступен
+type mapFollower map[string]string
+
+func (f mapFollower) Next(ctx context.Context, u *url.URL) (*url.URL, error) {
+	next, ok := f[u.String()]
+	if !ok {
+		return nil, nil
+	}
+	if next == "" {
+		return nil, errors.New("connection refused")
+	}
+	return u.Parse(next)
+}
+
+func writeListFile(t *testing.T, dir, name string, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func hashHex(expr string) string {
+	sum := sha256.Sum256([]byte(expr))
+	return hex.EncodeToString(sum[:])
+}
+
+func newTestSafetyChecker(t *testing.T, follower redirectFollower) *URLSafetyChecker {
+	t.Helper()
+	dir := t.TempDir()
+
+	checker, err := NewURLSafetyChecker(URLSafetyConfig{
+		OwnHosts:       []string{"sho.rt"},
+		DomainListPath: writeListFile(t, dir, "domains.txt", "# malware", "evil.example", "*.phish.example"),
+		HashPrefixPath: writeListFile(t, dir, "prefixes.txt",
+			hashHex("bank.example/login/"),
+			hashHex("sketchy.example/")[:8],
+		),
+		RulesPath: writeListFile(t, dir, "rules.txt",
+			`flag \.zip(/|$)`,
+			`block ^https?://[^/]*\bfree-prizes\b`,
+		),
+		MaxRedirects: 3,
+	})
+	if err != nil {
+		t.Fatalf("NewURLSafetyChecker() error = %v", err)
+	}
+	checker.follower = follower
+	t.Cleanup(func() { checker.Close() })
+	return checker
+}
+
+func TestURLSafetyCheckerLists(t *testing.T) {
+	checker := newTestSafetyChecker(t, nil)
+
+	tests := []struct {
+		url  string
+		want link.SafetyStatus
+	}{
+		{"https://example.com/page", link.SafetyOK},
+		{"https://evil.example/", link.SafetyBlocked},
+		{"https://cdn.EVIL.example./x", link.SafetyBlocked}, // поддомен, регистр, точка в конце
+		{"https://login.phish.example/", link.SafetyBlocked},
+		{"https://notevil.example/", link.SafetyOK},
+		{"https://bank.example/login/form.html?user=1", link.SafetyBlocked}, // полный хеш префикса пути
+		{"https://www.sketchy.example/any", link.SafetyFlagged},             // только префикс хеша
+		{"https://files.example/archive.zip", link.SafetyFlagged},
+		{"http://free-prizes.example/", link.SafetyBlocked},
+		{"https://sho.rt/abc123", link.SafetyBlocked}, // ссылка на сам сервис
+		{"https://bit.ly/xyz", link.SafetyFlagged},    // цель неизвестна без прохода редиректов
+		{"javascript:alert(1)", link.SafetyBlocked},
+	}
+
+	for _, tt := range tests {
+		verdict, err := checker.Check(context.Background(), tt.url)
+		if err != nil || verdict.Status != tt.want {
+			t.Errorf("Check(%q) = %+v, %v; want %s", tt.url, verdict, err, tt.want)
+		}
+		if verdict.Status != link.SafetyOK && verdict.Reason == "" {
+			t.Errorf("Check(%q) has no reason", tt.url)
+		}
+	}
+
+	if safe, _ := checker.IsSafe(context.Background(), "https://www.sketchy.example/any"); !safe {
+		t.Error("IsSafe() = false for flagged URL, want true")
+	}
+	if safe, _ := checker.IsSafe(context.Background(), "https://evil.example/"); safe {
+		t.Error("IsSafe() = true for blocked URL")
+	}
+	if err := checker.Validate("ftp://example.com/file"); !errors.Is(err, ErrUnsupportedURL) {
+		t.Errorf("Validate(ftp) error = %v, want %v", err, ErrUnsupportedURL)
+	}
+}
+
+func TestURLSafetyCheckerRedirects(t *testing.T) {
+	checker := newTestSafetyChecker(t, mapFollower{
+		// Сокращатель ведет на заблокированный домен
+		"https://bit.ly/bad":       "https://tinyurl.com/next",
+		"https://tinyurl.com/next": "https://evil.example/payload",
+		// Чужой сокращатель ведет обратно на нас
+		"https://bit.ly/loop": "https://sho.rt/abc123",
+		// Петля без заблокированных адресов
+		"https://a.example/":     "https://b.example/",
+		"https://b.example/":     "/back",
+		"https://b.example/back": "https://a.example/",
+		// Длинная цепочка
+		"https://r.example/1": "https://r.example/2",
+		"https://r.example/2": "https://r.example/3",
+		"https://r.example/3": "https://r.example/4",
+		"https://r.example/4": "https://r.example/5",
+		// Два сокращателя подряд с безопасной целью
+		"https://bit.ly/nested":      "https://t.co/inner",
+		"https://t.co/inner":         "https://example.com/landing",
+		"https://bit.ly/ok":          "https://example.com/landing",
+		"https://safe.example/start": "intent://open",
+		// Сокращатель недоступен на середине цепочки
+		"https://bit.ly/down":            "https://tinyurl.com/down",
+		"https://tinyurl.com/down":       "",
+		"https://files.example/data.zip": "",
+	})
+
+	tests := []struct {
+		url    string
+		want   link.SafetyStatus
+		reason string
+	}{
+		{"https://bit.ly/bad", link.SafetyBlocked, "redirects to evil.example"},
+		{"https://bit.ly/loop", link.SafetyBlocked, "points back to this shortener"},
+		{"https://a.example/", link.SafetyBlocked, "redirect loop"},
+		{"https://r.example/1", link.SafetyFlagged, "more than 3 redirects"},
+		{"https://bit.ly/nested", link.SafetyFlagged, "chain of 2 URL shorteners"},
+		{"https://bit.ly/ok", link.SafetyOK, ""},
+		{"https://safe.example/start", link.SafetyFlagged, "non-HTTP"},
+	}
+
+	for _, tt := range tests {
+		verdict, err := checker.Check(context.Background(), tt.url)
+		if err != nil || verdict.Status != tt.want || !strings.Contains(verdict.Reason, tt.reason) {
+			t.Errorf("Check(%q) = %+v, %v; want %s with %q", tt.url, verdict, err, tt.want, tt.reason)
+		}
+		if verdict.Inconclusive {
+			t.Errorf("Check(%q) is inconclusive", tt.url)
+		}
+	}
+
+	// Сбой на середине цепочки - не "угроз нет", а неокончательный вердикт
+	inconclusive := []struct {
+		url    string
+		want   link.SafetyStatus
+		reason string
+	}{
+		{"https://bit.ly/down", link.SafetyOK, "failed to follow redirect from tinyurl.com"},
+		{"https://files.example/data.zip", link.SafetyFlagged, "matches rule"},
+	}
+	for _, tt := range inconclusive {
+		verdict, err := checker.Check(context.Background(), tt.url)
+		if err != nil || !verdict.Inconclusive || verdict.Status != tt.want || !strings.Contains(verdict.Reason, tt.reason) {
+			t.Errorf("Check(%q) = %+v, %v; want inconclusive %s with %q", tt.url, verdict, err, tt.want, tt.reason)
+		}
+	}
+
+	// Пакетная проверка не ходит по редиректам: цепочку проверит перепроверка
+	if safe, err := checker.IsSafeLocal(context.Background(), "https://bit.ly/bad"); err != nil || !safe {
+		t.Errorf("IsSafeLocal(redirect to blocked) = %v, %v; want true", safe, err)
+	}
+	if safe, _ := checker.IsSafe(context.Background(), "https://bit.ly/bad"); safe {
+		t.Error("IsSafe(redirect to blocked) = true")
+	}
+	if safe, _ := checker.IsSafeLocal(context.Background(), "https://evil.example/"); safe {
+		t.Error("IsSafeLocal(blocked) = true")
+	}
+}
+
+func TestURLSafetyCheckerReload(t *testing.T) {
+	dir := t.TempDir()
+	domains := writeListFile(t, dir, "domains.txt", "evil.example")
+
+	checker, err := NewURLSafetyChecker(URLSafetyConfig{DomainListPath: domains})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer checker.Close()
+
+	// Новый домен в списке начинает блокироваться после перечитывания
+	writeListFile(t, dir, "domains.txt", "evil.example", "new-threat.example", "# updated")
+	if err := checker.Reload(); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if verdict, _ := checker.Check(context.Background(), "https://new-threat.example/"); verdict.Status != link.SafetyBlocked {
+		t.Errorf("Check() after reload = %+v, want blocked", verdict)
+	}
+
+	// Файл с ошибкой не заменяет рабочие списки
+	writeListFile(t, dir, "rules.txt", "block (unclosed")
+	checker.config.RulesPath = filepath.Join(dir, "rules.txt")
+	if err := checker.Reload(); err == nil {
+		t.Error("Reload() with invalid rule succeeded")
+	}
+	if verdict, _ := checker.Check(context.Background(), "https://evil.example/"); verdict.Status != link.SafetyBlocked {
+		t.Errorf("Check() after failed reload = %+v, want blocked", verdict)
+	}
+}
+
+func TestHTTPRedirectFollowerDeniesPrivateNetworks(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://example.com/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	target, _ := url.Parse(server.URL)
+	_, err := newHTTPRedirectFollower(time.Second).Next(context.Background(), target)
+	if !errors.Is(err, errPrivateAddress) {
+		t.Errorf("Next(loopback) error = %v, want %v", err, errPrivateAddress)
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"10.1.2.3", false},
+		{"169.254.169.254", false},
+		{"::ffff:192.168.0.1", false},
+		{"0.0.0.0", false},
+		{"0.1.2.3", false},
+		{"100.64.0.1", false},
+		{"192.0.0.8", false},
+		{"198.18.0.1", false},
+		{"198.19.255.255", false},
+		{"198.20.0.1", true},
+		{"64:ff9b::a00:1", false},
+		{"64:ff9b::5db8:d822", false},
+	}
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.public {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.public)
+		}
+	}
+}
+
+func TestURLExpressions(t *testing.T) {
+	u, _ := url.Parse("http://a.b.c/1/2.html?param=1")
+	got := urlExpressions(u)
+	want := []string{
+		"a.b.c/1/2.html?param=1", "a.b.c/1/2.html", "a.b.c/", "a.b.c/1/",
+		"b.c/1/2.html?param=1", "b.c/1/2.html", "b.c/", "b.c/1/",
+	}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("urlExpressions() = %v, want %v", got, want)
+	}
+}
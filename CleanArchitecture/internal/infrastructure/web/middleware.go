@@ -18,17 +18,21 @@ const (
 	UserIDKey ContextKey = "user_id"
 	// UserEmailKey ключ для email пользователя в контексте
 	UserEmailKey ContextKey = "user_email"
+	// TokenDataKey ключ для данных access токена в контексте (нужны для выхода)
+	TokenDataKey ContextKey = "token_data"
 )
 
 // AuthMiddleware middleware для аутентификации
 type AuthMiddleware struct {
 	tokenGenerator auth.TokenGenerator
+	denylist       auth.TokenDenylist // nil - отзыв токенов не проверяется
 }
 
 // NewAuthMiddleware создает новый middleware для аутентификации
-func NewAuthMiddleware(tokenGenerator auth.TokenGenerator) *AuthMiddleware {
+func NewAuthMiddleware(tokenGenerator auth.TokenGenerator, denylist auth.TokenDenylist) *AuthMiddleware {
 	return &AuthMiddleware{
 		tokenGenerator: tokenGenerator,
+		denylist:       denylist,
 	}
 }
 
@@ -58,9 +62,21 @@ func (m *AuthMiddleware) RequireAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
+		// Проверяем, не отозван ли токен или его сессия (выход, утечка)
+		revoked, err := m.isRevoked(r.Context(), tokenData)
+		if err != nil {
+			http.Error(w, "Failed to verify token", http.StatusServiceUnavailable)
+			return
+		}
+		if revoked {
+			http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+			return
+		}
+
 		// Добавляем данные пользователя в контекст
 		ctx := context.WithValue(r.Context(), UserIDKey, tokenData.UserID)
 		ctx = context.WithValue(ctx, UserEmailKey, tokenData.Email)
+		ctx = context.WithValue(ctx, TokenDataKey, tokenData)
 		r = r.WithContext(ctx)
 
 		// Передаем управление следующему handler
@@ -77,9 +93,13 @@ func (m *AuthMiddleware) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
 			if len(parts) == 2 && parts[0] == "Bearer" {
 				token := parts[1]
 				if tokenData, err := m.tokenGenerator.Validate(token); err == nil {
-					ctx := context.WithValue(r.Context(), UserIDKey, tokenData.UserID)
-					ctx = context.WithValue(ctx, UserEmailKey, tokenData.Email)
-					r = r.WithContext(ctx)
+					// Отозванный токен - то же, что отсутствие токена
+					if revoked, err := m.isRevoked(r.Context(), tokenData); err == nil && !revoked {
+						ctx := context.WithValue(r.Context(), UserIDKey, tokenData.UserID)
+						ctx = context.WithValue(ctx, UserEmailKey, tokenData.Email)
+						ctx = context.WithValue(ctx, TokenDataKey, tokenData)
+						r = r.WithContext(ctx)
+					}
 				}
 			}
 		}
@@ -87,6 +107,14 @@ func (m *AuthMiddleware) OptionalAuth(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+// isRevoked проверяет токен и его сессию по denylist
+func (m *AuthMiddleware) isRevoked(ctx context.Context, tokenData *auth.TokenData) (bool, error) {
+	if m.denylist == nil {
+		return false, nil
+	}
+	return m.denylist.IsRevoked(ctx, tokenData.TokenID, tokenData.SessionID)
+}
+
 // CORSMiddleware middleware для CORS
 func CORSMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -141,6 +169,12 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// Unwrap дает http.ResponseController доступ к исходному writer
+// (Flush для потоковых ответов)
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // RecoveryMiddleware middleware для восстановления от паник
 func RecoveryMiddleware(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -175,6 +209,12 @@ func GetUserEmailFromContext(ctx context.Context) (string, bool) {
 	return email, ok
 }
 
+// GetTokenDataFromContext извлекает данные access токена из контекста
+func GetTokenDataFromContext(ctx context.Context) (*auth.TokenData, bool) {
+	data, ok := ctx.Value(TokenDataKey).(*auth.TokenData)
+	return data, ok
+}
+
 // ПРИНЦИПЫ MIDDLEWARE:
 // 1. Каждый middleware имеет одну ответственность
 // 2. Middleware можно комбинировать в любом порядке
@@ -0,0 +1,270 @@
+package web
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RateLimit лимит запросов для маршрута: Requests запросов за Period
+//
+// Лимит реализован корзиной токенов (token bucket): в корзине до Requests
+// токенов, каждый запрос забирает один, и за Period корзина наполняется
+// полностью. Клиент может потратить всю корзину сразу, а дальше получает
+// запросы с равномерной скоростью Requests/Period.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// Enabled проверяет, задан ли лимит
+func (l RateLimit) Enabled() bool {
+	return l.Requests > 0 && l.Period > 0
+}
+
+// ratePerSecond скорость наполнения корзины
+func (l RateLimit) ratePerSecond() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// ParseRateLimit разбирает лимит в формате "20/1m"
+// "0" и "off" означают отсутствие лимита
+func ParseRateLimit(value string) (RateLimit, error) {
+	value = strings.TrimSpace(value)
+	if value == "0" || value == "off" {
+		return RateLimit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: want requests/period, e.g. 20/1m", value)
+	}
+	n, err := strconv.Atoi(requests)
+	if err != nil || n <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: requests must be a positive number", value)
+	}
+	d, err := time.ParseDuration(period)
+	if err != nil || d <= 0 {
+		return RateLimit{}, fmt.Errorf("invalid rate limit %q: period must be a positive duration", value)
+	}
+	return RateLimit{Requests: n, Period: d}, nil
+}
+
+// RateLimitResult результат попытки взять токен из корзины
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int           // целых токенов в корзине после запроса
+	Reset      time.Duration // через сколько корзина снова будет полной
+	RetryAfter time.Duration // через сколько появится токен (если запрос отклонен)
+}
+
+// RateLimitStore хранилище корзин токенов
+// Реализации: MemoryRateLimitStore (один экземпляр) и RedisRateLimitStore
+// (общие лимиты для нескольких экземпляров)
+type RateLimitStore interface {
+	// Take забирает токен из корзины key
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// newRateLimitResult считает результат по числу токенов после запроса
+func newRateLimitResult(limit RateLimit, tokens float64, allowed bool) RateLimitResult {
+	rate := limit.ratePerSecond()
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(limit.Requests) - tokens) / rate * float64(time.Second)),
+	}
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / rate * float64(time.Second))
+	}
+	return result
+}
+
+// RateLimiter ограничивает частоту запросов по маршрутам
+// Ключ корзины - маршрут и пользователь (если запрос аутентифицирован)
+// или IP адрес клиента
+type RateLimiter struct {
+	store      RateLimitStore
+	limits     map[string]RateLimit
+	trustProxy bool // брать IP из X-Real-Ip / X-Forwarded-For
+}
+
+// NewRateLimiter создает ограничитель с лимитами по именам маршрутов
+// trustProxy включается, только если приложение стоит за прокси,
+// который сам выставляет заголовки: иначе клиент подменит свой IP
+func NewRateLimiter(store RateLimitStore, limits map[string]RateLimit, trustProxy bool) *RateLimiter {
+	return &RateLimiter{
+		store:      store,
+		limits:     limits,
+		trustProxy: trustProxy,
+	}
+}
+
+// Limit возвращает middleware для маршрута route
+// Без лимита для маршрута (или без ограничителя) запросы не ограничиваются.
+// Чтобы лимит считался по пользователю, middleware ставится после RequireAuth
+func (l *RateLimiter) Limit(route string) func(http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		if l == nil {
+			return next
+		}
+		limit, ok := l.limits[route]
+		if !ok || !limit.Enabled() {
+			return next
+		}
+
+		return func(w http.ResponseWriter, r *http.Request) {
+			result, err := l.store.Take(r.Context(), route+":"+l.clientKey(r), limit)
+			if err != nil {
+				// Недоступное хранилище лимитов не должно останавливать сервис
+				log.Printf("Rate limit check failed for %s, allowing request: %v", route, err)
+				next(w, r)
+				return
+			}
+
+			// ШАГ 1: Стандартные заголовки RateLimit-*
+			header := w.Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(limit.Requests))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+			header.Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", limit.Requests, ceilSeconds(limit.Period)))
+
+			// ШАГ 2: Корзина пуста - отклоняем запрос
+			if !result.Allowed {
+				header.Set("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+				http.Error(w, "Too many requests", http.StatusTooManyRequests)
+				return
+			}
+
+			next(w, r)
+		}
+	}
+}
+
+// clientKey определяет, чей лимит расходует запрос
+func (l *RateLimiter) clientKey(r *http.Request) string {
+	if userID, ok := GetUserIDFromContext(r.Context()); ok {
+		return "user:" + strconv.FormatUint(uint64(userID), 10)
+	}
+	return "ip:" + l.clientIP(r)
+}
+
+// clientIP извлекает IP адрес клиента
+// IPv6 адреса группируются по /64: клиенту обычно выделена вся подсеть
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	raw := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(raw); err == nil {
+		raw = host
+	}
+
+	if l.trustProxy {
+		if ip := r.Header.Get("X-Real-Ip"); ip != "" {
+			raw = ip
+		} else if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			// Последний адрес добавлен нашим прокси, предыдущие мог прислать клиент
+			parts := strings.Split(forwarded, ",")
+			raw = strings.TrimSpace(parts[len(parts)-1])
+		}
+	}
+
+	ip := net.ParseIP(raw)
+	if ip == nil {
+		return raw
+	}
+	if ip.To4() == nil {
+		return ip.Mask(net.CIDRMask(64, 128)).String() + "/64"
+	}
+	return ip.String()
+}
+
+// ceilSeconds округляет длительность вверх до целых секунд
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// MemoryRateLimitStore хранит корзины в памяти процесса
+// Подходит для одного экземпляра: при нескольких экземплярах
+// каждый считает свой лимит
+type MemoryRateLimitStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+// tokenBucket состояние одной корзины
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time // после этого момента корзина полная и ее можно удалить
+}
+
+// memorySweepInterval как часто удаляются полные корзины
+const memorySweepInterval = time.Minute
+
+// NewMemoryRateLimitStore создает хранилище корзин в памяти
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		buckets: make(map[string]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// Take забирает токен из корзины key
+func (s *MemoryRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	capacity := float64(limit.Requests)
+	rate := limit.ratePerSecond()
+
+	// ШАГ 1: Наполняем корзину за время с последнего запроса
+	b, ok := s.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: capacity, updated: now}
+		s.buckets[key] = b
+	}
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+elapsed.Seconds()*rate)
+		b.updated = now
+	}
+
+	// ШАГ 2: Забираем токен, если он есть
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	result := newRateLimitResult(limit, b.tokens, allowed)
+	b.full = now.Add(result.Reset)
+	return result, nil
+}
+
+// sweep удаляет полные корзины: они не отличаются от новых
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < memorySweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		if !now.Before(b.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// ПРИНЦИПЫ ОГРАНИЧЕНИЯ ЧАСТОТЫ:
+// 1. Лимит задается на маршрут и считается на пользователя или IP
+// 2. Клиент видит свой остаток в заголовках RateLimit-* и знает, когда повторить
+// 3. Ошибка хранилища лимитов пропускает запрос, а не роняет сервис
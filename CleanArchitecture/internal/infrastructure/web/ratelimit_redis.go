@@ -0,0 +1,86 @@
+package web
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript атомарно наполняет корзину и забирает из нее токен
+// Состояние корзины - хеш {tokens, ts}; ключ живет, пока корзина не полная
+//
+// KEYS[1] - ключ корзины
+// ARGV[1] - емкость, ARGV[2] - токенов в миллисекунду, ARGV[3] - текущее время (мс)
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = capacity
+	ts = now
+end
+
+if now > ts then
+	tokens = math.min(capacity, tokens + (now - ts) * rate)
+	ts = now
+end
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(ts))
+redis.call('PEXPIRE', KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+return {allowed, tostring(tokens)}
+`)
+
+// RedisRateLimitStore хранит корзины в Redis
+// Лимит общий для всех экземпляров приложения.
+// Время берется с экземпляра, поэтому часы экземпляров должны быть синхронизированы
+type RedisRateLimitStore struct {
+	client redis.UniversalClient
+	prefix string
+	now    func() time.Time
+}
+
+// NewRedisRateLimitStore создает хранилище корзин в Redis
+func NewRedisRateLimitStore(client redis.UniversalClient, prefix string) *RedisRateLimitStore {
+	if prefix == "" {
+		prefix = "ratelimit:"
+	}
+	return &RedisRateLimitStore{
+		client: client,
+		prefix: prefix,
+		now:    time.Now,
+	}
+}
+
+// Take забирает токен из корзины key
+func (s *RedisRateLimitStore) Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error) {
+	ratePerMs := limit.ratePerSecond() / 1000
+
+	values, err := tokenBucketScript.Run(ctx, s.client, []string{s.prefix + key},
+		limit.Requests,
+		strconv.FormatFloat(ratePerMs, 'f', -1, 64),
+		s.now().UnixMilli(),
+	).Slice()
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+
+	allowed, _ := values[0].(int64)
+	tokensValue, _ := values[1].(string)
+	tokens, err := strconv.ParseFloat(tokensValue, 64)
+	if err != nil {
+		return RateLimitResult{}, err
+	}
+	return newRateLimitResult(limit, tokens, allowed == 1), nil
+}
@@ -0,0 +1,187 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+func TestParseRateLimit(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    RateLimit
+		wantErr bool
+	}{
+		{value: "20/1m", want: RateLimit{Requests: 20, Period: time.Minute}},
+		{value: " 5/10s ", want: RateLimit{Requests: 5, Period: 10 * time.Second}},
+		{value: "off"},
+		{value: "0"},
+		{value: "20", wantErr: true},
+		{value: "-1/1m", wantErr: true},
+		{value: "20/0s", wantErr: true},
+		{value: "20/minute", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRateLimit(tt.value)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRateLimit(%q) = %+v, %v; want %+v, error %v", tt.value, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// fakeClock управляемое время для корзин
+type fakeClock struct{ now time.Time }
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+// testTokenBucket проверяет поведение корзины 3 запроса в минуту
+func testTokenBucket(t *testing.T, store RateLimitStore, clock *fakeClock) {
+	t.Helper()
+	ctx := context.Background()
+	limit := RateLimit{Requests: 3, Period: time.Minute}
+
+	// Полная корзина позволяет всплеск
+	for i := 3; i > 0; i-- {
+		result, err := store.Take(ctx, "key", limit)
+		if err != nil || !result.Allowed || result.Remaining != i-1 {
+			t.Fatalf("Take() = %+v, %v; want allowed with %d remaining", result, err, i-1)
+		}
+	}
+
+	// Токен восстанавливается за Period/Requests = 20s
+	result, err := store.Take(ctx, "key", limit)
+	if err != nil || result.Allowed || result.RetryAfter != 20*time.Second || result.Reset != time.Minute {
+		t.Fatalf("Take() over limit = %+v, %v", result, err)
+	}
+	if other, _ := store.Take(ctx, "other", limit); !other.Allowed {
+		t.Error("Take() for another key was limited")
+	}
+
+	clock.now = clock.now.Add(20 * time.Second)
+	if result, _ := store.Take(ctx, "key", limit); !result.Allowed || result.Remaining != 0 {
+		t.Errorf("Take() after refill = %+v", result)
+	}
+
+	// Корзина не наполняется больше емкости
+	clock.now = clock.now.Add(time.Hour)
+	if result, _ := store.Take(ctx, "key", limit); result.Remaining != 2 || result.Reset != 20*time.Second {
+		t.Errorf("Take() after idle = %+v", result)
+	}
+}
+
+func TestMemoryRateLimitStore(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	store := NewMemoryRateLimitStore()
+	store.now = clock.Now
+
+	testTokenBucket(t, store, clock)
+
+	// Полные корзины удаляются
+	clock.now = clock.now.Add(2 * memorySweepInterval)
+	store.Take(context.Background(), "fresh", RateLimit{Requests: 1, Period: time.Second})
+	if len(store.buckets) != 1 {
+		t.Errorf("buckets after sweep = %d, want 1", len(store.buckets))
+	}
+}
+
+func TestRedisRateLimitStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	clock := &fakeClock{now: time.Unix(1700000000, 0)}
+	store := NewRedisRateLimitStore(client, "")
+	store.now = clock.Now
+
+	testTokenBucket(t, store, clock)
+
+	if ttl := server.TTL("ratelimit:key"); ttl <= 0 {
+		t.Errorf("bucket TTL = %v, want positive", ttl)
+	}
+}
+
+func TestRateLimiterMiddleware(t *testing.T) {
+	limiter := NewRateLimiter(NewMemoryRateLimitStore(), map[string]RateLimit{
+		"create": {Requests: 2, Period: time.Minute},
+	}, false)
+	handler := limiter.Limit("create")(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	send := func(remoteAddr string, userID uint, forwardedFor string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/links", nil)
+		req.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", forwardedFor)
+		}
+		if userID != 0 {
+			req = req.WithContext(context.WithValue(req.Context(), UserIDKey, userID))
+		}
+		rec := httptest.NewRecorder()
+		handler(rec, req)
+		return rec
+	}
+
+	first := send("10.0.0.1:1234", 0, "")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("first status = %d", first.Code)
+	}
+	if h := first.Header(); h.Get("RateLimit-Limit") != "2" || h.Get("RateLimit-Remaining") != "1" ||
+		h.Get("RateLimit-Reset") != "30" || h.Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("headers = %v", h)
+	}
+
+	send("10.0.0.1:1234", 0, "")
+	// Без доверия к прокси подставной заголовок не дает новый лимит
+	limited := send("10.0.0.1:5678", 0, "192.0.2.1")
+	if limited.Code != http.StatusTooManyRequests || limited.Header().Get("Retry-After") != "30" {
+		t.Errorf("limited status = %d, Retry-After = %q", limited.Code, limited.Header().Get("Retry-After"))
+	}
+
+	// Другой IP и аутентифицированный пользователь имеют свои лимиты
+	if rec := send("10.0.0.2:1234", 0, ""); rec.Code != http.StatusCreated {
+		t.Errorf("other IP status = %d", rec.Code)
+	}
+	if rec := send("10.0.0.1:1234", 7, ""); rec.Code != http.StatusCreated {
+		t.Errorf("user status = %d", rec.Code)
+	}
+
+	// IPv6 адреса одной /64 подсети делят лимит
+	send("[2001:db8::1]:1234", 0, "")
+	send("[2001:db8::2]:1234", 0, "")
+	if rec := send("[2001:db8::3]:1234", 0, ""); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("IPv6 /64 status = %d, want 429", rec.Code)
+	}
+
+	// Маршрут без лимита и nil ограничитель не ограничивают
+	var nilLimiter *RateLimiter
+	for _, mw := range []func(http.HandlerFunc) http.HandlerFunc{limiter.Limit("unknown"), nilLimiter.Limit("create")} {
+		rec := httptest.NewRecorder()
+		mw(func(w http.ResponseWriter, r *http.Request) {})(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Header().Get("RateLimit-Limit") != "" {
+			t.Error("unlimited route sent RateLimit headers")
+		}
+	}
+}
+
+func TestRateLimiterTrustProxy(t *testing.T) {
+	limiter := NewRateLimiter(nil, nil, true)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	req.Header.Set("X-Forwarded-For", "192.0.2.1, 198.51.100.7")
+	if ip := limiter.clientIP(req); ip != "198.51.100.7" {
+		t.Errorf("clientIP() = %q, want address added by proxy", ip)
+	}
+
+	req.Header.Set("X-Real-Ip", "203.0.113.5")
+	if ip := limiter.clientIP(req); ip != "203.0.113.5" {
+		t.Errorf("clientIP() = %q, want X-Real-Ip", ip)
+	}
+}
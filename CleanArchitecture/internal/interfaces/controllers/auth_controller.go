@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 	
 	"clean-url-shortener/internal/usecase/auth"
@@ -12,20 +13,29 @@ import (
 // AuthController обрабатывает HTTP запросы для аутентификации
 // ЭТО АДАПТЕР между HTTP и Use Cases
 type AuthController struct {
-	registerUC *auth.RegisterUseCase // Use Case для регистрации
-	loginUC    *auth.LoginUseCase    // Use Case для входа
-	validator  *validator.Validate   // Валидатор для входных данных
+	registerUC     *auth.RegisterUseCase // Use Case для регистрации
+	loginUC        *auth.LoginUseCase    // Use Case для входа
+	refreshUC      *auth.RefreshUseCase  // Use Case для обновления токенов
+	logoutUC       *auth.LogoutUseCase   // Use Case для выхода
+	validator      *validator.Validate   // Валидатор для входных данных
+	authMiddleware *web.AuthMiddleware   // Middleware для аутентификации (выход)
 }
 
 // NewAuthController создает новый контроллер аутентификации
 func NewAuthController(
 	registerUC *auth.RegisterUseCase,
 	loginUC *auth.LoginUseCase,
+	refreshUC *auth.RefreshUseCase,
+	logoutUC *auth.LogoutUseCase,
+	authMiddleware *web.AuthMiddleware,
 ) *AuthController {
 	return &AuthController{
-		registerUC: registerUC,
-		loginUC:    loginUC,
-		validator:  validator.New(),
+		registerUC:     registerUC,
+		loginUC:        loginUC,
+		refreshUC:      refreshUC,
+		logoutUC:       logoutUC,
+		validator:      validator.New(),
+		authMiddleware: authMiddleware,
 	}
 }
 
@@ -83,6 +93,63 @@ func (c *AuthController) Login(w http.ResponseWriter, r *http.Request) {
 	c.writeJSONResponse(w, response, http.StatusOK)
 }
 
+// Refresh обменивает refresh токен на новую пару токенов
+// POST /auth/refresh
+func (c *AuthController) Refresh(w http.ResponseWriter, r *http.Request) {
+	// ШАГ 1: Декодируем JSON из тела запроса
+	var req auth.RefreshRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+
+	// ШАГ 2: Валидируем входные данные
+	if err := c.validator.Struct(req); err != nil {
+		c.writeErrorResponse(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// ШАГ 3: Вызываем Use Case для ротации токенов
+	response, err := c.refreshUC.Execute(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, auth.ErrInvalidRefreshToken), errors.Is(err, auth.ErrRefreshTokenReused):
+			c.writeErrorResponse(w, err.Error(), http.StatusUnauthorized)
+		default:
+			c.writeErrorResponse(w, "Failed to refresh token", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// ШАГ 4: Возвращаем новую пару токенов
+	c.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// Logout завершает текущую сессию
+// POST /auth/logout
+func (c *AuthController) Logout(w http.ResponseWriter, r *http.Request) {
+	// ШАГ 1: Извлекаем данные токена из контекста (установлены middleware)
+	tokenData, ok := web.GetTokenDataFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// ШАГ 2: Отзываем сессию и текущий access токен
+	err := c.logoutUC.Execute(r.Context(), auth.LogoutRequest{
+		UserID:    tokenData.UserID,
+		TokenID:   tokenData.TokenID,
+		SessionID: tokenData.SessionID,
+		ExpiresAt: tokenData.ExpiresAt,
+	})
+	if err != nil {
+		c.writeErrorResponse(w, "Failed to logout", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // RegisterRoutes регистрирует маршруты контроллера
 func (c *AuthController) RegisterRoutes(mux *http.ServeMux) {
 	// Применяем middleware для всех маршрутов аутентификации
@@ -99,6 +166,21 @@ func (c *AuthController) RegisterRoutes(mux *http.ServeMux) {
 		web.LoggingMiddleware,
 		web.RecoveryMiddleware,
 	))
+
+	mux.HandleFunc("POST /auth/refresh", web.ChainMiddleware(
+		c.Refresh,
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+
+	mux.HandleFunc("POST /auth/logout", web.ChainMiddleware(
+		c.Logout,
+		c.authMiddleware.RequireAuth, // Выходим из сессии текущего токена
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
 }
 
 // ErrorResponse структура для ошибок
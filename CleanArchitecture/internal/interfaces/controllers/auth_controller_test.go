@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"clean-url-shortener/internal/infrastructure/database"
+	"clean-url-shortener/internal/infrastructure/external"
+	"clean-url-shortener/internal/infrastructure/web"
+	"clean-url-shortener/internal/usecase/auth"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newAuthTestMux поднимает маршруты аутентификации на встроенной SQLite базе
+func newAuthTestMux(t *testing.T) *http.ServeMux {
+	t.Helper()
+
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db := database.NewEmbedded(sqlDB)
+	if err := db.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	denylist, err := database.NewTokenDenylist(context.Background(), db, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	userRepo := database.NewUserRepository(db)
+	refreshRepo := database.NewRefreshTokenRepository(db)
+	hasher := external.NewBcryptPasswordHasher(4)
+	tokenGenerator := external.NewJWTTokenGenerator("test-secret", "test", 15*time.Minute)
+	issuer := auth.NewTokenIssuer(tokenGenerator, refreshRepo, time.Hour)
+	revoker := auth.NewSessionRevoker(refreshRepo, denylist, 15*time.Minute)
+
+	controller := NewAuthController(
+		auth.NewRegisterUseCase(userRepo, hasher, issuer),
+		auth.NewLoginUseCase(userRepo, hasher, issuer),
+		auth.NewRefreshUseCase(userRepo, refreshRepo, issuer, revoker),
+		auth.NewLogoutUseCase(revoker, denylist),
+		web.NewAuthMiddleware(tokenGenerator, denylist),
+	)
+
+	mux := http.NewServeMux()
+	controller.RegisterRoutes(mux)
+	return mux
+}
+
+// doAuthRequest отправляет JSON запрос и возвращает ответ
+func doAuthRequest(mux http.Handler, path, body, accessToken string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, req)
+	return rec
+}
+
+// registerTestUser регистрирует пользователя и возвращает пару токенов
+func registerTestUser(t *testing.T, mux http.Handler) auth.TokenPair {
+	t.Helper()
+
+	rec := doAuthRequest(mux, "/auth/register",
+		`{"email":"user@example.com","password":"Secr3t!pass","name":"User"}`, "")
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("register status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var resp auth.RegisterResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.AccessToken == "" || resp.RefreshToken == "" {
+		t.Fatalf("register response without tokens: %+v", resp)
+	}
+	return resp.TokenPair
+}
+
+// refreshTokens обменивает refresh токен и декодирует новую пару
+func refreshTokens(mux http.Handler, refreshToken string) (*httptest.ResponseRecorder, auth.TokenPair) {
+	rec := doAuthRequest(mux, "/auth/refresh", `{"refresh_token":"`+refreshToken+`"}`, "")
+	var pair auth.TokenPair
+	if rec.Code == http.StatusOK {
+		json.Unmarshal(rec.Body.Bytes(), &pair)
+	}
+	return rec, pair
+}
+
+func TestAuthControllerRefreshRotationAndReuse(t *testing.T) {
+	mux := newAuthTestMux(t)
+	first := registerTestUser(t, mux)
+
+	// Обмен выдает новую пару
+	rec, second := refreshTokens(mux, first.RefreshToken)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("refresh status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if second.RefreshToken == "" || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh token was not rotated: %+v", second)
+	}
+
+	// Повторное предъявление старого токена отзывает всю сессию
+	if rec, _ := refreshTokens(mux, first.RefreshToken); rec.Code != http.StatusUnauthorized ||
+		!strings.Contains(rec.Body.String(), auth.ErrRefreshTokenReused.Error()) {
+		t.Fatalf("reuse status = %d, body = %s", rec.Code, rec.Body)
+	}
+	if rec, _ := refreshTokens(mux, second.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after reuse status = %d, want 401", rec.Code)
+	}
+
+	// Access токены сессии тоже больше не принимаются
+	if rec := doAuthRequest(mux, "/auth/logout", "", second.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("access token after reuse status = %d, want 401", rec.Code)
+	}
+
+	// Неизвестный токен - 401 без побочных эффектов
+	if rec, _ := refreshTokens(mux, "unknown"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unknown token status = %d, want 401", rec.Code)
+	}
+}
+
+func TestAuthControllerLogout(t *testing.T) {
+	mux := newAuthTestMux(t)
+	registered := registerTestUser(t, mux)
+
+	// Вторая сессия того же пользователя
+	rec := doAuthRequest(mux, "/auth/login", `{"email":"user@example.com","password":"Secr3t!pass"}`, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("login status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var other auth.LoginResponse
+	if err := json.NewDecoder(rec.Body).Decode(&other); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec := doAuthRequest(mux, "/auth/logout", "", registered.AccessToken); rec.Code != http.StatusNoContent {
+		t.Fatalf("logout status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// Токены завершенной сессии отозваны
+	if rec := doAuthRequest(mux, "/auth/logout", "", registered.AccessToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("access token after logout status = %d, want 401", rec.Code)
+	}
+	if rec, _ := refreshTokens(mux, registered.RefreshToken); rec.Code != http.StatusUnauthorized {
+		t.Errorf("refresh after logout status = %d, want 401", rec.Code)
+	}
+
+	// Другая сессия продолжает работать
+	if rec, _ := refreshTokens(mux, other.RefreshToken); rec.Code != http.StatusOK {
+		t.Errorf("other session refresh status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
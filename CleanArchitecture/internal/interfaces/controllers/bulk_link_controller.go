@@ -0,0 +1,359 @@
+package controllers
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	domainDomain "clean-url-shortener/internal/domain/domain"
+	"clean-url-shortener/internal/infrastructure/web"
+	"clean-url-shortener/internal/usecase/link"
+)
+
+// maxBatchBodySize ограничение тела пакетного запроса
+const maxBatchBodySize = 10 << 20
+
+// exportFlushEvery через сколько строк выгрузки данные отправляются клиенту
+const exportFlushEvery = 100
+
+// BulkLinkController обрабатывает пакетное создание и выгрузку ссылок
+type BulkLinkController struct {
+	batchCreateUC  *link.BatchCreateLinksUseCase // Use Case пакетного создания
+	exportUC       *link.ExportLinksUseCase      // Use Case выгрузки
+	authMiddleware *web.AuthMiddleware           // Middleware для аутентификации
+	rateLimiter    *web.RateLimiter              // Ограничение частоты запросов
+}
+
+// NewBulkLinkController создает новый контроллер пакетных операций
+func NewBulkLinkController(
+	batchCreateUC *link.BatchCreateLinksUseCase,
+	exportUC *link.ExportLinksUseCase,
+	authMiddleware *web.AuthMiddleware,
+	rateLimiter *web.RateLimiter,
+) *BulkLinkController {
+	return &BulkLinkController{
+		batchCreateUC:  batchCreateUC,
+		exportUC:       exportUC,
+		authMiddleware: authMiddleware,
+		rateLimiter:    rateLimiter,
+	}
+}
+
+// BatchCreate создает ссылки пакетом
+// POST /links/batch
+// Content-Type: application/json - {"links": [{"original_url": ..., "custom_code": ...}]}
+// Content-Type: text/csv - колонки original_url[,custom_code], заголовок необязателен
+// ?domain=go.brand.com - пользовательский домен всех ссылок (или поле "domain" в JSON)
+func (c *BulkLinkController) BatchCreate(w http.ResponseWriter, r *http.Request) {
+	// ШАГ 1: Извлекаем ID пользователя из контекста (установлен middleware)
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// ШАГ 2: Разбираем тело в формате из Content-Type
+	body := http.MaxBytesReader(w, r.Body, maxBatchBodySize)
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var req link.BatchCreateLinksRequest
+	var err error
+	switch mediaType {
+	case "text/csv":
+		req.Links, err = parseBatchCSV(body)
+	case "application/json", "":
+		err = json.NewDecoder(body).Decode(&req)
+	default:
+		c.writeErrorResponse(w, "Unsupported content type: use application/json or text/csv", http.StatusUnsupportedMediaType)
+		return
+	}
+	if err != nil {
+		c.writeErrorResponse(w, "Invalid request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// ШАГ 3: Вызываем Use Case
+	if domain := r.URL.Query().Get("domain"); domain != "" {
+		req.Domain = domain
+	}
+	req.UserID = userID
+	response, err := c.batchCreateUC.Execute(r.Context(), req)
+	if err != nil {
+		switch {
+		case errors.Is(err, link.ErrEmptyBatch), errors.Is(err, link.ErrBatchTooLarge),
+			errors.Is(err, link.ErrDomainNotFound), errors.Is(err, link.ErrDomainNotVerified),
+			errors.Is(err, domainDomain.ErrInvalidHost):
+			c.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		default:
+			c.writeErrorResponse(w, "Failed to create links", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// ШАГ 4: Возвращаем результат по каждой строке
+	// Ошибки отдельных строк - часть ответа, поэтому статус 200
+	c.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// Export выгружает все ссылки пользователя
+// GET /links/export?format=csv|json
+func (c *BulkLinkController) Export(w http.ResponseWriter, r *http.Request) {
+	// ШАГ 1: Извлекаем ID пользователя из контекста (установлен middleware)
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// ШАГ 2: Выбираем формат
+	var writer exportWriter
+	switch format := r.URL.Query().Get("format"); format {
+	case "csv", "":
+		writer = newCSVExportWriter(w)
+	case "json":
+		writer = newJSONExportWriter(w)
+	default:
+		c.writeErrorResponse(w, "Invalid format: use csv or json", http.StatusBadRequest)
+		return
+	}
+
+	// ШАГ 3: Отдаем ссылки потоком по мере чтения из базы
+	// После первой строки статус уже отправлен, поэтому ошибку можно
+	// только залогировать и оборвать ответ
+	rc := http.NewResponseController(w)
+	rows := 0
+	err := c.exportUC.Execute(r.Context(), userID, func(l link.ExportedLink) error {
+		if err := writer.Write(l); err != nil {
+			return err
+		}
+		rows++
+		if rows%exportFlushEvery == 0 {
+			if err := writer.Flush(); err != nil {
+				return err
+			}
+			rc.Flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = writer.Close()
+	}
+	if err != nil {
+		log.Printf("Failed to export links for user %d: %v", userID, err)
+	}
+}
+
+// RegisterRoutes регистрирует маршруты контроллера
+func (c *BulkLinkController) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /links/batch", web.ChainMiddleware(
+		c.BatchCreate,
+		c.authMiddleware.RequireAuth, // Требуем аутентификации
+		c.rateLimiter.Limit(RateLimitBatchCreate),
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+
+	mux.HandleFunc("GET /links/export", web.ChainMiddleware(
+		c.Export,
+		c.authMiddleware.RequireAuth, // Выгрузка только своих ссылок
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+}
+
+// parseBatchCSV читает строки пакетного запроса из CSV
+// Если первая строка - заголовок, колонки ищутся по именам
+func parseBatchCSV(r io.Reader) ([]link.BatchLinkItem, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	urlCol, codeCol := 0, 1
+	var items []link.BatchLinkItem
+	for first := true; ; first = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return items, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if first && isBatchCSVHeader(record) {
+			urlCol, codeCol = -1, -1
+			for i, name := range record {
+				switch strings.ToLower(strings.TrimSpace(name)) {
+				case "original_url":
+					urlCol = i
+				case "custom_code":
+					codeCol = i
+				}
+			}
+			continue
+		}
+
+		items = append(items, link.BatchLinkItem{
+			OriginalURL: csvField(record, urlCol),
+			CustomCode:  csvField(record, codeCol),
+		})
+	}
+}
+
+// isBatchCSVHeader проверяет, что запись - заголовок с колонкой original_url
+func isBatchCSVHeader(record []string) bool {
+	for _, name := range record {
+		if strings.EqualFold(strings.TrimSpace(name), "original_url") {
+			return true
+		}
+	}
+	return false
+}
+
+// csvField возвращает колонку записи или пустую строку, если ее нет
+func csvField(record []string, col int) string {
+	if col < 0 || col >= len(record) {
+		return ""
+	}
+	return strings.TrimSpace(record[col])
+}
+
+// exportWriter пишет ссылки выгрузки в ответ в своем формате
+type exportWriter interface {
+	Write(l link.ExportedLink) error
+	Flush() error // отправить буферизованные строки в ответ
+	Close() error
+}
+
+// exportCSVHeader колонки CSV выгрузки
+var exportCSVHeader = []string{
+	"id", "short_code", "short_url", "original_url", "clicks_count",
+	"created_at", "expires_at", "max_clicks", "password_protected", "safety_status",
+}
+
+// csvExportWriter выгрузка в CSV с заголовком
+type csvExportWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+func newCSVExportWriter(w http.ResponseWriter) *csvExportWriter {
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="links.csv"`)
+	return &csvExportWriter{w: csv.NewWriter(w)}
+}
+
+func (e *csvExportWriter) Write(l link.ExportedLink) error {
+	e.writeHeader()
+
+	expiresAt := ""
+	if l.ExpiresAt != nil {
+		expiresAt = l.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+	e.w.Write([]string{
+		strconv.FormatUint(uint64(l.ID), 10),
+		l.ShortCode,
+		l.ShortURL,
+		l.OriginalURL,
+		strconv.FormatUint(uint64(l.ClicksCount), 10),
+		l.CreatedAt.UTC().Format(time.RFC3339),
+		expiresAt,
+		strconv.FormatUint(uint64(l.MaxClicks), 10),
+		strconv.FormatBool(l.PasswordProtected),
+		l.SafetyStatus,
+	})
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) Close() error {
+	// Пустая выгрузка - только заголовок
+	e.writeHeader()
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) Flush() error {
+	e.w.Flush()
+	return e.w.Error()
+}
+
+func (e *csvExportWriter) writeHeader() {
+	if !e.wroteHeader {
+		e.wroteHeader = true
+		e.w.Write(exportCSVHeader)
+	}
+}
+
+// jsonExportWriter выгрузка JSON массивом, элемент за элементом
+type jsonExportWriter struct {
+	w       io.Writer
+	enc     *json.Encoder
+	started bool
+}
+
+func newJSONExportWriter(w http.ResponseWriter) *jsonExportWriter {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", `attachment; filename="links.json"`)
+	return &jsonExportWriter{w: w, enc: json.NewEncoder(w)}
+}
+
+func (e *jsonExportWriter) Write(l link.ExportedLink) error {
+	sep := ","
+	if !e.started {
+		e.started = true
+		sep = "["
+	}
+	if _, err := io.WriteString(e.w, sep); err != nil {
+		return err
+	}
+	return e.enc.Encode(l)
+}
+
+// Flush ничего не делает: json.Encoder пишет в ответ сразу
+func (e *jsonExportWriter) Flush() error {
+	return nil
+}
+
+func (e *jsonExportWriter) Close() error {
+	if !e.started {
+		_, err := io.WriteString(e.w, "[]\n")
+		return err
+	}
+	_, err := io.WriteString(e.w, "]\n")
+	return err
+}
+
+// writeErrorResponse записывает ошибку в HTTP ответ
+func (c *BulkLinkController) writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	})
+}
+
+// writeJSONResponse записывает успешный JSON ответ
+func (c *BulkLinkController) writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ПРИНЦИПЫ ПАКЕТНЫХ ОПЕРАЦИЙ:
+// 1. Формат (JSON/CSV) - забота контроллера, Use Case работает со структурами
+// 2. Выгрузка потоковая: ответ пишется по мере чтения из базы
+// 3. Размер запроса ограничен и на уровне HTTP, и в Use Case
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"clean-url-shortener/internal/domain/user"
+	"clean-url-shortener/internal/infrastructure/database"
+	"clean-url-shortener/internal/infrastructure/web"
+	"clean-url-shortener/internal/usecase/link"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// stubValidator считает небезопасными URL на домене evil.example
+type stubValidator struct{}
+
+func (stubValidator) Validate(rawURL string) error { return nil }
+
+func (stubValidator) IsSafe(ctx context.Context, rawURL string) (bool, error) {
+	return !strings.Contains(rawURL, "evil.example"), nil
+}
+
+func (v stubValidator) IsSafeLocal(ctx context.Context, rawURL string) (bool, error) {
+	return v.IsSafe(ctx, rawURL)
+}
+
+// sequenceGenerator выдает коды по порядку, чтобы проверить коллизии
+type sequenceGenerator struct {
+	codes []string
+	next  int
+}
+
+func (g *sequenceGenerator) Generate() (string, error) {
+	code := g.codes[g.next%len(g.codes)]
+	g.next++
+	return code, nil
+}
+
+func (g *sequenceGenerator) GenerateCustom(customCode string) (string, error) {
+	return customCode, nil
+}
+
+// newBulkTestController поднимает контроллер на встроенной SQLite базе
+func newBulkTestController(t *testing.T, gen link.ShortCodeGenerator) (*BulkLinkController, uint) {
+	t.Helper()
+
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db := database.NewEmbedded(sqlDB)
+	if err := db.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := user.NewUser("bulk@example.com", "Bulk")
+	if err != nil {
+		t.Fatal(err)
+	}
+	u.HashedPassword = "hash"
+	if err := database.NewUserRepository(db).Save(context.Background(), u); err != nil {
+		t.Fatal(err)
+	}
+
+	linkRepo := database.NewLinkRepository(db)
+	domainRepo := database.NewDomainRepository(db)
+	controller := NewBulkLinkController(
+		link.NewBatchCreateLinksUseCase(linkRepo, domainRepo, stubValidator{}, gen, "http://short.test"),
+		link.NewExportLinksUseCase(linkRepo, domainRepo, "http://short.test"),
+		nil,
+		nil,
+	)
+	return controller, u.ID
+}
+
+func withUser(r *http.Request, userID uint) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), web.UserIDKey, userID))
+}
+
+func TestBulkLinkControllerBatchCreateCSV(t *testing.T) {
+	// Второй автоматический код совпадает с пользовательским "promo1"
+	gen := &sequenceGenerator{codes: []string{"auto01", "promo1", "auto02"}}
+	controller, userID := newBulkTestController(t, gen)
+
+	body := "custom_code,original_url\n" +
+		"promo1,https://example.com/a\n" +
+		",https://example.com/b\n" +
+		",https://evil.example/c\n" +
+		"promo1,https://example.com/d\n" +
+		",https://example.com/e\n"
+	req := httptest.NewRequest(http.MethodPost, "/links/batch", strings.NewReader(body))
+	req.Header.Set("Content-Type", "text/csv")
+	rec := httptest.NewRecorder()
+
+	controller.BatchCreate(rec, withUser(req, userID))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var resp link.BatchCreateLinksResponse
+	if err := json.NewDecoder(rec.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct{ status, code string }{
+		{link.BatchStatusCreated, "promo1"},
+		{link.BatchStatusCreated, "auto01"},
+		{link.BatchStatusInvalid, ""},
+		{link.BatchStatusConflict, ""},
+		{link.BatchStatusCreated, "auto02"}, // "promo1" занят пакетом - код сгенерирован заново
+	}
+	if resp.Created != 3 || resp.Failed != 2 || len(resp.Results) != len(want) {
+		t.Fatalf("response = %+v", resp)
+	}
+	for i, w := range want {
+		got := resp.Results[i]
+		if got.Row != i+1 || got.Status != w.status || got.ShortCode != w.code {
+			t.Errorf("row %d = %+v, want status %s code %q", i+1, got, w.status, w.code)
+		}
+	}
+}
+
+func TestBulkLinkControllerExport(t *testing.T) {
+	gen := &sequenceGenerator{}
+	for i := 0; i < 250; i++ {
+		gen.codes = append(gen.codes, fmt.Sprintf("code%03d", i))
+	}
+	controller, userID := newBulkTestController(t, gen)
+
+	var links []link.BatchLinkItem
+	for i := 0; i < 250; i++ {
+		links = append(links, link.BatchLinkItem{OriginalURL: fmt.Sprintf("https://example.com/%d", i)})
+	}
+	payload, _ := json.Marshal(link.BatchCreateLinksRequest{Links: links})
+	req := httptest.NewRequest(http.MethodPost, "/links/batch", strings.NewReader(string(payload)))
+	req.Header.Set("Content-Type", "application/json")
+	controller.BatchCreate(httptest.NewRecorder(), withUser(req, userID))
+
+	// JSON: все ссылки одним массивом
+	rec := httptest.NewRecorder()
+	controller.Export(rec, withUser(httptest.NewRequest(http.MethodGet, "/links/export?format=json", nil), userID))
+	var exported []link.ExportedLink
+	if err := json.NewDecoder(rec.Body).Decode(&exported); err != nil {
+		t.Fatalf("decode export: %v", err)
+	}
+	if len(exported) != 250 {
+		t.Errorf("exported %d links, want 250", len(exported))
+	}
+
+	// CSV: заголовок и строка на ссылку
+	rec = httptest.NewRecorder()
+	controller.Export(rec, withUser(httptest.NewRequest(http.MethodGet, "/links/export?format=csv", nil), userID))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 251 || !strings.HasPrefix(lines[0], "id,short_code,short_url") {
+		t.Errorf("csv export has %d lines, header %q", len(lines), lines[0])
+	}
+
+	// Чужие ссылки не выгружаются
+	rec = httptest.NewRecorder()
+	controller.Export(rec, withUser(httptest.NewRequest(http.MethodGet, "/links/export?format=json", nil), userID+1))
+	if body := strings.TrimSpace(rec.Body.String()); body != "[]" {
+		t.Errorf("export of another user = %s, want []", body)
+	}
+}
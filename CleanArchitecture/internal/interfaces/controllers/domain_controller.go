@@ -0,0 +1,240 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	domainDomain "clean-url-shortener/internal/domain/domain"
+	"clean-url-shortener/internal/infrastructure/web"
+	"clean-url-shortener/internal/usecase/domain"
+	"github.com/go-playground/validator/v10"
+)
+
+// DomainController обрабатывает HTTP запросы управления пользовательскими доменами
+type DomainController struct {
+	addDomainUC    *domain.AddDomainUseCase    // Use Case добавления домена
+	verifyDomainUC *domain.VerifyDomainUseCase // Use Case подтверждения через DNS
+	listDomainsUC  *domain.ListDomainsUseCase  // Use Case списка доменов
+	deleteDomainUC *domain.DeleteDomainUseCase // Use Case удаления домена
+	validator      *validator.Validate         // Валидатор входных данных
+	authMiddleware *web.AuthMiddleware         // Middleware для аутентификации
+}
+
+// NewDomainController создает новый контроллер доменов
+func NewDomainController(
+	addDomainUC *domain.AddDomainUseCase,
+	verifyDomainUC *domain.VerifyDomainUseCase,
+	listDomainsUC *domain.ListDomainsUseCase,
+	deleteDomainUC *domain.DeleteDomainUseCase,
+	authMiddleware *web.AuthMiddleware,
+) *DomainController {
+	return &DomainController{
+		addDomainUC:    addDomainUC,
+		verifyDomainUC: verifyDomainUC,
+		listDomainsUC:  listDomainsUC,
+		deleteDomainUC: deleteDomainUC,
+		validator:      validator.New(),
+		authMiddleware: authMiddleware,
+	}
+}
+
+// AddDomain регистрирует домен пользователя
+// POST /domains {"host": "go.brand.com"}
+func (c *DomainController) AddDomain(w http.ResponseWriter, r *http.Request) {
+	// ШАГ 1: Извлекаем ID пользователя из контекста (установлен middleware)
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// ШАГ 2: Декодируем и валидируем запрос
+	var req domain.AddDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		c.writeErrorResponse(w, "Invalid JSON format", http.StatusBadRequest)
+		return
+	}
+	req.UserID = userID
+	if err := c.validator.Struct(req); err != nil {
+		c.writeErrorResponse(w, "Validation failed: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// ШАГ 3: Вызываем Use Case
+	response, err := c.addDomainUC.Execute(r.Context(), req)
+	if err != nil {
+		c.handleError(w, err)
+		return
+	}
+
+	// ШАГ 4: Возвращаем домен с инструкцией по подтверждению
+	c.writeJSONResponse(w, response, http.StatusCreated)
+}
+
+// ListDomains возвращает домены пользователя
+// GET /domains
+func (c *DomainController) ListDomains(w http.ResponseWriter, r *http.Request) {
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	domains, err := c.listDomainsUC.Execute(r.Context(), userID)
+	if err != nil {
+		c.handleError(w, err)
+		return
+	}
+
+	c.writeJSONResponse(w, map[string]interface{}{"domains": domains}, http.StatusOK)
+}
+
+// VerifyDomain проверяет TXT запись домена
+// POST /domains/{id}/verify
+func (c *DomainController) VerifyDomain(w http.ResponseWriter, r *http.Request) {
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	domainID, ok := c.parseDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	response, err := c.verifyDomainUC.Execute(r.Context(), domain.VerifyDomainRequest{
+		DomainID: domainID,
+		UserID:   userID,
+	})
+	if err != nil {
+		c.handleError(w, err)
+		return
+	}
+
+	c.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// DeleteDomain удаляет домен без ссылок
+// DELETE /domains/{id}
+func (c *DomainController) DeleteDomain(w http.ResponseWriter, r *http.Request) {
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	domainID, ok := c.parseDomainID(w, r)
+	if !ok {
+		return
+	}
+
+	err := c.deleteDomainUC.Execute(r.Context(), domain.DeleteDomainRequest{
+		DomainID: domainID,
+		UserID:   userID,
+	})
+	if err != nil {
+		c.handleError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// parseDomainID извлекает ID домена из пути; при ошибке уже ответил 400
+func (c *DomainController) parseDomainID(w http.ResponseWriter, r *http.Request) (uint, bool) {
+	id, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || id == 0 {
+		c.writeErrorResponse(w, "Invalid domain id", http.StatusBadRequest)
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// handleError преобразует ошибки use case в HTTP статусы
+func (c *DomainController) handleError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, domainDomain.ErrInvalidHost), errors.Is(err, domain.ErrDomainReserved):
+		c.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, domain.ErrDomainNotFound):
+		c.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, domain.ErrAccessDenied):
+		c.writeErrorResponse(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domain.ErrDomainAlreadyExists), errors.Is(err, domain.ErrDomainInUse):
+		c.writeErrorResponse(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, domain.ErrVerificationFailed):
+		// Запрос корректен, но DNS запись еще не видна - можно повторить позже
+		c.writeErrorResponse(w, err.Error(), http.StatusUnprocessableEntity)
+	case errors.Is(err, domain.ErrDNSLookupFailed):
+		c.writeErrorResponse(w, domain.ErrDNSLookupFailed.Error(), http.StatusBadGateway)
+	default:
+		c.writeErrorResponse(w, "Failed to process domain request", http.StatusInternalServerError)
+	}
+}
+
+// RegisterRoutes регистрирует маршруты контроллера
+// Все маршруты требуют аутентификации: домены видит только владелец
+func (c *DomainController) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /domains", web.ChainMiddleware(
+		c.AddDomain,
+		c.authMiddleware.RequireAuth,
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+
+	mux.HandleFunc("GET /domains", web.ChainMiddleware(
+		c.ListDomains,
+		c.authMiddleware.RequireAuth,
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+
+	mux.HandleFunc("POST /domains/{id}/verify", web.ChainMiddleware(
+		c.VerifyDomain,
+		c.authMiddleware.RequireAuth,
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+
+	mux.HandleFunc("DELETE /domains/{id}", web.ChainMiddleware(
+		c.DeleteDomain,
+		c.authMiddleware.RequireAuth,
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+}
+
+// writeErrorResponse записывает ошибку в HTTP ответ
+func (c *DomainController) writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorResp := ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	json.NewEncoder(w).Encode(errorResp)
+}
+
+// writeJSONResponse записывает успешный JSON ответ
+func (c *DomainController) writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ПРИНЦИПЫ КОНТРОЛЛЕРА ДОМЕНОВ:
+// 1. Контроллер не знает, как устроено подтверждение через DNS
+// 2. Отсутствие записи (422) отличается от сбоя DNS (502): первое исправляет владелец
+// 3. Права доступа проверяет use case, контроллер только достает userID
@@ -0,0 +1,287 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"clean-url-shortener/internal/domain/user"
+	"clean-url-shortener/internal/infrastructure/database"
+	"clean-url-shortener/internal/infrastructure/external"
+	"clean-url-shortener/internal/usecase/domain"
+	"clean-url-shortener/internal/usecase/link"
+)
+
+// mapResolver TXT записи из таблицы вместо DNS
+type mapResolver struct {
+	records map[string][]string
+	err     error // сбой DNS для всех запросов
+}
+
+func (r *mapResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+	records, ok := r.records[name]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: name, IsNotFound: true}
+	}
+	return records, nil
+}
+
+// recordingPublisher запоминает метки канала опубликованных кликов
+type recordingPublisher struct {
+	sources []string
+}
+
+func (p *recordingPublisher) PublishLinkClicked(linkID uint, userAgent, ipAddress, referer, source string) error {
+	p.sources = append(p.sources, source)
+	return nil
+}
+
+// domainTestEnv контроллеры доменов и ссылок на общей SQLite базе
+type domainTestEnv struct {
+	domains   *DomainController
+	links     *LinkController
+	resolver  *mapResolver
+	publisher *recordingPublisher
+	owner     uint
+	stranger  uint
+}
+
+func newDomainTestEnv(t *testing.T) *domainTestEnv {
+	t.Helper()
+
+	sqlDB, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+
+	db := database.NewEmbedded(sqlDB)
+	if err := db.RunMigrations(); err != nil {
+		t.Fatal(err)
+	}
+
+	env := &domainTestEnv{
+		resolver:  &mapResolver{records: map[string][]string{}},
+		publisher: &recordingPublisher{},
+	}
+	for _, email := range []string{"owner@example.com", "stranger@example.com"} {
+		u, err := user.NewUser(email, "Tester")
+		if err != nil {
+			t.Fatal(err)
+		}
+		u.HashedPassword = "hash"
+		if err := database.NewUserRepository(db).Save(context.Background(), u); err != nil {
+			t.Fatal(err)
+		}
+		if env.owner == 0 {
+			env.owner = u.ID
+		} else {
+			env.stranger = u.ID
+		}
+	}
+
+	const baseURL = "http://short.test"
+	linkRepo := database.NewLinkRepository(db)
+	domainRepo := database.NewDomainRepository(db)
+	hasher := external.NewBcryptPasswordHasher(4)
+	renderer, err := external.NewQRRenderer(external.QRConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env.domains = NewDomainController(
+		domain.NewAddDomainUseCase(domainRepo, []string{"short.test"}),
+		domain.NewVerifyDomainUseCase(domainRepo, env.resolver),
+		domain.NewListDomainsUseCase(domainRepo),
+		domain.NewDeleteDomainUseCase(domainRepo, linkRepo),
+		nil,
+	)
+	env.links = NewLinkController(
+		link.NewCreateLinkUseCase(linkRepo, domainRepo, stubValidator{}, &sequenceGenerator{codes: []string{"auto01"}}, hasher, baseURL),
+		link.NewRedirectUseCase(linkRepo, domainRepo, env.publisher, hasher, baseURL),
+		link.NewGenerateQRUseCase(linkRepo, domainRepo, renderer, baseURL),
+		nil,
+		nil,
+	)
+	return env
+}
+
+// call вызывает обработчик от имени пользователя
+func (env *domainTestEnv) call(handler http.HandlerFunc, userID uint, method, target, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	handler(rec, withUser(req, userID))
+	return rec
+}
+
+// verify вызывает подтверждение домена по ID
+func (env *domainTestEnv) verify(userID, domainID uint) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /domains/{id}/verify", env.domains.VerifyDomain)
+
+	req := httptest.NewRequest(http.MethodPost, "/domains/"+strconv.FormatUint(uint64(domainID), 10)+"/verify", nil)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, withUser(req, userID))
+	return rec
+}
+
+// redirect выполняет переход по ссылке на хосте host
+func (env *domainTestEnv) redirect(host, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	req.Host = host
+	rec := httptest.NewRecorder()
+	env.links.Redirect(rec, req)
+	return rec
+}
+
+func TestDomainControllerVerificationFlow(t *testing.T) {
+	env := newDomainTestEnv(t)
+
+	// Неподтвержденная заявка другого пользователя не держит имя
+	if rec := env.call(env.domains.AddDomain, env.stranger, http.MethodPost, "/domains", `{"host":"go.brand.example"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("add by stranger status = %d, body = %s", rec.Code, rec.Body)
+	}
+	rec := env.call(env.domains.AddDomain, env.owner, http.MethodPost, "/domains", `{"host":"Go.Brand.Example."}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("add status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var added domain.DomainResponse
+	if err := json.NewDecoder(rec.Body).Decode(&added); err != nil {
+		t.Fatal(err)
+	}
+	if added.Host != "go.brand.example" || added.Verified ||
+		added.Verification.Name != "_shortener-verification.go.brand.example" {
+		t.Fatalf("added domain = %+v", added)
+	}
+
+	// Хост самого сервиса и некорректное имя
+	for body, want := range map[string]int{
+		`{"host":"short.test"}`:            http.StatusBadRequest,
+		`{"host":"https://go.brand.test"}`: http.StatusBadRequest,
+		`{"host":"go.brand.example"}`:      http.StatusConflict,
+	} {
+		if rec := env.call(env.domains.AddDomain, env.owner, http.MethodPost, "/domains", body); rec.Code != want {
+			t.Errorf("add %s status = %d, want %d", body, rec.Code, want)
+		}
+	}
+
+	// До подтверждения ссылки на домене не создаются
+	createBody := `{"original_url":"https://brand.example/sale","custom_code":"sale","domain":"go.brand.example"}`
+	if rec := env.call(env.links.CreateLink, env.owner, http.MethodPost, "/links", createBody); rec.Code != http.StatusBadRequest ||
+		!strings.Contains(rec.Body.String(), link.ErrDomainNotVerified.Error()) {
+		t.Errorf("create on unverified domain status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// Записи нет - 422, сбой DNS - 502, чужой домен - 403
+	if rec := env.verify(env.owner, added.ID); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("verify without record status = %d", rec.Code)
+	}
+	env.resolver.err = errors.New("server misbehaving")
+	if rec := env.verify(env.owner, added.ID); rec.Code != http.StatusBadGateway {
+		t.Errorf("verify with DNS failure status = %d", rec.Code)
+	}
+	env.resolver.err = nil
+	if rec := env.verify(env.stranger, added.ID); rec.Code != http.StatusForbidden {
+		t.Errorf("verify by stranger status = %d", rec.Code)
+	}
+
+	env.resolver.records[added.Verification.Name] = []string{"unrelated", added.Verification.Value}
+	rec = env.verify(env.owner, added.ID)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"verified":true`) {
+		t.Fatalf("verify status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// Подтвержденный домен не перехватить
+	if rec := env.call(env.domains.AddDomain, env.stranger, http.MethodPost, "/domains", `{"host":"go.brand.example"}`); rec.Code != http.StatusConflict {
+		t.Errorf("add verified domain by stranger status = %d", rec.Code)
+	}
+}
+
+func TestDomainControllerBrandedLinks(t *testing.T) {
+	env := newDomainTestEnv(t)
+
+	rec := env.call(env.domains.AddDomain, env.owner, http.MethodPost, "/domains", `{"host":"go.brand.example"}`)
+	var added domain.DomainResponse
+	json.NewDecoder(rec.Body).Decode(&added)
+	env.resolver.records[added.Verification.Name] = []string{added.Verification.Value}
+	if rec := env.verify(env.owner, added.ID); rec.Code != http.StatusOK {
+		t.Fatalf("verify status = %d", rec.Code)
+	}
+
+	// Один и тот же код на домене по умолчанию и на пользовательском домене
+	rec = env.call(env.links.CreateLink, env.owner, http.MethodPost, "/links",
+		`{"original_url":"https://example.com/default","custom_code":"sale"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create default status = %d, body = %s", rec.Code, rec.Body)
+	}
+	rec = env.call(env.links.CreateLink, env.owner, http.MethodPost, "/links",
+		`{"original_url":"https://brand.example/sale","custom_code":"sale","domain":"go.brand.example"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("create branded status = %d, body = %s", rec.Code, rec.Body)
+	}
+	var created link.CreateLinkResponse
+	json.NewDecoder(rec.Body).Decode(&created)
+	if created.ShortURL != "http://go.brand.example/sale" || created.Domain != "go.brand.example" {
+		t.Errorf("branded link = %+v", created)
+	}
+
+	// Повтор кода на том же домене и чужой домен
+	if rec := env.call(env.links.CreateLink, env.owner, http.MethodPost, "/links",
+		`{"original_url":"https://brand.example/x","custom_code":"sale","domain":"go.brand.example"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("create duplicate status = %d", rec.Code)
+	}
+	if rec := env.call(env.links.CreateLink, env.stranger, http.MethodPost, "/links",
+		`{"original_url":"https://example.com/x","domain":"go.brand.example"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("create on another user's domain status = %d", rec.Code)
+	}
+
+	// Ссылка выбирается по паре (Host, код)
+	tests := []struct {
+		host, path string
+		status     int
+		location   string
+	}{
+		{"go.brand.example", "/sale", http.StatusFound, "https://brand.example/sale"},
+		{"GO.brand.example:443", "/sale", http.StatusFound, "https://brand.example/sale"},
+		{"short.test", "/sale", http.StatusFound, "https://example.com/default"},
+		{"203.0.113.7:8080", "/sale", http.StatusFound, "https://example.com/default"}, // неизвестный хост - домен по умолчанию
+		{"go.brand.example", "/missing", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		rec := env.redirect(tt.host, tt.path)
+		if rec.Code != tt.status || rec.Header().Get("Location") != tt.location {
+			t.Errorf("GET %s%s = %d %q, want %d %q", tt.host, tt.path, rec.Code, rec.Header().Get("Location"), tt.status, tt.location)
+		}
+	}
+
+	// Домен со ссылками не удаляется
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /domains/{id}", env.domains.DeleteDomain)
+	req := httptest.NewRequest(http.MethodDelete, "/domains/"+strconv.FormatUint(uint64(added.ID), 10), nil)
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, withUser(req, env.owner))
+	if rec.Code != http.StatusConflict {
+		t.Errorf("delete domain with links status = %d", rec.Code)
+	}
+
+	// Список доменов владельца
+	rec = env.call(env.domains.ListDomains, env.owner, http.MethodGet, "/domains", "")
+	var list struct {
+		Domains []domain.DomainResponse `json:"domains"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&list); err != nil || len(list.Domains) != 1 || !list.Domains[0].Verified {
+		t.Errorf("list domains = %+v, %v", list, err)
+	}
+}
@@ -1,35 +1,57 @@
 package controllers
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"html/template"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 	
+	domainDomain "clean-url-shortener/internal/domain/domain"
+	domainLink "clean-url-shortener/internal/domain/link"
 	"clean-url-shortener/internal/usecase/link"
 	"clean-url-shortener/internal/infrastructure/web"
 	"github.com/go-playground/validator/v10"
 )
 
+// Имена маршрутов для лимитов частоты запросов
+const (
+	RateLimitCreateLink  = "create_link"  // POST /links
+	RateLimitBatchCreate = "batch_create" // POST /links/batch
+	RateLimitRedirect    = "redirect"     // переход и ввод пароля ссылки
+	RateLimitQR          = "qr"           // GET /links/{code}/qr
+)
+
 // LinkController обрабатывает HTTP запросы для работы со ссылками
 type LinkController struct {
 	createLinkUC   *link.CreateLinkUseCase // Use Case для создания ссылок
 	redirectUC     *link.RedirectUseCase   // Use Case для редиректа
+	generateQRUC   *link.GenerateQRUseCase // Use Case для QR кодов
 	validator      *validator.Validate     // Валидатор входных данных
 	authMiddleware *web.AuthMiddleware     // Middleware для аутентификации
+	rateLimiter    *web.RateLimiter        // Ограничение частоты запросов
 }
 
 // NewLinkController создает новый контроллер ссылок
 func NewLinkController(
 	createLinkUC *link.CreateLinkUseCase,
 	redirectUC *link.RedirectUseCase,
+	generateQRUC *link.GenerateQRUseCase,
 	authMiddleware *web.AuthMiddleware,
+	rateLimiter *web.RateLimiter,
 ) *LinkController {
 	return &LinkController{
 		createLinkUC:   createLinkUC,
 		redirectUC:     redirectUC,
+		generateQRUC:   generateQRUC,
 		validator:      validator.New(),
 		authMiddleware: authMiddleware,
+		rateLimiter:    rateLimiter,
 	}
 }
 
@@ -72,6 +94,7 @@ func (c *LinkController) CreateLink(w http.ResponseWriter, r *http.Request) {
 
 // Redirect обрабатывает HTTP запрос на редирект по короткой ссылке
 // GET /{shortCode}
+// POST /{shortCode} - отправка формы с паролем защищенной ссылки
 func (c *LinkController) Redirect(w http.ResponseWriter, r *http.Request) {
 	// ШАГ 1: Извлекаем короткий код из URL пути
 	path := strings.TrimPrefix(r.URL.Path, "/")
@@ -83,15 +106,21 @@ func (c *LinkController) Redirect(w http.ResponseWriter, r *http.Request) {
 	// ШАГ 2: Создаем запрос для Use Case
 	req := link.RedirectRequest{
 		ShortCode: path,
+		Host:      r.Host, // Домен ссылки: go.brand.com/sale и BASE_URL/sale - разные ссылки
 		UserAgent: r.Header.Get("User-Agent"),
 		IPAddress: c.getClientIP(r),
 		Referer:   r.Header.Get("Referer"),
+		Source:    r.URL.Query().Get(link.SourceParam), // "qr" для переходов по QR коду
+	}
+	if r.Method == http.MethodPost {
+		// Пароль принимаем только из тела формы, чтобы он не попал в логи URL
+		req.Password = r.PostFormValue("password")
 	}
 
 	// ШАГ 3: Вызываем Use Case для редиректа
 	response, err := c.redirectUC.Execute(r.Context(), req)
 	if err != nil {
-		c.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+		c.handleRedirectError(w, req, err)
 		return
 	}
 
@@ -99,6 +128,125 @@ func (c *LinkController) Redirect(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, response.OriginalURL, http.StatusFound)
 }
 
+// handleRedirectError переводит ошибки доступности ссылки в HTTP статусы
+func (c *LinkController) handleRedirectError(w http.ResponseWriter, req link.RedirectRequest, err error) {
+	switch {
+	case errors.Is(err, domainLink.ErrLinkExpired), errors.Is(err, domainLink.ErrClickLimitReached):
+		// Ссылка существовала, но больше не работает
+		c.writeErrorResponse(w, err.Error(), http.StatusGone)
+	case errors.Is(err, domainLink.ErrLinkBlocked):
+		// Адрес назначения признан вредоносным - не отправляем туда посетителя
+		c.writeErrorResponse(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, domainLink.ErrPasswordRequired):
+		c.writePasswordForm(w, req, false)
+	case errors.Is(err, domainLink.ErrInvalidPassword):
+		c.writePasswordForm(w, req, true)
+	case errors.Is(err, link.ErrLinkNotFound), errors.Is(err, domainLink.ErrLinkNotActive):
+		// До начала действия ссылка для посетителей не существует
+		c.writeErrorResponse(w, link.ErrLinkNotFound.Error(), http.StatusNotFound)
+	default:
+		c.writeErrorResponse(w, "Failed to resolve link", http.StatusInternalServerError)
+	}
+}
+
+// passwordFormTemplate форма ввода пароля защищенной ссылки
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Password required</title></head>
+<body>
+<form method="POST" action="/{{.ShortCode}}{{if .Source}}?utm_source={{.Source}}{{end}}">
+{{if .Invalid}}<p>Invalid password, try again.</p>{{end}}
+<label>This link is password protected: <input type="password" name="password" autofocus required></label>
+<button type="submit">Open</button>
+</form>
+</body>
+</html>
+`))
+
+// writePasswordForm отвечает 401 с HTML формой ввода пароля
+// Метка канала сохраняется в адресе формы, чтобы переход после ввода
+// пароля попал в статистику с тем же источником
+func (c *LinkController) writePasswordForm(w http.ResponseWriter, req link.RedirectRequest, invalid bool) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusUnauthorized)
+
+	passwordFormTemplate.Execute(w, struct {
+		ShortCode string
+		Source    string
+		Invalid   bool
+	}{req.ShortCode, req.Source, invalid})
+}
+
+// GetQRCode возвращает QR код короткой ссылки пользователя
+// GET /links/{code}/qr?format=png|svg&size=256&ecc=M&fg=000000&bg=ffffff&logo=true&domain=go.brand.com
+func (c *LinkController) GetQRCode(w http.ResponseWriter, r *http.Request) {
+	// ШАГ 1: Извлекаем ID пользователя из контекста (установлен middleware)
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// ШАГ 2: Собираем параметры изображения из query
+	query := r.URL.Query()
+	req := link.GenerateQRRequest{
+		ShortCode:  r.PathValue("code"),
+		Domain:     query.Get("domain"),
+		UserID:     userID,
+		Format:     query.Get("format"),
+		ECC:        query.Get("ecc"),
+		Foreground: query.Get("fg"),
+		Background: query.Get("bg"),
+	}
+	if value := query.Get("size"); value != "" {
+		size, err := strconv.Atoi(value)
+		if err != nil {
+			c.writeErrorResponse(w, "Invalid size", http.StatusBadRequest)
+			return
+		}
+		req.Size = size
+	}
+	if value := query.Get("logo"); value != "" {
+		logo, err := strconv.ParseBool(value)
+		if err != nil {
+			c.writeErrorResponse(w, "Invalid logo flag", http.StatusBadRequest)
+			return
+		}
+		req.Logo = logo
+	}
+
+	// ШАГ 3: Вызываем Use Case
+	response, err := c.generateQRUC.Execute(r.Context(), req)
+	if err != nil {
+		c.handleQRError(w, err)
+		return
+	}
+
+	// ШАГ 4: Отдаем изображение; ETag позволяет клиенту не скачивать его повторно
+	sum := sha256.Sum256(response.Image)
+	w.Header().Set("Content-Type", response.ContentType)
+	w.Header().Set("Cache-Control", "private, max-age=86400")
+	w.Header().Set("ETag", `"`+hex.EncodeToString(sum[:16])+`"`)
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(response.Image))
+}
+
+// handleQRError переводит ошибки генерации QR кода в HTTP статусы
+func (c *LinkController) handleQRError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, link.ErrInvalidQROptions), errors.Is(err, link.ErrQRLogoNotAvailable),
+		errors.Is(err, domainDomain.ErrInvalidHost), errors.Is(err, link.ErrDomainNotFound),
+		errors.Is(err, link.ErrDomainNotVerified):
+		c.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, link.ErrLinkNotFound):
+		c.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, link.ErrAccessDenied), errors.Is(err, domainLink.ErrLinkBlocked):
+		c.writeErrorResponse(w, err.Error(), http.StatusForbidden)
+	default:
+		c.writeErrorResponse(w, "Failed to generate QR code", http.StatusInternalServerError)
+	}
+}
+
 // GetUserLinks возвращает все ссылки пользователя (заглушка для примера)
 // GET /links
 func (c *LinkController) GetUserLinks(w http.ResponseWriter, r *http.Request) {
@@ -141,7 +289,8 @@ func (c *LinkController) RegisterRoutes(mux *http.ServeMux) {
 	// Защищенные маршруты (требуют аутентификации)
 	mux.HandleFunc("POST /links", web.ChainMiddleware(
 		c.CreateLink,
-		c.authMiddleware.RequireAuth, // Требуем аутентификации
+		c.authMiddleware.RequireAuth,             // Требуем аутентификации
+		c.rateLimiter.Limit(RateLimitCreateLink), // Лимит на пользователя
 		web.CORSMiddleware,
 		web.LoggingMiddleware,
 		web.RecoveryMiddleware,
@@ -155,13 +304,31 @@ func (c *LinkController) RegisterRoutes(mux *http.ServeMux) {
 		web.RecoveryMiddleware,
 	))
 
+	mux.HandleFunc("GET /links/{code}/qr", web.ChainMiddleware(
+		c.GetQRCode,
+		c.authMiddleware.RequireAuth,     // QR код печатает только владелец ссылки
+		c.rateLimiter.Limit(RateLimitQR), // Отрисовка без кеша заметно нагружает CPU
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+
 	// Публичные маршруты (не требуют аутентификации)
 	mux.HandleFunc("GET /", web.ChainMiddleware(
 		c.Redirect,
+		c.rateLimiter.Limit(RateLimitRedirect), // Лимит на IP
 		web.CORSMiddleware,
 		web.LoggingMiddleware,
 		web.RecoveryMiddleware,
 	))
+
+	// Форма пароля защищенной ссылки отправляется на тот же адрес
+	mux.HandleFunc("POST /{shortCode}", web.ChainMiddleware(
+		c.Redirect,
+		c.rateLimiter.Limit(RateLimitRedirect), // Заодно ограничивает подбор пароля
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
 }
 
 // getClientIP извлекает IP адрес клиента из HTTP запроса
@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"encoding/json"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"clean-url-shortener/internal/usecase/domain"
+)
+
+// qr запрашивает QR код через маршрутизатор, чтобы заполнился {code}
+func (env *domainTestEnv) qr(userID uint, target string, header http.Header) *httptest.ResponseRecorder {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /links/{code}/qr", env.links.GetQRCode)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, withUser(req, userID))
+	return rec
+}
+
+func TestLinkControllerQRCode(t *testing.T) {
+	env := newDomainTestEnv(t)
+
+	if rec := env.call(env.links.CreateLink, env.owner, http.MethodPost, "/links",
+		`{"original_url":"https://example.com/flyer","custom_code":"flyer"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// PNG по умолчанию: 256x256
+	rec := env.qr(env.owner, "/links/flyer/qr", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/png" {
+		t.Fatalf("qr status = %d, content type = %q, body = %s", rec.Code, rec.Header().Get("Content-Type"), rec.Body)
+	}
+	img, err := png.Decode(rec.Body)
+	if err != nil || img.Bounds().Dx() != 256 {
+		t.Fatalf("qr image = %v, %v", img.Bounds(), err)
+	}
+
+	// Повторный запрос с тем же ETag не передает изображение
+	etag := rec.Header().Get("ETag")
+	if rec := env.qr(env.owner, "/links/flyer/qr", http.Header{"If-None-Match": {etag}}); etag == "" || rec.Code != http.StatusNotModified {
+		t.Errorf("conditional qr status = %d, etag = %q", rec.Code, etag)
+	}
+
+	rec = env.qr(env.owner, "/links/flyer/qr?format=svg&size=512&ecc=q&fg=1A237E&bg=fff", nil)
+	if rec.Code != http.StatusOK || rec.Header().Get("Content-Type") != "image/svg+xml" ||
+		!strings.Contains(rec.Body.String(), `width="512"`) || !strings.Contains(rec.Body.String(), `fill="#1a237e"`) {
+		t.Errorf("svg qr status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	tests := []struct {
+		name   string
+		userID uint
+		target string
+		status int
+	}{
+		{"size too small", env.owner, "/links/flyer/qr?size=10", http.StatusBadRequest},
+		{"size not a number", env.owner, "/links/flyer/qr?size=big", http.StatusBadRequest},
+		{"unknown format", env.owner, "/links/flyer/qr?format=gif", http.StatusBadRequest},
+		{"unknown ecc", env.owner, "/links/flyer/qr?ecc=X", http.StatusBadRequest},
+		{"inverted colors", env.owner, "/links/flyer/qr?fg=ffffff&bg=000000", http.StatusBadRequest},
+		{"low contrast", env.owner, "/links/flyer/qr?fg=999999&bg=aaaaaa", http.StatusBadRequest},
+		{"logo with low ecc", env.owner, "/links/flyer/qr?logo=true&ecc=L", http.StatusBadRequest},
+		{"logo not configured", env.owner, "/links/flyer/qr?logo=true", http.StatusBadRequest},
+		{"unknown domain", env.owner, "/links/flyer/qr?domain=other.example", http.StatusBadRequest},
+		{"missing link", env.owner, "/links/missing/qr", http.StatusNotFound},
+		{"another user's link", env.stranger, "/links/flyer/qr", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		if rec := env.qr(tt.userID, tt.target, nil); rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d, body = %s", tt.name, rec.Code, tt.status, rec.Body)
+		}
+	}
+}
+
+func TestLinkControllerQRCodeOnCustomDomain(t *testing.T) {
+	env := newDomainTestEnv(t)
+
+	rec := env.call(env.domains.AddDomain, env.owner, http.MethodPost, "/domains", `{"host":"go.brand.example"}`)
+	var added domain.DomainResponse
+	json.NewDecoder(rec.Body).Decode(&added)
+	env.resolver.records[added.Verification.Name] = []string{added.Verification.Value}
+	if rec := env.verify(env.owner, added.ID); rec.Code != http.StatusOK {
+		t.Fatalf("verify status = %d", rec.Code)
+	}
+	if rec := env.call(env.links.CreateLink, env.owner, http.MethodPost, "/links",
+		`{"original_url":"https://brand.example/sale","custom_code":"sale","domain":"go.brand.example"}`); rec.Code != http.StatusCreated {
+		t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body)
+	}
+
+	// Код есть только на пользовательском домене
+	if rec := env.qr(env.owner, "/links/sale/qr", nil); rec.Code != http.StatusNotFound {
+		t.Errorf("qr on default domain status = %d", rec.Code)
+	}
+	if rec := env.qr(env.owner, "/links/sale/qr?domain=go.brand.example", nil); rec.Code != http.StatusOK {
+		t.Errorf("qr on custom domain status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
+
+func TestLinkControllerRedirectRecordsSource(t *testing.T) {
+	env := newDomainTestEnv(t)
+
+	for _, body := range []string{
+		`{"original_url":"https://example.com/flyer","custom_code":"flyer"}`,
+		`{"original_url":"https://example.com/secret","custom_code":"secret","password":"letmein"}`,
+	} {
+		if rec := env.call(env.links.CreateLink, env.owner, http.MethodPost, "/links", body); rec.Code != http.StatusCreated {
+			t.Fatalf("create status = %d, body = %s", rec.Code, rec.Body)
+		}
+	}
+
+	// Переход по QR коду и обычный переход
+	for _, path := range []string{"/flyer?utm_source=qr", "/flyer"} {
+		if rec := env.redirect("short.test", path); rec.Code != http.StatusFound {
+			t.Fatalf("GET %s status = %d", path, rec.Code)
+		}
+	}
+	if got := env.publisher.sources; len(got) != 2 || got[0] != "qr" || got[1] != "" {
+		t.Errorf("published sources = %q, want [qr, \"\"]", got)
+	}
+
+	// Форма пароля отправляется с той же меткой канала
+	rec := env.redirect("short.test", "/secret?utm_source=qr")
+	if rec.Code != http.StatusUnauthorized || !strings.Contains(rec.Body.String(), `action="/secret?utm_source=qr"`) {
+		t.Errorf("password form status = %d, body = %s", rec.Code, rec.Body)
+	}
+}
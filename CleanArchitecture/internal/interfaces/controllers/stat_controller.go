@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"clean-url-shortener/internal/infrastructure/web"
+	"clean-url-shortener/internal/usecase/stat"
+)
+
+// StatController обрабатывает HTTP запросы статистики переходов
+type StatController struct {
+	getLinkStatsUC *stat.GetLinkStatsUseCase // Use Case для статистики ссылки
+	authMiddleware *web.AuthMiddleware       // Middleware для аутентификации
+}
+
+// NewStatController создает новый контроллер статистики
+func NewStatController(
+	getLinkStatsUC *stat.GetLinkStatsUseCase,
+	authMiddleware *web.AuthMiddleware,
+) *StatController {
+	return &StatController{
+		getLinkStatsUC: getLinkStatsUC,
+		authMiddleware: authMiddleware,
+	}
+}
+
+// GetLinkStats возвращает агрегированную статистику ссылки
+// GET /links/{id}/stats?period=7d
+func (c *StatController) GetLinkStats(w http.ResponseWriter, r *http.Request) {
+	// ШАГ 1: Извлекаем ID пользователя из контекста (установлен middleware)
+	userID, ok := web.GetUserIDFromContext(r.Context())
+	if !ok {
+		c.writeErrorResponse(w, "User not authenticated", http.StatusUnauthorized)
+		return
+	}
+
+	// ШАГ 2: Извлекаем ID ссылки из пути
+	linkID, err := strconv.ParseUint(r.PathValue("id"), 10, 64)
+	if err != nil || linkID == 0 {
+		c.writeErrorResponse(w, "Invalid link id", http.StatusBadRequest)
+		return
+	}
+
+	// ШАГ 3: Вызываем Use Case
+	response, err := c.getLinkStatsUC.Execute(r.Context(), stat.GetLinkStatsRequest{
+		LinkID: uint(linkID),
+		UserID: userID,
+		Period: r.URL.Query().Get("period"),
+	})
+	if err != nil {
+		// ШАГ 4: Преобразуем ошибки use case в HTTP статусы
+		switch {
+		case errors.Is(err, stat.ErrInvalidPeriod):
+			c.writeErrorResponse(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, stat.ErrLinkNotFound):
+			c.writeErrorResponse(w, err.Error(), http.StatusNotFound)
+		case errors.Is(err, stat.ErrAccessDenied):
+			c.writeErrorResponse(w, err.Error(), http.StatusForbidden)
+		default:
+			c.writeErrorResponse(w, "Failed to load statistics", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	// ШАГ 5: Возвращаем успешный ответ
+	c.writeJSONResponse(w, response, http.StatusOK)
+}
+
+// RegisterRoutes регистрирует маршруты контроллера
+func (c *StatController) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /links/{id}/stats", web.ChainMiddleware(
+		c.GetLinkStats,
+		c.authMiddleware.RequireAuth, // Статистику видит только владелец
+		web.CORSMiddleware,
+		web.LoggingMiddleware,
+		web.RecoveryMiddleware,
+	))
+}
+
+// writeErrorResponse записывает ошибку в HTTP ответ
+func (c *StatController) writeErrorResponse(w http.ResponseWriter, message string, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	errorResp := ErrorResponse{
+		Error:   http.StatusText(statusCode),
+		Message: message,
+		Code:    statusCode,
+	}
+
+	json.NewEncoder(w).Encode(errorResp)
+}
+
+// writeJSONResponse записывает успешный JSON ответ
+func (c *StatController) writeJSONResponse(w http.ResponseWriter, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// ПРИНЦИПЫ КОНТРОЛЛЕРА СТАТИСТИКИ:
+// 1. Путь и query параметры превращаются в GetLinkStatsRequest
+// 2. Ошибки use case отображаются в 400/403/404, остальное - 500 без деталей
+// 3. Права доступа проверяет use case, контроллер только достает userID
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"clean-url-shortener/internal/domain/token"
+)
+
+// CleanupExpiredTokensUseCase удаляет истекшие refresh токены
+// Каждая ротация добавляет запись, поэтому без очистки таблица только растет
+type CleanupExpiredTokensUseCase struct {
+	refreshRepo token.Repository // Из domain слоя
+}
+
+// NewCleanupExpiredTokensUseCase создает Use Case очистки refresh токенов
+func NewCleanupExpiredTokensUseCase(refreshRepo token.Repository) *CleanupExpiredTokensUseCase {
+	return &CleanupExpiredTokensUseCase{
+		refreshRepo: refreshRepo,
+	}
+}
+
+// Execute удаляет refresh токены, истекшие к текущему моменту
+// Истекший токен не принимается в любом случае, а повторное использование
+// отслеживается только пока токен мог бы быть валиден
+func (uc *CleanupExpiredTokensUseCase) Execute(ctx context.Context) (int64, error) {
+	return uc.refreshRepo.DeleteExpired(ctx, time.Now())
+}
+
+// Run выполняет очистку сразу и затем каждые interval, пока не отменен ctx
+func (uc *CleanupExpiredTokensUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := uc.Execute(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("Failed to clean up expired refresh tokens: %v", err)
+		case deleted > 0:
+			log.Printf("Cleaned up %d expired refresh tokens", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package auth
 
+import (
+	"context"
+	"time"
+)
+
 // ИНТЕРФЕЙСЫ ДЛЯ ЗАВИСИМОСТЕЙ USE CASES
 // Эти интерфейсы определяют контракты для внешних сервисов,
 // которые будут реализованы в infrastructure слое
@@ -17,17 +22,40 @@ type PasswordHasher interface {
 // TokenGenerator определяет интерфейс для генерации JWT токенов
 // Скрывает детали реализации JWT
 type TokenGenerator interface {
-	// Generate создает токен для пользователя
-	Generate(userID uint, email string) (string, error)
+	// Generate создает короткоживущий access токен пользователя
+	// sessionID связывает токен с семейством refresh токенов
+	Generate(userID uint, email, sessionID string) (*AccessToken, error)
 	
 	// Validate проверяет валидность токена и возвращает данные
 	Validate(token string) (*TokenData, error)
 }
 
+// AccessToken выпущенный access токен
+type AccessToken struct {
+	Token     string
+	ID        string // уникальный ID токена (jti)
+	ExpiresAt time.Time
+}
+
 // TokenData содержит данные из токена
 type TokenData struct {
-	UserID uint
-	Email  string
+	UserID    uint
+	Email     string
+	TokenID   string    // jti
+	SessionID string    // семейство refresh токенов; пусто у старых токенов
+	ExpiresAt time.Time
+}
+
+// TokenDenylist определяет интерфейс списка отозванных токенов
+// Access токен нельзя "забрать" у клиента, поэтому до истечения он
+// проверяется по этому списку
+type TokenDenylist interface {
+	// Revoke отзывает токен или сессию по ID до момента until
+	// (после until токен истек бы и сам)
+	Revoke(ctx context.Context, id string, until time.Time) error
+	
+	// IsRevoked проверяет, отозван ли хотя бы один из ID
+	IsRevoked(ctx context.Context, ids ...string) (bool, error)
 }
 
 // ВАЖНО: Эти интерфейсы находятся в usecase слое, потому что:
@@ -14,7 +14,7 @@ type LoginRequest struct {
 
 // LoginResponse представляет ответ при авторизации
 type LoginResponse struct {
-	Token  string `json:"token"`
+	TokenPair
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Name   string `json:"name"`
@@ -30,19 +30,19 @@ var (
 type LoginUseCase struct {
 	userRepo       user.Repository
 	passwordHasher PasswordHasher
-	tokenGenerator TokenGenerator
+	tokenIssuer    *TokenIssuer
 }
 
 // NewLoginUseCase создает новый Use Case для авторизации
 func NewLoginUseCase(
 	userRepo user.Repository,
 	passwordHasher PasswordHasher,
-	tokenGenerator TokenGenerator,
+	tokenIssuer *TokenIssuer,
 ) *LoginUseCase {
 	return &LoginUseCase{
 		userRepo:       userRepo,
 		passwordHasher: passwordHasher,
-		tokenGenerator: tokenGenerator,
+		tokenIssuer:    tokenIssuer,
 	}
 }
 
@@ -65,15 +65,15 @@ func (uc *LoginUseCase) Execute(ctx context.Context, req LoginRequest) (*LoginRe
 		return nil, ErrInvalidCredentials
 	}
 
-	// ШАГ 3: Генерируем JWT токен
-	token, err := uc.tokenGenerator.Generate(existingUser.ID, existingUser.Email)
+	// ШАГ 3: Начинаем новую сессию: access и refresh токены
+	tokens, err := uc.tokenIssuer.StartSession(ctx, existingUser)
 	if err != nil {
 		return nil, err
 	}
 
 	// ШАГ 4: Возвращаем ответ
 	return &LoginResponse{
-		Token:  token,
+		TokenPair: *tokens,
 		UserID: existingUser.ID,
 		Email:  existingUser.Email,
 		Name:   existingUser.Name,
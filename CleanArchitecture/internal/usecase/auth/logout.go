@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"clean-url-shortener/internal/domain/token"
+)
+
+// SessionRevoker отзывает сессии: refresh токены в базе и access токены
+// через denylist. Используется при выходе и при повторном использовании
+// refresh токена
+type SessionRevoker struct {
+	refreshRepo token.Repository // Из domain слоя
+	denylist    TokenDenylist    // Из usecase слоя
+	accessTTL   time.Duration    // Время жизни access токена
+}
+
+// NewSessionRevoker создает отзыв сессий
+// accessTTL нужен, чтобы держать сессию в denylist, пока живы ее access токены
+func NewSessionRevoker(refreshRepo token.Repository, denylist TokenDenylist, accessTTL time.Duration) *SessionRevoker {
+	return &SessionRevoker{
+		refreshRepo: refreshRepo,
+		denylist:    denylist,
+		accessTTL:   accessTTL,
+	}
+}
+
+// RevokeSession отзывает все refresh и access токены сессии
+func (r *SessionRevoker) RevokeSession(ctx context.Context, sessionID string) error {
+	now := time.Now()
+
+	// ШАГ 1: Refresh токены сессии больше не обмениваются
+	if err := r.refreshRepo.RevokeFamily(ctx, sessionID, now); err != nil {
+		return err
+	}
+
+	// ШАГ 2: Уже выданные access токены сессии перестают приниматься
+	// Все они выпущены не позже now и истекут не позже now + accessTTL
+	return r.denylist.Revoke(ctx, sessionID, now.Add(r.accessTTL))
+}
+
+// LogoutRequest представляет запрос на выход
+// Заполняется из access токена текущего запроса
+type LogoutRequest struct {
+	UserID    uint
+	TokenID   string
+	SessionID string
+	ExpiresAt time.Time
+}
+
+// LogoutUseCase содержит бизнес-логику выхода из сессии
+type LogoutUseCase struct {
+	revoker  *SessionRevoker
+	denylist TokenDenylist // Из usecase слоя
+}
+
+// NewLogoutUseCase создает новый Use Case для выхода
+func NewLogoutUseCase(revoker *SessionRevoker, denylist TokenDenylist) *LogoutUseCase {
+	return &LogoutUseCase{
+		revoker:  revoker,
+		denylist: denylist,
+	}
+}
+
+// Execute завершает сессию, к которой относится access токен
+func (uc *LogoutUseCase) Execute(ctx context.Context, req LogoutRequest) error {
+	// ШАГ 1: Отзываем сессию целиком
+	if req.SessionID != "" {
+		if err := uc.revoker.RevokeSession(ctx, req.SessionID); err != nil {
+			return err
+		}
+	}
+
+	// ШАГ 2: Отзываем сам access токен
+	// Нужно для токенов без сессии, выпущенных до появления refresh токенов
+	if req.TokenID != "" {
+		return uc.denylist.Revoke(ctx, req.TokenID, req.ExpiresAt)
+	}
+	return nil
+}
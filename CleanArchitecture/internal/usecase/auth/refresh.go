@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"clean-url-shortener/internal/domain/token"
+	"clean-url-shortener/internal/domain/user"
+)
+
+// RefreshRequest представляет запрос на обновление токенов
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" validate:"required"`
+}
+
+// Ошибки обновления токенов
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, session revoked")
+)
+
+// RefreshUseCase содержит бизнес-логику ротации refresh токенов
+//
+// Каждый refresh токен обменивается на новую пару ровно один раз.
+// Повторное предъявление уже использованного токена значит, что его
+// копия есть у кого-то еще: отзывается вся сессия, и выйти из нее
+// придется и злоумышленнику, и владельцу.
+type RefreshUseCase struct {
+	userRepo    user.Repository  // Из domain слоя
+	refreshRepo token.Repository // Из domain слоя
+	tokenIssuer *TokenIssuer
+	revoker     *SessionRevoker
+}
+
+// NewRefreshUseCase создает новый Use Case для обновления токенов
+func NewRefreshUseCase(
+	userRepo user.Repository,
+	refreshRepo token.Repository,
+	tokenIssuer *TokenIssuer,
+	revoker *SessionRevoker,
+) *RefreshUseCase {
+	return &RefreshUseCase{
+		userRepo:    userRepo,
+		refreshRepo: refreshRepo,
+		tokenIssuer: tokenIssuer,
+		revoker:     revoker,
+	}
+}
+
+// Execute обменивает refresh токен на новую пару токенов
+func (uc *RefreshUseCase) Execute(ctx context.Context, req RefreshRequest) (*TokenPair, error) {
+	now := time.Now()
+
+	// ШАГ 1: Находим токен по хешу
+	current, err := uc.refreshRepo.FindByHash(ctx, token.Hash(req.RefreshToken))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil || current.IsRevoked() || current.IsExpired(now) {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// ШАГ 2: Использованный токен предъявлен повторно - отзываем сессию
+	if current.IsUsed() {
+		return nil, uc.revokeReused(ctx, current)
+	}
+
+	// ШАГ 3: Помечаем токен использованным
+	// Если параллельный запрос успел раньше, это тоже повторное использование
+	marked, err := uc.refreshRepo.MarkUsed(ctx, current.ID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !marked {
+		return nil, uc.revokeReused(ctx, current)
+	}
+
+	// ШАГ 4: Проверяем, что пользователь еще существует
+	owner, err := uc.userRepo.FindByID(ctx, current.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	// ШАГ 5: Выпускаем новую пару в той же сессии
+	return uc.tokenIssuer.Issue(ctx, owner, current.FamilyID)
+}
+
+// revokeReused отзывает сессию, токен которой был предъявлен повторно
+func (uc *RefreshUseCase) revokeReused(ctx context.Context, reused *token.RefreshToken) error {
+	log.Printf("Refresh token reuse detected for user %d, revoking session %s", reused.UserID, reused.FamilyID)
+
+	if err := uc.revoker.RevokeSession(ctx, reused.FamilyID); err != nil {
+		return err
+	}
+	return ErrRefreshTokenReused
+}
+
+// ПРИНЦИПЫ РОТАЦИИ ТОКЕНОВ:
+// 1. Refresh токен одноразовый, в базе хранится только его хеш
+// 2. Обмен атомарен: из двух параллельных обменов проходит один
+// 3. Повторное использование отзывает всю сессию, включая access токены
@@ -17,7 +17,7 @@ type RegisterRequest struct {
 
 // RegisterResponse представляет ответ при регистрации
 type RegisterResponse struct {
-	Token  string `json:"token"`
+	TokenPair
 	UserID uint   `json:"user_id"`
 	Email  string `json:"email"`
 	Name   string `json:"name"`
@@ -36,7 +36,7 @@ type RegisterUseCase struct {
 	// Зависимости инжектируются через интерфейсы
 	userRepo       user.Repository  // Из domain слоя
 	passwordHasher PasswordHasher   // Из usecase слоя
-	tokenGenerator TokenGenerator   // Из usecase слоя
+	tokenIssuer    *TokenIssuer     // Из usecase слоя
 }
 
 // NewRegisterUseCase создает новый Use Case для регистрации
@@ -44,12 +44,12 @@ type RegisterUseCase struct {
 func NewRegisterUseCase(
 	userRepo user.Repository,
 	passwordHasher PasswordHasher,
-	tokenGenerator TokenGenerator,
+	tokenIssuer *TokenIssuer,
 ) *RegisterUseCase {
 	return &RegisterUseCase{
 		userRepo:       userRepo,
 		passwordHasher: passwordHasher,
-		tokenGenerator: tokenGenerator,
+		tokenIssuer:    tokenIssuer,
 	}
 }
 
@@ -95,15 +95,15 @@ func (uc *RegisterUseCase) Execute(ctx context.Context, req RegisterRequest) (*R
 		return nil, err
 	}
 
-	// ШАГ 7: Генерируем JWT токен для автоматического входа
-	token, err := uc.tokenGenerator.Generate(newUser.ID, newUser.Email)
+	// ШАГ 7: Выпускаем токены для автоматического входа
+	tokens, err := uc.tokenIssuer.StartSession(ctx, newUser)
 	if err != nil {
 		return nil, err
 	}
 
 	// ШАГ 8: Возвращаем ответ
 	return &RegisterResponse{
-		Token:  token,
+		TokenPair: *tokens,
 		UserID: newUser.ID,
 		Email:  newUser.Email,
 		Name:   newUser.Name,
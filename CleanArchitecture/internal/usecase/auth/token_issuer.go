@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"clean-url-shortener/internal/domain/token"
+	"clean-url-shortener/internal/domain/user"
+)
+
+// TokenPair пара токенов, которую получает клиент
+type TokenPair struct {
+	AccessToken      string    `json:"token"`
+	AccessExpiresAt  time.Time `json:"token_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_token_expires_at"`
+}
+
+// TokenIssuer выпускает пары access + refresh токенов
+// Общая часть входа, регистрации и обновления токенов
+type TokenIssuer struct {
+	tokenGenerator TokenGenerator   // Из usecase слоя
+	refreshRepo    token.Repository // Из domain слоя
+	refreshTTL     time.Duration
+}
+
+// NewTokenIssuer создает выпуск токенов с временем жизни refresh токена refreshTTL
+func NewTokenIssuer(
+	tokenGenerator TokenGenerator,
+	refreshRepo token.Repository,
+	refreshTTL time.Duration,
+) *TokenIssuer {
+	return &TokenIssuer{
+		tokenGenerator: tokenGenerator,
+		refreshRepo:    refreshRepo,
+		refreshTTL:     refreshTTL,
+	}
+}
+
+// StartSession выпускает токены новой сессии (вход, регистрация)
+func (i *TokenIssuer) StartSession(ctx context.Context, u *user.User) (*TokenPair, error) {
+	familyID, err := token.NewFamilyID()
+	if err != nil {
+		return nil, err
+	}
+	return i.Issue(ctx, u, familyID)
+}
+
+// Issue выпускает токены в существующей сессии familyID
+func (i *TokenIssuer) Issue(ctx context.Context, u *user.User, familyID string) (*TokenPair, error) {
+	// ШАГ 1: Создаем и сохраняем refresh токен (в базе только хеш)
+	refresh, refreshValue, err := token.NewRefreshToken(u.ID, familyID, i.refreshTTL)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.refreshRepo.Save(ctx, refresh); err != nil {
+		return nil, err
+	}
+
+	// ШАГ 2: Выпускаем access токен той же сессии
+	access, err := i.tokenGenerator.Generate(u.ID, u.Email, familyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TokenPair{
+		AccessToken:      access.Token,
+		AccessExpiresAt:  access.ExpiresAt,
+		RefreshToken:     refreshValue,
+		RefreshExpiresAt: refresh.ExpiresAt,
+	}, nil
+}
@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"clean-url-shortener/internal/domain/domain"
+)
+
+// Ошибки управления доменами
+var (
+	ErrDomainAlreadyExists = errors.New("domain is already registered")
+	ErrDomainReserved      = errors.New("domain belongs to the service itself")
+	ErrDomainNotFound      = errors.New("domain not found")
+	ErrAccessDenied        = errors.New("access denied")
+)
+
+// AddDomainRequest представляет запрос на добавление домена
+type AddDomainRequest struct {
+	Host   string `json:"host" validate:"required,max=253"`
+	UserID uint   `json:"-"` // Передается из контекста аутентификации
+}
+
+// DomainResponse представляет домен в ответах API
+type DomainResponse struct {
+	ID           uint               `json:"id"`
+	Host         string             `json:"host"`
+	Verified     bool               `json:"verified"`
+	VerifiedAt   *time.Time         `json:"verified_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	Verification VerificationRecord `json:"verification"`
+}
+
+// VerificationRecord DNS запись, которую нужно создать для подтверждения
+type VerificationRecord struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// AddDomainUseCase содержит бизнес-логику добавления пользовательского домена
+//
+// Домен добавляется неподтвержденным: ссылки на нем создавать нельзя,
+// пока владелец не опубликует TXT запись (VerifyDomainUseCase).
+type AddDomainUseCase struct {
+	domainRepo    domain.Repository   // Из domain слоя
+	reservedHosts map[string]struct{} // Хосты самого сервиса
+}
+
+// NewAddDomainUseCase создает новый Use Case для добавления домена
+// reservedHosts - хосты сервиса (BASE_URL), их нельзя зарегистрировать
+func NewAddDomainUseCase(domainRepo domain.Repository, reservedHosts []string) *AddDomainUseCase {
+	reserved := make(map[string]struct{}, len(reservedHosts))
+	for _, host := range reservedHosts {
+		if normalized, err := domain.NormalizeHost(host); err == nil {
+			reserved[normalized] = struct{}{}
+		}
+	}
+
+	return &AddDomainUseCase{
+		domainRepo:    domainRepo,
+		reservedHosts: reserved,
+	}
+}
+
+// Execute выполняет бизнес-логику добавления домена
+func (uc *AddDomainUseCase) Execute(ctx context.Context, req AddDomainRequest) (*DomainResponse, error) {
+	// ШАГ 1: Создаем доменную модель (нормализация и проверка имени)
+	newDomain, err := domain.NewDomain(req.Host, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := uc.reservedHosts[newDomain.Host]; ok {
+		return nil, ErrDomainReserved
+	}
+
+	// ШАГ 2: Проверяем, не занято ли имя
+	existing, err := uc.domainRepo.FindByHost(ctx, newDomain.Host)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		if existing.IsOwner(req.UserID) || existing.IsVerified() {
+			return nil, ErrDomainAlreadyExists
+		}
+
+		// Неподтвержденная заявка другого пользователя не держит имя:
+		// подтвердить домен сможет только тот, кто управляет его DNS
+		if err := uc.domainRepo.Delete(ctx, existing.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	// ШАГ 3: Сохраняем домен
+	if err := uc.domainRepo.Save(ctx, newDomain); err != nil {
+		return nil, err
+	}
+
+	// ШАГ 4: Возвращаем домен вместе с инструкцией по подтверждению
+	return toDomainResponse(newDomain), nil
+}
+
+// toDomainResponse переводит доменную модель в формат ответа
+func toDomainResponse(d *domain.Domain) *DomainResponse {
+	return &DomainResponse{
+		ID:         d.ID,
+		Host:       d.Host,
+		Verified:   d.IsVerified(),
+		VerifiedAt: d.VerifiedAt,
+		CreatedAt:  d.CreatedAt,
+		Verification: VerificationRecord{
+			Type:  "TXT",
+			Name:  d.VerificationRecordName(),
+			Value: d.VerificationRecordValue(),
+		},
+	}
+}
+
+// findOwnedDomain находит домен и проверяет, что он принадлежит пользователю
+func findOwnedDomain(ctx context.Context, repo domain.Repository, domainID, userID uint) (*domain.Domain, error) {
+	d, err := repo.FindByID(ctx, domainID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, ErrDomainNotFound
+	}
+	if !d.IsOwner(userID) {
+		return nil, ErrAccessDenied
+	}
+	return d, nil
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+// ИНТЕРФЕЙСЫ ДЛЯ ЗАВИСИМОСТЕЙ DOMAIN USE CASES
+
+// TXTResolver определяет интерфейс для чтения TXT записей DNS
+// *net.Resolver подходит без адаптера; в тестах подменяется таблицей
+type TXTResolver interface {
+	// LookupTXT возвращает TXT записи имени name
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// ПРИНЦИПЫ:
+// 1. Подтверждение домена не зависит от конкретного DNS клиента
+// 2. Проверку можно протестировать без сети
@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"context"
+	"errors"
+
+	"clean-url-shortener/internal/domain/domain"
+	"clean-url-shortener/internal/domain/link"
+)
+
+// ErrDomainInUse домен нельзя удалить, пока на нем есть ссылки
+var ErrDomainInUse = errors.New("domain has links: delete them first")
+
+// ListDomainsUseCase возвращает домены пользователя
+type ListDomainsUseCase struct {
+	domainRepo domain.Repository // Из domain слоя
+}
+
+// NewListDomainsUseCase создает новый Use Case для списка доменов
+func NewListDomainsUseCase(domainRepo domain.Repository) *ListDomainsUseCase {
+	return &ListDomainsUseCase{
+		domainRepo: domainRepo,
+	}
+}
+
+// Execute возвращает домены пользователя по порядку добавления
+func (uc *ListDomainsUseCase) Execute(ctx context.Context, userID uint) ([]*DomainResponse, error) {
+	domains, err := uc.domainRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]*DomainResponse, 0, len(domains))
+	for _, d := range domains {
+		responses = append(responses, toDomainResponse(d))
+	}
+	return responses, nil
+}
+
+// DeleteDomainRequest представляет запрос на удаление домена
+type DeleteDomainRequest struct {
+	DomainID uint `json:"domain_id" validate:"required"`
+	UserID   uint `json:"-"` // Передается из контекста аутентификации
+}
+
+// DeleteDomainUseCase содержит бизнес-логику удаления домена
+//
+// Ссылки вместе с доменом не удаляются и не переносятся на домен по
+// умолчанию: там их коды могут быть заняты, а опубликованные адреса
+// все равно перестанут работать. Поэтому домен с ссылками не удаляется.
+type DeleteDomainUseCase struct {
+	domainRepo domain.Repository // Из domain слоя
+	linkRepo   link.Repository   // Из domain слоя
+}
+
+// NewDeleteDomainUseCase создает новый Use Case для удаления домена
+func NewDeleteDomainUseCase(domainRepo domain.Repository, linkRepo link.Repository) *DeleteDomainUseCase {
+	return &DeleteDomainUseCase{
+		domainRepo: domainRepo,
+		linkRepo:   linkRepo,
+	}
+}
+
+// Execute удаляет домен пользователя без ссылок
+func (uc *DeleteDomainUseCase) Execute(ctx context.Context, req DeleteDomainRequest) error {
+	// ШАГ 1: Находим домен и проверяем владельца
+	d, err := findOwnedDomain(ctx, uc.domainRepo, req.DomainID, req.UserID)
+	if err != nil {
+		return err
+	}
+
+	// ШАГ 2: Проверяем, что на домене нет ссылок
+	count, err := uc.linkRepo.CountByDomainID(ctx, d.ID)
+	if err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrDomainInUse
+	}
+
+	// ШАГ 3: Удаляем домен
+	return uc.domainRepo.Delete(ctx, d.ID)
+}
+
+// ПРИНЦИПЫ УПРАВЛЕНИЯ ДОМЕНАМИ:
+// 1. Домен видит и меняет только владелец (ErrAccessDenied)
+// 2. Ссылки обслуживает только подтвержденный домен
+// 3. Удаление домена не ломает существующие ссылки молча
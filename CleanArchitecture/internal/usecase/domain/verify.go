@@ -0,0 +1,84 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	"clean-url-shortener/internal/domain/domain"
+)
+
+// verifyTimeout ограничение времени на DNS запрос при подтверждении
+const verifyTimeout = 10 * time.Second
+
+// Ошибки подтверждения домена
+var (
+	ErrVerificationFailed = errors.New("verification TXT record not found")
+	ErrDNSLookupFailed    = errors.New("DNS lookup failed")
+)
+
+// VerifyDomainRequest представляет запрос на подтверждение домена
+type VerifyDomainRequest struct {
+	DomainID uint `json:"domain_id" validate:"required"`
+	UserID   uint `json:"-"` // Передается из контекста аутентификации
+}
+
+// VerifyDomainUseCase содержит бизнес-логику подтверждения домена
+//
+// Владелец создает TXT запись _shortener-verification.<host> со значением
+// shortener-verification=<token>. Запись доказывает, что пользователь
+// управляет DNS домена, и только после этого домен обслуживает ссылки.
+type VerifyDomainUseCase struct {
+	domainRepo domain.Repository // Из domain слоя
+	resolver   TXTResolver       // Из usecase слоя
+}
+
+// NewVerifyDomainUseCase создает новый Use Case для подтверждения домена
+func NewVerifyDomainUseCase(domainRepo domain.Repository, resolver TXTResolver) *VerifyDomainUseCase {
+	return &VerifyDomainUseCase{
+		domainRepo: domainRepo,
+		resolver:   resolver,
+	}
+}
+
+// Execute проверяет TXT запись и отмечает домен подтвержденным
+// Повторное подтверждение уже подтвержденного домена - не ошибка
+func (uc *VerifyDomainUseCase) Execute(ctx context.Context, req VerifyDomainRequest) (*DomainResponse, error) {
+	// ШАГ 1: Находим домен и проверяем владельца
+	d, err := findOwnedDomain(ctx, uc.domainRepo, req.DomainID, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if d.IsVerified() {
+		return toDomainResponse(d), nil
+	}
+
+	// ШАГ 2: Читаем TXT записи
+	lookupCtx, cancel := context.WithTimeout(ctx, verifyTimeout)
+	defer cancel()
+
+	records, err := uc.resolver.LookupTXT(lookupCtx, d.VerificationRecordName())
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		// Записи еще нет - это ответ DNS, а не сбой
+		return nil, ErrVerificationFailed
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrDNSLookupFailed, err)
+	}
+
+	// ШАГ 3: Сверяем значение
+	if !d.MatchesTXT(records) {
+		return nil, ErrVerificationFailed
+	}
+
+	// ШАГ 4: Сохраняем подтверждение
+	d.MarkVerified(time.Now())
+	if err := uc.domainRepo.Save(ctx, d); err != nil {
+		return nil, err
+	}
+
+	return toDomainResponse(d), nil
+}
@@ -0,0 +1,249 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"clean-url-shortener/internal/domain/domain"
+	"clean-url-shortener/internal/domain/link"
+)
+
+// MaxBatchSize максимальное количество ссылок в одном пакетном запросе
+const MaxBatchSize = 5000
+
+// Ошибки пакетного создания
+var (
+	ErrEmptyBatch    = errors.New("batch is empty")
+	ErrBatchTooLarge = fmt.Errorf("batch is too large (max %d links)", MaxBatchSize)
+)
+
+// Статусы строки пакетного запроса
+const (
+	BatchStatusCreated  = "created"
+	BatchStatusInvalid  = "invalid"  // URL или код не прошли проверку
+	BatchStatusConflict = "conflict" // пользовательский код уже занят
+)
+
+// BatchLinkItem одна ссылка из пакетного запроса
+type BatchLinkItem struct {
+	OriginalURL string `json:"original_url"`
+	CustomCode  string `json:"custom_code,omitempty"`
+}
+
+// BatchCreateLinksRequest представляет запрос на пакетное создание ссылок
+type BatchCreateLinksRequest struct {
+	Links  []BatchLinkItem `json:"links"`
+	Domain string          `json:"domain,omitempty"` // Домен всех ссылок пакета (пусто - по умолчанию)
+	UserID uint            `json:"-"`                // Передается из контекста аутентификации
+}
+
+// BatchLinkResult результат обработки одной строки
+// Row - номер строки в запросе, начиная с 1
+type BatchLinkResult struct {
+	Row         int    `json:"row"`
+	Status      string `json:"status"`
+	OriginalURL string `json:"original_url"`
+	ShortCode   string `json:"short_code,omitempty"`
+	ShortURL    string `json:"short_url,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// BatchCreateLinksResponse представляет ответ на пакетный запрос
+type BatchCreateLinksResponse struct {
+	Created int               `json:"created"`
+	Failed  int               `json:"failed"`
+	Results []BatchLinkResult `json:"results"`
+}
+
+// BatchCreateLinksUseCase содержит бизнес-логику пакетного создания ссылок
+//
+// Каждая строка проверяется отдельно: ошибка в одной строке не мешает
+// остальным. Все корректные строки сохраняются в одной транзакции,
+// поэтому проверка уникальности кодов и вставка не разъезжаются.
+type BatchCreateLinksUseCase struct {
+	linkRepo     link.Repository    // Из domain слоя
+	domainRepo   domain.Repository  // Из domain слоя
+	urlValidator URLValidator       // Из usecase слоя
+	shortCodeGen ShortCodeGenerator // Из usecase слоя
+	baseURL      string
+}
+
+// NewBatchCreateLinksUseCase создает новый Use Case для пакетного создания ссылок
+func NewBatchCreateLinksUseCase(
+	linkRepo link.Repository,
+	domainRepo domain.Repository,
+	urlValidator URLValidator,
+	shortCodeGen ShortCodeGenerator,
+	baseURL string,
+) *BatchCreateLinksUseCase {
+	return &BatchCreateLinksUseCase{
+		linkRepo:     linkRepo,
+		domainRepo:   domainRepo,
+		urlValidator: urlValidator,
+		shortCodeGen: shortCodeGen,
+		baseURL:      baseURL,
+	}
+}
+
+// Execute выполняет пакетное создание ссылок
+// Ошибка возвращается только если пакет не обработан целиком
+// (пустой, слишком большой, недоступный домен, сбой базы) - тогда ничего не сохранено
+func (uc *BatchCreateLinksUseCase) Execute(ctx context.Context, req BatchCreateLinksRequest) (*BatchCreateLinksResponse, error) {
+	if len(req.Links) == 0 {
+		return nil, ErrEmptyBatch
+	}
+	if len(req.Links) > MaxBatchSize {
+		return nil, ErrBatchTooLarge
+	}
+
+	linkDomain, err := resolveUserDomain(ctx, uc.domainRepo, req.Domain, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchLinkResult, len(req.Links))
+
+	// ШАГ 1: Проверяем строки и создаем доменные модели до транзакции
+	pending := make([]pendingLink, 0, len(req.Links))
+	for i, item := range req.Links {
+		results[i] = BatchLinkResult{Row: i + 1, OriginalURL: item.OriginalURL}
+
+		newLink, err := uc.buildLink(ctx, item, req.UserID)
+		if err != nil {
+			results[i].Status = BatchStatusInvalid
+			results[i].Error = err.Error()
+			continue
+		}
+		newLink.SetDomain(domainID(linkDomain))
+		pending = append(pending, pendingLink{row: i, link: newLink, custom: item.CustomCode != ""})
+	}
+
+	// ШАГ 2: Проверяем уникальность кодов и сохраняем одной транзакцией
+	err = uc.linkRepo.RunInTx(ctx, func(repo link.Repository) error {
+		// Коды, занятые этим же пакетом
+		reserved := make(map[string]bool, len(pending))
+
+		for _, p := range pending {
+			result := &results[p.row]
+
+			unique, err := uc.ensureUniqueCode(ctx, repo, p, reserved)
+			if err != nil {
+				return err
+			}
+			if !unique {
+				result.Status = BatchStatusConflict
+				result.Error = ErrShortCodeAlreadyExists.Error()
+				continue
+			}
+
+			if err := repo.Save(ctx, p.link); err != nil {
+				return err
+			}
+			reserved[p.link.ShortCode] = true
+
+			result.Status = BatchStatusCreated
+			result.ShortCode = p.link.ShortCode
+			result.ShortURL = shortURL(uc.baseURL, linkDomain, p.link.ShortCode)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// ШАГ 3: Формируем сводку
+	response := &BatchCreateLinksResponse{Results: results}
+	for _, result := range results {
+		if result.Status == BatchStatusCreated {
+			response.Created++
+		} else {
+			response.Failed++
+		}
+	}
+	return response, nil
+}
+
+// pendingLink прошедшая проверку строка, ожидающая сохранения
+type pendingLink struct {
+	row    int
+	link   *link.Link
+	custom bool // код задан пользователем и не может быть заменен
+}
+
+// buildLink проверяет строку так же, как CreateLinkUseCase, и создает ссылку
+// Безопасность проверяется только по локальным данным: переход по тысячам
+// адресов держал бы запрос минутами. Цепочки редиректов новых ссылок
+// проверит ScanLinksSafetyUseCase
+func (uc *BatchCreateLinksUseCase) buildLink(ctx context.Context, item BatchLinkItem, userID uint) (*link.Link, error) {
+	if err := uc.urlValidator.Validate(item.OriginalURL); err != nil {
+		return nil, err
+	}
+
+	isSafe, err := uc.urlValidator.IsSafeLocal(ctx, item.OriginalURL)
+	if err != nil {
+		return nil, err
+	}
+	if !isSafe {
+		return nil, ErrUnsafeURL
+	}
+
+	var code string
+	if item.CustomCode != "" {
+		code, err = uc.shortCodeGen.GenerateCustom(item.CustomCode)
+		if err != nil {
+			return nil, ErrInvalidCustomCode
+		}
+	} else {
+		code, err = uc.shortCodeGen.Generate()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return link.NewLinkWithCustomCode(item.OriginalURL, code, userID)
+}
+
+// ensureUniqueCode проверяет, что код свободен в базе и в пакете
+// Автоматический код при коллизии генерируется заново, пользовательский -
+// нет: тогда возвращается false
+func (uc *BatchCreateLinksUseCase) ensureUniqueCode(
+	ctx context.Context,
+	repo link.Repository,
+	p pendingLink,
+	reserved map[string]bool,
+) (bool, error) {
+	const maxAttempts = 10
+
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		taken := reserved[p.link.ShortCode]
+		if !taken {
+			exists, err := repo.ExistsByShortCode(ctx, p.link.DomainID, p.link.ShortCode)
+			if err != nil {
+				return false, err
+			}
+			taken = exists
+		}
+		if !taken {
+			return true, nil
+		}
+		if p.custom {
+			return false, nil
+		}
+
+		code, err := uc.shortCodeGen.Generate()
+		if err != nil {
+			return false, err
+		}
+		if err := p.link.UpdateShortCode(code); err != nil {
+			return false, err
+		}
+	}
+
+	return false, errors.New("failed to generate unique short code")
+}
+
+// ПРИНЦИПЫ ПАКЕТНОЙ ОБРАБОТКИ:
+// 1. Ошибки строк - это данные ответа, а не ошибка всего запроса
+// 2. Уникальность кодов проверяется внутри той же транзакции, что и вставка
+// 3. Сбой базы откатывает весь пакет - частично сохраненных пакетов нет
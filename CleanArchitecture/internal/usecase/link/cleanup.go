@@ -0,0 +1,55 @@
+package link
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"clean-url-shortener/internal/domain/link"
+)
+
+// CleanupExpiredLinksUseCase удаляет ссылки с истекшим сроком действия
+//
+// Истекшая ссылка какое-то время (retention) остается в базе и отвечает
+// 410 Gone, чтобы владелец и посетители видели, что она истекла, а не
+// никогда не существовала. После этого ссылка удаляется вместе со статистикой.
+type CleanupExpiredLinksUseCase struct {
+	linkRepo  link.Repository // Из domain слоя
+	retention time.Duration   // Сколько хранить ссылку после истечения
+}
+
+// NewCleanupExpiredLinksUseCase создает Use Case очистки истекших ссылок
+func NewCleanupExpiredLinksUseCase(linkRepo link.Repository, retention time.Duration) *CleanupExpiredLinksUseCase {
+	return &CleanupExpiredLinksUseCase{
+		linkRepo:  linkRepo,
+		retention: retention,
+	}
+}
+
+// Execute удаляет ссылки, истекшие раньше чем retention назад
+func (uc *CleanupExpiredLinksUseCase) Execute(ctx context.Context) (int64, error) {
+	return uc.linkRepo.DeleteExpired(ctx, time.Now().Add(-uc.retention))
+}
+
+// Run выполняет очистку сразу и затем каждые interval, пока не отменен ctx
+// Ошибки логируются: очистка повторится на следующем тике
+func (uc *CleanupExpiredLinksUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := uc.Execute(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("Failed to clean up expired links: %v", err)
+		case deleted > 0:
+			log.Printf("Cleaned up %d expired links", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
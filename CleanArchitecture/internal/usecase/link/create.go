@@ -3,6 +3,9 @@ package link
 import (
 	"context"
 	"errors"
+	"time"
+
+	"clean-url-shortener/internal/domain/domain"
 	"clean-url-shortener/internal/domain/link"
 )
 
@@ -10,7 +13,14 @@ import (
 type CreateLinkRequest struct {
 	OriginalURL string `json:"original_url" validate:"required,url"`
 	CustomCode  string `json:"custom_code,omitempty" validate:"omitempty,min=3,max=10"`
-	UserID      uint   `json:"-"` // Передается из контекста аутентификации
+	Domain      string `json:"domain,omitempty"` // Подтвержденный домен пользователя (пусто - домен по умолчанию)
+	UserID      uint   `json:"-"`                // Передается из контекста аутентификации
+
+	// Необязательные ограничения доступа
+	ActivatesAt *time.Time `json:"activates_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	MaxClicks   uint       `json:"max_clicks,omitempty"`
+	Password    string     `json:"password,omitempty" validate:"omitempty,min=4,max=72"` // bcrypt учитывает до 72 байт
 }
 
 // CreateLinkResponse представляет ответ при создании ссылки
@@ -19,7 +29,13 @@ type CreateLinkResponse struct {
 	OriginalURL string `json:"original_url"`
 	ShortCode   string `json:"short_code"`
 	ShortURL    string `json:"short_url"` // Полный URL для использования
+	Domain      string `json:"domain,omitempty"`
 	CreatedAt   string `json:"created_at"`
+
+	ActivatesAt       *time.Time `json:"activates_at,omitempty"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	MaxClicks         uint       `json:"max_clicks,omitempty"`
+	PasswordProtected bool       `json:"password_protected"`
 }
 
 // Ошибки для создания ссылок
@@ -32,23 +48,29 @@ var (
 // CreateLinkUseCase содержит бизнес-логику создания короткой ссылки
 type CreateLinkUseCase struct {
 	linkRepo          link.Repository    // Из domain слоя
+	domainRepo        domain.Repository  // Из domain слоя
 	urlValidator      URLValidator       // Из usecase слоя
 	shortCodeGen      ShortCodeGenerator // Из usecase слоя
+	passwordHasher    PasswordHasher     // Из usecase слоя
 	baseURL           string             // Базовый URL для формирования коротких ссылок
 }
 
 // NewCreateLinkUseCase создает новый Use Case для создания ссылок
 func NewCreateLinkUseCase(
 	linkRepo link.Repository,
+	domainRepo domain.Repository,
 	urlValidator URLValidator,
 	shortCodeGen ShortCodeGenerator,
+	passwordHasher PasswordHasher,
 	baseURL string,
 ) *CreateLinkUseCase {
 	return &CreateLinkUseCase{
-		linkRepo:     linkRepo,
-		urlValidator: urlValidator,
-		shortCodeGen: shortCodeGen,
-		baseURL:      baseURL,
+		linkRepo:       linkRepo,
+		domainRepo:     domainRepo,
+		urlValidator:   urlValidator,
+		shortCodeGen:   shortCodeGen,
+		passwordHasher: passwordHasher,
+		baseURL:        baseURL,
 	}
 }
 
@@ -61,7 +83,7 @@ func (uc *CreateLinkUseCase) Execute(ctx context.Context, req CreateLinkRequest)
 	}
 
 	// ШАГ 2: Проверяем безопасность URL (не фишинг, не malware)
-	isSafe, err := uc.urlValidator.IsSafe(req.OriginalURL)
+	isSafe, err := uc.urlValidator.IsSafe(ctx, req.OriginalURL)
 	if err != nil {
 		return nil, err
 	}
@@ -69,43 +91,83 @@ func (uc *CreateLinkUseCase) Execute(ctx context.Context, req CreateLinkRequest)
 		return nil, ErrUnsafeURL
 	}
 
+	// ШАГ 3: Выбираем домен ссылки - коды уникальны в его пределах
+	linkDomain, err := resolveUserDomain(ctx, uc.domainRepo, req.Domain, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+
 	var newLink *link.Link
 
-	// ШАГ 3: Создаем ссылку с пользовательским или автоматическим кодом
+	// ШАГ 4: Создаем ссылку с пользовательским или автоматическим кодом
 	if req.CustomCode != "" {
 		// Пользователь предоставил свой код
-		newLink, err = uc.createLinkWithCustomCode(ctx, req)
+		newLink, err = uc.createLinkWithCustomCode(ctx, req, domainID(linkDomain))
 		if err != nil {
 			return nil, err
 		}
 	} else {
 		// Генерируем автоматический код
-		newLink, err = uc.createLinkWithAutoCode(ctx, req)
+		newLink, err = uc.createLinkWithAutoCode(ctx, req, domainID(linkDomain))
 		if err != nil {
 			return nil, err
 		}
 	}
+	newLink.SetDomain(domainID(linkDomain))
 
-	// ШАГ 4: Сохраняем ссылку в базе данных
+	// ШАГ 5: Применяем ограничения доступа
+	if err := uc.applyRestrictions(newLink, req); err != nil {
+		return nil, err
+	}
+
+	// ШАГ 6: Сохраняем ссылку в базе данных
 	err = uc.linkRepo.Save(ctx, newLink)
 	if err != nil {
 		return nil, err
 	}
 
-	// ШАГ 5: Формируем ответ
-	return &CreateLinkResponse{
+	// ШАГ 7: Формируем ответ
+	response := &CreateLinkResponse{
 		ID:          newLink.ID,
 		OriginalURL: newLink.OriginalURL,
 		ShortCode:   newLink.ShortCode,
-		ShortURL:    uc.baseURL + "/" + newLink.ShortCode,
+		ShortURL:    shortURL(uc.baseURL, linkDomain, newLink.ShortCode),
 		CreatedAt:   newLink.CreatedAt.Format("2006-01-02T15:04:05Z"),
-	}, nil
+
+		ActivatesAt:       newLink.ActivatesAt,
+		ExpiresAt:         newLink.ExpiresAt,
+		MaxClicks:         newLink.MaxClicks,
+		PasswordProtected: newLink.IsPasswordProtected(),
+	}
+	if linkDomain != nil {
+		response.Domain = linkDomain.Host
+	}
+	return response, nil
+}
+
+// applyRestrictions переносит ограничения из запроса в доменную модель
+// Пароль хранится только в виде хеша
+func (uc *CreateLinkUseCase) applyRestrictions(l *link.Link, req CreateLinkRequest) error {
+	if err := l.SetSchedule(req.ActivatesAt, req.ExpiresAt); err != nil {
+		return err
+	}
+
+	l.SetMaxClicks(req.MaxClicks)
+
+	if req.Password != "" {
+		hash, err := uc.passwordHasher.Hash(req.Password)
+		if err != nil {
+			return err
+		}
+		l.SetPasswordHash(hash)
+	}
+	return nil
 }
 
 // createLinkWithCustomCode создает ссылку с пользовательским кодом
-func (uc *CreateLinkUseCase) createLinkWithCustomCode(ctx context.Context, req CreateLinkRequest) (*link.Link, error) {
-	// Проверяем уникальность пользовательского кода
-	exists, err := uc.linkRepo.ExistsByShortCode(ctx, req.CustomCode)
+func (uc *CreateLinkUseCase) createLinkWithCustomCode(ctx context.Context, req CreateLinkRequest, domainID uint) (*link.Link, error) {
+	// Проверяем уникальность пользовательского кода на домене ссылки
+	exists, err := uc.linkRepo.ExistsByShortCode(ctx, domainID, req.CustomCode)
 	if err != nil {
 		return nil, err
 	}
@@ -124,7 +186,7 @@ func (uc *CreateLinkUseCase) createLinkWithCustomCode(ctx context.Context, req C
 }
 
 // createLinkWithAutoCode создает ссылку с автоматически сгенерированным кодом
-func (uc *CreateLinkUseCase) createLinkWithAutoCode(ctx context.Context, req CreateLinkRequest) (*link.Link, error) {
+func (uc *CreateLinkUseCase) createLinkWithAutoCode(ctx context.Context, req CreateLinkRequest, domainID uint) (*link.Link, error) {
 	// Генерируем уникальный код (попытки до 10 раз)
 	const maxAttempts = 10
 	
@@ -136,7 +198,7 @@ func (uc *CreateLinkUseCase) createLinkWithAutoCode(ctx context.Context, req Cre
 		}
 
 		// Проверяем уникальность
-		exists, err := uc.linkRepo.ExistsByShortCode(ctx, shortCode)
+		exists, err := uc.linkRepo.ExistsByShortCode(ctx, domainID, shortCode)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,74 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/url"
+	"strings"
+
+	"clean-url-shortener/internal/domain/domain"
+)
+
+// Ошибки выбора домена для новой ссылки
+var (
+	ErrDomainNotFound    = errors.New("domain not found")
+	ErrDomainNotVerified = errors.New("domain is not verified yet")
+)
+
+// resolveUserDomain находит домен, на котором пользователь создает ссылку
+// Пустой host - домен сервиса по умолчанию (nil). Чужой домен для
+// пользователя не существует, как и незарегистрированный
+func resolveUserDomain(ctx context.Context, repo domain.Repository, host string, userID uint) (*domain.Domain, error) {
+	if host == "" {
+		return nil, nil
+	}
+
+	normalized, err := domain.NormalizeHost(host)
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := repo.FindByHost(ctx, normalized)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || !d.IsOwner(userID) {
+		return nil, ErrDomainNotFound
+	}
+	if !d.IsVerified() {
+		return nil, ErrDomainNotVerified
+	}
+	return d, nil
+}
+
+// domainID ID домена для ссылки (0 - домен по умолчанию)
+func domainID(d *domain.Domain) uint {
+	if d == nil {
+		return 0
+	}
+	return d.ID
+}
+
+// shortURL полный адрес короткой ссылки
+// Ссылки пользовательского домена используют схему BASE_URL
+func shortURL(baseURL string, d *domain.Domain, shortCode string) string {
+	if d == nil {
+		return baseURL + "/" + shortCode
+	}
+
+	scheme := "https"
+	if u, err := url.Parse(baseURL); err == nil && u.Scheme != "" {
+		scheme = u.Scheme
+	}
+	return scheme + "://" + d.Host + "/" + shortCode
+}
+
+// requestHost имя хоста из заголовка Host без порта
+func requestHost(hostHeader string) string {
+	host := hostHeader
+	if h, _, err := net.SplitHostPort(hostHeader); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
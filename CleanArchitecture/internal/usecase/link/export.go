@@ -0,0 +1,95 @@
+package link
+
+import (
+	"context"
+	"time"
+
+	"clean-url-shortener/internal/domain/domain"
+	"clean-url-shortener/internal/domain/link"
+)
+
+// exportPageSize сколько ссылок читается из базы за один запрос
+const exportPageSize = 500
+
+// ExportedLink представляет ссылку в выгрузке
+type ExportedLink struct {
+	ID                uint       `json:"id"`
+	OriginalURL       string     `json:"original_url"`
+	ShortCode         string     `json:"short_code"`
+	ShortURL          string     `json:"short_url"`
+	ClicksCount       uint       `json:"clicks_count"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ExpiresAt         *time.Time `json:"expires_at,omitempty"`
+	MaxClicks         uint       `json:"max_clicks,omitempty"`
+	PasswordProtected bool       `json:"password_protected"`
+	SafetyStatus      string     `json:"safety_status"` // ok, flagged, blocked
+}
+
+// ExportLinksUseCase содержит бизнес-логику выгрузки ссылок пользователя
+//
+// Ссылки читаются страницами и сразу передаются вызывающему коду,
+// поэтому выгрузка тысяч ссылок не держит их все в памяти.
+type ExportLinksUseCase struct {
+	linkRepo   link.Repository   // Из domain слоя
+	domainRepo domain.Repository // Из domain слоя
+	baseURL    string
+}
+
+// NewExportLinksUseCase создает новый Use Case для выгрузки ссылок
+func NewExportLinksUseCase(linkRepo link.Repository, domainRepo domain.Repository, baseURL string) *ExportLinksUseCase {
+	return &ExportLinksUseCase{
+		linkRepo:   linkRepo,
+		domainRepo: domainRepo,
+		baseURL:    baseURL,
+	}
+}
+
+// Execute передает все ссылки пользователя в emit, начиная с новых
+// Ошибка emit (например, клиент отключился) прекращает выгрузку
+func (uc *ExportLinksUseCase) Execute(ctx context.Context, userID uint, emit func(ExportedLink) error) error {
+	// Домены пользователя нужны для адресов ссылок; их немного
+	domains, err := uc.domainRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	domainsByID := make(map[uint]*domain.Domain, len(domains))
+	for _, d := range domains {
+		domainsByID[d.ID] = d
+	}
+
+	for offset := 0; ; offset += exportPageSize {
+		// ШАГ 1: Читаем очередную страницу
+		links, err := uc.linkRepo.FindByUserID(ctx, userID, exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		// ШАГ 2: Отдаем ссылки по одной
+		for _, l := range links {
+			if err := emit(uc.toExported(l, domainsByID[l.DomainID])); err != nil {
+				return err
+			}
+		}
+
+		if len(links) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+// toExported переводит доменную модель в формат выгрузки
+// d - домен ссылки, nil для домена по умолчанию
+func (uc *ExportLinksUseCase) toExported(l *link.Link, d *domain.Domain) ExportedLink {
+	return ExportedLink{
+		ID:                l.ID,
+		OriginalURL:       l.OriginalURL,
+		ShortCode:         l.ShortCode,
+		ShortURL:          shortURL(uc.baseURL, d, l.ShortCode),
+		ClicksCount:       l.ClicksCount,
+		CreatedAt:         l.CreatedAt,
+		ExpiresAt:         l.ExpiresAt,
+		MaxClicks:         l.MaxClicks,
+		PasswordProtected: l.IsPasswordProtected(),
+		SafetyStatus:      string(l.SafetyStatus),
+	}
+}
@@ -1,12 +1,19 @@
 package link
 
+import (
+	"context"
+
+	"clean-url-shortener/internal/domain/link"
+)
+
 // ИНТЕРФЕЙСЫ ДЛЯ ЗАВИСИМОСТЕЙ LINK USE CASES
 
 // EventPublisher определяет интерфейс для публикации событий
 // Используется для уведомления о переходах по ссылкам
 type EventPublisher interface {
 	// PublishLinkClicked публикует событие о клике по ссылке
-	PublishLinkClicked(linkID uint, userAgent, ipAddress, referer string) error
+	// source - метка канала из адреса ссылки (utm_source), пусто - без метки
+	PublishLinkClicked(linkID uint, userAgent, ipAddress, referer, source string) error
 }
 
 // ShortCodeGenerator определяет интерфейс для генерации коротких кодов
@@ -14,7 +21,7 @@ type EventPublisher interface {
 type ShortCodeGenerator interface {
 	// Generate генерирует новый короткий код
 	Generate() (string, error)
-	
+
 	// GenerateCustom проверяет и адаптирует пользовательский код
 	GenerateCustom(customCode string) (string, error)
 }
@@ -24,9 +31,52 @@ type ShortCodeGenerator interface {
 type URLValidator interface {
 	// Validate проверяет валидность и безопасность URL
 	Validate(url string) error
-	
+
 	// IsSafe проверяет, что URL безопасен (не фишинг, не malware)
-	IsSafe(url string) (bool, error)
+	// Проверка может обращаться к сети, поэтому ограничена ctx запроса
+	IsSafe(ctx context.Context, url string) (bool, error)
+
+	// IsSafeLocal проверяет URL только по локальным данным, без сетевых
+	// запросов - для пакетов, где обращение к каждому адресу заняло бы
+	// минуты. Остальное проверит периодическая перепроверка
+	IsSafeLocal(ctx context.Context, url string) (bool, error)
+}
+
+// SafetyChecker определяет интерфейс подробной проверки адреса назначения
+// В отличие от URLValidator.IsSafe возвращает вердикт с причиной:
+// его сохраняет периодическая перепроверка существующих ссылок
+type SafetyChecker interface {
+	// Check проверяет URL по спискам блокировки и цепочке редиректов
+	Check(ctx context.Context, url string) (SafetyVerdict, error)
+}
+
+// SafetyVerdict результат проверки адреса назначения
+type SafetyVerdict struct {
+	Status link.SafetyStatus
+	Reason string // какое правило сработало
+
+	// Inconclusive - проверку не удалось довести до конца (например,
+	// сбой при переходе по редиректу): Status учитывает только то,
+	// что удалось проверить
+	Inconclusive bool
+}
+
+// PasswordHasher определяет интерфейс для хеширования паролей ссылок
+// Тот же контракт, что у паролей пользователей (auth.PasswordHasher)
+type PasswordHasher interface {
+	// Hash хеширует пароль доступа к ссылке
+	Hash(password string) (string, error)
+
+	// Compare возвращает ошибку, если пароль не соответствует хешу
+	Compare(hashedPassword, password string) error
+}
+
+// QRCodeRenderer определяет интерфейс отрисовки QR кодов
+// Реализация может кешировать изображения: результат зависит только от аргументов
+type QRCodeRenderer interface {
+	// Render кодирует content и возвращает изображение в формате options.Format
+	// Если options.Logo, а логотип не настроен - ErrQRLogoNotAvailable
+	Render(content string, options QROptions) ([]byte, error)
 }
 
 // ПРИНЦИПЫ:
@@ -0,0 +1,258 @@
+package link
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"image/color"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"clean-url-shortener/internal/domain/domain"
+	"clean-url-shortener/internal/domain/link"
+)
+
+// Метка канала в адресе ссылки: переходы по QR коду приходят с
+// ?utm_source=qr, и TrackClickUseCase сохраняет ее как источник клика
+const (
+	SourceParam = "utm_source"
+	QRSource    = "qr"
+)
+
+// Форматы и ограничения изображения QR кода
+const (
+	QRFormatPNG = "png"
+	QRFormatSVG = "svg"
+
+	DefaultQRSize = 256
+	MinQRSize     = 64
+	MaxQRSize     = 2048
+
+	// MinQRContrast минимальный контраст цветов (как у графики в WCAG):
+	// при меньшем многие сканеры не различают модули
+	MinQRContrast = 3.0
+)
+
+// Уровни коррекции ошибок QR кода: доля модулей, которые можно потерять
+const (
+	QRLevelLow     = "L" // ~7%
+	QRLevelMedium  = "M" // ~15%
+	QRLevelQuarter = "Q" // ~25%
+	QRLevelHigh    = "H" // ~30%
+)
+
+// Ошибки генерации QR кода
+var (
+	ErrInvalidQROptions   = errors.New("invalid QR code parameters")
+	ErrQRLogoNotAvailable = errors.New("QR code logo is not configured")
+	ErrAccessDenied       = errors.New("access denied")
+)
+
+// GenerateQRRequest представляет запрос QR кода короткой ссылки
+type GenerateQRRequest struct {
+	ShortCode  string `json:"short_code" validate:"required"`
+	Domain     string `json:"domain"` // Домен ссылки (пусто - домен по умолчанию)
+	UserID     uint   `json:"-"`      // Передается из контекста аутентификации
+	Format     string `json:"format"` // png (по умолчанию) или svg
+	Size       int    `json:"size"`   // Сторона изображения в пикселях
+	ECC        string `json:"ecc"`    // L, M (по умолчанию), Q, H
+	Foreground string `json:"fg"`     // Цвет модулей, hex "000000"
+	Background string `json:"bg"`     // Цвет фона, hex "ffffff"
+	Logo       bool   `json:"logo"`   // Наложить логотип в центр
+}
+
+// GenerateQRResponse представляет готовое изображение
+type GenerateQRResponse struct {
+	Image       []byte
+	ContentType string
+	Content     string // Закодированный адрес с меткой канала
+}
+
+// QROptions проверенные параметры отрисовки QR кода
+type QROptions struct {
+	Format     string
+	Size       int
+	ECC        string
+	Foreground color.RGBA
+	Background color.RGBA
+	Logo       bool
+}
+
+// GenerateQRUseCase содержит бизнес-логику генерации QR кода ссылки
+//
+// QR код кодирует короткий адрес с меткой ?utm_source=qr, поэтому
+// переходы по напечатанному коду видны в статистике отдельно
+type GenerateQRUseCase struct {
+	linkRepo   link.Repository   // Из domain слоя
+	domainRepo domain.Repository // Из domain слоя
+	renderer   QRCodeRenderer    // Из usecase слоя
+	baseURL    string
+}
+
+// NewGenerateQRUseCase создает новый Use Case для генерации QR кодов
+func NewGenerateQRUseCase(
+	linkRepo link.Repository,
+	domainRepo domain.Repository,
+	renderer QRCodeRenderer,
+	baseURL string,
+) *GenerateQRUseCase {
+	return &GenerateQRUseCase{
+		linkRepo:   linkRepo,
+		domainRepo: domainRepo,
+		renderer:   renderer,
+		baseURL:    baseURL,
+	}
+}
+
+// Execute возвращает QR код ссылки пользователя
+func (uc *GenerateQRUseCase) Execute(ctx context.Context, req GenerateQRRequest) (*GenerateQRResponse, error) {
+	// ШАГ 1: Проверяем параметры изображения до обращения к базе
+	options, err := ParseQROptions(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// ШАГ 2: Находим ссылку на выбранном домене
+	linkDomain, err := resolveUserDomain(ctx, uc.domainRepo, req.Domain, req.UserID)
+	if err != nil {
+		return nil, err
+	}
+	foundLink, err := uc.linkRepo.FindByShortCode(ctx, domainID(linkDomain), req.ShortCode)
+	if err != nil {
+		return nil, err
+	}
+	if foundLink == nil {
+		return nil, ErrLinkNotFound
+	}
+
+	// ШАГ 3: QR код печатает только владелец, и только безопасную ссылку
+	if !foundLink.IsOwner(req.UserID) {
+		return nil, ErrAccessDenied
+	}
+	if foundLink.IsBlocked() {
+		return nil, link.ErrLinkBlocked
+	}
+
+	// ШАГ 4: Кодируем короткий адрес с меткой канала
+	content := QRContent(shortURL(uc.baseURL, linkDomain, foundLink.ShortCode))
+	image, err := uc.renderer.Render(content, options)
+	if err != nil {
+		return nil, err
+	}
+
+	return &GenerateQRResponse{
+		Image:       image,
+		ContentType: qrContentType(options.Format),
+		Content:     content,
+	}, nil
+}
+
+// QRContent добавляет к короткому адресу метку канала QR
+func QRContent(shortURL string) string {
+	return shortURL + "?" + SourceParam + "=" + url.QueryEscape(QRSource)
+}
+
+// ParseQROptions проверяет параметры запроса и подставляет значения по умолчанию
+func ParseQROptions(req GenerateQRRequest) (QROptions, error) {
+	options := QROptions{
+		Format: strings.ToLower(req.Format),
+		Size:   req.Size,
+		ECC:    strings.ToUpper(req.ECC),
+		Logo:   req.Logo,
+	}
+
+	switch options.Format {
+	case "":
+		options.Format = QRFormatPNG
+	case QRFormatPNG, QRFormatSVG:
+	default:
+		return QROptions{}, fmt.Errorf("%w: format must be png or svg", ErrInvalidQROptions)
+	}
+
+	if options.Size == 0 {
+		options.Size = DefaultQRSize
+	}
+	if options.Size < MinQRSize || options.Size > MaxQRSize {
+		return QROptions{}, fmt.Errorf("%w: size must be between %d and %d", ErrInvalidQROptions, MinQRSize, MaxQRSize)
+	}
+
+	switch options.ECC {
+	case "":
+		options.ECC = QRLevelMedium
+		if options.Logo {
+			options.ECC = QRLevelHigh
+		}
+	case QRLevelLow, QRLevelMedium:
+		// Логотип закрывает часть модулей - нужен запас на их восстановление
+		if options.Logo {
+			return QROptions{}, fmt.Errorf("%w: logo requires ecc Q or H", ErrInvalidQROptions)
+		}
+	case QRLevelQuarter, QRLevelHigh:
+	default:
+		return QROptions{}, fmt.Errorf("%w: ecc must be one of L, M, Q, H", ErrInvalidQROptions)
+	}
+
+	var err error
+	if options.Foreground, err = parseHexColor(req.Foreground, color.RGBA{A: 0xff}); err != nil {
+		return QROptions{}, fmt.Errorf("%w: fg: %v", ErrInvalidQROptions, err)
+	}
+	if options.Background, err = parseHexColor(req.Background, color.RGBA{R: 0xff, G: 0xff, B: 0xff, A: 0xff}); err != nil {
+		return QROptions{}, fmt.Errorf("%w: bg: %v", ErrInvalidQROptions, err)
+	}
+
+	// Инвертированные и бледные коды читают не все сканеры
+	fg, bg := relativeLuminance(options.Foreground), relativeLuminance(options.Background)
+	if fg >= bg || (bg+0.05)/(fg+0.05) < MinQRContrast {
+		return QROptions{}, fmt.Errorf("%w: fg must be darker than bg with contrast at least %.0f:1", ErrInvalidQROptions, MinQRContrast)
+	}
+
+	return options, nil
+}
+
+// parseHexColor разбирает цвет "rrggbb" или "rgb" (с "#" или без)
+func parseHexColor(value string, defaultColor color.RGBA) (color.RGBA, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), "#")
+	if value == "" {
+		return defaultColor, nil
+	}
+	if len(value) == 3 {
+		value = string([]byte{value[0], value[0], value[1], value[1], value[2], value[2]})
+	}
+	if len(value) != 6 {
+		return color.RGBA{}, fmt.Errorf("color %q must be 6 hex digits", value)
+	}
+
+	rgb, err := strconv.ParseUint(value, 16, 32)
+	if err != nil {
+		return color.RGBA{}, fmt.Errorf("color %q must be 6 hex digits", value)
+	}
+	return color.RGBA{R: uint8(rgb >> 16), G: uint8(rgb >> 8), B: uint8(rgb), A: 0xff}, nil
+}
+
+// relativeLuminance относительная яркость цвета по WCAG 2
+func relativeLuminance(c color.RGBA) float64 {
+	channel := func(v uint8) float64 {
+		s := float64(v) / 255
+		if s <= 0.03928 {
+			return s / 12.92
+		}
+		return math.Pow((s+0.055)/1.055, 2.4)
+	}
+	return 0.2126*channel(c.R) + 0.7152*channel(c.G) + 0.0722*channel(c.B)
+}
+
+// qrContentType MIME тип изображения
+func qrContentType(format string) string {
+	if format == QRFormatSVG {
+		return "image/svg+xml"
+	}
+	return "image/png"
+}
+
+// ПРИНЦИПЫ USE CASE QR КОДА:
+// 1. Use case решает, ЧТО кодировать (адрес с меткой канала), а отрисовку
+//    и кеширование изображений выполняет QRCodeRenderer из infrastructure
+// 2. Параметры проверяются до обращения к базе и к отрисовке
+// 3. Метка канала - обычный utm_source: ее видит и внешняя аналитика
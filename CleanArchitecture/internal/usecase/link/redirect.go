@@ -3,15 +3,23 @@ package link
 import (
 	"context"
 	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"clean-url-shortener/internal/domain/domain"
 	"clean-url-shortener/internal/domain/link"
 )
 
 // RedirectRequest представляет запрос на редирект по короткой ссылке
 type RedirectRequest struct {
 	ShortCode  string `json:"short_code" validate:"required"`
+	Host       string `json:"host"` // Заголовок Host: по нему выбирается домен ссылки
 	UserAgent  string `json:"user_agent"`
 	IPAddress  string `json:"ip_address"`
 	Referer    string `json:"referer"`
+	Source     string `json:"source"` // Метка канала из адреса ссылки (utm_source)
+	Password   string `json:"-"` // Пароль для защищенной ссылки (из формы)
 }
 
 // RedirectResponse представляет ответ с URL для редиректа
@@ -21,32 +29,55 @@ type RedirectResponse struct {
 }
 
 // Ошибки для редиректа
+// Ошибки доступности (link.ErrLinkBlocked, link.ErrLinkNotActive, link.ErrClickLimitReached,
+// link.ErrPasswordRequired, link.ErrInvalidPassword) возвращаются из домена как есть
 var (
 	ErrLinkNotFound = errors.New("link not found")
-	ErrLinkExpired  = errors.New("link has expired")
+	ErrLinkExpired  = link.ErrLinkExpired
 )
 
 // RedirectUseCase содержит бизнес-логику редиректа по короткой ссылке
+//
+// Ссылка ищется по паре (домен, код). Домен определяется по заголовку
+// Host: подтвержденный пользовательский домен обслуживает только свои
+// ссылки, любой другой хост (BASE_URL, IP, неподтвержденный домен) -
+// ссылки домена сервиса по умолчанию.
 type RedirectUseCase struct {
-	linkRepo       link.Repository // Из domain слоя
-	eventPublisher EventPublisher  // Из usecase слоя
+	linkRepo       link.Repository   // Из domain слоя
+	domainRepo     domain.Repository // Из domain слоя
+	eventPublisher EventPublisher    // Из usecase слоя
+	passwordHasher PasswordHasher    // Из usecase слоя
+	defaultHost    string            // Хост BASE_URL: для него домен не ищется
 }
 
 // NewRedirectUseCase создает новый Use Case для редиректа
 func NewRedirectUseCase(
 	linkRepo link.Repository,
+	domainRepo domain.Repository,
 	eventPublisher EventPublisher,
+	passwordHasher PasswordHasher,
+	baseURL string,
 ) *RedirectUseCase {
-	return &RedirectUseCase{
+	uc := &RedirectUseCase{
 		linkRepo:       linkRepo,
+		domainRepo:     domainRepo,
 		eventPublisher: eventPublisher,
+		passwordHasher: passwordHasher,
+	}
+	if u, err := url.Parse(baseURL); err == nil {
+		uc.defaultHost = requestHost(u.Host)
 	}
+	return uc
 }
 
 // Execute выполняет бизнес-логику редиректа по короткой ссылке
 func (uc *RedirectUseCase) Execute(ctx context.Context, req RedirectRequest) (*RedirectResponse, error) {
-	// ШАГ 1: Находим ссылку по короткому коду
-	foundLink, err := uc.linkRepo.FindByShortCode(ctx, req.ShortCode)
+	// ШАГ 1: Находим ссылку по домену из Host и короткому коду
+	domainID, err := uc.resolveDomain(ctx, req.Host)
+	if err != nil {
+		return nil, err
+	}
+	foundLink, err := uc.linkRepo.FindByShortCode(ctx, domainID, req.ShortCode)
 	if err != nil {
 		return nil, err
 	}
@@ -54,26 +85,47 @@ func (uc *RedirectUseCase) Execute(ctx context.Context, req RedirectRequest) (*R
 		return nil, ErrLinkNotFound
 	}
 
-	// ШАГ 2: Увеличиваем счетчик кликов в доменной модели
+	// ШАГ 2: Проверяем доменные правила: безопасность, период действия и лимит переходов
+	if err := foundLink.CheckAvailability(time.Now()); err != nil {
+		return nil, err
+	}
+
+	// ШАГ 3: Проверяем пароль защищенной ссылки
+	// Неверный пароль не считается переходом
+	if foundLink.IsPasswordProtected() {
+		if req.Password == "" {
+			return nil, link.ErrPasswordRequired
+		}
+		if err := uc.passwordHasher.Compare(foundLink.PasswordHash, req.Password); err != nil {
+			return nil, link.ErrInvalidPassword
+		}
+	}
+
+	// ШАГ 4: Увеличиваем счетчик кликов в доменной модели
 	// Это доменная операция - каждый переход увеличивает счетчик
 	foundLink.IncrementClicks()
 
-	// ШАГ 3: Обновляем счетчик в базе данных
-	// Можно сделать это асинхронно для улучшения производительности
+	// ШАГ 5: Обновляем счетчик в базе данных
+	// Для ссылок с лимитом именно здесь атомарно проверяется, что лимит
+	// не исчерпан параллельными переходами
 	err = uc.linkRepo.IncrementClicks(ctx, foundLink.ID)
+	if errors.Is(err, link.ErrClickLimitReached) {
+		return nil, err
+	}
 	if err != nil {
 		// Логируем ошибку, но не прерываем редирект
 		// Пользователь должен быть перенаправлен даже если счетчик не обновился
-		// TODO: логирование ошибки
+		log.Printf("Failed to increment clicks for link %d: %v", foundLink.ID, err)
 	}
 
-	// ШАГ 4: Публикуем событие о клике для сбора статистики
+	// ШАГ 6: Публикуем событие о клике для сбора статистики
 	// Это делается асинхронно через event publisher
 	err = uc.eventPublisher.PublishLinkClicked(
 		foundLink.ID,
 		req.UserAgent,
 		req.IPAddress,
 		req.Referer,
+		req.Source,
 	)
 	if err != nil {
 		// Логируем ошибку, но не прерываем редирект
@@ -81,13 +133,32 @@ func (uc *RedirectUseCase) Execute(ctx context.Context, req RedirectRequest) (*R
 		// TODO: логирование ошибки
 	}
 
-	// ШАГ 5: Возвращаем URL для редиректа
+	// ШАГ 7: Возвращаем URL для редиректа
 	return &RedirectResponse{
 		OriginalURL: foundLink.OriginalURL,
 		LinkID:      foundLink.ID,
 	}, nil
 }
 
+// resolveDomain определяет домен ссылки по заголовку Host
+// 0 - домен сервиса по умолчанию
+func (uc *RedirectUseCase) resolveDomain(ctx context.Context, hostHeader string) (uint, error) {
+	host := requestHost(hostHeader)
+	if host == "" || host == uc.defaultHost {
+		// Основной трафик идет на домен сервиса - без запроса к базе
+		return 0, nil
+	}
+
+	d, err := uc.domainRepo.FindByHost(ctx, host)
+	if err != nil {
+		return 0, err
+	}
+	if d == nil || !d.IsVerified() {
+		return 0, nil
+	}
+	return d.ID, nil
+}
+
 // ПРИНЦИПЫ ДИЗАЙНА:
 // 1. ПРОИЗВОДИТЕЛЬНОСТЬ - редирект должен быть максимально быстрым
 // 2. НАДЕЖНОСТЬ - даже если статистика не работает, редирект должен работать
@@ -0,0 +1,130 @@
+package link
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"clean-url-shortener/internal/domain/link"
+)
+
+// safetyScanPageSize сколько ссылок проверяется за одну выборку
+const safetyScanPageSize = 200
+
+// SafetyScanResult итоги одного прохода проверки
+type SafetyScanResult struct {
+	Checked int // проверено ссылок
+	Changed int // у скольких изменился статус
+	Flagged int // отмечены как подозрительные
+	Blocked int // отключены как вредоносные
+	Failed  int // проверка не удалась, статус не менялся
+
+	// Inconclusive - цепочку редиректов пройти не удалось, статус не менялся
+	Inconclusive int
+}
+
+// ScanLinksSafetyUseCase перепроверяет адреса назначения существующих ссылок
+//
+// При создании ссылка проверяется один раз, но адрес может стать
+// вредоносным позже: домен попадает в списки блокировки, а сокращатель
+// на другом конце меняет цель редиректа. Периодический проход отключает
+// такие ссылки (SafetyBlocked), отмечает подозрительные (SafetyFlagged)
+// и возвращает в работу ссылки, снятые со списков.
+//
+// Неокончательный вердикт (сбой на середине цепочки редиректов) статус
+// не меняет: иначе временная недоступность сокращателя снимала бы
+// блокировку. Исключение - блокировка по уже проверенной части цепочки.
+type ScanLinksSafetyUseCase struct {
+	linkRepo link.Repository // Из domain слоя
+	checker  SafetyChecker   // Из usecase слоя
+}
+
+// NewScanLinksSafetyUseCase создает Use Case перепроверки ссылок
+func NewScanLinksSafetyUseCase(linkRepo link.Repository, checker SafetyChecker) *ScanLinksSafetyUseCase {
+	return &ScanLinksSafetyUseCase{
+		linkRepo: linkRepo,
+		checker:  checker,
+	}
+}
+
+// Execute проверяет все ссылки и сохраняет изменившиеся статусы
+// Ошибка проверки одной ссылки не прерывает проход
+func (uc *ScanLinksSafetyUseCase) Execute(ctx context.Context) (SafetyScanResult, error) {
+	var result SafetyScanResult
+
+	for afterID := uint(0); ; {
+		// ШАГ 1: Читаем очередную страницу по возрастанию ID
+		links, err := uc.linkRepo.FindAfterID(ctx, afterID, safetyScanPageSize)
+		if err != nil {
+			return result, err
+		}
+
+		for _, l := range links {
+			afterID = l.ID
+
+			// ШАГ 2: Проверяем адрес назначения
+			verdict, err := uc.checker.Check(ctx, l.OriginalURL)
+			if err != nil {
+				if ctx.Err() != nil {
+					return result, ctx.Err()
+				}
+				log.Printf("Failed to check safety of link %d: %v", l.ID, err)
+				result.Failed++
+				continue
+			}
+			if verdict.Inconclusive && verdict.Status != link.SafetyBlocked {
+				log.Printf("Safety of link %d is inconclusive, keeping %s: %s", l.ID, l.SafetyStatus, verdict.Reason)
+				result.Inconclusive++
+				continue
+			}
+			result.Checked++
+
+			switch verdict.Status {
+			case link.SafetyFlagged:
+				result.Flagged++
+			case link.SafetyBlocked:
+				result.Blocked++
+			}
+
+			// ШАГ 3: Сохраняем статус, только если он изменился
+			if !l.SetSafety(verdict.Status, verdict.Reason, time.Now()) {
+				continue
+			}
+			if err := uc.linkRepo.UpdateSafety(ctx, l); err != nil {
+				return result, err
+			}
+			result.Changed++
+
+			if verdict.Status != link.SafetyOK {
+				log.Printf("Link %d (%s) marked %s: %s", l.ID, l.ShortCode, verdict.Status, verdict.Reason)
+			}
+		}
+
+		if len(links) < safetyScanPageSize {
+			return result, nil
+		}
+	}
+}
+
+// Run выполняет проверку сразу и затем каждые interval, пока не отменен ctx
+func (uc *ScanLinksSafetyUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		result, err := uc.Execute(ctx)
+		switch {
+		case err != nil && ctx.Err() == nil:
+			log.Printf("Failed to scan links for safety: %v", err)
+		case result.Changed > 0:
+			log.Printf("Safety scan checked %d links: %d changed, %d flagged, %d blocked",
+				result.Checked, result.Changed, result.Flagged, result.Blocked)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package stat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"clean-url-shortener/internal/domain/link"
+	"clean-url-shortener/internal/domain/stat"
+)
+
+// DefaultPeriod период статистики, если он не указан в запросе
+const DefaultPeriod = "7d"
+
+// Ошибки получения статистики
+var (
+	ErrLinkNotFound  = errors.New("link not found")
+	ErrAccessDenied  = errors.New("access denied")
+	ErrInvalidPeriod = errors.New("invalid period: use values like 24h, 7d or 30d")
+)
+
+// GetLinkStatsRequest представляет запрос статистики ссылки
+type GetLinkStatsRequest struct {
+	LinkID uint   `json:"link_id" validate:"required"`
+	UserID uint   `json:"user_id" validate:"required"`
+	Period string `json:"period"`
+}
+
+// GetLinkStatsResponse представляет агрегированную статистику ссылки
+type GetLinkStatsResponse struct {
+	LinkID          uint             `json:"link_id"`
+	ShortCode       string           `json:"short_code"`
+	TotalClicks     int              `json:"total_clicks"`
+	UniqueClicks    int              `json:"unique_clicks"`
+	ClicksByCountry map[string]int   `json:"clicks_by_country"`
+	ClicksByHour    []int            `json:"clicks_by_hour"`
+	TopReferers     []RefererSummary `json:"top_referers"`
+	ClicksBySource  map[string]int   `json:"clicks_by_source"`
+	PeriodStart     time.Time        `json:"period_start"`
+	PeriodEnd       time.Time        `json:"period_end"`
+}
+
+// RefererSummary источник трафика в ответе
+type RefererSummary struct {
+	Referer string `json:"referer"`
+	Count   int    `json:"count"`
+}
+
+// GetLinkStatsUseCase содержит бизнес-логику получения статистики ссылки
+type GetLinkStatsUseCase struct {
+	statRepo stat.Repository // Из domain слоя
+	linkRepo link.Repository // Из domain слоя
+}
+
+// NewGetLinkStatsUseCase создает новый Use Case для получения статистики
+func NewGetLinkStatsUseCase(
+	statRepo stat.Repository,
+	linkRepo link.Repository,
+) *GetLinkStatsUseCase {
+	return &GetLinkStatsUseCase{
+		statRepo: statRepo,
+		linkRepo: linkRepo,
+	}
+}
+
+// Execute возвращает статистику ссылки за период
+func (uc *GetLinkStatsUseCase) Execute(ctx context.Context, req GetLinkStatsRequest) (*GetLinkStatsResponse, error) {
+	// ШАГ 1: Разбираем период ("7d" по умолчанию)
+	if req.Period == "" {
+		req.Period = DefaultPeriod
+	}
+	period, err := stat.ParsePeriod(req.Period)
+	if err != nil {
+		return nil, ErrInvalidPeriod
+	}
+
+	// ШАГ 2: Проверяем, что ссылка существует
+	foundLink, err := uc.linkRepo.FindByID(ctx, req.LinkID)
+	if err != nil {
+		return nil, err
+	}
+	if foundLink == nil {
+		return nil, ErrLinkNotFound
+	}
+
+	// ШАГ 3: Статистику видит только владелец ссылки
+	if !foundLink.IsOwner(req.UserID) {
+		return nil, ErrAccessDenied
+	}
+
+	// ШАГ 4: Получаем агрегаты из репозитория
+	stats, err := uc.statRepo.GetLinkStatistics(ctx, foundLink.ID, period)
+	if err != nil {
+		return nil, err
+	}
+
+	// ШАГ 5: Формируем ответ
+	referers := make([]RefererSummary, 0, len(stats.TopReferers))
+	for _, r := range stats.TopReferers {
+		referers = append(referers, RefererSummary{Referer: r.Referer, Count: r.Count})
+	}
+
+	return &GetLinkStatsResponse{
+		LinkID:          foundLink.ID,
+		ShortCode:       foundLink.ShortCode,
+		TotalClicks:     stats.TotalClicks,
+		UniqueClicks:    stats.UniqueClicks,
+		ClicksByCountry: stats.ClicksByCountry,
+		ClicksByHour:    stats.ClicksByHour,
+		TopReferers:     referers,
+		ClicksBySource:  stats.ClicksBySource,
+		PeriodStart:     period.StartDate.UTC(),
+		PeriodEnd:       period.EndDate.UTC(),
+	}, nil
+}
+
+// ПРИНЦИПЫ USE CASE СТАТИСТИКИ:
+// 1. Период приходит строкой и разбирается доменной функцией ParsePeriod
+// 2. Проверка владельца - бизнес-правило, а не забота контроллера
+// 3. Ответ - собственная структура use case, domain модель наружу не отдается
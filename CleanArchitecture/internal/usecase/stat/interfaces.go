@@ -1,5 +1,10 @@
 package stat
 
+import (
+	"context"
+	"time"
+)
+
 // ИНТЕРФЕЙСЫ ДЛЯ ЗАВИСИМОСТЕЙ STAT USE CASES
 
 // GeoLocationService определяет интерфейс для определения геолокации по IP
@@ -12,21 +17,53 @@ type GeoLocationService interface {
 }
 
 // EventSubscriber определяет интерфейс для подписки на события
+// Доставка "хотя бы один раз": событие считается обработанным только после Ack
 type EventSubscriber interface {
 	// SubscribeLinkClicked подписывается на события кликов по ссылкам
+	// Канал закрывается, когда подписка остановлена и очередь вычитана
 	SubscribeLinkClicked() (<-chan LinkClickedEvent, error)
 	
+	// Ack подтверждает, что события сохранены и больше не нужны
+	Ack(events ...LinkClickedEvent) error
+	
+	// Nack сообщает об ошибке обработки события: оно будет доставлено
+	// повторно, а после исчерпания попыток попадет в DeadLetterStore
+	Nack(event LinkClickedEvent, cause error) error
+	
 	// Close закрывает подписку
 	Close() error
 }
 
+// DeadLetterStore хранит события, которые не удалось обработать
+type DeadLetterStore interface {
+	// SaveDeadLetter сохраняет событие вместе с последней ошибкой
+	SaveDeadLetter(ctx context.Context, letter DeadLetter) error
+	
+	// ListDeadLetters возвращает последние limit событий, новые первыми
+	ListDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error)
+}
+
 // LinkClickedEvent представляет событие клика по ссылке
 type LinkClickedEvent struct {
+	// ID - идентификатор доставки, назначается шиной событий
+	ID        string `json:"id"`
 	LinkID    uint   `json:"link_id"`
 	UserAgent string `json:"user_agent"`
 	IPAddress string `json:"ip_address"`
 	Referer   string `json:"referer"`
+	// Source - метка канала (utm_source), например "qr"
+	Source string `json:"source,omitempty"`
+	// Timestamp - время клика в RFC 3339, не меняется при повторах
 	Timestamp string `json:"timestamp"`
+	// Attempt - количество неудачных попыток обработки
+	Attempt int `json:"attempt"`
+}
+
+// DeadLetter событие, исчерпавшее попытки обработки
+type DeadLetter struct {
+	Event    LinkClickedEvent `json:"event"`
+	Error    string           `json:"error"`
+	FailedAt time.Time        `json:"failed_at"`
 }
 
 // ПРИНЦИПЫ:
@@ -2,15 +2,35 @@ package stat
 
 import (
 	"context"
+	"log"
+	"time"
+
 	"clean-url-shortener/internal/domain/stat"
 )
 
+// TrackingConfig настройки пакетной обработки кликов
+type TrackingConfig struct {
+	// BatchSize - сколько событий сохраняется одной транзакцией
+	BatchSize int
+
+	// FlushInterval - как часто сохраняется неполная пачка
+	FlushInterval time.Duration
+
+	// AnonymizeIP - режим приватности: в базу попадает только адрес
+	// без последнего октета (IPv4) или без последних 80 бит (IPv6).
+	// Геолокация определяется до усечения, уникальные клики
+	// становятся приблизительными
+	AnonymizeIP bool
+}
+
 // TrackClickUseCase содержит бизнес-логику отслеживания кликов по ссылкам
 // Это Use Case обрабатывает события кликов и сохраняет статистику
 type TrackClickUseCase struct {
 	statRepo        stat.Repository    // Из domain слоя
 	geoService      GeoLocationService // Из usecase слоя
 	eventSubscriber EventSubscriber    // Из usecase слоя
+	config          TrackingConfig
+	done            chan struct{} // закрывается, когда обработка остановлена
 }
 
 // NewTrackClickUseCase создает новый Use Case для отслеживания кликов
@@ -18,16 +38,31 @@ func NewTrackClickUseCase(
 	statRepo stat.Repository,
 	geoService GeoLocationService,
 	eventSubscriber EventSubscriber,
+	config TrackingConfig,
 ) *TrackClickUseCase {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+
 	return &TrackClickUseCase{
 		statRepo:        statRepo,
 		geoService:      geoService,
 		eventSubscriber: eventSubscriber,
+		config:          config,
+		done:            make(chan struct{}),
 	}
 }
 
 // StartTracking запускает отслеживание кликов по ссылкам
-// Это долго работающий процесс, который слушает события и обрабатывает их
+// Это долго работающий процесс, который слушает события и обрабатывает их.
+// Вызывается один раз; когда обработка остановится, закроется Done()
+//
+// Корректная остановка - закрыть подписку (канал событий закроется),
+// тогда последняя пачка будет сохранена. Отмена ctx останавливает
+// обработку сразу, неподтвержденные события доставит шина.
 func (uc *TrackClickUseCase) StartTracking(ctx context.Context) error {
 	// Подписываемся на события кликов
 	eventChan, err := uc.eventSubscriber.SubscribeLinkClicked()
@@ -37,8 +72,13 @@ func (uc *TrackClickUseCase) StartTracking(ctx context.Context) error {
 
 	// Запускаем обработку событий в горутине
 	go func() {
+		defer close(uc.done)
 		defer uc.eventSubscriber.Close()
 
+		batch := make([]LinkClickedEvent, 0, uc.config.BatchSize)
+		ticker := time.NewTicker(uc.config.FlushInterval)
+		defer ticker.Stop()
+
 		for {
 			select {
 			case <-ctx.Done():
@@ -47,16 +87,22 @@ func (uc *TrackClickUseCase) StartTracking(ctx context.Context) error {
 
 			case event, ok := <-eventChan:
 				if !ok {
-					// Канал закрыт, завершаем работу
+					// Канал закрыт - сохраняем остаток и завершаем работу
+					uc.processBatch(ctx, batch)
 					return
 				}
 
-				// Обрабатываем событие клика
-				err := uc.processClickEvent(ctx, event)
-				if err != nil {
-					// TODO: логирование ошибки
-					// В продакшене здесь должно быть полноценное логирование
-					continue
+				batch = append(batch, event)
+				if len(batch) >= uc.config.BatchSize {
+					uc.processBatch(ctx, batch)
+					batch = batch[:0]
+				}
+
+			case <-ticker.C:
+				// Неполная пачка не должна ждать долго
+				if len(batch) > 0 {
+					uc.processBatch(ctx, batch)
+					batch = batch[:0]
 				}
 			}
 		}
@@ -65,8 +111,53 @@ func (uc *TrackClickUseCase) StartTracking(ctx context.Context) error {
 	return nil
 }
 
+// Done возвращает канал, который закрывается после остановки обработки
+func (uc *TrackClickUseCase) Done() <-chan struct{} {
+	return uc.done
+}
+
+// processBatch сохраняет пачку событий и подтверждает их в шине
+func (uc *TrackClickUseCase) processBatch(ctx context.Context, events []LinkClickedEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	// ШАГ 1: Превращаем события в доменные модели
+	stats := make([]*stat.Stat, len(events))
+	for i, event := range events {
+		stats[i] = uc.buildStat(event)
+	}
+
+	// ШАГ 2: Сохраняем пачку одной транзакцией
+	if err := uc.statRepo.SaveBatch(ctx, stats); err == nil {
+		if err := uc.eventSubscriber.Ack(events...); err != nil {
+			log.Printf("Failed to ack %d click events: %v", len(events), err)
+		}
+		return
+	}
+
+	// ШАГ 3: Пачка не сохранилась - сохраняем по одному, чтобы одно
+	// испорченное событие не отправило на повтор всю пачку
+	for i, event := range events {
+		if err := uc.statRepo.Save(ctx, stats[i]); err != nil {
+			if nackErr := uc.eventSubscriber.Nack(event, err); nackErr != nil {
+				log.Printf("Failed to nack click event %s: %v", event.ID, nackErr)
+			}
+			continue
+		}
+		if err := uc.eventSubscriber.Ack(event); err != nil {
+			log.Printf("Failed to ack click event %s: %v", event.ID, err)
+		}
+	}
+}
+
 // processClickEvent обрабатывает одно событие клика
 func (uc *TrackClickUseCase) processClickEvent(ctx context.Context, event LinkClickedEvent) error {
+	return uc.statRepo.Save(ctx, uc.buildStat(event))
+}
+
+// buildStat создает доменную модель статистики из события
+func (uc *TrackClickUseCase) buildStat(event LinkClickedEvent) *stat.Stat {
 	// ШАГ 1: Создаем доменную модель статистики
 	clickStat := stat.NewStat(
 		event.LinkID,
@@ -75,8 +166,17 @@ func (uc *TrackClickUseCase) processClickEvent(ctx context.Context, event LinkCl
 		event.Referer,
 	)
 
+	// Метка канала (например, "qr" для переходов по QR коду) приходит
+	// из адреса ссылки, поэтому проверяется доменной моделью
+	clickStat.SetSource(event.Source)
+
+	// Время клика берем из события: повторная доставка не должна его сдвигать
+	if clickedAt, err := time.Parse(time.RFC3339Nano, event.Timestamp); err == nil {
+		clickStat.CreatedAt = clickedAt
+	}
+
 	// ШАГ 2: Обогащаем данные геолокацией
-	if uc.geoService.IsValidIP(event.IPAddress) {
+	if uc.geoService != nil && uc.geoService.IsValidIP(event.IPAddress) {
 		country, city, err := uc.geoService.GetLocation(event.IPAddress)
 		if err == nil {
 			clickStat.SetLocation(country, city)
@@ -84,13 +184,12 @@ func (uc *TrackClickUseCase) processClickEvent(ctx context.Context, event LinkCl
 		// Если геолокация не работает, продолжаем без нее
 	}
 
-	// ШАГ 3: Сохраняем статистику в базе данных
-	err := uc.statRepo.Save(ctx, clickStat)
-	if err != nil {
-		return err
+	// ШАГ 3: В режиме приватности полный IP не сохраняем
+	if uc.config.AnonymizeIP {
+		clickStat.IPAddress = clickStat.GetAnonymizedIP()
 	}
 
-	return nil
+	return clickStat
 }
 
 // TrackSingleClick обрабатывает единичный клик синхронно
@@ -113,7 +212,7 @@ func (uc *TrackClickUseCase) TrackSingleClick(
 }
 
 // АРХИТЕКТУРНЫЕ ПРИНЦИПЫ:
-// 1. АСИНХРОННАЯ ОБРАБОТКА - события обрабатываются в фоне
+// 1. АСИНХРОННАЯ ОБРАБОТКА - события обрабатываются в фоне пачками
 // 2. ОТКАЗОУСТОЙЧИВОСТЬ - ошибки в статистике не влияют на основную функциональность
-// 3. РАЗДЕЛЕНИЕ ОТВЕТСТВЕННОСТИ - Use Case координирует, domain модели содержат логику
-// 4. ТЕСТИРУЕМОСТЬ - все зависимости инжектируются через интерфейсы
\ No newline at end of file
+// 3. ДОСТАВКА "ХОТЯ БЫ ОДИН РАЗ" - Ack только после записи в базу, иначе Nack
+// 4. ТЕСТИРУЕМОСТЬ - все зависимости инжектируются через интерфейсы
@@ -1,22 +1,44 @@
 package di
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"time"
 	
 	"clean-url-shortener/configs"
 	"clean-url-shortener/internal/domain/user"
 	"clean-url-shortener/internal/domain/link"
+	"clean-url-shortener/internal/domain/stat"
+	"clean-url-shortener/internal/domain/token"
+	"clean-url-shortener/internal/domain/domain"
 	"clean-url-shortener/internal/usecase/auth"
+	domainUC "clean-url-shortener/internal/usecase/domain"
 	linkUC "clean-url-shortener/internal/usecase/link"
+	statUC "clean-url-shortener/internal/usecase/stat"
 	"clean-url-shortener/internal/infrastructure/database"
+	"clean-url-shortener/internal/infrastructure/events"
 	"clean-url-shortener/internal/infrastructure/external"
 	"clean-url-shortener/internal/infrastructure/web"
 	"clean-url-shortener/internal/interfaces/controllers"
+	"github.com/redis/go-redis/v9"
 )
 
+// EventBus шина событий кликов: публикация для redirect и подписка для статистики
+type EventBus interface {
+	linkUC.EventPublisher
+	statUC.EventSubscriber
+	
+	// Drain прекращает прием событий и дожидается доставки очереди
+	Drain(ctx context.Context) error
+}
+
 // Container содержит все зависимости приложения
 // ЭТО ГЛАВНЫЙ КОНТЕЙНЕР ДЛЯ DEPENDENCY INJECTION
 type Container struct {
@@ -24,31 +46,60 @@ type Container struct {
 	Config *configs.Config
 	
 	// Инфраструктура
-	DB     *database.DB
-	Server *web.Server
+	DB          *database.DB
+	Server      *web.Server
+	RedisClient *redis.Client // только для EVENTS_DRIVER=redis или RATE_LIMIT_DRIVER=redis
+	EventBus    EventBus
 	
 	// Репозитории (реализации интерфейсов из domain слоя)
 	UserRepo user.Repository
 	LinkRepo link.Repository
+	StatRepo stat.Repository
+	DeadLetters statUC.DeadLetterStore
+	RefreshTokenRepo token.Repository
+	TokenDenylist    auth.TokenDenylist
+	DomainRepo       domain.Repository
 	
 	// Внешние сервисы (реализации интерфейсов из usecase слоя)
 	PasswordHasher auth.PasswordHasher
 	TokenGenerator auth.TokenGenerator
 	URLValidator   linkUC.URLValidator
+	SafetyChecker  linkUC.SafetyChecker
 	ShortCodeGen   linkUC.ShortCodeGenerator
+	GeoService     statUC.GeoLocationService
+	TXTResolver    domainUC.TXTResolver
+	QRRenderer     linkUC.QRCodeRenderer
 	
 	// Use Cases (бизнес-логика приложения)
 	RegisterUC   *auth.RegisterUseCase
 	LoginUC      *auth.LoginUseCase
+	RefreshUC    *auth.RefreshUseCase
+	LogoutUC     *auth.LogoutUseCase
+	CleanupTokensUC *auth.CleanupExpiredTokensUseCase
 	CreateLinkUC *linkUC.CreateLinkUseCase
 	RedirectUC   *linkUC.RedirectUseCase
+	LinkStatsUC  *statUC.GetLinkStatsUseCase
+	TrackClickUC *statUC.TrackClickUseCase
+	CleanupLinksUC *linkUC.CleanupExpiredLinksUseCase
+	BatchCreateUC  *linkUC.BatchCreateLinksUseCase
+	ExportLinksUC  *linkUC.ExportLinksUseCase
+	ScanSafetyUC   *linkUC.ScanLinksSafetyUseCase
+	GenerateQRUC   *linkUC.GenerateQRUseCase
+	AddDomainUC    *domainUC.AddDomainUseCase
+	VerifyDomainUC *domainUC.VerifyDomainUseCase
+	ListDomainsUC  *domainUC.ListDomainsUseCase
+	DeleteDomainUC *domainUC.DeleteDomainUseCase
 	
 	// Middleware
 	AuthMiddleware *web.AuthMiddleware
+	RateLimiter    *web.RateLimiter
 	
 	// Контроллеры (HTTP handlers)
 	AuthController *controllers.AuthController
 	LinkController *controllers.LinkController
+	StatController *controllers.StatController
+	BulkLinkController *controllers.BulkLinkController
+	DomainController   *controllers.DomainController
 }
 
 // NewContainer создает и настраивает контейнер зависимостей
@@ -121,6 +172,19 @@ func (c *Container) initRepositories() error {
 	// Создаем репозитории, которые реализуют интерфейсы из domain слоя
 	c.UserRepo = database.NewUserRepository(c.DB)
 	c.LinkRepo = database.NewLinkRepository(c.DB)
+	c.StatRepo = database.NewStatRepository(c.DB)
+	c.DeadLetters = database.NewDeadLetterRepository(c.DB)
+	c.RefreshTokenRepo = database.NewRefreshTokenRepository(c.DB)
+	c.DomainRepo = database.NewDomainRepository(c.DB)
+	
+	// Denylist загружает отозванные токены при создании
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	denylist, err := database.NewTokenDenylist(ctx, c.DB, c.Config.Auth.DenylistRefresh)
+	if err != nil {
+		return fmt.Errorf("failed to load token denylist: %w", err)
+	}
+	c.TokenDenylist = denylist
 	
 	return nil
 }
@@ -137,44 +201,271 @@ func (c *Container) initExternalServices() error {
 		c.Config.Auth.JWTExpiry,
 	)
 	
-	// URL validator (простая реализация для примера)
-	c.URLValidator = &SimpleURLValidator{}
+	// Проверка адресов назначения по спискам блокировки
+	if err := c.initURLSafety(); err != nil {
+		return err
+	}
 	
 	// Short code generator (простая реализация для примера)
 	c.ShortCodeGen = &SimpleShortCodeGenerator{}
 	
+	// Системный DNS для подтверждения пользовательских доменов
+	c.TXTResolver = net.DefaultResolver
+	
+	// QR коды ссылок: отрисовка на чистом Go с LRU кешем
+	qrRenderer, err := external.NewQRRenderer(external.QRConfig{
+		LogoPath:  c.Config.QR.LogoPath,
+		CacheSize: c.Config.QR.CacheSize,
+	})
+	if err != nil {
+		return err
+	}
+	c.QRRenderer = qrRenderer
+	
+	// Геолокация по локальной базе MaxMind (если файл задан)
+	if err := c.initGeoIP(); err != nil {
+		return err
+	}
+	
+	// Шина событий кликов
+	return c.initEventBus()
+}
+
+// initURLSafety создает проверку адресов по SAFETY_*
+// Один объект служит и валидатором при создании, и проверкой при перепроверке
+func (c *Container) initURLSafety() error {
+	cfg := c.Config.Safety
+	
+	// Ссылка на сам сервис образует петлю
+	checker, err := external.NewURLSafetyChecker(external.URLSafetyConfig{
+		OwnHosts:        c.serviceHosts(),
+		IsOwnHost:       c.isVerifiedDomain,
+		DomainListPath:  cfg.DomainListPath,
+		HashPrefixPath:  cfg.HashPrefixPath,
+		RulesPath:       cfg.RulesPath,
+		ReloadInterval:  cfg.ReloadInterval,
+		FollowRedirects: cfg.FollowRedirects,
+		MaxRedirects:    cfg.MaxRedirects,
+		RedirectTimeout: cfg.RedirectTimeout,
+	})
+	if err != nil {
+		return err
+	}
+	
+	c.URLValidator = checker
+	c.SafetyChecker = checker
+	return nil
+}
+
+// serviceHosts хосты самого сервиса: SAFETY_OWN_HOSTS и хост BASE_URL
+func (c *Container) serviceHosts() []string {
+	hosts := strings.Split(c.Config.Safety.OwnHosts, ",")
+	if baseURL, err := url.Parse(c.Config.App.BaseURL); err == nil {
+		hosts = append(hosts, baseURL.Hostname())
+	}
+	return hosts
+}
+
+// isVerifiedDomain проверяет, обслуживает ли сервис хост как пользовательский домен
+// Ошибка базы не должна блокировать ссылку, поэтому считается "не наш"
+func (c *Container) isVerifiedDomain(host string) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	
+	d, err := c.DomainRepo.FindByHost(ctx, host)
+	return err == nil && d != nil && d.IsVerified()
+}
+
+// initGeoIP создает геолокацию по GEOIP_DB_PATH
+// Без файла базы страна и город кликов не определяются
+func (c *Container) initGeoIP() error {
+	cfg := c.Config.GeoIP
+	if cfg.DBPath == "" {
+		c.GeoService = &NoOpGeoLocationService{}
+		return nil
+	}
+	
+	geo, err := external.NewMMDBGeoLocationService(external.GeoIPConfig{
+		Path:           cfg.DBPath,
+		CacheSize:      cfg.CacheSize,
+		ReloadInterval: cfg.ReloadInterval,
+		Language:       cfg.Language,
+	})
+	if err != nil {
+		return err
+	}
+	
+	c.GeoService = geo
+	return nil
+}
+
+// initEventBus создает шину событий кликов по EVENTS_DRIVER
+func (c *Container) initEventBus() error {
+	cfg := c.Config.Events
+	busConfig := events.Config{
+		BufferSize:  cfg.BufferSize,
+		MaxAttempts: cfg.MaxAttempts,
+		RetryDelay:  cfg.RetryDelay,
+	}
+	
+	if cfg.Driver != "redis" {
+		c.EventBus = events.NewMemoryBus(busConfig, c.DeadLetters)
+		return nil
+	}
+	
+	if err := c.connectRedis(); err != nil {
+		return err
+	}
+	
+	// Имя потребителя должно быть уникальным для каждого экземпляра
+	hostname, _ := os.Hostname()
+	c.EventBus = events.NewRedisStreamBus(c.RedisClient, events.RedisConfig{
+		Config:        busConfig,
+		Stream:        cfg.RedisStream,
+		Group:         cfg.RedisGroup,
+		Consumer:      fmt.Sprintf("%s-%d", hostname, os.Getpid()),
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+	}, c.DeadLetters)
+	return nil
+}
+
+// connectRedis подключается к Redis один раз для всех компонентов
+func (c *Container) connectRedis() error {
+	if c.RedisClient != nil {
+		return nil
+	}
+	
+	client := redis.NewClient(&redis.Options{
+		Addr:     c.Config.Events.RedisAddr,
+		Password: c.Config.Events.RedisPassword,
+	})
+	
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return fmt.Errorf("failed to connect to redis: %w", err)
+	}
+	
+	c.RedisClient = client
 	return nil
 }
 
 // initUseCases инициализирует Use Cases
 func (c *Container) initUseCases() error {
 	// Auth Use Cases
+	// Access токен короткий, refresh токен хранится в базе и ротируется
+	tokenIssuer := auth.NewTokenIssuer(
+		c.TokenGenerator,
+		c.RefreshTokenRepo,
+		c.Config.Auth.RefreshExpiry,
+	)
+	
+	sessionRevoker := auth.NewSessionRevoker(
+		c.RefreshTokenRepo,
+		c.TokenDenylist,
+		c.Config.Auth.JWTExpiry,
+	)
+	
 	c.RegisterUC = auth.NewRegisterUseCase(
 		c.UserRepo,
 		c.PasswordHasher,
-		c.TokenGenerator,
+		tokenIssuer,
 	)
 	
 	c.LoginUC = auth.NewLoginUseCase(
 		c.UserRepo,
 		c.PasswordHasher,
-		c.TokenGenerator,
+		tokenIssuer,
+	)
+	
+	c.RefreshUC = auth.NewRefreshUseCase(
+		c.UserRepo,
+		c.RefreshTokenRepo,
+		tokenIssuer,
+		sessionRevoker,
+	)
+	
+	c.LogoutUC = auth.NewLogoutUseCase(
+		sessionRevoker,
+		c.TokenDenylist,
 	)
 	
+	c.CleanupTokensUC = auth.NewCleanupExpiredTokensUseCase(c.RefreshTokenRepo)
+	
 	// Link Use Cases
 	c.CreateLinkUC = linkUC.NewCreateLinkUseCase(
 		c.LinkRepo,
+		c.DomainRepo,
 		c.URLValidator,
 		c.ShortCodeGen,
+		c.PasswordHasher,
 		c.Config.App.BaseURL,
 	)
 	
-	// Для RedirectUC нужен EventPublisher, создаем заглушку
-	eventPublisher := &NoOpEventPublisher{}
-	
+	// RedirectUC публикует клики в шину, не дожидаясь их сохранения
 	c.RedirectUC = linkUC.NewRedirectUseCase(
 		c.LinkRepo,
-		eventPublisher,
+		c.DomainRepo,
+		c.EventBus,
+		c.PasswordHasher,
+		c.Config.App.BaseURL,
+	)
+	
+	c.BatchCreateUC = linkUC.NewBatchCreateLinksUseCase(
+		c.LinkRepo,
+		c.DomainRepo,
+		c.URLValidator,
+		c.ShortCodeGen,
+		c.Config.App.BaseURL,
+	)
+	
+	c.ExportLinksUC = linkUC.NewExportLinksUseCase(
+		c.LinkRepo,
+		c.DomainRepo,
+		c.Config.App.BaseURL,
+	)
+	
+	c.CleanupLinksUC = linkUC.NewCleanupExpiredLinksUseCase(
+		c.LinkRepo,
+		c.Config.Links.ExpiredRetention,
+	)
+	
+	c.ScanSafetyUC = linkUC.NewScanLinksSafetyUseCase(
+		c.LinkRepo,
+		c.SafetyChecker,
+	)
+	
+	c.GenerateQRUC = linkUC.NewGenerateQRUseCase(
+		c.LinkRepo,
+		c.DomainRepo,
+		c.QRRenderer,
+		c.Config.App.BaseURL,
+	)
+	
+	// Domain Use Cases
+	// Хосты сервиса нельзя зарегистрировать как пользовательский домен
+	c.AddDomainUC = domainUC.NewAddDomainUseCase(c.DomainRepo, c.serviceHosts())
+	c.VerifyDomainUC = domainUC.NewVerifyDomainUseCase(c.DomainRepo, c.TXTResolver)
+	c.ListDomainsUC = domainUC.NewListDomainsUseCase(c.DomainRepo)
+	c.DeleteDomainUC = domainUC.NewDeleteDomainUseCase(c.DomainRepo, c.LinkRepo)
+	
+	// Stat Use Cases
+	c.LinkStatsUC = statUC.NewGetLinkStatsUseCase(
+		c.StatRepo,
+		c.LinkRepo,
+	)
+	
+	c.TrackClickUC = statUC.NewTrackClickUseCase(
+		c.StatRepo,
+		c.GeoService,
+		c.EventBus,
+		statUC.TrackingConfig{
+			BatchSize:     c.Config.Events.BatchSize,
+			FlushInterval: c.Config.Events.FlushInterval,
+			AnonymizeIP:   c.Config.GeoIP.AnonymizeIP,
+		},
 	)
 	
 	return nil
@@ -182,7 +473,37 @@ func (c *Container) initUseCases() error {
 
 // initMiddleware инициализирует middleware
 func (c *Container) initMiddleware() error {
-	c.AuthMiddleware = web.NewAuthMiddleware(c.TokenGenerator)
+	c.AuthMiddleware = web.NewAuthMiddleware(c.TokenGenerator, c.TokenDenylist)
+	return c.initRateLimiter()
+}
+
+// initRateLimiter создает ограничитель частоты запросов по RATE_LIMIT_*
+func (c *Container) initRateLimiter() error {
+	cfg := c.Config.RateLimit
+	
+	limits := make(map[string]web.RateLimit)
+	for route, value := range map[string]string{
+		controllers.RateLimitCreateLink:  cfg.CreateLink,
+		controllers.RateLimitBatchCreate: cfg.BatchCreate,
+		controllers.RateLimitRedirect:    cfg.Redirect,
+		controllers.RateLimitQR:          cfg.QR,
+	} {
+		limit, err := web.ParseRateLimit(value)
+		if err != nil {
+			return fmt.Errorf("rate limit for %s: %w", route, err)
+		}
+		limits[route] = limit
+	}
+	
+	var store web.RateLimitStore = web.NewMemoryRateLimitStore()
+	if cfg.Driver == "redis" {
+		if err := c.connectRedis(); err != nil {
+			return err
+		}
+		store = web.NewRedisRateLimitStore(c.RedisClient, "ratelimit:")
+	}
+	
+	c.RateLimiter = web.NewRateLimiter(store, limits, cfg.TrustProxy)
 	return nil
 }
 
@@ -191,11 +512,36 @@ func (c *Container) initControllers() error {
 	c.AuthController = controllers.NewAuthController(
 		c.RegisterUC,
 		c.LoginUC,
+		c.RefreshUC,
+		c.LogoutUC,
+		c.AuthMiddleware,
 	)
 	
 	c.LinkController = controllers.NewLinkController(
 		c.CreateLinkUC,
 		c.RedirectUC,
+		c.GenerateQRUC,
+		c.AuthMiddleware,
+		c.RateLimiter,
+	)
+	
+	c.StatController = controllers.NewStatController(
+		c.LinkStatsUC,
+		c.AuthMiddleware,
+	)
+	
+	c.BulkLinkController = controllers.NewBulkLinkController(
+		c.BatchCreateUC,
+		c.ExportLinksUC,
+		c.AuthMiddleware,
+		c.RateLimiter,
+	)
+	
+	c.DomainController = controllers.NewDomainController(
+		c.AddDomainUC,
+		c.VerifyDomainUC,
+		c.ListDomainsUC,
+		c.DeleteDomainUC,
 		c.AuthMiddleware,
 	)
 	
@@ -227,6 +573,9 @@ func (c *Container) setupRoutes() *http.ServeMux {
 	// Регистрируем маршруты контроллеров
 	c.AuthController.RegisterRoutes(mux)
 	c.LinkController.RegisterRoutes(mux)
+	c.StatController.RegisterRoutes(mux)
+	c.BulkLinkController.RegisterRoutes(mux)
+	c.DomainController.RegisterRoutes(mux)
 	
 	// Добавляем health check endpoint
 	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
@@ -238,8 +587,50 @@ func (c *Container) setupRoutes() *http.ServeMux {
 	return mux
 }
 
+// StartBackgroundJobs запускает фоновую обработку:
+// сохранение кликов, очистку истекших ссылок и refresh токенов,
+// перепроверку адресов назначения
+func (c *Container) StartBackgroundJobs(ctx context.Context) error {
+	if err := c.TrackClickUC.StartTracking(ctx); err != nil {
+		return err
+	}
+	
+	go c.CleanupLinksUC.Run(ctx, c.Config.Links.CleanupInterval)
+	go c.CleanupTokensUC.Run(ctx, c.Config.Auth.TokensCleanupInterval)
+	go c.ScanSafetyUC.Run(ctx, c.Config.Safety.ScanInterval)
+	return nil
+}
+
+// Shutdown корректно останавливает фоновую обработку
+// Вызывается после остановки HTTP сервера: новых кликов уже нет,
+// очередь дочитывается и сохраняется в базу до закрытия соединений
+func (c *Container) Shutdown(ctx context.Context) error {
+	drainCtx, cancel := context.WithTimeout(ctx, c.Config.Events.DrainTimeout)
+	defer cancel()
+	
+	err := c.EventBus.Drain(drainCtx)
+	
+	// Ждем, пока TrackClickUseCase сохранит последнюю пачку
+	select {
+	case <-c.TrackClickUC.Done():
+	case <-drainCtx.Done():
+		if err == nil {
+			err = drainCtx.Err()
+		}
+	}
+	return err
+}
+
 // Cleanup освобождает ресурсы
 func (c *Container) Cleanup() error {
+	for _, service := range []interface{}{c.GeoService, c.SafetyChecker} {
+		if closer, ok := service.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+	if c.RedisClient != nil {
+		c.RedisClient.Close()
+	}
 	if c.DB != nil {
 		return c.DB.Close()
 	}
@@ -249,19 +640,6 @@ func (c *Container) Cleanup() error {
 // ПРОСТЫЕ РЕАЛИЗАЦИИ ДЛЯ ПРИМЕРА
 // В реальном проекте эти компоненты были бы более сложными
 
-// SimpleURLValidator простая реализация валидатора URL
-type SimpleURLValidator struct{}
-
-func (v *SimpleURLValidator) Validate(rawURL string) error {
-	_, err := url.Parse(rawURL)
-	return err
-}
-
-func (v *SimpleURLValidator) IsSafe(rawURL string) (bool, error) {
-	// Простая проверка - в реальном проекте здесь была бы проверка на malware/phishing
-	return true, nil
-}
-
 // SimpleShortCodeGenerator простая реализация генератора коротких кодов
 type SimpleShortCodeGenerator struct{}
 
@@ -289,12 +667,16 @@ func (g *SimpleShortCodeGenerator) GenerateCustom(customCode string) (string, er
 	return customCode, nil
 }
 
-// NoOpEventPublisher заглушка для event publisher
-type NoOpEventPublisher struct{}
+// NoOpGeoLocationService заглушка для геолокации
+type NoOpGeoLocationService struct{}
 
-func (p *NoOpEventPublisher) PublishLinkClicked(linkID uint, userAgent, ipAddress, referer string) error {
-	// В реальном проекте здесь была бы публикация событий
-	return nil
+func (g *NoOpGeoLocationService) GetLocation(ipAddress string) (country, city string, err error) {
+	// В реальном проекте здесь был бы поиск по базе GeoIP
+	return "", "", nil
+}
+
+func (g *NoOpGeoLocationService) IsValidIP(ipAddress string) bool {
+	return net.ParseIP(ipAddress) != nil
 }
 
 // ПРИНЦИПЫ DEPENDENCY INJECTION:
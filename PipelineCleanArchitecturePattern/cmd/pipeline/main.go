@@ -2,24 +2,59 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
 
 	// ИМПОРТЫ ПО СЛОЯМ CLEAN ARCHITECTURE:
 	
 	// APPLICATION LAYER - шаги пайплайна, которые координируют бизнес-процессы
+	"pipeline-clean-architecture/internal/application/inventoryservice"
+	"pipeline-clean-architecture/internal/application/orderservice"
+	"pipeline-clean-architecture/internal/application/paymentservice"
+	"pipeline-clean-architecture/internal/application/promotionservice"
 	"pipeline-clean-architecture/internal/application/pipeline"
 	
+	// DELIVERY LAYER - REST и gRPC API поверх use case слоя
+	"pipeline-clean-architecture/internal/delivery/grpcapi"
+	"pipeline-clean-architecture/internal/delivery/rest"
+	
 	// DOMAIN LAYER - доменные сущности с бизнес-логикой (НЕ зависят от внешних систем)
 	"pipeline-clean-architecture/internal/domain/order"
 	"pipeline-clean-architecture/internal/domain/payment"
+	"pipeline-clean-architecture/internal/domain/product"
+	"pipeline-clean-architecture/internal/domain/promotion"
+	
+	// INFRASTRUCTURE LAYER - реализации репозиториев и адаптеры внешних систем
+	"pipeline-clean-architecture/internal/infrastructure/exchangerates"
+	"pipeline-clean-architecture/internal/infrastructure/inventorystore"
+	"pipeline-clean-architecture/internal/infrastructure/paymentgateway"
+	"pipeline-clean-architecture/internal/infrastructure/paymentstore"
+	"pipeline-clean-architecture/internal/infrastructure/promotionstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore"
 	
 	// PKG LAYER - переиспользуемые компоненты (Pipeline Engine)
 	pipelineEngine "pipeline-clean-architecture/pkg/pipeline"
+	"pipeline-clean-architecture/pkg/pipeline/metrics"
+	"pipeline-clean-architecture/pkg/pipeline/tracing"
 
 	// ВНЕШНИЕ ЗАВИСИМОСТИ
+	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"errors"
 )
 
@@ -71,11 +106,58 @@ func main() {
 	
 	// INFRASTRUCTURE LAYER - моки репозиториев и внешних сервисов
 	// В реальности это были бы PostgreSQLOrderRepository, StripePaymentService и т.д.
-	orderRepo := &MockOrderRepository{}                // Имитация PostgreSQL репозитория
+	var orderRepo order.Repository = &MockOrderRepository{} // Имитация PostgreSQL репозитория
+	var promotionUsage promotion.UsageRepository = promotionstore.NewMemoryUsageRepository(orderRepo)
+	
+	// ORDERS_DB_DIALECT=postgres|sqlite и ORDERS_DB_DSN включают настоящее SQL хранилище
+	// (использования скидок тогда считаются по order_promotions той же базы)
+	if dsn := os.Getenv("ORDERS_DB_DSN"); dsn != "" {
+		sqlRepo, err := openOrderRepository(sqlstore.Dialect(os.Getenv("ORDERS_DB_DIALECT")), dsn)
+		if err != nil {
+			logger.Fatal("Failed to open order database", zap.Error(err))
+		}
+		orderRepo = sqlRepo
+		promotionUsage = sqlstore.NewPromotionUsageRepository(sqlRepo)
+	}
+	
 	productService := &MockProductService{}            // Имитация сервиса каталога товаров
-	paymentService := &MockPaymentService{}            // Имитация Stripe/PayPal API
-	inventoryService := &MockInventoryService{}        // Имитация системы управления складом
+	var paymentService pipeline.PaymentService = &MockPaymentService{} // Имитация Stripe/PayPal API
 	notificationService := &MockNotificationService{}  // Имитация email/SMS сервисов
+	
+	// Склад: остатки и резервы в памяти, каталог заполняется демо товарами
+	products := inventorystore.NewProductRepository()
+	catalog, err := seedDemoCatalog(context.Background(), products)
+	if err != nil {
+		logger.Fatal("Failed to seed product catalog", zap.Error(err))
+	}
+	for _, p := range catalog {
+		logger.Info("📦 Product in stock",
+			zap.String("product_id", p.ID().String()),
+			zap.String("name", p.Name()),
+			zap.Int("stock", p.StockLevel()))
+	}
+	inventoryService := inventoryservice.NewService(logger, products, inventorystore.NewReservationRepository(), orderRepo, inventoryservice.DefaultConfig())
+	
+	// Просроченные резервы неоплаченных заказов снимаются, пока работает процесс
+	inventoryCtx, stopInventory := context.WithCancel(context.Background())
+	defer stopInventory()
+	go inventoryService.Run(inventoryCtx)
+	
+	// PROMOTIONS_FILE включает правила скидок (см. configs/promotions);
+	// категории товаров берутся из каталога склада
+	var promotionService *promotionservice.Service
+	if promotionsFile := os.Getenv("PROMOTIONS_FILE"); promotionsFile != "" {
+		rules, err := promotionstore.LoadFile(promotionsFile)
+		if err != nil {
+			logger.Fatal("Failed to load promotions", zap.Error(err))
+		}
+		promotionService = promotionservice.NewService(logger, rules, promotionUsage, orderRepo, products)
+	}
+	
+	// PAYMENT_GATEWAY_URL подключает карты и СБП через настоящие адаптеры провайдера
+	if gatewayURL := os.Getenv("PAYMENT_GATEWAY_URL"); gatewayURL != "" {
+		paymentService = newPaymentProcessor(logger, orderRepo, gatewayURL)
+	}
 
 	// ================================================
 	// ЭТАП 3: КОНФИГУРАЦИЯ PIPELINE ENGINE
@@ -96,13 +178,32 @@ func main() {
 			Multiplier:  2.0,                     // Экспоненциальный backoff (1s, 2s, 4s...)
 			Jitter:      true,                    // Добавить случайное отклонение для избежания thundering herd
 		},
-		BufferSize: 100,                          // Размер буферов каналов
+		BufferSize: 100,                          // Размер буферов каналов и очереди ожидания
+		QueuePolicy: pipelineEngine.QueuePolicyWait, // При заполненной очереди ждать, а не отклонять
+		PipelinePriorities: pipeline.PipelinePriorities(map[string]order.Priority{
+			"order_processing": order.PriorityNormal, // Приоритет пайплайна в очереди
+		}),
 	}
 
 	// Создаем экземпляр Pipeline Engine
 	// Это центральный компонент, который будет координировать выполнение всех шагов
 	engine := pipelineEngine.NewEngine(logger, config)
 
+	// Наблюдаемость: метрики Prometheus и span'ы OpenTelemetry на каждый шаг.
+	// Экспортер трейсов настраивается через глобальный otel.TracerProvider.
+	// Метрики отдаются REST API по GET /metrics.
+	var metricsHandler http.Handler
+	if config.EnableMetrics {
+		registry := prometheus.NewRegistry()
+		collector, err := metrics.NewPrometheusCollector("pipeline", registry)
+		if err != nil {
+			logger.Fatal("Failed to register pipeline metrics", zap.Error(err))
+		}
+		engine.SetMetricsCollector(collector)
+		metricsHandler = promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+	}
+	engine.RegisterMiddleware(tracing.NewMiddleware(otel.GetTracerProvider()))
+
 	// ================================================
 	// ЭТАП 4: СОЗДАНИЕ И РЕГИСТРАЦИЯ ШАГОВ ПАЙПЛАЙНА
 	// ================================================
@@ -123,6 +224,10 @@ func main() {
 	// Зависимости: логгер + репозиторий заказов + платежный сервис
 	// Что делает: обрабатывает платеж через внешний сервис (Stripe/PayPal)
 	paymentStep := pipeline.NewProcessPaymentStep(logger, orderRepo, paymentService)
+	if promotionService != nil {
+		// При возврате платежа лимиты скидок заказа освобождаются
+		paymentStep.SetPromotionReleaser(promotionService)
+	}
 	
 	// Шаг 3: Проверка склада
 	// Зависимости: логгер + репозиторий заказов + сервис управления складом
@@ -154,9 +259,11 @@ func main() {
 	}
 
 	// Создаем тестовый заказ для демонстрации
-	testOrderID := uuid.New()
-	testOrder := createTestOrder(testOrderID)
-	orderRepo.orders[testOrderID] = testOrder
+	testOrder := createTestOrder(catalog)
+	testOrderID := testOrder.ID()
+	if err := orderRepo.Save(context.Background(), testOrder); err != nil {
+		logger.Fatal("Failed to save test order", zap.Error(err))
+	}
 
 	logger.Info("📋 Created test order", 
 		zap.String("order_id", testOrderID.String()),
@@ -164,29 +271,59 @@ func main() {
 		zap.Int("items_count", len(testOrder.Items())),
 		zap.String("total_amount", testOrder.TotalAmount().String()))
 
-	// Определяем пайплайн обработки заказов
-	pipelineDefinition := pipelineEngine.PipelineDefinition{
-		Name:        "order_processing",
-		Description: "Полный пайплайн обработки e-commerce заказа",
-		Steps: []string{
-			"validate_order",
-			"process_payment", 
-			"check_inventory",
-			"send_notifications",
-		},
-		Timeout: 10 * time.Minute,
-		Retry: pipelineEngine.RetryConfig{
-			MaxAttempts: 3,
-			BaseDelay:   2 * time.Second,
-			MaxDelay:    30 * time.Second,
-			Multiplier:  2.0,
-			Jitter:      true,
-		},
-		Metadata: map[string]string{
-			"version":     "1.0",
-			"environment": "demo",
-			"owner":       "order_processing_team",
-		},
+	// Загружаем определение пайплайна из файла (configs/pipelines/order_processing.yaml)
+	// Шаги проверяются по реестру движка, ошибки указывают файл и строку
+	definitionsDir := os.Getenv("PIPELINE_DEFINITIONS_DIR")
+	if definitionsDir == "" {
+		definitionsDir = "configs/pipelines"
+	}
+
+	definitionLoader := pipelineEngine.NewDefinitionLoader(engine, logger)
+	if err := definitionLoader.LoadDir(definitionsDir); err != nil {
+		logger.Fatal("Failed to load pipeline definitions", zap.Error(err))
+	}
+
+	pipelineDefinition, ok := definitionLoader.Get("order_processing")
+	if !ok {
+		logger.Fatal("Pipeline definition not found",
+			zap.String("pipeline", "order_processing"),
+			zap.String("dir", definitionsDir))
+	}
+
+	// ORDERS_HTTP_ADDR и/или ORDERS_GRPC_ADDR запускают API заказов вместо демо
+	httpAddr, grpcAddr := os.Getenv("ORDERS_HTTP_ADDR"), os.Getenv("ORDERS_GRPC_ADDR")
+	if httpAddr != "" || grpcAddr != "" {
+		deps := orderservice.Dependencies{
+			Engine:        engine,
+			Definitions:   definitionLoader,
+			Orders:        orderRepo,
+			Products:      productService,
+			Inventory:     inventoryService,
+			Notifications: notificationService,
+		}
+		
+		// EXCHANGE_RATES_FILE: цены каталога в других валютах пересчитываются
+		// в ORDERS_CURRENCY (по умолчанию RUB)
+		if ratesFile := os.Getenv("EXCHANGE_RATES_FILE"); ratesFile != "" {
+			rates, err := exchangerates.LoadStaticFile(ratesFile)
+			if err != nil {
+				logger.Fatal("Failed to load exchange rates", zap.Error(err))
+			}
+			deps.Rates = rates
+		}
+		
+		service, err := orderservice.NewService(logger, deps, orderservice.Config{Currency: os.Getenv("ORDERS_CURRENCY")})
+		if err != nil {
+			logger.Fatal("Failed to create order service", zap.Error(err))
+		}
+		
+		var promotions rest.PromotionApplier
+		if promotionService != nil {
+			promotions = promotionService
+		}
+		
+		serveAPI(logger, engine, definitionLoader, definitionsDir, service, paymentService, promotions, metricsHandler, httpAddr, grpcAddr)
+		return
 	}
 
 	// Подготавливаем входные данные для пайплайна
@@ -202,6 +339,12 @@ func main() {
 	ctx := context.Background()
 	executionContext, err := engine.Execute(ctx, pipelineDefinition, initialData)
 
+	if err != nil && executionContext == nil {
+		// Пайплайн не был запущен (например, очередь заполнена)
+		logger.Error("❌ Pipeline was not started", zap.Error(err))
+		return
+	}
+
 	if err != nil {
 		logger.Error("❌ Pipeline execution failed", 
 			zap.Error(err),
@@ -227,9 +370,110 @@ func main() {
 		zap.String("status", finalOrder.Status().String()),
 		zap.String("total_amount", finalOrder.TotalAmount().String()))
 
+	// Дожидаемся пайплайнов в очереди движка перед выходом
+	if err := engine.Shutdown(ctx); err != nil {
+		logger.Error("Failed to shut down pipeline engine", zap.Error(err))
+	}
+
 	logger.Info("🎉 Demo completed successfully!")
 }
 
+// serveAPI обслуживает REST и gRPC API заказов до SIGINT/SIGTERM
+//
+// Пока API работает, каталог определений пайплайнов перечитывается при
+// изменении файлов (PIPELINE_RELOAD_INTERVAL, по умолчанию 5s): сервис
+// заказов берет определение из загрузчика при каждом запуске пайплайна.
+func serveAPI(logger *zap.Logger, engine *pipelineEngine.Engine, definitions *pipelineEngine.DefinitionLoader, definitionsDir string, service *orderservice.Service, paymentService pipeline.PaymentService, promotions rest.PromotionApplier, metricsHandler http.Handler, httpAddr, grpcAddr string) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	
+	reloadInterval := 5 * time.Second
+	if interval := os.Getenv("PIPELINE_RELOAD_INTERVAL"); interval != "" {
+		value, err := time.ParseDuration(interval)
+		if err != nil || value <= 0 {
+			logger.Fatal("Invalid PIPELINE_RELOAD_INTERVAL", zap.String("value", interval), zap.Error(err))
+		}
+		reloadInterval = value
+	}
+	go func() {
+		// Невалидные файлы не заменяют загруженный набор (см. LoadDir)
+		if err := definitions.Watch(ctx, definitionsDir, reloadInterval); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Error("Pipeline definitions watcher stopped", zap.Error(err))
+		}
+	}()
+	
+	var httpServer *http.Server
+	if httpAddr != "" {
+		router := gin.New()
+		router.Use(gin.Recovery())
+		rest.NewHandler(service, service, logger).Register(router)
+		if webhooks, ok := paymentService.(rest.PaymentWebhookProcessor); ok {
+			rest.NewWebhookHandler(webhooks, logger).Register(router)
+		}
+		if promotions != nil {
+			rest.NewPromotionHandler(promotions, logger).Register(router)
+		}
+		if metricsHandler != nil {
+			router.GET("/metrics", gin.WrapH(metricsHandler))
+		}
+		
+		httpServer = &http.Server{Addr: httpAddr, Handler: router}
+		go func() {
+			logger.Info("🌐 Order REST API listening", zap.String("addr", httpAddr))
+			if err := httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				logger.Error("REST API server failed", zap.Error(err))
+				stop()
+			}
+		}()
+	}
+	
+	var grpcServer *grpc.Server
+	if grpcAddr != "" {
+		listener, err := net.Listen("tcp", grpcAddr)
+		if err != nil {
+			logger.Fatal("Failed to listen for gRPC", zap.String("addr", grpcAddr), zap.Error(err))
+		}
+		
+		grpcServer = grpc.NewServer()
+		grpcapi.NewServer(service, service, logger).Register(grpcServer)
+		go func() {
+			logger.Info("🛰️ Order gRPC API listening", zap.String("addr", grpcAddr))
+			if err := grpcServer.Serve(listener); err != nil {
+				logger.Error("gRPC API server failed", zap.Error(err))
+				stop()
+			}
+		}()
+	}
+	
+	<-ctx.Done()
+	logger.Info("Shutting down order API")
+	
+	// Открытые SSE и gRPC стримы не должны держать остановку дольше таймаута
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	
+	if httpServer != nil {
+		if err := httpServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Failed to shut down REST API", zap.Error(err))
+		}
+	}
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			grpcServer.Stop()
+		}
+	}
+	if err := engine.Shutdown(shutdownCtx); err != nil {
+		logger.Error("Failed to shut down pipeline engine", zap.Error(err))
+	}
+}
+
 // displayResults показывает подробные результаты выполнения пайплайна
 func displayResults(logger *zap.Logger, execCtx *pipelineEngine.ExecutionContext) {
 	logger.Info("📊 Pipeline Execution Results",
@@ -280,24 +524,24 @@ func displayResults(logger *zap.Logger, execCtx *pipelineEngine.ExecutionContext
 	}
 }
 
-// createTestOrder создает тестовый заказ для демонстрации
-func createTestOrder(orderID uuid.UUID) *order.Order {
+// createTestOrder создает тестовый заказ для демонстрации из товаров каталога
+func createTestOrder(catalog []*product.Product) *order.Order {
 	customerID := uuid.New()
 	
 	// Создаем товары заказа
 	items := []order.OrderItem{
-		createOrderItem(uuid.New(), 2, 150000), // Товар 1: 2 шт по 1500 руб
-		createOrderItem(uuid.New(), 1, 75000),  // Товар 2: 1 шт по 750 руб
+		createOrderItem(catalog[0].ID(), 2, catalog[0].Price().Amount()), // Товар 1: 2 шт по 1500 руб
+		createOrderItem(catalog[1].ID(), 1, catalog[1].Price().Amount()), // Товар 2: 1 шт по 750 руб
 	}
 
 	// Создаем адрес доставки
-	shippingAddress := order.Address{
-		Street:     "ул. Примерная, д. 123, кв. 45",
-		City:       "Москва", 
-		PostalCode: "123456",
-		Country:    "Россия",
-		Phone:      "+7 (900) 123-45-67",
-	}
+	shippingAddress := order.NewAddress(
+		"ул. Примерная, д. 123, кв. 45",
+		"Москва",
+		"123456",
+		"Россия",
+		"+7 (900) 123-45-67",
+	)
 
 	// Создаем заказ
 	testOrder, err := order.NewOrder(customerID, items, shippingAddress)
@@ -312,28 +556,115 @@ func createTestOrder(orderID uuid.UUID) *order.Order {
 	return testOrder
 }
 
+// seedDemoCatalog сохраняет демо товары с остатками на складе
+func seedDemoCatalog(ctx context.Context, products product.Repository) ([]*product.Product, error) {
+	demo := []struct {
+		name, sku, category, path string
+		price                     int64
+		stock                     int
+	}{
+		{"Механическая клавиатура", "KB-001", "Computers", "Electronics > Computers", 150000, 50},
+		{"Чехол для ноутбука", "CASE-001", "Accessories", "Electronics > Accessories", 75000, 100},
+	}
+	
+	catalog := make([]*product.Product, 0, len(demo))
+	for _, d := range demo {
+		category, err := product.NewCategory(d.category, d.path)
+		if err != nil {
+			return nil, err
+		}
+		p, err := product.NewProduct(d.name, "", d.sku, category, product.NewMoney(d.price, "RUB"))
+		if err != nil {
+			return nil, err
+		}
+		if err := p.UpdateStock(d.stock); err != nil {
+			return nil, err
+		}
+		if err := products.Save(ctx, p); err != nil {
+			return nil, fmt.Errorf("save product %s: %w", d.sku, err)
+		}
+		catalog = append(catalog, p)
+	}
+	return catalog, nil
+}
+
 // createOrderItem создает товар заказа (хелпер функция)
 func createOrderItem(productID uuid.UUID, quantity int, priceKopecks int64) order.OrderItem {
-	// В реальном приложении это было бы через фабричный метод
-	// Здесь используем reflection для доступа к приватным полям (только для демо!)
-	money := order.Money{Amount: priceKopecks, Currency: "RUB"}
-	
-	return order.OrderItem{
-		ProductID: productID,
-		Quantity:  quantity,
-		UnitPrice: money,
-		Discount:  order.Money{Amount: 0, Currency: "RUB"},
+	item, err := order.NewOrderItem(productID, quantity, order.NewMoney(priceKopecks, "RUB"))
+	if err != nil {
+		log.Fatalf("Failed to create order item: %v", err)
 	}
+	
+	return item
 }
 
 // 🔧 MOCK РЕАЛИЗАЦИИ СЕРВИСОВ ДЛЯ ДЕМОНСТРАЦИИ
 
+// newPaymentProcessor подключает адаптеры карт и СБП к процессору платежей
+//
+// PAYMENT_GATEWAY_KEY - API ключ, PAYMENT_WEBHOOK_SECRET - секрет подписи
+// вебхуков, PAYMENT_CONFIRMATION_TIMEOUT - сколько шаг оплаты ждет QR / 3-D Secure.
+func newPaymentProcessor(logger *zap.Logger, orderRepo order.Repository, gatewayURL string) *paymentservice.Processor {
+	var config paymentservice.Config
+	if timeout := os.Getenv("PAYMENT_CONFIRMATION_TIMEOUT"); timeout != "" {
+		value, err := time.ParseDuration(timeout)
+		if err != nil {
+			logger.Fatal("Invalid PAYMENT_CONFIRMATION_TIMEOUT", zap.Error(err))
+		}
+		config.ConfirmationTimeout = value
+	}
+	
+	gatewayConfig := paymentgateway.Config{
+		BaseURL:       gatewayURL,
+		APIKey:        os.Getenv("PAYMENT_GATEWAY_KEY"),
+		WebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+	}
+	
+	processor := paymentservice.NewProcessor(logger, orderRepo, paymentstore.NewMemoryRepository(), config)
+	processor.RegisterGateway(paymentgateway.NewCardGateway(gatewayConfig), payment.MethodCard)
+	processor.RegisterGateway(paymentgateway.NewSBPGateway(gatewayConfig), payment.MethodSBP)
+	return processor
+}
+
+// openOrderRepository подключает SQL репозиторий заказов и применяет миграции
+func openOrderRepository(dialect sqlstore.Dialect, dsn string) (*sqlstore.OrderRepository, error) {
+	driver := map[sqlstore.Dialect]string{
+		sqlstore.DialectPostgres: "postgres",
+		sqlstore.DialectSQLite:   "sqlite3",
+	}[dialect]
+	if driver == "" {
+		return nil, fmt.Errorf("unsupported ORDERS_DB_DIALECT %q", dialect)
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return nil, err
+	}
+	if dialect == sqlstore.DialectSQLite {
+		// SQLite допускает одного писателя; для ":memory:" это еще и одна база
+		db.SetMaxOpenConns(1)
+	}
+
+	repo, err := sqlstore.NewOrderRepository(db, dialect)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.Migrate(context.Background()); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
 // MockOrderRepository мок репозитория заказов
 type MockOrderRepository struct {
+	mu     sync.RWMutex // API обслуживает запросы параллельно
 	orders map[uuid.UUID]*order.Order
 }
 
 func (r *MockOrderRepository) Save(ctx context.Context, ord *order.Order) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	
 	if r.orders == nil {
 		r.orders = make(map[uuid.UUID]*order.Order)
 	}
@@ -342,13 +673,12 @@ func (r *MockOrderRepository) Save(ctx context.Context, ord *order.Order) error
 }
 
 func (r *MockOrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*order.Order, error) {
-	if r.orders == nil {
-		r.orders = make(map[uuid.UUID]*order.Order)
-	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
 	
 	ord, exists := r.orders[id]
 	if !exists {
-		return nil, errors.New("order not found")
+		return nil, fmt.Errorf("order %s: %w", id, order.ErrOrderNotFound)
 	}
 	return ord, nil
 }
@@ -387,7 +717,7 @@ func (r *MockOrderRepository) CountByStatus(ctx context.Context) (map[order.Stat
 }
 
 func (r *MockOrderRepository) GetTotalRevenueByPeriod(ctx context.Context, from, to time.Time) (order.Money, error) {
-	return order.Money{}, errors.New("not implemented")
+	return order.NewMoney(0, "RUB"), errors.New("not implemented")
 }
 
 func (r *MockOrderRepository) SaveBatch(ctx context.Context, orders []*order.Order) error {
@@ -408,7 +738,7 @@ func (s *MockProductService) CheckProductAvailability(ctx context.Context, produ
 
 func (s *MockProductService) GetProductPrice(ctx context.Context, productID uuid.UUID) (order.Money, error) {
 	// Возвращаем фиксированную цену для демо
-	return order.Money{Amount: 150000, Currency: "RUB"}, nil
+	return order.NewMoney(150000, "RUB"), nil
 }
 
 // MockPaymentService мок сервиса платежей
@@ -417,7 +747,7 @@ type MockPaymentService struct{}
 func (s *MockPaymentService) ProcessPayment(ctx context.Context, orderID uuid.UUID, method payment.Method) (*payment.Payment, error) {
 	// Создаем успешный платеж для демо
 	pmt, err := payment.NewPayment(orderID, uuid.New(), 
-		payment.Money{Amount: 375000, Currency: "RUB"}, 
+		payment.NewMoney(375000, "RUB"), 
 		method, 
 		payment.ProviderSberbank)
 	
@@ -437,25 +767,9 @@ func (s *MockPaymentService) ValidatePaymentMethod(method payment.Method) error
 	return nil
 }
 
-// MockInventoryService мок сервиса склада
-type MockInventoryService struct{}
-
-func (s *MockInventoryService) CheckAvailability(ctx context.Context, productID uuid.UUID, quantity int) (bool, error) {
-	// Для демо всегда есть в наличии
-	return true, nil
-}
-
-func (s *MockInventoryService) ReserveItems(ctx context.Context, items []pipeline.ReservationItem) (*pipeline.Reservation, error) {
-	// Создаем успешное резервирование
-	return &pipeline.Reservation{
-		ID:        uuid.New(),
-		Items:     items,
-		ExpiresAt: time.Now().Add(24 * time.Hour),
-	}, nil
-}
-
-func (s *MockInventoryService) ReleaseReservation(ctx context.Context, reservationID uuid.UUID) error {
-	// Для демо всегда успешно
+func (s *MockPaymentService) RefundPayment(ctx context.Context, paymentID uuid.UUID, reason string) error {
+	// Имитируем возврат платежа
+	log.Printf("💸 REFUND %s: %s", paymentID.String(), reason)
 	return nil
 }
 
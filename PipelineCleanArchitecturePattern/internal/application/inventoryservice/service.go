@@ -0,0 +1,457 @@
+package inventoryservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"pipeline-clean-architecture/internal/application/pipeline"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/product"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 📦 СЕРВИС СКЛАДА
+//
+// ============================================================================
+// ЧТО ДЕЛАЕТ Service:
+// ============================================================================
+//
+// Service реализует pipeline.InventoryService поверх product.Repository:
+//
+//	ReserveItems       → Product.Reserve для каждой позиции + активный резерв с TTL
+//	ReserveOrderItems  → то же для заказа; повтор возвращает уже созданный резерв
+//	ReleaseReservation → Product.RestoreStock, резерв released
+//	ConfirmReservation → резерв оплаченного заказа confirmed, TTL не действует
+//	ExpireReservations → резервы неоплаченных заказов с истекшим TTL снимаются,
+//	                     резерв оплаченного подтверждается (только один на заказ)
+//
+// ============================================================================
+// ЗАЩИТА ОТ ПЕРЕПРОДАЖИ:
+// ============================================================================
+//
+// Остаток каждого товара меняется циклом read-modify-write с проверкой версии
+// в Save (optimistic locking по SKU). При конфликте версия перечитывается и
+// попытка повторяется до MaxConflictRetries раз. Позиции обрабатываются в
+// порядке ID товара; если одна из них не резервируется, уже списанные
+// позиции возвращаются на склад.
+//
+// Глобальной блокировки на остатки нет: заказы на разные товары не ждут
+// друг друга.
+//
+// ============================================================================
+// ПОВТОР РЕЗЕРВА ЗАКАЗА:
+// ============================================================================
+//
+// Шаг check_inventory повторяется движком и после восстановления (Resume).
+// Если у заказа уже есть резерв, держащий остаток, ReserveOrderItems
+// возвращает его, а не списывает товар второй раз. Резервы одного заказа
+// создаются под блокировкой его полосы (orderLocks), чтобы два повтора
+// не разминулись между проверкой и сохранением.
+//
+// ============================================================================
+// СОБЫТИЯ:
+// ============================================================================
+//
+// Когда IsLowStock() товара становится true или остаток доходит до нуля,
+// сервис отправляет product.StockEvent в StockEventPublisher (SetEventPublisher).
+// Ошибка публикации не отменяет резерв - она только логируется.
+//
+// ============================================================================
+
+// Проверка реализации интерфейсов пайплайна
+var (
+	_ pipeline.InventoryService       = (*Service)(nil)
+	_ pipeline.OrderInventoryReserver = (*Service)(nil)
+	_ pipeline.ReservationConfirmer   = (*Service)(nil)
+)
+
+// Config настройки сервиса склада
+type Config struct {
+	// ReservationTTL сколько резерв ждет оплаты заказа (по умолчанию 15 минут)
+	ReservationTTL time.Duration
+
+	// MaxConflictRetries повторы при конкурентном изменении остатка (по умолчанию 10)
+	MaxConflictRetries int
+
+	// SweepInterval период проверки просроченных резервов в Run (по умолчанию 1 минута)
+	SweepInterval time.Duration
+}
+
+// DefaultConfig настройки по умолчанию
+func DefaultConfig() Config {
+	return Config{
+		ReservationTTL:     15 * time.Minute,
+		MaxConflictRetries: 10,
+		SweepInterval:      time.Minute,
+	}
+}
+
+// Service сервис склада
+type Service struct {
+	logger       *zap.Logger
+	products     product.Repository
+	reservations product.ReservationRepository
+	orders       order.Repository
+	config       Config
+	publisher    product.StockEventPublisher
+
+	// mu сериализует переходы статуса резервов (подтверждение, снятие, истечение)
+	mu sync.Mutex
+
+	// orderLocks сериализуют резервирование одного заказа; заказы делят
+	// полосы по ID, чтобы не держать по блокировке на каждый заказ
+	orderLocks [orderLockStripes]sync.Mutex
+}
+
+// orderLockStripes число полос блокировок резервирования заказов
+const orderLockStripes = 64
+
+// NewService создает сервис склада; orders нужен для проверки оплаты при истечении TTL
+func NewService(logger *zap.Logger, products product.Repository, reservations product.ReservationRepository, orders order.Repository, config Config) *Service {
+	defaults := DefaultConfig()
+	if config.ReservationTTL <= 0 {
+		config.ReservationTTL = defaults.ReservationTTL
+	}
+	if config.MaxConflictRetries <= 0 {
+		config.MaxConflictRetries = defaults.MaxConflictRetries
+	}
+	if config.SweepInterval <= 0 {
+		config.SweepInterval = defaults.SweepInterval
+	}
+
+	return &Service{
+		logger:       logger,
+		products:     products,
+		reservations: reservations,
+		orders:       orders,
+		config:       config,
+	}
+}
+
+// SetEventPublisher подключает получателя событий остатков
+func (s *Service) SetEventPublisher(publisher product.StockEventPublisher) {
+	s.publisher = publisher
+}
+
+// CheckAvailability проверяет, можно ли сейчас зарезервировать количество
+func (s *Service) CheckAvailability(ctx context.Context, productID uuid.UUID, quantity int) (bool, error) {
+	p, err := s.products.GetByID(ctx, productID)
+	if errors.Is(err, product.ErrProductNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return p.CanReserve(quantity), nil
+}
+
+// ReserveItems резервирует товары без привязки к заказу
+func (s *Service) ReserveItems(ctx context.Context, items []pipeline.ReservationItem) (*pipeline.Reservation, error) {
+	return s.ReserveOrderItems(ctx, uuid.Nil, items)
+}
+
+// ReserveOrderItems резервирует товары заказа: при истечении TTL резерв
+// сохраняется, только если заказ к этому времени оплачен
+//
+// Повторный вызов для заказа, у которого резерв уже держит остаток,
+// возвращает этот резерв без повторного списания.
+func (s *Service) ReserveOrderItems(ctx context.Context, orderID uuid.UUID, items []pipeline.ReservationItem) (*pipeline.Reservation, error) {
+	lines, err := mergeItems(items)
+	if err != nil {
+		return nil, err
+	}
+
+	if orderID != uuid.Nil {
+		lock := &s.orderLocks[orderID[0]%orderLockStripes]
+		lock.Lock()
+		defer lock.Unlock()
+
+		existing, err := s.reservations.GetByOrderID(ctx, orderID)
+		if err == nil {
+			s.logger.Info("Order already has a reservation",
+				zap.String("reservation_id", existing.ID().String()),
+				zap.String("order_id", orderID.String()))
+			return toPipelineReservation(existing), nil
+		}
+		if !errors.Is(err, product.ErrReservationNotFound) {
+			return nil, fmt.Errorf("failed to look up reservation of order %s: %w", orderID, err)
+		}
+	}
+
+	reserved := make([]product.ReservationLine, 0, len(lines))
+	for _, line := range lines {
+		sku, err := s.changeStock(ctx, line.ProductID, func(p *product.Product) error {
+			return p.Reserve(line.Quantity)
+		})
+		if err != nil {
+			s.restock(ctx, reserved)
+			return nil, fmt.Errorf("reserve %d of product %s: %w", line.Quantity, line.ProductID, err)
+		}
+		line.SKU = sku
+		reserved = append(reserved, line)
+	}
+
+	reservation, err := product.NewReservation(orderID, reserved, s.config.ReservationTTL)
+	if err == nil {
+		err = s.reservations.Save(ctx, reservation)
+	}
+	if err != nil {
+		s.restock(ctx, reserved)
+		return nil, fmt.Errorf("failed to save reservation: %w", err)
+	}
+
+	s.logger.Info("Items reserved",
+		zap.String("reservation_id", reservation.ID().String()),
+		zap.String("order_id", orderID.String()),
+		zap.Int("lines", len(reserved)),
+		zap.Time("expires_at", reservation.ExpiresAt()))
+
+	return toPipelineReservation(reservation), nil
+}
+
+// ReleaseReservation возвращает товары резерва на склад
+//
+// Повторный вызов для уже снятого или истекшего резерва ничего не делает:
+// компенсация пайплайна может выполниться после фонового истечения TTL.
+func (s *Service) ReleaseReservation(ctx context.Context, reservationID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, err := s.reservations.GetByID(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+
+	switch reservation.Status() {
+	case product.ReservationReleased, product.ReservationExpired:
+		return nil
+	}
+
+	if err := reservation.Release(); err != nil {
+		return err
+	}
+	if err := s.reservations.Save(ctx, reservation); err != nil {
+		return err
+	}
+	s.restock(ctx, reservation.Lines())
+
+	s.logger.Info("Reservation released", zap.String("reservation_id", reservationID.String()))
+	return nil
+}
+
+// ConfirmReservation закрепляет резерв за оплаченным заказом: TTL больше не действует
+func (s *Service) ConfirmReservation(ctx context.Context, reservationID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, err := s.reservations.GetByID(ctx, reservationID)
+	if err != nil {
+		return err
+	}
+	if reservation.Status() == product.ReservationConfirmed {
+		return nil
+	}
+	if err := reservation.Confirm(); err != nil {
+		return err
+	}
+	return s.reservations.Save(ctx, reservation)
+}
+
+// ExpireReservations обрабатывает резервы с истекшим TTL и возвращает число
+// снятых: резервы оплаченных заказов подтверждаются, остальные снимаются
+func (s *Service) ExpireReservations(ctx context.Context) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	expired, err := s.reservations.GetExpired(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, reservation := range expired {
+		if s.isOrderPaid(ctx, reservation.OrderID()) && s.isOrderReservation(ctx, reservation) {
+			if err := reservation.Confirm(); err != nil {
+				return released, err
+			}
+			if err := s.reservations.Save(ctx, reservation); err != nil {
+				return released, err
+			}
+			continue
+		}
+
+		if err := reservation.Expire(now); err != nil {
+			return released, err
+		}
+		if err := s.reservations.Save(ctx, reservation); err != nil {
+			return released, err
+		}
+		s.restock(ctx, reservation.Lines())
+		released++
+
+		s.logger.Info("Reservation expired",
+			zap.String("reservation_id", reservation.ID().String()),
+			zap.String("order_id", reservation.OrderID().String()))
+	}
+
+	return released, nil
+}
+
+// Run периодически снимает просроченные резервы до отмены ctx
+func (s *Service) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.config.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.ExpireReservations(ctx); err != nil {
+				s.logger.Error("Failed to expire reservations", zap.Error(err))
+			}
+		}
+	}
+}
+
+// 🔧 ВСПОМОГАТЕЛЬНЫЕ МЕТОДЫ
+
+// changeStock применяет change к свежей копии товара и сохраняет ее с
+// проверкой версии; при конфликте повторяет с перечитанным товаром
+func (s *Service) changeStock(ctx context.Context, productID uuid.UUID, change func(*product.Product) error) (string, error) {
+	for attempt := 0; attempt < s.config.MaxConflictRetries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		p, err := s.products.GetByID(ctx, productID)
+		if err != nil {
+			return "", err
+		}
+
+		before := p.Snapshot()
+		if err := change(p); err != nil {
+			return p.SKU(), err
+		}
+
+		err = s.products.Save(ctx, p)
+		if errors.Is(err, product.ErrStockVersionConflict) {
+			continue
+		}
+		if err != nil {
+			return p.SKU(), err
+		}
+
+		s.publish(ctx, product.StockEventsBetween(before, p))
+		return p.SKU(), nil
+	}
+
+	return "", fmt.Errorf("product %s: %w after %d attempts",
+		productID, product.ErrStockVersionConflict, s.config.MaxConflictRetries)
+}
+
+// restock возвращает позиции на склад; ошибки только логируются, чтобы
+// вернуть как можно больше позиций
+func (s *Service) restock(ctx context.Context, lines []product.ReservationLine) {
+	for _, line := range lines {
+		quantity := line.Quantity
+		_, err := s.changeStock(ctx, line.ProductID, func(p *product.Product) error {
+			return p.RestoreStock(quantity)
+		})
+		if err != nil {
+			s.logger.Error("Failed to restore stock",
+				zap.String("product_id", line.ProductID.String()),
+				zap.Int("quantity", quantity),
+				zap.Error(err))
+		}
+	}
+}
+
+// isOrderReservation проверяет, что резерв - тот, который заказ держит
+// (GetByOrderID): лишние резервы того же заказа снимаются, а не подтверждаются
+func (s *Service) isOrderReservation(ctx context.Context, reservation *product.Reservation) bool {
+	current, err := s.reservations.GetByOrderID(ctx, reservation.OrderID())
+	if err != nil {
+		s.logger.Warn("Failed to load reservation of order",
+			zap.String("order_id", reservation.OrderID().String()),
+			zap.Error(err))
+		return false
+	}
+	return current.ID() == reservation.ID()
+}
+
+// isOrderPaid проверяет, дошел ли заказ до оплаты и не был ли отменен
+func (s *Service) isOrderPaid(ctx context.Context, orderID uuid.UUID) bool {
+	if orderID == uuid.Nil || s.orders == nil {
+		return false
+	}
+
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		s.logger.Warn("Failed to load order of reservation",
+			zap.String("order_id", orderID.String()),
+			zap.Error(err))
+		return false
+	}
+
+	status := ord.Status()
+	return status >= order.StatusPaid && status < order.StatusCancelled
+}
+
+func (s *Service) publish(ctx context.Context, events []product.StockEvent) {
+	if s.publisher == nil {
+		return
+	}
+	for _, event := range events {
+		if err := s.publisher.PublishStockEvent(ctx, event); err != nil {
+			s.logger.Warn("Failed to publish stock event",
+				zap.String("type", string(event.Type)),
+				zap.String("sku", event.SKU),
+				zap.Error(err))
+		}
+	}
+}
+
+// mergeItems складывает повторяющиеся товары и упорядочивает их по ID
+func mergeItems(items []pipeline.ReservationItem) ([]product.ReservationLine, error) {
+	if len(items) == 0 {
+		return nil, errors.New("no items to reserve")
+	}
+
+	quantities := make(map[uuid.UUID]int, len(items))
+	for _, item := range items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity of product %s must be positive", item.ProductID)
+		}
+		quantities[item.ProductID] += item.Quantity
+	}
+
+	lines := make([]product.ReservationLine, 0, len(quantities))
+	for productID, quantity := range quantities {
+		lines = append(lines, product.ReservationLine{ProductID: productID, Quantity: quantity})
+	}
+	sort.Slice(lines, func(i, j int) bool {
+		return lines[i].ProductID.String() < lines[j].ProductID.String()
+	})
+	return lines, nil
+}
+
+func toPipelineReservation(reservation *product.Reservation) *pipeline.Reservation {
+	lines := reservation.Lines()
+	items := make([]pipeline.ReservationItem, 0, len(lines))
+	for _, line := range lines {
+		items = append(items, pipeline.ReservationItem{ProductID: line.ProductID, Quantity: line.Quantity})
+	}
+	return &pipeline.Reservation{
+		ID:        reservation.ID(),
+		Items:     items,
+		ExpiresAt: reservation.ExpiresAt(),
+	}
+}
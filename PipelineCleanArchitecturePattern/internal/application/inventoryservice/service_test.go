@@ -0,0 +1,282 @@
+package inventoryservice
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/application/pipeline"
+	"pipeline-clean-architecture/internal/domain/product"
+	"pipeline-clean-architecture/internal/infrastructure/inventorystore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore/sqlstoretest"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// recordingPublisher запоминает события остатков
+type recordingPublisher struct {
+	mu     sync.Mutex
+	events []product.StockEvent
+}
+
+func (p *recordingPublisher) PublishStockEvent(ctx context.Context, event product.StockEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) count(eventType product.StockEventType) int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, event := range p.events {
+		if event.Type == eventType {
+			n++
+		}
+	}
+	return n
+}
+
+// fixture сервис склада поверх in-memory товаров и SQLite заказов
+type fixture struct {
+	service      *Service
+	products     *inventorystore.ProductRepository
+	reservations *inventorystore.ReservationRepository
+	orders       *sqlstore.OrderRepository
+	publisher    *recordingPublisher
+}
+
+func newFixture(t *testing.T, config Config) *fixture {
+	t.Helper()
+
+	orders := sqlstoretest.NewOrderRepository(t)
+	products := inventorystore.NewProductRepository()
+	reservations := inventorystore.NewReservationRepository()
+	service := NewService(zap.NewNop(), products, reservations, orders, config)
+	publisher := &recordingPublisher{}
+	service.SetEventPublisher(publisher)
+
+	return &fixture{service: service, products: products, reservations: reservations, orders: orders, publisher: publisher}
+}
+
+func (f *fixture) addProduct(t *testing.T, sku string, stock, minStock int) uuid.UUID {
+	t.Helper()
+
+	p, err := product.NewProduct("Товар "+sku, "", sku, product.Category{}, product.NewMoney(10000, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UpdateStock(stock); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.SetMinStockLevel(minStock); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.products.Save(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	return p.ID()
+}
+
+func (f *fixture) stock(t *testing.T, productID uuid.UUID) int {
+	t.Helper()
+
+	p, err := f.products.GetByID(context.Background(), productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.StockLevel()
+}
+
+// createOrder сохраняет заказ; paid - довести его до оплаты
+func (f *fixture) createOrder(t *testing.T, paid bool) uuid.UUID {
+	t.Helper()
+
+	ord := sqlstoretest.NewOrder(t, uuid.New(), sqlstoretest.NewItem(t, uuid.New(), 1, 10000))
+	if paid {
+		sqlstoretest.MarkAsPaid(t, ord)
+	}
+	if err := f.orders.Save(context.Background(), ord); err != nil {
+		t.Fatal(err)
+	}
+	return ord.ID()
+}
+
+func TestConcurrentReservationsDoNotOversell(t *testing.T) {
+	f := newFixture(t, Config{})
+	productID := f.addProduct(t, "SKU-LAST", 5, 1)
+
+	const buyers = 50
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		reserved int
+	)
+	for i := 0; i < buyers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := f.service.ReserveItems(context.Background(), []pipeline.ReservationItem{{ProductID: productID, Quantity: 1}})
+			switch {
+			case err == nil:
+				mu.Lock()
+				reserved++
+				mu.Unlock()
+			case !errors.Is(err, product.ErrInsufficientStock):
+				t.Errorf("ReserveItems() error = %v, want nil or ErrInsufficientStock", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if reserved != 5 || f.stock(t, productID) != 0 {
+		t.Errorf("reserved %d units, stock left %d; want 5 and 0", reserved, f.stock(t, productID))
+	}
+	if n := f.publisher.count(product.StockEventOutOfStock); n != 1 {
+		t.Errorf("out of stock events = %d, want 1", n)
+	}
+}
+
+func TestFailedLineReturnsReservedStock(t *testing.T) {
+	f := newFixture(t, Config{})
+	plenty := f.addProduct(t, "SKU-PLENTY", 10, 1)
+	scarce := f.addProduct(t, "SKU-SCARCE", 1, 0)
+
+	_, err := f.service.ReserveItems(context.Background(), []pipeline.ReservationItem{
+		{ProductID: plenty, Quantity: 3},
+		{ProductID: scarce, Quantity: 2},
+	})
+	if !errors.Is(err, product.ErrInsufficientStock) {
+		t.Fatalf("ReserveItems() error = %v, want ErrInsufficientStock", err)
+	}
+	if f.stock(t, plenty) != 10 || f.stock(t, scarce) != 1 {
+		t.Errorf("stock after failed reservation = %d/%d, want 10/1", f.stock(t, plenty), f.stock(t, scarce))
+	}
+}
+
+func TestExpiredReservationIsReleasedUnlessOrderIsPaid(t *testing.T) {
+	f := newFixture(t, Config{ReservationTTL: 20 * time.Millisecond})
+	ctx := context.Background()
+	productID := f.addProduct(t, "SKU-TTL", 10, 0)
+	items := []pipeline.ReservationItem{{ProductID: productID, Quantity: 4}}
+
+	abandoned, err := f.service.ReserveOrderItems(ctx, f.createOrder(t, false), items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.service.ReserveOrderItems(ctx, f.createOrder(t, true), items); err != nil {
+		t.Fatal(err)
+	}
+	if f.stock(t, productID) != 2 {
+		t.Fatalf("stock after reservations = %d, want 2", f.stock(t, productID))
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	released, err := f.service.ExpireReservations(ctx)
+	if err != nil {
+		t.Fatalf("ExpireReservations() error = %v", err)
+	}
+	if released != 1 || f.stock(t, productID) != 6 {
+		t.Errorf("released %d reservations, stock %d; want 1 and 6", released, f.stock(t, productID))
+	}
+
+	// Компенсация пайплайна после истечения TTL не возвращает товар дважды
+	if err := f.service.ReleaseReservation(ctx, abandoned.ID); err != nil {
+		t.Fatalf("ReleaseReservation() after expiry error = %v", err)
+	}
+	if released, _ := f.service.ExpireReservations(ctx); released != 0 || f.stock(t, productID) != 6 {
+		t.Errorf("second sweep released %d, stock %d; want 0 and 6", released, f.stock(t, productID))
+	}
+}
+
+func TestRepeatedOrderReservationReturnsExistingOne(t *testing.T) {
+	f := newFixture(t, Config{ReservationTTL: 20 * time.Millisecond})
+	ctx := context.Background()
+	productID := f.addProduct(t, "SKU-RETRY", 10, 0)
+	orderID := f.createOrder(t, true)
+	items := []pipeline.ReservationItem{{ProductID: productID, Quantity: 4}}
+
+	// Повтор шага check_inventory после сбоя не списывает товар второй раз
+	first, err := f.service.ReserveOrderItems(ctx, orderID, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := f.service.ReserveOrderItems(ctx, orderID, items)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ID != first.ID || f.stock(t, productID) != 6 {
+		t.Fatalf("second reservation %s (first %s), stock %d; want the same reservation and 6", second.ID, first.ID, f.stock(t, productID))
+	}
+
+	// Лишний резерв того же заказа, созданный до исправления, при истечении
+	// TTL снимается: подтверждается только резерв, который держит заказ
+	p, err := f.products.GetByID(ctx, productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Reserve(4); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.products.Save(ctx, p); err != nil {
+		t.Fatal(err)
+	}
+	duplicate, err := product.NewReservation(orderID, []product.ReservationLine{{ProductID: productID, SKU: p.SKU(), Quantity: 4}}, 20*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.reservations.Save(ctx, duplicate); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	released, err := f.service.ExpireReservations(ctx)
+	if err != nil {
+		t.Fatalf("ExpireReservations() error = %v", err)
+	}
+	if released != 1 || f.stock(t, productID) != 6 {
+		t.Errorf("released %d reservations, stock %d; want 1 and 6", released, f.stock(t, productID))
+	}
+	if kept, _ := f.reservations.GetByID(ctx, first.ID); kept.Status() != product.ReservationConfirmed {
+		t.Errorf("order reservation status = %s, want %s", kept.Status(), product.ReservationConfirmed)
+	}
+}
+
+func TestLowStockEventIsPublishedOnTransition(t *testing.T) {
+	f := newFixture(t, Config{})
+	ctx := context.Background()
+	productID := f.addProduct(t, "SKU-LOW", 10, 3)
+
+	var reservations []*pipeline.Reservation
+	for _, quantity := range []int{5, 2, 1} {
+		reservation, err := f.service.ReserveItems(ctx, []pipeline.ReservationItem{{ProductID: productID, Quantity: quantity}})
+		if err != nil {
+			t.Fatalf("reserve %d: %v", quantity, err)
+		}
+		reservations = append(reservations, reservation)
+	}
+	if n := f.publisher.count(product.StockEventLowStock); n != 1 {
+		t.Fatalf("low stock events after 10→5→3→2 = %d, want 1", n)
+	}
+
+	// Остаток снова выше порога, следующее падение - новое событие
+	for _, reservation := range reservations[:2] {
+		if err := f.service.ReleaseReservation(ctx, reservation.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := f.service.ReserveItems(ctx, []pipeline.ReservationItem{{ProductID: productID, Quantity: 6}}); err != nil {
+		t.Fatal(err)
+	}
+	if n := f.publisher.count(product.StockEventLowStock); n != 2 {
+		t.Errorf("low stock events = %d, want 2", n)
+	}
+	if f.publisher.events[0].SKU != "SKU-LOW" || f.publisher.events[0].StockLevel != 3 {
+		t.Errorf("first event = %+v, want SKU-LOW at stock 3", f.publisher.events[0])
+	}
+}
@@ -0,0 +1,301 @@
+package orderservice
+
+import (
+	"context"
+	"fmt"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/payment"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+	pipelineEngine "pipeline-clean-architecture/pkg/pipeline"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 🔄 ВЫПОЛНЕНИЕ ПАЙПЛАЙНА ЗАКАЗА
+
+// ExecuteOrderPipeline выполняет полный пайплайн обработки заказа
+//
+// Неуспех шага не является ошибкой вызова: он возвращается в
+// PipelineResult.Errors. Ошибка означает, что пайплайн не был запущен.
+func (s *Service) ExecuteOrderPipeline(ctx context.Context, orderID uuid.UUID) (*usecase.PipelineResult, error) {
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	definition, err := s.definition()
+	if err != nil {
+		return nil, err
+	}
+	if method := ord.PaymentMethod(); method != "" {
+		definition.Metadata = withMetadata(definition.Metadata, map[string]string{
+			"payment_method": method,
+		})
+	}
+
+	if err := s.progress.begin(orderID, len(definitionSteps(definition))); err != nil {
+		return nil, err
+	}
+
+	execCtx, err := s.engine.Execute(withTrackedOrder(ctx, orderID), definition, map[string]interface{}{
+		"order_id": orderID.String(),
+	})
+	s.progress.finish(orderID, err)
+
+	if execCtx == nil {
+		return nil, fmt.Errorf("pipeline %s was not started: %w", definition.Name, err)
+	}
+
+	// Итоговый статус заказа после всех шагов (и компенсаций)
+	finalStatus := ord.Status()
+	if final, getErr := s.orders.GetByID(ctx, orderID); getErr == nil {
+		finalStatus = final.Status()
+	}
+
+	result := &usecase.PipelineResult{
+		OrderID:     orderID,
+		Success:     err == nil,
+		Steps:       make([]usecase.StepResult, 0, len(execCtx.Results)),
+		Duration:    execCtx.CompletedAt.Sub(execCtx.StartedAt),
+		CompletedAt: *execCtx.CompletedAt,
+		FinalStatus: finalStatus,
+	}
+
+	for _, step := range resultOrder(execCtx) {
+		stepResult := execCtx.Results[step]
+		if stepResult == nil {
+			continue
+		}
+
+		converted := s.stepResult(step, stepResult)
+		result.Steps = append(result.Steps, converted)
+		if converted.Error != nil {
+			result.Errors = append(result.Errors, *converted.Error)
+		}
+	}
+
+	// Ошибка вне шагов (таймаут пайплайна, невалидное определение)
+	if err != nil && len(result.Errors) == 0 {
+		result.Errors = append(result.Errors, usecase.PipelineError{
+			Code:    "pipeline_failed",
+			Message: err.Error(),
+		})
+	}
+
+	s.logger.Info("Order pipeline finished",
+		zap.String("order_id", orderID.String()),
+		zap.String("execution_id", execCtx.ID),
+		zap.String("status", string(execCtx.Status)),
+		zap.Duration("duration", result.Duration))
+
+	return result, nil
+}
+
+// ExecuteStep выполняет отдельный шаг пайплайна для заказа
+func (s *Service) ExecuteStep(ctx context.Context, step usecase.PipelineStep, orderID uuid.UUID) (*usecase.StepResult, error) {
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.runStep(ctx, step, ord, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := s.stepResult(s.stepNames[step], result)
+	return &converted, nil
+}
+
+// GetPipelineStatus возвращает статус последнего пайплайна заказа
+func (s *Service) GetPipelineStatus(ctx context.Context, orderID uuid.UUID) (*usecase.PipelineStatus, error) {
+	status, ok := s.progress.status(orderID)
+	if !ok {
+		return nil, fmt.Errorf("order %s: %w", orderID, usecase.ErrPipelineNotFound)
+	}
+	return &status, nil
+}
+
+// WatchPipelineStatus подписывает на изменения статуса пайплайна заказа
+//
+// Первым приходит текущий статус (если пайплайн уже запускался). Если
+// пайплайн еще не запущен, канал ждет его запуска. При медленном чтении
+// промежуточные статусы могут быть пропущены, последний - никогда.
+func (s *Service) WatchPipelineStatus(ctx context.Context, orderID uuid.UUID) (<-chan usecase.PipelineStatus, error) {
+	if _, err := s.orders.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	sub := s.progress.subscribe(orderID)
+	go func() {
+		select {
+		case <-ctx.Done():
+			s.progress.unsubscribe(orderID, sub)
+		case <-sub.done:
+		}
+	}()
+
+	return sub.updates, nil
+}
+
+// 🔧 ВСПОМОГАТЕЛЬНЫЕ МЕТОДЫ
+
+// definition возвращает актуальное определение пайплайна заказа
+func (s *Service) definition() (pipelineEngine.PipelineDefinition, error) {
+	definition, ok := s.definitions.Get(s.pipelineName)
+	if !ok {
+		return pipelineEngine.PipelineDefinition{}, fmt.Errorf("pipeline definition %s not found", s.pipelineName)
+	}
+	return definition, nil
+}
+
+// runStep выполняет один шаг движка пайплайном из одного шага
+//
+// Неуспех шага возвращается в StepResult.Error, ошибка - только если шаг
+// не выполнялся или упал до получения данных заказа.
+func (s *Service) runStep(ctx context.Context, step usecase.PipelineStep, ord *order.Order, metadata map[string]string) (*pipelineEngine.StepResult, error) {
+	name, ok := s.stepNames[step]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", usecase.ErrStepNotSupported, step)
+	}
+	if _, running := s.progress.running(ord.ID()); running {
+		return nil, fmt.Errorf("order %s: %w", ord.ID(), usecase.ErrPipelineRunning)
+	}
+
+	definition, err := s.definition()
+	if err != nil {
+		return nil, err
+	}
+
+	single := pipelineEngine.PipelineDefinition{
+		Name:         definition.Name + "." + name,
+		Description:  fmt.Sprintf("Шаг %s пайплайна %s", name, definition.Name),
+		Steps:        []string{name},
+		Timeout:      definition.Timeout,
+		StepTimeouts: definition.StepTimeouts,
+		Retry:        definition.Retry,
+		Metadata:     withMetadata(definition.Metadata, metadata),
+	}
+
+	execCtx, err := s.engine.Execute(ctx, single, stepInput(ord))
+	if execCtx == nil {
+		return nil, fmt.Errorf("step %s was not started: %w", name, err)
+	}
+
+	result, ok := execCtx.Results[name]
+	if !ok || result == nil {
+		if err == nil {
+			err = fmt.Errorf("step %s returned no result", name)
+		}
+		return nil, err
+	}
+
+	// Шаг упал, не дойдя до бизнес-результата (например, заказ не загрузился)
+	if result.Output == nil && result.Error != nil {
+		return nil, result.Error
+	}
+
+	return result, nil
+}
+
+// stepInput восстанавливает выходы предыдущих шагов по статусу заказа
+func stepInput(ord *order.Order) map[string]interface{} {
+	status := ord.Status()
+	// Отмененные и возвращенные заказы не проходят проверки шагов
+	active := status < order.StatusCancelled
+
+	paymentStatus := payment.StatusPending
+	if active && status >= order.StatusPaid {
+		paymentStatus = payment.StatusSucceeded
+	}
+
+	return map[string]interface{}{
+		"order_id":       ord.ID().String(),
+		"is_valid":       active && status >= order.StatusValidated,
+		"payment_status": paymentStatus.String(),
+		"all_available":  active && status >= order.StatusInventoryChecked,
+	}
+}
+
+// stepResult переводит результат шага движка в результат use case слоя
+func (s *Service) stepResult(engineStep string, result *pipelineEngine.StepResult) usecase.StepResult {
+	step := s.progress.useCaseStep(engineStep)
+
+	converted := usecase.StepResult{
+		Step:        step,
+		Success:     result.Success && result.Error == nil,
+		Duration:    result.Duration,
+		StartedAt:   result.StartedAt,
+		CompletedAt: result.CompletedAt,
+	}
+	if len(result.Output) > 0 {
+		converted.Data = result.Output
+	}
+	if result.Error != nil {
+		converted.Error = &usecase.PipelineError{
+			Step:    step,
+			Code:    "step_failed",
+			Message: result.Error.Error(),
+			Retry:   result.Retryable,
+		}
+	}
+	return converted
+}
+
+// definitionSteps шаги определения в порядке объявления, включая группы Parallel
+func definitionSteps(definition pipelineEngine.PipelineDefinition) []string {
+	steps := make([]string, 0, len(definition.Steps))
+	seen := make(map[string]bool)
+
+	add := func(step string) {
+		if !seen[step] {
+			seen[step] = true
+			steps = append(steps, step)
+		}
+	}
+
+	for _, step := range definition.Steps {
+		add(step)
+	}
+	for _, group := range definition.Parallel {
+		for _, step := range group {
+			add(step)
+		}
+	}
+	return steps
+}
+
+// resultOrder шаги с результатами: сначала завершенные в порядке выполнения,
+// затем остальные (упавшие, прерванные) в порядке определения
+func resultOrder(execCtx *pipelineEngine.ExecutionContext) []string {
+	steps := make([]string, 0, len(execCtx.Results))
+	seen := make(map[string]bool)
+
+	for _, step := range execCtx.CompletedSteps {
+		if _, ok := execCtx.Results[step]; ok && !seen[step] {
+			seen[step] = true
+			steps = append(steps, step)
+		}
+	}
+	for _, step := range definitionSteps(execCtx.Pipeline) {
+		if _, ok := execCtx.Results[step]; ok && !seen[step] {
+			seen[step] = true
+			steps = append(steps, step)
+		}
+	}
+	return steps
+}
+
+// withMetadata возвращает копию метаданных с дополнительными ключами
+func withMetadata(base, extra map[string]string) map[string]string {
+	merged := make(map[string]string, len(base)+len(extra))
+	for k, v := range base {
+		merged[k] = v
+	}
+	for k, v := range extra {
+		merged[k] = v
+	}
+	return merged
+}
@@ -0,0 +1,353 @@
+package orderservice
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+	pipelineEngine "pipeline-clean-architecture/pkg/pipeline"
+
+	"github.com/google/uuid"
+)
+
+// 📡 ПРОГРЕСС ПАЙПЛАЙНА ЗАКАЗА
+//
+// ============================================================================
+// КАК ЭТО РАБОТАЕТ:
+// ============================================================================
+//
+// 1. ExecuteOrderPipeline вызывает begin и кладет ID заказа в контекст
+//    выполнения (withTrackedOrder) - движок передает этот контекст в
+//    middleware, в том числе для пайплайнов из очереди
+// 2. progressTracker как pipeline.Middleware отмечает текущий шаг в Before
+//    и завершенный (или упавший) шаг в After
+// 3. finish фиксирует итог и закрывает каналы подписчиков
+//
+// Каждое изменение рассылается подписчикам полным снимком статуса, поэтому
+// медленному подписчику достаточно последнего: при заполненном буфере
+// самое старое обновление вытесняется.
+//
+// Пайплайны, запущенные мимо ExecuteOrderPipeline, не отслеживаются.
+//
+// ============================================================================
+
+// statusBuffer емкость канала обновлений одного подписчика
+const statusBuffer = 16
+
+// trackedOrderKey ключ контекста с ID отслеживаемого заказа
+type trackedOrderKey struct{}
+
+// withTrackedOrder помечает выполнение пайплайна как отслеживаемое
+func withTrackedOrder(ctx context.Context, orderID uuid.UUID) context.Context {
+	return context.WithValue(ctx, trackedOrderKey{}, orderID)
+}
+
+// trackedOrder возвращает ID заказа отслеживаемого выполнения
+func trackedOrder(ctx context.Context) (uuid.UUID, bool) {
+	orderID, ok := ctx.Value(trackedOrderKey{}).(uuid.UUID)
+	return orderID, ok
+}
+
+// pipelineRun последнее выполнение пайплайна заказа
+type pipelineRun struct {
+	status usecase.PipelineStatus
+	total  int
+}
+
+// snapshot копия статуса, которую можно отдать наружу
+func (r *pipelineRun) snapshot() usecase.PipelineStatus {
+	status := r.status
+	status.CompletedSteps = append([]usecase.PipelineStep(nil), r.status.CompletedSteps...)
+	if r.status.EstimatedEnd != nil {
+		end := *r.status.EstimatedEnd
+		status.EstimatedEnd = &end
+	}
+	return status
+}
+
+// subscriber подписчик на статус пайплайна
+type subscriber struct {
+	updates chan usecase.PipelineStatus
+	done    chan struct{}
+}
+
+// send отправляет статус, не блокируясь на медленном подписчике
+func (s *subscriber) send(status usecase.PipelineStatus) {
+	select {
+	case s.updates <- status:
+		return
+	default:
+	}
+
+	// Буфер заполнен: вытесняем самое старое обновление
+	select {
+	case <-s.updates:
+	default:
+	}
+	select {
+	case s.updates <- status:
+	default:
+	}
+}
+
+// close закрывает канал обновлений
+func (s *subscriber) close() {
+	close(s.updates)
+	close(s.done)
+}
+
+// progressTracker отслеживает шаги пайплайнов заказов
+type progressTracker struct {
+	mu          sync.Mutex
+	stepNames   map[string]usecase.PipelineStep
+	retention   time.Duration
+	runs        map[uuid.UUID]*pipelineRun
+	subscribers map[uuid.UUID]map[*subscriber]struct{}
+}
+
+// Проверка реализации интерфейса на этапе компиляции
+var _ pipelineEngine.Middleware = (*progressTracker)(nil)
+
+// newProgressTracker создает трекер с обратным отображением имен шагов
+func newProgressTracker(stepNames map[usecase.PipelineStep]string, retention time.Duration) *progressTracker {
+	engineSteps := make(map[string]usecase.PipelineStep, len(stepNames))
+	for step, name := range stepNames {
+		engineSteps[name] = step
+	}
+
+	return &progressTracker{
+		stepNames:   engineSteps,
+		retention:   retention,
+		runs:        make(map[uuid.UUID]*pipelineRun),
+		subscribers: make(map[uuid.UUID]map[*subscriber]struct{}),
+	}
+}
+
+// useCaseStep переводит имя шага движка в шаг use case слоя
+//
+// Шаги без отображения передаются под своим именем.
+func (t *progressTracker) useCaseStep(engineStep string) usecase.PipelineStep {
+	if step, ok := t.stepNames[engineStep]; ok {
+		return step
+	}
+	return usecase.PipelineStep(engineStep)
+}
+
+// 🔄 ЖИЗНЕННЫЙ ЦИКЛ ВЫПОЛНЕНИЯ
+
+// begin начинает отслеживание нового пайплайна заказа из total шагов
+func (t *progressTracker) begin(orderID uuid.UUID, total int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+	t.evictLocked(now)
+
+	if run, ok := t.runs[orderID]; ok && !run.status.State.IsFinal() {
+		return fmt.Errorf("order %s: %w", orderID, usecase.ErrPipelineRunning)
+	}
+
+	run := &pipelineRun{
+		total: total,
+		status: usecase.PipelineStatus{
+			OrderID:        orderID,
+			CompletedSteps: make([]usecase.PipelineStep, 0, total),
+			StartedAt:      now,
+			UpdatedAt:      now,
+			State:          usecase.PipelineStateRunning,
+		},
+	}
+	t.runs[orderID] = run
+	t.publishLocked(orderID, run)
+	return nil
+}
+
+// finish фиксирует итог пайплайна и закрывает подписки
+func (t *progressTracker) finish(orderID uuid.UUID, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	run, ok := t.runs[orderID]
+	if !ok || run.status.State.IsFinal() {
+		return
+	}
+
+	run.status.CurrentStep = ""
+	run.status.EstimatedEnd = nil
+	run.status.UpdatedAt = time.Now()
+	if err == nil {
+		run.status.State = usecase.PipelineStateCompleted
+		run.status.Progress = 1
+	} else {
+		run.status.State = usecase.PipelineStateFailed
+		run.status.Error = err.Error()
+	}
+
+	t.publishLocked(orderID, run)
+}
+
+// update изменяет статус выполняющегося пайплайна и рассылает его
+func (t *progressTracker) update(orderID uuid.UUID, change func(run *pipelineRun)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	run, ok := t.runs[orderID]
+	if !ok || run.status.State.IsFinal() {
+		return
+	}
+
+	change(run)
+
+	now := time.Now()
+	run.status.UpdatedAt = now
+	run.status.EstimatedEnd = nil
+	if progress := run.status.Progress; progress > 0 && progress < 1 {
+		// Линейная оценка по доле завершенных шагов
+		elapsed := now.Sub(run.status.StartedAt)
+		end := run.status.StartedAt.Add(time.Duration(float64(elapsed) / progress))
+		run.status.EstimatedEnd = &end
+	}
+
+	t.publishLocked(orderID, run)
+}
+
+// 🔌 MIDDLEWARE
+
+// Before отмечает начало шага
+func (t *progressTracker) Before(ctx context.Context, step string, data *pipelineEngine.StepData) error {
+	if orderID, ok := trackedOrder(ctx); ok {
+		t.update(orderID, func(run *pipelineRun) {
+			run.status.CurrentStep = t.useCaseStep(step)
+		})
+	}
+	return nil
+}
+
+// After отмечает завершение шага (после всех повторов)
+func (t *progressTracker) After(ctx context.Context, step string, result *pipelineEngine.StepResult) error {
+	orderID, ok := trackedOrder(ctx)
+	if !ok {
+		return nil
+	}
+
+	t.update(orderID, func(run *pipelineRun) {
+		finished := t.useCaseStep(step)
+		if run.status.CurrentStep == finished {
+			run.status.CurrentStep = ""
+		}
+
+		if result == nil || result.Error != nil {
+			run.status.FailedStep = finished
+			return
+		}
+
+		run.status.CompletedSteps = append(run.status.CompletedSteps, finished)
+		if run.total > 0 {
+			run.status.Progress = float64(len(run.status.CompletedSteps)) / float64(run.total)
+		}
+		if run.status.Progress > 1 {
+			run.status.Progress = 1
+		}
+	})
+	return nil
+}
+
+// OnError ошибки шага уже учтены в After
+func (t *progressTracker) OnError(ctx context.Context, step string, err error) error {
+	return nil
+}
+
+// 📬 ПОДПИСКИ И ЗАПРОСЫ
+
+// subscribe подписывает на статус пайплайна заказа
+//
+// Если статус уже известен, он приходит первым; для завершенного
+// пайплайна канал сразу закрывается.
+func (t *progressTracker) subscribe(orderID uuid.UUID) *subscriber {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	sub := &subscriber{
+		updates: make(chan usecase.PipelineStatus, statusBuffer),
+		done:    make(chan struct{}),
+	}
+
+	if run, ok := t.runs[orderID]; ok {
+		sub.send(run.snapshot())
+		if run.status.State.IsFinal() {
+			sub.close()
+			return sub
+		}
+	}
+
+	if t.subscribers[orderID] == nil {
+		t.subscribers[orderID] = make(map[*subscriber]struct{})
+	}
+	t.subscribers[orderID][sub] = struct{}{}
+	return sub
+}
+
+// unsubscribe отменяет подписку и закрывает ее канал
+func (t *progressTracker) unsubscribe(orderID uuid.UUID, sub *subscriber) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.subscribers[orderID][sub]; !ok {
+		return
+	}
+
+	delete(t.subscribers[orderID], sub)
+	if len(t.subscribers[orderID]) == 0 {
+		delete(t.subscribers, orderID)
+	}
+	sub.close()
+}
+
+// status возвращает статус последнего пайплайна заказа
+func (t *progressTracker) status(orderID uuid.UUID) (usecase.PipelineStatus, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	run, ok := t.runs[orderID]
+	if !ok {
+		return usecase.PipelineStatus{}, false
+	}
+	return run.snapshot(), true
+}
+
+// running возвращает статус, если пайплайн заказа сейчас выполняется
+func (t *progressTracker) running(orderID uuid.UUID) (usecase.PipelineStatus, bool) {
+	status, ok := t.status(orderID)
+	return status, ok && !status.State.IsFinal()
+}
+
+// publishLocked рассылает статус; после итогового статуса подписки закрываются
+func (t *progressTracker) publishLocked(orderID uuid.UUID, run *pipelineRun) {
+	subscribers := t.subscribers[orderID]
+	if len(subscribers) == 0 {
+		return
+	}
+
+	snapshot := run.snapshot()
+	for sub := range subscribers {
+		sub.send(snapshot)
+	}
+
+	if snapshot.State.IsFinal() {
+		for sub := range subscribers {
+			sub.close()
+		}
+		delete(t.subscribers, orderID)
+	}
+}
+
+// evictLocked удаляет статусы пайплайнов, завершенных раньше retention
+func (t *progressTracker) evictLocked(now time.Time) {
+	for orderID, run := range t.runs {
+		if run.status.State.IsFinal() && now.Sub(run.status.UpdatedAt) > t.retention {
+			delete(t.runs, orderID)
+		}
+	}
+}
@@ -0,0 +1,519 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"pipeline-clean-architecture/internal/application/pipeline"
+	"pipeline-clean-architecture/internal/domain/money"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/payment"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+	pipelineEngine "pipeline-clean-architecture/pkg/pipeline"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 💼 РЕАЛИЗАЦИЯ USE CASE СЛОЯ
+//
+// ============================================================================
+// ЧТО ДЕЛАЕТ Service:
+// ============================================================================
+//
+// Service реализует usecase.OrderProcessor и usecase.PipelineExecutor
+// поверх pipeline.Engine. Бизнес-операции, у которых есть шаг пайплайна
+// (валидация, оплата), выполняются через движок пайплайном из одного шага,
+// поэтому логика шага, повторы, метрики и трассировка остаются в одном месте.
+//
+// Данные предыдущих шагов (is_valid, payment_status, ...) для отдельного шага
+// восстанавливаются из статуса заказа (см. stepInput).
+//
+// ============================================================================
+// ПРОГРЕСС ПАЙПЛАЙНА:
+// ============================================================================
+//
+// NewService регистрирует в движке middleware progressTracker (progress.go).
+// Он обновляет usecase.PipelineStatus на каждом шаге пайплайна, запущенного
+// через ExecuteOrderPipeline, и рассылает его подписчикам WatchPipelineStatus.
+//
+// ============================================================================
+
+// DeliveryService интерфейс службы доставки
+type DeliveryService interface {
+	ArrangeDelivery(ctx context.Context, ord *order.Order) (*usecase.ArrangeDeliveryResponse, error)
+}
+
+// DefinitionSource источник определений пайплайнов
+//
+// Реализуется pipelineEngine.DefinitionLoader, поэтому сервис видит
+// определения после горячей перезагрузки.
+type DefinitionSource interface {
+	Get(name string) (pipelineEngine.PipelineDefinition, bool)
+}
+
+// Dependencies зависимости сервиса
+type Dependencies struct {
+	Engine      *pipelineEngine.Engine
+	Definitions DefinitionSource
+	Orders      order.Repository
+
+	Products      pipeline.ProductService
+	Inventory     pipeline.InventoryService
+	Notifications pipeline.NotificationService
+
+	// Delivery опционален: без него ArrangeDelivery возвращает ErrNotConfigured
+	Delivery DeliveryService
+
+	// Rates опционален: с ним цены каталога в других валютах пересчитываются
+	// в Config.Currency, без него все цены заказа должны быть в одной валюте
+	Rates money.ExchangeRateProvider
+}
+
+// Config настройки сервиса
+type Config struct {
+	// PipelineName имя пайплайна обработки заказа в Definitions
+	PipelineName string
+
+	// StepNames шаги движка для шагов use case слоя (по умолчанию DefaultStepNames)
+	StepNames map[usecase.PipelineStep]string
+
+	// StatusRetention сколько хранить статус завершенного пайплайна (по умолчанию час)
+	StatusRetention time.Duration
+
+	// Currency валюта заказов при подключенных Dependencies.Rates (по умолчанию RUB)
+	Currency string
+}
+
+// DefaultStepNames шаги движка, зарегистрированные в cmd/pipeline
+var DefaultStepNames = map[usecase.PipelineStep]string{
+	usecase.StepValidation:        "validate_order",
+	usecase.StepPaymentProcessing: "process_payment",
+	usecase.StepInventoryCheck:    "check_inventory",
+	usecase.StepNotifications:     "send_notifications",
+}
+
+// Service реализация use case слоя обработки заказов
+type Service struct {
+	logger       *zap.Logger
+	engine       *pipelineEngine.Engine
+	definitions  DefinitionSource
+	pipelineName string
+	stepNames    map[usecase.PipelineStep]string
+
+	orders        order.Repository
+	products      pipeline.ProductService
+	inventory     pipeline.InventoryService
+	notifications pipeline.NotificationService
+	delivery      DeliveryService
+	rates         money.ExchangeRateProvider
+	currency      string
+
+	progress *progressTracker
+}
+
+// Проверка реализации интерфейсов на этапе компиляции
+var (
+	_ usecase.OrderProcessor   = (*Service)(nil)
+	_ usecase.PipelineExecutor = (*Service)(nil)
+)
+
+// NewService создает сервис и подключает к движку отслеживание прогресса
+func NewService(logger *zap.Logger, deps Dependencies, config Config) (*Service, error) {
+	if deps.Engine == nil || deps.Definitions == nil || deps.Orders == nil {
+		return nil, errors.New("engine, definitions and orders are required")
+	}
+	if config.PipelineName == "" {
+		config.PipelineName = "order_processing"
+	}
+	if config.StepNames == nil {
+		config.StepNames = DefaultStepNames
+	}
+	if config.StatusRetention <= 0 {
+		config.StatusRetention = time.Hour
+	}
+	if config.Currency == "" {
+		config.Currency = "RUB"
+	}
+
+	s := &Service{
+		logger:        logger,
+		engine:        deps.Engine,
+		definitions:   deps.Definitions,
+		pipelineName:  config.PipelineName,
+		stepNames:     config.StepNames,
+		orders:        deps.Orders,
+		products:      deps.Products,
+		inventory:     deps.Inventory,
+		notifications: deps.Notifications,
+		delivery:      deps.Delivery,
+		rates:         deps.Rates,
+		currency:      money.New(0, config.Currency).Currency(),
+		progress:      newProgressTracker(config.StepNames, config.StatusRetention),
+	}
+
+	deps.Engine.RegisterMiddleware(s.progress)
+	return s, nil
+}
+
+// 📝 СОЗДАНИЕ И ВАЛИДАЦИЯ ЗАКАЗОВ
+
+// CreateOrder создает заказ по текущим ценам каталога
+//
+// Недоступные товары не мешают созданию заказа, они попадают в Warnings -
+// окончательно наличие проверяет шаг валидации.
+func (s *Service) CreateOrder(ctx context.Context, req usecase.CreateOrderRequest) (*usecase.CreateOrderResponse, error) {
+	if s.products == nil {
+		return nil, fmt.Errorf("product service: %w", usecase.ErrNotConfigured)
+	}
+	if req.CustomerID == uuid.Nil {
+		return nil, fmt.Errorf("%w: customer_id is required", usecase.ErrInvalidRequest)
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("%w: order must contain at least one item", usecase.ErrInvalidRequest)
+	}
+
+	items := make([]order.OrderItem, 0, len(req.Items))
+	warnings := make([]string, 0)
+
+	for _, reqItem := range req.Items {
+		if reqItem.Quantity <= 0 {
+			return nil, fmt.Errorf("%w: quantity for product %s must be positive", usecase.ErrInvalidRequest, reqItem.ProductID)
+		}
+
+		price, err := s.products.GetProductPrice(ctx, reqItem.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get price for product %s: %w", reqItem.ProductID, err)
+		}
+
+		available, err := s.products.CheckProductAvailability(ctx, reqItem.ProductID, reqItem.Quantity)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check availability for product %s: %w", reqItem.ProductID, err)
+		}
+		if !available {
+			warnings = append(warnings, fmt.Sprintf("product %s is not available in quantity %d", reqItem.ProductID, reqItem.Quantity))
+		}
+
+		if s.rates != nil && price.Currency() != s.currency {
+			// Цена каталога в другой валюте: пересчитываем по текущему курсу
+			rate, err := s.rates.Rate(ctx, price.Currency(), s.currency)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get %s/%s exchange rate: %w", price.Currency(), s.currency, err)
+			}
+			item, err := order.NewConvertedOrderItem(reqItem.ProductID, reqItem.Quantity, price, rate)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", usecase.ErrInvalidRequest, err)
+			}
+			items = append(items, item)
+			continue
+		}
+
+		item, err := order.NewOrderItem(reqItem.ProductID, reqItem.Quantity, price)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", usecase.ErrInvalidRequest, err)
+		}
+		items = append(items, item)
+	}
+
+	ord, err := order.NewOrder(req.CustomerID, items, req.ShippingAddress)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", usecase.ErrInvalidRequest, err)
+	}
+	if req.BillingAddress != nil {
+		if err := ord.SetBillingAddress(*req.BillingAddress); err != nil {
+			return nil, fmt.Errorf("%w: billing address: %v", usecase.ErrInvalidRequest, err)
+		}
+	}
+	if req.Priority != ord.Priority() {
+		ord.SetPriority(req.Priority)
+	}
+	if req.Source != "" && req.Source != ord.Source() {
+		ord.SetSource(req.Source)
+	}
+	if req.Notes != "" {
+		ord.SetNotes(req.Notes)
+	}
+	// Способ оплаты хранится в заказе: пайплайн могут запустить позже
+	// и из другого экземпляра сервиса
+	if req.PaymentMethod != "" {
+		ord.SetPaymentMethod(req.PaymentMethod.String())
+	}
+
+	if err := s.orders.Save(ctx, ord); err != nil {
+		return nil, fmt.Errorf("failed to save order: %w", err)
+	}
+
+	s.logger.Info("Order created",
+		zap.String("order_id", ord.ID().String()),
+		zap.Int("items_count", len(items)),
+		zap.Int("warnings", len(warnings)))
+
+	return &usecase.CreateOrderResponse{
+		Order:     ord,
+		Warnings:  warnings,
+		NextSteps: s.nextSteps(),
+	}, nil
+}
+
+// nextSteps шаги пайплайна, которые пройдет новый заказ
+func (s *Service) nextSteps() []string {
+	definition, err := s.definition()
+	if err != nil {
+		return nil
+	}
+
+	steps := make([]string, 0, len(definition.Steps))
+	for _, step := range definitionSteps(definition) {
+		steps = append(steps, string(s.progress.useCaseStep(step)))
+	}
+	return steps
+}
+
+// ValidateOrder выполняет шаг валидации заказа
+func (s *Service) ValidateOrder(ctx context.Context, orderID uuid.UUID) (*usecase.ValidateOrderResponse, error) {
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.runStep(ctx, usecase.StepValidation, ord, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	isValid, _ := result.Output["is_valid"].(bool)
+	response := &usecase.ValidateOrderResponse{
+		IsValid:     isValid,
+		ValidatedAt: result.CompletedAt,
+		ValidatedBy: result.Metadata["validator"],
+	}
+	for _, message := range stringList(result.Output["validation_errors"]) {
+		response.Errors = append(response.Errors, usecase.ValidationError{
+			Code:    "validation_failed",
+			Message: message,
+		})
+	}
+	return response, nil
+}
+
+// 💰 ОБРАБОТКА ПЛАТЕЖЕЙ
+
+// ProcessPayment выполняет шаг оплаты заказа выбранным способом
+//
+// Токен карты и ReturnURL передаются шагу метаданными; их получает
+// PaymentService с расширением pipeline.PaymentRequestProcessor. Если оплату
+// должен подтвердить покупатель (QR СБП, 3-D Secure), ответ содержит статус
+// processing и данные для подтверждения; повторный вызов после вебхука
+// провайдера завершает оплату заказа.
+func (s *Service) ProcessPayment(ctx context.Context, req usecase.ProcessPaymentRequest) (*usecase.ProcessPaymentResponse, error) {
+	if req.PaymentMethod == "" {
+		return nil, fmt.Errorf("%w: payment_method is required", usecase.ErrInvalidRequest)
+	}
+
+	ord, err := s.orders.GetByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := map[string]string{"payment_method": req.PaymentMethod.String()}
+	if req.CardToken != "" {
+		metadata["card_token"] = req.CardToken
+	}
+	if req.ReturnURL != "" {
+		metadata["return_url"] = req.ReturnURL
+	}
+
+	result, err := s.runStep(ctx, usecase.StepPaymentProcessing, ord, metadata)
+	if err != nil {
+		return nil, err
+	}
+	pending, _ := result.Output["payment_pending"].(bool)
+	if result.Error != nil && !pending {
+		return nil, fmt.Errorf("%w: %v", usecase.ErrPaymentFailed, result.Error)
+	}
+
+	response := &usecase.ProcessPaymentResponse{
+		Status: paymentStatus(result.Output["payment_status"]),
+	}
+	if id, ok := result.Output["payment_id"].(string); ok {
+		response.PaymentID, _ = uuid.Parse(id)
+	}
+	response.QRCodeData, _ = result.Output["qr_code_data"].(string)
+	response.RedirectURL, _ = result.Output["redirect_url"].(string)
+	if value, ok := result.Output["expires_at"].(string); ok {
+		if expiresAt, err := time.Parse(time.RFC3339, value); err == nil {
+			response.ExpiresAt = &expiresAt
+		}
+	}
+	return response, nil
+}
+
+// 📦 ПРОВЕРКА СКЛАДА
+
+// CheckInventory проверяет наличие товаров заказа без резервирования
+func (s *Service) CheckInventory(ctx context.Context, orderID uuid.UUID) (*usecase.CheckInventoryResponse, error) {
+	if s.inventory == nil {
+		return nil, fmt.Errorf("inventory service: %w", usecase.ErrNotConfigured)
+	}
+
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &usecase.CheckInventoryResponse{
+		AvailableItems:   make([]usecase.InventoryItem, 0),
+		UnavailableItems: make([]usecase.InventoryItem, 0),
+		IsFullyAvailable: true,
+	}
+
+	for _, item := range ord.Items() {
+		available, err := s.inventory.CheckAvailability(ctx, item.ProductID(), item.Quantity())
+		if err != nil {
+			return nil, fmt.Errorf("failed to check inventory for product %s: %w", item.ProductID(), err)
+		}
+
+		inventoryItem := usecase.InventoryItem{
+			ProductID:    item.ProductID(),
+			RequestedQty: item.Quantity(),
+		}
+		if available {
+			inventoryItem.AvailableQty = item.Quantity()
+			response.AvailableItems = append(response.AvailableItems, inventoryItem)
+		} else {
+			response.UnavailableItems = append(response.UnavailableItems, inventoryItem)
+			response.IsFullyAvailable = false
+		}
+	}
+
+	return response, nil
+}
+
+// ReserveInventory резервирует все товары заказа
+func (s *Service) ReserveInventory(ctx context.Context, orderID uuid.UUID) (*usecase.ReserveInventoryResponse, error) {
+	if s.inventory == nil {
+		return nil, fmt.Errorf("inventory service: %w", usecase.ErrNotConfigured)
+	}
+
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]pipeline.ReservationItem, 0, len(ord.Items()))
+	for _, item := range ord.Items() {
+		items = append(items, pipeline.ReservationItem{ProductID: item.ProductID(), Quantity: item.Quantity()})
+	}
+
+	var reservation *pipeline.Reservation
+	if reserver, ok := s.inventory.(pipeline.OrderInventoryReserver); ok {
+		reservation, err = reserver.ReserveOrderItems(ctx, orderID, items)
+	} else {
+		reservation, err = s.inventory.ReserveItems(ctx, items)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reserve items: %w", err)
+	}
+
+	reservedAt := time.Now()
+	response := &usecase.ReserveInventoryResponse{
+		ReservedItems: make([]usecase.ReservedItem, 0, len(reservation.Items)),
+		ReservationID: reservation.ID,
+		ExpiresAt:     reservation.ExpiresAt,
+	}
+	for _, item := range reservation.Items {
+		response.ReservedItems = append(response.ReservedItems, usecase.ReservedItem{
+			ProductID:  item.ProductID,
+			Quantity:   item.Quantity,
+			ReservedAt: reservedAt,
+		})
+		response.TotalReserved += item.Quantity
+	}
+
+	return response, nil
+}
+
+// 🚚 ОРГАНИЗАЦИЯ ДОСТАВКИ
+
+// ArrangeDelivery организует доставку заказа, прошедшего проверку склада
+func (s *Service) ArrangeDelivery(ctx context.Context, orderID uuid.UUID) (*usecase.ArrangeDeliveryResponse, error) {
+	if s.delivery == nil {
+		return nil, fmt.Errorf("delivery service: %w", usecase.ErrNotConfigured)
+	}
+
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	if ord.Status() != order.StatusInventoryChecked {
+		return nil, fmt.Errorf("%w: order status is %s, expected inventory_checked", usecase.ErrInvalidRequest, ord.Status())
+	}
+
+	return s.delivery.ArrangeDelivery(ctx, ord)
+}
+
+// 📧 УВЕДОМЛЕНИЯ
+
+// notificationTexts заголовки и тексты push уведомлений
+var notificationTexts = map[usecase.NotificationEvent][2]string{
+	usecase.NotificationOrderCreated:      {"Заказ создан", "Заказ %s принят и ожидает проверки."},
+	usecase.NotificationOrderValidated:    {"Заказ проверен", "Заказ %s проверен и ожидает оплаты."},
+	usecase.NotificationPaymentReceived:   {"Оплата получена", "Оплата заказа %s получена."},
+	usecase.NotificationPaymentFailed:     {"Оплата не прошла", "Не удалось оплатить заказ %s."},
+	usecase.NotificationInventoryReserved: {"Товары зарезервированы", "Товары заказа %s зарезервированы на складе."},
+	usecase.NotificationShipped:           {"Заказ отправлен", "Заказ %s передан в доставку."},
+	usecase.NotificationDelivered:         {"Заказ доставлен", "Заказ %s доставлен."},
+	usecase.NotificationCancelled:         {"Заказ отменен", "Заказ %s отменен."},
+}
+
+// SendNotifications отправляет клиенту push уведомление о событии заказа
+func (s *Service) SendNotifications(ctx context.Context, orderID uuid.UUID, event usecase.NotificationEvent) error {
+	if s.notifications == nil {
+		return fmt.Errorf("notification service: %w", usecase.ErrNotConfigured)
+	}
+
+	text, ok := notificationTexts[event]
+	if !ok {
+		return fmt.Errorf("%w: unknown notification event %q", usecase.ErrInvalidRequest, event)
+	}
+
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return err
+	}
+
+	return s.notifications.SendPushNotification(ctx, ord.CustomerID(), text[0], fmt.Sprintf(text[1], ord.ID()))
+}
+
+// 🔧 ВСПОМОГАТЕЛЬНЫЕ ФУНКЦИИ
+
+// stringList читает []string из выхода шага (после JSON checkpoint'а это []interface{})
+func stringList(value interface{}) []string {
+	switch list := value.(type) {
+	case []string:
+		return list
+	case []interface{}:
+		result := make([]string, 0, len(list))
+		for _, item := range list {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	default:
+		return nil
+	}
+}
+
+// paymentStatus переводит строковый статус из выхода шага в payment.Status
+func paymentStatus(value interface{}) payment.Status {
+	name, _ := value.(string)
+	for status := payment.StatusPending; status <= payment.StatusPartiallyRefunded; status++ {
+		if status.String() == name {
+			return status
+		}
+	}
+	return payment.StatusPending
+}
@@ -0,0 +1,354 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore/sqlstoretest"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+	pipelineEngine "pipeline-clean-architecture/pkg/pipeline"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// stepFunc шаг движка, переводящий заказ в следующий статус
+type stepFunc struct {
+	name    string
+	execute func(ctx context.Context, data *pipelineEngine.StepData) (map[string]interface{}, error)
+}
+
+func (s *stepFunc) Execute(ctx context.Context, data *pipelineEngine.StepData) (*pipelineEngine.StepResult, error) {
+	output, err := s.execute(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+	return &pipelineEngine.StepResult{Step: s.name, Success: true, Output: output}, nil
+}
+
+func (s *stepFunc) Name() string                                 { return s.name }
+func (s *stepFunc) Validate(data *pipelineEngine.StepData) error { return nil }
+func (s *stepFunc) Timeout() time.Duration                       { return 5 * time.Second }
+func (s *stepFunc) Dependencies() []string                       { return nil }
+func (s *stepFunc) CanRetry(err error) bool                      { return false }
+
+// definitions статический источник определений
+type definitions map[string]pipelineEngine.PipelineDefinition
+
+func (d definitions) Get(name string) (pipelineEngine.PipelineDefinition, bool) {
+	definition, ok := d[name]
+	return definition, ok
+}
+
+// products каталог с фиксированными ценами
+type products map[uuid.UUID]int64
+
+func (p products) CheckProductAvailability(ctx context.Context, productID uuid.UUID, quantity int) (bool, error) {
+	_, ok := p[productID]
+	return ok, nil
+}
+
+func (p products) GetProductPrice(ctx context.Context, productID uuid.UUID) (order.Money, error) {
+	price, ok := p[productID]
+	if !ok {
+		return order.Money{}, fmt.Errorf("product %s not found", productID)
+	}
+	return order.NewMoney(price, "RUB"), nil
+}
+
+// fixture сервис с шагами, меняющими статус заказа в репозитории
+type fixture struct {
+	service   *Service
+	orders    *sqlstore.OrderRepository
+	productID uuid.UUID
+
+	// inventoryGate, если задан, держит шаг check_inventory до закрытия
+	inventoryGate chan struct{}
+	// inventoryStarted закрывается при входе в check_inventory
+	inventoryStarted chan struct{}
+	startedOnce      sync.Once
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+
+	f := &fixture{
+		orders:           sqlstoretest.NewOrderRepository(t),
+		productID:        uuid.New(),
+		inventoryStarted: make(chan struct{}),
+	}
+
+	engine := pipelineEngine.NewEngine(zap.NewNop(), pipelineEngine.Config{
+		DefaultTimeout: 5 * time.Second,
+		RetryConfig:    pipelineEngine.RetryConfig{MaxAttempts: 1},
+	})
+
+	transition := func(name string, output map[string]interface{}, marks ...func(*order.Order) error) *stepFunc {
+		return &stepFunc{name: name, execute: func(ctx context.Context, data *pipelineEngine.StepData) (map[string]interface{}, error) {
+			orderID, _ := uuid.Parse(fmt.Sprint(data.Input["order_id"]))
+			ord, err := f.orders.GetByID(ctx, orderID)
+			if err != nil {
+				return nil, err
+			}
+			for _, mark := range marks {
+				if err := mark(ord); err != nil {
+					return nil, err
+				}
+			}
+			return output, f.orders.Save(ctx, ord)
+		}}
+	}
+
+	inventory := transition("check_inventory", map[string]interface{}{"all_available": true}, (*order.Order).MarkAsInventoryChecked)
+	checkInventory := inventory.execute
+	inventory.execute = func(ctx context.Context, data *pipelineEngine.StepData) (map[string]interface{}, error) {
+		f.startedOnce.Do(func() { close(f.inventoryStarted) })
+		if f.inventoryGate != nil {
+			<-f.inventoryGate
+		}
+		return checkInventory(ctx, data)
+	}
+
+	steps := []*stepFunc{
+		transition("validate_order", map[string]interface{}{"is_valid": true}, (*order.Order).MarkAsValidated),
+		transition("process_payment", map[string]interface{}{
+			"payment_status": "succeeded",
+			"payment_id":     "6f1f7a52-7f1c-4a43-a2bb-0a34c7c1b1a0",
+		}, (*order.Order).MarkAsPaymentProcessing, (*order.Order).MarkAsPaid),
+		inventory,
+		transition("send_notifications", nil),
+	}
+	for _, step := range steps {
+		if err := engine.RegisterStep(step.name, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	service, err := NewService(zap.NewNop(), Dependencies{
+		Engine: engine,
+		Definitions: definitions{"order_processing": {
+			Name:    "order_processing",
+			Steps:   []string{"validate_order", "process_payment", "check_inventory", "send_notifications"},
+			Timeout: 10 * time.Second,
+			Retry:   pipelineEngine.RetryConfig{MaxAttempts: 1},
+		}},
+		Orders:   f.orders,
+		Products: products{f.productID: 50000},
+	}, Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.service = service
+	return f
+}
+
+// createOrder создает заказ из двух единиц товара фикстуры
+func (f *fixture) createOrder(t *testing.T) uuid.UUID {
+	t.Helper()
+
+	resp, err := f.service.CreateOrder(context.Background(), usecase.CreateOrderRequest{
+		CustomerID:      uuid.New(),
+		Items:           []usecase.CreateOrderItem{{ProductID: f.productID, Quantity: 2}},
+		ShippingAddress: order.NewAddress("Тверская 1", "Москва", "125009", "RU", "+7"),
+		PaymentMethod:   "card",
+		Priority:        order.PriorityHigh,
+		Source:          order.SourceMobile,
+	})
+	if err != nil {
+		t.Fatalf("CreateOrder() error = %v", err)
+	}
+	return resp.Order.ID()
+}
+
+func TestCreateOrderUsesCatalogPrices(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	orderID := f.createOrder(t)
+	ord, err := f.orders.GetByID(ctx, orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord.TotalAmount().Amount() != 100000 {
+		t.Errorf("total = %d, want 100000", ord.TotalAmount().Amount())
+	}
+	if ord.Priority() != order.PriorityHigh || ord.Source() != order.SourceMobile {
+		t.Errorf("priority %s source %s, want high mobile", ord.Priority(), ord.Source())
+	}
+	if ord.PaymentMethod() != "card" {
+		t.Errorf("payment method = %q, want card", ord.PaymentMethod())
+	}
+
+	_, err = f.service.CreateOrder(ctx, usecase.CreateOrderRequest{CustomerID: uuid.New()})
+	if !errors.Is(err, usecase.ErrInvalidRequest) {
+		t.Errorf("CreateOrder() without items error = %v, want ErrInvalidRequest", err)
+	}
+}
+
+func TestExecuteOrderPipelineReportsStepsInOrder(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+
+	result, err := f.service.ExecuteOrderPipeline(ctx, orderID)
+	if err != nil {
+		t.Fatalf("ExecuteOrderPipeline() error = %v", err)
+	}
+	if !result.Success || len(result.Errors) != 0 {
+		t.Fatalf("result success %v errors %v, want success", result.Success, result.Errors)
+	}
+	if result.FinalStatus != order.StatusInventoryChecked {
+		t.Errorf("final status = %s, want inventory_checked", result.FinalStatus)
+	}
+
+	want := []usecase.PipelineStep{usecase.StepValidation, usecase.StepPaymentProcessing, usecase.StepInventoryCheck, usecase.StepNotifications}
+	if len(result.Steps) != len(want) {
+		t.Fatalf("steps = %d, want %d", len(result.Steps), len(want))
+	}
+	for i, step := range want {
+		if result.Steps[i].Step != step || !result.Steps[i].Success {
+			t.Errorf("steps[%d] = %s success %v, want %s", i, result.Steps[i].Step, result.Steps[i].Success, step)
+		}
+	}
+
+	status, err := f.service.GetPipelineStatus(ctx, orderID)
+	if err != nil {
+		t.Fatalf("GetPipelineStatus() error = %v", err)
+	}
+	if status.State != usecase.PipelineStateCompleted || status.Progress != 1 || len(status.CompletedSteps) != 4 {
+		t.Errorf("status = %+v, want completed with 4 steps", status)
+	}
+}
+
+func TestWatchPipelineStatusStreamsProgress(t *testing.T) {
+	f := newFixture(t)
+	f.inventoryGate = make(chan struct{})
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+
+	updates, err := f.service.WatchPipelineStatus(ctx, orderID)
+	if err != nil {
+		t.Fatalf("WatchPipelineStatus() error = %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := f.service.ExecuteOrderPipeline(ctx, orderID)
+		done <- err
+	}()
+
+	<-f.inventoryStarted
+	if _, err := f.service.ValidateOrder(ctx, orderID); !errors.Is(err, usecase.ErrPipelineRunning) {
+		t.Errorf("ValidateOrder() while running error = %v, want ErrPipelineRunning", err)
+	}
+	if _, err := f.service.ExecuteOrderPipeline(ctx, orderID); !errors.Is(err, usecase.ErrPipelineRunning) {
+		t.Errorf("second ExecuteOrderPipeline() error = %v, want ErrPipelineRunning", err)
+	}
+	close(f.inventoryGate)
+
+	var statuses []usecase.PipelineStatus
+	timeout := time.After(5 * time.Second)
+	for collecting := true; collecting; {
+		select {
+		case status, ok := <-updates:
+			if !ok {
+				collecting = false
+				break
+			}
+			statuses = append(statuses, status)
+		case <-timeout:
+			t.Fatal("status stream was not closed")
+		}
+	}
+	if err := <-done; err != nil {
+		t.Fatalf("ExecuteOrderPipeline() error = %v", err)
+	}
+
+	if len(statuses) == 0 {
+		t.Fatal("no status updates")
+	}
+	last := statuses[len(statuses)-1]
+	if last.State != usecase.PipelineStateCompleted || last.Progress != 1 {
+		t.Errorf("last status = %+v, want completed", last)
+	}
+
+	sawInventoryRunning := false
+	for i, status := range statuses {
+		if status.CurrentStep == usecase.StepInventoryCheck {
+			sawInventoryRunning = true
+		}
+		if i > 0 && len(status.CompletedSteps) < len(statuses[i-1].CompletedSteps) {
+			t.Errorf("completed steps went back: %v -> %v", statuses[i-1].CompletedSteps, status.CompletedSteps)
+		}
+	}
+	if !sawInventoryRunning {
+		t.Error("no status with current step inventory_check")
+	}
+}
+
+func TestWatchPipelineStatusStopsOnContextCancel(t *testing.T) {
+	f := newFixture(t)
+	orderID := f.createOrder(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	updates, err := f.service.WatchPipelineStatus(ctx, orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	select {
+	case _, ok := <-updates:
+		if ok {
+			t.Error("unexpected status before pipeline start")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("channel was not closed after cancel")
+	}
+}
+
+func TestExecuteStepRunsSingleEngineStep(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+
+	result, err := f.service.ExecuteStep(ctx, usecase.StepValidation, orderID)
+	if err != nil {
+		t.Fatalf("ExecuteStep() error = %v", err)
+	}
+	if result.Step != usecase.StepValidation || !result.Success {
+		t.Errorf("result = %+v, want successful validation", result)
+	}
+
+	payment, err := f.service.ProcessPayment(ctx, usecase.ProcessPaymentRequest{OrderID: orderID, PaymentMethod: "card"})
+	if err != nil {
+		t.Fatalf("ProcessPayment() error = %v", err)
+	}
+	if payment.Status.String() != "succeeded" || payment.PaymentID == uuid.Nil {
+		t.Errorf("payment = %+v, want succeeded with id", payment)
+	}
+
+	ord, err := f.orders.GetByID(ctx, orderID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ord.Status() != order.StatusPaid {
+		t.Errorf("status = %s, want paid", ord.Status())
+	}
+
+	if _, err := f.service.ExecuteStep(ctx, usecase.StepDeliveryArrange, orderID); !errors.Is(err, usecase.ErrStepNotSupported) {
+		t.Errorf("ExecuteStep(delivery) error = %v, want ErrStepNotSupported", err)
+	}
+	if _, err := f.service.GetPipelineStatus(ctx, orderID); !errors.Is(err, usecase.ErrPipelineNotFound) {
+		t.Errorf("GetPipelineStatus() error = %v, want ErrPipelineNotFound", err)
+	}
+	if _, err := f.service.ArrangeDelivery(ctx, orderID); !errors.Is(err, usecase.ErrNotConfigured) {
+		t.Errorf("ArrangeDelivery() error = %v, want ErrNotConfigured", err)
+	}
+}
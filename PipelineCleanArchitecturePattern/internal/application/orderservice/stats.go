@@ -0,0 +1,259 @@
+package orderservice
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/product"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"github.com/google/uuid"
+)
+
+// 📊 СТАТИСТИКА ЗАКАЗОВ
+//
+// Заказы выбираются через order.SearchRepository, если репозиторий его
+// реализует, иначе - базовыми запросами Repository с фильтрацией в памяти.
+// Выручка, тренды и популярные товары, как и в аналитике репозиториев,
+// считаются по оплаченным заказам (paid ... delivered); период - по
+// дате создания заказа в полуинтервале [DateFrom, DateTo).
+
+// topProductsLimit сколько товаров возвращать в TopProducts
+const topProductsLimit = 10
+
+// GetOrderStats возвращает статистику по заказам
+func (s *Service) GetOrderStats(ctx context.Context, req usecase.GetOrderStatsRequest) (*usecase.GetOrderStatsResponse, error) {
+	switch req.GroupBy {
+	case "", usecase.StatsGroupByDay, usecase.StatsGroupByWeek, usecase.StatsGroupByMonth,
+		usecase.StatsGroupByStatus, usecase.StatsGroupBySource:
+	default:
+		return nil, fmt.Errorf("%w: unknown group_by %q", usecase.ErrInvalidRequest, req.GroupBy)
+	}
+	if req.DateFrom != nil && req.DateTo != nil && !req.DateFrom.Before(*req.DateTo) {
+		return nil, fmt.Errorf("%w: date_from must be before date_to", usecase.ErrInvalidRequest)
+	}
+
+	orders, err := s.findOrders(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	paid := make([]*order.Order, 0, len(orders))
+	for _, ord := range orders {
+		if isRevenueStatus(ord.Status()) {
+			paid = append(paid, ord)
+		}
+	}
+
+	currency, err := ordersCurrency(paid)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &usecase.GetOrderStatsResponse{
+		TotalOrders:     len(orders),
+		StatusBreakdown: make(map[string]int),
+		TrendData:       make([]usecase.StatsTrendPoint, 0),
+		TopProducts:     topProducts(paid, currency),
+	}
+
+	for _, ord := range orders {
+		key := ord.Status().String()
+		if req.GroupBy == usecase.StatsGroupBySource {
+			key = string(ord.Source())
+		}
+		response.StatusBreakdown[key]++
+	}
+
+	var revenue int64
+	for _, ord := range paid {
+		revenue += ord.TotalAmount().Amount()
+	}
+	response.TotalRevenue = product.NewMoney(revenue, currency)
+	if len(paid) > 0 {
+		response.AverageOrder = product.NewMoney(revenue/int64(len(paid)), currency)
+	} else {
+		response.AverageOrder = product.NewMoney(0, currency)
+	}
+
+	if period := periodStart(req.GroupBy); period != nil {
+		response.TrendData = trends(paid, currency, period)
+	}
+
+	return response, nil
+}
+
+// findOrders выбирает заказы по фильтрам запроса
+func (s *Service) findOrders(ctx context.Context, req usecase.GetOrderStatsRequest) ([]*order.Order, error) {
+	if searcher, ok := s.orders.(order.SearchRepository); ok {
+		return searcher.Search(ctx, order.SearchCriteria{
+			CustomerID: req.CustomerID,
+			Status:     req.Status,
+			Source:     req.Source,
+			DateFrom:   req.DateFrom,
+			DateTo:     req.DateTo,
+		})
+	}
+
+	var (
+		candidates []*order.Order
+		err        error
+	)
+	switch {
+	case req.CustomerID != nil:
+		candidates, err = s.orders.GetByCustomerID(ctx, *req.CustomerID)
+	case req.Status != nil:
+		candidates, err = s.orders.GetByStatus(ctx, *req.Status)
+	default:
+		from, to := time.Time{}, time.Now().Add(time.Minute)
+		if req.DateFrom != nil {
+			from = *req.DateFrom
+		}
+		if req.DateTo != nil {
+			to = *req.DateTo
+		}
+		candidates, err = s.orders.GetOrdersInDateRange(ctx, from, to)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load orders: %w", err)
+	}
+
+	orders := make([]*order.Order, 0, len(candidates))
+	for _, ord := range candidates {
+		if matchesStatsRequest(ord, req) {
+			orders = append(orders, ord)
+		}
+	}
+	return orders, nil
+}
+
+// matchesStatsRequest проверяет заказ по всем фильтрам запроса
+func matchesStatsRequest(ord *order.Order, req usecase.GetOrderStatsRequest) bool {
+	switch {
+	case req.CustomerID != nil && ord.CustomerID() != *req.CustomerID:
+		return false
+	case req.Status != nil && ord.Status() != *req.Status:
+		return false
+	case req.Source != nil && ord.Source() != *req.Source:
+		return false
+	case req.DateFrom != nil && ord.CreatedAt().Before(*req.DateFrom):
+		return false
+	case req.DateTo != nil && !ord.CreatedAt().Before(*req.DateTo):
+		return false
+	}
+	return true
+}
+
+// isRevenueStatus дает ли заказ в этом статусе выручку
+func isRevenueStatus(status order.Status) bool {
+	return status >= order.StatusPaid && status <= order.StatusDelivered
+}
+
+// ordersCurrency общая валюта заказов
+func ordersCurrency(orders []*order.Order) (string, error) {
+	currency := ""
+	for _, ord := range orders {
+		if currency == "" {
+			currency = ord.Currency()
+		} else if ord.Currency() != currency {
+			return "", order.ErrMixedCurrencies
+		}
+	}
+	return currency, nil
+}
+
+// periodStart начало периода для группировки трендов (nil - не тренд)
+func periodStart(groupBy usecase.StatsGroupBy) func(time.Time) time.Time {
+	switch groupBy {
+	case usecase.StatsGroupByDay:
+		return func(t time.Time) time.Time {
+			t = t.UTC()
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		}
+	case usecase.StatsGroupByWeek:
+		// Неделя начинается с понедельника, как в sqlstore
+		return func(t time.Time) time.Time {
+			t = t.UTC()
+			day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+			return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		}
+	case usecase.StatsGroupByMonth:
+		return func(t time.Time) time.Time {
+			t = t.UTC()
+			return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		}
+	default:
+		return nil
+	}
+}
+
+// trends группирует оплаченные заказы по периодам
+func trends(orders []*order.Order, currency string, period func(time.Time) time.Time) []usecase.StatsTrendPoint {
+	type bucket struct {
+		count   int
+		revenue int64
+	}
+
+	buckets := make(map[time.Time]*bucket)
+	for _, ord := range orders {
+		start := period(ord.CreatedAt())
+		if buckets[start] == nil {
+			buckets[start] = &bucket{}
+		}
+		buckets[start].count++
+		buckets[start].revenue += ord.TotalAmount().Amount()
+	}
+
+	points := make([]usecase.StatsTrendPoint, 0, len(buckets))
+	for start, b := range buckets {
+		points = append(points, usecase.StatsTrendPoint{
+			Period:  start,
+			Count:   b.count,
+			Revenue: product.NewMoney(b.revenue, currency),
+		})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Period.Before(points[j].Period) })
+	return points
+}
+
+// topProducts самые продаваемые товары по количеству
+func topProducts(orders []*order.Order, currency string) []usecase.StatsProduct {
+	type sales struct {
+		quantity int
+		revenue  int64
+	}
+
+	byProduct := make(map[uuid.UUID]*sales)
+	for _, ord := range orders {
+		for _, item := range ord.Items() {
+			if byProduct[item.ProductID()] == nil {
+				byProduct[item.ProductID()] = &sales{}
+			}
+			byProduct[item.ProductID()].quantity += item.Quantity()
+			byProduct[item.ProductID()].revenue += item.Total().Amount()
+		}
+	}
+
+	result := make([]usecase.StatsProduct, 0, len(byProduct))
+	for productID, s := range byProduct {
+		result = append(result, usecase.StatsProduct{
+			ProductID: productID,
+			Quantity:  s.quantity,
+			Revenue:   product.NewMoney(s.revenue, currency),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Quantity != result[j].Quantity {
+			return result[i].Quantity > result[j].Quantity
+		}
+		return result[i].ProductID.String() < result[j].ProductID.String()
+	})
+
+	if len(result) > topProductsLimit {
+		result = result[:topProductsLimit]
+	}
+	return result
+}
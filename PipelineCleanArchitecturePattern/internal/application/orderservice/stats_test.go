@@ -0,0 +1,72 @@
+package orderservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+)
+
+func TestGetOrderStats(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+
+	paid := f.createOrder(t)
+	if _, err := f.service.ExecuteOrderPipeline(ctx, paid); err != nil {
+		t.Fatal(err)
+	}
+
+	f.createOrder(t)
+
+	cancelled := f.createOrder(t)
+	ord, err := f.orders.GetByID(ctx, cancelled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ord.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.orders.Save(ctx, ord); err != nil {
+		t.Fatal(err)
+	}
+
+	stats, err := f.service.GetOrderStats(ctx, usecase.GetOrderStatsRequest{GroupBy: usecase.StatsGroupByDay})
+	if err != nil {
+		t.Fatalf("GetOrderStats() error = %v", err)
+	}
+	if stats.TotalOrders != 3 {
+		t.Errorf("total orders = %d, want 3", stats.TotalOrders)
+	}
+	if stats.TotalRevenue.Amount() != 100000 || stats.AverageOrder.Amount() != 100000 {
+		t.Errorf("revenue %d average %d, want only the paid order (100000)", stats.TotalRevenue.Amount(), stats.AverageOrder.Amount())
+	}
+	for status, want := range map[string]int{"inventory_checked": 1, "pending": 1, "cancelled": 1} {
+		if got := stats.StatusBreakdown[status]; got != want {
+			t.Errorf("breakdown[%s] = %d, want %d", status, got, want)
+		}
+	}
+	if len(stats.TrendData) != 1 || stats.TrendData[0].Count != 1 {
+		t.Errorf("trend = %+v, want one day with one paid order", stats.TrendData)
+	}
+	if len(stats.TopProducts) != 1 || stats.TopProducts[0].ProductID != f.productID || stats.TopProducts[0].Quantity != 2 {
+		t.Errorf("top products = %+v, want fixture product x2", stats.TopProducts)
+	}
+
+	pending := order.StatusPending
+	stats, err = f.service.GetOrderStats(ctx, usecase.GetOrderStatsRequest{Status: &pending, GroupBy: usecase.StatsGroupBySource})
+	if err != nil {
+		t.Fatalf("GetOrderStats(pending) error = %v", err)
+	}
+	if stats.TotalOrders != 1 || stats.StatusBreakdown[string(order.SourceMobile)] != 1 {
+		t.Errorf("pending stats = %+v, want one mobile order", stats)
+	}
+	if stats.TotalRevenue.Amount() != 0 {
+		t.Errorf("pending revenue = %d, want 0", stats.TotalRevenue.Amount())
+	}
+
+	if _, err := f.service.GetOrderStats(ctx, usecase.GetOrderStatsRequest{GroupBy: "year"}); !errors.Is(err, usecase.ErrInvalidRequest) {
+		t.Errorf("GetOrderStats(year) error = %v, want ErrInvalidRequest", err)
+	}
+}
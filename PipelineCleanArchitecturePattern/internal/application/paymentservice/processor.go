@@ -0,0 +1,579 @@
+package paymentservice
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"pipeline-clean-architecture/internal/application/pipeline"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/payment"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 💳 ПРОЦЕССОР ПЛАТЕЖЕЙ
+//
+// ============================================================================
+// ЧТО ДЕЛАЕТ Processor:
+// ============================================================================
+//
+// Processor реализует pipeline.PaymentService поверх подключаемых адаптеров
+// payment.PaymentGateway (RegisterGateway связывает способ оплаты с адаптером):
+//
+//	Authorize → (approved)  Capture → MarkAsSucceeded
+//	          → (captured)  MarkAsSucceeded
+//	          → (pending)   ждем вебхук: QR СБП / 3-D Secure
+//	          → (declined)  MarkAsFailed, ErrPaymentDeclined
+//
+// ============================================================================
+// ИДЕМПОТЕНТНОСТЬ:
+// ============================================================================
+//
+// Ключи операций выводятся из ID заказа и номера попытки оплаты
+// (payment.IdempotencyKey). Повтор шага пайплайна:
+//   - находит успешный платеж заказа и возвращает его без новых списаний;
+//   - продолжает незавершенную попытку с тем же ключом (обрыв связи после
+//     Authorize не приведет к второй блокировке средств);
+//   - после отказа/отмены начинает новую попытку с новым ключом.
+//
+// ============================================================================
+// ВЕБХУКИ:
+// ============================================================================
+//
+// HandleWebhook проверяет подпись через адаптер провайдера и переводит платеж:
+// payment.authorized → Capture, payment.succeeded → MarkAsSucceeded,
+// payment.failed → MarkAsFailed. Повторная доставка того же события не
+// меняет платеж, но повторяет списание авторизованного платежа, если прошлый
+// Capture не удался (ключ идемпотентности не даст списать дважды). Пока платеж
+// ждет подтверждения, ProcessPayment либо сразу возвращает его в статусе
+// processing (ConfirmationTimeout = 0), либо ждет вебхук не дольше
+// ConfirmationTimeout.
+//
+// Репозиторий может возвращать копии платежа (как SQL хранилище), поэтому
+// каждое изменение и каждая проверка ожидания работают с платежом,
+// перечитанным из репозитория.
+//
+// ============================================================================
+
+// Проверка реализации интерфейсов пайплайна
+var (
+	_ pipeline.PaymentService          = (*Processor)(nil)
+	_ pipeline.PaymentRequestProcessor = (*Processor)(nil)
+)
+
+// Config настройки процессора
+type Config struct {
+	// ManualCapture не списывать средства сразу после авторизации (списание - Capture)
+	ManualCapture bool
+
+	// ConfirmationTimeout сколько ProcessPayment ждет вебхук для QR / 3-D Secure;
+	// 0 - не ждать, вернуть платеж в статусе processing
+	ConfirmationTimeout time.Duration
+}
+
+// Processor процессор платежей
+type Processor struct {
+	logger   *zap.Logger
+	orders   order.Repository
+	payments payment.Repository
+	config   Config
+
+	gateways   map[payment.Method]payment.PaymentGateway
+	byProvider map[payment.Provider]payment.PaymentGateway
+
+	// mu сериализует изменения платежей между ProcessPayment и вебхуками
+	mu      sync.Mutex
+	waiters map[uuid.UUID][]chan struct{} // платеж → ожидающие подтверждения
+}
+
+// NewProcessor создает процессор без адаптеров
+func NewProcessor(logger *zap.Logger, orders order.Repository, payments payment.Repository, config Config) *Processor {
+	return &Processor{
+		logger:     logger,
+		orders:     orders,
+		payments:   payments,
+		config:     config,
+		gateways:   make(map[payment.Method]payment.PaymentGateway),
+		byProvider: make(map[payment.Provider]payment.PaymentGateway),
+		waiters:    make(map[uuid.UUID][]chan struct{}),
+	}
+}
+
+// RegisterGateway подключает адаптер для способов оплаты
+func (p *Processor) RegisterGateway(gateway payment.PaymentGateway, methods ...payment.Method) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, method := range methods {
+		p.gateways[method] = gateway
+	}
+	p.byProvider[gateway.Provider()] = gateway
+}
+
+// ValidatePaymentMethod проверяет, что для способа оплаты есть адаптер
+func (p *Processor) ValidatePaymentMethod(method payment.Method) error {
+	_, err := p.gateway(method)
+	return err
+}
+
+// ProcessPayment оплачивает заказ без реквизитов платежной формы
+func (p *Processor) ProcessPayment(ctx context.Context, orderID uuid.UUID, method payment.Method) (*payment.Payment, error) {
+	return p.ProcessPaymentRequest(ctx, pipeline.PaymentRequest{OrderID: orderID, Method: method})
+}
+
+// ProcessPaymentRequest оплачивает заказ: авторизация и списание
+func (p *Processor) ProcessPaymentRequest(ctx context.Context, req pipeline.PaymentRequest) (*payment.Payment, error) {
+	gateway, err := p.gateway(req.Method)
+	if err != nil {
+		return nil, err
+	}
+
+	ord, err := p.orders.GetByID(ctx, req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	pmt, attempt, stage, err := p.startAttempt(ctx, ord, req.Method, gateway.Provider())
+	switch {
+	case err != nil:
+		return nil, err
+	case stage == stageSucceeded:
+		return pmt, nil
+	case stage == stageAuthorized:
+		// Незавершенная попытка: средства уже заблокированы
+		return p.capture(ctx, gateway, pmt, attempt)
+	case stage == stageAwaiting:
+		// Незавершенная попытка: ждем покупателя
+		return p.awaitConfirmation(ctx, gateway, pmt)
+	}
+
+	authorization, err := gateway.Authorize(ctx, payment.AuthorizeRequest{
+		PaymentID:      pmt.ID(),
+		OrderID:        ord.ID(),
+		CustomerID:     ord.CustomerID(),
+		Amount:         pmt.Amount(),
+		Method:         req.Method,
+		CardToken:      req.CardToken,
+		ReturnURL:      req.ReturnURL,
+		Description:    fmt.Sprintf("Order %s", ord.ID()),
+		IdempotencyKey: payment.IdempotencyKey(ord.ID(), "authorize", attempt),
+	})
+	if err != nil {
+		// Платеж остается processing: повтор шага пройдет с тем же ключом
+		return nil, fmt.Errorf("authorize payment %s: %w", pmt.ID(), err)
+	}
+
+	pmt, err = p.applyAuthorization(ctx, pmt, authorization)
+	if err != nil {
+		return nil, err
+	}
+
+	switch authorization.Status {
+	case payment.AuthorizationApproved:
+		if p.config.ManualCapture {
+			return pmt, nil
+		}
+		return p.capture(ctx, gateway, pmt, attempt)
+	case payment.AuthorizationPending:
+		return p.awaitConfirmation(ctx, gateway, pmt)
+	case payment.AuthorizationDeclined:
+		return pmt, fmt.Errorf("%w: %s", payment.ErrPaymentDeclined, authorization.DeclineReason)
+	}
+	return pmt, nil
+}
+
+// Capture списывает средства авторизованного платежа (при ManualCapture)
+func (p *Processor) Capture(ctx context.Context, paymentID uuid.UUID) (*payment.Payment, error) {
+	pmt, err := p.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if pmt.IsSuccessful() {
+		return pmt, nil
+	}
+	if !pmt.IsAuthorized() {
+		return nil, fmt.Errorf("payment %s is not authorized (status %s)", paymentID, pmt.Status())
+	}
+
+	gateway, err := p.providerGateway(pmt.Provider())
+	if err != nil {
+		return nil, err
+	}
+	return p.capture(ctx, gateway, pmt, attemptOf(pmt))
+}
+
+// Void отменяет авторизацию или неоплаченный QR-код
+func (p *Processor) Void(ctx context.Context, paymentID uuid.UUID, reason string) error {
+	_, err := p.void(ctx, paymentID, reason)
+	return err
+}
+
+// RefundPayment возвращает всю сумму успешного платежа (компенсация шага оплаты)
+func (p *Processor) RefundPayment(ctx context.Context, paymentID uuid.UUID, reason string) error {
+	pmt, err := p.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return err
+	}
+	if pmt.Status() == payment.StatusRefunded {
+		return nil
+	}
+	if !pmt.CanRefund() {
+		return fmt.Errorf("payment %s cannot be refunded in status %s", paymentID, pmt.Status())
+	}
+
+	gateway, err := p.providerGateway(pmt.Provider())
+	if err != nil {
+		return err
+	}
+	key := payment.IdempotencyKey(pmt.OrderID(), "refund", attemptOf(pmt))
+	if err := gateway.Refund(ctx, pmt.ExternalID(), pmt.Amount(), key); err != nil {
+		return fmt.Errorf("refund payment %s: %w", paymentID, err)
+	}
+
+	_, err = p.update(ctx, pmt.ID(), func(pmt *payment.Payment) error {
+		return pmt.Refund(reason)
+	})
+	return err
+}
+
+// GetPayment возвращает платеж по ID
+func (p *Processor) GetPayment(ctx context.Context, paymentID uuid.UUID) (*payment.Payment, error) {
+	return p.payments.GetByID(ctx, paymentID)
+}
+
+// 📨 ВЕБХУКИ
+
+// HandleWebhook обрабатывает уведомление провайдера
+func (p *Processor) HandleWebhook(ctx context.Context, provider payment.Provider, payload []byte, signature string) error {
+	gateway, err := p.providerGateway(provider)
+	if err != nil {
+		return err
+	}
+
+	event, err := gateway.ParseWebhook(payload, signature)
+	if err != nil {
+		return err
+	}
+
+	pmt, err := p.payments.GetByExternalID(ctx, provider, event.ExternalID)
+	if err != nil {
+		return err
+	}
+
+	p.logger.Info("Payment webhook received",
+		zap.String("event_id", event.ID),
+		zap.String("event_type", string(event.Type)),
+		zap.String("payment_id", pmt.ID().String()),
+		zap.String("payment_status", pmt.Status().String()))
+
+	switch event.Type {
+	case payment.WebhookAuthorized:
+		pmt, err = p.update(ctx, pmt.ID(), func(pmt *payment.Payment) error {
+			if !pmt.IsProcessing() || pmt.IsAuthorized() {
+				return nil // Повторная доставка
+			}
+			return pmt.MarkAsAuthorized(event.ExternalID)
+		})
+		// Списываем и при повторной доставке: прошлый Capture мог не пройти,
+		// а провайдер повторяет вебхук, пока мы отвечаем ошибкой
+		if err == nil && pmt.IsAuthorized() && !p.config.ManualCapture {
+			pmt, err = p.capture(ctx, gateway, pmt, attemptOf(pmt))
+		}
+	case payment.WebhookSucceeded:
+		_, err = p.update(ctx, pmt.ID(), func(pmt *payment.Payment) error {
+			if pmt.IsSuccessful() || pmt.IsRefunded() {
+				return nil
+			}
+			if !pmt.IsProcessing() {
+				// Покупатель оплатил уже отмененный платеж - деньги нужно вернуть вручную
+				return fmt.Errorf("payment %s succeeded at provider in status %s", pmt.ID(), pmt.Status())
+			}
+			return pmt.MarkAsSucceeded(event.ExternalID)
+		})
+	case payment.WebhookFailed:
+		_, err = p.update(ctx, pmt.ID(), func(pmt *payment.Payment) error {
+			if !pmt.IsProcessing() {
+				return nil
+			}
+			return pmt.MarkAsFailed(event.Reason)
+		})
+	}
+	if err != nil {
+		p.logger.Error("Payment webhook failed",
+			zap.String("event_id", event.ID),
+			zap.String("external_id", event.ExternalID),
+			zap.Error(err))
+	}
+	return err
+}
+
+// 🔧 ВНУТРЕННИЕ МЕТОДЫ
+
+// attemptStage на каком этапе попытка оплаты, найденная startAttempt
+type attemptStage int
+
+const (
+	stageNew        attemptStage = iota // нужна авторизация
+	stageAuthorized                     // средства заблокированы, нужно списание
+	stageAwaiting                       // ждем вебхук с итогом
+	stageSucceeded                      // заказ уже оплачен
+)
+
+// startAttempt возвращает успешный или незавершенный платеж заказа либо
+// создает новую попытку оплаты
+func (p *Processor) startAttempt(ctx context.Context, ord *order.Order, method payment.Method, provider payment.Provider) (*payment.Payment, int, attemptStage, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	existing, err := p.payments.GetByOrderID(ctx, ord.ID())
+	if err != nil {
+		return nil, 0, stageNew, err
+	}
+	for _, pmt := range existing {
+		switch {
+		case pmt.IsSuccessful():
+			return pmt, attemptOf(pmt), stageSucceeded, nil
+		case !pmt.IsProcessing() || pmt.Method() != method:
+			continue
+		case pmt.IsAuthorized():
+			return pmt, attemptOf(pmt), stageAuthorized, nil
+		case pmt.ExternalID() != "":
+			return pmt, attemptOf(pmt), stageAwaiting, nil
+		default:
+			// Authorize оборвался: повторяем с тем же ключом
+			return pmt, attemptOf(pmt), stageNew, nil
+		}
+	}
+
+	attempt := len(existing)
+	total := ord.TotalAmount()
+	pmt, err := payment.NewPayment(ord.ID(), ord.CustomerID(),
+		payment.NewMoney(total.Amount(), total.Currency()), method, provider)
+	if err != nil {
+		return nil, 0, stageNew, err
+	}
+	pmt.SetMetadata("attempt", strconv.Itoa(attempt))
+	if err := pmt.MarkAsProcessing(); err != nil {
+		return nil, 0, stageNew, err
+	}
+	if err := p.payments.Save(ctx, pmt); err != nil {
+		return nil, 0, stageNew, err
+	}
+	return pmt, attempt, stageNew, nil
+}
+
+// applyAuthorization фиксирует ответ провайдера на авторизацию
+func (p *Processor) applyAuthorization(ctx context.Context, pmt *payment.Payment, authorization *payment.Authorization) (*payment.Payment, error) {
+	return p.update(ctx, pmt.ID(), func(pmt *payment.Payment) error {
+		if !pmt.IsProcessing() {
+			return nil // Вебхук пришел раньше ответа на Authorize
+		}
+
+		switch authorization.Status {
+		case payment.AuthorizationApproved:
+			if err := pmt.MarkAsAuthorized(authorization.ExternalID); err != nil {
+				return err
+			}
+		case payment.AuthorizationCaptured:
+			if err := pmt.MarkAsSucceeded(authorization.ExternalID); err != nil {
+				return err
+			}
+		case payment.AuthorizationPending:
+			if err := pmt.AttachExternalID(authorization.ExternalID); err != nil {
+				return err
+			}
+			if authorization.QRPayload != "" {
+				pmt.SetMetadata("qr_payload", authorization.QRPayload)
+			}
+			if authorization.RedirectURL != "" {
+				pmt.SetMetadata("redirect_url", authorization.RedirectURL)
+			}
+			if authorization.ExpiresAt != nil {
+				pmt.SetMetadata("expires_at", authorization.ExpiresAt.Format(time.RFC3339))
+			}
+			return nil
+		case payment.AuthorizationDeclined:
+			return pmt.MarkAsFailed(authorization.DeclineReason)
+		default:
+			return fmt.Errorf("unknown authorization status %q", authorization.Status)
+		}
+
+		if authorization.CardLast4 != "" {
+			if err := pmt.SetCardInfo(authorization.CardLast4, authorization.CardBrand); err != nil {
+				return err
+			}
+		}
+		if authorization.Fee.Amount() > 0 {
+			return pmt.SetFee(authorization.Fee)
+		}
+		return nil
+	})
+}
+
+// capture списывает авторизованную сумму
+func (p *Processor) capture(ctx context.Context, gateway payment.PaymentGateway, pmt *payment.Payment, attempt int) (*payment.Payment, error) {
+	key := payment.IdempotencyKey(pmt.OrderID(), "capture", attempt)
+	if err := gateway.Capture(ctx, pmt.ExternalID(), pmt.Amount(), key); err != nil {
+		return pmt, fmt.Errorf("capture payment %s: %w", pmt.ID(), err)
+	}
+
+	return p.update(ctx, pmt.ID(), func(pmt *payment.Payment) error {
+		if pmt.IsSuccessful() {
+			return nil
+		}
+		return pmt.MarkAsSucceeded(pmt.ExternalID())
+	})
+}
+
+// void отменяет платеж у провайдера и возвращает его актуальное состояние
+func (p *Processor) void(ctx context.Context, paymentID uuid.UUID, reason string) (*payment.Payment, error) {
+	pmt, err := p.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !pmt.IsProcessing() {
+		return pmt, fmt.Errorf("payment %s cannot be voided in status %s", paymentID, pmt.Status())
+	}
+
+	if pmt.ExternalID() != "" {
+		gateway, err := p.providerGateway(pmt.Provider())
+		if err != nil {
+			return pmt, err
+		}
+		key := payment.IdempotencyKey(pmt.OrderID(), "void", attemptOf(pmt))
+		if err := gateway.Void(ctx, pmt.ExternalID(), key); err != nil {
+			return pmt, fmt.Errorf("void payment %s: %w", paymentID, err)
+		}
+	}
+
+	return p.update(ctx, paymentID, func(pmt *payment.Payment) error {
+		if !pmt.IsProcessing() {
+			return nil // Вебхук успел завершить платеж
+		}
+		return pmt.Void(reason)
+	})
+}
+
+// awaitConfirmation ждет вебхук с итогом платежа не дольше ConfirmationTimeout
+//
+// Вебхук меняет платеж, сохраненный в репозитории, а не объект pmt,
+// поэтому после каждого пробуждения платеж перечитывается.
+func (p *Processor) awaitConfirmation(ctx context.Context, gateway payment.PaymentGateway, pmt *payment.Payment) (*payment.Payment, error) {
+	if p.config.ConfirmationTimeout <= 0 {
+		return pmt, nil
+	}
+
+	timer := time.NewTimer(p.config.ConfirmationTimeout)
+	defer timer.Stop()
+
+	var (
+		status     payment.Status
+		authorized bool
+		reason     string
+	)
+	for {
+		p.mu.Lock()
+		current, err := p.payments.GetByID(ctx, pmt.ID())
+		if err != nil {
+			p.mu.Unlock()
+			return pmt, err
+		}
+		pmt = current
+		status, authorized = pmt.Status(), pmt.IsAuthorized()
+		reason, _ = pmt.GetMetadata("failure_reason")
+		if status != payment.StatusProcessing || authorized {
+			p.mu.Unlock()
+			break
+		}
+		settled := make(chan struct{})
+		p.waiters[pmt.ID()] = append(p.waiters[pmt.ID()], settled)
+		p.mu.Unlock()
+
+		select {
+		case <-settled:
+			continue
+		case <-ctx.Done():
+			return pmt, ctx.Err()
+		case <-timer.C:
+			// Покупатель не подтвердил оплату: отменяем QR / авторизацию
+			voided, err := p.void(ctx, pmt.ID(), "confirmation timeout")
+			if err != nil {
+				return voided, err
+			}
+			return voided, fmt.Errorf("payment %s was not confirmed in %s", pmt.ID(), p.config.ConfirmationTimeout)
+		}
+	}
+
+	switch {
+	case authorized && p.config.ManualCapture:
+		return pmt, nil
+	case authorized:
+		// Вебхук payment.authorized тоже списывает средства; capture с тем же
+		// ключом идемпотентен, поэтому двойного списания не будет
+		return p.capture(ctx, gateway, pmt, attemptOf(pmt))
+	case status == payment.StatusFailed:
+		return pmt, fmt.Errorf("%w: %s", payment.ErrPaymentDeclined, reason)
+	case status != payment.StatusSucceeded:
+		return pmt, fmt.Errorf("payment %s finished with status %s", pmt.ID(), status)
+	}
+	return pmt, nil
+}
+
+// update перечитывает платеж под блокировкой, применяет изменение,
+// сохраняет платеж и будит ожидающих подтверждения
+//
+// Возвращает актуальное состояние платежа (и при ошибке изменения).
+func (p *Processor) update(ctx context.Context, paymentID uuid.UUID, change func(*payment.Payment) error) (*payment.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pmt, err := p.payments.GetByID(ctx, paymentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(pmt); err != nil {
+		return pmt, err
+	}
+	if err := p.payments.Save(ctx, pmt); err != nil {
+		return pmt, err
+	}
+
+	for _, settled := range p.waiters[paymentID] {
+		close(settled)
+	}
+	delete(p.waiters, paymentID)
+	return pmt, nil
+}
+
+func (p *Processor) gateway(method payment.Method) (payment.PaymentGateway, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	gateway, ok := p.gateways[method]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", payment.ErrMethodNotSupported, method)
+	}
+	return gateway, nil
+}
+
+func (p *Processor) providerGateway(provider payment.Provider) (payment.PaymentGateway, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	gateway, ok := p.byProvider[provider]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", payment.ErrProviderUnknown, provider)
+	}
+	return gateway, nil
+}
+
+// attemptOf номер попытки оплаты, с которым выводились ключи идемпотентности
+func attemptOf(pmt *payment.Payment) int {
+	value, _ := pmt.GetMetadata("attempt")
+	attempt, _ := strconv.Atoi(value)
+	return attempt
+}
@@ -0,0 +1,324 @@
+package paymentservice
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/application/pipeline"
+	"pipeline-clean-architecture/internal/domain/payment"
+	"pipeline-clean-architecture/internal/infrastructure/paymentgateway"
+	"pipeline-clean-architecture/internal/infrastructure/paymentstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore/sqlstoretest"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fixture процессор с картами и СБП поверх фейкового провайдера
+type fixture struct {
+	processor  *Processor
+	provider   *paymentgateway.FakeServer
+	orders     *sqlstore.OrderRepository
+	payments   *copyingRepository
+	webhookURL string
+}
+
+// copyingRepository отдает и сохраняет копии платежей, как SQL хранилище:
+// изменения, не прошедшие через Save, не видны другим читателям
+type copyingRepository struct {
+	*paymentstore.MemoryRepository
+}
+
+func (r *copyingRepository) Save(ctx context.Context, pmt *payment.Payment) error {
+	saved := *pmt
+	return r.MemoryRepository.Save(ctx, &saved)
+}
+
+func (r *copyingRepository) GetByID(ctx context.Context, id uuid.UUID) (*payment.Payment, error) {
+	return copyPayment(r.MemoryRepository.GetByID(ctx, id))
+}
+
+func (r *copyingRepository) GetByExternalID(ctx context.Context, provider payment.Provider, externalID string) (*payment.Payment, error) {
+	return copyPayment(r.MemoryRepository.GetByExternalID(ctx, provider, externalID))
+}
+
+func (r *copyingRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*payment.Payment, error) {
+	payments, err := r.MemoryRepository.GetByOrderID(ctx, orderID)
+	for i, pmt := range payments {
+		payments[i], _ = copyPayment(pmt, nil)
+	}
+	return payments, err
+}
+
+func copyPayment(pmt *payment.Payment, err error) (*payment.Payment, error) {
+	if pmt == nil {
+		return nil, err
+	}
+	c := *pmt
+	return &c, err
+}
+
+// flakyCapture адаптер, у которого первые failures списаний падают
+type flakyCapture struct {
+	payment.PaymentGateway
+	failures int
+}
+
+func (g *flakyCapture) Capture(ctx context.Context, externalID string, amount payment.Money, idempotencyKey string) error {
+	if g.failures > 0 {
+		g.failures--
+		return errors.New("provider unavailable")
+	}
+	return g.PaymentGateway.Capture(ctx, externalID, amount, idempotencyKey)
+}
+
+func newFixture(t *testing.T, config Config) *fixture {
+	t.Helper()
+
+	orders := sqlstoretest.NewOrderRepository(t)
+	provider := paymentgateway.NewFakeServer("whsec_test")
+	t.Cleanup(provider.Close)
+
+	payments := &copyingRepository{paymentstore.NewMemoryRepository()}
+	processor := NewProcessor(zap.NewNop(), orders, payments, config)
+	gatewayConfig := paymentgateway.Config{BaseURL: provider.URL(), APIKey: "sk_test", WebhookSecret: "whsec_test"}
+	processor.RegisterGateway(paymentgateway.NewCardGateway(gatewayConfig), payment.MethodCard)
+	processor.RegisterGateway(paymentgateway.NewSBPGateway(gatewayConfig), payment.MethodSBP)
+
+	// Вебхуки провайдера доставляются прямо в процессор
+	webhooks := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		payload, _ := io.ReadAll(r.Body)
+		provider := payment.Provider(r.URL.Path[1:])
+		if err := processor.HandleWebhook(r.Context(), provider, payload, r.Header.Get(paymentgateway.SignatureHeader)); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(webhooks.Close)
+
+	f := &fixture{processor: processor, provider: provider, orders: orders, payments: payments, webhookURL: webhooks.URL}
+	f.setWebhookProvider(payment.ProviderSberbank)
+	return f
+}
+
+// setWebhookProvider направляет вебхуки фейкового провайдера от имени provider
+func (f *fixture) setWebhookProvider(provider payment.Provider) {
+	f.provider.SetWebhookURL(f.webhookURL + "/" + provider.String())
+}
+
+// externalID ждет, пока платеж заказа получит ID у провайдера
+func (f *fixture) externalID(t *testing.T, orderID uuid.UUID) string {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		payments, err := f.payments.GetByOrderID(context.Background(), orderID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(payments) > 0 && payments[0].ExternalID() != "" {
+			return payments[0].ExternalID()
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("order %s has no authorized payment", orderID)
+	return ""
+}
+
+func (f *fixture) createOrder(t *testing.T) uuid.UUID {
+	t.Helper()
+
+	ord := sqlstoretest.NewOrder(t, uuid.New(), sqlstoretest.NewItem(t, uuid.New(), 2, 50000))
+	if err := f.orders.Save(context.Background(), ord); err != nil {
+		t.Fatal(err)
+	}
+	return ord.ID()
+}
+
+func (f *fixture) pay(ctx context.Context, orderID uuid.UUID, method payment.Method, token string) (*payment.Payment, error) {
+	return f.processor.ProcessPaymentRequest(ctx, pipeline.PaymentRequest{OrderID: orderID, Method: method, CardToken: token})
+}
+
+func TestCardPaymentIsAuthorizedAndCapturedOnce(t *testing.T) {
+	f := newFixture(t, Config{})
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+
+	pmt, err := f.pay(ctx, orderID, payment.MethodCard, "tok_visa")
+	if err != nil {
+		t.Fatalf("ProcessPaymentRequest() error = %v", err)
+	}
+	if !pmt.IsSuccessful() || pmt.Amount().Amount() != 100000 || pmt.CardLast4() != "4242" || pmt.Fee().Amount() != 2000 {
+		t.Errorf("payment = %s %s card %s fee %s, want succeeded 1000 RUB", pmt.Status(), pmt.Amount(), pmt.CardLast4(), pmt.Fee())
+	}
+
+	// Повтор шага пайплайна не списывает деньги повторно
+	again, err := f.pay(ctx, orderID, payment.MethodCard, "tok_visa")
+	if err != nil {
+		t.Fatalf("repeated ProcessPaymentRequest() error = %v", err)
+	}
+	if again.ID() != pmt.ID() || f.provider.Calls("authorize") != 1 || f.provider.Calls("capture") != 1 {
+		t.Errorf("repeat returned %s with %d authorizations, %d captures; want %s, 1, 1",
+			again.ID(), f.provider.Calls("authorize"), f.provider.Calls("capture"), pmt.ID())
+	}
+
+	if err := f.processor.RefundPayment(ctx, pmt.ID(), "order pipeline failed"); err != nil {
+		t.Fatalf("RefundPayment() error = %v", err)
+	}
+	refunded, _ := f.payments.GetByID(ctx, pmt.ID())
+	state, _ := f.provider.Payment(pmt.ExternalID())
+	if refunded.Status() != payment.StatusRefunded || state.Refunded != 100000 {
+		t.Errorf("after refund: payment %s, provider refunded %d", refunded.Status(), state.Refunded)
+	}
+}
+
+func TestDeclinedCardStartsNewAttempt(t *testing.T) {
+	f := newFixture(t, Config{})
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+
+	declined, err := f.pay(ctx, orderID, payment.MethodCard, "tok_declined")
+	if !errors.Is(err, payment.ErrPaymentDeclined) {
+		t.Fatalf("ProcessPaymentRequest() error = %v, want ErrPaymentDeclined", err)
+	}
+	if !declined.IsFailed() {
+		t.Errorf("declined payment status = %s, want failed", declined.Status())
+	}
+
+	pmt, err := f.pay(ctx, orderID, payment.MethodCard, "tok_visa")
+	if err != nil {
+		t.Fatalf("second attempt error = %v", err)
+	}
+	if pmt.ID() == declined.ID() || !pmt.IsSuccessful() || f.provider.Calls("authorize") != 2 {
+		t.Errorf("second attempt %s (%s), %d authorizations; want a new succeeded payment",
+			pmt.ID(), pmt.Status(), f.provider.Calls("authorize"))
+	}
+}
+
+func TestSBPPaymentIsSettledByWebhook(t *testing.T) {
+	f := newFixture(t, Config{})
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+	f.setWebhookProvider(payment.ProviderSBP)
+
+	pmt, err := f.pay(ctx, orderID, payment.MethodSBP, "")
+	if err != nil {
+		t.Fatalf("ProcessPaymentRequest() error = %v", err)
+	}
+	qr, _ := pmt.GetMetadata("qr_payload")
+	if !pmt.IsProcessing() || qr == "" {
+		t.Fatalf("payment = %s with QR %q, want processing with QR payload", pmt.Status(), qr)
+	}
+
+	if err := f.provider.Complete(ctx, pmt.ExternalID()); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+	// Повторная доставка вебхука ничего не меняет
+	if err := f.provider.SendWebhook(ctx, "payment.succeeded", pmt.ExternalID(), ""); err != nil {
+		t.Fatalf("duplicate webhook error = %v", err)
+	}
+
+	settled, err := f.pay(ctx, orderID, payment.MethodSBP, "")
+	if err != nil {
+		t.Fatalf("ProcessPaymentRequest() after webhook error = %v", err)
+	}
+	if settled.ID() != pmt.ID() || !settled.IsSuccessful() || f.provider.Calls("authorize") != 1 {
+		t.Errorf("after webhook: %s %s, %d QR codes; want the same succeeded payment",
+			settled.ID(), settled.Status(), f.provider.Calls("authorize"))
+	}
+}
+
+func TestThreeDSecureWaitsForConfirmation(t *testing.T) {
+	f := newFixture(t, Config{ConfirmationTimeout: 5 * time.Second})
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+
+	type result struct {
+		pmt *payment.Payment
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		pmt, err := f.pay(ctx, orderID, payment.MethodCard, "tok_3ds")
+		done <- result{pmt, err}
+	}()
+
+	// Покупатель проходит 3-D Secure, провайдер присылает payment.authorized
+	externalID := f.externalID(t, orderID)
+	if err := f.provider.Complete(ctx, externalID); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	res := <-done
+	if res.err != nil {
+		t.Fatalf("ProcessPaymentRequest() error = %v", res.err)
+	}
+	if !res.pmt.IsSuccessful() || f.provider.Calls("capture") != 1 {
+		t.Errorf("payment %s with %d captures, want succeeded with one capture", res.pmt.Status(), f.provider.Calls("capture"))
+	}
+}
+
+func TestAuthorizedWebhookRetriesFailedCapture(t *testing.T) {
+	f := newFixture(t, Config{})
+	ctx := context.Background()
+	orderID := f.createOrder(t)
+
+	// Первое списание после вебхука не проходит
+	gatewayConfig := paymentgateway.Config{BaseURL: f.provider.URL(), APIKey: "sk_test", WebhookSecret: "whsec_test"}
+	f.processor.RegisterGateway(&flakyCapture{PaymentGateway: paymentgateway.NewCardGateway(gatewayConfig), failures: 1}, payment.MethodCard)
+
+	pmt, err := f.pay(ctx, orderID, payment.MethodCard, "tok_3ds")
+	if err != nil || !pmt.IsProcessing() {
+		t.Fatalf("ProcessPaymentRequest() = %v, %v; want processing payment", pmt, err)
+	}
+	if err := f.provider.Complete(ctx, pmt.ExternalID()); err == nil {
+		t.Fatal("payment.authorized with failed capture was acknowledged")
+	}
+	if authorized, _ := f.payments.GetByID(ctx, pmt.ID()); !authorized.IsAuthorized() {
+		t.Fatalf("payment %s is not authorized after webhook", authorized.Status())
+	}
+
+	// Провайдер повторяет вебхук - списание повторяется
+	if err := f.provider.SendWebhook(ctx, "payment.authorized", pmt.ExternalID(), ""); err != nil {
+		t.Fatalf("redelivered webhook error = %v", err)
+	}
+	settled, _ := f.payments.GetByID(ctx, pmt.ID())
+	if !settled.IsSuccessful() || f.provider.Calls("capture") != 1 {
+		t.Errorf("after redelivery: payment %s with %d captures, want succeeded with one capture",
+			settled.Status(), f.provider.Calls("capture"))
+	}
+}
+
+func TestUnconfirmedPaymentIsVoided(t *testing.T) {
+	f := newFixture(t, Config{ConfirmationTimeout: 50 * time.Millisecond})
+	orderID := f.createOrder(t)
+
+	pmt, err := f.pay(context.Background(), orderID, payment.MethodSBP, "")
+	if err == nil {
+		t.Fatal("ProcessPaymentRequest() error = nil, want confirmation timeout")
+	}
+	state, _ := f.provider.Payment(pmt.ExternalID())
+	if pmt.Status() != payment.StatusCancelled || state.Status != "voided" {
+		t.Errorf("payment %s, provider %s; want cancelled and voided", pmt.Status(), state.Status)
+	}
+}
+
+func TestWebhookWithInvalidSignatureIsRejected(t *testing.T) {
+	f := newFixture(t, Config{})
+
+	err := f.processor.HandleWebhook(context.Background(), payment.ProviderSberbank,
+		[]byte(`{"type":"payment.succeeded","object_id":"card_1"}`), "deadbeef")
+	if !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Errorf("HandleWebhook() error = %v, want ErrInvalidSignature", err)
+	}
+
+	err = f.processor.HandleWebhook(context.Background(), payment.ProviderPayPal, nil, "")
+	if !errors.Is(err, payment.ErrProviderUnknown) {
+		t.Errorf("HandleWebhook(paypal) error = %v, want ErrProviderUnknown", err)
+	}
+}
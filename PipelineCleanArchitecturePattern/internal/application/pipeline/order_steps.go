@@ -8,6 +8,7 @@ import (
 
 	"pipeline-clean-architecture/internal/domain/order"
 	"pipeline-clean-architecture/internal/domain/payment"
+	"pipeline-clean-architecture/internal/domain/product"
 	"pipeline-clean-architecture/pkg/pipeline"
 
 	"github.com/google/uuid"
@@ -59,6 +60,27 @@ import (
 // Step 4 (Notifications) → { notifications_sent }
 //
 // ============================================================================
+// КОМПЕНСАЦИЯ (SAGA):
+// ============================================================================
+//
+// Если пайплайн падает, движок вызывает Compensate у завершенных шагов
+// в обратном порядке:
+//
+// - CheckInventoryStep → освобождает резервирование (reservation_id)
+// - ProcessPaymentStep → возвращает платеж (payment_id), заказ → refunded,
+//   лимиты примененных скидок освобождаются (SetPromotionReleaser)
+//
+// ============================================================================
+// ПОВТОР ПОСЛЕ СБОЯ (Engine.Resume):
+// ============================================================================
+//
+// Процесс может упасть после того, как шаг сохранил заказ, но до checkpoint'а
+// движка - тогда Resume выполнит шаг снова. Шаги оплаты и склада узнают свое
+// незавершенное выполнение по статусу заказа и не переводят его повторно,
+// а платеж и резерв получают у идемпотентных сервисов
+// (PaymentRequestProcessor, OrderInventoryReserver).
+//
+// ============================================================================
 
 // ============================================================================
 // ШАГ 1: ВАЛИДАЦИЯ ЗАКАЗА
@@ -103,6 +125,22 @@ type ProductService interface {
 type PaymentService interface {
 	ProcessPayment(ctx context.Context, orderID uuid.UUID, method payment.Method) (*payment.Payment, error)
 	ValidatePaymentMethod(method payment.Method) error
+	RefundPayment(ctx context.Context, paymentID uuid.UUID, reason string) error
+}
+
+// PaymentRequestProcessor опциональное расширение PaymentService: оплата с
+// реквизитами платежной формы. Шаг оплаты использует его, если сервис его
+// реализует, иначе вызывает ProcessPayment только со способом оплаты.
+type PaymentRequestProcessor interface {
+	ProcessPaymentRequest(ctx context.Context, req PaymentRequest) (*payment.Payment, error)
+}
+
+// PaymentRequest реквизиты оплаты заказа
+type PaymentRequest struct {
+	OrderID   uuid.UUID
+	Method    payment.Method
+	CardToken string // метаданные шага "card_token"
+	ReturnURL string // метаданные шага "return_url"
 }
 
 // InventoryService интерфейс для работы со складом
@@ -112,6 +150,25 @@ type InventoryService interface {
 	ReleaseReservation(ctx context.Context, reservationID uuid.UUID) error
 }
 
+// OrderInventoryReserver опциональное расширение InventoryService: резерв,
+// привязанный к заказу. Такой резерв по истечении TTL снимается, только если
+// заказ не оплачен. Шаг склада использует его, если сервис его реализует.
+type OrderInventoryReserver interface {
+	ReserveOrderItems(ctx context.Context, orderID uuid.UUID, items []ReservationItem) (*Reservation, error)
+}
+
+// PromotionReleaser освобождает лимиты скидок заказа, который вернули или отменили
+type PromotionReleaser interface {
+	ReleasePromotions(ctx context.Context, orderID uuid.UUID) error
+}
+
+// ReservationConfirmer опциональное расширение InventoryService: резерв
+// оплаченного заказа закрепляется и больше не снимается по TTL.
+// Шаг склада подтверждает резерв сразу: заказ к этому моменту оплачен.
+type ReservationConfirmer interface {
+	ConfirmReservation(ctx context.Context, reservationID uuid.UUID) error
+}
+
 // NotificationService интерфейс для уведомлений
 type NotificationService interface {
 	SendEmail(ctx context.Context, to, subject, body string) error
@@ -230,10 +287,11 @@ func (s *ValidateOrderStep) Execute(ctx context.Context, data *pipeline.StepData
 			continue
 		}
 		
-		if currentPrice.Amount() != item.UnitPrice().Amount() {
+		// Сравниваем с ценой каталога: у пересчитанных позиций она в другой валюте
+		if !currentPrice.Equal(item.CatalogPrice()) {
 			validationErrors = append(validationErrors, 
-				fmt.Sprintf("price for product %s has changed from %d to %d", 
-					item.ProductID().String(), item.UnitPrice().Amount(), currentPrice.Amount()))
+				fmt.Sprintf("price for product %s has changed from %s to %s", 
+					item.ProductID().String(), item.CatalogPrice(), currentPrice))
 		}
 	}
 	
@@ -328,6 +386,7 @@ type ProcessPaymentStep struct {
 	logger         *zap.Logger
 	orderRepo      order.Repository
 	paymentService PaymentService
+	promotions     PromotionReleaser
 }
 
 // NewProcessPaymentStep создает новый шаг обработки платежа
@@ -339,6 +398,11 @@ func NewProcessPaymentStep(logger *zap.Logger, orderRepo order.Repository, payme
 	}
 }
 
+// SetPromotionReleaser подключает освобождение лимитов скидок при возврате платежа
+func (s *ProcessPaymentStep) SetPromotionReleaser(promotions PromotionReleaser) {
+	s.promotions = promotions
+}
+
 // Execute выполняет обработку платежа
 func (s *ProcessPaymentStep) Execute(ctx context.Context, data *pipeline.StepData) (*pipeline.StepResult, error) {
 	s.logger.Info("Processing payment", zap.String("execution_id", data.ID))
@@ -389,18 +453,45 @@ func (s *ProcessPaymentStep) Execute(ctx context.Context, data *pipeline.StepDat
 		return nil, fmt.Errorf("invalid payment method: %w", err)
 	}
 	
-	// Обновляем статус заказа
-	if err := ord.MarkAsPaymentProcessing(); err != nil {
-		return nil, fmt.Errorf("failed to mark order as payment processing: %w", err)
-	}
-	
-	// Сохраняем заказ
-	if err := s.orderRepo.Save(ctx, ord); err != nil {
-		return nil, fmt.Errorf("failed to save order: %w", err)
+	// Заказ уже в оплате или оплачен - процесс упал внутри шага до checkpoint'а,
+	// и Resume выполняет шаг снова. Статус не переводится повторно, а
+	// идемпотентный ProcessPaymentRequest вернет уже созданный платеж
+	resumed := ord.Status() == order.StatusPaymentProcessing || ord.Status() == order.StatusPaid
+	if resumed {
+		s.logger.Info("Resuming payment of order",
+			zap.String("order_id", orderID.String()),
+			zap.String("order_status", ord.Status().String()))
+	} else {
+		// Обновляем статус заказа
+		if err := ord.MarkAsPaymentProcessing(); err != nil {
+			return nil, fmt.Errorf("failed to mark order as payment processing: %w", err)
+		}
+		
+		// Сохраняем заказ
+		if err := s.orderRepo.Save(ctx, ord); err != nil {
+			return nil, fmt.Errorf("failed to save order: %w", err)
+		}
 	}
 	
 	// Обрабатываем платеж
-	pmt, err := s.paymentService.ProcessPayment(ctx, orderID, paymentMethod)
+	pmt, err := s.processPayment(ctx, orderID, paymentMethod, data.Metadata)
+	if err == nil && pmt.IsProcessing() {
+		// Нужно действие покупателя (QR СБП, 3-D Secure): заказ остается
+		// неоплаченным, повтор шага после вебхука вернет успешный платеж
+		ord.MarkAsValidated()
+		s.orderRepo.Save(ctx, ord)
+		
+		return &pipeline.StepResult{
+			Step:        "process_payment",
+			Success:     false,
+			StartedAt:   startTime,
+			CompletedAt: time.Now(),
+			Duration:    time.Since(startTime),
+			Output:      pendingPaymentOutput(orderID, pmt),
+			Error:       fmt.Errorf("payment %s awaits customer confirmation", pmt.ID()),
+			Retryable:   false,
+		}, nil
+	}
 	if err != nil {
 		// Возвращаем заказ в предыдущий статус при ошибке
 		ord.MarkAsValidated()
@@ -418,18 +509,20 @@ func (s *ProcessPaymentStep) Execute(ctx context.Context, data *pipeline.StepDat
 				"error_message":  err.Error(),
 			},
 			Error:     fmt.Errorf("payment processing failed: %w", err),
-			Retryable: true, // Можно повторить обработку платежа
+			Retryable: !errors.Is(err, payment.ErrPaymentDeclined), // Отказ банка повтором не исправить
 		}, nil
 	}
 	
-	// Обновляем статус заказа на оплаченный
-	if err := ord.MarkAsPaid(); err != nil {
-		return nil, fmt.Errorf("failed to mark order as paid: %w", err)
-	}
-	
-	// Сохраняем заказ
-	if err := s.orderRepo.Save(ctx, ord); err != nil {
-		return nil, fmt.Errorf("failed to save paid order: %w", err)
+	// Обновляем статус заказа на оплаченный (если не успели до сбоя)
+	if ord.Status() != order.StatusPaid {
+		if err := ord.MarkAsPaid(); err != nil {
+			return nil, fmt.Errorf("failed to mark order as paid: %w", err)
+		}
+		
+		// Сохраняем заказ
+		if err := s.orderRepo.Save(ctx, ord); err != nil {
+			return nil, fmt.Errorf("failed to save paid order: %w", err)
+		}
 	}
 	
 	// Создаем результат
@@ -462,6 +555,40 @@ func (s *ProcessPaymentStep) Execute(ctx context.Context, data *pipeline.StepDat
 	return result, nil
 }
 
+// processPayment передает реквизиты платежной формы, если сервис их принимает
+func (s *ProcessPaymentStep) processPayment(ctx context.Context, orderID uuid.UUID, method payment.Method, metadata map[string]string) (*payment.Payment, error) {
+	if processor, ok := s.paymentService.(PaymentRequestProcessor); ok {
+		return processor.ProcessPaymentRequest(ctx, PaymentRequest{
+			OrderID:   orderID,
+			Method:    method,
+			CardToken: metadata["card_token"],
+			ReturnURL: metadata["return_url"],
+		})
+	}
+	return s.paymentService.ProcessPayment(ctx, orderID, method)
+}
+
+// pendingPaymentOutput выход шага для платежа, ждущего покупателя
+func pendingPaymentOutput(orderID uuid.UUID, pmt *payment.Payment) map[string]interface{} {
+	output := map[string]interface{}{
+		"order_id":        orderID.String(),
+		"payment_id":      pmt.ID().String(),
+		"payment_status":  pmt.Status().String(),
+		"payment_method":  pmt.Method().String(),
+		"payment_pending": true,
+	}
+	for key, metadataKey := range map[string]string{
+		"qr_code_data": "qr_payload",
+		"redirect_url": "redirect_url",
+		"expires_at":   "expires_at",
+	} {
+		if value, ok := pmt.GetMetadata(metadataKey); ok {
+			output[key] = value
+		}
+	}
+	return output
+}
+
 // Name возвращает название шага
 func (s *ProcessPaymentStep) Name() string {
 	return "process_payment"
@@ -494,6 +621,60 @@ func (s *ProcessPaymentStep) CanRetry(err error) bool {
 	return true
 }
 
+// Compensate возвращает платеж, если пайплайн упал после оплаты
+func (s *ProcessPaymentStep) Compensate(ctx context.Context, data *pipeline.StepData, result *pipeline.StepResult) error {
+	if result == nil || !result.Success {
+		return nil // Платеж не проводился - возвращать нечего
+	}
+	
+	paymentIDStr, ok := result.Output["payment_id"].(string)
+	if !ok {
+		return errors.New("payment_id is missing in step result")
+	}
+	
+	paymentID, err := uuid.Parse(paymentIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid payment_id: %w", err)
+	}
+	
+	orderIDStr, _ := result.Output["order_id"].(string)
+	s.logger.Info("Refunding payment",
+		zap.String("execution_id", data.ID),
+		zap.String("order_id", orderIDStr),
+		zap.String("payment_id", paymentIDStr))
+	
+	if err := s.paymentService.RefundPayment(ctx, paymentID, "order pipeline failed"); err != nil {
+		return fmt.Errorf("failed to refund payment %s: %w", paymentIDStr, err)
+	}
+	
+	orderID, err := uuid.Parse(orderIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid order_id: %w", err)
+	}
+	
+	ord, err := s.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+	
+	if err := ord.MarkAsRefunded(); err != nil {
+		return fmt.Errorf("failed to mark order as refunded: %w", err)
+	}
+	
+	if err := s.orderRepo.Save(ctx, ord); err != nil {
+		return err
+	}
+	
+	// Возвращенный заказ не должен расходовать лимит скидок покупателя
+	if s.promotions != nil {
+		if err := s.promotions.ReleasePromotions(ctx, orderID); err != nil {
+			return fmt.Errorf("failed to release promotions of order %s: %w", orderIDStr, err)
+		}
+	}
+	
+	return nil
+}
+
 // 🔄 STEP 3: CHECK INVENTORY
 
 // CheckInventoryStep шаг проверки склада
@@ -549,12 +730,28 @@ func (s *CheckInventoryStep) Execute(ctx context.Context, data *pipeline.StepDat
 		return nil, fmt.Errorf("failed to get order: %w", err)
 	}
 	
+	// Заказ уже проверен по складу - процесс упал внутри шага до checkpoint'а.
+	// Остаток уже списан под его резерв, поэтому наличие не проверяется
+	// заново: ReserveOrderItems вернет существующий резерв
+	resumed := ord.Status() == order.StatusInventoryChecked
+	if resumed {
+		s.logger.Info("Resuming inventory check of order", zap.String("order_id", orderID.String()))
+	}
+	
 	// Проверяем наличие всех товаров
 	allAvailable := true
 	unavailableItems := make([]string, 0)
 	reservationItems := make([]ReservationItem, 0)
 	
 	for _, item := range ord.Items() {
+		if resumed {
+			reservationItems = append(reservationItems, ReservationItem{
+				ProductID: item.ProductID(),
+				Quantity:  item.Quantity(),
+			})
+			continue
+		}
+		
 		available, err := s.inventoryService.CheckAvailability(ctx, item.ProductID(), item.Quantity())
 		if err != nil {
 			s.logger.Error("Failed to check inventory", 
@@ -595,23 +792,50 @@ func (s *CheckInventoryStep) Execute(ctx context.Context, data *pipeline.StepDat
 	
 	if allAvailable {
 		// Резервируем товары
-		reservation, err := s.inventoryService.ReserveItems(ctx, reservationItems)
+		var reservation *Reservation
+		if reserver, ok := s.inventoryService.(OrderInventoryReserver); ok {
+			reservation, err = reserver.ReserveOrderItems(ctx, orderID, reservationItems)
+		} else {
+			reservation, err = s.inventoryService.ReserveItems(ctx, reservationItems)
+		}
+		if errors.Is(err, product.ErrInsufficientStock) {
+			// Последние единицы успел зарезервировать другой заказ
+			result.Success = false
+			result.Output["all_available"] = false
+			result.Error = fmt.Errorf("insufficient inventory: %w", err)
+			result.Retryable = false
+			return result, nil
+		}
 		if err != nil {
 			return nil, fmt.Errorf("failed to reserve items: %w", err)
 		}
 		
-		// Обновляем статус заказа
-		if err := ord.MarkAsInventoryChecked(); err != nil {
-			// Освобождаем резервирование при ошибке
-			s.inventoryService.ReleaseReservation(ctx, reservation.ID)
-			return nil, fmt.Errorf("failed to mark order as inventory checked: %w", err)
+		// Обновляем статус заказа (при восстановлении он уже обновлен)
+		if !resumed {
+			if err := ord.MarkAsInventoryChecked(); err != nil {
+				// Освобождаем резервирование при ошибке
+				s.inventoryService.ReleaseReservation(ctx, reservation.ID)
+				return nil, fmt.Errorf("failed to mark order as inventory checked: %w", err)
+			}
+			
+			// Сохраняем заказ
+			if err := s.orderRepo.Save(ctx, ord); err != nil {
+				// Освобождаем резервирование при ошибке
+				s.inventoryService.ReleaseReservation(ctx, reservation.ID)
+				return nil, fmt.Errorf("failed to save order: %w", err)
+			}
 		}
 		
-		// Сохраняем заказ
-		if err := s.orderRepo.Save(ctx, ord); err != nil {
-			// Освобождаем резервирование при ошибке
-			s.inventoryService.ReleaseReservation(ctx, reservation.ID)
-			return nil, fmt.Errorf("failed to save order: %w", err)
+		// Заказ оплачен шагом process_payment - резерв больше не ждет оплаты.
+		// Если подтвердить не удалось, фоновое истечение TTL подтвердит резерв
+		// оплаченного заказа само, поэтому шаг не падает
+		if confirmer, ok := s.inventoryService.(ReservationConfirmer); ok {
+			if err := confirmer.ConfirmReservation(ctx, reservation.ID); err != nil {
+				s.logger.Warn("Failed to confirm reservation of paid order",
+					zap.String("order_id", orderID.String()),
+					zap.String("reservation_id", reservation.ID.String()),
+					zap.Error(err))
+			}
 		}
 		
 		result.Output["reservation_id"] = reservation.ID.String()
@@ -623,7 +847,7 @@ func (s *CheckInventoryStep) Execute(ctx context.Context, data *pipeline.StepDat
 			zap.String("reservation_id", reservation.ID.String()),
 			zap.Duration("duration", result.Duration))
 	} else {
-		result.Error = errors.New("insufficient inventory")
+		result.Error = fmt.Errorf("insufficient inventory: %w", product.ErrInsufficientStock)
 		result.Retryable = false // Не повторяем если товаров нет
 		
 		s.logger.Warn("Inventory check failed - insufficient stock", 
@@ -664,7 +888,34 @@ func (s *CheckInventoryStep) Dependencies() []string {
 // CanRetry определяет, можно ли повторить шаг при ошибке
 func (s *CheckInventoryStep) CanRetry(err error) bool {
 	// Повторяем только технические ошибки
-	return !errors.Is(err, errors.New("insufficient inventory"))
+	return !errors.Is(err, product.ErrInsufficientStock)
+}
+
+// Compensate освобождает резервирование, если пайплайн упал после проверки склада
+func (s *CheckInventoryStep) Compensate(ctx context.Context, data *pipeline.StepData, result *pipeline.StepResult) error {
+	if result == nil {
+		return nil
+	}
+	
+	reservationIDStr, ok := result.Output["reservation_id"].(string)
+	if !ok {
+		return nil // Товары не резервировались
+	}
+	
+	reservationID, err := uuid.Parse(reservationIDStr)
+	if err != nil {
+		return fmt.Errorf("invalid reservation_id: %w", err)
+	}
+	
+	s.logger.Info("Releasing reservation",
+		zap.String("execution_id", data.ID),
+		zap.String("reservation_id", reservationIDStr))
+	
+	if err := s.inventoryService.ReleaseReservation(ctx, reservationID); err != nil {
+		return fmt.Errorf("failed to release reservation %s: %w", reservationIDStr, err)
+	}
+	
+	return nil
 }
 
 // 🔄 STEP 4: SEND NOTIFICATIONS
@@ -0,0 +1,247 @@
+package pipeline_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/application/inventoryservice"
+	"pipeline-clean-architecture/internal/application/pipeline"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/payment"
+	"pipeline-clean-architecture/internal/domain/product"
+	"pipeline-clean-architecture/internal/infrastructure/inventorystore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore/sqlstoretest"
+	pipelineEngine "pipeline-clean-architecture/pkg/pipeline"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// errProcessKilled процесс умер, не успев сохранить checkpoint
+var errProcessKilled = errors.New("process killed")
+
+// catalog товары с одной ценой
+type catalog struct{ price int64 }
+
+func (c catalog) CheckProductAvailability(ctx context.Context, productID uuid.UUID, quantity int) (bool, error) {
+	return true, nil
+}
+
+func (c catalog) GetProductPrice(ctx context.Context, productID uuid.UUID) (order.Money, error) {
+	return order.NewMoney(c.price, "RUB"), nil
+}
+
+// idempotentPayments один платеж на заказ, как paymentservice.Processor
+type idempotentPayments struct {
+	mu       sync.Mutex
+	payments map[uuid.UUID]*payment.Payment
+	charges  int
+}
+
+func (p *idempotentPayments) ProcessPayment(ctx context.Context, orderID uuid.UUID, method payment.Method) (*payment.Payment, error) {
+	return p.ProcessPaymentRequest(ctx, pipeline.PaymentRequest{OrderID: orderID, Method: method})
+}
+
+func (p *idempotentPayments) ProcessPaymentRequest(ctx context.Context, req pipeline.PaymentRequest) (*payment.Payment, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pmt, ok := p.payments[req.OrderID]; ok {
+		return pmt, nil
+	}
+	pmt, err := payment.NewPayment(req.OrderID, uuid.New(), payment.NewMoney(50000, "RUB"), req.Method, payment.ProviderSberbank)
+	if err != nil {
+		return nil, err
+	}
+	if err := pmt.MarkAsProcessing(); err != nil {
+		return nil, err
+	}
+	if err := pmt.MarkAsSucceeded("ext-" + req.OrderID.String()); err != nil {
+		return nil, err
+	}
+	p.payments[req.OrderID] = pmt
+	p.charges++
+	return pmt, nil
+}
+
+func (p *idempotentPayments) ValidatePaymentMethod(method payment.Method) error { return nil }
+
+func (p *idempotentPayments) RefundPayment(ctx context.Context, paymentID uuid.UUID, reason string) error {
+	return errors.New("refund is not expected")
+}
+
+// dyingStore хранилище выполнений, которое после смерти процесса не принимает
+// новых checkpoint'ов: в нем остается состояние на момент сбоя
+type dyingStore struct {
+	*pipelineEngine.MemoryExecutionStore
+	mu   sync.Mutex
+	dead bool
+}
+
+func (s *dyingStore) Save(ctx context.Context, execCtx *pipelineEngine.ExecutionContext) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.dead {
+		return nil
+	}
+	return s.MemoryExecutionStore.Save(ctx, execCtx)
+}
+
+func (s *dyingStore) kill(dead bool) {
+	s.mu.Lock()
+	s.dead = dead
+	s.mu.Unlock()
+}
+
+// doomedStep шаг в процессе, который умрет: выполняет настоящий шаг, а если
+// это crashStep - убивает процесс до сохранения результата. Compensate не
+// прокидывается: умерший процесс ничего не откатывает
+type doomedStep struct {
+	pipelineEngine.StepHandler
+	crash bool
+	store *dyingStore
+}
+
+func (s *doomedStep) Execute(ctx context.Context, data *pipelineEngine.StepData) (*pipelineEngine.StepResult, error) {
+	result, err := s.StepHandler.Execute(ctx, data)
+	if err != nil || !s.crash {
+		return result, err
+	}
+	s.store.kill(true)
+	return nil, errProcessKilled
+}
+
+// resumeFixture заказ, склад и платежи, общие для умершего и нового процесса
+type resumeFixture struct {
+	orders    *sqlstore.OrderRepository
+	products  *inventorystore.ProductRepository
+	inventory *inventoryservice.Service
+	payments  *idempotentPayments
+	store     *dyingStore
+	productID uuid.UUID
+}
+
+func newResumeFixture(t *testing.T) *resumeFixture {
+	t.Helper()
+
+	f := &resumeFixture{
+		orders:   sqlstoretest.NewOrderRepository(t),
+		products: inventorystore.NewProductRepository(),
+		payments: &idempotentPayments{payments: make(map[uuid.UUID]*payment.Payment)},
+		store:    &dyingStore{MemoryExecutionStore: pipelineEngine.NewMemoryExecutionStore()},
+	}
+	f.inventory = inventoryservice.NewService(zap.NewNop(), f.products, inventorystore.NewReservationRepository(), f.orders, inventoryservice.Config{})
+
+	p, err := product.NewProduct("Клавиатура", "", "KB-1", product.Category{}, product.NewMoney(25000, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Ровно на один заказ: повторная проверка наличия его бы отклонила
+	if err := p.UpdateStock(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.products.Save(context.Background(), p); err != nil {
+		t.Fatal(err)
+	}
+	f.productID = p.ID()
+	return f
+}
+
+// newEngine движок процесса; crashStep - шаг, внутри которого процесс умрет
+func (f *resumeFixture) newEngine(t *testing.T, crashStep string) *pipelineEngine.Engine {
+	t.Helper()
+
+	engine := pipelineEngine.NewEngine(zap.NewNop(), pipelineEngine.Config{
+		DefaultTimeout: 5 * time.Second,
+		RetryConfig:    pipelineEngine.RetryConfig{MaxAttempts: 1},
+	})
+	engine.SetExecutionStore(f.store)
+
+	steps := []pipelineEngine.StepHandler{
+		pipeline.NewValidateOrderStep(zap.NewNop(), f.orders, catalog{price: 25000}),
+		pipeline.NewProcessPaymentStep(zap.NewNop(), f.orders, f.payments),
+		pipeline.NewCheckInventoryStep(zap.NewNop(), f.orders, f.inventory),
+	}
+	for _, step := range steps {
+		if crashStep != "" {
+			step = &doomedStep{StepHandler: step, crash: step.Name() == crashStep, store: f.store}
+		}
+		if err := engine.RegisterStep(step.Name(), step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return engine
+}
+
+func (f *resumeFixture) stock(t *testing.T) int {
+	t.Helper()
+
+	p, err := f.products.GetByID(context.Background(), f.productID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.StockLevel()
+}
+
+func TestResumeAfterCrashInsideStep(t *testing.T) {
+	tests := []struct {
+		crashStep   string
+		crashStatus order.Status // статус заказа, сохраненный до сбоя
+	}{
+		{"process_payment", order.StatusPaid},
+		{"check_inventory", order.StatusInventoryChecked},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.crashStep, func(t *testing.T) {
+			f := newResumeFixture(t)
+			ctx := context.Background()
+
+			ord := sqlstoretest.NewOrder(t, uuid.New(), sqlstoretest.NewItem(t, f.productID, 2, 25000))
+			if err := f.orders.Save(ctx, ord); err != nil {
+				t.Fatal(err)
+			}
+
+			definition := pipelineEngine.PipelineDefinition{
+				Name:    "order_processing",
+				Steps:   []string{"validate_order", "process_payment", "check_inventory"},
+				Timeout: 5 * time.Second,
+			}
+			execCtx, err := f.newEngine(t, tt.crashStep).Execute(ctx, definition, map[string]interface{}{"order_id": ord.ID().String()})
+			if !errors.Is(err, errProcessKilled) {
+				t.Fatalf("Execute() error = %v, want %v", err, errProcessKilled)
+			}
+			if saved, _ := f.orders.GetByID(ctx, ord.ID()); saved.Status() != tt.crashStatus {
+				t.Fatalf("order status at crash = %s, want %s", saved.Status(), tt.crashStatus)
+			}
+
+			// Новый процесс продолжает с последнего checkpoint'а
+			f.store.kill(false)
+			resumed, err := f.newEngine(t, "").Resume(ctx, execCtx.ID)
+			if err != nil {
+				t.Fatalf("Resume() error = %v", err)
+			}
+			if resumed.Status != pipelineEngine.StatusCompleted {
+				t.Errorf("resumed status = %s, want %s", resumed.Status, pipelineEngine.StatusCompleted)
+			}
+
+			saved, err := f.orders.GetByID(ctx, ord.ID())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if saved.Status() != order.StatusInventoryChecked {
+				t.Errorf("order status = %s, want %s", saved.Status(), order.StatusInventoryChecked)
+			}
+			if f.payments.charges != 1 {
+				t.Errorf("charges = %d, want 1", f.payments.charges)
+			}
+			if f.stock(t) != 0 {
+				t.Errorf("stock = %d, want 0 (reserved once)", f.stock(t))
+			}
+		})
+	}
+}
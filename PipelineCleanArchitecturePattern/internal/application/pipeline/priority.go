@@ -0,0 +1,37 @@
+package pipeline
+
+import (
+	"context"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/pkg/pipeline"
+)
+
+// 🚦 ПРИОРИТЕТ ЗАКАЗОВ В ОЧЕРЕДИ ДВИЖКА
+//
+// Движок (pkg/pipeline) не знает о доменных сущностях и работает с int
+// приоритетом. Здесь order.Priority переводится в приоритет очереди:
+// urgent-заказы выходят из очереди раньше normal, low - последними.
+
+// QueuePriority переводит приоритет заказа в приоритет очереди движка
+func QueuePriority(priority order.Priority) int {
+	return int(priority)
+}
+
+// PipelinePriorities строит Config.PipelinePriorities из приоритетов заказов
+func PipelinePriorities(priorities map[string]order.Priority) map[string]int {
+	result := make(map[string]int, len(priorities))
+	for name, priority := range priorities {
+		result[name] = QueuePriority(priority)
+	}
+	return result
+}
+
+// SubmitOrder ставит пайплайн обработки заказа в очередь с приоритетом заказа
+func SubmitOrder(ctx context.Context, engine *pipeline.Engine, definition pipeline.PipelineDefinition, ord *order.Order) (string, error) {
+	initialData := map[string]interface{}{
+		"order_id": ord.ID().String(),
+	}
+
+	return engine.SubmitWithPriority(ctx, definition, initialData, QueuePriority(ord.Priority()))
+}
@@ -0,0 +1,175 @@
+package promotionservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"pipeline-clean-architecture/internal/application/pipeline"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/product"
+	"pipeline-clean-architecture/internal/domain/promotion"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 🏷️ СЕРВИС СКИДОК
+//
+// ============================================================================
+// ЧТО ДЕЛАЕТ Service:
+// ============================================================================
+//
+// ApplyPromotions собирает корзину из заказа (категории товаров - из
+// product.Repository), считает скидки движком promotion.Evaluate и
+// записывает результат в заказ через Order.ApplyPromotions:
+//
+//	order.Repository → promotion.Cart → Evaluate → Order.ApplyPromotions → Save
+//
+// Повторный вызов пересчитывает скидки с нуля с новым набором купонов.
+//
+// ============================================================================
+// ЛИМИТЫ НА ПОКУПАТЕЛЯ:
+// ============================================================================
+//
+// Использования правил хранит promotion.UsageRepository: после сохранения
+// заказа сервис заменяет список правил этого заказа. Проверка лимита и
+// запись выполняются под мьютексом сервиса, поэтому два заказа одного
+// покупателя в одном процессе не получат одноразовую скидку дважды.
+// ReleasePromotions освобождает использования отмененного заказа; шаг
+// оплаты вызывает его при возврате платежа (pipeline.PromotionReleaser).
+//
+// ============================================================================
+
+// Проверка реализации интерфейса пайплайна
+var _ pipeline.PromotionReleaser = (*Service)(nil)
+
+// Service сервис применения скидок к заказам
+type Service struct {
+	logger   *zap.Logger
+	rules    promotion.RuleSource
+	usage    promotion.UsageRepository
+	orders   order.Repository
+	products product.Repository
+
+	// mu сериализует проверку лимитов и запись использований
+	mu sync.Mutex
+}
+
+// NewService создает сервис скидок; products опционален - без него
+// правила по категориям не применяются
+func NewService(logger *zap.Logger, rules promotion.RuleSource, usage promotion.UsageRepository, orders order.Repository, products product.Repository) *Service {
+	return &Service{
+		logger:   logger,
+		rules:    rules,
+		usage:    usage,
+		orders:   orders,
+		products: products,
+	}
+}
+
+// ApplyPromotions пересчитывает скидки заказа с купонами coupons
+func (s *Service) ApplyPromotions(ctx context.Context, orderID uuid.UUID, coupons []string) (*order.Order, *promotion.Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ord, err := s.orders.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ord.CanModifyItems() {
+		return nil, nil, fmt.Errorf("%w: order %s is %s, promotions can no longer change", usecase.ErrInvalidRequest, orderID, ord.Status())
+	}
+
+	rules, err := s.rules.Rules(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load promotion rules: %w", err)
+	}
+
+	cart, err := s.cart(ctx, ord, rules, coupons)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result, err := promotion.Evaluate(rules, cart)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := ord.ApplyPromotions(result.Discounts, result.Applied); err != nil {
+		return nil, nil, fmt.Errorf("%w: %v", usecase.ErrInvalidRequest, err)
+	}
+	if err := s.orders.Save(ctx, ord); err != nil {
+		return nil, nil, fmt.Errorf("failed to save order: %w", err)
+	}
+
+	ruleIDs := make([]string, 0, len(result.Applied))
+	for _, applied := range result.Applied {
+		ruleIDs = append(ruleIDs, applied.RuleID)
+	}
+	if err := s.usage.ReplaceUses(ctx, orderID, ord.CustomerID(), ruleIDs); err != nil {
+		return nil, nil, fmt.Errorf("failed to record promotion usage: %w", err)
+	}
+
+	s.logger.Info("Promotions applied",
+		zap.String("order_id", orderID.String()),
+		zap.Strings("rules", ruleIDs),
+		zap.Int("rejected_coupons", len(result.Rejected)),
+		zap.String("discount", result.Total.String()))
+
+	return ord, result, nil
+}
+
+// ReleasePromotions освобождает лимиты правил, примененных к заказу
+//
+// Скидки в самом заказе не меняются: отмененный заказ хранит их для аудита.
+func (s *Service) ReleasePromotions(ctx context.Context, orderID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usage.ReplaceUses(ctx, orderID, uuid.Nil, nil)
+}
+
+// cart собирает корзину заказа и прошлые использования правил с лимитом
+func (s *Service) cart(ctx context.Context, ord *order.Order, rules []promotion.Rule, coupons []string) (promotion.Cart, error) {
+	cart := promotion.Cart{
+		CustomerID: ord.CustomerID(),
+		Currency:   ord.Currency(),
+		Coupons:    coupons,
+		Uses:       make(map[string]int),
+	}
+
+	for _, item := range ord.Items() {
+		line := promotion.CartLine{
+			ProductID: item.ProductID(),
+			Quantity:  item.Quantity(),
+			UnitPrice: item.UnitPrice(),
+		}
+		if s.products != nil {
+			p, err := s.products.GetByID(ctx, item.ProductID())
+			switch {
+			case errors.Is(err, product.ErrProductNotFound):
+				// Товар убран из каталога: скидки по категориям к нему не относятся
+			case err != nil:
+				return promotion.Cart{}, fmt.Errorf("failed to get product %s: %w", item.ProductID(), err)
+			default:
+				line.Category = p.Category()
+			}
+		}
+		cart.Lines = append(cart.Lines, line)
+	}
+
+	for _, rule := range rules {
+		if rule.MaxUsesPerCustomer == 0 {
+			continue
+		}
+		uses, err := s.usage.CountUses(ctx, rule.ID, ord.CustomerID(), ord.ID())
+		if err != nil {
+			return promotion.Cart{}, fmt.Errorf("failed to count uses of promotion %s: %w", rule.ID, err)
+		}
+		cart.Uses[rule.ID] = uses
+	}
+	return cart, nil
+}
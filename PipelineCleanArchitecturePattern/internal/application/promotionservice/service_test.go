@@ -0,0 +1,178 @@
+package promotionservice
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/product"
+	"pipeline-clean-architecture/internal/domain/promotion"
+	"pipeline-clean-architecture/internal/infrastructure/inventorystore"
+	"pipeline-clean-architecture/internal/infrastructure/promotionstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore/sqlstoretest"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fixture сервис скидок поверх SQLite заказов и in-memory каталога
+type fixture struct {
+	service *Service
+	orders  *sqlstore.OrderRepository
+	laptop  *product.Product
+	book    *product.Product
+}
+
+// usageRepositories хранилища использований правил, с которыми работает сервис
+var usageRepositories = map[string]func(orders *sqlstore.OrderRepository) promotion.UsageRepository{
+	"memory": func(orders *sqlstore.OrderRepository) promotion.UsageRepository {
+		return promotionstore.NewMemoryUsageRepository(orders)
+	},
+	"sql": func(orders *sqlstore.OrderRepository) promotion.UsageRepository {
+		return sqlstore.NewPromotionUsageRepository(orders)
+	},
+}
+
+func newFixture(t *testing.T, rules []promotion.Rule) *fixture {
+	return newFixtureWithUsage(t, rules, usageRepositories["memory"])
+}
+
+func newFixtureWithUsage(t *testing.T, rules []promotion.Rule, usage func(orders *sqlstore.OrderRepository) promotion.UsageRepository) *fixture {
+	t.Helper()
+	ctx := context.Background()
+
+	orders := sqlstoretest.NewOrderRepository(t)
+	products := inventorystore.NewProductRepository()
+	newProduct := func(name, sku, path string, price int64) *product.Product {
+		category, err := product.NewCategory(name, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := product.NewProduct(name, "", sku, category, product.NewMoney(price, "RUB"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := products.Save(ctx, p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+
+	source, err := promotionstore.NewFileSource(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &fixture{
+		service: NewService(zap.NewNop(), source, usage(orders), orders, products),
+		orders:  orders,
+		laptop:  newProduct("Laptop", "LAP-1", "Electronics > Computers", 100000),
+		book:    newProduct("Book", "BOOK-1", "Books", 2000),
+	}
+}
+
+func (f *fixture) newOrder(t *testing.T, customerID uuid.UUID) *order.Order {
+	t.Helper()
+
+	var items []order.OrderItem
+	for _, p := range []*product.Product{f.laptop, f.book} {
+		items = append(items, sqlstoretest.NewItem(t, p.ID(), 1, p.Price().Amount()))
+	}
+	ord := sqlstoretest.NewOrder(t, customerID, items...)
+	if err := f.orders.Save(context.Background(), ord); err != nil {
+		t.Fatal(err)
+	}
+	return ord
+}
+
+func TestApplyPromotionsByCategoryAndCoupon(t *testing.T) {
+	f := newFixture(t, []promotion.Rule{
+		{ID: "electronics", Name: "Электроника -10%", Type: promotion.TypeCategorySale,
+			Category: "Electronics", Percent: 10, Stackable: true},
+		{ID: "welcome", Name: "Новичок", Type: promotion.TypeFixed, Coupon: "WELCOME",
+			Amount: 1000, Priority: 1, Stackable: true, MaxUsesPerCustomer: 1},
+	})
+	ctx := context.Background()
+	ord := f.newOrder(t, uuid.New())
+
+	updated, result, err := f.service.ApplyPromotions(ctx, ord.ID(), []string{"welcome"})
+	if err != nil {
+		t.Fatalf("ApplyPromotions() error = %v", err)
+	}
+	// 10% от ноутбука, затем 10 ₽ пропорционально остаткам 900 ₽ и 20 ₽
+	if result.Total.Amount() != 11000 || len(result.Applied) != 2 {
+		t.Errorf("Total = %s, Applied = %+v; want 110.00 RUB from two rules", result.Total, result.Applied)
+	}
+	if updated.TotalAmount().Amount() != 102000-11000 {
+		t.Errorf("TotalAmount() = %s, want 910.00 RUB", updated.TotalAmount())
+	}
+
+	saved, err := f.orders.GetByID(ctx, ord.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Promotions()) != 2 || saved.Promotions()[1].Coupon != "WELCOME" {
+		t.Errorf("saved Promotions() = %+v", saved.Promotions())
+	}
+
+	if _, _, err := f.service.ApplyPromotions(ctx, ord.ID(), []string{"NOPE"}); !errors.Is(err, promotion.ErrUnknownCoupon) {
+		t.Errorf("ApplyPromotions(NOPE) error = %v, want ErrUnknownCoupon", err)
+	}
+}
+
+func TestApplyPromotionsEnforcesUsagePerCustomer(t *testing.T) {
+	for name, usage := range usageRepositories {
+		t.Run(name, func(t *testing.T) { testUsagePerCustomer(t, usage) })
+	}
+}
+
+func testUsagePerCustomer(t *testing.T, usage func(orders *sqlstore.OrderRepository) promotion.UsageRepository) {
+	f := newFixtureWithUsage(t, []promotion.Rule{
+		{ID: "welcome", Name: "Новичок", Type: promotion.TypeFixed, Coupon: "WELCOME",
+			Amount: 1000, MaxUsesPerCustomer: 1},
+	}, usage)
+	ctx := context.Background()
+	customerID := uuid.New()
+	first, second := f.newOrder(t, customerID), f.newOrder(t, customerID)
+
+	if _, result, err := f.service.ApplyPromotions(ctx, first.ID(), []string{"WELCOME"}); err != nil || len(result.Applied) != 1 {
+		t.Fatalf("first ApplyPromotions() = %+v, %v", result, err)
+	}
+	// Пересчет того же заказа не расходует лимит
+	if _, result, err := f.service.ApplyPromotions(ctx, first.ID(), []string{"WELCOME"}); err != nil || len(result.Applied) != 1 {
+		t.Fatalf("repeated ApplyPromotions() = %+v, %v", result, err)
+	}
+
+	_, result, err := f.service.ApplyPromotions(ctx, second.ID(), []string{"WELCOME"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Applied) != 0 || !errors.Is(result.Rejected["WELCOME"], promotion.ErrUsageLimitReached) {
+		t.Errorf("second order: Applied = %+v, Rejected = %v; want usage limit", result.Applied, result.Rejected)
+	}
+
+	// Отмена первого заказа освобождает лимит и без ReleasePromotions
+	cancelled, err := f.orders.GetByID(ctx, first.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cancelled.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.orders.Save(ctx, cancelled); err != nil {
+		t.Fatal(err)
+	}
+	if _, result, err := f.service.ApplyPromotions(ctx, second.ID(), []string{"WELCOME"}); err != nil || len(result.Applied) != 1 {
+		t.Fatalf("ApplyPromotions() after cancel = %+v, %v", result, err)
+	}
+
+	// Снятые с отмененного заказа правила не мешают и после ReleasePromotions
+	if err := f.service.ReleasePromotions(ctx, first.ID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, result, err := f.service.ApplyPromotions(ctx, second.ID(), []string{"WELCOME"}); err != nil || len(result.Applied) != 1 {
+		t.Errorf("ApplyPromotions() after release = %+v, %v", result, err)
+	}
+}
@@ -0,0 +1,275 @@
+package dto
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/payment"
+	"pipeline-clean-architecture/internal/domain/product"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"github.com/google/uuid"
+)
+
+// 📨 DTO DELIVERY СЛОЯ
+//
+// ============================================================================
+// ЗАЧЕМ ОТДЕЛЬНЫЕ DTO:
+// ============================================================================
+//
+// Доменные сущности хранят состояние в неэкспортируемых полях, поэтому
+// encoding/json видит order.Order и order.Address как {}. Здесь описан
+// формат, общий для REST и gRPC (gRPC сервис кодирует сообщения в JSON):
+//
+// - деньги передаются в копейках: {"amount": 150000, "currency": "RUB"}
+// - статусы, приоритеты и шаги - строками ("paid", "urgent", "validation")
+// - длительности - в миллисекундах (*_ms)
+//
+// Ошибки разбора запросов оборачивают usecase.ErrInvalidRequest.
+//
+// ============================================================================
+
+// 💰 ДЕНЬГИ И АДРЕСА
+
+// Money денежная сумма в копейках
+type Money struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+// FromOrderMoney представление order.Money
+func FromOrderMoney(m order.Money) Money {
+	return Money{Amount: m.Amount(), Currency: m.Currency()}
+}
+
+// FromProductMoney представление product.Money
+func FromProductMoney(m product.Money) Money {
+	return Money{Amount: m.Amount(), Currency: m.Currency()}
+}
+
+// Address адрес доставки или счета
+type Address struct {
+	Street     string `json:"street"`
+	City       string `json:"city"`
+	PostalCode string `json:"postal_code"`
+	Country    string `json:"country"`
+	Phone      string `json:"phone"`
+}
+
+// FromAddress представление order.Address
+func FromAddress(a order.Address) Address {
+	return Address{
+		Street:     a.Street(),
+		City:       a.City(),
+		PostalCode: a.PostalCode(),
+		Country:    a.Country(),
+		Phone:      a.Phone(),
+	}
+}
+
+// ToDomain создает order.Address; адрес проверяют доменные методы заказа
+func (a Address) ToDomain() order.Address {
+	return order.NewAddress(a.Street, a.City, a.PostalCode, a.Country, a.Phone)
+}
+
+// 📦 ЗАКАЗЫ
+
+// Order представление заказа
+type Order struct {
+	ID              uuid.UUID   `json:"id"`
+	CustomerID      uuid.UUID   `json:"customer_id"`
+	Status          string      `json:"status"`
+	Priority        string      `json:"priority"`
+	Source          string      `json:"source"`
+	Items           []OrderItem `json:"items"`
+	TotalAmount     Money       `json:"total_amount"`
+	Promotions      []Promotion `json:"promotions,omitempty"`
+	ShippingAddress Address     `json:"shipping_address"`
+	BillingAddress  Address     `json:"billing_address"`
+	Notes           string      `json:"notes,omitempty"`
+	Version         int         `json:"version"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// OrderItem позиция заказа
+type OrderItem struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Quantity  int       `json:"quantity"`
+	UnitPrice Money     `json:"unit_price"`
+	Discount  Money     `json:"discount"`
+	Total     Money     `json:"total"`
+}
+
+// Promotion примененное правило скидки
+type Promotion struct {
+	RuleID   string `json:"rule_id"`
+	Name     string `json:"name"`
+	Coupon   string `json:"coupon,omitempty"`
+	Discount Money  `json:"discount"`
+}
+
+// FromPromotions представление примененных правил
+func FromPromotions(applied []order.AppliedPromotion) []Promotion {
+	if len(applied) == 0 {
+		return nil
+	}
+	promotions := make([]Promotion, 0, len(applied))
+	for _, promo := range applied {
+		promotions = append(promotions, Promotion{
+			RuleID:   promo.RuleID,
+			Name:     promo.Name,
+			Coupon:   promo.Coupon,
+			Discount: FromOrderMoney(promo.Discount),
+		})
+	}
+	return promotions
+}
+
+// FromOrder представление order.Order
+func FromOrder(ord *order.Order) *Order {
+	if ord == nil {
+		return nil
+	}
+
+	items := make([]OrderItem, 0, len(ord.Items()))
+	for _, item := range ord.Items() {
+		items = append(items, OrderItem{
+			ProductID: item.ProductID(),
+			Quantity:  item.Quantity(),
+			UnitPrice: FromOrderMoney(item.UnitPrice()),
+			Discount:  FromOrderMoney(item.Discount()),
+			Total:     FromOrderMoney(item.Total()),
+		})
+	}
+
+	return &Order{
+		ID:              ord.ID(),
+		CustomerID:      ord.CustomerID(),
+		Status:          ord.Status().String(),
+		Priority:        ord.Priority().String(),
+		Source:          string(ord.Source()),
+		Items:           items,
+		TotalAmount:     FromOrderMoney(ord.TotalAmount()),
+		Promotions:      FromPromotions(ord.Promotions()),
+		ShippingAddress: FromAddress(ord.ShippingAddress()),
+		BillingAddress:  FromAddress(ord.BillingAddress()),
+		Notes:           ord.Notes(),
+		Version:         ord.Version(),
+		CreatedAt:       ord.CreatedAt(),
+		UpdatedAt:       ord.UpdatedAt(),
+	}
+}
+
+// CreateOrderRequest запрос на создание заказа
+type CreateOrderRequest struct {
+	CustomerID      uuid.UUID                 `json:"customer_id"`
+	Items           []usecase.CreateOrderItem `json:"items"`
+	ShippingAddress Address                   `json:"shipping_address"`
+	BillingAddress  *Address                  `json:"billing_address,omitempty"`
+	PaymentMethod   string                    `json:"payment_method"`
+	Notes           string                    `json:"notes,omitempty"`
+	Priority        string                    `json:"priority,omitempty"` // по умолчанию normal
+	Source          string                    `json:"source,omitempty"`   // по умолчанию web
+}
+
+// ToUseCase переводит запрос в формат use case слоя
+func (r CreateOrderRequest) ToUseCase() (usecase.CreateOrderRequest, error) {
+	priority, err := ParsePriority(r.Priority)
+	if err != nil {
+		return usecase.CreateOrderRequest{}, err
+	}
+
+	source, err := ParseSource(r.Source)
+	if err != nil {
+		return usecase.CreateOrderRequest{}, err
+	}
+	if source == "" {
+		source = order.SourceWeb
+	}
+
+	req := usecase.CreateOrderRequest{
+		CustomerID:      r.CustomerID,
+		Items:           r.Items,
+		ShippingAddress: r.ShippingAddress.ToDomain(),
+		PaymentMethod:   payment.Method(r.PaymentMethod),
+		Notes:           r.Notes,
+		Priority:        priority,
+		Source:          source,
+	}
+	if r.BillingAddress != nil {
+		billing := r.BillingAddress.ToDomain()
+		req.BillingAddress = &billing
+	}
+	return req, nil
+}
+
+// CreateOrderResponse ответ создания заказа
+type CreateOrderResponse struct {
+	Order     *Order   `json:"order"`
+	Warnings  []string `json:"warnings,omitempty"`
+	NextSteps []string `json:"next_steps"`
+}
+
+// FromCreateOrderResponse представление ответа создания заказа
+func FromCreateOrderResponse(resp *usecase.CreateOrderResponse) *CreateOrderResponse {
+	return &CreateOrderResponse{
+		Order:     FromOrder(resp.Order),
+		Warnings:  resp.Warnings,
+		NextSteps: resp.NextSteps,
+	}
+}
+
+// 🔎 РАЗБОР ПЕРЕЧИСЛЕНИЙ
+
+// ParsePriority разбирает приоритет заказа (пустая строка - normal)
+func ParsePriority(value string) (order.Priority, error) {
+	if value == "" {
+		return order.PriorityNormal, nil
+	}
+	for priority := order.PriorityLow; priority <= order.PriorityUrgent; priority++ {
+		if priority.String() == value {
+			return priority, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown priority %q", usecase.ErrInvalidRequest, value)
+}
+
+// ParseStatus разбирает статус заказа
+func ParseStatus(value string) (order.Status, error) {
+	for status := order.StatusPending; status <= order.StatusRefunded; status++ {
+		if status.String() == value {
+			return status, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: unknown order status %q", usecase.ErrInvalidRequest, value)
+}
+
+// ParseSource разбирает источник заказа (пустая строка остается пустой)
+func ParseSource(value string) (order.Source, error) {
+	switch source := order.Source(value); source {
+	case "", order.SourceWeb, order.SourceMobile, order.SourceAPI:
+		return source, nil
+	default:
+		return "", fmt.Errorf("%w: unknown source %q", usecase.ErrInvalidRequest, value)
+	}
+}
+
+// ParseStep разбирает шаг пайплайна
+func ParseStep(value string) (usecase.PipelineStep, error) {
+	if strings.TrimSpace(value) == "" {
+		return "", fmt.Errorf("%w: step is required", usecase.ErrInvalidRequest)
+	}
+	return usecase.PipelineStep(value), nil
+}
+
+// ParseOrderID разбирает ID заказа
+func ParseOrderID(value string) (uuid.UUID, error) {
+	id, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("%w: invalid order id %q", usecase.ErrInvalidRequest, value)
+	}
+	return id, nil
+}
@@ -0,0 +1,346 @@
+package dto
+
+import (
+	"fmt"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/promotion"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"github.com/google/uuid"
+)
+
+// 💰 ПЛАТЕЖИ
+
+// ProcessPaymentResponse ответ обработки платежа
+type ProcessPaymentResponse struct {
+	PaymentID   uuid.UUID  `json:"payment_id"`
+	Status      string     `json:"status"`
+	RedirectURL string     `json:"redirect_url,omitempty"`
+	QRCodeData  string     `json:"qr_code_data,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+}
+
+// FromProcessPaymentResponse представление ответа обработки платежа
+func FromProcessPaymentResponse(resp *usecase.ProcessPaymentResponse) *ProcessPaymentResponse {
+	return &ProcessPaymentResponse{
+		PaymentID:   resp.PaymentID,
+		Status:      resp.Status.String(),
+		RedirectURL: resp.RedirectURL,
+		QRCodeData:  resp.QRCodeData,
+		ExpiresAt:   resp.ExpiresAt,
+	}
+}
+
+// 📦 СКЛАД И ДОСТАВКА
+
+// CheckInventoryResponse ответ проверки склада
+type CheckInventoryResponse struct {
+	AvailableItems   []usecase.InventoryItem `json:"available_items"`
+	UnavailableItems []usecase.InventoryItem `json:"unavailable_items"`
+	IsFullyAvailable bool                    `json:"is_fully_available"`
+	EstimatedDelayMs *int64                  `json:"estimated_delay_ms,omitempty"`
+	Alternatives     []Alternative           `json:"alternatives,omitempty"`
+}
+
+// Alternative альтернативный товар
+type Alternative struct {
+	ProductID   uuid.UUID `json:"product_id"`
+	Name        string    `json:"name"`
+	Price       Money     `json:"price"`
+	Similarity  float64   `json:"similarity"`
+	Description string    `json:"description"`
+}
+
+// FromCheckInventoryResponse представление ответа проверки склада
+func FromCheckInventoryResponse(resp *usecase.CheckInventoryResponse) *CheckInventoryResponse {
+	result := &CheckInventoryResponse{
+		AvailableItems:   resp.AvailableItems,
+		UnavailableItems: resp.UnavailableItems,
+		IsFullyAvailable: resp.IsFullyAvailable,
+	}
+	if resp.EstimatedDelay != nil {
+		delay := resp.EstimatedDelay.Milliseconds()
+		result.EstimatedDelayMs = &delay
+	}
+	for _, alt := range resp.Alternatives {
+		result.Alternatives = append(result.Alternatives, Alternative{
+			ProductID:   alt.ProductID,
+			Name:        alt.Name,
+			Price:       FromProductMoney(alt.Price),
+			Similarity:  alt.Similarity,
+			Description: alt.Description,
+		})
+	}
+	return result
+}
+
+// ArrangeDeliveryResponse ответ организации доставки
+type ArrangeDeliveryResponse struct {
+	DeliveryID      uuid.UUID        `json:"delivery_id"`
+	CarrierID       uuid.UUID        `json:"carrier_id"`
+	CarrierName     string           `json:"carrier_name"`
+	TrackingNumber  string           `json:"tracking_number"`
+	EstimatedDate   time.Time        `json:"estimated_date"`
+	DeliveryOptions []DeliveryOption `json:"delivery_options"`
+	Cost            Money            `json:"cost"`
+}
+
+// DeliveryOption опция доставки
+type DeliveryOption struct {
+	ID          uuid.UUID `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Cost        Money     `json:"cost"`
+	DurationMs  int64     `json:"duration_ms"`
+	IsSelected  bool      `json:"is_selected"`
+}
+
+// FromArrangeDeliveryResponse представление ответа организации доставки
+func FromArrangeDeliveryResponse(resp *usecase.ArrangeDeliveryResponse) *ArrangeDeliveryResponse {
+	result := &ArrangeDeliveryResponse{
+		DeliveryID:      resp.DeliveryID,
+		CarrierID:       resp.CarrierID,
+		CarrierName:     resp.CarrierName,
+		TrackingNumber:  resp.TrackingNumber,
+		EstimatedDate:   resp.EstimatedDate,
+		DeliveryOptions: make([]DeliveryOption, 0, len(resp.DeliveryOptions)),
+		Cost:            FromProductMoney(resp.Cost),
+	}
+	for _, option := range resp.DeliveryOptions {
+		result.DeliveryOptions = append(result.DeliveryOptions, DeliveryOption{
+			ID:          option.ID,
+			Name:        option.Name,
+			Description: option.Description,
+			Cost:        FromProductMoney(option.Cost),
+			DurationMs:  option.Duration.Milliseconds(),
+			IsSelected:  option.IsSelected,
+		})
+	}
+	return result
+}
+
+// 🔄 ПАЙПЛАЙН
+
+// PipelineResult результат выполнения пайплайна
+type PipelineResult struct {
+	OrderID     uuid.UUID               `json:"order_id"`
+	Success     bool                    `json:"success"`
+	Steps       []StepResult            `json:"steps"`
+	DurationMs  int64                   `json:"duration_ms"`
+	CompletedAt time.Time               `json:"completed_at"`
+	FinalStatus string                  `json:"final_status"`
+	Errors      []usecase.PipelineError `json:"errors,omitempty"`
+}
+
+// StepResult результат выполнения шага
+type StepResult struct {
+	Step        usecase.PipelineStep   `json:"step"`
+	Success     bool                   `json:"success"`
+	DurationMs  int64                  `json:"duration_ms"`
+	StartedAt   time.Time              `json:"started_at"`
+	CompletedAt time.Time              `json:"completed_at"`
+	Data        interface{}            `json:"data,omitempty"`
+	Error       *usecase.PipelineError `json:"error,omitempty"`
+}
+
+// FromPipelineResult представление результата пайплайна
+func FromPipelineResult(result *usecase.PipelineResult) *PipelineResult {
+	steps := make([]StepResult, 0, len(result.Steps))
+	for i := range result.Steps {
+		steps = append(steps, *FromStepResult(&result.Steps[i]))
+	}
+
+	return &PipelineResult{
+		OrderID:     result.OrderID,
+		Success:     result.Success,
+		Steps:       steps,
+		DurationMs:  result.Duration.Milliseconds(),
+		CompletedAt: result.CompletedAt,
+		FinalStatus: result.FinalStatus.String(),
+		Errors:      result.Errors,
+	}
+}
+
+// FromStepResult представление результата шага
+func FromStepResult(result *usecase.StepResult) *StepResult {
+	return &StepResult{
+		Step:        result.Step,
+		Success:     result.Success,
+		DurationMs:  result.Duration.Milliseconds(),
+		StartedAt:   result.StartedAt,
+		CompletedAt: result.CompletedAt,
+		Data:        result.Data,
+		Error:       result.Error,
+	}
+}
+
+// 📊 СТАТИСТИКА
+
+// StatsRequest запрос статистики заказов
+//
+// Даты в формате RFC 3339, период - полуинтервал [date_from, date_to).
+type StatsRequest struct {
+	CustomerID string `json:"customer_id,omitempty"`
+	DateFrom   string `json:"date_from,omitempty"`
+	DateTo     string `json:"date_to,omitempty"`
+	Status     string `json:"status,omitempty"`
+	Source     string `json:"source,omitempty"`
+	GroupBy    string `json:"group_by,omitempty"`
+}
+
+// ToUseCase переводит запрос в формат use case слоя
+func (r StatsRequest) ToUseCase() (usecase.GetOrderStatsRequest, error) {
+	req := usecase.GetOrderStatsRequest{GroupBy: usecase.StatsGroupBy(r.GroupBy)}
+
+	if r.CustomerID != "" {
+		id, err := uuid.Parse(r.CustomerID)
+		if err != nil {
+			return req, fmt.Errorf("%w: invalid customer_id %q", usecase.ErrInvalidRequest, r.CustomerID)
+		}
+		req.CustomerID = &id
+	}
+
+	for _, field := range []struct {
+		name  string
+		value string
+		dest  **time.Time
+	}{
+		{"date_from", r.DateFrom, &req.DateFrom},
+		{"date_to", r.DateTo, &req.DateTo},
+	} {
+		if field.value == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, field.value)
+		if err != nil {
+			return req, fmt.Errorf("%w: %s must be RFC 3339", usecase.ErrInvalidRequest, field.name)
+		}
+		*field.dest = &t
+	}
+
+	if r.Status != "" {
+		status, err := ParseStatus(r.Status)
+		if err != nil {
+			return req, err
+		}
+		req.Status = &status
+	}
+
+	if r.Source != "" {
+		source, err := ParseSource(r.Source)
+		if err != nil {
+			return req, err
+		}
+		req.Source = &source
+	}
+
+	return req, nil
+}
+
+// StatsResponse статистика заказов
+type StatsResponse struct {
+	TotalOrders     int            `json:"total_orders"`
+	TotalRevenue    Money          `json:"total_revenue"`
+	AverageOrder    Money          `json:"average_order"`
+	StatusBreakdown map[string]int `json:"status_breakdown"`
+	TrendData       []TrendPoint   `json:"trend_data"`
+	TopProducts     []ProductStats `json:"top_products"`
+}
+
+// TrendPoint точка тренда
+type TrendPoint struct {
+	Period  time.Time `json:"period"`
+	Count   int       `json:"count"`
+	Revenue Money     `json:"revenue"`
+}
+
+// ProductStats статистика по товару
+type ProductStats struct {
+	ProductID uuid.UUID `json:"product_id"`
+	Name      string    `json:"name,omitempty"`
+	Quantity  int       `json:"quantity"`
+	Revenue   Money     `json:"revenue"`
+}
+
+// FromStatsResponse представление статистики заказов
+func FromStatsResponse(resp *usecase.GetOrderStatsResponse) *StatsResponse {
+	result := &StatsResponse{
+		TotalOrders:     resp.TotalOrders,
+		TotalRevenue:    FromProductMoney(resp.TotalRevenue),
+		AverageOrder:    FromProductMoney(resp.AverageOrder),
+		StatusBreakdown: resp.StatusBreakdown,
+		TrendData:       make([]TrendPoint, 0, len(resp.TrendData)),
+		TopProducts:     make([]ProductStats, 0, len(resp.TopProducts)),
+	}
+	for _, point := range resp.TrendData {
+		result.TrendData = append(result.TrendData, TrendPoint{
+			Period:  point.Period,
+			Count:   point.Count,
+			Revenue: FromProductMoney(point.Revenue),
+		})
+	}
+	for _, p := range resp.TopProducts {
+		result.TopProducts = append(result.TopProducts, ProductStats{
+			ProductID: p.ProductID,
+			Name:      p.Name,
+			Quantity:  p.Quantity,
+			Revenue:   FromProductMoney(p.Revenue),
+		})
+	}
+	return result
+}
+
+// 🏷️ СКИДКИ
+
+// ApplyPromotionsRequest запрос пересчета скидок заказа
+type ApplyPromotionsRequest struct {
+	Coupons []string `json:"coupons"`
+}
+
+// ApplyPromotionsResponse заказ после пересчета скидок
+type ApplyPromotionsResponse struct {
+	Order         *Order            `json:"order"`
+	TotalDiscount Money             `json:"total_discount"`
+	Rejected      map[string]string `json:"rejected_coupons,omitempty"`
+}
+
+// FromPromotionResult представление заказа и итога promotion.Evaluate
+func FromPromotionResult(ord *order.Order, result *promotion.Result) *ApplyPromotionsResponse {
+	resp := &ApplyPromotionsResponse{
+		Order:         FromOrder(ord),
+		TotalDiscount: FromOrderMoney(result.Total),
+	}
+	for coupon, err := range result.Rejected {
+		if resp.Rejected == nil {
+			resp.Rejected = make(map[string]string, len(result.Rejected))
+		}
+		resp.Rejected[coupon] = err.Error()
+	}
+	return resp
+}
+
+// ✉️ ПРОЧИЕ ЗАПРОСЫ
+//
+// В REST ID заказа и шаг берутся из пути, в gRPC - из полей сообщения.
+
+// OrderRequest запрос операции над заказом
+type OrderRequest struct {
+	OrderID string `json:"order_id"`
+}
+
+// StepRequest запрос выполнения шага пайплайна
+type StepRequest struct {
+	OrderID string `json:"order_id"`
+	Step    string `json:"step"`
+}
+
+// NotificationRequest запрос отправки уведомления
+type NotificationRequest struct {
+	OrderID string                    `json:"order_id,omitempty"`
+	Event   usecase.NotificationEvent `json:"event"`
+}
+
+// Empty пустой ответ
+type Empty struct{}
@@ -0,0 +1,126 @@
+package grpcapi
+
+import (
+	"context"
+
+	"pipeline-clean-architecture/internal/delivery/dto"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"google.golang.org/grpc"
+)
+
+// 📞 КЛИЕНТ gRPC СЕРВИСА ЗАКАЗОВ
+//
+// Клиент сам добавляет content-subtype "json" к каждому вызову.
+
+// Client клиент сервиса orders.v1.OrderService
+type Client struct {
+	conn grpc.ClientConnInterface
+}
+
+// NewClient создает клиент поверх gRPC соединения
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{conn: conn}
+}
+
+// CreateOrder создает заказ
+func (c *Client) CreateOrder(ctx context.Context, req *dto.CreateOrderRequest, opts ...grpc.CallOption) (*dto.CreateOrderResponse, error) {
+	resp := new(dto.CreateOrderResponse)
+	return resp, c.invoke(ctx, "CreateOrder", req, resp, opts)
+}
+
+// ValidateOrder проверяет заказ
+func (c *Client) ValidateOrder(ctx context.Context, req *dto.OrderRequest, opts ...grpc.CallOption) (*usecase.ValidateOrderResponse, error) {
+	resp := new(usecase.ValidateOrderResponse)
+	return resp, c.invoke(ctx, "ValidateOrder", req, resp, opts)
+}
+
+// ProcessPayment оплачивает заказ
+func (c *Client) ProcessPayment(ctx context.Context, req *usecase.ProcessPaymentRequest, opts ...grpc.CallOption) (*dto.ProcessPaymentResponse, error) {
+	resp := new(dto.ProcessPaymentResponse)
+	return resp, c.invoke(ctx, "ProcessPayment", req, resp, opts)
+}
+
+// CheckInventory проверяет наличие товаров заказа
+func (c *Client) CheckInventory(ctx context.Context, req *dto.OrderRequest, opts ...grpc.CallOption) (*dto.CheckInventoryResponse, error) {
+	resp := new(dto.CheckInventoryResponse)
+	return resp, c.invoke(ctx, "CheckInventory", req, resp, opts)
+}
+
+// ReserveInventory резервирует товары заказа
+func (c *Client) ReserveInventory(ctx context.Context, req *dto.OrderRequest, opts ...grpc.CallOption) (*usecase.ReserveInventoryResponse, error) {
+	resp := new(usecase.ReserveInventoryResponse)
+	return resp, c.invoke(ctx, "ReserveInventory", req, resp, opts)
+}
+
+// ArrangeDelivery организует доставку заказа
+func (c *Client) ArrangeDelivery(ctx context.Context, req *dto.OrderRequest, opts ...grpc.CallOption) (*dto.ArrangeDeliveryResponse, error) {
+	resp := new(dto.ArrangeDeliveryResponse)
+	return resp, c.invoke(ctx, "ArrangeDelivery", req, resp, opts)
+}
+
+// SendNotification отправляет уведомление о событии заказа
+func (c *Client) SendNotification(ctx context.Context, req *dto.NotificationRequest, opts ...grpc.CallOption) error {
+	return c.invoke(ctx, "SendNotification", req, new(dto.Empty), opts)
+}
+
+// GetOrderStats возвращает статистику заказов
+func (c *Client) GetOrderStats(ctx context.Context, req *dto.StatsRequest, opts ...grpc.CallOption) (*dto.StatsResponse, error) {
+	resp := new(dto.StatsResponse)
+	return resp, c.invoke(ctx, "GetOrderStats", req, resp, opts)
+}
+
+// ExecuteOrderPipeline выполняет пайплайн заказа
+func (c *Client) ExecuteOrderPipeline(ctx context.Context, req *dto.OrderRequest, opts ...grpc.CallOption) (*dto.PipelineResult, error) {
+	resp := new(dto.PipelineResult)
+	return resp, c.invoke(ctx, "ExecuteOrderPipeline", req, resp, opts)
+}
+
+// ExecuteStep выполняет отдельный шаг пайплайна
+func (c *Client) ExecuteStep(ctx context.Context, req *dto.StepRequest, opts ...grpc.CallOption) (*dto.StepResult, error) {
+	resp := new(dto.StepResult)
+	return resp, c.invoke(ctx, "ExecuteStep", req, resp, opts)
+}
+
+// GetPipelineStatus возвращает статус последнего пайплайна заказа
+func (c *Client) GetPipelineStatus(ctx context.Context, req *dto.OrderRequest, opts ...grpc.CallOption) (*usecase.PipelineStatus, error) {
+	resp := new(usecase.PipelineStatus)
+	return resp, c.invoke(ctx, "GetPipelineStatus", req, resp, opts)
+}
+
+// WatchPipelineStatus открывает стрим статусов пайплайна заказа
+func (c *Client) WatchPipelineStatus(ctx context.Context, req *dto.OrderRequest, opts ...grpc.CallOption) (*StatusStream, error) {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+
+	stream, err := c.conn.NewStream(ctx, &serviceDesc.Streams[0], "/"+ServiceName+"/WatchPipelineStatus", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &StatusStream{stream: stream}, nil
+}
+
+// invoke вызывает унарный метод сервиса
+func (c *Client) invoke(ctx context.Context, method string, req, resp interface{}, opts []grpc.CallOption) error {
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	return c.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp, opts...)
+}
+
+// StatusStream стрим статусов пайплайна
+type StatusStream struct {
+	stream grpc.ClientStream
+}
+
+// Recv получает следующий статус; io.EOF - пайплайн завершен
+func (s *StatusStream) Recv() (*usecase.PipelineStatus, error) {
+	pipelineStatus := new(usecase.PipelineStatus)
+	if err := s.stream.RecvMsg(pipelineStatus); err != nil {
+		return nil, err
+	}
+	return pipelineStatus, nil
+}
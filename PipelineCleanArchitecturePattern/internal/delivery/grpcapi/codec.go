@@ -0,0 +1,38 @@
+package grpcapi
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// 📦 JSON КОДЕК
+//
+// Сообщения сервиса - DTO из internal/delivery/dto, а не protobuf, поэтому
+// сервис и клиент договариваются о content-subtype "json"
+// (application/grpc+json). Кодек регистрируется при импорте пакета.
+
+// codecName content-subtype сообщений сервиса
+const codecName = "json"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+// jsonCodec кодирует сообщения gRPC в JSON
+type jsonCodec struct{}
+
+// Marshal кодирует сообщение
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+// Unmarshal декодирует сообщение
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// Name имя кодека (content-subtype)
+func (jsonCodec) Name() string {
+	return codecName
+}
@@ -0,0 +1,327 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+
+	"pipeline-clean-architecture/internal/delivery/dto"
+	"pipeline-clean-architecture/internal/domain/order"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// 🛰️ gRPC СЕРВИС ЗАКАЗОВ
+//
+// ============================================================================
+// СЕРВИС orders.v1.OrderService:
+// ============================================================================
+//
+// Унарные методы повторяют REST API (rest.Handler), сообщения - те же DTO.
+// WatchPipelineStatus - серверный стрим: сервер присылает
+// usecase.PipelineStatus на каждом шаге и закрывает стрим после итогового
+// статуса.
+//
+// Описание сервиса (serviceDesc) написано вручную: сообщения кодируются
+// JSON кодеком (codec.go), сгенерированный protobuf код не нужен.
+//
+// Ошибки use case слоя переводятся в gRPC коды (см. toStatus).
+//
+// ============================================================================
+
+// ServiceName полное имя gRPC сервиса
+const ServiceName = "orders.v1.OrderService"
+
+// Server реализация gRPC сервиса заказов
+type Server struct {
+	processor usecase.OrderProcessor
+	executor  usecase.PipelineExecutor
+	logger    *zap.Logger
+}
+
+// NewServer создает gRPC сервис заказов
+func NewServer(processor usecase.OrderProcessor, executor usecase.PipelineExecutor, logger *zap.Logger) *Server {
+	return &Server{
+		processor: processor,
+		executor:  executor,
+		logger:    logger,
+	}
+}
+
+// Register регистрирует сервис в gRPC сервере
+func (s *Server) Register(registrar grpc.ServiceRegistrar) {
+	registrar.RegisterService(&serviceDesc, s)
+}
+
+// 📝 ЗАКАЗЫ
+
+// CreateOrder создает заказ
+func (s *Server) CreateOrder(ctx context.Context, req *dto.CreateOrderRequest) (*dto.CreateOrderResponse, error) {
+	ucReq, err := req.ToUseCase()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.processor.CreateOrder(ctx, ucReq)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromCreateOrderResponse(resp), nil
+}
+
+// ValidateOrder проверяет заказ
+func (s *Server) ValidateOrder(ctx context.Context, req *dto.OrderRequest) (*usecase.ValidateOrderResponse, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.processor.ValidateOrder(ctx, orderID)
+}
+
+// ProcessPayment оплачивает заказ
+func (s *Server) ProcessPayment(ctx context.Context, req *usecase.ProcessPaymentRequest) (*dto.ProcessPaymentResponse, error) {
+	resp, err := s.processor.ProcessPayment(ctx, *req)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromProcessPaymentResponse(resp), nil
+}
+
+// CheckInventory проверяет наличие товаров заказа
+func (s *Server) CheckInventory(ctx context.Context, req *dto.OrderRequest) (*dto.CheckInventoryResponse, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.processor.CheckInventory(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromCheckInventoryResponse(resp), nil
+}
+
+// ReserveInventory резервирует товары заказа
+func (s *Server) ReserveInventory(ctx context.Context, req *dto.OrderRequest) (*usecase.ReserveInventoryResponse, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.processor.ReserveInventory(ctx, orderID)
+}
+
+// ArrangeDelivery организует доставку заказа
+func (s *Server) ArrangeDelivery(ctx context.Context, req *dto.OrderRequest) (*dto.ArrangeDeliveryResponse, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.processor.ArrangeDelivery(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromArrangeDeliveryResponse(resp), nil
+}
+
+// SendNotification отправляет уведомление о событии заказа
+func (s *Server) SendNotification(ctx context.Context, req *dto.NotificationRequest) (*dto.Empty, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.processor.SendNotifications(ctx, orderID, req.Event); err != nil {
+		return nil, err
+	}
+	return &dto.Empty{}, nil
+}
+
+// GetOrderStats возвращает статистику заказов
+func (s *Server) GetOrderStats(ctx context.Context, req *dto.StatsRequest) (*dto.StatsResponse, error) {
+	ucReq, err := req.ToUseCase()
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.processor.GetOrderStats(ctx, ucReq)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromStatsResponse(resp), nil
+}
+
+// 🔄 ПАЙПЛАЙН
+
+// ExecuteOrderPipeline выполняет пайплайн заказа и возвращает результат
+func (s *Server) ExecuteOrderPipeline(ctx context.Context, req *dto.OrderRequest) (*dto.PipelineResult, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.executor.ExecuteOrderPipeline(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromPipelineResult(result), nil
+}
+
+// ExecuteStep выполняет отдельный шаг пайплайна
+func (s *Server) ExecuteStep(ctx context.Context, req *dto.StepRequest) (*dto.StepResult, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	step, err := dto.ParseStep(req.Step)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.executor.ExecuteStep(ctx, step, orderID)
+	if err != nil {
+		return nil, err
+	}
+	return dto.FromStepResult(result), nil
+}
+
+// GetPipelineStatus возвращает статус последнего пайплайна заказа
+func (s *Server) GetPipelineStatus(ctx context.Context, req *dto.OrderRequest) (*usecase.PipelineStatus, error) {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return nil, err
+	}
+	return s.executor.GetPipelineStatus(ctx, orderID)
+}
+
+// WatchPipelineStatus отправляет статус пайплайна в стрим до итогового статуса
+func (s *Server) WatchPipelineStatus(req *dto.OrderRequest, stream grpc.ServerStream) error {
+	orderID, err := dto.ParseOrderID(req.OrderID)
+	if err != nil {
+		return err
+	}
+
+	// Подписка снимается при отмене контекста стрима
+	updates, err := s.executor.WatchPipelineStatus(stream.Context(), orderID)
+	if err != nil {
+		return err
+	}
+
+	for pipelineStatus := range updates {
+		pipelineStatus := pipelineStatus
+		if err := stream.SendMsg(&pipelineStatus); err != nil {
+			return err
+		}
+	}
+	return stream.Context().Err()
+}
+
+// ⚠️ ОШИБКИ
+
+// fail переводит ошибку метода в gRPC статус и логирует внутренние ошибки
+func (s *Server) fail(method string, err error) error {
+	st := toStatus(err)
+	if status.Code(st) == codes.Internal {
+		s.logger.Error("Order gRPC call failed",
+			zap.String("method", method),
+			zap.Error(err))
+	}
+	return st
+}
+
+// toStatus переводит ошибку use case слоя в gRPC статус
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := status.FromError(err); ok {
+		return err
+	}
+
+	code := codes.Internal
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, usecase.ErrInvalidRequest), errors.Is(err, usecase.ErrStepNotSupported):
+		code = codes.InvalidArgument
+	case errors.Is(err, order.ErrOrderNotFound), errors.Is(err, usecase.ErrPipelineNotFound):
+		code = codes.NotFound
+	case errors.Is(err, usecase.ErrPipelineRunning):
+		code = codes.Aborted
+	case errors.Is(err, usecase.ErrPaymentFailed), errors.Is(err, order.ErrMixedCurrencies):
+		code = codes.FailedPrecondition
+	case errors.Is(err, usecase.ErrNotConfigured):
+		code = codes.Unimplemented
+	}
+	return status.Error(code, err.Error())
+}
+
+// 📜 ОПИСАНИЕ СЕРВИСА
+
+// unary описание унарного метода с JSON сообщениями Req и Resp
+func unary[Req, Resp any](name string, call func(s *Server, ctx context.Context, req *Req) (*Resp, error)) grpc.MethodDesc {
+	return grpc.MethodDesc{
+		MethodName: name,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				resp, err := call(srv.(*Server), ctx, req.(*Req))
+				if err != nil {
+					return nil, srv.(*Server).fail(name, err)
+				}
+				return resp, nil
+			}
+			if interceptor == nil {
+				return handler(ctx, req)
+			}
+
+			info := &grpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + name}
+			return interceptor(ctx, req, info, handler)
+		},
+	}
+}
+
+// watchPipelineStatusHandler обработчик серверного стрима WatchPipelineStatus
+func watchPipelineStatusHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(dto.OrderRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	if err := srv.(*Server).WatchPipelineStatus(req, stream); err != nil {
+		return srv.(*Server).fail("WatchPipelineStatus", err)
+	}
+	return nil
+}
+
+// serviceDesc описание сервиса orders.v1.OrderService
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []grpc.MethodDesc{
+		unary("CreateOrder", (*Server).CreateOrder),
+		unary("ValidateOrder", (*Server).ValidateOrder),
+		unary("ProcessPayment", (*Server).ProcessPayment),
+		unary("CheckInventory", (*Server).CheckInventory),
+		unary("ReserveInventory", (*Server).ReserveInventory),
+		unary("ArrangeDelivery", (*Server).ArrangeDelivery),
+		unary("SendNotification", (*Server).SendNotification),
+		unary("GetOrderStats", (*Server).GetOrderStats),
+		unary("ExecuteOrderPipeline", (*Server).ExecuteOrderPipeline),
+		unary("ExecuteStep", (*Server).ExecuteStep),
+		unary("GetPipelineStatus", (*Server).GetPipelineStatus),
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchPipelineStatus",
+			Handler:       watchPipelineStatusHandler,
+			ServerStreams: true,
+		},
+	},
+}
@@ -0,0 +1,138 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/delivery/dto"
+	"pipeline-clean-architecture/internal/domain/order"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// fakeAPI use case слой с подменяемыми методами; остальные методы паникуют
+type fakeAPI struct {
+	usecase.OrderProcessor
+	usecase.PipelineExecutor
+
+	orders map[uuid.UUID]bool
+}
+
+func (f *fakeAPI) ExecuteOrderPipeline(ctx context.Context, orderID uuid.UUID) (*usecase.PipelineResult, error) {
+	if !f.orders[orderID] {
+		return nil, fmt.Errorf("order %s: %w", orderID, order.ErrOrderNotFound)
+	}
+	return &usecase.PipelineResult{
+		OrderID:     orderID,
+		Success:     true,
+		Duration:    1500 * time.Millisecond,
+		FinalStatus: order.StatusInventoryChecked,
+		Steps:       []usecase.StepResult{{Step: usecase.StepValidation, Success: true}},
+	}, nil
+}
+
+func (f *fakeAPI) WatchPipelineStatus(ctx context.Context, orderID uuid.UUID) (<-chan usecase.PipelineStatus, error) {
+	updates := make(chan usecase.PipelineStatus, 3)
+	for _, step := range []usecase.PipelineStep{usecase.StepValidation, usecase.StepPaymentProcessing} {
+		updates <- usecase.PipelineStatus{OrderID: orderID, CurrentStep: step, State: usecase.PipelineStateRunning}
+	}
+	updates <- usecase.PipelineStatus{OrderID: orderID, Progress: 1, State: usecase.PipelineStateCompleted}
+	close(updates)
+	return updates, nil
+}
+
+func newClient(t *testing.T, api *fakeAPI) *Client {
+	t.Helper()
+
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer()
+	NewServer(api, api, zap.NewNop()).Register(server)
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return NewClient(conn)
+}
+
+func TestUnaryCallRoundTrip(t *testing.T) {
+	orderID := uuid.New()
+	client := newClient(t, &fakeAPI{orders: map[uuid.UUID]bool{orderID: true}})
+	ctx := context.Background()
+
+	result, err := client.ExecuteOrderPipeline(ctx, &dto.OrderRequest{OrderID: orderID.String()})
+	if err != nil {
+		t.Fatalf("ExecuteOrderPipeline() error = %v", err)
+	}
+	if result.OrderID != orderID || result.FinalStatus != "inventory_checked" || result.DurationMs != 1500 {
+		t.Errorf("result = %+v", result)
+	}
+	if len(result.Steps) != 1 || result.Steps[0].Step != usecase.StepValidation {
+		t.Errorf("steps = %+v, want validation", result.Steps)
+	}
+}
+
+func TestErrorsMapToStatusCodes(t *testing.T) {
+	client := newClient(t, &fakeAPI{})
+	ctx := context.Background()
+
+	_, err := client.ExecuteOrderPipeline(ctx, &dto.OrderRequest{OrderID: uuid.New().String()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("unknown order: code = %s, want NotFound", status.Code(err))
+	}
+
+	_, err = client.ExecuteOrderPipeline(ctx, &dto.OrderRequest{OrderID: "42"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("invalid id: code = %s, want InvalidArgument", status.Code(err))
+	}
+}
+
+func TestWatchPipelineStatusStreamsUntilFinal(t *testing.T) {
+	client := newClient(t, &fakeAPI{})
+	orderID := uuid.New()
+
+	stream, err := client.WatchPipelineStatus(context.Background(), &dto.OrderRequest{OrderID: orderID.String()})
+	if err != nil {
+		t.Fatalf("WatchPipelineStatus() error = %v", err)
+	}
+
+	var states []usecase.PipelineState
+	for {
+		pipelineStatus, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		if pipelineStatus.OrderID != orderID {
+			t.Errorf("status for order %s, want %s", pipelineStatus.OrderID, orderID)
+		}
+		states = append(states, pipelineStatus.State)
+	}
+
+	want := []usecase.PipelineState{usecase.PipelineStateRunning, usecase.PipelineStateRunning, usecase.PipelineStateCompleted}
+	if fmt.Sprint(states) != fmt.Sprint(want) {
+		t.Errorf("states = %v, want %v", states, want)
+	}
+}
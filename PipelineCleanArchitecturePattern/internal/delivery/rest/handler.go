@@ -0,0 +1,356 @@
+package rest
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"pipeline-clean-architecture/internal/delivery/dto"
+	"pipeline-clean-architecture/internal/domain/order"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 🌐 REST API ЗАКАЗОВ
+//
+// ============================================================================
+// МАРШРУТЫ (/api/v1/orders):
+// ============================================================================
+//
+//   POST /                        создать заказ
+//   GET  /stats                   статистика заказов
+//   POST /:id/validate            валидация заказа
+//   POST /:id/payment             оплата заказа
+//   GET  /:id/inventory           проверка склада
+//   POST /:id/inventory/reserve   резервирование товаров
+//   POST /:id/delivery            организация доставки
+//   POST /:id/notifications       уведомление клиента
+//   POST /:id/pipeline            полный пайплайн (ответ после завершения)
+//   GET  /:id/pipeline            статус последнего пайплайна
+//   GET  /:id/pipeline/stream     прогресс пайплайна (Server-Sent Events)
+//   POST /:id/steps/:step         отдельный шаг пайплайна
+//
+// ============================================================================
+// ПРОГРЕСС ДЛЯ ВИТРИНЫ:
+// ============================================================================
+//
+// Витрина открывает /pipeline/stream и запускает POST /pipeline. Поток
+// ждет запуска пайплайна, присылает событие "status" на каждом шаге и
+// закрывается после итогового статуса (state = completed или failed).
+//
+// Ошибки возвращаются как {"error": "..."}; код ответа - по sentinel
+// ошибкам use case и доменного слоя (см. statusCode).
+//
+// ============================================================================
+
+// Handler HTTP обработчик API заказов
+type Handler struct {
+	processor usecase.OrderProcessor
+	executor  usecase.PipelineExecutor
+	logger    *zap.Logger
+}
+
+// NewHandler создает обработчик API заказов
+func NewHandler(processor usecase.OrderProcessor, executor usecase.PipelineExecutor, logger *zap.Logger) *Handler {
+	return &Handler{
+		processor: processor,
+		executor:  executor,
+		logger:    logger,
+	}
+}
+
+// Register регистрирует маршруты API в роутере
+func (h *Handler) Register(router gin.IRouter) {
+	orders := router.Group("/api/v1/orders")
+
+	orders.POST("", h.createOrder)
+	orders.GET("/stats", h.getOrderStats)
+
+	orders.POST("/:id/validate", h.validateOrder)
+	orders.POST("/:id/payment", h.processPayment)
+	orders.GET("/:id/inventory", h.checkInventory)
+	orders.POST("/:id/inventory/reserve", h.reserveInventory)
+	orders.POST("/:id/delivery", h.arrangeDelivery)
+	orders.POST("/:id/notifications", h.sendNotification)
+
+	orders.POST("/:id/pipeline", h.executePipeline)
+	orders.GET("/:id/pipeline", h.getPipelineStatus)
+	orders.GET("/:id/pipeline/stream", h.streamPipelineStatus)
+	orders.POST("/:id/steps/:step", h.executeStep)
+}
+
+// 📝 ЗАКАЗЫ
+
+func (h *Handler) createOrder(c *gin.Context) {
+	var body dto.CreateOrderRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	req, err := body.ToUseCase()
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	resp, err := h.processor.CreateOrder(c.Request.Context(), req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, dto.FromCreateOrderResponse(resp))
+}
+
+func (h *Handler) validateOrder(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.processor.ValidateOrder(c.Request.Context(), orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) processPayment(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	var req usecase.ProcessPaymentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+	req.OrderID = orderID
+
+	resp, err := h.processor.ProcessPayment(c.Request.Context(), req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromProcessPaymentResponse(resp))
+}
+
+func (h *Handler) checkInventory(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.processor.CheckInventory(c.Request.Context(), orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromCheckInventoryResponse(resp))
+}
+
+func (h *Handler) reserveInventory(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.processor.ReserveInventory(c.Request.Context(), orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+func (h *Handler) arrangeDelivery(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	resp, err := h.processor.ArrangeDelivery(c.Request.Context(), orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromArrangeDeliveryResponse(resp))
+}
+
+func (h *Handler) sendNotification(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.NotificationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		h.badRequest(c, err)
+		return
+	}
+
+	if err := h.processor.SendNotifications(c.Request.Context(), orderID, req.Event); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusAccepted)
+}
+
+func (h *Handler) getOrderStats(c *gin.Context) {
+	query := dto.StatsRequest{
+		CustomerID: c.Query("customer_id"),
+		DateFrom:   c.Query("date_from"),
+		DateTo:     c.Query("date_to"),
+		Status:     c.Query("status"),
+		Source:     c.Query("source"),
+		GroupBy:    c.Query("group_by"),
+	}
+
+	req, err := query.ToUseCase()
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	resp, err := h.processor.GetOrderStats(c.Request.Context(), req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromStatsResponse(resp))
+}
+
+// 🔄 ПАЙПЛАЙН
+
+func (h *Handler) executePipeline(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.executor.ExecuteOrderPipeline(c.Request.Context(), orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromPipelineResult(result))
+}
+
+func (h *Handler) executeStep(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	step, err := dto.ParseStep(c.Param("step"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	result, err := h.executor.ExecuteStep(c.Request.Context(), step, orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromStepResult(result))
+}
+
+func (h *Handler) getPipelineStatus(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	status, err := h.executor.GetPipelineStatus(c.Request.Context(), orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, status)
+}
+
+// streamPipelineStatus отправляет статус пайплайна событиями SSE
+//
+// Поток завершается после итогового статуса или при отключении клиента.
+func (h *Handler) streamPipelineStatus(c *gin.Context) {
+	orderID, ok := h.orderID(c)
+	if !ok {
+		return
+	}
+
+	updates, err := h.executor.WatchPipelineStatus(c.Request.Context(), orderID)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case status, ok := <-updates:
+			if !ok {
+				return false
+			}
+			c.SSEvent("status", status)
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+// 🔧 ВСПОМОГАТЕЛЬНЫЕ МЕТОДЫ
+
+// orderID разбирает ID заказа из пути; при ошибке ответ уже отправлен
+func (h *Handler) orderID(c *gin.Context) (uuid.UUID, bool) {
+	orderID, err := dto.ParseOrderID(c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return uuid.Nil, false
+	}
+	return orderID, true
+}
+
+// badRequest отвечает на тело запроса, которое не удалось разобрать
+func (h *Handler) badRequest(c *gin.Context, err error) {
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+}
+
+// fail отвечает ошибкой с кодом по ее типу
+func (h *Handler) fail(c *gin.Context, err error) {
+	code := statusCode(err)
+	if code >= http.StatusInternalServerError {
+		h.logger.Error("Order API request failed",
+			zap.String("method", c.Request.Method),
+			zap.String("path", c.FullPath()),
+			zap.Error(err))
+	}
+	c.AbortWithStatusJSON(code, gin.H{"error": err.Error()})
+}
+
+// statusCode HTTP код ответа для ошибки use case слоя
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, usecase.ErrInvalidRequest), errors.Is(err, usecase.ErrStepNotSupported):
+		return http.StatusBadRequest
+	case errors.Is(err, order.ErrOrderNotFound), errors.Is(err, usecase.ErrPipelineNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, usecase.ErrPipelineRunning):
+		return http.StatusConflict
+	case errors.Is(err, usecase.ErrPaymentFailed):
+		return http.StatusPaymentRequired
+	case errors.Is(err, order.ErrMixedCurrencies):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, usecase.ErrNotConfigured):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,183 @@
+package rest
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	usecase "pipeline-clean-architecture/internal/usecase/order_processing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakeAPI use case слой с подменяемыми методами; остальные методы паникуют
+type fakeAPI struct {
+	usecase.OrderProcessor
+	usecase.PipelineExecutor
+
+	createOrder func(req usecase.CreateOrderRequest) (*usecase.CreateOrderResponse, error)
+	statusErr   error
+	pipelineErr error
+	deliveryErr error
+	watch       func(orderID uuid.UUID) (<-chan usecase.PipelineStatus, error)
+}
+
+func (f *fakeAPI) CreateOrder(ctx context.Context, req usecase.CreateOrderRequest) (*usecase.CreateOrderResponse, error) {
+	return f.createOrder(req)
+}
+
+func (f *fakeAPI) ArrangeDelivery(ctx context.Context, orderID uuid.UUID) (*usecase.ArrangeDeliveryResponse, error) {
+	return nil, f.deliveryErr
+}
+
+func (f *fakeAPI) GetPipelineStatus(ctx context.Context, orderID uuid.UUID) (*usecase.PipelineStatus, error) {
+	return nil, f.statusErr
+}
+
+func (f *fakeAPI) ExecuteOrderPipeline(ctx context.Context, orderID uuid.UUID) (*usecase.PipelineResult, error) {
+	return nil, f.pipelineErr
+}
+
+func (f *fakeAPI) WatchPipelineStatus(ctx context.Context, orderID uuid.UUID) (<-chan usecase.PipelineStatus, error) {
+	return f.watch(orderID)
+}
+
+func newRouter(api *fakeAPI) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewHandler(api, api, zap.NewNop()).Register(router)
+	return router
+}
+
+func TestCreateOrderReturnsOrderView(t *testing.T) {
+	productID := uuid.New()
+	var got usecase.CreateOrderRequest
+	api := &fakeAPI{createOrder: func(req usecase.CreateOrderRequest) (*usecase.CreateOrderResponse, error) {
+		got = req
+		item, err := order.NewOrderItem(productID, 2, order.NewMoney(50000, "RUB"))
+		if err != nil {
+			return nil, err
+		}
+		ord, err := order.NewOrder(req.CustomerID, []order.OrderItem{item}, req.ShippingAddress)
+		if err != nil {
+			return nil, err
+		}
+		return &usecase.CreateOrderResponse{Order: ord, NextSteps: []string{"validation"}}, nil
+	}}
+
+	body := fmt.Sprintf(`{
+		"customer_id": %q,
+		"items": [{"product_id": %q, "quantity": 2}],
+		"shipping_address": {"street": "Тверская 1", "city": "Москва", "postal_code": "125009", "country": "RU", "phone": "+7"},
+		"payment_method": "card",
+		"priority": "urgent"
+	}`, uuid.New(), productID)
+
+	rec := httptest.NewRecorder()
+	newRouter(api).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/v1/orders", strings.NewReader(body)))
+
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	if got.Priority != order.PriorityUrgent || got.Source != order.SourceWeb || got.ShippingAddress.City() != "Москва" {
+		t.Errorf("use case request = priority %s source %s city %q", got.Priority, got.Source, got.ShippingAddress.City())
+	}
+
+	var resp struct {
+		Order struct {
+			Status      string `json:"status"`
+			TotalAmount struct {
+				Amount int64 `json:"amount"`
+			} `json:"total_amount"`
+		} `json:"order"`
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Order.Status != "pending" || resp.Order.TotalAmount.Amount != 100000 {
+		t.Errorf("order view = %+v, want pending 100000", resp.Order)
+	}
+}
+
+func TestErrorsMapToStatusCodes(t *testing.T) {
+	api := &fakeAPI{
+		statusErr:   fmt.Errorf("order x: %w", usecase.ErrPipelineNotFound),
+		pipelineErr: fmt.Errorf("order x: %w", usecase.ErrPipelineRunning),
+		deliveryErr: fmt.Errorf("delivery service: %w", usecase.ErrNotConfigured),
+	}
+	router := newRouter(api)
+	id := uuid.New().String()
+
+	tests := []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/api/v1/orders/not-a-uuid/pipeline", http.StatusBadRequest},
+		{http.MethodGet, "/api/v1/orders/" + id + "/pipeline", http.StatusNotFound},
+		{http.MethodPost, "/api/v1/orders/" + id + "/pipeline", http.StatusConflict},
+		{http.MethodPost, "/api/v1/orders/" + id + "/delivery", http.StatusNotImplemented},
+		{http.MethodGet, "/api/v1/orders/stats?date_from=yesterday", http.StatusBadRequest},
+		{http.MethodPost, "/api/v1/orders", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, strings.NewReader("{")))
+
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.path, rec.Code, tt.want)
+		}
+		if !strings.Contains(rec.Body.String(), `"error"`) {
+			t.Errorf("%s %s body = %s, want error field", tt.method, tt.path, rec.Body)
+		}
+	}
+}
+
+func TestStreamPipelineStatusSendsEvents(t *testing.T) {
+	orderID := uuid.New()
+	api := &fakeAPI{watch: func(id uuid.UUID) (<-chan usecase.PipelineStatus, error) {
+		updates := make(chan usecase.PipelineStatus, 2)
+		updates <- usecase.PipelineStatus{OrderID: id, CurrentStep: usecase.StepValidation, State: usecase.PipelineStateRunning}
+		updates <- usecase.PipelineStatus{OrderID: id, Progress: 1, State: usecase.PipelineStateCompleted}
+		close(updates)
+		return updates, nil
+	}}
+
+	server := httptest.NewServer(newRouter(api))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/v1/orders/" + orderID.String() + "/pipeline/stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "text/event-stream") {
+		t.Errorf("content type = %q, want text/event-stream", ct)
+	}
+
+	var states []usecase.PipelineState
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		var status usecase.PipelineStatus
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data:")), &status); err != nil {
+			t.Fatalf("event data %q: %v", line, err)
+		}
+		states = append(states, status.State)
+	}
+
+	if len(states) != 2 || states[0] != usecase.PipelineStateRunning || states[1] != usecase.PipelineStateCompleted {
+		t.Errorf("streamed states = %v, want [running completed]", states)
+	}
+}
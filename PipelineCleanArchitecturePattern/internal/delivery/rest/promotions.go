@@ -0,0 +1,81 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"pipeline-clean-architecture/internal/delivery/dto"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/promotion"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 🏷️ СКИДКИ ЗАКАЗА
+//
+//   POST /api/v1/orders/:id/promotions   {"coupons": ["WELCOME"]}
+//
+// Скидки пересчитываются с нуля: автоматические правила плюс правила
+// переданных купонов. Пустой список купонов снимает купонные скидки.
+// Купоны, которые существуют, но не сработали, возвращаются в
+// rejected_coupons с причиной; неизвестный купон - ошибка 422.
+
+// PromotionApplier применение правил скидок к заказу
+//
+// Реализуется promotionservice.Service.
+type PromotionApplier interface {
+	ApplyPromotions(ctx context.Context, orderID uuid.UUID, coupons []string) (*order.Order, *promotion.Result, error)
+}
+
+// PromotionHandler HTTP обработчик скидок
+type PromotionHandler struct {
+	promotions PromotionApplier
+	logger     *zap.Logger
+}
+
+// NewPromotionHandler создает обработчик скидок
+func NewPromotionHandler(promotions PromotionApplier, logger *zap.Logger) *PromotionHandler {
+	return &PromotionHandler{promotions: promotions, logger: logger}
+}
+
+// Register регистрирует маршрут скидок в роутере
+func (h *PromotionHandler) Register(router gin.IRouter) {
+	router.POST("/api/v1/orders/:id/promotions", h.applyPromotions)
+}
+
+func (h *PromotionHandler) applyPromotions(c *gin.Context) {
+	orderID, err := dto.ParseOrderID(c.Param("id"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+
+	var body dto.ApplyPromotionsRequest
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ord, result, err := h.promotions.ApplyPromotions(c.Request.Context(), orderID, body.Coupons)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, dto.FromPromotionResult(ord, result))
+}
+
+func (h *PromotionHandler) fail(c *gin.Context, err error) {
+	code := statusCode(err)
+	if errors.Is(err, promotion.ErrUnknownCoupon) {
+		code = http.StatusUnprocessableEntity
+	}
+	if code >= http.StatusInternalServerError {
+		h.logger.Error("Promotions request failed",
+			zap.String("path", c.FullPath()),
+			zap.Error(err))
+	}
+	c.AbortWithStatusJSON(code, gin.H{"error": err.Error()})
+}
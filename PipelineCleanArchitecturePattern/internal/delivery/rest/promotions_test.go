@@ -0,0 +1,85 @@
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pipeline-clean-architecture/internal/delivery/dto"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/promotion"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// fakePromotions знает только купон "WELCOME"
+type fakePromotions struct {
+	ord *order.Order
+}
+
+func (f *fakePromotions) ApplyPromotions(ctx context.Context, orderID uuid.UUID, coupons []string) (*order.Order, *promotion.Result, error) {
+	if orderID != f.ord.ID() {
+		return nil, nil, fmt.Errorf("%w: %s", order.ErrOrderNotFound, orderID)
+	}
+	result := &promotion.Result{Total: order.NewMoney(0, "RUB"), Rejected: map[string]error{}}
+	for _, coupon := range coupons {
+		if coupon != "WELCOME" {
+			return nil, nil, fmt.Errorf("%w: %s", promotion.ErrUnknownCoupon, coupon)
+		}
+		result.Rejected[coupon] = promotion.ErrUsageLimitReached
+	}
+	return f.ord, result, nil
+}
+
+func TestApplyPromotionsEndpoint(t *testing.T) {
+	item, err := order.NewOrderItem(uuid.New(), 1, order.NewMoney(10000, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ord, err := order.NewOrder(uuid.New(), []order.OrderItem{item}, order.NewAddress("Тверская 1", "Москва", "101000", "RU", "+7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewPromotionHandler(&fakePromotions{ord: ord}, zap.NewNop()).Register(router)
+
+	tests := []struct {
+		orderID string
+		body    string
+		want    int
+	}{
+		{ord.ID().String(), `{"coupons": ["WELCOME"]}`, http.StatusOK},
+		{ord.ID().String(), `{"coupons": ["NOPE"]}`, http.StatusUnprocessableEntity},
+		{uuid.New().String(), `{"coupons": []}`, http.StatusNotFound},
+		{"not-a-uuid", `{}`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/orders/"+tt.orderID+"/promotions", strings.NewReader(tt.body))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.orderID, tt.body, rec.Code, tt.want)
+			continue
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+
+		var resp dto.ApplyPromotionsResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err)
+		}
+		if resp.Order == nil || resp.Order.ID != ord.ID() || resp.Rejected["WELCOME"] == "" {
+			t.Errorf("response = %s", rec.Body.String())
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"pipeline-clean-architecture/internal/domain/payment"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// 📨 ВЕБХУКИ ПЛАТЕЖНЫХ ПРОВАЙДЕРОВ
+//
+//   POST /api/v1/payments/webhooks/:provider
+//
+// Тело передается процессору без изменений: подпись (заголовок X-Signature)
+// считается от сырых байтов. 2xx - событие принято (в том числе повторное),
+// иначе провайдер доставит его еще раз.
+//
+// Маршрут публичный, а подпись проверяется только после чтения тела,
+// поэтому тело ограничено maxWebhookBodySize (больше - 413).
+
+// signatureHeader заголовок подписи в протоколе адаптеров paymentgateway
+const signatureHeader = "X-Signature"
+
+// maxWebhookBodySize ограничение тела уведомления провайдера
+const maxWebhookBodySize = 1 << 20
+
+// PaymentWebhookProcessor обработчик уведомлений провайдеров
+//
+// Реализуется paymentservice.Processor.
+type PaymentWebhookProcessor interface {
+	HandleWebhook(ctx context.Context, provider payment.Provider, payload []byte, signature string) error
+}
+
+// WebhookHandler HTTP обработчик вебхуков
+type WebhookHandler struct {
+	payments PaymentWebhookProcessor
+	logger   *zap.Logger
+}
+
+// NewWebhookHandler создает обработчик вебхуков
+func NewWebhookHandler(payments PaymentWebhookProcessor, logger *zap.Logger) *WebhookHandler {
+	return &WebhookHandler{payments: payments, logger: logger}
+}
+
+// Register регистрирует маршрут вебхуков в роутере
+func (h *WebhookHandler) Register(router gin.IRouter) {
+	router.POST("/api/v1/payments/webhooks/:provider", h.handleWebhook)
+}
+
+func (h *WebhookHandler) handleWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxWebhookBodySize))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	provider := payment.Provider(c.Param("provider"))
+	err = h.payments.HandleWebhook(c.Request.Context(), provider, payload, c.GetHeader(signatureHeader))
+	if err != nil {
+		code := webhookStatusCode(err)
+		if code >= http.StatusInternalServerError {
+			h.logger.Error("Payment webhook failed",
+				zap.String("provider", provider.String()),
+				zap.Error(err))
+		}
+		c.AbortWithStatusJSON(code, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// webhookStatusCode HTTP код ответа для ошибки обработки вебхука
+func webhookStatusCode(err error) int {
+	switch {
+	case errors.Is(err, payment.ErrInvalidSignature):
+		return http.StatusUnauthorized
+	case errors.Is(err, payment.ErrProviderUnknown), errors.Is(err, payment.ErrPaymentNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
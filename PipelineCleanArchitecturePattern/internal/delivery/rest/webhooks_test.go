@@ -0,0 +1,72 @@
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/payment"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+// fakeWebhooks принимает вебхуки только с подписью "valid"
+type fakeWebhooks struct {
+	provider payment.Provider
+	payload  string
+}
+
+func (f *fakeWebhooks) HandleWebhook(ctx context.Context, provider payment.Provider, payload []byte, signature string) error {
+	switch {
+	case provider != payment.ProviderSBP:
+		return fmt.Errorf("%w: %s", payment.ErrProviderUnknown, provider)
+	case signature != "valid":
+		return payment.ErrInvalidSignature
+	}
+	f.provider, f.payload = provider, string(payload)
+	return nil
+}
+
+func TestPaymentWebhooks(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	webhooks := &fakeWebhooks{}
+	NewWebhookHandler(webhooks, zap.NewNop()).Register(router)
+
+	tests := []struct {
+		provider, signature string
+		want                int
+	}{
+		{"sbp", "valid", http.StatusNoContent},
+		{"sbp", "forged", http.StatusUnauthorized},
+		{"paypal", "valid", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/webhooks/"+tt.provider, strings.NewReader(`{"id":"evt_1"}`))
+		req.Header.Set("X-Signature", tt.signature)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != tt.want {
+			t.Errorf("%s with %s signature = %d, want %d", tt.provider, tt.signature, rec.Code, tt.want)
+		}
+	}
+
+	if webhooks.provider != payment.ProviderSBP || webhooks.payload != `{"id":"evt_1"}` {
+		t.Errorf("processor got %s %q, want raw sbp payload", webhooks.provider, webhooks.payload)
+	}
+
+	// Неподписанное тело без ограничения не читается целиком
+	webhooks.payload = ""
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/payments/webhooks/sbp", strings.NewReader(strings.Repeat("x", maxWebhookBodySize+1)))
+	req.Header.Set("X-Signature", "valid")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	if rec.Code != http.StatusRequestEntityTooLarge || webhooks.payload != "" {
+		t.Errorf("oversized webhook = %d, processed %d bytes; want %d and nothing processed", rec.Code, len(webhooks.payload), http.StatusRequestEntityTooLarge)
+	}
+}
@@ -0,0 +1,106 @@
+package money
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+)
+
+// 💱 КУРСЫ ВАЛЮТ
+
+// ErrRateNotFound у провайдера нет курса для пары валют
+var ErrRateNotFound = errors.New("exchange rate not found")
+
+// ExchangeRateProvider источник курсов валют (порт для инфраструктуры)
+type ExchangeRateProvider interface {
+	// Rate возвращает курс from → to: 1 единица from = Value() единиц to
+	Rate(ctx context.Context, from, to string) (Rate, error)
+}
+
+// Rate курс пары валют на момент AsOf
+type Rate struct {
+	from  string
+	to    string
+	value *big.Rat
+	asOf  time.Time
+}
+
+// NewRate создает курс из десятичной записи ("101.2534")
+func NewRate(from, to, value string, asOf time.Time) (Rate, error) {
+	parsed, ok := new(big.Rat).SetString(value)
+	if !ok {
+		return Rate{}, fmt.Errorf("invalid exchange rate %q for %s/%s", value, from, to)
+	}
+	return newRate(from, to, parsed, asOf)
+}
+
+// NewRateFromRat создает курс из дроби (кросс-курсы провайдеров)
+func NewRateFromRat(from, to string, value *big.Rat, asOf time.Time) (Rate, error) {
+	if value == nil {
+		return Rate{}, fmt.Errorf("exchange rate for %s/%s is empty", from, to)
+	}
+	return newRate(from, to, new(big.Rat).Set(value), asOf)
+}
+
+// IdentityRate курс валюты к самой себе
+func IdentityRate(currency string) Rate {
+	currency = normalizeCurrency(currency)
+	return Rate{from: currency, to: currency, value: big.NewRat(1, 1)}
+}
+
+func newRate(from, to string, value *big.Rat, asOf time.Time) (Rate, error) {
+	from, to = normalizeCurrency(from), normalizeCurrency(to)
+	if from == "" || to == "" {
+		return Rate{}, errors.New("exchange rate currencies cannot be empty")
+	}
+	if value.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("exchange rate for %s/%s must be positive", from, to)
+	}
+	return Rate{from: from, to: to, value: value, asOf: asOf}, nil
+}
+
+func (r Rate) From() string    { return r.from }
+func (r Rate) To() string      { return r.to }
+func (r Rate) AsOf() time.Time { return r.asOf }
+func (r Rate) IsZero() bool    { return r.value == nil }
+func (r Rate) Rat() *big.Rat   { return new(big.Rat).Set(r.value) }
+func (r Rate) Value() string   { return r.value.FloatString(6) }
+func (r Rate) String() string  { return fmt.Sprintf("%s/%s %s", r.from, r.to, r.Value()) }
+
+// Inverse обратный курс to → from
+func (r Rate) Inverse() Rate {
+	return Rate{from: r.to, to: r.from, value: new(big.Rat).Inv(r.value), asOf: r.asOf}
+}
+
+// Convert пересчитывает сумму в валюте From в валюту To
+//
+// Учитывает разную разрядность валют: 1000 JPY (0 знаков) по курсу 0.6 RUB
+// дают 600.00 RUB = 60000 копеек.
+func (r Rate) Convert(m Money, mode RoundingMode) (Money, error) {
+	if r.value == nil {
+		return Money{}, errors.New("exchange rate is not set")
+	}
+	if m.currency != r.from {
+		return Money{}, fmt.Errorf("%w: cannot convert %s with %s rate", ErrCurrencyMismatch, m.currency, r)
+	}
+
+	converted := m.rat().Mul(m.rat(), r.value)
+	shift := MinorUnits(r.to) - MinorUnits(r.from)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(shift))), nil))
+	if shift >= 0 {
+		converted.Mul(converted, scale)
+	} else {
+		converted.Quo(converted, scale)
+	}
+
+	return Money{amount: round(converted, mode), currency: r.to}, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,184 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+// 💰 ДЕНЬГИ (SHARED KERNEL)
+//
+// ============================================================================
+// ОБЩИЙ VALUE OBJECT ДЛЯ ЗАКАЗОВ, ПЛАТЕЖЕЙ И ТОВАРОВ
+// ============================================================================
+//
+// Сумма хранится целым числом в минимальных единицах валюты (копейки,
+// центы; для JPY - иены), поэтому арифметика точная. Операции над двумя
+// суммами проверяют валюту и возвращают ErrCurrencyMismatch вместо
+// молчаливого сложения рублей с евро.
+//
+// Дробные операции (процент, масштабирование, конвертация по курсу)
+// округляют результат явно заданным RoundingMode. Allocate делит сумму
+// без потери копеек - так скидка на заказ раскладывается по позициям.
+//
+// order.Money, payment.Money и product.Money - псевдонимы этого типа.
+//
+// ============================================================================
+
+// ErrCurrencyMismatch операция над суммами в разных валютах
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// Money денежная сумма в минимальных единицах валюты
+type Money struct {
+	amount   int64
+	currency string
+}
+
+// minorUnits валюты с числом знаков после запятой, отличным от двух (ISO 4217)
+var minorUnits = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+}
+
+// New создает сумму; amount в минимальных единицах валюты (копейках)
+func New(amount int64, currency string) Money {
+	return Money{amount: amount, currency: normalizeCurrency(currency)}
+}
+
+// Zero нулевая сумма в валюте
+func Zero(currency string) Money {
+	return New(0, currency)
+}
+
+// Sum складывает суммы одной валюты; пустой список дает Zero(currency)
+func Sum(currency string, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, m := range amounts {
+		var err error
+		if total, err = total.Add(m); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// MinorUnits число знаков после запятой в валюте (2 для RUB, 0 для JPY)
+func MinorUnits(currency string) int {
+	if units, ok := minorUnits[normalizeCurrency(currency)]; ok {
+		return units
+	}
+	return 2
+}
+
+// 🔍 ГЕТТЕРЫ
+
+func (m Money) Amount() int64    { return m.amount }
+func (m Money) Currency() string { return m.currency }
+
+// ToFloat возвращает сумму в основных единицах (рублях)
+func (m Money) ToFloat() float64 {
+	return float64(m.amount) / math.Pow10(MinorUnits(m.currency))
+}
+
+// String возвращает строковое представление денег
+func (m Money) String() string {
+	return fmt.Sprintf("%.*f %s", MinorUnits(m.currency), m.ToFloat(), m.currency)
+}
+
+func (m Money) IsZero() bool     { return m.amount == 0 }
+func (m Money) IsPositive() bool { return m.amount > 0 }
+func (m Money) IsNegative() bool { return m.amount < 0 }
+
+// SameCurrency проверяет, что суммы в одной валюте
+func (m Money) SameCurrency(other Money) bool {
+	return m.currency == other.currency
+}
+
+// Equal сравнивает сумму и валюту
+func (m Money) Equal(other Money) bool {
+	return m.amount == other.amount && m.currency == other.currency
+}
+
+// Cmp сравнивает суммы одной валюты: -1, 0 или 1
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.amount < other.amount:
+		return -1, nil
+	case m.amount > other.amount:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// ➕ АРИФМЕТИКА
+
+// Add складывает суммы одной валюты
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount + other.amount, currency: m.currency}, nil
+}
+
+// Sub вычитает сумму той же валюты
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.checkCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{amount: m.amount - other.amount, currency: m.currency}, nil
+}
+
+// Multiply умножает на целое (цена × количество)
+func (m Money) Multiply(n int64) Money {
+	return Money{amount: m.amount * n, currency: m.currency}
+}
+
+// Negate меняет знак суммы
+func (m Money) Negate() Money {
+	return Money{amount: -m.amount, currency: m.currency}
+}
+
+// Scale умножает на дробь numerator/denominator с округлением
+func (m Money) Scale(numerator, denominator int64, mode RoundingMode) Money {
+	if denominator == 0 {
+		return Money{amount: 0, currency: m.currency}
+	}
+	factor := big.NewRat(numerator, denominator)
+	return Money{amount: round(m.rat().Mul(m.rat(), factor), mode), currency: m.currency}
+}
+
+// Percent возвращает percent процентов суммы с округлением (15.5 - это 15,5%)
+func (m Money) Percent(percent float64, mode RoundingMode) Money {
+	if math.IsNaN(percent) || math.IsInf(percent, 0) {
+		return Money{amount: 0, currency: m.currency}
+	}
+	// Десятичная запись, а не двоичное значение float64: 0.1 - ровно 1/10
+	factor, _ := new(big.Rat).SetString(fmt.Sprintf("%g", percent))
+	factor.Quo(factor, big.NewRat(100, 1))
+	return Money{amount: round(m.rat().Mul(m.rat(), factor), mode), currency: m.currency}
+}
+
+func (m Money) rat() *big.Rat {
+	return new(big.Rat).SetInt64(m.amount)
+}
+
+func (m Money) checkCurrency(other Money) error {
+	if m.currency != other.currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.currency, other.currency)
+	}
+	return nil
+}
+
+func normalizeCurrency(currency string) string {
+	return strings.ToUpper(strings.TrimSpace(currency))
+}
@@ -0,0 +1,121 @@
+package money
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestArithmeticRejectsCurrencyMismatch(t *testing.T) {
+	rub, eur := New(10000, "RUB"), New(500, "eur")
+
+	if _, err := rub.Add(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add() error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := Sum("RUB", rub, rub, eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum() error = %v, want ErrCurrencyMismatch", err)
+	}
+
+	total, err := Sum("rub", rub, rub.Multiply(2))
+	if err != nil || !total.Equal(New(30000, "RUB")) {
+		t.Errorf("Sum() = %s, %v; want 300.00 RUB", total, err)
+	}
+	if eur.String() != "5.00 EUR" || New(1500, "JPY").String() != "1500 JPY" {
+		t.Errorf("String() = %q, %q", eur.String(), New(1500, "JPY").String())
+	}
+}
+
+func TestRoundingModes(t *testing.T) {
+	tests := []struct {
+		amount int64
+		mode   RoundingMode
+		want   int64
+	}{
+		// amount / 2
+		{5, RoundHalfUp, 3},
+		{5, RoundHalfEven, 2},
+		{7, RoundHalfEven, 4},
+		{5, RoundDown, 2},
+		{5, RoundUp, 3},
+		{-5, RoundHalfUp, -3},
+		{-5, RoundHalfEven, -2},
+		{-5, RoundDown, -2},
+		{-5, RoundUp, -3},
+		{4, RoundUp, 2},
+	}
+	for _, tt := range tests {
+		if got := New(tt.amount, "RUB").Scale(1, 2, tt.mode).Amount(); got != tt.want {
+			t.Errorf("%d/2 with %s = %d, want %d", tt.amount, tt.mode, got, tt.want)
+		}
+	}
+
+	// 15% от 99.99 ₽ = 14.9985 ₽
+	price := New(9999, "RUB")
+	if got := price.Percent(15, RoundHalfUp).Amount(); got != 1500 {
+		t.Errorf("Percent(15, half up) = %d, want 1500", got)
+	}
+	if got := price.Percent(15, RoundDown).Amount(); got != 1499 {
+		t.Errorf("Percent(15, down) = %d, want 1499", got)
+	}
+}
+
+func TestAllocateKeepsEveryKopeck(t *testing.T) {
+	tests := []struct {
+		amount int64
+		ratios []int64
+		want   []int64
+	}{
+		{10000, []int64{1, 1, 1}, []int64{3334, 3333, 3333}},
+		{-10000, []int64{1, 1, 1}, []int64{-3334, -3333, -3333}},
+		{5, []int64{3, 7}, []int64{2, 3}},
+		{100, []int64{0, 1}, []int64{0, 100}},
+		// Отброшены 1/3 и 2/3 копейки: копейка достается второй части
+		{10, []int64{1, 2}, []int64{3, 7}},
+	}
+	for _, tt := range tests {
+		parts, err := New(tt.amount, "RUB").Allocate(tt.ratios...)
+		if err != nil {
+			t.Fatalf("Allocate(%d, %v) error = %v", tt.amount, tt.ratios, err)
+		}
+		for i := range parts {
+			if parts[i].Amount() != tt.want[i] {
+				t.Errorf("Allocate(%d, %v) = %v, want %v", tt.amount, tt.ratios, parts, tt.want)
+				break
+			}
+		}
+	}
+
+	if _, err := New(100, "RUB").Allocate(0, 0); err == nil {
+		t.Error("Allocate with zero ratios error = nil")
+	}
+}
+
+func TestRateConvertsBetweenMinorUnits(t *testing.T) {
+	asOf := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+
+	eurRub, err := NewRate("EUR", "RUB", "101.2534", asOf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 19.99 EUR × 101.2534 = 2024.055466 RUB
+	rub, err := eurRub.Convert(New(1999, "EUR"), RoundHalfUp)
+	if err != nil || !rub.Equal(New(202406, "RUB")) {
+		t.Errorf("Convert(19.99 EUR) = %s, %v; want 2024.06 RUB", rub, err)
+	}
+	back, _ := eurRub.Inverse().Convert(rub, RoundHalfEven)
+	if !back.Equal(New(1999, "EUR")) {
+		t.Errorf("inverse Convert = %s, want 19.99 EUR", back)
+	}
+
+	jpyRub, _ := NewRate("JPY", "RUB", "0.6", asOf)
+	if rub, _ := jpyRub.Convert(New(1000, "JPY"), RoundHalfUp); rub.Amount() != 60000 {
+		t.Errorf("Convert(1000 JPY) = %s, want 600.00 RUB", rub)
+	}
+
+	if _, err := eurRub.Convert(New(100, "USD"), RoundHalfUp); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Convert(USD) error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := NewRate("EUR", "RUB", "-1", asOf); err == nil {
+		t.Error("NewRate(-1) error = nil")
+	}
+}
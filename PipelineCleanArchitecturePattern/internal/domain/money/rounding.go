@@ -0,0 +1,139 @@
+package money
+
+import (
+	"errors"
+	"math/big"
+)
+
+// RoundingMode правило округления до минимальной единицы валюты
+type RoundingMode int
+
+const (
+	// RoundHalfUp половина - от нуля: 0.5 → 1, -0.5 → -1 (кассовое округление)
+	RoundHalfUp RoundingMode = iota
+	// RoundHalfEven половина - к четному: 0.5 → 0, 1.5 → 2 (банковское)
+	RoundHalfEven
+	// RoundDown отбросить дробную часть (к нулю): скидки не больше расчетной
+	RoundDown
+	// RoundUp любая дробная часть - от нуля: комиссии не меньше расчетной
+	RoundUp
+)
+
+// String возвращает название режима
+func (r RoundingMode) String() string {
+	switch r {
+	case RoundHalfUp:
+		return "half_up"
+	case RoundHalfEven:
+		return "half_even"
+	case RoundDown:
+		return "down"
+	case RoundUp:
+		return "up"
+	default:
+		return "unknown"
+	}
+}
+
+// round округляет дробь до целого по режиму mode
+func round(r *big.Rat, mode RoundingMode) int64 {
+	quo, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	if rem.Sign() == 0 {
+		return quo.Int64()
+	}
+
+	// Знак результата и сравнение остатка с половиной знаменателя
+	sign := int64(r.Sign())
+	half := new(big.Int).Abs(rem)
+	half.Mul(half, big.NewInt(2))
+	cmpHalf := half.Cmp(r.Denom())
+
+	awayFromZero := false
+	switch mode {
+	case RoundUp:
+		awayFromZero = true
+	case RoundDown:
+		awayFromZero = false
+	case RoundHalfEven:
+		awayFromZero = cmpHalf > 0 || (cmpHalf == 0 && quo.Bit(0) == 1)
+	default:
+		awayFromZero = cmpHalf >= 0
+	}
+
+	// QuoRem усекает к нулю
+	if awayFromZero {
+		return quo.Int64() + sign
+	}
+	return quo.Int64()
+}
+
+// ✂️ РАСПРЕДЕЛЕНИЕ
+
+// Allocate делит сумму пропорционально ratios без потери копеек
+//
+// Части округляются вниз, остаток раздается по копейке частям с наибольшей
+// отброшенной дробью (при равенстве - первым по порядку). Сумма частей
+// всегда равна исходной: скидка 100 ₽ на три позиции с весами 1:1:1 дает
+// 33.34 + 33.33 + 33.33.
+func (m Money) Allocate(ratios ...int64) ([]Money, error) {
+	if len(ratios) == 0 {
+		return nil, errors.New("allocate needs at least one ratio")
+	}
+
+	var total int64
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, errors.New("allocation ratios cannot be negative")
+		}
+		total += ratio
+	}
+	if total == 0 {
+		return nil, errors.New("allocation ratios sum to zero")
+	}
+
+	// Отрицательную сумму делим как положительную и возвращаем знак
+	amount, sign := m.amount, int64(1)
+	if amount < 0 {
+		amount, sign = -amount, -1
+	}
+
+	parts := make([]Money, len(ratios))
+	remainders := make([]*big.Int, len(ratios))
+	allocated := int64(0)
+	for i, ratio := range ratios {
+		share, rem := new(big.Int).QuoRem(
+			new(big.Int).Mul(big.NewInt(amount), big.NewInt(ratio)),
+			big.NewInt(total), new(big.Int))
+		parts[i] = Money{amount: share.Int64(), currency: m.currency}
+		remainders[i] = rem
+		allocated += share.Int64()
+	}
+
+	for left := amount - allocated; left > 0; left-- {
+		best := -1
+		for i, rem := range remainders {
+			if rem.Sign() > 0 && (best < 0 || rem.Cmp(remainders[best]) > 0) {
+				best = i
+			}
+		}
+		parts[best].amount++
+		remainders[best].SetInt64(0)
+	}
+
+	for i := range parts {
+		parts[i].amount *= sign
+	}
+	return parts, nil
+}
+
+// Split делит сумму на n равных частей (разница между частями - не больше копейки)
+func (m Money) Split(n int) ([]Money, error) {
+	if n <= 0 {
+		return nil, errors.New("split needs a positive number of parts")
+	}
+	ratios := make([]int64, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"pipeline-clean-architecture/internal/domain/money"
+
 	"github.com/google/uuid"
 )
 
@@ -35,6 +37,11 @@ import (
 //    - Тесты запускаются за миллисекунды
 //    - 100% покрытие возможно
 //
+// 5. 📜 ИСТОРИЯ ИЗМЕНЕНИЙ (Event Sourcing):
+//    - Каждое изменение заказа - доменное событие (events.go)
+//    - Заказ восстанавливается из потока событий или снимка (snapshot.go)
+//    - Аналитика строится проекциями по тем же событиям
+//
 // ============================================================================
 // БИЗНЕС-ПРАВИЛА ЗАКАЗА:
 // ============================================================================
@@ -69,6 +76,7 @@ type Order struct {
 	// Финансовая информация  
 	totalAmount Money       // Общая стоимость заказа (рассчитывается автоматически)
 	currency    string      // Валюта заказа (обычно RUB)
+	promotions  []AppliedPromotion // Примененные правила скидок (для аудита)
 	
 	// Временные метки
 	createdAt   time.Time   // Когда заказ был создан
@@ -88,14 +96,31 @@ type Order struct {
 	notes       string    // Заметки к заказу от клиента
 	priority    Priority  // Приоритет обработки заказа (normal, high, urgent)
 	source      Source    // Откуда пришел заказ (web, mobile, api)
+	paymentMethod string  // Способ оплаты, выбранный покупателем ("" - по умолчанию)
+	
+	// ============================================================================
+	// EVENT SOURCING (см. events.go)
+	// ============================================================================
+	
+	version     int       // Номер последнего примененного события
+	changes     []Event   // Новые события, еще не сохраненные в EventStore
 }
 
 // OrderItem представляет товар в заказе
 type OrderItem struct {
-	productID uuid.UUID
-	quantity  int
-	unitPrice Money
-	discount  Money
+	productID    uuid.UUID
+	quantity     int
+	unitPrice    Money // цена в валюте заказа
+	discount     Money
+	catalogPrice Money // цена каталога до конвертации (нулевая, если валюта совпадала)
+}
+
+// AppliedPromotion правило скидки, примененное к заказу
+type AppliedPromotion struct {
+	RuleID   string // ID правила из конфигурации
+	Name     string // Название для покупателя и отчетов
+	Coupon   string // Купон, которым активировано правило ("" - автоматическое)
+	Discount Money  // Суммарная скидка правила по заказу
 }
 
 // Address представляет адрес доставки/счета
@@ -108,10 +133,10 @@ type Address struct {
 }
 
 // Money представляет денежную сумму (Value Object)
-type Money struct {
-	amount   int64 // в копейках для точности
-	currency string
-}
+//
+// Общий тип домена: арифметика с проверкой валюты, округление и
+// распределение по частям - в пакете money.
+type Money = money.Money
 
 // Status представляет статус заказа
 type Status int
@@ -164,41 +189,101 @@ func NewOrder(customerID uuid.UUID, items []OrderItem, shippingAddr Address) (*O
 		return nil, err
 	}
 	
-	order := &Order{
-		id:              uuid.New(),
-		customerID:      customerID,
-		items:           items,
-		status:          StatusPending,
-		shippingAddress: shippingAddr,
-		billingAddress:  shippingAddr, // По умолчанию такой же как доставки
-		createdAt:       time.Now(),
-		updatedAt:       time.Now(),
-		priority:        PriorityNormal,
-		source:          SourceWeb,
-		currency:        "RUB",
+	// Валюта заказа - общая валюта цен позиций
+	currency := items[0].unitPrice.Currency()
+	for _, item := range items[1:] {
+		if item.unitPrice.Currency() != currency {
+			return nil, fmt.Errorf("%w: items are priced in %s and %s, convert catalog prices with NewConvertedOrderItem",
+				money.ErrCurrencyMismatch, currency, item.unitPrice.Currency())
+		}
 	}
 	
-	// Рассчитываем общую стоимость
-	order.calculateTotalAmount()
+	// Состояние (включая общую стоимость) заполняет событие OrderCreated
+	order := &Order{id: uuid.New()}
+	order.raise(OrderCreated{
+		CustomerID:      customerID,
+		Items:           items,
+		ShippingAddress: shippingAddr,
+		BillingAddress:  shippingAddr, // По умолчанию такой же как доставки
+		Currency:        currency,
+		Priority:        PriorityNormal,
+		Source:          SourceWeb,
+	})
 	
 	return order, nil
 }
 
+// NewOrderItem создает позицию заказа
+func NewOrderItem(productID uuid.UUID, quantity int, unitPrice Money) (OrderItem, error) {
+	if productID == uuid.Nil {
+		return OrderItem{}, errors.New("product ID cannot be empty")
+	}
+	
+	if quantity <= 0 {
+		return OrderItem{}, errors.New("quantity must be positive")
+	}
+	
+	if !unitPrice.IsPositive() {
+		return OrderItem{}, errors.New("unit price must be positive")
+	}
+	
+	return OrderItem{
+		productID: productID,
+		quantity:  quantity,
+		unitPrice: unitPrice,
+		discount:  money.Zero(unitPrice.Currency()),
+	}, nil
+}
+
+// NewConvertedOrderItem создает позицию, цена которой в каталоге указана в
+// другой валюте: цена за единицу пересчитывается по курсу rate (половина
+// копейки - вверх), исходная цена сохраняется в CatalogPrice.
+func NewConvertedOrderItem(productID uuid.UUID, quantity int, catalogPrice Money, rate money.Rate) (OrderItem, error) {
+	unitPrice, err := rate.Convert(catalogPrice, money.RoundHalfUp)
+	if err != nil {
+		return OrderItem{}, err
+	}
+	
+	item, err := NewOrderItem(productID, quantity, unitPrice)
+	if err != nil {
+		return OrderItem{}, err
+	}
+	
+	if !catalogPrice.SameCurrency(unitPrice) {
+		item.catalogPrice = catalogPrice
+	}
+	return item, nil
+}
+
+// NewAddress создает адрес доставки/счета
+func NewAddress(street, city, postalCode, country, phone string) Address {
+	return Address{
+		street:     street,
+		city:       city,
+		postalCode: postalCode,
+		country:    country,
+		phone:      phone,
+	}
+}
+
+// NewMoney создает денежную сумму (amount в копейках)
+func NewMoney(amount int64, currency string) Money {
+	return money.New(amount, currency)
+}
+
 // 💰 БИЗНЕС-ЛОГИКА РАСЧЕТОВ
 
 // calculateTotalAmount рассчитывает общую стоимость заказа
 func (o *Order) calculateTotalAmount() {
-	var total int64
+	total := money.Zero(o.currency)
 	for _, item := range o.items {
-		itemTotal := item.unitPrice.amount * int64(item.quantity)
-		itemTotal -= item.discount.amount
-		total += itemTotal
+		// Валюту позиций проверяют команды (NewOrder, AddItem, ApplyDiscount)
+		if sum, err := total.Add(item.Total()); err == nil {
+			total = sum
+		}
 	}
 	
-	o.totalAmount = Money{
-		amount:   total,
-		currency: o.currency,
-	}
+	o.totalAmount = total
 }
 
 // AddItem добавляет товар в заказ
@@ -211,10 +296,14 @@ func (o *Order) AddItem(productID uuid.UUID, quantity int, unitPrice Money) erro
 		return errors.New("quantity must be positive")
 	}
 	
-	if unitPrice.amount <= 0 {
+	if !unitPrice.IsPositive() {
 		return errors.New("unit price must be positive")
 	}
 	
+	if unitPrice.Currency() != o.currency {
+		return fmt.Errorf("%w: order is in %s, item price is in %s", money.ErrCurrencyMismatch, o.currency, unitPrice.Currency())
+	}
+	
 	// Проверяем, что заказ можно изменять
 	if !o.CanModifyItems() {
 		return errors.New("cannot modify items in current status")
@@ -224,12 +313,10 @@ func (o *Order) AddItem(productID uuid.UUID, quantity int, unitPrice Money) erro
 		productID: productID,
 		quantity:  quantity,
 		unitPrice: unitPrice,
-		discount:  Money{amount: 0, currency: unitPrice.currency},
+		discount:  money.Zero(unitPrice.Currency()),
 	}
 	
-	o.items = append(o.items, item)
-	o.calculateTotalAmount()
-	o.updatedAt = time.Now()
+	o.raise(ItemAdded{Item: item})
 	
 	return nil
 }
@@ -240,11 +327,17 @@ func (o *Order) ApplyDiscount(productID uuid.UUID, discount Money) error {
 		return errors.New("cannot apply discount in current status")
 	}
 	
+	if discount.Currency() != o.currency {
+		return fmt.Errorf("%w: order is in %s, discount is in %s", money.ErrCurrencyMismatch, o.currency, discount.Currency())
+	}
+	
+	if discount.IsNegative() {
+		return errors.New("discount cannot be negative")
+	}
+	
 	for i := range o.items {
 		if o.items[i].productID == productID {
-			o.items[i].discount = discount
-			o.calculateTotalAmount()
-			o.updatedAt = time.Now()
+			o.raise(DiscountApplied{ProductID: productID, Discount: discount})
 			return nil
 		}
 	}
@@ -252,6 +345,84 @@ func (o *Order) ApplyDiscount(productID uuid.UUID, discount Money) error {
 	return errors.New("product not found in order")
 }
 
+// ApplyPromotions заменяет скидки позиций результатом движка правил и
+// записывает примененные правила (см. пакет promotion)
+//
+// discounts - скидка по товару; товары без записи остаются без скидки.
+// Прежние скидки и примененные правила заменяются: правила всегда считаются
+// от полной стоимости заказа.
+func (o *Order) ApplyPromotions(discounts map[uuid.UUID]Money, applied []AppliedPromotion) error {
+	if !o.CanModifyItems() {
+		return errors.New("cannot apply promotions in current status")
+	}
+	
+	for productID, discount := range discounts {
+		if discount.Currency() != o.currency {
+			return fmt.Errorf("%w: order is in %s, discount is in %s", money.ErrCurrencyMismatch, o.currency, discount.Currency())
+		}
+		found := false
+		for _, item := range o.items {
+			if item.productID != productID {
+				continue
+			}
+			found = true
+			if discount.IsNegative() || discount.Amount() > item.unitPrice.Multiply(int64(item.quantity)).Amount() {
+				return fmt.Errorf("discount %s for product %s exceeds item subtotal", discount, productID)
+			}
+		}
+		if !found {
+			return fmt.Errorf("product %s not found in order", productID)
+		}
+	}
+	
+	for _, item := range o.items {
+		discount, ok := discounts[item.productID]
+		if !ok {
+			discount = money.Zero(o.currency)
+		}
+		if !discount.Equal(item.discount) && !(discount.IsZero() && item.discount.IsZero()) {
+			o.raise(DiscountApplied{ProductID: item.productID, Discount: discount})
+		}
+	}
+	
+	o.raise(PromotionsApplied{Promotions: append([]AppliedPromotion(nil), applied...)})
+	return nil
+}
+
+// DistributeDiscount раскладывает скидку на весь заказ по позициям
+// пропорционально их стоимости без скидки; сумма скидок позиций равна
+// discount до копейки (money.Allocate). Прежние скидки позиций заменяются.
+func (o *Order) DistributeDiscount(discount Money) error {
+	if !o.CanModifyItems() {
+		return errors.New("cannot apply discount in current status")
+	}
+	
+	if discount.Currency() != o.currency {
+		return fmt.Errorf("%w: order is in %s, discount is in %s", money.ErrCurrencyMismatch, o.currency, discount.Currency())
+	}
+	
+	weights := make([]int64, len(o.items))
+	var subtotal int64
+	for i, item := range o.items {
+		weights[i] = item.unitPrice.Multiply(int64(item.quantity)).Amount()
+		subtotal += weights[i]
+	}
+	
+	if discount.IsNegative() || discount.Amount() > subtotal {
+		return fmt.Errorf("discount %s must be between zero and order subtotal", discount)
+	}
+	
+	shares, err := discount.Allocate(weights...)
+	if err != nil {
+		return err
+	}
+	
+	for i, item := range o.items {
+		o.raise(DiscountApplied{ProductID: item.productID, Discount: shares[i]})
+	}
+	return nil
+}
+
 // 📊 БИЗНЕС-ЛОГИКА СТАТУСОВ
 
 // CanModifyItems проверяет, можно ли изменять товары в заказе
@@ -273,8 +444,7 @@ func (o *Order) Cancel() error {
 		return errors.New("cannot cancel order in current status")
 	}
 	
-	o.status = StatusCancelled
-	o.updatedAt = time.Now()
+	o.raise(OrderCancelled{PreviousStatus: o.status})
 	return nil
 }
 
@@ -284,8 +454,7 @@ func (o *Order) MarkAsValidated() error {
 		return errors.New("can only validate pending orders")
 	}
 	
-	o.status = StatusValidated
-	o.updatedAt = time.Now()
+	o.raise(OrderValidated{})
 	return nil
 }
 
@@ -295,8 +464,7 @@ func (o *Order) MarkAsPaymentProcessing() error {
 		return errors.New("can only process payment for validated orders")
 	}
 	
-	o.status = StatusPaymentProcessing
-	o.updatedAt = time.Now()
+	o.raise(PaymentStarted{})
 	return nil
 }
 
@@ -306,8 +474,7 @@ func (o *Order) MarkAsPaid() error {
 		return errors.New("can only mark as paid orders in payment processing")
 	}
 	
-	o.status = StatusPaid
-	o.updatedAt = time.Now()
+	o.raise(OrderPaid{Amount: o.totalAmount})
 	return nil
 }
 
@@ -317,8 +484,7 @@ func (o *Order) MarkAsInventoryChecked() error {
 		return errors.New("can only check inventory for paid orders")
 	}
 	
-	o.status = StatusInventoryChecked
-	o.updatedAt = time.Time{}
+	o.raise(InventoryChecked{})
 	return nil
 }
 
@@ -328,8 +494,7 @@ func (o *Order) MarkAsShipped() error {
 		return errors.New("can only ship orders in fulfillment")
 	}
 	
-	o.status = StatusShipped
-	o.updatedAt = time.Now()
+	o.raise(OrderShipped{})
 	return nil
 }
 
@@ -339,8 +504,19 @@ func (o *Order) MarkAsDelivered() error {
 		return errors.New("can only deliver shipped orders")
 	}
 	
-	o.status = StatusDelivered
-	o.updatedAt = time.Now()
+	o.raise(OrderDelivered{})
+	return nil
+}
+
+// MarkAsRefunded помечает заказ как возвращенный (после возврата платежа)
+func (o *Order) MarkAsRefunded() error {
+	if o.status != StatusPaid && 
+	   o.status != StatusInventoryChecked && 
+	   o.status != StatusFulfillment {
+		return errors.New("can only refund paid orders")
+	}
+	
+	o.raise(OrderRefunded{Amount: o.totalAmount})
 	return nil
 }
 
@@ -352,6 +528,11 @@ func (o *Order) Items() []OrderItem     { return o.items }
 func (o *Order) Status() Status         { return o.status }
 func (o *Order) TotalAmount() Money     { return o.totalAmount }
 func (o *Order) Currency() string       { return o.currency }
+
+// Promotions возвращает примененные правила скидок
+func (o *Order) Promotions() []AppliedPromotion {
+	return append([]AppliedPromotion(nil), o.promotions...)
+}
 func (o *Order) CreatedAt() time.Time   { return o.createdAt }
 func (o *Order) UpdatedAt() time.Time   { return o.updatedAt }
 func (o *Order) ShippingAddress() Address { return o.shippingAddress }
@@ -360,18 +541,29 @@ func (o *Order) Notes() string          { return o.notes }
 func (o *Order) Priority() Priority     { return o.priority }
 func (o *Order) Source() Source         { return o.source }
 
+// PaymentMethod возвращает способ оплаты, выбранный при оформлении заказа
+func (o *Order) PaymentMethod() string { return o.paymentMethod }
+
 // 📊 ДОПОЛНИТЕЛЬНЫЕ МЕТОДЫ
 
 // SetPriority устанавливает приоритет заказа
 func (o *Order) SetPriority(priority Priority) {
-	o.priority = priority
-	o.updatedAt = time.Now()
+	o.raise(PriorityChanged{Priority: priority})
 }
 
 // SetNotes устанавливает заметки к заказу
 func (o *Order) SetNotes(notes string) {
-	o.notes = notes
-	o.updatedAt = time.Now()
+	o.raise(NotesChanged{Notes: notes})
+}
+
+// SetSource устанавливает источник заказа
+func (o *Order) SetSource(source Source) {
+	o.raise(SourceChanged{Source: source})
+}
+
+// SetPaymentMethod запоминает способ оплаты для шага оплаты пайплайна
+func (o *Order) SetPaymentMethod(method string) {
+	o.raise(PaymentMethodChanged{Method: method})
 }
 
 // SetBillingAddress устанавливает адрес для счета
@@ -380,8 +572,7 @@ func (o *Order) SetBillingAddress(address Address) error {
 		return err
 	}
 	
-	o.billingAddress = address
-	o.updatedAt = time.Time{}
+	o.raise(BillingAddressChanged{Address: address})
 	return nil
 }
 
@@ -401,27 +592,43 @@ func (oi *OrderItem) Quantity() int        { return oi.quantity }
 func (oi *OrderItem) UnitPrice() Money     { return oi.unitPrice }
 func (oi *OrderItem) Discount() Money      { return oi.discount }
 
+// CatalogPrice цена за единицу в валюте каталога (для неконвертированной
+// позиции совпадает с UnitPrice)
+func (oi *OrderItem) CatalogPrice() Money {
+	if oi.IsConverted() {
+		return oi.catalogPrice
+	}
+	return oi.unitPrice
+}
+
+// IsConverted проверяет, пересчитана ли цена позиции из другой валюты
+func (oi *OrderItem) IsConverted() bool {
+	return oi.catalogPrice.Currency() != ""
+}
+
 // Total возвращает общую стоимость позиции (количество * цена - скидка)
 func (oi *OrderItem) Total() Money {
-	total := oi.unitPrice.amount * int64(oi.quantity)
-	total -= oi.discount.amount
+	total := oi.unitPrice.Multiply(int64(oi.quantity))
+	if oi.discount.IsZero() {
+		return total
+	}
 	
-	return Money{
-		amount:   total,
-		currency: oi.unitPrice.currency,
+	if discounted, err := total.Sub(oi.discount); err == nil {
+		return discounted
 	}
+	return total
 }
 
 // 🔍 МЕТОДЫ ДЛЯ Address
 
-func (a *Address) Street() string     { return a.street }
-func (a *Address) City() string       { return a.city }
-func (a *Address) PostalCode() string { return a.postalCode }
-func (a *Address) Country() string    { return a.country }
-func (a *Address) Phone() string      { return a.phone }
+func (a Address) Street() string     { return a.street }
+func (a Address) City() string       { return a.city }
+func (a Address) PostalCode() string { return a.postalCode }
+func (a Address) Country() string    { return a.country }
+func (a Address) Phone() string      { return a.phone }
 
 // Validate валидирует адрес
-func (a *Address) Validate() error {
+func (a Address) Validate() error {
 	if a.street == "" {
 		return errors.New("street is required")
 	}
@@ -437,21 +644,6 @@ func (a *Address) Validate() error {
 	return nil
 }
 
-// 🔍 МЕТОДЫ ДЛЯ Money
-
-func (m *Money) Amount() int64   { return m.amount }
-func (m *Money) Currency() string { return m.currency }
-
-// ToFloat возвращает сумму в рублях (деля на 100 копеек)
-func (m *Money) ToFloat() float64 {
-	return float64(m.amount) / 100.0
-}
-
-// String возвращает строковое представление денег
-func (m *Money) String() string {
-	return fmt.Sprintf("%.2f %s", m.ToFloat(), m.currency)
-}
-
 // 🔍 МЕТОДЫ ДЛЯ Status
 
 func (s Status) String() string {
@@ -0,0 +1,106 @@
+package order
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/money"
+
+	"github.com/google/uuid"
+)
+
+func TestOrderMixesConvertedCatalogPrices(t *testing.T) {
+	address := NewAddress("Тверская 1", "Москва", "101000", "RU", "+7")
+	rubItem, err := NewOrderItem(uuid.New(), 1, NewMoney(100000, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Без конвертации цены в EUR и RUB не складываются
+	eurItem, err := NewOrderItem(uuid.New(), 2, NewMoney(1000, "EUR"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewOrder(uuid.New(), []OrderItem{rubItem, eurItem}, address); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Fatalf("NewOrder(RUB + EUR) error = %v, want ErrCurrencyMismatch", err)
+	}
+
+	rate, err := money.NewRate("EUR", "RUB", "100.005", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	converted, err := NewConvertedOrderItem(eurItem.ProductID(), 2, NewMoney(1000, "EUR"), rate)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ord, err := NewOrder(uuid.New(), []OrderItem{rubItem, converted}, address)
+	if err != nil {
+		t.Fatalf("NewOrder() error = %v", err)
+	}
+
+	// 10.00 EUR × 100.005 = 1000.05 RUB за единицу
+	if !converted.CatalogPrice().Equal(NewMoney(1000, "EUR")) || converted.UnitPrice().Amount() != 100005 {
+		t.Errorf("converted item = %s (catalog %s)", converted.UnitPrice(), converted.CatalogPrice())
+	}
+	if !ord.TotalAmount().Equal(NewMoney(300010, "RUB")) {
+		t.Errorf("TotalAmount() = %s, want 3000.10 RUB", ord.TotalAmount())
+	}
+}
+
+func TestDistributeDiscountKeepsTotal(t *testing.T) {
+	ord := newTestOrder(t) // 2 × 1500 ₽
+	if err := ord.AddItem(uuid.New(), 1, NewMoney(100000, "RUB")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ord.DistributeDiscount(NewMoney(10000, "RUB")); err != nil {
+		t.Fatalf("DistributeDiscount() error = %v", err)
+	}
+
+	items := ord.Items()
+	if items[0].Discount().Amount() != 7500 || items[1].Discount().Amount() != 2500 {
+		t.Errorf("item discounts = %s, %s; want 75 and 25 RUB", items[0].Discount(), items[1].Discount())
+	}
+	if !ord.TotalAmount().Equal(NewMoney(390000, "RUB")) {
+		t.Errorf("TotalAmount() = %s, want 3900.00 RUB", ord.TotalAmount())
+	}
+
+	if err := ord.DistributeDiscount(NewMoney(100, "EUR")); !errors.Is(err, money.ErrCurrencyMismatch) {
+		t.Errorf("DistributeDiscount(EUR) error = %v, want ErrCurrencyMismatch", err)
+	}
+	if err := ord.DistributeDiscount(NewMoney(500000, "RUB")); err == nil {
+		t.Error("DistributeDiscount() above subtotal error = nil")
+	}
+}
+
+func TestApplyPromotionsReplacesDiscounts(t *testing.T) {
+	ord := newTestOrder(t) // 2 × 1500 ₽
+	productID := ord.Items()[0].ProductID()
+	if err := ord.ApplyDiscount(productID, NewMoney(50000, "RUB")); err != nil {
+		t.Fatal(err)
+	}
+
+	applied := []AppliedPromotion{{RuleID: "autumn", Name: "Осень", Discount: NewMoney(30000, "RUB")}}
+	if err := ord.ApplyPromotions(map[uuid.UUID]Money{productID: NewMoney(30000, "RUB")}, applied); err != nil {
+		t.Fatalf("ApplyPromotions() error = %v", err)
+	}
+	if !ord.TotalAmount().Equal(NewMoney(270000, "RUB")) || len(ord.Promotions()) != 1 {
+		t.Errorf("TotalAmount() = %s, Promotions() = %+v; want 2700.00 RUB and one rule", ord.TotalAmount(), ord.Promotions())
+	}
+
+	// История восстанавливает примененные правила
+	restored, err := NewOrderFromHistory(ord.UncommittedEvents())
+	if err != nil {
+		t.Fatal(err)
+	}
+	sameState(t, restored, ord)
+
+	// Пустой набор снимает все скидки
+	if err := ord.ApplyPromotions(nil, nil); err != nil || !ord.TotalAmount().Equal(NewMoney(300000, "RUB")) {
+		t.Errorf("ApplyPromotions(nil) = %v, TotalAmount() = %s; want 3000.00 RUB", err, ord.TotalAmount())
+	}
+	if err := ord.ApplyPromotions(map[uuid.UUID]Money{productID: NewMoney(300001, "RUB")}, nil); err == nil {
+		t.Error("ApplyPromotions() above item subtotal error = nil")
+	}
+}
@@ -0,0 +1,327 @@
+package order
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 📜 ДОМЕННЫЕ СОБЫТИЯ ЗАКАЗА (EVENT SOURCING)
+//
+// ============================================================================
+// КАК ЭТО РАБОТАЕТ:
+// ============================================================================
+//
+// 1. Бизнес-метод агрегата (AddItem, MarkAsPaid, Cancel...) проверяет
+//    инварианты и ничего не меняет напрямую - он выпускает событие (raise).
+//
+// 2. Состояние меняет только apply: один и тот же код применяет новое
+//    событие и событие из истории, поэтому заказ, восстановленный из
+//    хранилища, совпадает с исходным.
+//
+// 3. Новые события копятся в UncommittedEvents до сохранения в EventStore.
+//    После записи репозиторий вызывает MarkEventsCommitted.
+//
+// 4. Version - номер последнего примененного события (1, 2, 3...). По нему
+//    EventStore обнаруживает конкурентные изменения (optimistic locking).
+//
+// ============================================================================
+// ПОТОК СОБЫТИЙ ЗАКАЗА:
+// ============================================================================
+//
+//	order.created → order.item_added* → order.discount_applied*
+//	  → order.promotions_applied? → order.validated → order.payment_started → order.paid
+//	  → order.inventory_checked → order.shipped → order.delivered
+//	  (order.cancelled / order.refunded - альтернативные завершения)
+//
+// ============================================================================
+
+// Ошибки потока событий
+var (
+	// ErrInvalidEventStream поток событий не может быть применен к заказу
+	ErrInvalidEventStream = errors.New("invalid order event stream")
+
+	// ErrEventVersionConflict заказ был изменен конкурентно
+	ErrEventVersionConflict = errors.New("order event version conflict")
+)
+
+// EventType тип доменного события заказа
+type EventType string
+
+const (
+	EventOrderCreated          EventType = "order.created"
+	EventItemAdded             EventType = "order.item_added"
+	EventDiscountApplied       EventType = "order.discount_applied"
+	EventPromotionsApplied     EventType = "order.promotions_applied"
+	EventOrderValidated        EventType = "order.validated"
+	EventPaymentStarted        EventType = "order.payment_started"
+	EventOrderPaid             EventType = "order.paid"
+	EventInventoryChecked      EventType = "order.inventory_checked"
+	EventOrderShipped          EventType = "order.shipped"
+	EventOrderDelivered        EventType = "order.delivered"
+	EventOrderCancelled        EventType = "order.cancelled"
+	EventOrderRefunded         EventType = "order.refunded"
+	EventPriorityChanged       EventType = "order.priority_changed"
+	EventNotesChanged          EventType = "order.notes_changed"
+	EventBillingAddressChanged EventType = "order.billing_address_changed"
+	EventSourceChanged         EventType = "order.source_changed"
+	EventPaymentMethodChanged  EventType = "order.payment_method_changed"
+)
+
+// Event доменное событие заказа с метаданными потока
+type Event struct {
+	ID         uuid.UUID // Уникальный ID события
+	OrderID    uuid.UUID // ID агрегата (заказа)
+	Version    int       // Позиция события в потоке заказа, начиная с 1
+	OccurredAt time.Time // Когда событие произошло
+	Data       EventData // Полезная нагрузка события
+}
+
+// Type возвращает тип события
+func (e Event) Type() EventType {
+	if e.Data == nil {
+		return ""
+	}
+	return e.Data.EventType()
+}
+
+// EventData полезная нагрузка доменного события
+type EventData interface {
+	EventType() EventType
+}
+
+// 📦 ПОЛЕЗНЫЕ НАГРУЗКИ СОБЫТИЙ
+
+// OrderCreated заказ создан
+type OrderCreated struct {
+	CustomerID      uuid.UUID
+	Items           []OrderItem
+	ShippingAddress Address
+	BillingAddress  Address
+	Currency        string
+	Priority        Priority
+	Source          Source
+}
+
+// ItemAdded в заказ добавлен товар
+type ItemAdded struct {
+	Item OrderItem
+}
+
+// DiscountApplied к товару применена скидка
+type DiscountApplied struct {
+	ProductID uuid.UUID
+	Discount  Money
+}
+
+// PromotionsApplied движок правил пересчитал скидки заказа
+//
+// Скидки позиций приходят отдельными DiscountApplied перед этим событием,
+// здесь - список сработавших правил для аудита.
+type PromotionsApplied struct {
+	Promotions []AppliedPromotion
+}
+
+// OrderValidated заказ прошел валидацию
+type OrderValidated struct{}
+
+// PaymentStarted начата обработка платежа
+type PaymentStarted struct{}
+
+// OrderPaid заказ оплачен
+type OrderPaid struct {
+	Amount Money // Сумма заказа на момент оплаты
+}
+
+// InventoryChecked товары проверены на складе
+type InventoryChecked struct{}
+
+// OrderShipped заказ отправлен
+type OrderShipped struct{}
+
+// OrderDelivered заказ доставлен
+type OrderDelivered struct{}
+
+// OrderCancelled заказ отменен
+type OrderCancelled struct {
+	PreviousStatus Status // Статус, из которого заказ был отменен
+}
+
+// OrderRefunded платеж по заказу возвращен
+type OrderRefunded struct {
+	Amount Money
+}
+
+// PriorityChanged изменен приоритет заказа
+type PriorityChanged struct {
+	Priority Priority
+}
+
+// NotesChanged изменены заметки к заказу
+type NotesChanged struct {
+	Notes string
+}
+
+// BillingAddressChanged изменен адрес для счета
+type BillingAddressChanged struct {
+	Address Address
+}
+
+// SourceChanged изменен источник заказа
+type SourceChanged struct {
+	Source Source
+}
+
+// PaymentMethodChanged покупатель выбрал способ оплаты
+type PaymentMethodChanged struct {
+	Method string
+}
+
+func (OrderCreated) EventType() EventType          { return EventOrderCreated }
+func (ItemAdded) EventType() EventType             { return EventItemAdded }
+func (DiscountApplied) EventType() EventType       { return EventDiscountApplied }
+func (PromotionsApplied) EventType() EventType     { return EventPromotionsApplied }
+func (OrderValidated) EventType() EventType        { return EventOrderValidated }
+func (PaymentStarted) EventType() EventType        { return EventPaymentStarted }
+func (OrderPaid) EventType() EventType             { return EventOrderPaid }
+func (InventoryChecked) EventType() EventType      { return EventInventoryChecked }
+func (OrderShipped) EventType() EventType          { return EventOrderShipped }
+func (OrderDelivered) EventType() EventType        { return EventOrderDelivered }
+func (OrderCancelled) EventType() EventType        { return EventOrderCancelled }
+func (OrderRefunded) EventType() EventType         { return EventOrderRefunded }
+func (PriorityChanged) EventType() EventType       { return EventPriorityChanged }
+func (NotesChanged) EventType() EventType          { return EventNotesChanged }
+func (BillingAddressChanged) EventType() EventType { return EventBillingAddressChanged }
+func (SourceChanged) EventType() EventType         { return EventSourceChanged }
+func (PaymentMethodChanged) EventType() EventType  { return EventPaymentMethodChanged }
+
+// 🔄 ПРИМЕНЕНИЕ СОБЫТИЙ
+
+// raise выпускает новое событие: применяет его и откладывает для сохранения
+func (o *Order) raise(data EventData) {
+	event := Event{
+		ID:         uuid.New(),
+		OrderID:    o.id,
+		Version:    o.version + 1,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+
+	o.apply(event)
+	o.changes = append(o.changes, event)
+}
+
+// apply меняет состояние заказа по событию
+//
+// Не проверяет бизнес-правила: событие - уже свершившийся факт.
+func (o *Order) apply(event Event) {
+	switch data := event.Data.(type) {
+	case OrderCreated:
+		o.id = event.OrderID
+		o.customerID = data.CustomerID
+		o.items = append([]OrderItem(nil), data.Items...)
+		o.status = StatusPending
+		o.shippingAddress = data.ShippingAddress
+		o.billingAddress = data.BillingAddress
+		o.currency = data.Currency
+		o.priority = data.Priority
+		o.source = data.Source
+		o.createdAt = event.OccurredAt
+		o.calculateTotalAmount()
+	case ItemAdded:
+		o.items = append(o.items, data.Item)
+		o.calculateTotalAmount()
+	case DiscountApplied:
+		for i := range o.items {
+			if o.items[i].productID == data.ProductID {
+				o.items[i].discount = data.Discount
+				break
+			}
+		}
+		o.calculateTotalAmount()
+	case PromotionsApplied:
+		o.promotions = append([]AppliedPromotion(nil), data.Promotions...)
+	case OrderValidated:
+		o.status = StatusValidated
+	case PaymentStarted:
+		o.status = StatusPaymentProcessing
+	case OrderPaid:
+		o.status = StatusPaid
+	case InventoryChecked:
+		o.status = StatusInventoryChecked
+	case OrderShipped:
+		o.status = StatusShipped
+	case OrderDelivered:
+		o.status = StatusDelivered
+	case OrderCancelled:
+		o.status = StatusCancelled
+	case OrderRefunded:
+		o.status = StatusRefunded
+	case PriorityChanged:
+		o.priority = data.Priority
+	case NotesChanged:
+		o.notes = data.Notes
+	case BillingAddressChanged:
+		o.billingAddress = data.Address
+	case SourceChanged:
+		o.source = data.Source
+	case PaymentMethodChanged:
+		o.paymentMethod = data.Method
+	}
+
+	o.version = event.Version
+	o.updatedAt = event.OccurredAt
+}
+
+// applyHistory применяет сохраненные события с проверкой непрерывности потока
+func (o *Order) applyHistory(events []Event) error {
+	for _, event := range events {
+		if event.Data == nil {
+			return fmt.Errorf("%w: event %d has no data", ErrInvalidEventStream, event.Version)
+		}
+		if event.Version != o.version+1 {
+			return fmt.Errorf("%w: expected version %d, got %d", ErrInvalidEventStream, o.version+1, event.Version)
+		}
+		if o.version > 0 && event.OrderID != o.id {
+			return fmt.Errorf("%w: event %d belongs to order %s", ErrInvalidEventStream, event.Version, event.OrderID)
+		}
+		if (o.version == 0) != (event.Type() == EventOrderCreated) {
+			return fmt.Errorf("%w: stream must start with exactly one %s, got %s at version %d",
+				ErrInvalidEventStream, EventOrderCreated, event.Type(), event.Version)
+		}
+
+		o.apply(event)
+	}
+
+	return nil
+}
+
+// 🏗️ ВОССТАНОВЛЕНИЕ АГРЕГАТА
+
+// NewOrderFromHistory восстанавливает заказ из полного потока событий
+func NewOrderFromHistory(events []Event) (*Order, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: no events", ErrInvalidEventStream)
+	}
+
+	order := &Order{}
+	if err := order.applyHistory(events); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// UncommittedEvents возвращает события, еще не сохраненные в EventStore
+func (o *Order) UncommittedEvents() []Event {
+	return append([]Event(nil), o.changes...)
+}
+
+// MarkEventsCommitted отмечает новые события как сохраненные
+func (o *Order) MarkEventsCommitted() {
+	o.changes = nil
+}
+
+// Version возвращает номер последнего примененного события
+func (o *Order) Version() int { return o.version }
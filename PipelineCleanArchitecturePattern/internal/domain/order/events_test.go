@@ -0,0 +1,190 @@
+package order
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func newTestOrder(t *testing.T) *Order {
+	t.Helper()
+
+	item, err := NewOrderItem(uuid.New(), 2, NewMoney(150000, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ord, err := NewOrder(uuid.New(), []OrderItem{item}, NewAddress("Тверская 1", "Москва", "101000", "RU", "+7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ord
+}
+
+func eventTypes(events []Event) []EventType {
+	types := make([]EventType, len(events))
+	for i, event := range events {
+		types[i] = event.Type()
+	}
+	return types
+}
+
+// sameState сравнивает заказы без учета несохраненных событий
+func sameState(t *testing.T, got, want *Order) {
+	t.Helper()
+
+	gotCopy, wantCopy := *got, *want
+	gotCopy.changes, wantCopy.changes = nil, nil
+	if !reflect.DeepEqual(gotCopy, wantCopy) {
+		t.Errorf("restored order = %+v, want %+v", gotCopy, wantCopy)
+	}
+}
+
+func TestOrderTransitionsEmitEvents(t *testing.T) {
+	ord := newTestOrder(t)
+	productID := ord.Items()[0].productID
+
+	steps := []func() error{
+		func() error { return ord.AddItem(uuid.New(), 1, NewMoney(50000, "RUB")) },
+		func() error { return ord.ApplyDiscount(productID, NewMoney(10000, "RUB")) },
+		ord.MarkAsValidated,
+		ord.MarkAsPaymentProcessing,
+		ord.MarkAsPaid,
+		func() error { ord.SetNotes("позвонить за час"); return nil },
+		ord.Cancel,
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("step %d: %v", i, err)
+		}
+	}
+
+	want := []EventType{
+		EventOrderCreated, EventItemAdded, EventDiscountApplied, EventOrderValidated,
+		EventPaymentStarted, EventOrderPaid, EventNotesChanged, EventOrderCancelled,
+	}
+	events := ord.UncommittedEvents()
+	if got := eventTypes(events); !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %v, want %v", got, want)
+	}
+
+	for i, event := range events {
+		if event.Version != i+1 || event.OrderID != ord.ID() {
+			t.Errorf("event %d has version %d order %s", i, event.Version, event.OrderID)
+		}
+	}
+	if ord.Version() != len(want) {
+		t.Errorf("Version() = %d, want %d", ord.Version(), len(want))
+	}
+
+	paid := events[5].Data.(OrderPaid)
+	if paid.Amount.Amount() != 2*150000+50000-10000 {
+		t.Errorf("paid amount = %d, want total at payment time", paid.Amount.Amount())
+	}
+	if cancelled := events[7].Data.(OrderCancelled); cancelled.PreviousStatus != StatusPaid {
+		t.Errorf("cancelled from %s, want paid", cancelled.PreviousStatus)
+	}
+}
+
+func TestRejectedTransitionEmitsNoEvent(t *testing.T) {
+	ord := newTestOrder(t)
+	ord.MarkEventsCommitted()
+
+	if err := ord.MarkAsPaid(); err == nil {
+		t.Fatal("MarkAsPaid() on pending order error = nil")
+	}
+	if err := ord.ApplyDiscount(uuid.New(), NewMoney(100, "RUB")); err == nil {
+		t.Fatal("ApplyDiscount() for unknown product error = nil")
+	}
+
+	if events := ord.UncommittedEvents(); len(events) != 0 {
+		t.Errorf("rejected transitions emitted %v", eventTypes(events))
+	}
+	if ord.Version() != 1 {
+		t.Errorf("Version() = %d, want 1", ord.Version())
+	}
+}
+
+func TestOrderRebuiltFromHistoryAndSnapshot(t *testing.T) {
+	ord := newTestOrder(t)
+	if err := ord.MarkAsValidated(); err != nil {
+		t.Fatal(err)
+	}
+	ord.SetPriority(PriorityUrgent)
+	history := ord.UncommittedEvents()
+	ord.MarkEventsCommitted()
+
+	restored, err := NewOrderFromHistory(history)
+	if err != nil {
+		t.Fatalf("NewOrderFromHistory() error = %v", err)
+	}
+	sameState(t, restored, ord)
+
+	snapshot, err := ord.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	if err := ord.MarkAsPaymentProcessing(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ord.MarkAsPaid(); err != nil {
+		t.Fatal(err)
+	}
+	tail := ord.UncommittedEvents()
+	ord.MarkEventsCommitted()
+
+	restored, err = NewOrderFromSnapshot(snapshot, tail)
+	if err != nil {
+		t.Fatalf("NewOrderFromSnapshot() error = %v", err)
+	}
+	sameState(t, restored, ord)
+	if restored.Status() != StatusPaid || restored.Priority() != PriorityUrgent {
+		t.Errorf("restored status %s priority %s, want paid urgent", restored.Status(), restored.Priority())
+	}
+}
+
+func TestSnapshotRequiresCommittedEvents(t *testing.T) {
+	ord := newTestOrder(t)
+	if _, err := ord.Snapshot(); err == nil {
+		t.Error("Snapshot() with uncommitted events error = nil")
+	}
+}
+
+func TestNewOrderFromHistoryRejectsBrokenStreams(t *testing.T) {
+	ord := newTestOrder(t)
+	if err := ord.MarkAsValidated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := ord.MarkAsPaymentProcessing(); err != nil {
+		t.Fatal(err)
+	}
+	events := ord.UncommittedEvents()
+
+	foreign := events[1]
+	foreign.OrderID = uuid.New()
+
+	duplicateCreated := events[0]
+	duplicateCreated.Version = 2
+
+	tests := []struct {
+		name   string
+		events []Event
+	}{
+		{"empty", nil},
+		{"gap", []Event{events[0], events[2]}},
+		{"missing created", events[1:]},
+		{"foreign event", []Event{events[0], foreign}},
+		{"second created", []Event{events[0], duplicateCreated}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewOrderFromHistory(tt.events)
+			if !errors.Is(err, ErrInvalidEventStream) {
+				t.Errorf("error = %v, want ErrInvalidEventStream", err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package order
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/google/uuid"
@@ -80,6 +81,9 @@ type QueryRepository interface {
 
 // 📊 ВСПОМОГАТЕЛЬНЫЕ ТИПЫ ДЛЯ АНАЛИТИКИ
 
+// ErrMixedCurrencies в выборке аналитики оказались заказы в разных валютах
+var ErrMixedCurrencies = errors.New("orders use different currencies")
+
 // CustomerRevenue представляет выручку от клиента
 type CustomerRevenue struct {
 	CustomerID    uuid.UUID
@@ -160,4 +164,45 @@ type SearchRepository interface {
 	
 	// Count возвращает количество заказов по критериям
 	Count(ctx context.Context, criteria SearchCriteria) (int, error)
-}
\ No newline at end of file
+}
+
+// 📜 EVENT SOURCING
+
+// Ошибки хранилищ событий и снимков
+var (
+	// ErrOrderNotFound для заказа нет ни одного события
+	ErrOrderNotFound = errors.New("order not found")
+	
+	// ErrSnapshotNotFound для заказа еще не сделан снимок
+	ErrSnapshotNotFound = errors.New("order snapshot not found")
+)
+
+// EventStore append-only хранилище доменных событий заказов
+type EventStore interface {
+	// Append дописывает события в поток заказа
+	//
+	// expectedVersion - версия заказа, от которой построены события.
+	// Если поток уже длиннее, возвращается ErrEventVersionConflict.
+	Append(ctx context.Context, orderID uuid.UUID, expectedVersion int, events []Event) error
+	
+	// Load возвращает события заказа с версией больше afterVersion
+	Load(ctx context.Context, orderID uuid.UUID, afterVersion int) ([]Event, error)
+	
+	// LoadAll возвращает события всех заказов в порядке записи (для перестроения проекций)
+	LoadAll(ctx context.Context) ([]Event, error)
+}
+
+// SnapshotStore хранилище снимков заказов
+type SnapshotStore interface {
+	// SaveSnapshot сохраняет снимок, заменяя предыдущий
+	SaveSnapshot(ctx context.Context, snapshot Snapshot) error
+	
+	// LoadSnapshot возвращает последний снимок или ErrSnapshotNotFound
+	LoadSnapshot(ctx context.Context, orderID uuid.UUID) (*Snapshot, error)
+}
+
+// Projection модель чтения, которая строится по событиям заказов
+type Projection interface {
+	// Apply применяет сохраненное событие к модели чтения
+	Apply(ctx context.Context, event Event) error
+}
@@ -0,0 +1,117 @@
+package order
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 📸 СНИМКИ СОСТОЯНИЯ ЗАКАЗА
+//
+// Снимок фиксирует состояние агрегата на версии Version. Восстановление
+// заказа начинается со снимка и применяет только события после него,
+// поэтому длинная история не перечитывается целиком.
+
+// Snapshot состояние заказа на определенной версии потока событий
+type Snapshot struct {
+	OrderID         uuid.UUID
+	Version         int
+	TakenAt         time.Time
+	CustomerID      uuid.UUID
+	Items           []OrderItem
+	Status          Status
+	Currency        string
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	ShippingAddress Address
+	BillingAddress  Address
+	Notes           string
+	Priority        Priority
+	Source          Source
+	PaymentMethod   string
+	Promotions      []AppliedPromotion
+}
+
+// Snapshot делает снимок сохраненного состояния заказа
+//
+// Несохраненные события в снимок не попадают: снимок должен соответствовать
+// версии, уже записанной в EventStore.
+func (o *Order) Snapshot() (Snapshot, error) {
+	if len(o.changes) > 0 {
+		return Snapshot{}, fmt.Errorf("order %s has %d uncommitted events", o.id, len(o.changes))
+	}
+
+	return Snapshot{
+		OrderID:         o.id,
+		Version:         o.version,
+		TakenAt:         time.Now(),
+		CustomerID:      o.customerID,
+		Items:           append([]OrderItem(nil), o.items...),
+		Status:          o.status,
+		Currency:        o.currency,
+		CreatedAt:       o.createdAt,
+		UpdatedAt:       o.updatedAt,
+		ShippingAddress: o.shippingAddress,
+		BillingAddress:  o.billingAddress,
+		Notes:           o.notes,
+		Priority:        o.priority,
+		Source:          o.source,
+		PaymentMethod:   o.paymentMethod,
+		Promotions:      append([]AppliedPromotion(nil), o.promotions...),
+	}, nil
+}
+
+// RestoreOrderItem восстанавливает позицию заказа из хранилища
+//
+// В отличие от NewOrderItem не проверяет значения и сохраняет скидку:
+// позиция уже прошла проверки при создании.
+func RestoreOrderItem(productID uuid.UUID, quantity int, unitPrice, discount Money) OrderItem {
+	return OrderItem{
+		productID: productID,
+		quantity:  quantity,
+		unitPrice: unitPrice,
+		discount:  discount,
+	}
+}
+
+// RestoreConvertedOrderItem восстанавливает позицию, цена которой была
+// пересчитана из валюты каталога (см. NewConvertedOrderItem)
+func RestoreConvertedOrderItem(productID uuid.UUID, quantity int, unitPrice, discount, catalogPrice Money) OrderItem {
+	item := RestoreOrderItem(productID, quantity, unitPrice, discount)
+	if !catalogPrice.SameCurrency(unitPrice) {
+		item.catalogPrice = catalogPrice
+	}
+	return item
+}
+
+// NewOrderFromSnapshot восстанавливает заказ из снимка и событий после него
+func NewOrderFromSnapshot(snapshot Snapshot, events []Event) (*Order, error) {
+	if snapshot.OrderID == uuid.Nil || snapshot.Version <= 0 {
+		return nil, fmt.Errorf("%w: snapshot has no order or version", ErrInvalidEventStream)
+	}
+
+	order := &Order{
+		id:              snapshot.OrderID,
+		version:         snapshot.Version,
+		customerID:      snapshot.CustomerID,
+		items:           append([]OrderItem(nil), snapshot.Items...),
+		status:          snapshot.Status,
+		currency:        snapshot.Currency,
+		createdAt:       snapshot.CreatedAt,
+		updatedAt:       snapshot.UpdatedAt,
+		shippingAddress: snapshot.ShippingAddress,
+		billingAddress:  snapshot.BillingAddress,
+		notes:           snapshot.Notes,
+		priority:        snapshot.Priority,
+		source:          snapshot.Source,
+		paymentMethod:   snapshot.PaymentMethod,
+		promotions:      append([]AppliedPromotion(nil), snapshot.Promotions...),
+	}
+	order.calculateTotalAmount()
+
+	if err := order.applyHistory(events); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
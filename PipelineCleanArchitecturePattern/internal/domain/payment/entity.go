@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"time"
 
+	"pipeline-clean-architecture/internal/domain/money"
+
 	"github.com/google/uuid"
 )
 
@@ -25,16 +27,14 @@ type Payment struct {
 	fee             Money     // комиссия
 	description     string
 	metadata        map[string]string
+	authorizedAt    *time.Time // средства заблокированы, но еще не списаны
 	processedAt     *time.Time
 	createdAt       time.Time
 	updatedAt       time.Time
 }
 
-// Money представляет денежную сумму
-type Money struct {
-	amount   int64  // в копейках
-	currency string
-}
+// Money представляет денежную сумму (общий тип домена, см. пакет money)
+type Money = money.Money
 
 // Method представляет способ оплаты
 type Method string
@@ -45,6 +45,7 @@ const (
 	MethodDigitalWallet  Method = "digital_wallet"
 	MethodCryptocurrency Method = "cryptocurrency"
 	MethodCash           Method = "cash"
+	MethodSBP            Method = "sbp" // Система быстрых платежей (оплата по QR)
 )
 
 // Status представляет статус платежа
@@ -69,6 +70,7 @@ const (
 	ProviderSberbank  Provider = "sberbank"
 	ProviderYandex    Provider = "yandex"
 	ProviderInternal  Provider = "internal"
+	ProviderSBP       Provider = "sbp"
 )
 
 // 🏗️ КОНСТРУКТОРЫ
@@ -83,7 +85,7 @@ func NewPayment(orderID, customerID uuid.UUID, amount Money, method Method, prov
 		return nil, errors.New("customer ID cannot be empty")
 	}
 	
-	if amount.amount <= 0 {
+	if !amount.IsPositive() {
 		return nil, errors.New("payment amount must be positive")
 	}
 	
@@ -105,6 +107,11 @@ func NewPayment(orderID, customerID uuid.UUID, amount Money, method Method, prov
 	}, nil
 }
 
+// NewMoney создает денежную сумму (amount в копейках)
+func NewMoney(amount int64, currency string) Money {
+	return money.New(amount, currency)
+}
+
 // 💳 БИЗНЕС-ЛОГИКА ОБРАБОТКИ ПЛАТЕЖЕЙ
 
 // CanProcess проверяет, можно ли обработать платеж
@@ -160,6 +167,53 @@ func (p *Payment) Cancel() error {
 	return nil
 }
 
+// 🔐 ДВУХФАЗНАЯ ОПЛАТА (AUTHORIZE → CAPTURE)
+
+// AttachExternalID запоминает ID платежа во внешней системе до его завершения
+// (например, когда покупатель еще должен отсканировать QR или пройти 3-D Secure)
+func (p *Payment) AttachExternalID(externalID string) error {
+	if p.status != StatusProcessing {
+		return errors.New("can only attach external ID to processing payments")
+	}
+	
+	if externalID == "" {
+		return errors.New("external ID cannot be empty")
+	}
+	
+	p.externalID = externalID
+	p.updatedAt = time.Now()
+	return nil
+}
+
+// MarkAsAuthorized помечает средства заблокированными у провайдера
+func (p *Payment) MarkAsAuthorized(externalID string) error {
+	if err := p.AttachExternalID(externalID); err != nil {
+		return err
+	}
+	
+	now := time.Now()
+	p.authorizedAt = &now
+	p.updatedAt = now
+	return nil
+}
+
+// IsAuthorized проверяет, заблокированы ли средства и ожидают ли списания
+func (p *Payment) IsAuthorized() bool {
+	return p.status == StatusProcessing && p.authorizedAt != nil
+}
+
+// Void отменяет авторизацию: блокировка снимается, деньги не списываются
+func (p *Payment) Void(reason string) error {
+	if p.status != StatusProcessing {
+		return errors.New("can only void processing payments")
+	}
+	
+	p.status = StatusCancelled
+	p.SetMetadata("void_reason", reason)
+	p.updatedAt = time.Now()
+	return nil
+}
+
 // 💰 БИЗНЕС-ЛОГИКА ВОЗВРАТОВ
 
 // CanRefund проверяет, можно ли сделать возврат
@@ -185,16 +239,16 @@ func (p *Payment) PartialRefund(amount Money, reason string) error {
 		return errors.New("cannot refund payment in current status")
 	}
 	
-	if amount.amount >= p.amount.amount {
-		return errors.New("refund amount cannot exceed payment amount")
+	if !amount.SameCurrency(p.amount) {
+		return errors.New("refund currency must match payment currency")
 	}
 	
-	if amount.currency != p.amount.currency {
-		return errors.New("refund currency must match payment currency")
+	if amount.Amount() >= p.amount.Amount() {
+		return errors.New("refund amount cannot exceed payment amount")
 	}
 	
 	p.status = StatusPartiallyRefunded
-	p.SetMetadata("partial_refund_amount", fmt.Sprintf("%d", amount.amount))
+	p.SetMetadata("partial_refund_amount", fmt.Sprintf("%d", amount.Amount()))
 	p.SetMetadata("refund_reason", reason)
 	p.updatedAt = time.Now()
 	return nil
@@ -240,11 +294,11 @@ func (p *Payment) SetCardInfo(last4, brand string) error {
 
 // SetFee устанавливает комиссию
 func (p *Payment) SetFee(fee Money) error {
-	if fee.currency != p.amount.currency {
+	if !fee.SameCurrency(p.amount) {
 		return errors.New("fee currency must match payment currency")
 	}
 	
-	if fee.amount < 0 {
+	if fee.IsNegative() {
 		return errors.New("fee cannot be negative")
 	}
 	
@@ -257,10 +311,15 @@ func (p *Payment) SetFee(fee Money) error {
 
 // NetAmount возвращает сумму к получению (без комиссии)
 func (p *Payment) NetAmount() Money {
-	return Money{
-		amount:   p.amount.amount - p.fee.amount,
-		currency: p.amount.currency,
+	if p.fee.IsZero() {
+		return p.amount
 	}
+	
+	net, err := p.amount.Sub(p.fee)
+	if err != nil {
+		return p.amount // SetFee не принимает комиссию в другой валюте
+	}
+	return net
 }
 
 // IsExpired проверяет, истек ли платеж
@@ -314,21 +373,13 @@ func (p *Payment) CardBrand() string       { return p.cardBrand }
 func (p *Payment) Fee() Money              { return p.fee }
 func (p *Payment) Description() string     { return p.description }
 func (p *Payment) Metadata() map[string]string { return p.metadata }
+func (p *Payment) AuthorizedAt() *time.Time { return p.authorizedAt }
 func (p *Payment) ProcessedAt() *time.Time { return p.processedAt }
 func (p *Payment) CreatedAt() time.Time    { return p.createdAt }
 func (p *Payment) UpdatedAt() time.Time    { return p.updatedAt }
 
 // 🔍 МЕТОДЫ ДЛЯ ВСПОМОГАТЕЛЬНЫХ ТИПОВ
 
-// Money methods
-func (m Money) Amount() int64    { return m.amount }
-func (m Money) Currency() string { return m.currency }
-func (m Money) ToFloat() float64 { return float64(m.amount) / 100.0 }
-
-func (m Money) String() string {
-	return fmt.Sprintf("%.2f %s", m.ToFloat(), m.currency)
-}
-
 // Method methods
 func (m Method) String() string { return string(m) }
 
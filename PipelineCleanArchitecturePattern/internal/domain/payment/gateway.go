@@ -0,0 +1,135 @@
+package payment
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 🔌 ПОРТ ПЛАТЕЖНОГО ШЛЮЗА
+// Доменный контракт внешнего провайдера; адаптеры (карты, СБП) живут в
+// инфраструктурном слое. Оплата двухфазная: Authorize блокирует средства,
+// Capture их списывает, Void снимает блокировку.
+
+// PaymentGateway адаптер внешнего платежного провайдера
+type PaymentGateway interface {
+	// Provider возвращает провайдера, которого обслуживает адаптер
+	Provider() Provider
+
+	// Authorize блокирует средства; для QR/3-D Secure результат приходит вебхуком
+	Authorize(ctx context.Context, req AuthorizeRequest) (*Authorization, error)
+
+	// Capture списывает ранее авторизованную сумму
+	Capture(ctx context.Context, externalID string, amount Money, idempotencyKey string) error
+
+	// Void отменяет авторизацию без списания
+	Void(ctx context.Context, externalID string, idempotencyKey string) error
+
+	// Refund возвращает списанную сумму
+	Refund(ctx context.Context, externalID string, amount Money, idempotencyKey string) error
+
+	// ParseWebhook проверяет подпись уведомления провайдера и разбирает его
+	ParseWebhook(payload []byte, signature string) (*WebhookEvent, error)
+}
+
+// AuthorizeRequest запрос авторизации платежа
+type AuthorizeRequest struct {
+	PaymentID      uuid.UUID
+	OrderID        uuid.UUID
+	CustomerID     uuid.UUID
+	Amount         Money
+	Method         Method
+	CardToken      string // токен карты из платежной формы
+	ReturnURL      string // куда вернуть покупателя после 3-D Secure / оплаты по QR
+	Description    string
+	IdempotencyKey string
+}
+
+// AuthorizationStatus результат авторизации
+type AuthorizationStatus string
+
+const (
+	// AuthorizationApproved средства заблокированы, можно списывать
+	AuthorizationApproved AuthorizationStatus = "authorized"
+	// AuthorizationCaptured провайдер сразу списал средства (одностадийная оплата)
+	AuthorizationCaptured AuthorizationStatus = "captured"
+	// AuthorizationPending нужно действие покупателя, итог придет вебхуком
+	AuthorizationPending AuthorizationStatus = "pending"
+	// AuthorizationDeclined провайдер отказал
+	AuthorizationDeclined AuthorizationStatus = "declined"
+)
+
+// Authorization ответ провайдера на авторизацию
+type Authorization struct {
+	ExternalID    string
+	Status        AuthorizationStatus
+	DeclineReason string
+	CardLast4     string
+	CardBrand     string
+	Fee           Money
+	QRPayload     string     // ссылка для QR-кода СБП
+	RedirectURL   string     // страница 3-D Secure
+	ExpiresAt     *time.Time // срок действия QR-кода / авторизации
+}
+
+// WebhookEventType тип уведомления провайдера
+type WebhookEventType string
+
+const (
+	WebhookAuthorized WebhookEventType = "payment.authorized"
+	WebhookSucceeded  WebhookEventType = "payment.succeeded"
+	WebhookFailed     WebhookEventType = "payment.failed"
+)
+
+// WebhookEvent уведомление провайдера об изменении статуса платежа
+type WebhookEvent struct {
+	ID         string
+	Type       WebhookEventType
+	ExternalID string
+	Reason     string
+	OccurredAt time.Time
+}
+
+// 🔑 ИДЕМПОТЕНТНОСТЬ
+
+// idempotencyNamespace пространство имен UUID v5 для ключей идемпотентности
+var idempotencyNamespace = uuid.MustParse("6f1c1f4e-8a3b-4e0a-9d55-2b7f3c1a9e10")
+
+// IdempotencyKey детерминированный ключ операции над заказом. Повтор того же
+// шага (ретрай пайплайна, переподключение) дает тот же ключ, и провайдер не
+// спишет деньги дважды; новая попытка оплаты после отказа получает новый ключ.
+func IdempotencyKey(orderID uuid.UUID, operation string, attempt int) string {
+	return uuid.NewSHA1(idempotencyNamespace, []byte(fmt.Sprintf("%s/%s/%d", orderID, operation, attempt))).String()
+}
+
+// 💾 РЕПОЗИТОРИЙ ПЛАТЕЖЕЙ
+
+// Repository хранилище платежей
+type Repository interface {
+	// Save сохраняет новый платеж или обновляет существующий
+	Save(ctx context.Context, payment *Payment) error
+
+	// GetByID получает платеж по ID
+	GetByID(ctx context.Context, id uuid.UUID) (*Payment, error)
+
+	// GetByExternalID получает платеж по ID во внешней системе
+	GetByExternalID(ctx context.Context, provider Provider, externalID string) (*Payment, error)
+
+	// GetByOrderID получает все попытки оплаты заказа в порядке создания
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*Payment, error)
+}
+
+// ❌ ОШИБКИ ШЛЮЗА
+
+var (
+	ErrPaymentNotFound     = errors.New("payment not found")
+	ErrPaymentDeclined     = errors.New("payment declined")
+	ErrMethodNotSupported  = errors.New("payment method not supported")
+	ErrProviderUnknown     = errors.New("payment provider is not registered")
+	ErrCaptureNotSupported = errors.New("capture not supported by provider")
+	ErrInvalidSignature    = errors.New("invalid webhook signature")
+	ErrIdempotencyConflict = errors.New("idempotency key reused with different request")
+)
@@ -2,8 +2,11 @@ package product
 
 import (
 	"errors"
+	"strings"
 	"time"
 
+	"pipeline-clean-architecture/internal/domain/money"
+
 	"github.com/google/uuid"
 )
 
@@ -25,6 +28,7 @@ type Product struct {
 	status       Status
 	images       []string
 	tags         []string
+	version      int       // версия для оптимистической блокировки остатков
 	createdAt    time.Time
 	updatedAt    time.Time
 }
@@ -36,11 +40,8 @@ type Category struct {
 	path string // иерархический путь: "Electronics > Computers > Laptops"
 }
 
-// Money представляет цену товара
-type Money struct {
-	amount   int64  // в копейках
-	currency string
-}
+// Money представляет цену товара (общий тип домена, см. пакет money)
+type Money = money.Money
 
 // Weight представляет вес товара
 type Weight struct {
@@ -78,7 +79,7 @@ func NewProduct(name, description, sku string, category Category, price Money) (
 		return nil, errors.New("product SKU cannot be empty")
 	}
 	
-	if price.amount <= 0 {
+	if !price.IsPositive() {
 		return nil, errors.New("product price must be positive")
 	}
 	
@@ -99,6 +100,25 @@ func NewProduct(name, description, sku string, category Category, price Money) (
 	}, nil
 }
 
+// NewCategory создает категорию; path - иерархический путь через " > "
+// ("Electronics > Computers"), пустой path - категория верхнего уровня name
+func NewCategory(name, path string) (Category, error) {
+	if name == "" {
+		return Category{}, errors.New("category name cannot be empty")
+	}
+	
+	if path == "" {
+		path = name
+	}
+	
+	return Category{id: uuid.New(), name: name, path: path}, nil
+}
+
+// NewMoney создает цену товара (amount в копейках)
+func NewMoney(amount int64, currency string) Money {
+	return money.New(amount, currency)
+}
+
 // 📦 БИЗНЕС-ЛОГИКА СКЛАДА
 
 // IsInStock проверяет, есть ли товар в наличии
@@ -118,8 +138,12 @@ func (p *Product) CanReserve(quantity int) bool {
 
 // Reserve резервирует товар
 func (p *Product) Reserve(quantity int) error {
+	if quantity <= 0 {
+		return errors.New("quantity must be positive")
+	}
+	
 	if !p.CanReserve(quantity) {
-		return errors.New("insufficient stock")
+		return ErrInsufficientStock
 	}
 	
 	p.stockLevel -= quantity
@@ -170,12 +194,12 @@ func (p *Product) UpdateStock(newStock int) error {
 
 // UpdatePrice обновляет цену товара
 func (p *Product) UpdatePrice(newPrice Money) error {
-	if newPrice.amount <= 0 {
+	if !newPrice.IsPositive() {
 		return errors.New("price must be positive")
 	}
 	
-	if newPrice.currency != p.price.currency {
-		return errors.New("currency mismatch")
+	if !newPrice.SameCurrency(p.price) {
+		return money.ErrCurrencyMismatch
 	}
 	
 	p.price = newPrice
@@ -189,13 +213,9 @@ func (p *Product) CalculateDiscountedPrice(discountPercent float64) (Money, erro
 		return Money{}, errors.New("discount must be between 0 and 100")
 	}
 	
-	discountAmount := float64(p.price.amount) * discountPercent / 100
-	finalAmount := p.price.amount - int64(discountAmount)
-	
-	return Money{
-		amount:   finalAmount,
-		currency: p.price.currency,
-	}, nil
+	// Скидка округляется вниз: покупатель не получит больше обещанного процента
+	discount := p.price.Percent(discountPercent, money.RoundDown)
+	return p.price.Sub(discount)
 }
 
 // 📊 УПРАВЛЕНИЕ МЕТАДАННЫМИ
@@ -314,6 +334,7 @@ func (p *Product) Images() []string    { return p.images }
 func (p *Product) Tags() []string      { return p.tags }
 func (p *Product) CreatedAt() time.Time { return p.createdAt }
 func (p *Product) UpdatedAt() time.Time { return p.updatedAt }
+func (p *Product) Version() int        { return p.version }
 
 // 🔍 МЕТОДЫ ДЛЯ ВСПОМОГАТЕЛЬНЫХ ТИПОВ
 
@@ -322,10 +343,17 @@ func (c Category) ID() uuid.UUID { return c.id }
 func (c Category) Name() string  { return c.name }
 func (c Category) Path() string  { return c.path }
 
-// Money methods
-func (m Money) Amount() int64    { return m.amount }
-func (m Money) Currency() string { return m.currency }
-func (m Money) ToFloat() float64 { return float64(m.amount) / 100.0 }
+// BelongsTo проверяет, входит ли категория в ветку path: "Electronics"
+// включает "Electronics" и "Electronics > Computers", но не "Electronic Books"
+func (c Category) BelongsTo(path string) bool {
+	if path == "" || c.path == "" {
+		return false
+	}
+	return c.path == path || strings.HasPrefix(c.path, path+categorySeparator)
+}
+
+// categorySeparator разделитель уровней в пути категории
+const categorySeparator = " > "
 
 // Weight methods
 func (w Weight) Value() int64   { return w.value }
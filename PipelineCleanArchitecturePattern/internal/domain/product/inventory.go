@@ -0,0 +1,235 @@
+package product
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 📦 РЕЗЕРВИРОВАНИЕ ОСТАТКОВ
+//
+// ============================================================================
+// ЖИЗНЕННЫЙ ЦИКЛ РЕЗЕРВА:
+// ============================================================================
+//
+//	active ──Confirm──→ confirmed   заказ оплачен, товар списан окончательно
+//	   │  └──Release──→ released    заказ отменен, остаток возвращен
+//	   └────Expire───→ expired      TTL истек до оплаты, остаток возвращен
+//
+// Остаток уменьшается при создании резерва (Product.Reserve) и
+// возвращается при Release/Expire (Product.RestoreStock).
+//
+// ============================================================================
+
+// Ошибки склада
+var (
+	// ErrProductNotFound товара нет в хранилище
+	ErrProductNotFound = errors.New("product not found")
+
+	// ErrInsufficientStock остатка не хватает для резерва
+	ErrInsufficientStock = errors.New("insufficient stock")
+
+	// ErrStockVersionConflict остаток товара изменен конкурентно
+	ErrStockVersionConflict = errors.New("product stock version conflict")
+
+	// ErrReservationNotFound резерва нет в хранилище
+	ErrReservationNotFound = errors.New("reservation not found")
+)
+
+// Repository хранилище товаров
+type Repository interface {
+	// GetByID получает товар по ID
+	GetByID(ctx context.Context, id uuid.UUID) (*Product, error)
+
+	// GetBySKU получает товар по артикулу
+	GetBySKU(ctx context.Context, sku string) (*Product, error)
+
+	// Save сохраняет товар, если версия в хранилище равна product.Version();
+	// иначе возвращает ErrStockVersionConflict. После записи вызывает MarkSaved.
+	Save(ctx context.Context, product *Product) error
+}
+
+// ReservationRepository хранилище резервов
+type ReservationRepository interface {
+	// Save сохраняет новый резерв или обновляет существующий
+	Save(ctx context.Context, reservation *Reservation) error
+
+	// GetByID получает резерв по ID
+	GetByID(ctx context.Context, id uuid.UUID) (*Reservation, error)
+
+	// GetByOrderID получает резерв заказа, который держит остаток (активный
+	// или подтвержденный; при нескольких - самый ранний);
+	// ErrReservationNotFound - такого нет
+	GetByOrderID(ctx context.Context, orderID uuid.UUID) (*Reservation, error)
+
+	// GetExpired возвращает активные резервы с истекшим TTL
+	GetExpired(ctx context.Context, now time.Time) ([]*Reservation, error)
+}
+
+// ReservationStatus статус резерва
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationConfirmed ReservationStatus = "confirmed"
+	ReservationReleased  ReservationStatus = "released"
+	ReservationExpired   ReservationStatus = "expired"
+)
+
+// ReservationLine зарезервированное количество одного товара
+type ReservationLine struct {
+	ProductID uuid.UUID
+	SKU       string
+	Quantity  int
+}
+
+// Reservation резерв товаров заказа на время оплаты
+type Reservation struct {
+	id        uuid.UUID
+	orderID   uuid.UUID // uuid.Nil - резерв без заказа
+	lines     []ReservationLine
+	status    ReservationStatus
+	createdAt time.Time
+	expiresAt time.Time
+	closedAt  *time.Time
+}
+
+// NewReservation создает активный резерв со сроком жизни ttl
+func NewReservation(orderID uuid.UUID, lines []ReservationLine, ttl time.Duration) (*Reservation, error) {
+	if len(lines) == 0 {
+		return nil, errors.New("reservation must contain at least one line")
+	}
+
+	if ttl <= 0 {
+		return nil, errors.New("reservation TTL must be positive")
+	}
+
+	for _, line := range lines {
+		if line.Quantity <= 0 {
+			return nil, fmt.Errorf("quantity of product %s must be positive", line.ProductID)
+		}
+	}
+
+	now := time.Now()
+	return &Reservation{
+		id:        uuid.New(),
+		orderID:   orderID,
+		lines:     append([]ReservationLine(nil), lines...),
+		status:    ReservationActive,
+		createdAt: now,
+		expiresAt: now.Add(ttl),
+	}, nil
+}
+
+// IsActive проверяет, держит ли резерв остаток
+func (r *Reservation) IsActive() bool {
+	return r.status == ReservationActive
+}
+
+// HoldsStock проверяет, списан ли под резерв остаток: активный или подтвержденный
+func (r *Reservation) HoldsStock() bool {
+	return r.status == ReservationActive || r.status == ReservationConfirmed
+}
+
+// IsExpired проверяет, истек ли TTL активного резерва
+func (r *Reservation) IsExpired(now time.Time) bool {
+	return r.status == ReservationActive && !now.Before(r.expiresAt)
+}
+
+// Confirm закрепляет резерв за оплаченным заказом
+func (r *Reservation) Confirm() error {
+	if r.status != ReservationActive {
+		return fmt.Errorf("cannot confirm %s reservation", r.status)
+	}
+	r.close(ReservationConfirmed)
+	return nil
+}
+
+// Release снимает резерв: активный (отмена до оплаты) или подтвержденный
+// (компенсация после оплаты)
+func (r *Reservation) Release() error {
+	if r.status != ReservationActive && r.status != ReservationConfirmed {
+		return fmt.Errorf("cannot release %s reservation", r.status)
+	}
+	r.close(ReservationReleased)
+	return nil
+}
+
+// Expire снимает резерв, не дождавшийся оплаты
+func (r *Reservation) Expire(now time.Time) error {
+	if !r.IsExpired(now) {
+		return fmt.Errorf("reservation %s is not expired", r.id)
+	}
+	r.close(ReservationExpired)
+	return nil
+}
+
+func (r *Reservation) close(status ReservationStatus) {
+	now := time.Now()
+	r.status = status
+	r.closedAt = &now
+}
+
+// 🔍 ГЕТТЕРЫ
+
+func (r *Reservation) ID() uuid.UUID             { return r.id }
+func (r *Reservation) OrderID() uuid.UUID        { return r.orderID }
+func (r *Reservation) Lines() []ReservationLine  { return append([]ReservationLine(nil), r.lines...) }
+func (r *Reservation) Status() ReservationStatus { return r.status }
+func (r *Reservation) CreatedAt() time.Time      { return r.createdAt }
+func (r *Reservation) ExpiresAt() time.Time      { return r.expiresAt }
+func (r *Reservation) ClosedAt() *time.Time      { return r.closedAt }
+
+// 📢 СОБЫТИЯ ОСТАТКОВ
+
+// StockEventType тип события остатка
+type StockEventType string
+
+const (
+	// StockEventLowStock IsLowStock() сменился с false на true
+	StockEventLowStock StockEventType = "product.low_stock"
+	// StockEventOutOfStock остаток закончился
+	StockEventOutOfStock StockEventType = "product.out_of_stock"
+)
+
+// StockEvent изменение остатка, на которое нужно отреагировать (дозаказ)
+type StockEvent struct {
+	Type          StockEventType
+	ProductID     uuid.UUID
+	SKU           string
+	StockLevel    int
+	MinStockLevel int
+	OccurredAt    time.Time
+}
+
+// StockEventPublisher получатель событий остатков
+type StockEventPublisher interface {
+	PublishStockEvent(ctx context.Context, event StockEvent) error
+}
+
+// StockEventsBetween события при переходе остатка товара из before в after
+func StockEventsBetween(before Snapshot, after *Product) []StockEvent {
+	wasLow := before.StockLevel <= before.MinStockLevel && before.StockLevel > 0
+
+	event := StockEvent{
+		ProductID:     after.id,
+		SKU:           after.sku,
+		StockLevel:    after.stockLevel,
+		MinStockLevel: after.minStockLevel,
+		OccurredAt:    time.Now(),
+	}
+
+	var events []StockEvent
+	if !wasLow && after.IsLowStock() {
+		event.Type = StockEventLowStock
+		events = append(events, event)
+	}
+	if before.StockLevel > 0 && after.stockLevel == 0 {
+		event.Type = StockEventOutOfStock
+		events = append(events, event)
+	}
+	return events
+}
@@ -0,0 +1,100 @@
+package product
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 📸 СНИМОК ТОВАРА ДЛЯ ХРАНИЛИЩА
+//
+// Хранилище держит снимки, а не указатели: каждый читатель получает свою
+// копию товара, и конкурентные резервирования сталкиваются только на
+// Save, где версия снимка проверяется (optimistic locking по SKU).
+
+// Snapshot состояние товара на версии Version
+type Snapshot struct {
+	ProductID     uuid.UUID
+	Version       int
+	Name          string
+	Description   string
+	SKU           string
+	Category      Category
+	Price         Money
+	Weight        Weight
+	Dimensions    Dimensions
+	StockLevel    int
+	MinStockLevel int
+	Status        Status
+	Images        []string
+	Tags          []string
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Snapshot делает снимок текущего состояния товара
+func (p *Product) Snapshot() Snapshot {
+	return Snapshot{
+		ProductID:     p.id,
+		Version:       p.version,
+		Name:          p.name,
+		Description:   p.description,
+		SKU:           p.sku,
+		Category:      p.category,
+		Price:         p.price,
+		Weight:        p.weight,
+		Dimensions:    p.dimensions,
+		StockLevel:    p.stockLevel,
+		MinStockLevel: p.minStockLevel,
+		Status:        p.status,
+		Images:        append([]string(nil), p.images...),
+		Tags:          append([]string(nil), p.tags...),
+		CreatedAt:     p.createdAt,
+		UpdatedAt:     p.updatedAt,
+	}
+}
+
+// NewProductFromSnapshot восстанавливает товар из хранилища
+func NewProductFromSnapshot(snapshot Snapshot) (*Product, error) {
+	if snapshot.ProductID == uuid.Nil || snapshot.SKU == "" {
+		return nil, errors.New("snapshot has no product ID or SKU")
+	}
+
+	return &Product{
+		id:            snapshot.ProductID,
+		version:       snapshot.Version,
+		name:          snapshot.Name,
+		description:   snapshot.Description,
+		sku:           snapshot.SKU,
+		category:      snapshot.Category,
+		price:         snapshot.Price,
+		weight:        snapshot.Weight,
+		dimensions:    snapshot.Dimensions,
+		stockLevel:    snapshot.StockLevel,
+		minStockLevel: snapshot.MinStockLevel,
+		status:        snapshot.Status,
+		images:        append([]string(nil), snapshot.Images...),
+		tags:          append([]string(nil), snapshot.Tags...),
+		createdAt:     snapshot.CreatedAt,
+		updatedAt:     snapshot.UpdatedAt,
+	}, nil
+}
+
+// MarkSaved фиксирует запись товара: версия увеличивается на единицу
+//
+// Вызывается репозиторием после успешного Save, как MarkEventsCommitted у заказа.
+func (p *Product) MarkSaved() {
+	p.version++
+}
+
+// SetMinStockLevel задает порог, ниже которого остаток считается низким
+func (p *Product) SetMinStockLevel(level int) error {
+	if level < 0 {
+		return errors.New("min stock level cannot be negative")
+	}
+
+	p.minStockLevel = level
+	p.updatedAt = time.Now()
+	return nil
+}
@@ -0,0 +1,281 @@
+package promotion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/money"
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/product"
+
+	"github.com/google/uuid"
+)
+
+// ⚙️ ДВИЖОК ПРАВИЛ
+//
+// ============================================================================
+// КАК СЧИТАЕТСЯ СКИДКА:
+// ============================================================================
+//
+// Evaluate проходит правила по приоритету и для каждой позиции корзины
+// хранит остаток - стоимость позиции за вычетом уже примененных скидок.
+// Каждое следующее правило считается от остатка, поэтому 10% + 5% дают
+// 14.5%, а не 15%, и скидка позиции никогда не превышает ее стоимость.
+//
+// Проценты округляются вниз, фиксированные суммы раскладываются по
+// позициям через money.Allocate без потери копеек.
+//
+// ============================================================================
+
+// CartLine позиция корзины
+type CartLine struct {
+	ProductID uuid.UUID
+	Category  product.Category
+	Quantity  int
+	UnitPrice money.Money
+}
+
+// Cart корзина, к которой применяются правила
+type Cart struct {
+	CustomerID uuid.UUID
+	Currency   string
+	Lines      []CartLine
+	Coupons    []string
+	Uses       map[string]int // ID правила → заказы покупателя с этим правилом
+	Now        time.Time      // нулевое значение - time.Now()
+}
+
+// Result итог применения правил
+type Result struct {
+	// Discounts скидка по товару - аргумент Order.ApplyPromotions
+	Discounts map[uuid.UUID]money.Money
+	// Applied примененные правила в порядке применения
+	Applied []order.AppliedPromotion
+	// Rejected введенные купоны, которые не сработали, и причина
+	Rejected map[string]error
+	Total    money.Money
+}
+
+// Evaluate применяет правила к корзине
+//
+// Купон, который не относится ни к одному правилу, - ошибка
+// ErrUnknownCoupon. Купоны существующих правил, которые не сработали,
+// попадают в Result.Rejected.
+func Evaluate(rules []Rule, cart Cart) (*Result, error) {
+	currency := money.Zero(cart.Currency).Currency()
+	if cart.Now.IsZero() {
+		cart.Now = time.Now()
+	}
+
+	remaining := make([]int64, len(cart.Lines))
+	var subtotal int64
+	for i, line := range cart.Lines {
+		if line.UnitPrice.Currency() != currency {
+			return nil, fmt.Errorf("%w: cart is in %s, product %s is in %s",
+				money.ErrCurrencyMismatch, currency, line.ProductID, line.UnitPrice.Currency())
+		}
+		remaining[i] = line.UnitPrice.Multiply(int64(line.Quantity)).Amount()
+		subtotal += remaining[i]
+	}
+
+	ordered := make([]*Rule, 0, len(rules))
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+		ordered = append(ordered, &rules[i])
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Priority != ordered[j].Priority {
+			return ordered[i].Priority < ordered[j].Priority
+		}
+		return ordered[i].ID < ordered[j].ID
+	})
+
+	for _, coupon := range cart.Coupons {
+		if findCoupon(ordered, coupon) == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCoupon, strings.TrimSpace(coupon))
+		}
+	}
+
+	result := &Result{
+		Discounts: make(map[uuid.UUID]money.Money),
+		Rejected:  make(map[string]error),
+		Total:     money.Zero(currency),
+	}
+	lineDiscounts := make([]int64, len(cart.Lines))
+	exclusive := false
+
+	for _, rule := range ordered {
+		if rule.Coupon != "" && !hasCoupon(cart.Coupons, rule) {
+			continue
+		}
+		reject := func(err error) {
+			if rule.Coupon != "" {
+				result.Rejected[rule.Coupon] = err
+			}
+		}
+
+		switch {
+		case !rule.IsActive(cart.Now):
+			reject(ErrRuleInactive)
+			continue
+		case rule.MaxUsesPerCustomer > 0 && cart.Uses[rule.ID] >= rule.MaxUsesPerCustomer:
+			reject(ErrUsageLimitReached)
+			continue
+		case rule.Currency != "" && money.Zero(rule.Currency).Currency() != currency,
+			subtotal < rule.MinSubtotal:
+			reject(ErrConditionsNotMet)
+			continue
+		case exclusive || (!rule.Stackable && len(result.Applied) > 0):
+			reject(ErrNotStackable)
+			continue
+		}
+
+		discounts := rule.lineDiscounts(cart.Lines, remaining, currency)
+		var total int64
+		for i, discount := range discounts {
+			if discount > remaining[i] {
+				discount = remaining[i]
+			}
+			discounts[i] = discount
+			total += discount
+		}
+		if total == 0 {
+			reject(ErrConditionsNotMet)
+			continue
+		}
+
+		for i, discount := range discounts {
+			remaining[i] -= discount
+			lineDiscounts[i] += discount
+		}
+		result.Applied = append(result.Applied, order.AppliedPromotion{
+			RuleID:   rule.ID,
+			Name:     rule.Name,
+			Coupon:   rule.Coupon,
+			Discount: money.New(total, currency),
+		})
+		exclusive = !rule.Stackable
+	}
+
+	var total int64
+	for i, line := range cart.Lines {
+		if lineDiscounts[i] == 0 {
+			continue
+		}
+		sum := lineDiscounts[i] + result.Discounts[line.ProductID].Amount()
+		result.Discounts[line.ProductID] = money.New(sum, currency)
+		total += lineDiscounts[i]
+	}
+	result.Total = money.New(total, currency)
+	return result, nil
+}
+
+// lineDiscounts скидка правила по позициям от остатков remaining
+func (r *Rule) lineDiscounts(lines []CartLine, remaining []int64, currency string) []int64 {
+	discounts := make([]int64, len(lines))
+	eligible := make([]bool, len(lines))
+	var base int64
+	for i, line := range lines {
+		eligible[i] = r.appliesTo(line) && remaining[i] > 0
+		if eligible[i] {
+			base += remaining[i]
+		}
+	}
+	if base == 0 {
+		return discounts
+	}
+
+	percent := func(p float64) []int64 {
+		for i := range lines {
+			if eligible[i] {
+				discounts[i] = money.New(remaining[i], currency).Percent(p, money.RoundDown).Amount()
+			}
+		}
+		return discounts
+	}
+	fixed := func(amount int64) []int64 {
+		if amount > base {
+			amount = base
+		}
+		ratios := make([]int64, len(lines))
+		for i := range lines {
+			if eligible[i] {
+				ratios[i] = remaining[i]
+			}
+		}
+		parts, _ := money.New(amount, currency).Allocate(ratios...) // base > 0
+		for i, part := range parts {
+			discounts[i] = part.Amount()
+		}
+		return discounts
+	}
+
+	switch r.Type {
+	case TypePercentage, TypeCategorySale:
+		return percent(r.Percent)
+	case TypeFixed:
+		return fixed(r.Amount)
+	case TypeBuyXGetY:
+		for i, line := range lines {
+			if eligible[i] {
+				free := line.Quantity / (r.Buy + r.Get) * r.Get
+				discounts[i] = line.UnitPrice.Multiply(int64(free)).Amount()
+			}
+		}
+		return discounts
+	case TypeTiered:
+		var tier *Tier
+		for i := range r.Tiers {
+			if base >= r.Tiers[i].Threshold {
+				tier = &r.Tiers[i]
+			}
+		}
+		switch {
+		case tier == nil:
+			return discounts
+		case tier.Percent > 0:
+			return percent(tier.Percent)
+		default:
+			return fixed(tier.Amount)
+		}
+	}
+	return discounts
+}
+
+// appliesTo проверяет фильтры правила по товарам и категории
+func (r *Rule) appliesTo(line CartLine) bool {
+	if r.Category != "" && !line.Category.BelongsTo(r.Category) {
+		return false
+	}
+	if len(r.ProductIDs) == 0 {
+		return true
+	}
+	for _, id := range r.ProductIDs {
+		if id == line.ProductID {
+			return true
+		}
+	}
+	return false
+}
+
+func findCoupon(rules []*Rule, coupon string) *Rule {
+	for _, rule := range rules {
+		if rule.MatchesCoupon(coupon) {
+			return rule
+		}
+	}
+	return nil
+}
+
+func hasCoupon(coupons []string, rule *Rule) bool {
+	for _, coupon := range coupons {
+		if rule.MatchesCoupon(coupon) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,166 @@
+package promotion
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/money"
+	"pipeline-clean-architecture/internal/domain/product"
+
+	"github.com/google/uuid"
+)
+
+func category(t *testing.T, path string) product.Category {
+	t.Helper()
+	c, err := product.NewCategory(path, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func line(id uuid.UUID, c product.Category, quantity int, price int64) CartLine {
+	return CartLine{ProductID: id, Category: c, Quantity: quantity, UnitPrice: money.New(price, "RUB")}
+}
+
+func TestEvaluateRuleTypes(t *testing.T) {
+	laptop, mouse := uuid.New(), uuid.New()
+	computers := category(t, "Electronics > Computers")
+	books := category(t, "Books")
+	book := uuid.New()
+
+	cart := Cart{
+		Currency: "RUB",
+		Lines: []CartLine{
+			line(laptop, computers, 1, 100000),
+			line(mouse, computers, 5, 1000),
+			line(book, books, 1, 2000),
+		},
+	}
+
+	tests := []struct {
+		name string
+		rule Rule
+		want map[uuid.UUID]int64
+	}{
+		{
+			name: "percentage",
+			rule: Rule{Type: TypePercentage, Percent: 10},
+			want: map[uuid.UUID]int64{laptop: 10000, mouse: 500, book: 200},
+		},
+		{
+			name: "fixed split by line value",
+			rule: Rule{Type: TypeFixed, Amount: 1070},
+			want: map[uuid.UUID]int64{laptop: 1000, mouse: 50, book: 20},
+		},
+		{
+			name: "buy 2 get 1",
+			rule: Rule{Type: TypeBuyXGetY, Buy: 2, Get: 1, ProductIDs: []uuid.UUID{mouse}},
+			want: map[uuid.UUID]int64{mouse: 1000},
+		},
+		{
+			name: "highest reached tier",
+			rule: Rule{Type: TypeTiered, Category: "Electronics", Tiers: []Tier{
+				{Threshold: 50000, Amount: 3000},
+				{Threshold: 100000, Percent: 5},
+				{Threshold: 500000, Percent: 20},
+			}},
+			want: map[uuid.UUID]int64{laptop: 5000, mouse: 250},
+		},
+		{
+			name: "category sale",
+			rule: Rule{Type: TypeCategorySale, Category: "Electronics", Percent: 15},
+			want: map[uuid.UUID]int64{laptop: 15000, mouse: 750},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.ID, tt.rule.Name = tt.name, tt.name
+			result, err := Evaluate([]Rule{tt.rule}, cart)
+			if err != nil {
+				t.Fatalf("Evaluate() error = %v", err)
+			}
+			if len(result.Discounts) != len(tt.want) {
+				t.Fatalf("Discounts = %v, want %v", result.Discounts, tt.want)
+			}
+			var total int64
+			for id, want := range tt.want {
+				if got := result.Discounts[id].Amount(); got != want {
+					t.Errorf("discount for %s = %d, want %d", id, got, want)
+				}
+				total += want
+			}
+			if result.Total.Amount() != total || len(result.Applied) != 1 || result.Applied[0].Discount.Amount() != total {
+				t.Errorf("Total = %s, Applied = %+v; want %d", result.Total, result.Applied, total)
+			}
+		})
+	}
+}
+
+func TestEvaluateStackingAndCoupons(t *testing.T) {
+	item := uuid.New()
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	cart := Cart{
+		Currency: "RUB",
+		Lines:    []CartLine{line(item, product.Category{}, 1, 10000)},
+		Coupons:  []string{" welcome ", "VIP"},
+		Uses:     map[string]int{"welcome": 1},
+		Now:      now,
+	}
+	rules := []Rule{
+		{ID: "autumn", Name: "Осень", Type: TypePercentage, Percent: 10, Priority: 1, Stackable: true},
+		{ID: "extra", Name: "Еще 5%", Type: TypePercentage, Percent: 5, Priority: 2, Stackable: true},
+		{ID: "welcome", Name: "Новичок", Type: TypeFixed, Amount: 500, Coupon: "WELCOME", Priority: 3,
+			Stackable: true, MaxUsesPerCustomer: 1},
+		{ID: "vip", Name: "VIP", Type: TypePercentage, Percent: 50, Coupon: "VIP", Priority: 4},
+		{ID: "expired", Name: "Лето", Type: TypeFixed, Amount: 100, Priority: 0, Stackable: true,
+			EndsAt: now.Add(-time.Hour)},
+	}
+
+	result, err := Evaluate(rules, cart)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	// 10% от 100 ₽, затем 5% от оставшихся 90 ₽
+	if result.Total.Amount() != 1450 || len(result.Applied) != 2 {
+		t.Errorf("Total = %s, Applied = %+v; want 14.50 RUB from autumn and extra", result.Total, result.Applied)
+	}
+	if !errors.Is(result.Rejected["WELCOME"], ErrUsageLimitReached) {
+		t.Errorf("Rejected[WELCOME] = %v, want ErrUsageLimitReached", result.Rejected["WELCOME"])
+	}
+	if !errors.Is(result.Rejected["VIP"], ErrNotStackable) {
+		t.Errorf("Rejected[VIP] = %v, want ErrNotStackable", result.Rejected["VIP"])
+	}
+
+	// Исключительное правило с высшим приоритетом отключает остальные
+	rules[3].Priority = -1
+	result, err = Evaluate(rules, cart)
+	if err != nil {
+		t.Fatalf("Evaluate() error = %v", err)
+	}
+	if result.Total.Amount() != 5000 || len(result.Applied) != 1 || result.Applied[0].RuleID != "vip" {
+		t.Errorf("Total = %s, Applied = %+v; want 50.00 RUB from vip only", result.Total, result.Applied)
+	}
+
+	cart.Coupons = []string{"NOPE"}
+	if _, err := Evaluate(rules, cart); !errors.Is(err, ErrUnknownCoupon) {
+		t.Errorf("Evaluate(NOPE) error = %v, want ErrUnknownCoupon", err)
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	invalid := []Rule{
+		{ID: "x", Name: "x", Type: TypePercentage, Percent: 120},
+		{ID: "x", Name: "x", Type: TypeFixed},
+		{ID: "x", Name: "x", Type: TypeBuyXGetY, Buy: 2},
+		{ID: "x", Name: "x", Type: TypeCategorySale, Percent: 10},
+		{ID: "x", Name: "x", Type: TypeTiered, Tiers: []Tier{{Threshold: 100, Percent: 5}, {Threshold: 50, Percent: 10}}},
+		{ID: "x", Name: "x", Type: "bogus"},
+	}
+	for _, rule := range invalid {
+		if err := rule.Validate(); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("Validate(%+v) error = %v, want ErrInvalidRule", rule, err)
+		}
+	}
+}
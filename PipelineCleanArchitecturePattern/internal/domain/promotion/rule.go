@@ -0,0 +1,198 @@
+package promotion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// 🏷️ ПРАВИЛА СКИДОК
+//
+// ============================================================================
+// ТИПЫ ПРАВИЛ:
+// ============================================================================
+//
+//	percentage    Percent% от подходящих позиций
+//	fixed         Amount на подходящие позиции (пропорционально стоимости)
+//	buy_x_get_y   из каждых Buy+Get единиц товара Get бесплатно
+//	tiered        ступень с наибольшим Threshold <= суммы подходящих позиций
+//	category_sale Percent% на товары ветки Category
+//
+// Подходящие позиции - товары из ProductIDs и ветки Category (пустой фильтр
+// пропускает все позиции). Денежные поля (Amount, MinSubtotal, Threshold)
+// заданы в копейках валюты Currency; правило с другой валютой к корзине
+// не применяется.
+//
+// ============================================================================
+// КУПОНЫ И СОВМЕСТИМОСТЬ:
+// ============================================================================
+//
+// Правило без Coupon применяется автоматически, с Coupon - только если
+// покупатель ввел купон. Правила проверяются по возрастанию Priority.
+// Stackable правила суммируются; правило без Stackable применяется, только
+// если до него ничего не применилось, и останавливает проверку остальных.
+//
+// ============================================================================
+
+// Ошибки правил скидок
+var (
+	// ErrInvalidRule правило не прошло проверку Validate
+	ErrInvalidRule = errors.New("invalid promotion rule")
+
+	// ErrUnknownCoupon купон не относится ни к одному правилу
+	ErrUnknownCoupon = errors.New("unknown coupon")
+
+	// ErrRuleInactive правило еще не началось или уже закончилось
+	ErrRuleInactive = errors.New("promotion is not active")
+
+	// ErrUsageLimitReached покупатель исчерпал MaxUsesPerCustomer
+	ErrUsageLimitReached = errors.New("promotion usage limit reached")
+
+	// ErrConditionsNotMet корзина не подходит под условия правила
+	ErrConditionsNotMet = errors.New("promotion conditions not met")
+
+	// ErrNotStackable правило не совмещается с уже примененными
+	ErrNotStackable = errors.New("promotion cannot be combined with others")
+)
+
+// Type тип правила скидки
+type Type string
+
+const (
+	TypePercentage   Type = "percentage"
+	TypeFixed        Type = "fixed"
+	TypeBuyXGetY     Type = "buy_x_get_y"
+	TypeTiered       Type = "tiered"
+	TypeCategorySale Type = "category_sale"
+)
+
+// Tier ступень правила tiered: Percent или Amount от порога Threshold
+type Tier struct {
+	Threshold int64
+	Percent   float64
+	Amount    int64
+}
+
+// Rule декларативное правило скидки (см. promotionstore)
+type Rule struct {
+	ID                 string
+	Name               string
+	Type               Type
+	Coupon             string // "" - правило применяется автоматически
+	Priority           int    // меньше - раньше
+	Stackable          bool   // суммируется с другими правилами
+	MaxUsesPerCustomer int    // заказов со скидкой на покупателя (0 - без ограничения)
+
+	StartsAt time.Time // нулевое значение - без начала
+	EndsAt   time.Time // нулевое значение - без окончания
+
+	Currency    string
+	Percent     float64
+	Amount      int64
+	MinSubtotal int64 // минимальная сумма корзины до скидок
+
+	ProductIDs []uuid.UUID
+	Category   string // путь категории: "Electronics > Computers"
+
+	Buy   int
+	Get   int
+	Tiers []Tier // по возрастанию Threshold
+}
+
+// RuleSource источник правил скидок
+type RuleSource interface {
+	// Rules возвращает все правила, включая неактивные по датам
+	Rules(ctx context.Context) ([]Rule, error)
+}
+
+// UsageRepository учет использований правил покупателями
+//
+// Использование - заказ, к которому применено правило. Повторное применение
+// правил к тому же заказу не расходует лимит.
+type UsageRepository interface {
+	// CountUses считает заказы покупателя с правилом, кроме exceptOrderID
+	CountUses(ctx context.Context, ruleID string, customerID, exceptOrderID uuid.UUID) (int, error)
+
+	// ReplaceUses заменяет правила, примененные к заказу; пустой ruleIDs
+	// освобождает использования заказа (например, при отмене)
+	ReplaceUses(ctx context.Context, orderID, customerID uuid.UUID, ruleIDs []string) error
+}
+
+// Validate проверяет правило
+func (r *Rule) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("%w: id is required", ErrInvalidRule)
+	}
+	if r.Name == "" {
+		return fmt.Errorf("%w: %s: name is required", ErrInvalidRule, r.ID)
+	}
+	if r.MaxUsesPerCustomer < 0 {
+		return fmt.Errorf("%w: %s: max uses per customer cannot be negative", ErrInvalidRule, r.ID)
+	}
+	if !r.StartsAt.IsZero() && !r.EndsAt.IsZero() && !r.EndsAt.After(r.StartsAt) {
+		return fmt.Errorf("%w: %s: ends_at must be after starts_at", ErrInvalidRule, r.ID)
+	}
+	if r.MinSubtotal < 0 {
+		return fmt.Errorf("%w: %s: min subtotal cannot be negative", ErrInvalidRule, r.ID)
+	}
+
+	switch r.Type {
+	case TypePercentage:
+		return r.validatePercent(r.Percent)
+	case TypeFixed:
+		if r.Amount <= 0 {
+			return fmt.Errorf("%w: %s: amount must be positive", ErrInvalidRule, r.ID)
+		}
+	case TypeBuyXGetY:
+		if r.Buy <= 0 || r.Get <= 0 {
+			return fmt.Errorf("%w: %s: buy and get must be positive", ErrInvalidRule, r.ID)
+		}
+	case TypeTiered:
+		if len(r.Tiers) == 0 {
+			return fmt.Errorf("%w: %s: tiers are required", ErrInvalidRule, r.ID)
+		}
+		for i, tier := range r.Tiers {
+			if i > 0 && tier.Threshold <= r.Tiers[i-1].Threshold {
+				return fmt.Errorf("%w: %s: tier thresholds must increase", ErrInvalidRule, r.ID)
+			}
+			if (tier.Percent > 0) == (tier.Amount > 0) {
+				return fmt.Errorf("%w: %s: tier %d needs either percent or amount", ErrInvalidRule, r.ID, i)
+			}
+			if tier.Percent > 100 {
+				return fmt.Errorf("%w: %s: tier %d percent must be in (0, 100]", ErrInvalidRule, r.ID, i)
+			}
+		}
+	case TypeCategorySale:
+		if r.Category == "" {
+			return fmt.Errorf("%w: %s: category is required", ErrInvalidRule, r.ID)
+		}
+		return r.validatePercent(r.Percent)
+	default:
+		return fmt.Errorf("%w: %s: unknown type %q", ErrInvalidRule, r.ID, r.Type)
+	}
+	return nil
+}
+
+func (r *Rule) validatePercent(percent float64) error {
+	if percent <= 0 || percent > 100 {
+		return fmt.Errorf("%w: %s: percent must be in (0, 100]", ErrInvalidRule, r.ID)
+	}
+	return nil
+}
+
+// IsActive проверяет, действует ли правило в момент now
+func (r *Rule) IsActive(now time.Time) bool {
+	if !r.StartsAt.IsZero() && now.Before(r.StartsAt) {
+		return false
+	}
+	return r.EndsAt.IsZero() || now.Before(r.EndsAt)
+}
+
+// MatchesCoupon проверяет купон без учета регистра и пробелов
+func (r *Rule) MatchesCoupon(coupon string) bool {
+	return r.Coupon != "" && strings.EqualFold(r.Coupon, strings.TrimSpace(coupon))
+}
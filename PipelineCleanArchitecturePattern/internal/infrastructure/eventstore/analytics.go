@@ -0,0 +1,356 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+// 📊 ПРОЕКЦИЯ АНАЛИТИКИ ЗАКАЗОВ
+//
+// ============================================================================
+// МОДЕЛЬ ЧТЕНИЯ ДЛЯ order.QueryRepository:
+// ============================================================================
+//
+// Проекция хранит по каждому заказу клиента, позиции и факт оплаты.
+// Выручка признается событием order.paid (время события - время оплаты)
+// и снимается событиями order.cancelled и order.refunded.
+//
+// Все запросы считают только оплаченные заказы с временем оплаты
+// в полуинтервале [from, to).
+//
+// ============================================================================
+
+// Проверка реализации интерфейсов домена
+var (
+	_ order.Projection      = (*AnalyticsProjection)(nil)
+	_ order.QueryRepository = (*AnalyticsProjection)(nil)
+)
+
+// AnalyticsProjection модель чтения для аналитики заказов
+type AnalyticsProjection struct {
+	mu     sync.RWMutex
+	orders map[uuid.UUID]*orderView
+}
+
+// orderView состояние заказа, нужное аналитике
+type orderView struct {
+	customerID uuid.UUID
+	currency   string
+	items      []itemView
+	paid       bool
+	paidAt     time.Time
+	paidAmount order.Money
+}
+
+// itemView позиция заказа
+type itemView struct {
+	productID uuid.UUID
+	quantity  int
+	unitPrice int64
+	discount  int64
+}
+
+// total стоимость позиции в копейках
+func (i itemView) total() int64 {
+	return i.unitPrice*int64(i.quantity) - i.discount
+}
+
+// NewAnalyticsProjection создает пустую проекцию
+func NewAnalyticsProjection() *AnalyticsProjection {
+	return &AnalyticsProjection{
+		orders: make(map[uuid.UUID]*orderView),
+	}
+}
+
+// 🔄 ПОСТРОЕНИЕ ПРОЕКЦИИ
+
+// Apply применяет событие заказа
+func (p *AnalyticsProjection) Apply(ctx context.Context, event order.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.applyLocked(event)
+}
+
+// Rebuild строит проекцию заново по всем событиям хранилища
+func (p *AnalyticsProjection) Rebuild(ctx context.Context, store order.EventStore) error {
+	events, err := store.LoadAll(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load events: %w", err)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.orders = make(map[uuid.UUID]*orderView)
+	for _, event := range events {
+		if err := p.applyLocked(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *AnalyticsProjection) applyLocked(event order.Event) error {
+	if created, ok := event.Data.(order.OrderCreated); ok {
+		view := &orderView{customerID: created.CustomerID, currency: created.Currency}
+		for i := range created.Items {
+			view.items = append(view.items, newItemView(&created.Items[i]))
+		}
+		p.orders[event.OrderID] = view
+		return nil
+	}
+
+	view, ok := p.orders[event.OrderID]
+	if !ok {
+		return fmt.Errorf("analytics projection: %s for unknown order %s", event.Type(), event.OrderID)
+	}
+
+	switch data := event.Data.(type) {
+	case order.ItemAdded:
+		view.items = append(view.items, newItemView(&data.Item))
+	case order.DiscountApplied:
+		for i := range view.items {
+			if view.items[i].productID == data.ProductID {
+				view.items[i].discount = data.Discount.Amount()
+				break
+			}
+		}
+	case order.OrderPaid:
+		view.paid = true
+		view.paidAt = event.OccurredAt
+		view.paidAmount = data.Amount
+	case order.OrderCancelled, order.OrderRefunded:
+		view.paid = false
+	}
+	return nil
+}
+
+func newItemView(item *order.OrderItem) itemView {
+	return itemView{
+		productID: item.ProductID(),
+		quantity:  item.Quantity(),
+		unitPrice: item.UnitPrice().Amount(),
+		discount:  item.Discount().Amount(),
+	}
+}
+
+// 🔍 order.QueryRepository
+
+// GetTopCustomersByRevenue возвращает клиентов с наибольшей выручкой
+func (p *AnalyticsProjection) GetTopCustomersByRevenue(ctx context.Context, limit int, from, to time.Time) ([]order.CustomerRevenue, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	byCustomer := make(map[uuid.UUID]*revenue)
+	for _, view := range p.paidOrders(from, to) {
+		if byCustomer[view.customerID] == nil {
+			byCustomer[view.customerID] = &revenue{}
+		}
+		if err := byCustomer[view.customerID].add(view.paidAmount.Amount(), view.paidAmount.Currency()); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]order.CustomerRevenue, 0, len(byCustomer))
+	for customerID, total := range byCustomer {
+		result = append(result, order.CustomerRevenue{
+			CustomerID:   customerID,
+			TotalRevenue: total.total(),
+			OrdersCount:  total.count,
+			AverageOrder: total.average(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalRevenue.Amount() != result[j].TotalRevenue.Amount() {
+			return result[i].TotalRevenue.Amount() > result[j].TotalRevenue.Amount()
+		}
+		return result[i].CustomerID.String() < result[j].CustomerID.String()
+	})
+	return truncate(result, limit), nil
+}
+
+// GetPopularProducts возвращает товары с наибольшим количеством продаж
+func (p *AnalyticsProjection) GetPopularProducts(ctx context.Context, limit int, from, to time.Time) ([]order.ProductSales, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	type productTotals struct {
+		quantity int
+		revenue  revenue
+	}
+
+	byProduct := make(map[uuid.UUID]*productTotals)
+	for _, view := range p.paidOrders(from, to) {
+		// Позиции одного товара в заказе сливаются, чтобы OrdersCount считал заказы
+		type orderLine struct {
+			quantity int
+			amount   int64
+		}
+		perOrder := make(map[uuid.UUID]orderLine)
+		for _, item := range view.items {
+			line := perOrder[item.productID]
+			line.quantity += item.quantity
+			line.amount += item.total()
+			perOrder[item.productID] = line
+		}
+
+		for productID, line := range perOrder {
+			if byProduct[productID] == nil {
+				byProduct[productID] = &productTotals{}
+			}
+			totals := byProduct[productID]
+			totals.quantity += line.quantity
+			if err := totals.revenue.add(line.amount, view.currency); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	result := make([]order.ProductSales, 0, len(byProduct))
+	for productID, totals := range byProduct {
+		result = append(result, order.ProductSales{
+			ProductID:     productID,
+			TotalQuantity: totals.quantity,
+			TotalRevenue:  totals.revenue.total(),
+			OrdersCount:   totals.revenue.count,
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].TotalQuantity != result[j].TotalQuantity {
+			return result[i].TotalQuantity > result[j].TotalQuantity
+		}
+		if result[i].TotalRevenue.Amount() != result[j].TotalRevenue.Amount() {
+			return result[i].TotalRevenue.Amount() > result[j].TotalRevenue.Amount()
+		}
+		return result[i].ProductID.String() < result[j].ProductID.String()
+	})
+	return truncate(result, limit), nil
+}
+
+// GetOrderTrends возвращает количество и выручку оплаченных заказов по периодам
+func (p *AnalyticsProjection) GetOrderTrends(ctx context.Context, from, to time.Time, groupBy order.TrendGroupBy) ([]order.OrderTrend, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	byPeriod := make(map[time.Time]*revenue)
+	for _, view := range p.paidOrders(from, to) {
+		period, err := periodStart(view.paidAt, groupBy)
+		if err != nil {
+			return nil, err
+		}
+		if byPeriod[period] == nil {
+			byPeriod[period] = &revenue{}
+		}
+		if err := byPeriod[period].add(view.paidAmount.Amount(), view.paidAmount.Currency()); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]order.OrderTrend, 0, len(byPeriod))
+	for period, total := range byPeriod {
+		result = append(result, order.OrderTrend{
+			Period:       period,
+			OrdersCount:  total.count,
+			TotalRevenue: total.total(),
+			AverageOrder: total.average(),
+		})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Period.Before(result[j].Period)
+	})
+	return result, nil
+}
+
+// GetAverageOrderValue возвращает средний чек оплаченных заказов
+func (p *AnalyticsProjection) GetAverageOrderValue(ctx context.Context, from, to time.Time) (order.Money, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var total revenue
+	for _, view := range p.paidOrders(from, to) {
+		if err := total.add(view.paidAmount.Amount(), view.paidAmount.Currency()); err != nil {
+			return order.Money{}, err
+		}
+	}
+	return total.average(), nil
+}
+
+// 🔧 ВСПОМОГАТЕЛЬНЫЕ ФУНКЦИИ
+
+// paidOrders возвращает заказы, оплаченные в [from, to)
+func (p *AnalyticsProjection) paidOrders(from, to time.Time) []*orderView {
+	var result []*orderView
+	for _, view := range p.orders {
+		if view.paid && !view.paidAt.Before(from) && view.paidAt.Before(to) {
+			result = append(result, view)
+		}
+	}
+	return result
+}
+
+// revenue накопитель суммы в одной валюте
+type revenue struct {
+	amount   int64
+	currency string
+	count    int
+}
+
+func (r *revenue) add(amount int64, currency string) error {
+	if r.count > 0 && r.currency != currency {
+		return fmt.Errorf("%w: %s and %s", order.ErrMixedCurrencies, r.currency, currency)
+	}
+
+	r.amount += amount
+	r.currency = currency
+	r.count++
+	return nil
+}
+
+func (r *revenue) total() order.Money {
+	return order.NewMoney(r.amount, r.currency)
+}
+
+func (r *revenue) average() order.Money {
+	if r.count == 0 {
+		return order.NewMoney(0, r.currency)
+	}
+	return order.NewMoney(r.amount/int64(r.count), r.currency)
+}
+
+// periodStart возвращает начало периода группировки (UTC, неделя с понедельника)
+func periodStart(t time.Time, groupBy order.TrendGroupBy) (time.Time, error) {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+
+	switch groupBy {
+	case order.TrendGroupByDay:
+		return day, nil
+	case order.TrendGroupByWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)), nil
+	case order.TrendGroupByMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC), nil
+	case order.TrendGroupByYear:
+		return time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported trend grouping %q", groupBy)
+	}
+}
+
+// truncate ограничивает выборку; limit <= 0 - без ограничения
+func truncate[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
@@ -0,0 +1,165 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+// orderStream собирает поток событий заказа с заданными временами
+type orderStream struct {
+	id     uuid.UUID
+	events []order.Event
+}
+
+func newOrderStream(customerID uuid.UUID, at time.Time, items ...order.OrderItem) *orderStream {
+	s := &orderStream{id: uuid.New()}
+	return s.add(at, order.OrderCreated{CustomerID: customerID, Items: items, Currency: "RUB"})
+}
+
+func (s *orderStream) add(at time.Time, data order.EventData) *orderStream {
+	s.events = append(s.events, order.Event{
+		ID:         uuid.New(),
+		OrderID:    s.id,
+		Version:    len(s.events) + 1,
+		OccurredAt: at,
+		Data:       data,
+	})
+	return s
+}
+
+func (s *orderStream) paid(at time.Time, amount int64) *orderStream {
+	return s.add(at, order.OrderPaid{Amount: order.NewMoney(amount, "RUB")})
+}
+
+func item(t *testing.T, productID uuid.UUID, quantity int, price int64) order.OrderItem {
+	t.Helper()
+
+	it, err := order.NewOrderItem(productID, quantity, order.NewMoney(price, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return it
+}
+
+func TestAnalyticsProjectionQueries(t *testing.T) {
+	ctx := context.Background()
+	day := func(d, h int) time.Time { return time.Date(2024, time.March, d, h, 0, 0, 0, time.UTC) }
+
+	alice, bob := uuid.New(), uuid.New()
+	laptop, mouse := uuid.New(), uuid.New()
+
+	streams := []*orderStream{
+		// Alice: два оплаченных заказа 4 и 5 марта
+		newOrderStream(alice, day(4, 9), item(t, laptop, 1, 100000)).paid(day(4, 10), 100000),
+		newOrderStream(alice, day(5, 9), item(t, mouse, 2, 5000)).
+			add(day(5, 9), order.ItemAdded{Item: item(t, mouse, 1, 5000)}).
+			add(day(5, 9), order.DiscountApplied{ProductID: mouse, Discount: order.NewMoney(1000, "RUB")}).
+			paid(day(5, 10), 14000),
+		// Bob: оплачен 11 марта (следующая неделя)
+		newOrderStream(bob, day(11, 9), item(t, mouse, 4, 5000)).paid(day(11, 10), 20000),
+		// Bob: оплачен и возвращен - выручка не учитывается
+		newOrderStream(bob, day(6, 9), item(t, laptop, 3, 100000)).
+			paid(day(6, 10), 300000).
+			add(day(7, 10), order.OrderRefunded{Amount: order.NewMoney(300000, "RUB")}),
+		// Не оплачен
+		newOrderStream(bob, day(8, 9), item(t, laptop, 5, 100000)),
+	}
+
+	store := NewMemoryStore()
+	for _, s := range streams {
+		if err := store.Append(ctx, s.id, 0, s.events); err != nil {
+			t.Fatal(err)
+		}
+	}
+	projection := NewAnalyticsProjection()
+	if err := projection.Rebuild(ctx, store); err != nil {
+		t.Fatalf("Rebuild() error = %v", err)
+	}
+
+	from, to := day(1, 0), day(31, 0)
+
+	top, err := projection.GetTopCustomersByRevenue(ctx, 1, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 1 || top[0].CustomerID != alice || top[0].TotalRevenue.Amount() != 114000 ||
+		top[0].OrdersCount != 2 || top[0].AverageOrder.Amount() != 57000 {
+		t.Errorf("top customers = %+v, want alice with 114000 over 2 orders", top)
+	}
+
+	products, err := projection.GetPopularProducts(ctx, 0, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(products) != 2 {
+		t.Fatalf("popular products = %+v, want 2", products)
+	}
+	if p := products[0]; p.ProductID != mouse || p.TotalQuantity != 7 || p.OrdersCount != 2 || p.TotalRevenue.Amount() != 34000 {
+		t.Errorf("top product = %+v, want mouse x7 in 2 orders for 34000", p)
+	}
+	if p := products[1]; p.ProductID != laptop || p.TotalQuantity != 1 {
+		t.Errorf("second product = %+v, want laptop x1", p)
+	}
+
+	trends, err := projection.GetOrderTrends(ctx, from, to, order.TrendGroupByWeek)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trends) != 2 {
+		t.Fatalf("weekly trends = %+v, want 2 weeks", trends)
+	}
+	if !trends[0].Period.Equal(day(4, 0)) || trends[0].OrdersCount != 2 || trends[0].TotalRevenue.Amount() != 114000 {
+		t.Errorf("first week = %+v, want week of March 4 with 2 orders", trends[0])
+	}
+	if !trends[1].Period.Equal(day(11, 0)) || trends[1].OrdersCount != 1 {
+		t.Errorf("second week = %+v, want week of March 11 with 1 order", trends[1])
+	}
+
+	average, err := projection.GetAverageOrderValue(ctx, day(5, 0), day(12, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if average.Amount() != 17000 {
+		t.Errorf("average order value = %s, want 170.00 RUB", average)
+	}
+
+	if _, err := projection.GetOrderTrends(ctx, from, to, "quarter"); err == nil {
+		t.Error("GetOrderTrends() with unknown grouping error = nil")
+	}
+}
+
+func TestAnalyticsProjectionRejectsMixedCurrencies(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2024, time.March, 4, 10, 0, 0, 0, time.UTC)
+	projection := NewAnalyticsProjection()
+
+	rub := newOrderStream(uuid.New(), at, item(t, uuid.New(), 1, 100)).paid(at, 100)
+	usd := newOrderStream(uuid.New(), at, item(t, uuid.New(), 1, 100)).
+		add(at, order.OrderPaid{Amount: order.NewMoney(100, "USD")})
+
+	for _, s := range []*orderStream{rub, usd} {
+		for _, event := range s.events {
+			if err := projection.Apply(ctx, event); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if _, err := projection.GetAverageOrderValue(ctx, at, at.Add(time.Hour)); !errors.Is(err, order.ErrMixedCurrencies) {
+		t.Errorf("GetAverageOrderValue() error = %v, want ErrMixedCurrencies", err)
+	}
+}
+
+func TestAnalyticsProjectionRejectsUnknownOrder(t *testing.T) {
+	event := order.Event{OrderID: uuid.New(), Version: 2, Data: order.OrderValidated{}}
+
+	if err := NewAnalyticsProjection().Apply(context.Background(), event); err == nil {
+		t.Error("Apply() for order without order.created error = nil")
+	}
+}
@@ -0,0 +1,109 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+// 💾 IN-MEMORY ХРАНИЛИЩЕ СОБЫТИЙ И СНИМКОВ
+//
+// Реализует order.EventStore и order.SnapshotStore. Подходит для тестов и
+// демо; продакшен-реализация на PostgreSQL повторяет те же гарантии:
+// append-only поток на заказ и проверка expectedVersion при записи.
+
+// Проверка реализации интерфейсов домена
+var (
+	_ order.EventStore    = (*MemoryStore)(nil)
+	_ order.SnapshotStore = (*MemoryStore)(nil)
+)
+
+// MemoryStore хранилище событий заказов в памяти
+type MemoryStore struct {
+	mu        sync.RWMutex
+	streams   map[uuid.UUID][]order.Event
+	log       []order.Event // Все события в порядке записи
+	snapshots map[uuid.UUID]order.Snapshot
+}
+
+// NewMemoryStore создает пустое хранилище
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		streams:   make(map[uuid.UUID][]order.Event),
+		snapshots: make(map[uuid.UUID]order.Snapshot),
+	}
+}
+
+// Append дописывает события в поток заказа
+func (s *MemoryStore) Append(ctx context.Context, orderID uuid.UUID, expectedVersion int, events []order.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stream := s.streams[orderID]
+	if len(stream) != expectedVersion {
+		return fmt.Errorf("%w: order %s is at version %d, expected %d",
+			order.ErrEventVersionConflict, orderID, len(stream), expectedVersion)
+	}
+
+	for i, event := range events {
+		if event.OrderID != orderID || event.Version != expectedVersion+i+1 {
+			return fmt.Errorf("%w: event %s/%d cannot follow version %d of order %s",
+				order.ErrInvalidEventStream, event.OrderID, event.Version, expectedVersion+i, orderID)
+		}
+	}
+
+	s.streams[orderID] = append(stream, events...)
+	s.log = append(s.log, events...)
+	return nil
+}
+
+// Load возвращает события заказа с версией больше afterVersion
+func (s *MemoryStore) Load(ctx context.Context, orderID uuid.UUID, afterVersion int) ([]order.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stream := s.streams[orderID]
+	if afterVersion < 0 {
+		afterVersion = 0
+	}
+	if afterVersion >= len(stream) {
+		return nil, nil
+	}
+	return append([]order.Event(nil), stream[afterVersion:]...), nil
+}
+
+// LoadAll возвращает события всех заказов в порядке записи
+func (s *MemoryStore) LoadAll(ctx context.Context) ([]order.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return append([]order.Event(nil), s.log...), nil
+}
+
+// SaveSnapshot сохраняет снимок, если он не старше уже сохраненного
+func (s *MemoryStore) SaveSnapshot(ctx context.Context, snapshot order.Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if current, ok := s.snapshots[snapshot.OrderID]; ok && current.Version >= snapshot.Version {
+		return nil
+	}
+	s.snapshots[snapshot.OrderID] = snapshot
+	return nil
+}
+
+// LoadSnapshot возвращает последний снимок заказа
+func (s *MemoryStore) LoadSnapshot(ctx context.Context, orderID uuid.UUID) (*order.Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.snapshots[orderID]
+	if !ok {
+		return nil, order.ErrSnapshotNotFound
+	}
+	return &snapshot, nil
+}
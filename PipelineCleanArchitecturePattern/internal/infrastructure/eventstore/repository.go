@@ -0,0 +1,159 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// 🗄️ EVENT-SOURCED РЕПОЗИТОРИЙ ЗАКАЗОВ
+//
+// ============================================================================
+// СОХРАНЕНИЕ:
+// ============================================================================
+//
+// 1. Новые события заказа дописываются в EventStore с expectedVersion,
+//    поэтому параллельное изменение того же заказа получит
+//    order.ErrEventVersionConflict.
+// 2. Каждые snapshotEvery событий сохраняется снимок состояния.
+// 3. Записанные события передаются зарегистрированным проекциям.
+//
+// Снимки и проекции вторичны: события уже записаны, поэтому их ошибки
+// логируются и не возвращаются. Снимок будет сделан на следующем пороге,
+// проекцию можно перестроить из EventStore (AnalyticsProjection.Rebuild).
+//
+// ============================================================================
+// ЗАГРУЗКА:
+// ============================================================================
+//
+// Последний снимок + события после него. Без снимка заказ восстанавливается
+// из полного потока.
+//
+// ============================================================================
+
+// Repository репозиторий заказов поверх хранилища событий
+type Repository struct {
+	logger        *zap.Logger
+	events        order.EventStore
+	snapshots     order.SnapshotStore
+	snapshotEvery int
+	projections   []order.Projection
+}
+
+// NewRepository создает репозиторий
+//
+// snapshots == nil или snapshotEvery <= 0 отключают снимки.
+func NewRepository(logger *zap.Logger, events order.EventStore, snapshots order.SnapshotStore, snapshotEvery int) *Repository {
+	return &Repository{
+		logger:        logger,
+		events:        events,
+		snapshots:     snapshots,
+		snapshotEvery: snapshotEvery,
+	}
+}
+
+// RegisterProjection подписывает проекцию на сохраненные события
+func (r *Repository) RegisterProjection(projection order.Projection) {
+	r.projections = append(r.projections, projection)
+}
+
+// Save дописывает новые события заказа
+func (r *Repository) Save(ctx context.Context, ord *order.Order) error {
+	changes := ord.UncommittedEvents()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	expectedVersion := changes[0].Version - 1
+	if err := r.events.Append(ctx, ord.ID(), expectedVersion, changes); err != nil {
+		return fmt.Errorf("failed to append events of order %s: %w", ord.ID(), err)
+	}
+	ord.MarkEventsCommitted()
+
+	if r.shouldSnapshot(expectedVersion, ord.Version()) {
+		r.saveSnapshot(ctx, ord)
+	}
+
+	for _, event := range changes {
+		for _, projection := range r.projections {
+			if err := projection.Apply(ctx, event); err != nil {
+				r.logger.Error("Projection failed to apply order event",
+					zap.String("order_id", event.OrderID.String()),
+					zap.String("event", string(event.Type())),
+					zap.Int("version", event.Version),
+					zap.Error(err))
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetByID восстанавливает заказ из снимка и событий
+func (r *Repository) GetByID(ctx context.Context, id uuid.UUID) (*order.Order, error) {
+	snapshot, err := r.loadSnapshot(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	afterVersion := 0
+	if snapshot != nil {
+		afterVersion = snapshot.Version
+	}
+
+	events, err := r.events.Load(ctx, id, afterVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load events of order %s: %w", id, err)
+	}
+
+	if snapshot != nil {
+		return order.NewOrderFromSnapshot(*snapshot, events)
+	}
+	if len(events) == 0 {
+		return nil, fmt.Errorf("%w: %s", order.ErrOrderNotFound, id)
+	}
+	return order.NewOrderFromHistory(events)
+}
+
+// shouldSnapshot проверяет, пересекла ли запись порог снимка
+func (r *Repository) shouldSnapshot(fromVersion, toVersion int) bool {
+	if r.snapshots == nil || r.snapshotEvery <= 0 {
+		return false
+	}
+	return toVersion/r.snapshotEvery > fromVersion/r.snapshotEvery
+}
+
+// saveSnapshot сохраняет снимок заказа
+func (r *Repository) saveSnapshot(ctx context.Context, ord *order.Order) {
+	snapshot, err := ord.Snapshot()
+	if err == nil {
+		err = r.snapshots.SaveSnapshot(ctx, snapshot)
+	}
+	if err != nil {
+		r.logger.Warn("Failed to save order snapshot",
+			zap.String("order_id", ord.ID().String()),
+			zap.Int("version", ord.Version()),
+			zap.Error(err))
+	}
+}
+
+// loadSnapshot возвращает последний снимок заказа или nil
+func (r *Repository) loadSnapshot(ctx context.Context, id uuid.UUID) (*order.Snapshot, error) {
+	if r.snapshots == nil {
+		return nil, nil
+	}
+
+	snapshot, err := r.snapshots.LoadSnapshot(ctx, id)
+	if errors.Is(err, order.ErrSnapshotNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load snapshot of order %s: %w", id, err)
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,156 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+func newOrder(t *testing.T, customerID uuid.UUID, productID uuid.UUID, quantity int, price int64) *order.Order {
+	t.Helper()
+
+	item, err := order.NewOrderItem(productID, quantity, order.NewMoney(price, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ord, err := order.NewOrder(customerID, []order.OrderItem{item}, order.NewAddress("Невский 1", "Санкт-Петербург", "191186", "RU", "+7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ord
+}
+
+func TestRepositoryRoundTripWithSnapshots(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	repo := NewRepository(zap.NewNop(), store, store, 3)
+
+	ord := newOrder(t, uuid.New(), uuid.New(), 1, 10000)
+	if err := repo.Save(ctx, ord); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := store.LoadSnapshot(ctx, ord.ID()); !errors.Is(err, order.ErrSnapshotNotFound) {
+		t.Errorf("snapshot after 1 event: error = %v, want ErrSnapshotNotFound", err)
+	}
+
+	for _, transition := range []func() error{ord.MarkAsValidated, ord.MarkAsPaymentProcessing, ord.MarkAsPaid} {
+		if err := transition(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Save(ctx, ord); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	snapshot, err := store.LoadSnapshot(ctx, ord.ID())
+	if err != nil {
+		t.Fatalf("LoadSnapshot() error = %v", err)
+	}
+	if snapshot.Version != 4 || snapshot.Status != order.StatusPaid {
+		t.Errorf("snapshot version %d status %s, want 4 paid", snapshot.Version, snapshot.Status)
+	}
+
+	if err := ord.MarkAsInventoryChecked(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, ord); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	loaded, err := repo.GetByID(ctx, ord.ID())
+	if err != nil {
+		t.Fatalf("GetByID() error = %v", err)
+	}
+	if loaded.Version() != 5 || loaded.Status() != order.StatusInventoryChecked {
+		t.Errorf("loaded version %d status %s, want 5 inventory_checked", loaded.Version(), loaded.Status())
+	}
+	if loaded.TotalAmount() != ord.TotalAmount() || loaded.CustomerID() != ord.CustomerID() {
+		t.Errorf("loaded order %s/%s differs from saved %s/%s",
+			loaded.CustomerID(), loaded.TotalAmount(), ord.CustomerID(), ord.TotalAmount())
+	}
+
+	events, err := store.Load(ctx, ord.ID(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 5 {
+		t.Errorf("stored %d events, want full history of 5", len(events))
+	}
+}
+
+func TestRepositoryDetectsConcurrentChanges(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	repo := NewRepository(zap.NewNop(), store, nil, 0)
+
+	ord := newOrder(t, uuid.New(), uuid.New(), 1, 10000)
+	if err := repo.Save(ctx, ord); err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := repo.GetByID(ctx, ord.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := repo.GetByID(ctx, ord.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := first.MarkAsValidated(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, first); err != nil {
+		t.Fatalf("first Save() error = %v", err)
+	}
+
+	if err := second.Cancel(); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Save(ctx, second); !errors.Is(err, order.ErrEventVersionConflict) {
+		t.Errorf("second Save() error = %v, want ErrEventVersionConflict", err)
+	}
+}
+
+func TestRepositoryGetByIDUnknownOrder(t *testing.T) {
+	store := NewMemoryStore()
+	repo := NewRepository(zap.NewNop(), store, store, 10)
+
+	if _, err := repo.GetByID(context.Background(), uuid.New()); !errors.Is(err, order.ErrOrderNotFound) {
+		t.Errorf("GetByID() error = %v, want ErrOrderNotFound", err)
+	}
+}
+
+func TestRepositoryFeedsProjections(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	projection := NewAnalyticsProjection()
+	repo := NewRepository(zap.NewNop(), store, store, 0)
+	repo.RegisterProjection(projection)
+
+	customerID := uuid.New()
+	ord := newOrder(t, customerID, uuid.New(), 2, 25000)
+	for _, transition := range []func() error{ord.MarkAsValidated, ord.MarkAsPaymentProcessing, ord.MarkAsPaid} {
+		if err := transition(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Save(ctx, ord); err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	top, err := projection.GetTopCustomersByRevenue(ctx, 10, from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(top) != 1 || top[0].CustomerID != customerID || top[0].TotalRevenue.Amount() != 50000 {
+		t.Errorf("top customers = %+v, want %s with 50000", top, customerID)
+	}
+}
@@ -0,0 +1,106 @@
+package exchangerates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/money"
+)
+
+// 💱 КУРСЫ ВАЛЮТ ИЗ ФАЙЛА
+//
+// ============================================================================
+// ФОРМАТ ФАЙЛА:
+// ============================================================================
+//
+//	{
+//	  "base": "RUB",
+//	  "as_of": "2026-10-01T00:00:00Z",
+//	  "rates": {"EUR": "101.2534", "USD": "92.1"}
+//	}
+//
+// rates - стоимость единицы валюты в базовой валюте (1 EUR = 101.2534 RUB).
+// Курсы записаны строками, чтобы не терять точность на float64. Кросс-курсы
+// (EUR → USD) считаются через базовую валюту.
+//
+// ============================================================================
+
+// Проверка реализации интерфейса домена
+var _ money.ExchangeRateProvider = (*StaticProvider)(nil)
+
+// StaticProvider неизменяемый набор курсов к базовой валюте
+type StaticProvider struct {
+	base  string
+	asOf  time.Time
+	rates map[string]*big.Rat // валюта → стоимость в base
+}
+
+// staticFile содержимое файла курсов
+type staticFile struct {
+	Base  string            `json:"base"`
+	AsOf  time.Time         `json:"as_of"`
+	Rates map[string]string `json:"rates"`
+}
+
+// NewStaticProvider создает провайдер из курсов к базовой валюте
+func NewStaticProvider(base string, asOf time.Time, rates map[string]string) (*StaticProvider, error) {
+	base = strings.ToUpper(strings.TrimSpace(base))
+	if base == "" {
+		return nil, fmt.Errorf("base currency is required")
+	}
+
+	p := &StaticProvider{
+		base:  base,
+		asOf:  asOf,
+		rates: map[string]*big.Rat{base: big.NewRat(1, 1)},
+	}
+	for currency, value := range rates {
+		rate, err := money.NewRate(currency, base, value, asOf)
+		if err != nil {
+			return nil, err
+		}
+		p.rates[rate.From()] = rate.Rat()
+	}
+	return p, nil
+}
+
+// LoadStaticFile читает курсы из JSON файла
+func LoadStaticFile(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read exchange rates: %w", err)
+	}
+
+	var file staticFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid exchange rates file %s: %w", path, err)
+	}
+	return NewStaticProvider(file.Base, file.AsOf, file.Rates)
+}
+
+// Base базовая валюта файла
+func (p *StaticProvider) Base() string { return p.base }
+
+// Rate возвращает курс from → to через базовую валюту
+func (p *StaticProvider) Rate(ctx context.Context, from, to string) (money.Rate, error) {
+	from, to = strings.ToUpper(from), strings.ToUpper(to)
+	if from == to {
+		return money.IdentityRate(from), nil
+	}
+
+	fromRate, ok := p.rates[from]
+	if !ok {
+		return money.Rate{}, fmt.Errorf("%w: %s/%s", money.ErrRateNotFound, from, to)
+	}
+	toRate, ok := p.rates[to]
+	if !ok {
+		return money.Rate{}, fmt.Errorf("%w: %s/%s", money.ErrRateNotFound, from, to)
+	}
+
+	return money.NewRateFromRat(from, to, new(big.Rat).Quo(fromRate, toRate), p.asOf)
+}
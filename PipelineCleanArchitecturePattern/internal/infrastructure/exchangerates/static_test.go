@@ -0,0 +1,51 @@
+package exchangerates
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/money"
+)
+
+func TestStaticFileCrossRates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	data := `{"base": "RUB", "as_of": "2026-10-01T00:00:00Z", "rates": {"EUR": "100", "USD": "80"}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	provider, err := LoadStaticFile(path)
+	if err != nil {
+		t.Fatalf("LoadStaticFile() error = %v", err)
+	}
+	ctx := context.Background()
+
+	tests := []struct {
+		from, to string
+		amount   int64
+		want     int64
+	}{
+		{"EUR", "RUB", 1999, 199900},
+		{"RUB", "EUR", 10000, 100},
+		{"EUR", "USD", 1000, 1250},
+		{"usd", "eur", 1000, 800},
+		{"RUB", "RUB", 12345, 12345},
+	}
+	for _, tt := range tests {
+		rate, err := provider.Rate(ctx, tt.from, tt.to)
+		if err != nil {
+			t.Fatalf("Rate(%s, %s) error = %v", tt.from, tt.to, err)
+		}
+		converted, err := rate.Convert(money.New(tt.amount, tt.from), money.RoundHalfUp)
+		if err != nil || converted.Amount() != tt.want {
+			t.Errorf("%d %s → %s = %s, %v; want %d", tt.amount, tt.from, tt.to, converted, err, tt.want)
+		}
+	}
+
+	if _, err := provider.Rate(ctx, "GBP", "RUB"); !errors.Is(err, money.ErrRateNotFound) {
+		t.Errorf("Rate(GBP) error = %v, want ErrRateNotFound", err)
+	}
+}
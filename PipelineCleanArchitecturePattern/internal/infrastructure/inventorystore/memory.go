@@ -0,0 +1,160 @@
+package inventorystore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/product"
+
+	"github.com/google/uuid"
+)
+
+// 💾 IN-MEMORY ХРАНИЛИЩЕ СКЛАДА
+//
+// ProductRepository хранит снимки товаров, а не указатели: GetByID отдает
+// каждому вызывающему свою копию, а Save принимает ее, только если версия не
+// изменилась с момента чтения (compare-and-swap по товару). Так два заказа на
+// последнюю единицу не могут одновременно уменьшить один и тот же остаток.
+//
+// ReservationRepository хранит указатели на резервы: переходы статуса
+// сериализует сервис склада.
+
+// Проверка реализации интерфейсов домена
+var (
+	_ product.Repository            = (*ProductRepository)(nil)
+	_ product.ReservationRepository = (*ReservationRepository)(nil)
+)
+
+// ProductRepository хранилище товаров в памяти
+type ProductRepository struct {
+	mu       sync.RWMutex
+	products map[uuid.UUID]product.Snapshot
+	bySKU    map[string]uuid.UUID
+}
+
+// NewProductRepository создает пустое хранилище товаров
+func NewProductRepository() *ProductRepository {
+	return &ProductRepository{
+		products: make(map[uuid.UUID]product.Snapshot),
+		bySKU:    make(map[string]uuid.UUID),
+	}
+}
+
+// Save сохраняет товар с проверкой версии
+func (r *ProductRepository) Save(ctx context.Context, p *product.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.products[p.ID()]
+	switch {
+	case exists && stored.Version != p.Version():
+		return fmt.Errorf("product %s (version %d, stored %d): %w",
+			p.SKU(), p.Version(), stored.Version, product.ErrStockVersionConflict)
+	case !exists && p.Version() != 0:
+		return fmt.Errorf("product %s: %w", p.ID(), product.ErrProductNotFound)
+	}
+	if id, taken := r.bySKU[p.SKU()]; taken && id != p.ID() {
+		return fmt.Errorf("product SKU %s already exists", p.SKU())
+	}
+
+	snapshot := p.Snapshot()
+	snapshot.Version++
+	r.products[p.ID()] = snapshot
+	r.bySKU[p.SKU()] = p.ID()
+	p.MarkSaved()
+	return nil
+}
+
+// GetByID получает копию товара по ID
+func (r *ProductRepository) GetByID(ctx context.Context, id uuid.UUID) (*product.Product, error) {
+	r.mu.RLock()
+	snapshot, ok := r.products[id]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("product %s: %w", id, product.ErrProductNotFound)
+	}
+	return product.NewProductFromSnapshot(snapshot)
+}
+
+// GetBySKU получает копию товара по артикулу
+func (r *ProductRepository) GetBySKU(ctx context.Context, sku string) (*product.Product, error) {
+	r.mu.RLock()
+	id, ok := r.bySKU[sku]
+	r.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("product %s: %w", sku, product.ErrProductNotFound)
+	}
+	return r.GetByID(ctx, id)
+}
+
+// ReservationRepository хранилище резервов в памяти
+type ReservationRepository struct {
+	mu           sync.RWMutex
+	reservations map[uuid.UUID]*product.Reservation
+}
+
+// NewReservationRepository создает пустое хранилище резервов
+func NewReservationRepository() *ReservationRepository {
+	return &ReservationRepository{
+		reservations: make(map[uuid.UUID]*product.Reservation),
+	}
+}
+
+// Save сохраняет резерв
+func (r *ReservationRepository) Save(ctx context.Context, reservation *product.Reservation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reservations[reservation.ID()] = reservation
+	return nil
+}
+
+// GetByID получает резерв по ID
+func (r *ReservationRepository) GetByID(ctx context.Context, id uuid.UUID) (*product.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	reservation, ok := r.reservations[id]
+	if !ok {
+		return nil, fmt.Errorf("reservation %s: %w", id, product.ErrReservationNotFound)
+	}
+	return reservation, nil
+}
+
+// GetByOrderID получает самый ранний резерв заказа, который держит остаток
+func (r *ReservationRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) (*product.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var found *product.Reservation
+	for _, reservation := range r.reservations {
+		if reservation.OrderID() != orderID || !reservation.HoldsStock() {
+			continue
+		}
+		if found == nil || reservation.CreatedAt().Before(found.CreatedAt()) {
+			found = reservation
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("reservation of order %s: %w", orderID, product.ErrReservationNotFound)
+	}
+	return found, nil
+}
+
+// GetExpired возвращает активные резервы с истекшим TTL
+func (r *ReservationRepository) GetExpired(ctx context.Context, now time.Time) ([]*product.Reservation, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	expired := make([]*product.Reservation, 0)
+	for _, reservation := range r.reservations {
+		if reservation.IsExpired(now) {
+			expired = append(expired, reservation)
+		}
+	}
+	return expired, nil
+}
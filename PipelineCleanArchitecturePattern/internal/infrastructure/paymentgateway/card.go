@@ -0,0 +1,117 @@
+package paymentgateway
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"pipeline-clean-architecture/internal/domain/payment"
+)
+
+// 💳 АДАПТЕР КАРТОЧНОГО ПРОЦЕССИНГА
+//
+//	POST /v1/card/authorizations               - блокировка средств
+//	POST /v1/card/authorizations/{id}/capture  - списание
+//	POST /v1/card/authorizations/{id}/void     - отмена блокировки
+//	POST /v1/card/authorizations/{id}/refunds  - возврат
+//
+// Если карте нужен 3-D Secure, авторизация возвращается со статусом pending и
+// redirect_url; итог приходит вебхуком payment.authorized / payment.failed.
+
+// Проверка реализации интерфейса домена
+var _ payment.PaymentGateway = (*CardGateway)(nil)
+
+// CardGateway адаптер карточного процессинга
+type CardGateway struct {
+	client   *apiClient
+	provider payment.Provider
+}
+
+// NewCardGateway создает адаптер; провайдер по умолчанию - Сбербанк
+func NewCardGateway(config Config) *CardGateway {
+	provider := config.Provider
+	if provider == "" {
+		provider = payment.ProviderSberbank
+	}
+	return &CardGateway{client: newAPIClient(config), provider: provider}
+}
+
+// cardAuthorizeRequest тело запроса авторизации
+type cardAuthorizeRequest struct {
+	amountRequest
+	PaymentID   string `json:"payment_id"`
+	OrderID     string `json:"order_id"`
+	CardToken   string `json:"card_token"`
+	ReturnURL   string `json:"return_url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// cardAuthorization ответ процессинга
+type cardAuthorization struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	DeclineReason string `json:"decline_reason,omitempty"`
+	Card          struct {
+		Last4 string `json:"last4"`
+		Brand string `json:"brand"`
+	} `json:"card"`
+	Fee         int64  `json:"fee"`
+	RedirectURL string `json:"redirect_url,omitempty"`
+}
+
+// Provider возвращает провайдера процессинга
+func (g *CardGateway) Provider() payment.Provider { return g.provider }
+
+// Authorize блокирует средства на карте
+func (g *CardGateway) Authorize(ctx context.Context, req payment.AuthorizeRequest) (*payment.Authorization, error) {
+	if req.Method != payment.MethodCard {
+		return nil, fmt.Errorf("%w: card gateway cannot process %s", payment.ErrMethodNotSupported, req.Method)
+	}
+
+	var resp cardAuthorization
+	err := g.client.post(ctx, "/v1/card/authorizations", req.IdempotencyKey, cardAuthorizeRequest{
+		amountRequest: newAmountRequest(req.Amount),
+		PaymentID:     req.PaymentID.String(),
+		OrderID:       req.OrderID.String(),
+		CardToken:     req.CardToken,
+		ReturnURL:     req.ReturnURL,
+		Description:   req.Description,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment.Authorization{
+		ExternalID:    resp.ID,
+		Status:        payment.AuthorizationStatus(resp.Status),
+		DeclineReason: resp.DeclineReason,
+		CardLast4:     resp.Card.Last4,
+		CardBrand:     resp.Card.Brand,
+		Fee:           payment.NewMoney(resp.Fee, req.Amount.Currency()),
+		RedirectURL:   resp.RedirectURL,
+	}, nil
+}
+
+// Capture списывает заблокированную сумму
+func (g *CardGateway) Capture(ctx context.Context, externalID string, amount payment.Money, idempotencyKey string) error {
+	return g.client.post(ctx, g.path(externalID, "capture"), idempotencyKey, newAmountRequest(amount), nil)
+}
+
+// Void снимает блокировку
+func (g *CardGateway) Void(ctx context.Context, externalID string, idempotencyKey string) error {
+	return g.client.post(ctx, g.path(externalID, "void"), idempotencyKey, struct{}{}, nil)
+}
+
+// Refund возвращает деньги на карту
+func (g *CardGateway) Refund(ctx context.Context, externalID string, amount payment.Money, idempotencyKey string) error {
+	return g.client.post(ctx, g.path(externalID, "refunds"), idempotencyKey, newAmountRequest(amount), nil)
+}
+
+// ParseWebhook проверяет подпись и разбирает уведомление процессинга
+func (g *CardGateway) ParseWebhook(payload []byte, signature string) (*payment.WebhookEvent, error) {
+	return g.client.parseWebhook(payload, signature)
+}
+
+func (g *CardGateway) path(externalID, action string) string {
+	return "/v1/card/authorizations/" + url.PathEscape(externalID) + "/" + action
+}
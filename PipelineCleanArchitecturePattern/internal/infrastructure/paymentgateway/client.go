@@ -0,0 +1,187 @@
+package paymentgateway
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/payment"
+)
+
+// 🌐 HTTP КЛИЕНТ ПЛАТЕЖНЫХ ПРОВАЙДЕРОВ
+//
+// ============================================================================
+// ОБЩИЙ ПРОТОКОЛ АДАПТЕРОВ
+// ============================================================================
+//
+// Оба адаптера (карты и СБП) ходят в REST API провайдера:
+//
+//	Authorization: Bearer <APIKey>
+//	Idempotency-Key: <payment.IdempotencyKey(...)> - повтор с тем же ключом
+//	                 возвращает сохраненный ответ, а не новую операцию
+//
+// Ошибки приходят как {"error": {"code": "...", "message": "..."}}.
+//
+// Вебхуки подписываются HMAC-SHA256 от тела запроса секретом WebhookSecret;
+// подпись в hex передается в заголовке X-Signature.
+//
+// ============================================================================
+
+// SignatureHeader заголовок с подписью вебхука
+const SignatureHeader = "X-Signature"
+
+// Config настройки подключения к провайдеру
+type Config struct {
+	BaseURL       string
+	APIKey        string
+	WebhookSecret string
+	Provider      payment.Provider // по умолчанию - провайдер адаптера
+	HTTPClient    *http.Client     // по умолчанию - клиент с таймаутом 30s
+}
+
+// apiClient общий транспорт адаптеров
+type apiClient struct {
+	baseURL       string
+	apiKey        string
+	webhookSecret string
+	http          *http.Client
+}
+
+func newAPIClient(config Config) *apiClient {
+	httpClient := config.HTTPClient
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &apiClient{
+		baseURL:       strings.TrimSuffix(config.BaseURL, "/"),
+		apiKey:        config.APIKey,
+		webhookSecret: config.WebhookSecret,
+		http:          httpClient,
+	}
+}
+
+// apiError тело ошибки провайдера
+type apiError struct {
+	Error struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// post отправляет JSON запрос и разбирает ответ в out (если out != nil)
+func (c *apiClient) post(ctx context.Context, path, idempotencyKey string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("POST %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("POST %s: failed to read response: %w", path, err)
+	}
+
+	if resp.StatusCode >= 300 {
+		var apiErr apiError
+		_ = json.Unmarshal(data, &apiErr)
+		switch {
+		case resp.StatusCode == http.StatusConflict && apiErr.Error.Code == "idempotency_conflict":
+			return fmt.Errorf("POST %s: %w", path, payment.ErrIdempotencyConflict)
+		case resp.StatusCode == http.StatusNotFound:
+			return fmt.Errorf("POST %s: %w", path, payment.ErrPaymentNotFound)
+		default:
+			return fmt.Errorf("POST %s: provider returned %d %s: %s",
+				path, resp.StatusCode, apiErr.Error.Code, apiErr.Error.Message)
+		}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("POST %s: failed to decode response: %w", path, err)
+	}
+	return nil
+}
+
+// 📨 ВЕБХУКИ
+
+// webhookPayload тело уведомления провайдера
+type webhookPayload struct {
+	ID        string    `json:"id"`
+	Type      string    `json:"type"`
+	ObjectID  string    `json:"object_id"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Sign подписывает тело вебхука секретом провайдера
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// parseWebhook проверяет подпись и разбирает уведомление
+func (c *apiClient) parseWebhook(payload []byte, signature string) (*payment.WebhookEvent, error) {
+	if c.webhookSecret == "" || !hmac.Equal([]byte(Sign(c.webhookSecret, payload)), []byte(strings.ToLower(signature))) {
+		return nil, payment.ErrInvalidSignature
+	}
+
+	var body webhookPayload
+	if err := json.Unmarshal(payload, &body); err != nil {
+		return nil, fmt.Errorf("invalid webhook payload: %w", err)
+	}
+
+	eventType := payment.WebhookEventType(body.Type)
+	switch eventType {
+	case payment.WebhookAuthorized, payment.WebhookSucceeded, payment.WebhookFailed:
+	default:
+		return nil, fmt.Errorf("unknown webhook event type %q", body.Type)
+	}
+	if body.ObjectID == "" {
+		return nil, errors.New("webhook payload has no object_id")
+	}
+
+	return &payment.WebhookEvent{
+		ID:         body.ID,
+		Type:       eventType,
+		ExternalID: body.ObjectID,
+		Reason:     body.Reason,
+		OccurredAt: body.CreatedAt,
+	}, nil
+}
+
+// amountRequest сумма операции в минимальных единицах валюты
+type amountRequest struct {
+	Amount   int64  `json:"amount"`
+	Currency string `json:"currency"`
+}
+
+func newAmountRequest(amount payment.Money) amountRequest {
+	return amountRequest{Amount: amount.Amount(), Currency: amount.Currency()}
+}
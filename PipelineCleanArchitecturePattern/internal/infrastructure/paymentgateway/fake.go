@@ -0,0 +1,354 @@
+package paymentgateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🧪 ЛОКАЛЬНЫЙ ФЕЙКОВЫЙ ПРОВАЙДЕР
+//
+// ============================================================================
+// HTTP СЕРВЕР С ПРОТОКОЛОМ CardGateway И SBPGateway
+// ============================================================================
+//
+// Для тестов и локального запуска без реального процессинга:
+//
+//	server := paymentgateway.NewFakeServer("whsec")
+//	defer server.Close()
+//	cards := paymentgateway.NewCardGateway(paymentgateway.Config{BaseURL: server.URL(), ...})
+//
+// Поведение карт задается токеном:
+//
+//	пустой       - 400 invalid_request
+//	tok_declined - отказ (insufficient_funds)
+//	tok_3ds      - нужен 3-D Secure: pending, итог через Complete/Reject
+//	любой другой - авторизация одобрена, комиссия 2%
+//
+// QR-коды СБП всегда pending до Complete (покупатель оплатил) или Reject.
+// Complete/Reject отправляют подписанный вебхук на SetWebhookURL.
+//
+// Запросы с уже виденным Idempotency-Key возвращают сохраненный успешный ответ
+// и не выполняют операцию повторно; тот же ключ с другим телом - 409.
+// Ответы с ошибкой не сохраняются: исправленный запрос можно повторить.
+//
+// ============================================================================
+
+// FakePayment состояние операции на стороне фейкового провайдера
+type FakePayment struct {
+	ID       string
+	Kind     string // card | sbp
+	OrderID  string
+	Amount   int64
+	Currency string
+	Status   string // authorized | pending | captured | voided | declined | failed | refunded
+	Captured int64
+	Refunded int64
+}
+
+// FakeServer фейковый платежный провайдер
+type FakeServer struct {
+	server *httptest.Server
+	secret string
+
+	mu         sync.Mutex
+	payments   map[string]*FakePayment
+	responses  map[string]fakeResponse // по Idempotency-Key
+	calls      map[string]int          // выполненные операции (без повторов по ключу)
+	webhookURL string
+	sequence   int
+}
+
+// fakeResponse сохраненный ответ для повторов с тем же ключом
+type fakeResponse struct {
+	fingerprint string
+	status      int
+	body        []byte
+}
+
+// NewFakeServer запускает фейковый провайдер на локальном порту
+func NewFakeServer(webhookSecret string) *FakeServer {
+	f := &FakeServer{
+		secret:    webhookSecret,
+		payments:  make(map[string]*FakePayment),
+		responses: make(map[string]fakeResponse),
+		calls:     make(map[string]int),
+	}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serveHTTP))
+	return f
+}
+
+// URL базовый адрес API
+func (f *FakeServer) URL() string { return f.server.URL }
+
+// Close останавливает сервер
+func (f *FakeServer) Close() { f.server.Close() }
+
+// SetWebhookURL задает адрес, куда отправляются вебхуки
+func (f *FakeServer) SetWebhookURL(url string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.webhookURL = url
+}
+
+// Payment возвращает копию состояния операции
+func (f *FakeServer) Payment(externalID string) (FakePayment, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	p, ok := f.payments[externalID]
+	if !ok {
+		return FakePayment{}, false
+	}
+	return *p, true
+}
+
+// Calls число выполненных операций: authorize, capture, void, refund
+func (f *FakeServer) Calls(operation string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[operation]
+}
+
+// Complete имитирует действие покупателя: 3-D Secure пройден / QR оплачен
+func (f *FakeServer) Complete(ctx context.Context, externalID string) error {
+	f.mu.Lock()
+	p, ok := f.payments[externalID]
+	if !ok || p.Status != "pending" {
+		f.mu.Unlock()
+		return fmt.Errorf("fake payment %s is not pending", externalID)
+	}
+	eventType := "payment.authorized"
+	if p.Kind == "sbp" {
+		p.Status, p.Captured = "captured", p.Amount
+		eventType = "payment.succeeded"
+	} else {
+		p.Status = "authorized"
+	}
+	f.mu.Unlock()
+
+	return f.SendWebhook(ctx, eventType, externalID, "")
+}
+
+// Reject имитирует отказ: покупатель не прошел 3-D Secure / отменил перевод
+func (f *FakeServer) Reject(ctx context.Context, externalID, reason string) error {
+	f.mu.Lock()
+	p, ok := f.payments[externalID]
+	if !ok || p.Status != "pending" {
+		f.mu.Unlock()
+		return fmt.Errorf("fake payment %s is not pending", externalID)
+	}
+	p.Status = "failed"
+	f.mu.Unlock()
+
+	return f.SendWebhook(ctx, "payment.failed", externalID, reason)
+}
+
+// SendWebhook отправляет подписанное уведомление (в том числе повторное)
+func (f *FakeServer) SendWebhook(ctx context.Context, eventType, externalID, reason string) error {
+	f.mu.Lock()
+	f.sequence++
+	body, err := json.Marshal(webhookPayload{
+		ID:        fmt.Sprintf("evt_%d", f.sequence),
+		Type:      eventType,
+		ObjectID:  externalID,
+		Reason:    reason,
+		CreatedAt: time.Now().UTC(),
+	})
+	webhookURL := f.webhookURL
+	f.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if webhookURL == "" {
+		return fmt.Errorf("webhook URL is not set")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(SignatureHeader, Sign(f.secret, body))
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook %s rejected with %d", eventType, resp.StatusCode)
+	}
+	return nil
+}
+
+// 🔀 ОБРАБОТКА ЗАПРОСОВ
+
+func (f *FakeServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeFakeError(w, http.StatusMethodNotAllowed, "method_not_allowed", r.Method)
+		return
+	}
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+		writeFakeError(w, http.StatusUnauthorized, "unauthorized", "missing API key")
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeFakeError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	key := r.Header.Get("Idempotency-Key")
+	fingerprint := r.URL.Path + "\n" + string(body)
+	if stored, ok := f.responses[key]; ok && key != "" {
+		if stored.fingerprint != fingerprint {
+			writeFakeError(w, http.StatusConflict, "idempotency_conflict", "key was used for another request")
+			return
+		}
+		writeFakeResponse(w, stored.status, stored.body)
+		return
+	}
+
+	status, response := f.handle(r.URL.Path, body)
+	data, _ := json.Marshal(response)
+	if key != "" && status < 300 {
+		f.responses[key] = fakeResponse{fingerprint: fingerprint, status: status, body: data}
+	}
+	writeFakeResponse(w, status, data)
+}
+
+// handle выполняет операцию; вызывается под f.mu
+func (f *FakeServer) handle(path string, body []byte) (int, interface{}) {
+	var req struct {
+		Amount    int64  `json:"amount"`
+		Currency  string `json:"currency"`
+		OrderID   string `json:"order_id"`
+		CardToken string `json:"card_token"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return fakeError(http.StatusBadRequest, "invalid_request", err.Error())
+	}
+
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case path == "/v1/card/authorizations":
+		return f.authorizeCard(req.OrderID, req.Amount, req.Currency, req.CardToken)
+	case path == "/v1/sbp/qrc":
+		return f.createQR(req.OrderID, req.Amount, req.Currency)
+	case len(parts) == 5 && (parts[1] == "card" || parts[1] == "sbp"):
+		p, ok := f.payments[parts[3]]
+		if !ok || p.Kind != parts[1] {
+			return fakeError(http.StatusNotFound, "not_found", parts[3])
+		}
+		return f.operate(p, parts[4], req.Amount)
+	}
+	return fakeError(http.StatusNotFound, "not_found", path)
+}
+
+func (f *FakeServer) authorizeCard(orderID string, amount int64, currency, token string) (int, interface{}) {
+	if amount <= 0 {
+		return fakeError(http.StatusBadRequest, "invalid_amount", "amount must be positive")
+	}
+	if token == "" {
+		return fakeError(http.StatusBadRequest, "invalid_request", "card_token is required")
+	}
+	f.calls["authorize"]++
+	p := f.newPayment("card", orderID, amount, currency)
+
+	resp := map[string]interface{}{"id": p.ID}
+	switch token {
+	case "tok_declined":
+		p.Status = "declined"
+		resp["decline_reason"] = "insufficient_funds"
+	case "tok_3ds":
+		p.Status = "pending"
+		resp["redirect_url"] = f.server.URL + "/3ds/" + p.ID
+	default:
+		p.Status = "authorized"
+		resp["card"] = map[string]string{"last4": "4242", "brand": "visa"}
+		resp["fee"] = amount * 2 / 100
+	}
+	resp["status"] = p.Status
+	return http.StatusOK, resp
+}
+
+func (f *FakeServer) createQR(orderID string, amount int64, currency string) (int, interface{}) {
+	if amount <= 0 {
+		return fakeError(http.StatusBadRequest, "invalid_amount", "amount must be positive")
+	}
+	f.calls["authorize"]++
+	p := f.newPayment("sbp", orderID, amount, currency)
+	p.Status = "pending"
+
+	return http.StatusOK, map[string]interface{}{
+		"id":         p.ID,
+		"status":     p.Status,
+		"qr_payload": "https://qr.nspk.ru/" + p.ID,
+		"expires_at": time.Now().Add(15 * time.Minute).UTC(),
+	}
+}
+
+func (f *FakeServer) operate(p *FakePayment, action string, amount int64) (int, interface{}) {
+	switch {
+	case action == "capture" && p.Kind == "card" && p.Status == "authorized":
+		if amount > p.Amount {
+			return fakeError(http.StatusBadRequest, "invalid_amount", "capture exceeds authorized amount")
+		}
+		p.Status, p.Captured = "captured", amount
+		f.calls["capture"]++
+	case (action == "void" || action == "cancel") && (p.Status == "authorized" || p.Status == "pending"):
+		p.Status = "voided"
+		f.calls["void"]++
+	case action == "refunds" && (p.Status == "captured" || p.Status == "refunded"):
+		if p.Refunded+amount > p.Captured {
+			return fakeError(http.StatusBadRequest, "invalid_amount", "refund exceeds captured amount")
+		}
+		p.Status, p.Refunded = "refunded", p.Refunded+amount
+		f.calls["refund"]++
+	default:
+		return fakeError(http.StatusConflict, "invalid_state", fmt.Sprintf("cannot %s %s payment", action, p.Status))
+	}
+	return http.StatusOK, map[string]string{"id": p.ID, "status": p.Status}
+}
+
+func (f *FakeServer) newPayment(kind, orderID string, amount int64, currency string) *FakePayment {
+	f.sequence++
+	p := &FakePayment{
+		ID:       fmt.Sprintf("%s_%d", kind, f.sequence),
+		Kind:     kind,
+		OrderID:  orderID,
+		Amount:   amount,
+		Currency: currency,
+	}
+	f.payments[p.ID] = p
+	return p
+}
+
+func fakeError(status int, code, message string) (int, interface{}) {
+	body := apiError{}
+	body.Error.Code, body.Error.Message = code, message
+	return status, body
+}
+
+func writeFakeError(w http.ResponseWriter, status int, code, message string) {
+	_, body := fakeError(status, code, message)
+	data, _ := json.Marshal(body)
+	writeFakeResponse(w, status, data)
+}
+
+func writeFakeResponse(w http.ResponseWriter, status int, body []byte) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
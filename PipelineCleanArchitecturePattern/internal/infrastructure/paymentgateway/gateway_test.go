@@ -0,0 +1,143 @@
+package paymentgateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/payment"
+
+	"github.com/google/uuid"
+)
+
+func newGateways(t *testing.T) (*FakeServer, *CardGateway, *SBPGateway) {
+	t.Helper()
+
+	server := NewFakeServer("whsec_test")
+	t.Cleanup(server.Close)
+
+	config := Config{BaseURL: server.URL(), APIKey: "sk_test", WebhookSecret: "whsec_test"}
+	return server, NewCardGateway(config), NewSBPGateway(config)
+}
+
+// captureWebhooks принимает вебхуки и запоминает последний
+func captureWebhooks(t *testing.T, body *[]byte, signature *string) string {
+	t.Helper()
+
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*body, _ = io.ReadAll(r.Body)
+		*signature = r.Header.Get(SignatureHeader)
+	}))
+	t.Cleanup(receiver.Close)
+	return receiver.URL
+}
+
+func authorizeRequest(method payment.Method, token string) payment.AuthorizeRequest {
+	orderID := uuid.New()
+	return payment.AuthorizeRequest{
+		PaymentID:      uuid.New(),
+		OrderID:        orderID,
+		CustomerID:     uuid.New(),
+		Amount:         payment.NewMoney(100000, "RUB"),
+		Method:         method,
+		CardToken:      token,
+		IdempotencyKey: payment.IdempotencyKey(orderID, "authorize", 0),
+	}
+}
+
+func TestCardAuthorizeAndCapture(t *testing.T) {
+	server, cards, _ := newGateways(t)
+	ctx := context.Background()
+	req := authorizeRequest(payment.MethodCard, "tok_visa")
+
+	authorization, err := cards.Authorize(ctx, req)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if authorization.Status != payment.AuthorizationApproved || authorization.CardLast4 != "4242" || authorization.Fee.Amount() != 2000 {
+		t.Errorf("authorization = %+v, want approved visa 4242 with 2%% fee", authorization)
+	}
+
+	// Повтор с тем же ключом возвращает ту же авторизацию, а не новую
+	again, err := cards.Authorize(ctx, req)
+	if err != nil {
+		t.Fatalf("Authorize() retry error = %v", err)
+	}
+	if again.ExternalID != authorization.ExternalID || server.Calls("authorize") != 1 {
+		t.Errorf("retry created %s (%d calls), want replay of %s", again.ExternalID, server.Calls("authorize"), authorization.ExternalID)
+	}
+
+	captureKey := payment.IdempotencyKey(req.OrderID, "capture", 0)
+	for i := 0; i < 2; i++ {
+		if err := cards.Capture(ctx, authorization.ExternalID, req.Amount, captureKey); err != nil {
+			t.Fatalf("Capture() #%d error = %v", i+1, err)
+		}
+	}
+	state, _ := server.Payment(authorization.ExternalID)
+	if state.Status != "captured" || state.Captured != 100000 || server.Calls("capture") != 1 {
+		t.Errorf("provider state = %+v after %d captures, want one capture of 100000", state, server.Calls("capture"))
+	}
+}
+
+func TestCardDeclineAndIdempotencyConflict(t *testing.T) {
+	_, cards, _ := newGateways(t)
+	ctx := context.Background()
+	req := authorizeRequest(payment.MethodCard, "tok_declined")
+
+	authorization, err := cards.Authorize(ctx, req)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if authorization.Status != payment.AuthorizationDeclined || authorization.DeclineReason != "insufficient_funds" {
+		t.Errorf("authorization = %+v, want declined insufficient_funds", authorization)
+	}
+
+	req.Amount = payment.NewMoney(50000, "RUB")
+	if _, err := cards.Authorize(ctx, req); !errors.Is(err, payment.ErrIdempotencyConflict) {
+		t.Errorf("same key, other amount: error = %v, want ErrIdempotencyConflict", err)
+	}
+
+	if _, err := cards.Authorize(ctx, authorizeRequest(payment.MethodSBP, "")); !errors.Is(err, payment.ErrMethodNotSupported) {
+		t.Errorf("SBP via card gateway: error = %v, want ErrMethodNotSupported", err)
+	}
+}
+
+func TestSBPQRCodeIsSettledByWebhook(t *testing.T) {
+	server, _, sbp := newGateways(t)
+	ctx := context.Background()
+	req := authorizeRequest(payment.MethodSBP, "")
+
+	authorization, err := sbp.Authorize(ctx, req)
+	if err != nil {
+		t.Fatalf("Authorize() error = %v", err)
+	}
+	if authorization.Status != payment.AuthorizationPending || authorization.QRPayload == "" || authorization.ExpiresAt == nil {
+		t.Errorf("authorization = %+v, want pending with QR payload and expiry", authorization)
+	}
+	if err := sbp.Capture(ctx, authorization.ExternalID, req.Amount, "key"); !errors.Is(err, payment.ErrCaptureNotSupported) {
+		t.Errorf("Capture() error = %v, want ErrCaptureNotSupported", err)
+	}
+
+	var received []byte
+	var signature string
+	server.SetWebhookURL(captureWebhooks(t, &received, &signature))
+
+	if err := server.Complete(ctx, authorization.ExternalID); err != nil {
+		t.Fatalf("Complete() error = %v", err)
+	}
+
+	event, err := sbp.ParseWebhook(received, signature)
+	if err != nil {
+		t.Fatalf("ParseWebhook() error = %v", err)
+	}
+	if event.Type != payment.WebhookSucceeded || event.ExternalID != authorization.ExternalID {
+		t.Errorf("event = %+v, want payment.succeeded for %s", event, authorization.ExternalID)
+	}
+
+	if _, err := sbp.ParseWebhook(received, Sign("other_secret", received)); !errors.Is(err, payment.ErrInvalidSignature) {
+		t.Errorf("foreign signature: error = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,112 @@
+package paymentgateway
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/payment"
+)
+
+// 📱 АДАПТЕР СБП (ОПЛАТА ПО QR)
+//
+//	POST /v1/sbp/qrc               - динамический QR-код на сумму заказа
+//	POST /v1/sbp/qrc/{id}/cancel   - отмена неоплаченного QR-кода
+//	POST /v1/sbp/qrc/{id}/refunds  - возврат
+//
+// СБП одностадийная: отдельного списания нет, деньги переводятся в момент,
+// когда покупатель подтверждает оплату в банковском приложении. Authorize
+// всегда возвращает pending с qr_payload, итог приходит вебхуком
+// payment.succeeded / payment.failed; Capture возвращает ErrCaptureNotSupported.
+
+// Проверка реализации интерфейса домена
+var _ payment.PaymentGateway = (*SBPGateway)(nil)
+
+// SBPGateway адаптер Системы быстрых платежей
+type SBPGateway struct {
+	client   *apiClient
+	provider payment.Provider
+}
+
+// NewSBPGateway создает адаптер СБП
+func NewSBPGateway(config Config) *SBPGateway {
+	provider := config.Provider
+	if provider == "" {
+		provider = payment.ProviderSBP
+	}
+	return &SBPGateway{client: newAPIClient(config), provider: provider}
+}
+
+// sbpQRRequest тело запроса QR-кода
+type sbpQRRequest struct {
+	amountRequest
+	PaymentID   string `json:"payment_id"`
+	OrderID     string `json:"order_id"`
+	ReturnURL   string `json:"return_url,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// sbpQR ответ с QR-кодом
+type sbpQR struct {
+	ID        string    `json:"id"`
+	Status    string    `json:"status"`
+	Payload   string    `json:"qr_payload"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Provider возвращает провайдера СБП
+func (g *SBPGateway) Provider() payment.Provider { return g.provider }
+
+// Authorize выпускает QR-код; оплату покупатель подтверждает в приложении банка
+func (g *SBPGateway) Authorize(ctx context.Context, req payment.AuthorizeRequest) (*payment.Authorization, error) {
+	if req.Method != payment.MethodSBP {
+		return nil, fmt.Errorf("%w: SBP gateway cannot process %s", payment.ErrMethodNotSupported, req.Method)
+	}
+
+	var resp sbpQR
+	err := g.client.post(ctx, "/v1/sbp/qrc", req.IdempotencyKey, sbpQRRequest{
+		amountRequest: newAmountRequest(req.Amount),
+		PaymentID:     req.PaymentID.String(),
+		OrderID:       req.OrderID.String(),
+		ReturnURL:     req.ReturnURL,
+		Description:   req.Description,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization := &payment.Authorization{
+		ExternalID: resp.ID,
+		Status:     payment.AuthorizationStatus(resp.Status),
+		QRPayload:  resp.Payload,
+	}
+	if !resp.ExpiresAt.IsZero() {
+		authorization.ExpiresAt = &resp.ExpiresAt
+	}
+	return authorization, nil
+}
+
+// Capture не поддерживается: СБП списывает деньги сразу
+func (g *SBPGateway) Capture(ctx context.Context, externalID string, amount payment.Money, idempotencyKey string) error {
+	return payment.ErrCaptureNotSupported
+}
+
+// Void отменяет неоплаченный QR-код
+func (g *SBPGateway) Void(ctx context.Context, externalID string, idempotencyKey string) error {
+	return g.client.post(ctx, g.path(externalID, "cancel"), idempotencyKey, struct{}{}, nil)
+}
+
+// Refund возвращает перевод покупателю
+func (g *SBPGateway) Refund(ctx context.Context, externalID string, amount payment.Money, idempotencyKey string) error {
+	return g.client.post(ctx, g.path(externalID, "refunds"), idempotencyKey, newAmountRequest(amount), nil)
+}
+
+// ParseWebhook проверяет подпись и разбирает уведомление СБП
+func (g *SBPGateway) ParseWebhook(payload []byte, signature string) (*payment.WebhookEvent, error) {
+	return g.client.parseWebhook(payload, signature)
+}
+
+func (g *SBPGateway) path(externalID, action string) string {
+	return "/v1/sbp/qrc/" + url.PathEscape(externalID) + "/" + action
+}
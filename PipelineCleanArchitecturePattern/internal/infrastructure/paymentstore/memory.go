@@ -0,0 +1,93 @@
+package paymentstore
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"pipeline-clean-architecture/internal/domain/payment"
+
+	"github.com/google/uuid"
+)
+
+// 💾 IN-MEMORY ХРАНИЛИЩЕ ПЛАТЕЖЕЙ
+//
+// Реализует payment.Repository для тестов и демо. Хранит указатели на
+// сущности: изменения платежа сериализует вызывающий код (процессор платежей
+// делает это под своей блокировкой).
+
+// Проверка реализации интерфейса домена
+var _ payment.Repository = (*MemoryRepository)(nil)
+
+// MemoryRepository хранилище платежей в памяти
+type MemoryRepository struct {
+	mu         sync.RWMutex
+	payments   map[uuid.UUID]*payment.Payment
+	byExternal map[string]uuid.UUID      // provider/externalID → ID
+	byOrder    map[uuid.UUID][]uuid.UUID // попытки оплаты в порядке создания
+}
+
+// NewMemoryRepository создает пустое хранилище
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		payments:   make(map[uuid.UUID]*payment.Payment),
+		byExternal: make(map[string]uuid.UUID),
+		byOrder:    make(map[uuid.UUID][]uuid.UUID),
+	}
+}
+
+// Save сохраняет платеж и обновляет индексы
+func (r *MemoryRepository) Save(ctx context.Context, p *payment.Payment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.payments[p.ID()]; !exists {
+		r.byOrder[p.OrderID()] = append(r.byOrder[p.OrderID()], p.ID())
+	}
+	r.payments[p.ID()] = p
+	if p.ExternalID() != "" {
+		r.byExternal[externalKey(p.Provider(), p.ExternalID())] = p.ID()
+	}
+	return nil
+}
+
+// GetByID получает платеж по ID
+func (r *MemoryRepository) GetByID(ctx context.Context, id uuid.UUID) (*payment.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.payments[id]
+	if !ok {
+		return nil, fmt.Errorf("payment %s: %w", id, payment.ErrPaymentNotFound)
+	}
+	return p, nil
+}
+
+// GetByExternalID получает платеж по ID у провайдера
+func (r *MemoryRepository) GetByExternalID(ctx context.Context, provider payment.Provider, externalID string) (*payment.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	id, ok := r.byExternal[externalKey(provider, externalID)]
+	if !ok {
+		return nil, fmt.Errorf("payment %s/%s: %w", provider, externalID, payment.ErrPaymentNotFound)
+	}
+	return r.payments[id], nil
+}
+
+// GetByOrderID получает все попытки оплаты заказа
+func (r *MemoryRepository) GetByOrderID(ctx context.Context, orderID uuid.UUID) ([]*payment.Payment, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ids := r.byOrder[orderID]
+	payments := make([]*payment.Payment, 0, len(ids))
+	for _, id := range ids {
+		payments = append(payments, r.payments[id])
+	}
+	return payments, nil
+}
+
+func externalKey(provider payment.Provider, externalID string) string {
+	return string(provider) + "/" + externalID
+}
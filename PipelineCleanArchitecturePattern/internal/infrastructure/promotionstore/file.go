@@ -0,0 +1,159 @@
+package promotionstore
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/promotion"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// 🏷️ ПРАВИЛА СКИДОК ИЗ ФАЙЛА
+//
+// ============================================================================
+// ФОРМАТ ФАЙЛА (YAML или JSON):
+// ============================================================================
+//
+//	promotions:
+//	  - id: autumn-electronics
+//	    name: Осенняя распродажа электроники
+//	    type: category_sale
+//	    category: Electronics
+//	    percent: 15
+//	    priority: 10
+//	    stackable: true
+//	    ends_at: 2026-11-30T23:59:59Z
+//	  - id: welcome
+//	    name: Скидка на первый заказ
+//	    type: fixed
+//	    coupon: WELCOME
+//	    amount: 50000          # копейки: 500 ₽
+//	    currency: RUB
+//	    max_uses_per_customer: 1
+//
+// Все правила проверяются при загрузке (promotion.Rule.Validate), ошибка
+// указывает на правило. Пример - configs/promotions/promotions.yaml.
+//
+// ============================================================================
+
+// Проверка реализации интерфейса домена
+var _ promotion.RuleSource = (*FileSource)(nil)
+
+// FileSource неизменяемый набор правил, загруженный из файла
+type FileSource struct {
+	rules []promotion.Rule
+}
+
+// ruleFile содержимое файла правил
+type ruleFile struct {
+	Promotions []ruleDocument `yaml:"promotions"`
+}
+
+type ruleDocument struct {
+	ID                 string         `yaml:"id"`
+	Name               string         `yaml:"name"`
+	Type               string         `yaml:"type"`
+	Coupon             string         `yaml:"coupon"`
+	Priority           int            `yaml:"priority"`
+	Stackable          bool           `yaml:"stackable"`
+	MaxUsesPerCustomer int            `yaml:"max_uses_per_customer"`
+	StartsAt           time.Time      `yaml:"starts_at"`
+	EndsAt             time.Time      `yaml:"ends_at"`
+	Currency           string         `yaml:"currency"`
+	Percent            float64        `yaml:"percent"`
+	Amount             int64          `yaml:"amount"`
+	MinSubtotal        int64          `yaml:"min_subtotal"`
+	ProductIDs         []uuid.UUID    `yaml:"product_ids"`
+	Category           string         `yaml:"category"`
+	Buy                int            `yaml:"buy"`
+	Get                int            `yaml:"get"`
+	Tiers              []tierDocument `yaml:"tiers"`
+}
+
+type tierDocument struct {
+	Threshold int64   `yaml:"threshold"`
+	Percent   float64 `yaml:"percent"`
+	Amount    int64   `yaml:"amount"`
+}
+
+// NewFileSource создает источник из готовых правил
+func NewFileSource(rules []promotion.Rule) (*FileSource, error) {
+	seen := make(map[string]bool, len(rules))
+	coupons := make(map[string]string)
+	for i := range rules {
+		if err := rules[i].Validate(); err != nil {
+			return nil, err
+		}
+		if seen[rules[i].ID] {
+			return nil, fmt.Errorf("%w: duplicate id %s", promotion.ErrInvalidRule, rules[i].ID)
+		}
+		seen[rules[i].ID] = true
+
+		if coupon := rules[i].Coupon; coupon != "" {
+			for other, ruleID := range coupons {
+				if rules[i].MatchesCoupon(other) {
+					return nil, fmt.Errorf("%w: coupon %s is used by %s and %s",
+						promotion.ErrInvalidRule, coupon, ruleID, rules[i].ID)
+				}
+			}
+			coupons[coupon] = rules[i].ID
+		}
+	}
+	return &FileSource{rules: append([]promotion.Rule(nil), rules...)}, nil
+}
+
+// LoadFile читает правила из YAML или JSON файла
+func LoadFile(path string) (*FileSource, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read promotions: %w", err)
+	}
+
+	// JSON - подмножество YAML, поэтому один парсер читает оба формата
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid promotions file %s: %w", path, err)
+	}
+
+	rules := make([]promotion.Rule, 0, len(file.Promotions))
+	for _, doc := range file.Promotions {
+		rule := promotion.Rule{
+			ID:                 doc.ID,
+			Name:               doc.Name,
+			Type:               promotion.Type(doc.Type),
+			Coupon:             doc.Coupon,
+			Priority:           doc.Priority,
+			Stackable:          doc.Stackable,
+			MaxUsesPerCustomer: doc.MaxUsesPerCustomer,
+			StartsAt:           doc.StartsAt,
+			EndsAt:             doc.EndsAt,
+			Currency:           doc.Currency,
+			Percent:            doc.Percent,
+			Amount:             doc.Amount,
+			MinSubtotal:        doc.MinSubtotal,
+			ProductIDs:         doc.ProductIDs,
+			Category:           doc.Category,
+			Buy:                doc.Buy,
+			Get:                doc.Get,
+		}
+		for _, tier := range doc.Tiers {
+			rule.Tiers = append(rule.Tiers, promotion.Tier(tier))
+		}
+		rules = append(rules, rule)
+	}
+
+	source, err := NewFileSource(rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid promotions file %s: %w", path, err)
+	}
+	return source, nil
+}
+
+// Rules возвращает копию правил
+func (s *FileSource) Rules(ctx context.Context) ([]promotion.Rule, error) {
+	return append([]promotion.Rule(nil), s.rules...), nil
+}
@@ -0,0 +1,57 @@
+package promotionstore
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/promotion"
+)
+
+func TestLoadFileExample(t *testing.T) {
+	source, err := LoadFile(filepath.Join("..", "..", "..", "configs", "promotions", "promotions.yaml"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	rules, err := source.Rules(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	byID := make(map[string]promotion.Rule)
+	for _, rule := range rules {
+		byID[rule.ID] = rule
+	}
+
+	tiers := byID["cart-tiers"]
+	if len(tiers.Tiers) != 2 || tiers.Tiers[1].Percent != 5 || tiers.Tiers[0].Amount != 30000 {
+		t.Errorf("cart-tiers = %+v", tiers)
+	}
+	if welcome := byID["welcome"]; welcome.Coupon != "WELCOME" || welcome.MaxUsesPerCustomer != 1 {
+		t.Errorf("welcome = %+v", welcome)
+	}
+	if autumn := byID["autumn-electronics"]; autumn.EndsAt.IsZero() || autumn.Type != promotion.TypeCategorySale {
+		t.Errorf("autumn-electronics = %+v", autumn)
+	}
+}
+
+func TestLoadFileRejectsInvalidRules(t *testing.T) {
+	tests := map[string]string{
+		"unknown type": `{"promotions": [{"id": "a", "name": "A", "type": "magic"}]}`,
+		"duplicate coupon": `
+promotions:
+  - {id: a, name: A, type: fixed, amount: 100, coupon: SALE}
+  - {id: b, name: B, type: fixed, amount: 200, coupon: sale}`,
+	}
+	for name, data := range tests {
+		path := filepath.Join(t.TempDir(), "promotions.yaml")
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); !errors.Is(err, promotion.ErrInvalidRule) {
+			t.Errorf("%s: LoadFile() error = %v, want ErrInvalidRule", name, err)
+		}
+	}
+}
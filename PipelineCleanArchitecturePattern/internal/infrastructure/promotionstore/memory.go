@@ -0,0 +1,96 @@
+package promotionstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/promotion"
+
+	"github.com/google/uuid"
+)
+
+// Проверка реализации интерфейса домена
+var _ promotion.UsageRepository = (*MemoryUsageRepository)(nil)
+
+// MemoryUsageRepository учет использований правил в памяти.
+//
+// Как и sqlstore.PromotionUsageRepository, не считает отмененные, возвращенные
+// и удаленные заказы: их статус читается из хранилища заказов, поэтому
+// Order.Cancel освобождает лимит и без вызова ReleasePromotions.
+type MemoryUsageRepository struct {
+	mu     sync.RWMutex
+	orders map[uuid.UUID]usage // заказ → покупатель и примененные правила
+	store  order.Repository    // nil - статус заказов не проверяется
+}
+
+type usage struct {
+	customerID uuid.UUID
+	ruleIDs    []string
+}
+
+// NewMemoryUsageRepository создает пустое хранилище использований; статус
+// заказов проверяется через orders
+func NewMemoryUsageRepository(orders order.Repository) *MemoryUsageRepository {
+	return &MemoryUsageRepository{orders: make(map[uuid.UUID]usage), store: orders}
+}
+
+// CountUses считает действующие заказы покупателя с правилом, кроме exceptOrderID
+func (r *MemoryUsageRepository) CountUses(ctx context.Context, ruleID string, customerID, exceptOrderID uuid.UUID) (int, error) {
+	// ШАГ 1: Заказы покупателя с правилом
+	r.mu.RLock()
+	var orderIDs []uuid.UUID
+	for orderID, u := range r.orders {
+		if orderID == exceptOrderID || u.customerID != customerID {
+			continue
+		}
+		for _, id := range u.ruleIDs {
+			if id == ruleID {
+				orderIDs = append(orderIDs, orderID)
+				break
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	if r.store == nil {
+		return len(orderIDs), nil
+	}
+
+	// ШАГ 2: Отбрасываем отмененные, возвращенные и удаленные заказы
+	count := 0
+	for _, orderID := range orderIDs {
+		ord, err := r.store.GetByID(ctx, orderID)
+		if errors.Is(err, order.ErrOrderNotFound) {
+			continue
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to get order %s: %w", orderID, err)
+		}
+		if isReleased(ord.Status()) {
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
+
+// ReplaceUses заменяет правила, примененные к заказу
+func (r *MemoryUsageRepository) ReplaceUses(ctx context.Context, orderID, customerID uuid.UUID, ruleIDs []string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(ruleIDs) == 0 {
+		delete(r.orders, orderID)
+		return nil
+	}
+	r.orders[orderID] = usage{customerID: customerID, ruleIDs: append([]string(nil), ruleIDs...)}
+	return nil
+}
+
+// isReleased заказ в этом статусе лимит не расходует (как releasedStatuses в sqlstore)
+func isReleased(status order.Status) bool {
+	return status == order.StatusCancelled || status == order.StatusRefunded
+}
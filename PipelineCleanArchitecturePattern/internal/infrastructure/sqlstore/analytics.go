@@ -0,0 +1,295 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+// 📊 АНАЛИТИЧЕСКИЕ ЗАПРОСЫ
+//
+// ============================================================================
+// ВЫРУЧКА:
+// ============================================================================
+//
+// Выручку дают заказы в статусах от paid до delivered: отмененные,
+// возвращенные и еще не оплаченные не учитываются. Таблица хранит текущее
+// состояние без времени оплаты, поэтому период [from, to) считается по
+// created_at (проекция eventstore.AnalyticsProjection - по времени оплаты).
+//
+// Агрегаты считает база: SUM/COUNT с GROUP BY, тренды - по началу периода
+// (неделя начинается с понедельника, время в UTC). Суммы разных валют
+// не складываются: такие выборки возвращают order.ErrMixedCurrencies.
+//
+// ============================================================================
+
+// revenueStatuses статусы оплаченных заказов
+var revenueStatuses = []interface{}{
+	int(order.StatusPaid),
+	int(order.StatusInventoryChecked),
+	int(order.StatusFulfillment),
+	int(order.StatusShipped),
+	int(order.StatusDelivered),
+}
+
+// GetOrdersInDateRange получает заказы, созданные в [from, to)
+func (r *OrderRepository) GetOrdersInDateRange(ctx context.Context, from, to time.Time) ([]*order.Order, error) {
+	w := newWhere()
+	w.add("o.created_at >= ?", dbTime(from))
+	w.add("o.created_at < ?", dbTime(to))
+	return r.findOrders(ctx, w, "", 0, 0)
+}
+
+// CountByStatus возвращает количество заказов по статусам
+func (r *OrderRepository) CountByStatus(ctx context.Context) (map[order.Status]int, error) {
+	w := newWhere()
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT o.status, COUNT(*) FROM orders o WHERE `+w.sql()+` GROUP BY o.status`, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count orders by status: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[order.Status]int)
+	for rows.Next() {
+		var status, count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, err
+		}
+		counts[order.Status(status)] = count
+	}
+	return counts, rows.Err()
+}
+
+// GetTotalRevenueByPeriod возвращает выручку оплаченных заказов за [from, to)
+func (r *OrderRepository) GetTotalRevenueByPeriod(ctx context.Context, from, to time.Time) (order.Money, error) {
+	total, _, err := r.revenueTotals(ctx, from, to)
+	return total, err
+}
+
+// GetAverageOrderValue вычисляет средний чек оплаченных заказов за [from, to)
+func (r *OrderRepository) GetAverageOrderValue(ctx context.Context, from, to time.Time) (order.Money, error) {
+	total, count, err := r.revenueTotals(ctx, from, to)
+	if err != nil || count == 0 {
+		return total, err
+	}
+	return order.NewMoney(total.Amount()/int64(count), total.Currency()), nil
+}
+
+// GetTopCustomersByRevenue получает клиентов с наибольшей выручкой
+func (r *OrderRepository) GetTopCustomersByRevenue(ctx context.Context, limit int, from, to time.Time) ([]order.CustomerRevenue, error) {
+	w := revenueWhere(from, to)
+	currency, err := r.revenueCurrency(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT o.customer_id, SUM(o.total_amount) AS revenue, COUNT(*) AS orders_count
+		FROM orders o WHERE %s
+		GROUP BY o.customer_id
+		ORDER BY revenue DESC, o.customer_id ASC%s`, w.sql(), r.limitClause(w, limit, 0))
+
+	rows, err := r.db.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query top customers: %w", err)
+	}
+	defer rows.Close()
+
+	var result []order.CustomerRevenue
+	for rows.Next() {
+		var (
+			customerID uuid.UUID
+			revenue    int64
+			count      int
+		)
+		if err := rows.Scan(&customerID, &revenue, &count); err != nil {
+			return nil, err
+		}
+		result = append(result, order.CustomerRevenue{
+			CustomerID:   customerID,
+			TotalRevenue: order.NewMoney(revenue, currency),
+			OrdersCount:  count,
+			AverageOrder: order.NewMoney(revenue/int64(count), currency),
+		})
+	}
+	return result, rows.Err()
+}
+
+// GetPopularProducts получает товары с наибольшим количеством продаж
+func (r *OrderRepository) GetPopularProducts(ctx context.Context, limit int, from, to time.Time) ([]order.ProductSales, error) {
+	w := revenueWhere(from, to)
+	currency, err := r.revenueCurrency(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT i.product_id,
+			SUM(i.quantity) AS total_quantity,
+			SUM(i.unit_price * i.quantity - i.discount) AS revenue,
+			COUNT(DISTINCT i.order_id) AS orders_count
+		FROM order_items i JOIN orders o ON o.id = i.order_id
+		WHERE %s
+		GROUP BY i.product_id
+		ORDER BY total_quantity DESC, revenue DESC, i.product_id ASC%s`, w.sql(), r.limitClause(w, limit, 0))
+
+	rows, err := r.db.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query popular products: %w", err)
+	}
+	defer rows.Close()
+
+	var result []order.ProductSales
+	for rows.Next() {
+		var (
+			productID       uuid.UUID
+			quantity, count int
+			revenue         int64
+		)
+		if err := rows.Scan(&productID, &quantity, &revenue, &count); err != nil {
+			return nil, err
+		}
+		result = append(result, order.ProductSales{
+			ProductID:     productID,
+			TotalQuantity: quantity,
+			TotalRevenue:  order.NewMoney(revenue, currency),
+			OrdersCount:   count,
+		})
+	}
+	return result, rows.Err()
+}
+
+// GetOrderTrends получает количество и выручку оплаченных заказов по периодам
+func (r *OrderRepository) GetOrderTrends(ctx context.Context, from, to time.Time, groupBy order.TrendGroupBy) ([]order.OrderTrend, error) {
+	period, err := r.periodExpr(groupBy)
+	if err != nil {
+		return nil, err
+	}
+
+	w := revenueWhere(from, to)
+	currency, err := r.revenueCurrency(ctx, w)
+	if err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf(`SELECT period, COUNT(*), SUM(total_amount)
+		FROM (SELECT %s AS period, o.total_amount FROM orders o WHERE %s) periods
+		GROUP BY period
+		ORDER BY period`, period, w.sql())
+
+	rows, err := r.db.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query order trends: %w", err)
+	}
+	defer rows.Close()
+
+	var result []order.OrderTrend
+	for rows.Next() {
+		var (
+			start   string
+			count   int
+			revenue int64
+		)
+		if err := rows.Scan(&start, &count, &revenue); err != nil {
+			return nil, err
+		}
+
+		periodStart, err := time.Parse("2006-01-02", start)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected period %q: %w", start, err)
+		}
+		result = append(result, order.OrderTrend{
+			Period:       periodStart,
+			OrdersCount:  count,
+			TotalRevenue: order.NewMoney(revenue, currency),
+			AverageOrder: order.NewMoney(revenue/int64(count), currency),
+		})
+	}
+	return result, rows.Err()
+}
+
+// 🔧 ВСПОМОГАТЕЛЬНЫЕ ФУНКЦИИ
+
+// revenueWhere условие для оплаченных заказов, созданных в [from, to)
+func revenueWhere(from, to time.Time) *where {
+	w := newWhere()
+	w.add("o.status IN ("+placeholders(len(revenueStatuses))+")", revenueStatuses...)
+	w.add("o.created_at >= ?", dbTime(from))
+	w.add("o.created_at < ?", dbTime(to))
+	return w
+}
+
+// revenueCurrency возвращает единственную валюту выборки ("" для пустой)
+func (r *OrderRepository) revenueCurrency(ctx context.Context, w *where) (string, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT DISTINCT o.currency FROM orders o WHERE `+w.sql(), w.args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to query order currencies: %w", err)
+	}
+	defer rows.Close()
+
+	var currencies []string
+	for rows.Next() {
+		var currency string
+		if err := rows.Scan(&currency); err != nil {
+			return "", err
+		}
+		currencies = append(currencies, currency)
+	}
+	if err := rows.Err(); err != nil {
+		return "", err
+	}
+
+	switch len(currencies) {
+	case 0:
+		return "", nil
+	case 1:
+		return currencies[0], nil
+	default:
+		return "", fmt.Errorf("%w: %v", order.ErrMixedCurrencies, currencies)
+	}
+}
+
+// revenueTotals возвращает сумму и количество оплаченных заказов
+func (r *OrderRepository) revenueTotals(ctx context.Context, from, to time.Time) (order.Money, int, error) {
+	w := revenueWhere(from, to)
+	currency, err := r.revenueCurrency(ctx, w)
+	if err != nil {
+		return order.Money{}, 0, err
+	}
+
+	var (
+		total int64
+		count int
+	)
+	err = r.db.QueryRowContext(ctx,
+		`SELECT COALESCE(SUM(o.total_amount), 0), COUNT(*) FROM orders o WHERE `+w.sql(), w.args...).
+		Scan(&total, &count)
+	if err != nil {
+		return order.Money{}, 0, fmt.Errorf("failed to sum revenue: %w", err)
+	}
+	return order.NewMoney(total, currency), count, nil
+}
+
+// periodExpr выражение начала периода в формате YYYY-MM-DD
+func (r *OrderRepository) periodExpr(groupBy order.TrendGroupBy) (string, error) {
+	switch groupBy {
+	case order.TrendGroupByDay, order.TrendGroupByWeek, order.TrendGroupByMonth, order.TrendGroupByYear:
+	default:
+		return "", fmt.Errorf("unsupported trend grouping %q", groupBy)
+	}
+
+	if r.dialect == DialectPostgres {
+		// groupBy проверен выше и совпадает с единицами date_trunc
+		return fmt.Sprintf(`to_char(date_trunc('%s', o.created_at AT TIME ZONE 'UTC'), 'YYYY-MM-DD')`, groupBy), nil
+	}
+
+	return map[order.TrendGroupBy]string{
+		order.TrendGroupByDay:   `date(o.created_at)`,
+		order.TrendGroupByWeek:  `date(o.created_at, 'weekday 0', '-6 days')`,
+		order.TrendGroupByMonth: `strftime('%Y-%m-01', o.created_at)`,
+		order.TrendGroupByYear:  `strftime('%Y-01-01', o.created_at)`,
+	}[groupBy], nil
+}
@@ -0,0 +1,145 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+func TestOrderRepositoryAnalytics(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		day := func(d, h int) time.Time { return time.Date(2024, time.March, d, h, 0, 0, 0, time.UTC) }
+
+		alice, bob := uuid.New(), uuid.New()
+		laptop, mouse := uuid.New(), uuid.New()
+
+		orders := []*order.Order{
+			// Alice: оплаченные заказы 4 и 5 марта (понедельник и вторник)
+			makeOrder(t, orderSpec{customerID: alice, createdAt: day(4, 9), status: order.StatusPaid,
+				items: []order.OrderItem{lineItem(laptop, 1, 100000, 0, "RUB")}}),
+			makeOrder(t, orderSpec{customerID: alice, createdAt: day(5, 9), status: order.StatusDelivered,
+				items: []order.OrderItem{lineItem(mouse, 2, 5000, 1000, "RUB"), lineItem(mouse, 1, 5000, 0, "RUB")}}),
+			// Bob: оплачен 10 марта (воскресенье той же недели) и 11 марта (следующая неделя)
+			makeOrder(t, orderSpec{customerID: bob, createdAt: day(10, 23), status: order.StatusShipped,
+				items: []order.OrderItem{lineItem(mouse, 1, 6000, 0, "RUB")}}),
+			makeOrder(t, orderSpec{customerID: bob, createdAt: day(11, 9), status: order.StatusInventoryChecked,
+				items: []order.OrderItem{lineItem(mouse, 4, 5000, 0, "RUB")}}),
+			// Не дают выручку: возвращен, отменен, не оплачен
+			makeOrder(t, orderSpec{customerID: bob, createdAt: day(6, 9), status: order.StatusRefunded,
+				items: []order.OrderItem{lineItem(laptop, 3, 100000, 0, "RUB")}}),
+			makeOrder(t, orderSpec{customerID: bob, createdAt: day(7, 9), status: order.StatusCancelled}),
+			makeOrder(t, orderSpec{customerID: bob, createdAt: day(8, 9), status: order.StatusPending}),
+		}
+		if err := repo.SaveBatch(ctx, orders); err != nil {
+			t.Fatal(err)
+		}
+
+		from, to := day(1, 0), day(31, 0)
+
+		revenue, err := repo.GetTotalRevenueByPeriod(ctx, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if revenue != order.NewMoney(100000+14000+6000+20000, "RUB") {
+			t.Errorf("GetTotalRevenueByPeriod() = %s, want 1400.00 RUB", revenue)
+		}
+
+		average, err := repo.GetAverageOrderValue(ctx, day(5, 0), day(12, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if average.Amount() != (14000+6000+20000)/3 {
+			t.Errorf("GetAverageOrderValue() = %s, want 133.33 RUB", average)
+		}
+
+		top, err := repo.GetTopCustomersByRevenue(ctx, 1, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(top) != 1 || top[0].CustomerID != alice || top[0].TotalRevenue.Amount() != 114000 ||
+			top[0].OrdersCount != 2 || top[0].AverageOrder.Amount() != 57000 {
+			t.Errorf("GetTopCustomersByRevenue() = %+v, want alice with 114000 over 2 orders", top)
+		}
+
+		products, err := repo.GetPopularProducts(ctx, 0, from, to)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(products) != 2 {
+			t.Fatalf("GetPopularProducts() = %+v, want 2 products", products)
+		}
+		if p := products[0]; p.ProductID != mouse || p.TotalQuantity != 8 || p.OrdersCount != 3 || p.TotalRevenue.Amount() != 40000 {
+			t.Errorf("top product = %+v, want mouse x8 in 3 orders for 40000", p)
+		}
+		if p := products[1]; p.ProductID != laptop || p.TotalQuantity != 1 {
+			t.Errorf("second product = %+v, want laptop x1", p)
+		}
+
+		trends, err := repo.GetOrderTrends(ctx, from, to, order.TrendGroupByWeek)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(trends) != 2 {
+			t.Fatalf("weekly trends = %+v, want 2 weeks", trends)
+		}
+		if !trends[0].Period.Equal(day(4, 0)) || trends[0].OrdersCount != 3 || trends[0].TotalRevenue.Amount() != 120000 {
+			t.Errorf("first week = %+v, want week of March 4 with 3 orders for 120000", trends[0])
+		}
+		if !trends[1].Period.Equal(day(11, 0)) || trends[1].OrdersCount != 1 || trends[1].AverageOrder.Amount() != 20000 {
+			t.Errorf("second week = %+v, want week of March 11 with 1 order", trends[1])
+		}
+
+		monthly, err := repo.GetOrderTrends(ctx, from, to, order.TrendGroupByMonth)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(monthly) != 1 || !monthly[0].Period.Equal(day(1, 0)) || monthly[0].OrdersCount != 4 {
+			t.Errorf("monthly trends = %+v, want March with 4 orders", monthly)
+		}
+
+		if _, err := repo.GetOrderTrends(ctx, from, to, "quarter"); err == nil {
+			t.Error("GetOrderTrends(quarter) error = nil")
+		}
+
+		counts, err := repo.CountByStatus(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if counts[order.StatusPaid] != 1 || counts[order.StatusCancelled] != 1 || counts[order.StatusPending] != 1 {
+			t.Errorf("CountByStatus() = %v", counts)
+		}
+
+		inRange, err := repo.GetOrdersInDateRange(ctx, day(6, 0), day(8, 0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(inRange) != 2 {
+			t.Errorf("GetOrdersInDateRange() returned %d orders, want 2", len(inRange))
+		}
+	})
+}
+
+func TestOrderRepositoryAnalyticsRejectsMixedCurrencies(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		at := time.Date(2024, time.March, 4, 9, 0, 0, 0, time.UTC)
+
+		orders := []*order.Order{
+			makeOrder(t, orderSpec{createdAt: at, status: order.StatusPaid}),
+			makeOrder(t, orderSpec{createdAt: at, status: order.StatusPaid, currency: "USD"}),
+		}
+		if err := repo.SaveBatch(ctx, orders); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := repo.GetTotalRevenueByPeriod(ctx, at, at.Add(time.Hour)); !errors.Is(err, order.ErrMixedCurrencies) {
+			t.Errorf("GetTotalRevenueByPeriod() error = %v, want ErrMixedCurrencies", err)
+		}
+	})
+}
@@ -0,0 +1,151 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 🧱 МИГРАЦИИ СХЕМЫ
+//
+// ============================================================================
+// ФОРМАТ:
+// ============================================================================
+//
+// migrations/<диалект>/<версия>_<название>.sql, например
+// migrations/postgres/0001_create_orders.sql. Версии применяются по
+// возрастанию, каждая в своей транзакции вместе с записью в
+// schema_migrations, поэтому упавшая миграция не оставляет половину схемы,
+// а повторный Migrate применяет только новые версии.
+//
+// Новая миграция добавляется в оба каталога с одинаковой версией.
+//
+// ============================================================================
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+// Dialect диалект SQL базы данных
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// migration одна версия схемы
+type migration struct {
+	version int
+	name    string
+	sql     string
+}
+
+// Migrate применяет недостающие миграции схемы заказов
+func (r *OrderRepository) Migrate(ctx context.Context) error {
+	timeType := "TIMESTAMPTZ"
+	if r.dialect == DialectSQLite {
+		timeType = "TIMESTAMP"
+	}
+
+	_, err := r.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT NOT NULL,
+		applied_at %s NOT NULL
+	)`, timeType))
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	migrations, err := loadMigrations(r.dialect)
+	if err != nil {
+		return err
+	}
+
+	applied, err := r.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if applied[m.version] {
+			continue
+		}
+
+		err := r.withTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, m.sql); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				`INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)`,
+				m.version, m.name, dbTime(time.Now()))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %04d_%s: %w", m.version, m.name, err)
+		}
+	}
+
+	return nil
+}
+
+// appliedMigrations возвращает версии, уже записанные в schema_migrations
+func (r *OrderRepository) appliedMigrations(ctx context.Context) (map[int]bool, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]bool)
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+	return applied, rows.Err()
+}
+
+// loadMigrations читает миграции диалекта, отсортированные по версии
+func loadMigrations(dialect Dialect) ([]migration, error) {
+	dir := path.Join("migrations", string(dialect))
+	entries, err := migrationFiles.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for dialect %s: %w", dialect, err)
+	}
+
+	var migrations []migration
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), ".sql")
+		if entry.IsDir() || name == entry.Name() {
+			continue
+		}
+
+		prefix, title, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil {
+			return nil, fmt.Errorf("migration %s: name must look like 0001_title.sql", entry.Name())
+		}
+
+		data, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migrations = append(migrations, migration{version: version, name: title, sql: string(data)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].version < migrations[j].version })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].version == migrations[i-1].version {
+			return nil, fmt.Errorf("duplicate migration version %04d in %s", migrations[i].version, dir)
+		}
+	}
+	return migrations, nil
+}
@@ -0,0 +1,43 @@
+-- Заказы: текущее состояние агрегата order.Order
+CREATE TABLE orders (
+    id                   UUID PRIMARY KEY,
+    customer_id          UUID NOT NULL,
+    status               INTEGER NOT NULL,
+    priority             INTEGER NOT NULL,
+    source               TEXT NOT NULL,
+    currency             TEXT NOT NULL,
+    total_amount         BIGINT NOT NULL,
+    notes                TEXT NOT NULL DEFAULT '',
+    shipping_street      TEXT NOT NULL,
+    shipping_city        TEXT NOT NULL,
+    shipping_postal_code TEXT NOT NULL,
+    shipping_country     TEXT NOT NULL,
+    shipping_phone       TEXT NOT NULL DEFAULT '',
+    billing_street       TEXT NOT NULL,
+    billing_city         TEXT NOT NULL,
+    billing_postal_code  TEXT NOT NULL,
+    billing_country      TEXT NOT NULL,
+    billing_phone        TEXT NOT NULL DEFAULT '',
+    version              INTEGER NOT NULL,
+    created_at           TIMESTAMPTZ NOT NULL,
+    updated_at           TIMESTAMPTZ NOT NULL,
+    deleted_at           TIMESTAMPTZ
+);
+
+CREATE INDEX idx_orders_customer ON orders (customer_id);
+CREATE INDEX idx_orders_status_priority ON orders (status, priority);
+CREATE INDEX idx_orders_created_at ON orders (created_at);
+
+-- Позиции заказа в порядке добавления
+CREATE TABLE order_items (
+    order_id   UUID NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    position   INTEGER NOT NULL,
+    product_id UUID NOT NULL,
+    quantity   INTEGER NOT NULL CHECK (quantity > 0),
+    unit_price BIGINT NOT NULL,
+    discount   BIGINT NOT NULL DEFAULT 0,
+    currency   TEXT NOT NULL,
+    PRIMARY KEY (order_id, position)
+);
+
+CREATE INDEX idx_order_items_product ON order_items (product_id);
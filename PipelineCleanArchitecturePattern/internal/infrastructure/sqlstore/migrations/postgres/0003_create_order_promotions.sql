@@ -0,0 +1,13 @@
+-- Правила скидок, примененные к заказу (order.AppliedPromotion), для аудита
+CREATE TABLE order_promotions (
+    order_id  UUID NOT NULL REFERENCES orders (id) ON DELETE CASCADE,
+    position  INTEGER NOT NULL,
+    rule_id   TEXT NOT NULL,
+    name      TEXT NOT NULL,
+    coupon    TEXT NOT NULL DEFAULT '',
+    discount  BIGINT NOT NULL,
+    currency  TEXT NOT NULL,
+    PRIMARY KEY (order_id, position)
+);
+
+CREATE INDEX idx_order_promotions_rule ON order_promotions (rule_id);
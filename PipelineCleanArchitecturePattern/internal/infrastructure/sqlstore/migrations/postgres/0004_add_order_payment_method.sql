@@ -0,0 +1,3 @@
+-- Способ оплаты, выбранный при оформлении (order.Order.PaymentMethod);
+-- пустая строка - способ по умолчанию шага оплаты
+ALTER TABLE orders ADD COLUMN payment_method TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,4 @@
+-- Цена каталога для позиций, пересчитанных из другой валюты
+-- (order.NewConvertedOrderItem); NULL - цена не конвертировалась
+ALTER TABLE order_items ADD COLUMN catalog_price INTEGER;
+ALTER TABLE order_items ADD COLUMN catalog_currency TEXT;
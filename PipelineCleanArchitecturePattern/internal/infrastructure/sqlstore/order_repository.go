@@ -0,0 +1,531 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+// 🗄️ SQL РЕПОЗИТОРИЙ ЗАКАЗОВ
+//
+// ============================================================================
+// РЕАЛИЗУЕТ order.Repository, order.QueryRepository, order.SearchRepository
+// ============================================================================
+//
+// Работает через database/sql, драйвер подключает вызывающий код:
+//
+//	import _ "github.com/lib/pq"           // PostgreSQL (продакшен)
+//	import _ "github.com/mattn/go-sqlite3" // SQLite (тесты и локальный запуск)
+//
+// Схема (см. migrations/):
+//
+//	orders           - одна строка на заказ: статус, адреса, сумма, версия
+//	order_items      - позиции заказа в порядке добавления
+//	order_promotions - примененные правила скидок (аудит)
+//
+// Заказ хранится как текущее состояние и восстанавливается через
+// order.NewOrderFromSnapshot. Delete - мягкое удаление (deleted_at):
+// удаленные заказы не видны ни одному запросу.
+//
+// Для SQLite ":memory:" нужен db.SetMaxOpenConns(1): каждое соединение
+// к in-memory базе - отдельная база.
+//
+// ============================================================================
+
+// Проверка реализации интерфейсов домена
+var (
+	_ order.Repository       = (*OrderRepository)(nil)
+	_ order.QueryRepository  = (*OrderRepository)(nil)
+	_ order.SearchRepository = (*OrderRepository)(nil)
+)
+
+// OrderRepository хранилище заказов в SQL базе данных
+type OrderRepository struct {
+	db      *sql.DB
+	dialect Dialect
+}
+
+// NewOrderRepository создает SQL репозиторий заказов
+func NewOrderRepository(db *sql.DB, dialect Dialect) (*OrderRepository, error) {
+	switch dialect {
+	case DialectPostgres, DialectSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported SQL dialect: %s", dialect)
+	}
+
+	return &OrderRepository{db: db, dialect: dialect}, nil
+}
+
+// querier общий интерфейс *sql.DB и *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+}
+
+// orderColumns колонки заказа в порядке scanOrder
+const orderColumns = `o.id, o.customer_id, o.status, o.priority, o.source, o.currency, o.notes, o.payment_method,
+	o.shipping_street, o.shipping_city, o.shipping_postal_code, o.shipping_country, o.shipping_phone,
+	o.billing_street, o.billing_city, o.billing_postal_code, o.billing_country, o.billing_phone,
+	o.version, o.created_at, o.updated_at`
+
+// 💾 ОСНОВНЫЕ CRUD ОПЕРАЦИИ
+
+// Save сохраняет новый заказ или обновляет существующий вместе с позициями
+//
+// Обновление оптимистичное: строка меняется, только если в базе та версия,
+// от которой построены новые события заказа. Иначе заказ уже сохранил
+// кто-то другой и возвращается order.ErrEventVersionConflict.
+func (r *OrderRepository) Save(ctx context.Context, ord *order.Order) error {
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		return saveOrder(ctx, tx, ord)
+	})
+	if err != nil {
+		return err
+	}
+
+	ord.MarkEventsCommitted()
+	return nil
+}
+
+// GetByID получает заказ по ID
+func (r *OrderRepository) GetByID(ctx context.Context, id uuid.UUID) (*order.Order, error) {
+	w := newWhere()
+	w.add("o.id = ?", id)
+
+	orders, err := r.findOrders(ctx, w, "", 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("%w: %s", order.ErrOrderNotFound, id)
+	}
+	return orders[0], nil
+}
+
+// GetByCustomerID получает все заказы клиента
+func (r *OrderRepository) GetByCustomerID(ctx context.Context, customerID uuid.UUID) ([]*order.Order, error) {
+	w := newWhere()
+	w.add("o.customer_id = ?", customerID)
+	return r.findOrders(ctx, w, "", 0, 0)
+}
+
+// Delete мягко удаляет заказ
+func (r *OrderRepository) Delete(ctx context.Context, id uuid.UUID) error {
+	now := dbTime(time.Now())
+	result, err := r.db.ExecContext(ctx,
+		`UPDATE orders SET deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted_at IS NULL`,
+		now, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete order %s: %w", id, err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: %s", order.ErrOrderNotFound, id)
+	}
+	return nil
+}
+
+// 🔍 ПОИСК И ФИЛЬТРАЦИЯ
+
+// GetByStatus получает заказы по статусу
+func (r *OrderRepository) GetByStatus(ctx context.Context, status order.Status) ([]*order.Order, error) {
+	w := newWhere()
+	w.add("o.status = ?", int(status))
+	return r.findOrders(ctx, w, "", 0, 0)
+}
+
+// GetByStatusAndPriority получает заказы по статусу и приоритету
+func (r *OrderRepository) GetByStatusAndPriority(ctx context.Context, status order.Status, priority order.Priority) ([]*order.Order, error) {
+	w := newWhere()
+	w.add("o.status = ?", int(status))
+	w.add("o.priority = ?", int(priority))
+	return r.findOrders(ctx, w, "", 0, 0)
+}
+
+// GetPendingOrders получает ожидающие обработки заказы, срочные первыми
+func (r *OrderRepository) GetPendingOrders(ctx context.Context) ([]*order.Order, error) {
+	w := newWhere()
+	w.add("o.status = ?", int(order.StatusPending))
+	return r.findOrders(ctx, w, "o.priority DESC, o.created_at ASC, o.id ASC", 0, 0)
+}
+
+// GetActiveOrders получает заказы, которые еще не завершены
+func (r *OrderRepository) GetActiveOrders(ctx context.Context) ([]*order.Order, error) {
+	finished := []interface{}{int(order.StatusDelivered), int(order.StatusCancelled), int(order.StatusRefunded)}
+
+	w := newWhere()
+	w.add("o.status NOT IN ("+placeholders(len(finished))+")", finished...)
+	return r.findOrders(ctx, w, "", 0, 0)
+}
+
+// 🔄 ПАКЕТНЫЕ ОПЕРАЦИИ
+
+// SaveBatch сохраняет несколько заказов в одной транзакции
+//
+// Конфликт версии любого заказа откатывает всю пачку.
+func (r *OrderRepository) SaveBatch(ctx context.Context, orders []*order.Order) error {
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		for _, ord := range orders {
+			if err := saveOrder(ctx, tx, ord); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, ord := range orders {
+		ord.MarkEventsCommitted()
+	}
+	return nil
+}
+
+// UpdateStatusBatch устанавливает статус нескольким заказам в одной транзакции
+//
+// Административная операция: переходы статусов агрегата не проверяются.
+// Если хотя бы одного заказа нет, не меняется ни один.
+func (r *OrderRepository) UpdateStatusBatch(ctx context.Context, orderIDs []uuid.UUID, newStatus order.Status) error {
+	unique := make(map[uuid.UUID]bool, len(orderIDs))
+	args := []interface{}{int(newStatus), dbTime(time.Now())}
+	for _, id := range orderIDs {
+		if !unique[id] {
+			unique[id] = true
+			args = append(args, id)
+		}
+	}
+	if len(unique) == 0 {
+		return nil
+	}
+
+	query := fmt.Sprintf(`UPDATE orders SET status = $1, updated_at = $2
+		WHERE deleted_at IS NULL AND id IN (%s)`, numbered(3, len(unique)))
+
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to update order statuses: %w", err)
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if int(affected) != len(unique) {
+			return fmt.Errorf("%w: %d of %d orders", order.ErrOrderNotFound, len(unique)-int(affected), len(unique))
+		}
+		return nil
+	})
+}
+
+// 🔧 ЗАПИСЬ И ЧТЕНИЕ ЗАКАЗОВ
+
+// saveOrder записывает строку заказа и заменяет его позиции
+//
+// Ожидаемая версия строки - версия заказа до несохраненных событий.
+func saveOrder(ctx context.Context, q querier, ord *order.Order) error {
+	shipping, billing := ord.ShippingAddress(), ord.BillingAddress()
+	expectedVersion := ord.Version() - len(ord.UncommittedEvents())
+
+	result, err := q.ExecContext(ctx, `
+		INSERT INTO orders (id, customer_id, status, priority, source, currency, total_amount, notes,
+			shipping_street, shipping_city, shipping_postal_code, shipping_country, shipping_phone,
+			billing_street, billing_city, billing_postal_code, billing_country, billing_phone,
+			version, created_at, updated_at, payment_method)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22)
+		ON CONFLICT (id) DO UPDATE SET
+			status               = excluded.status,
+			priority             = excluded.priority,
+			currency             = excluded.currency,
+			total_amount         = excluded.total_amount,
+			notes                = excluded.notes,
+			payment_method       = excluded.payment_method,
+			shipping_street      = excluded.shipping_street,
+			shipping_city        = excluded.shipping_city,
+			shipping_postal_code = excluded.shipping_postal_code,
+			shipping_country     = excluded.shipping_country,
+			shipping_phone       = excluded.shipping_phone,
+			billing_street       = excluded.billing_street,
+			billing_city         = excluded.billing_city,
+			billing_postal_code  = excluded.billing_postal_code,
+			billing_country      = excluded.billing_country,
+			billing_phone        = excluded.billing_phone,
+			version              = excluded.version,
+			updated_at           = excluded.updated_at
+		WHERE orders.version = $23`,
+		ord.ID(), ord.CustomerID(), int(ord.Status()), int(ord.Priority()), string(ord.Source()),
+		ord.Currency(), ord.TotalAmount().Amount(), ord.Notes(),
+		shipping.Street(), shipping.City(), shipping.PostalCode(), shipping.Country(), shipping.Phone(),
+		billing.Street(), billing.City(), billing.PostalCode(), billing.Country(), billing.Phone(),
+		ord.Version(), dbTime(ord.CreatedAt()), dbTime(ord.UpdatedAt()),
+		ord.PaymentMethod(), expectedVersion,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save order %s: %w", ord.ID(), err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: order %s was saved by someone else after version %d",
+			order.ErrEventVersionConflict, ord.ID(), expectedVersion)
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM order_items WHERE order_id = $1`, ord.ID()); err != nil {
+		return fmt.Errorf("failed to replace items of order %s: %w", ord.ID(), err)
+	}
+
+	items := ord.Items()
+	for i := range items {
+		item := &items[i]
+		var catalogPrice sql.NullInt64
+		var catalogCurrency sql.NullString
+		if item.IsConverted() {
+			catalogPrice = sql.NullInt64{Int64: item.CatalogPrice().Amount(), Valid: true}
+			catalogCurrency = sql.NullString{String: item.CatalogPrice().Currency(), Valid: true}
+		}
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO order_items (order_id, position, product_id, quantity, unit_price, discount, currency,
+				catalog_price, catalog_currency)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`,
+			ord.ID(), i, item.ProductID(), item.Quantity(),
+			item.UnitPrice().Amount(), item.Discount().Amount(), item.UnitPrice().Currency(),
+			catalogPrice, catalogCurrency,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save item %d of order %s: %w", i, ord.ID(), err)
+		}
+	}
+
+	if _, err := q.ExecContext(ctx, `DELETE FROM order_promotions WHERE order_id = $1`, ord.ID()); err != nil {
+		return fmt.Errorf("failed to replace promotions of order %s: %w", ord.ID(), err)
+	}
+
+	for i, promo := range ord.Promotions() {
+		_, err := q.ExecContext(ctx, `
+			INSERT INTO order_promotions (order_id, position, rule_id, name, coupon, discount, currency)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+			ord.ID(), i, promo.RuleID, promo.Name, promo.Coupon,
+			promo.Discount.Amount(), promo.Discount.Currency(),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to save promotion %s of order %s: %w", promo.RuleID, ord.ID(), err)
+		}
+	}
+
+	return nil
+}
+
+// findOrders загружает неудаленные заказы по условию вместе с позициями
+//
+// orderBy - доверенное выражение сортировки (по умолчанию created_at, id).
+func (r *OrderRepository) findOrders(ctx context.Context, w *where, orderBy string, limit, offset int) ([]*order.Order, error) {
+	if orderBy == "" {
+		orderBy = "o.created_at ASC, o.id ASC"
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM orders o WHERE %s ORDER BY %s%s`,
+		orderColumns, w.sql(), orderBy, r.limitClause(w, limit, offset))
+
+	rows, err := r.db.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query orders: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []*order.Snapshot
+	for rows.Next() {
+		snapshot, err := scanOrder(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan order: %w", err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, nil
+	}
+
+	if err := r.loadItems(ctx, snapshots); err != nil {
+		return nil, err
+	}
+	if err := r.loadPromotions(ctx, snapshots); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*order.Order, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		ord, err := order.NewOrderFromSnapshot(*snapshot, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore order %s: %w", snapshot.OrderID, err)
+		}
+		orders = append(orders, ord)
+	}
+	return orders, nil
+}
+
+// scanOrder читает строку orderColumns в снимок заказа (без позиций)
+func scanOrder(rows *sql.Rows) (*order.Snapshot, error) {
+	var (
+		s                    order.Snapshot
+		status, priority     int
+		source               string
+		shipping, billing    [5]string
+		createdAt, updatedAt time.Time
+	)
+
+	err := rows.Scan(&s.OrderID, &s.CustomerID, &status, &priority, &source, &s.Currency, &s.Notes, &s.PaymentMethod,
+		&shipping[0], &shipping[1], &shipping[2], &shipping[3], &shipping[4],
+		&billing[0], &billing[1], &billing[2], &billing[3], &billing[4],
+		&s.Version, &createdAt, &updatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	s.Status = order.Status(status)
+	s.Priority = order.Priority(priority)
+	s.Source = order.Source(source)
+	s.ShippingAddress = order.NewAddress(shipping[0], shipping[1], shipping[2], shipping[3], shipping[4])
+	s.BillingAddress = order.NewAddress(billing[0], billing[1], billing[2], billing[3], billing[4])
+	s.CreatedAt = createdAt.UTC()
+	s.UpdatedAt = updatedAt.UTC()
+	s.TakenAt = time.Now()
+	return &s, nil
+}
+
+// loadItems загружает позиции для снимков одним запросом
+func (r *OrderRepository) loadItems(ctx context.Context, snapshots []*order.Snapshot) error {
+	byID := make(map[uuid.UUID]*order.Snapshot, len(snapshots))
+	args := make([]interface{}, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		byID[snapshot.OrderID] = snapshot
+		args = append(args, snapshot.OrderID)
+	}
+
+	query := fmt.Sprintf(`SELECT order_id, product_id, quantity, unit_price, discount, currency,
+			catalog_price, catalog_currency
+		FROM order_items WHERE order_id IN (%s) ORDER BY order_id, position`, numbered(1, len(args)))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query order items: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			orderID, productID  uuid.UUID
+			quantity            int
+			unitPrice, discount int64
+			currency            string
+			catalogPrice        sql.NullInt64
+			catalogCurrency     sql.NullString
+		)
+		if err := rows.Scan(&orderID, &productID, &quantity, &unitPrice, &discount, &currency,
+			&catalogPrice, &catalogCurrency); err != nil {
+			return fmt.Errorf("failed to scan order item: %w", err)
+		}
+
+		item := order.RestoreOrderItem(productID, quantity,
+			order.NewMoney(unitPrice, currency), order.NewMoney(discount, currency))
+		if catalogPrice.Valid && catalogCurrency.Valid {
+			item = order.RestoreConvertedOrderItem(productID, quantity,
+				order.NewMoney(unitPrice, currency), order.NewMoney(discount, currency),
+				order.NewMoney(catalogPrice.Int64, catalogCurrency.String))
+		}
+
+		snapshot := byID[orderID]
+		snapshot.Items = append(snapshot.Items, item)
+	}
+	return rows.Err()
+}
+
+// loadPromotions загружает примененные правила скидок для снимков
+func (r *OrderRepository) loadPromotions(ctx context.Context, snapshots []*order.Snapshot) error {
+	byID := make(map[uuid.UUID]*order.Snapshot, len(snapshots))
+	args := make([]interface{}, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		byID[snapshot.OrderID] = snapshot
+		args = append(args, snapshot.OrderID)
+	}
+
+	query := fmt.Sprintf(`SELECT order_id, rule_id, name, coupon, discount, currency
+		FROM order_promotions WHERE order_id IN (%s) ORDER BY order_id, position`, numbered(1, len(args)))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query order promotions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			orderID  uuid.UUID
+			promo    order.AppliedPromotion
+			discount int64
+			currency string
+		)
+		if err := rows.Scan(&orderID, &promo.RuleID, &promo.Name, &promo.Coupon, &discount, &currency); err != nil {
+			return fmt.Errorf("failed to scan order promotion: %w", err)
+		}
+		promo.Discount = order.NewMoney(discount, currency)
+
+		snapshot := byID[orderID]
+		snapshot.Promotions = append(snapshot.Promotions, promo)
+	}
+	return rows.Err()
+}
+
+// limitClause добавляет LIMIT/OFFSET с параметрами в w
+func (r *OrderRepository) limitClause(w *where, limit, offset int) string {
+	var clause strings.Builder
+	switch {
+	case limit > 0:
+		clause.WriteString(" LIMIT " + w.param(limit))
+	case offset > 0 && r.dialect == DialectSQLite:
+		// SQLite не поддерживает OFFSET без LIMIT
+		clause.WriteString(" LIMIT -1")
+	}
+	if offset > 0 {
+		clause.WriteString(" OFFSET " + w.param(offset))
+	}
+	return clause.String()
+}
+
+// withTx выполняет fn в транзакции: commit при успехе, rollback при ошибке
+func (r *OrderRepository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(); rollbackErr != nil && !errors.Is(rollbackErr, sql.ErrTxDone) {
+			return errors.Join(err, fmt.Errorf("rollback failed: %w", rollbackErr))
+		}
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// dbTime приводит время к точности и зоне, одинаковым в PostgreSQL и SQLite
+func dbTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Microsecond)
+}
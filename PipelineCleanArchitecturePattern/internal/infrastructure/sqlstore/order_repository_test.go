@@ -0,0 +1,419 @@
+package sqlstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/money"
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+	_ "github.com/lib/pq"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// postgresDSNEnv переменная окружения с DSN тестовой базы PostgreSQL
+const postgresDSNEnv = "ORDERS_POSTGRES_DSN"
+
+func newSQLiteRepository(t *testing.T) *OrderRepository {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Каждое соединение к :memory: - отдельная база
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	return migratedRepository(t, db, DialectSQLite)
+}
+
+func newPostgresRepository(t *testing.T) *OrderRepository {
+	t.Helper()
+
+	dsn := os.Getenv(postgresDSNEnv)
+	if dsn == "" {
+		t.Skipf("%s is not set", postgresDSNEnv)
+	}
+
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repo := migratedRepository(t, db, DialectPostgres)
+	if _, err := db.Exec(`TRUNCATE order_promotions, order_items, orders`); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+func migratedRepository(t *testing.T, db *sql.DB, dialect Dialect) *OrderRepository {
+	t.Helper()
+
+	repo, err := NewOrderRepository(db, dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Migrate(context.Background()); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	return repo
+}
+
+// forEachDialect запускает тест на SQLite и, если задан DSN, на PostgreSQL
+func forEachDialect(t *testing.T, test func(t *testing.T, repo *OrderRepository)) {
+	t.Run("sqlite", func(t *testing.T) { test(t, newSQLiteRepository(t)) })
+	t.Run("postgres", func(t *testing.T) { test(t, newPostgresRepository(t)) })
+}
+
+// orderSpec описание заказа для восстановления с заданными временем и статусом
+type orderSpec struct {
+	customerID uuid.UUID
+	status     order.Status
+	priority   order.Priority
+	createdAt  time.Time
+	city       string
+	currency   string
+	items      []order.OrderItem
+}
+
+func makeOrder(t *testing.T, spec orderSpec) *order.Order {
+	t.Helper()
+
+	if spec.customerID == uuid.Nil {
+		spec.customerID = uuid.New()
+	}
+	if spec.createdAt.IsZero() {
+		spec.createdAt = time.Now()
+	}
+	if spec.city == "" {
+		spec.city = "Москва"
+	}
+	if spec.currency == "" {
+		spec.currency = "RUB"
+	}
+	if len(spec.items) == 0 {
+		spec.items = []order.OrderItem{lineItem(uuid.New(), 1, 10000, 0, spec.currency)}
+	}
+
+	address := order.NewAddress("Тверская 1", spec.city, "101000", "RU", "+7")
+	ord, err := order.NewOrderFromSnapshot(order.Snapshot{
+		OrderID:         uuid.New(),
+		Version:         1,
+		CustomerID:      spec.customerID,
+		Items:           spec.items,
+		Status:          spec.status,
+		Currency:        spec.currency,
+		CreatedAt:       spec.createdAt,
+		UpdatedAt:       spec.createdAt,
+		ShippingAddress: address,
+		BillingAddress:  address,
+		Priority:        spec.priority,
+		Source:          order.SourceWeb,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ord
+}
+
+func lineItem(productID uuid.UUID, quantity int, price, discount int64, currency string) order.OrderItem {
+	return order.RestoreOrderItem(productID, quantity, order.NewMoney(price, currency), order.NewMoney(discount, currency))
+}
+
+func ids(orders []*order.Order) []uuid.UUID {
+	result := make([]uuid.UUID, len(orders))
+	for i, ord := range orders {
+		result[i] = ord.ID()
+	}
+	return result
+}
+
+func TestOrderRepositorySaveAndLoad(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+
+		productID := uuid.New()
+		item, err := order.NewOrderItem(productID, 2, order.NewMoney(150000, "RUB"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ord, err := order.NewOrder(uuid.New(), []order.OrderItem{item}, order.NewAddress("Невский 1", "Санкт-Петербург", "191186", "RU", "+7"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := ord.AddItem(uuid.New(), 1, order.NewMoney(5000, "RUB")); err != nil {
+			t.Fatal(err)
+		}
+		if err := ord.ApplyDiscount(productID, order.NewMoney(20000, "RUB")); err != nil {
+			t.Fatal(err)
+		}
+		ord.SetNotes("позвонить за час")
+		ord.SetPriority(order.PriorityHigh)
+		ord.SetPaymentMethod("sbp")
+
+		if err := repo.Save(ctx, ord); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		// Повторное сохранение обновляет строку и заменяет позиции
+		if err := ord.MarkAsValidated(); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Save(ctx, ord); err != nil {
+			t.Fatalf("second Save() error = %v", err)
+		}
+
+		loaded, err := repo.GetByID(ctx, ord.ID())
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+
+		if loaded.Status() != order.StatusValidated || loaded.Priority() != order.PriorityHigh ||
+			loaded.Notes() != ord.Notes() || loaded.Version() != ord.Version() {
+			t.Errorf("loaded status %s priority %s notes %q version %d",
+				loaded.Status(), loaded.Priority(), loaded.Notes(), loaded.Version())
+		}
+		if loaded.PaymentMethod() != "sbp" {
+			t.Errorf("PaymentMethod() = %q, want sbp", loaded.PaymentMethod())
+		}
+		if loaded.TotalAmount() != ord.TotalAmount() {
+			t.Errorf("TotalAmount() = %s, want %s", loaded.TotalAmount(), ord.TotalAmount())
+		}
+		if len(loaded.Items()) != 2 || loaded.Items()[0].Discount().Amount() != 20000 {
+			t.Errorf("loaded items = %+v, want 2 items with discount on the first", loaded.Items())
+		}
+		if loaded.ShippingAddress() != ord.ShippingAddress() {
+			t.Errorf("ShippingAddress() = %+v, want %+v", loaded.ShippingAddress(), ord.ShippingAddress())
+		}
+		if !loaded.CreatedAt().Equal(dbTime(ord.CreatedAt())) {
+			t.Errorf("CreatedAt() = %v, want %v", loaded.CreatedAt(), ord.CreatedAt())
+		}
+	})
+}
+
+func TestOrderRepositorySaveDetectsConcurrentUpdate(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		ord := makeOrder(t, orderSpec{})
+		if err := repo.Save(ctx, ord); err != nil {
+			t.Fatal(err)
+		}
+
+		// Два обработчика загрузили одну и ту же версию заказа
+		first, err := repo.GetByID(ctx, ord.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		second, err := repo.GetByID(ctx, ord.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := first.MarkAsValidated(); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Save(ctx, first); err != nil {
+			t.Fatalf("first Save() error = %v", err)
+		}
+
+		if err := second.Cancel(); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Save(ctx, second); !errors.Is(err, order.ErrEventVersionConflict) {
+			t.Fatalf("stale Save() error = %v, want ErrEventVersionConflict", err)
+		}
+
+		// Сохраненный заказ можно менять дальше тем же объектом
+		if err := first.MarkAsPaymentProcessing(); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Save(ctx, first); err != nil {
+			t.Fatalf("Save() after commit error = %v", err)
+		}
+
+		loaded, err := repo.GetByID(ctx, ord.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if loaded.Status() != order.StatusPaymentProcessing || loaded.Version() != first.Version() {
+			t.Errorf("loaded status %s version %d, want %s version %d",
+				loaded.Status(), loaded.Version(), order.StatusPaymentProcessing, first.Version())
+		}
+	})
+}
+
+func TestOrderRepositoryKeepsCatalogPrice(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+
+		rate, err := money.NewRate("EUR", "RUB", "101.25", time.Now())
+		if err != nil {
+			t.Fatal(err)
+		}
+		item, err := order.NewConvertedOrderItem(uuid.New(), 1, order.NewMoney(1999, "EUR"), rate)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ord := makeOrder(t, orderSpec{items: []order.OrderItem{item, lineItem(uuid.New(), 1, 10000, 0, "RUB")}})
+		if err := repo.Save(ctx, ord); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		loaded, err := repo.GetByID(ctx, ord.ID())
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		items := loaded.Items()
+		if !items[0].CatalogPrice().Equal(order.NewMoney(1999, "EUR")) || items[0].UnitPrice().Amount() != 202399 {
+			t.Errorf("converted item = %s (catalog %s), want 2023.99 RUB from 19.99 EUR", items[0].UnitPrice(), items[0].CatalogPrice())
+		}
+		if items[1].IsConverted() {
+			t.Errorf("RUB item restored as converted from %s", items[1].CatalogPrice())
+		}
+	})
+}
+
+func TestOrderRepositoryKeepsPromotions(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+
+		productID := uuid.New()
+		ord := makeOrder(t, orderSpec{items: []order.OrderItem{lineItem(productID, 2, 10000, 0, "RUB")}})
+		applied := []order.AppliedPromotion{
+			{RuleID: "autumn-10", Name: "Осенняя скидка", Discount: order.NewMoney(2000, "RUB")},
+			{RuleID: "welcome", Name: "Купон новичка", Coupon: "HELLO", Discount: order.NewMoney(500, "RUB")},
+		}
+		err := ord.ApplyPromotions(map[uuid.UUID]order.Money{productID: order.NewMoney(2500, "RUB")}, applied)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Save(ctx, ord); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+
+		loaded, err := repo.GetByID(ctx, ord.ID())
+		if err != nil {
+			t.Fatalf("GetByID() error = %v", err)
+		}
+		promotions := loaded.Promotions()
+		if len(promotions) != 2 || promotions[1] != applied[1] || !promotions[0].Discount.Equal(applied[0].Discount) {
+			t.Errorf("Promotions() = %+v, want %+v", promotions, applied)
+		}
+		if loaded.TotalAmount().Amount() != 17500 {
+			t.Errorf("TotalAmount() = %s, want 175.00 RUB", loaded.TotalAmount())
+		}
+	})
+}
+
+func TestOrderRepositorySoftDelete(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		customerID := uuid.New()
+		kept, deleted := makeOrder(t, orderSpec{customerID: customerID}), makeOrder(t, orderSpec{customerID: customerID})
+
+		if err := repo.SaveBatch(ctx, []*order.Order{kept, deleted}); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(ctx, deleted.ID()); err != nil {
+			t.Fatalf("Delete() error = %v", err)
+		}
+
+		if _, err := repo.GetByID(ctx, deleted.ID()); !errors.Is(err, order.ErrOrderNotFound) {
+			t.Errorf("GetByID(deleted) error = %v, want ErrOrderNotFound", err)
+		}
+		if err := repo.Delete(ctx, deleted.ID()); !errors.Is(err, order.ErrOrderNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrOrderNotFound", err)
+		}
+
+		orders, err := repo.GetByCustomerID(ctx, customerID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(orders) != 1 || orders[0].ID() != kept.ID() {
+			t.Errorf("GetByCustomerID() = %v, want only %s", ids(orders), kept.ID())
+		}
+	})
+}
+
+func TestOrderRepositorySaveBatchIsAtomic(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		valid := makeOrder(t, orderSpec{})
+		// quantity > 0 проверяет CHECK в схеме
+		invalid := makeOrder(t, orderSpec{items: []order.OrderItem{lineItem(uuid.New(), 0, 100, 0, "RUB")}})
+
+		if err := repo.SaveBatch(ctx, []*order.Order{valid, invalid}); err == nil {
+			t.Fatal("SaveBatch() error = nil, want constraint violation")
+		}
+		if _, err := repo.GetByID(ctx, valid.ID()); !errors.Is(err, order.ErrOrderNotFound) {
+			t.Errorf("order from failed batch: error = %v, want ErrOrderNotFound", err)
+		}
+	})
+}
+
+func TestOrderRepositoryUpdateStatusBatch(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		first, second := makeOrder(t, orderSpec{}), makeOrder(t, orderSpec{})
+		if err := repo.SaveBatch(ctx, []*order.Order{first, second}); err != nil {
+			t.Fatal(err)
+		}
+
+		err := repo.UpdateStatusBatch(ctx, []uuid.UUID{first.ID(), uuid.New()}, order.StatusCancelled)
+		if !errors.Is(err, order.ErrOrderNotFound) {
+			t.Fatalf("UpdateStatusBatch() with unknown ID error = %v, want ErrOrderNotFound", err)
+		}
+		if pending, _ := repo.GetPendingOrders(ctx); len(pending) != 2 {
+			t.Fatalf("failed batch changed statuses: %d pending orders left", len(pending))
+		}
+
+		if err := repo.UpdateStatusBatch(ctx, []uuid.UUID{first.ID(), second.ID(), first.ID()}, order.StatusCancelled); err != nil {
+			t.Fatalf("UpdateStatusBatch() error = %v", err)
+		}
+
+		cancelled, err := repo.GetByStatus(ctx, order.StatusCancelled)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(cancelled) != 2 {
+			t.Errorf("GetByStatus(cancelled) = %v, want both orders", ids(cancelled))
+		}
+		if active, _ := repo.GetActiveOrders(ctx); len(active) != 0 {
+			t.Errorf("GetActiveOrders() = %v, want none", ids(active))
+		}
+	})
+}
+
+func TestOrderRepositoryMigrateIsIdempotent(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	if err := repo.Migrate(context.Background()); err != nil {
+		t.Fatalf("second Migrate() error = %v", err)
+	}
+
+	var count int
+	if err := repo.db.QueryRow(`SELECT COUNT(*) FROM schema_migrations`).Scan(&count); err != nil {
+		t.Fatal(err)
+	}
+
+	migrations, err := loadMigrations(DialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != len(migrations) {
+		t.Errorf("schema_migrations has %d rows, want %d", count, len(migrations))
+	}
+}
+
+func TestNewOrderRepositoryRejectsUnknownDialect(t *testing.T) {
+	if _, err := NewOrderRepository(nil, "oracle"); err == nil {
+		t.Error("NewOrderRepository(oracle) error = nil")
+	}
+}
@@ -0,0 +1,70 @@
+package sqlstore
+
+import (
+	"context"
+	"fmt"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/domain/promotion"
+
+	"github.com/google/uuid"
+)
+
+// 🏷️ ИСПОЛЬЗОВАНИЯ ПРАВИЛ СКИДОК
+//
+// ============================================================================
+// РЕАЛИЗУЕТ promotion.UsageRepository ПОВЕРХ order_promotions
+// ============================================================================
+//
+// Отдельной таблицы использований нет: правила, примененные к заказу,
+// OrderRepository.Save пишет в order_promotions в одной транзакции с
+// заказом. Использование - неудаленный заказ покупателя со строкой правила.
+//
+// Отмененные и возвращенные заказы лимит не расходуют, а строки
+// order_promotions остаются для аудита. Поэтому ReplaceUses ничего не
+// пишет: к моменту вызова заказ со своими правилами уже сохранен, а
+// освобождение при отмене следует из статуса заказа.
+//
+// ============================================================================
+
+// Проверка реализации интерфейса домена
+var _ promotion.UsageRepository = (*PromotionUsageRepository)(nil)
+
+// releasedStatuses статусы заказов, не расходующих лимиты скидок
+var releasedStatuses = []interface{}{
+	int(order.StatusCancelled),
+	int(order.StatusRefunded),
+}
+
+// PromotionUsageRepository учет использований правил в SQL базе заказов
+type PromotionUsageRepository struct {
+	orders *OrderRepository
+}
+
+// NewPromotionUsageRepository создает учет использований на базе репозитория заказов
+func NewPromotionUsageRepository(orders *OrderRepository) *PromotionUsageRepository {
+	return &PromotionUsageRepository{orders: orders}
+}
+
+// CountUses считает заказы покупателя с правилом, кроме exceptOrderID
+func (r *PromotionUsageRepository) CountUses(ctx context.Context, ruleID string, customerID, exceptOrderID uuid.UUID) (int, error) {
+	w := newWhere()
+	w.add("p.rule_id = ?", ruleID)
+	w.add("o.customer_id = ?", customerID)
+	w.add("o.id <> ?", exceptOrderID)
+	w.add("o.status NOT IN ("+placeholders(len(releasedStatuses))+")", releasedStatuses...)
+
+	var count int
+	err := r.orders.db.QueryRowContext(ctx,
+		`SELECT COUNT(DISTINCT o.id) FROM order_promotions p JOIN orders o ON o.id = p.order_id WHERE `+w.sql(),
+		w.args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count uses of promotion %s: %w", ruleID, err)
+	}
+	return count, nil
+}
+
+// ReplaceUses ничего не записывает: правила заказа сохраняет OrderRepository.Save
+func (r *PromotionUsageRepository) ReplaceUses(ctx context.Context, orderID, customerID uuid.UUID, ruleIDs []string) error {
+	return nil
+}
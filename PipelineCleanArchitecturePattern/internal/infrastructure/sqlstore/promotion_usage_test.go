@@ -0,0 +1,76 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+func TestPromotionUsageRepositoryCountsActiveOrders(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		usage := NewPromotionUsageRepository(repo)
+		customerID := uuid.New()
+
+		// withCoupon сохраняет заказ покупателя со скидкой по купону welcome
+		withCoupon := func(customerID uuid.UUID) *order.Order {
+			productID := uuid.New()
+			ord := makeOrder(t, orderSpec{customerID: customerID, items: []order.OrderItem{lineItem(productID, 1, 10000, 0, "RUB")}})
+			applied := []order.AppliedPromotion{{RuleID: "welcome", Name: "Купон новичка", Coupon: "HELLO", Discount: order.NewMoney(500, "RUB")}}
+			if err := ord.ApplyPromotions(map[uuid.UUID]order.Money{productID: order.NewMoney(500, "RUB")}, applied); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.Save(ctx, ord); err != nil {
+				t.Fatal(err)
+			}
+			return ord
+		}
+		first, second, deleted := withCoupon(customerID), withCoupon(customerID), withCoupon(customerID)
+		withCoupon(uuid.New()) // Чужой заказ не считается
+		if err := repo.Delete(ctx, deleted.ID()); err != nil {
+			t.Fatal(err)
+		}
+
+		count := func(ruleID string, exceptOrderID uuid.UUID) int {
+			t.Helper()
+			uses, err := usage.CountUses(ctx, ruleID, customerID, exceptOrderID)
+			if err != nil {
+				t.Fatalf("CountUses() error = %v", err)
+			}
+			return uses
+		}
+		if got := count("welcome", uuid.Nil); got != 2 {
+			t.Errorf("CountUses(welcome) = %d, want 2", got)
+		}
+		if got := count("welcome", first.ID()); got != 1 {
+			t.Errorf("CountUses(welcome, except first) = %d, want 1", got)
+		}
+		if got := count("autumn-10", uuid.Nil); got != 0 {
+			t.Errorf("CountUses(autumn-10) = %d, want 0", got)
+		}
+
+		// Отмененный заказ освобождает лимит, но хранит скидку для аудита
+		if err := second.Cancel(); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Save(ctx, second); err != nil {
+			t.Fatal(err)
+		}
+		if err := usage.ReplaceUses(ctx, second.ID(), uuid.Nil, nil); err != nil {
+			t.Fatalf("ReplaceUses() error = %v", err)
+		}
+		if got := count("welcome", uuid.Nil); got != 1 {
+			t.Errorf("CountUses(welcome) after cancel = %d, want 1", got)
+		}
+		loaded, err := repo.GetByID(ctx, second.ID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded.Promotions()) != 1 {
+			t.Errorf("cancelled order promotions = %+v, want the applied coupon", loaded.Promotions())
+		}
+	})
+}
@@ -0,0 +1,185 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"pipeline-clean-architecture/internal/domain/order"
+)
+
+// 🔎 ПОИСК ПО КРИТЕРИЯМ
+//
+// ============================================================================
+// БЕЗОПАСНЫЙ ДИНАМИЧЕСКИЙ SQL:
+// ============================================================================
+//
+// - значения из SearchCriteria попадают в запрос только параметрами ($1, $2...)
+// - SortField и SortDirection сопоставляются с белым списком колонок и
+//   направлений; неизвестное значение - ErrInvalidCriteria, а не подстановка
+// - DateFrom/DateTo задают полуинтервал [DateFrom, DateTo) по created_at
+// - MinAmount/MaxAmount сравнивают сумму только с заказами в той же валюте
+//
+// ============================================================================
+
+// ErrInvalidCriteria критерии поиска не могут быть выполнены
+var ErrInvalidCriteria = errors.New("invalid search criteria")
+
+// sortColumns колонки, по которым разрешена сортировка
+var sortColumns = map[order.SortField]string{
+	order.SortByCreatedAt:   "o.created_at",
+	order.SortByUpdatedAt:   "o.updated_at",
+	order.SortByTotalAmount: "o.total_amount",
+	order.SortByStatus:      "o.status",
+	order.SortByPriority:    "o.priority",
+}
+
+// Search выполняет поиск по критериям (по умолчанию - новые заказы первыми)
+func (r *OrderRepository) Search(ctx context.Context, criteria order.SearchCriteria) ([]*order.Order, error) {
+	if criteria.Limit < 0 || criteria.Offset < 0 {
+		return nil, fmt.Errorf("%w: negative limit or offset", ErrInvalidCriteria)
+	}
+
+	orderBy, err := criteriaOrderBy(criteria)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.findOrders(ctx, criteriaWhere(criteria), orderBy, criteria.Limit, criteria.Offset)
+}
+
+// Count возвращает количество заказов по критериям (без Limit/Offset)
+func (r *OrderRepository) Count(ctx context.Context, criteria order.SearchCriteria) (int, error) {
+	w := criteriaWhere(criteria)
+
+	var count int
+	err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM orders o WHERE `+w.sql(), w.args...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count orders: %w", err)
+	}
+	return count, nil
+}
+
+// criteriaWhere переводит критерии в условие WHERE
+func criteriaWhere(c order.SearchCriteria) *where {
+	w := newWhere()
+
+	if c.CustomerID != nil {
+		w.add("o.customer_id = ?", *c.CustomerID)
+	}
+	if c.Status != nil {
+		w.add("o.status = ?", int(*c.Status))
+	}
+	if c.Priority != nil {
+		w.add("o.priority = ?", int(*c.Priority))
+	}
+	if c.Source != nil {
+		w.add("o.source = ?", string(*c.Source))
+	}
+	if c.MinAmount != nil {
+		w.add("o.total_amount >= ? AND o.currency = ?", c.MinAmount.Amount(), c.MinAmount.Currency())
+	}
+	if c.MaxAmount != nil {
+		w.add("o.total_amount <= ? AND o.currency = ?", c.MaxAmount.Amount(), c.MaxAmount.Currency())
+	}
+	if c.DateFrom != nil {
+		w.add("o.created_at >= ?", dbTime(*c.DateFrom))
+	}
+	if c.DateTo != nil {
+		w.add("o.created_at < ?", dbTime(*c.DateTo))
+	}
+	if c.ProductID != nil {
+		w.add("EXISTS (SELECT 1 FROM order_items i WHERE i.order_id = o.id AND i.product_id = ?)", *c.ProductID)
+	}
+	if c.ShippingCity != nil {
+		w.add("o.shipping_city = ?", *c.ShippingCity)
+	}
+
+	return w
+}
+
+// criteriaOrderBy переводит SortBy/SortDirection в ORDER BY по белому списку
+func criteriaOrderBy(c order.SearchCriteria) (string, error) {
+	field := c.SortBy
+	if field == "" {
+		field = order.SortByCreatedAt
+	}
+	column, ok := sortColumns[field]
+	if !ok {
+		return "", fmt.Errorf("%w: unknown sort field %q", ErrInvalidCriteria, c.SortBy)
+	}
+
+	var direction string
+	switch c.SortDirection {
+	case order.SortAsc:
+		direction = "ASC"
+	case order.SortDesc, "":
+		direction = "DESC"
+	default:
+		return "", fmt.Errorf("%w: unknown sort direction %q", ErrInvalidCriteria, c.SortDirection)
+	}
+
+	// id делает порядок стабильным для пагинации
+	return fmt.Sprintf("%s %s, o.id %s", column, direction, direction), nil
+}
+
+// 🧩 ПОСТРОИТЕЛЬ УСЛОВИЙ
+
+// where накапливает условия и нумерованные параметры запроса
+type where struct {
+	conditions []string
+	args       []interface{}
+}
+
+// newWhere создает условие, скрывающее мягко удаленные заказы
+func newWhere() *where {
+	return &where{conditions: []string{"o.deleted_at IS NULL"}}
+}
+
+// add добавляет условие; каждый "?" заменяется следующим параметром из args
+func (w *where) add(condition string, args ...interface{}) {
+	var b strings.Builder
+	next := 0
+	for _, ch := range condition {
+		if ch != '?' {
+			b.WriteRune(ch)
+			continue
+		}
+		if next >= len(args) {
+			panic(fmt.Sprintf("sqlstore: not enough arguments for %q", condition))
+		}
+		b.WriteString(w.param(args[next]))
+		next++
+	}
+	if next != len(args) {
+		panic(fmt.Sprintf("sqlstore: too many arguments for %q", condition))
+	}
+
+	w.conditions = append(w.conditions, b.String())
+}
+
+// param добавляет параметр и возвращает его плейсхолдер
+func (w *where) param(arg interface{}) string {
+	w.args = append(w.args, arg)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+// sql возвращает условия, объединенные через AND
+func (w *where) sql() string {
+	return strings.Join(w.conditions, " AND ")
+}
+
+// placeholders возвращает "?, ?, ..." для where.add
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// numbered возвращает "$start, $start+1, ..." для n параметров
+func numbered(start, n int) string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = fmt.Sprintf("$%d", start+i)
+	}
+	return strings.Join(parts, ", ")
+}
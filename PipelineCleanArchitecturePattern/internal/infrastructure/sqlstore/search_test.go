@@ -0,0 +1,107 @@
+package sqlstore
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/internal/domain/order"
+
+	"github.com/google/uuid"
+)
+
+func TestOrderRepositorySearch(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, repo *OrderRepository) {
+		ctx := context.Background()
+		base := time.Date(2024, time.March, 4, 12, 0, 0, 0, time.UTC)
+		customerID, productID := uuid.New(), uuid.New()
+
+		small := makeOrder(t, orderSpec{customerID: customerID, createdAt: base, city: "Казань"})
+		large := makeOrder(t, orderSpec{
+			customerID: customerID,
+			createdAt:  base.Add(time.Hour),
+			status:     order.StatusPaid,
+			priority:   order.PriorityUrgent,
+			items:      []order.OrderItem{lineItem(productID, 3, 50000, 0, "RUB")},
+		})
+		other := makeOrder(t, orderSpec{createdAt: base.Add(2 * time.Hour), items: []order.OrderItem{lineItem(productID, 1, 20000, 0, "RUB")}})
+		dollars := makeOrder(t, orderSpec{createdAt: base.Add(3 * time.Hour), currency: "USD", items: []order.OrderItem{lineItem(uuid.New(), 1, 900000, 0, "USD")}})
+
+		if err := repo.SaveBatch(ctx, []*order.Order{small, large, other, dollars}); err != nil {
+			t.Fatal(err)
+		}
+
+		paid, urgent := order.StatusPaid, order.PriorityUrgent
+		kazan, injection := "Казань", "'; DROP TABLE orders; --"
+		minAmount := order.NewMoney(15000, "RUB")
+		dateFrom, dateTo := base.Add(time.Hour), base.Add(3*time.Hour)
+
+		tests := []struct {
+			name     string
+			criteria order.SearchCriteria
+			want     []uuid.UUID
+		}{
+			{"default newest first", order.SearchCriteria{}, []uuid.UUID{dollars.ID(), other.ID(), large.ID(), small.ID()}},
+			{"customer", order.SearchCriteria{CustomerID: &customerID, SortDirection: order.SortAsc}, []uuid.UUID{small.ID(), large.ID()}},
+			{"status and priority", order.SearchCriteria{Status: &paid, Priority: &urgent}, []uuid.UUID{large.ID()}},
+			{"min amount in same currency", order.SearchCriteria{MinAmount: &minAmount, SortBy: order.SortByTotalAmount}, []uuid.UUID{large.ID(), other.ID()}},
+			{"date range is half-open", order.SearchCriteria{DateFrom: &dateFrom, DateTo: &dateTo, SortDirection: order.SortAsc}, []uuid.UUID{large.ID(), other.ID()}},
+			{"product", order.SearchCriteria{ProductID: &productID, SortBy: order.SortByTotalAmount, SortDirection: order.SortAsc}, []uuid.UUID{other.ID(), large.ID()}},
+			{"shipping city", order.SearchCriteria{ShippingCity: &kazan}, []uuid.UUID{small.ID()}},
+			{"values are parameters", order.SearchCriteria{ShippingCity: &injection}, []uuid.UUID{}},
+			{"limit", order.SearchCriteria{Limit: 2}, []uuid.UUID{dollars.ID(), other.ID()}},
+			{"offset without limit", order.SearchCriteria{Offset: 3}, []uuid.UUID{small.ID()}},
+			{"page", order.SearchCriteria{Limit: 2, Offset: 1, SortDirection: order.SortAsc}, []uuid.UUID{large.ID(), other.ID()}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				found, err := repo.Search(ctx, tt.criteria)
+				if err != nil {
+					t.Fatalf("Search() error = %v", err)
+				}
+				if got := ids(found); !reflect.DeepEqual(got, tt.want) {
+					t.Errorf("Search() = %v, want %v", got, tt.want)
+				}
+
+				// Count игнорирует пагинацию
+				countCriteria := tt.criteria
+				countCriteria.Limit, countCriteria.Offset = 0, 0
+				all, err := repo.Search(ctx, countCriteria)
+				if err != nil {
+					t.Fatal(err)
+				}
+				count, err := repo.Count(ctx, tt.criteria)
+				if err != nil {
+					t.Fatalf("Count() error = %v", err)
+				}
+				if count != len(all) {
+					t.Errorf("Count() = %d, want %d", count, len(all))
+				}
+			})
+		}
+
+		if _, err := repo.GetByID(ctx, small.ID()); err != nil {
+			t.Errorf("orders table damaged after injection attempt: %v", err)
+		}
+	})
+}
+
+func TestOrderRepositorySearchRejectsInvalidCriteria(t *testing.T) {
+	repo := newSQLiteRepository(t)
+
+	tests := []order.SearchCriteria{
+		{SortBy: "total_amount; DROP TABLE orders"},
+		{SortDirection: "sideways"},
+		{Limit: -1},
+		{Offset: -5},
+	}
+
+	for _, criteria := range tests {
+		if _, err := repo.Search(context.Background(), criteria); !errors.Is(err, ErrInvalidCriteria) {
+			t.Errorf("Search(%+v) error = %v, want ErrInvalidCriteria", criteria, err)
+		}
+	}
+}
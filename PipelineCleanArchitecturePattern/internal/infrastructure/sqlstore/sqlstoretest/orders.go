@@ -0,0 +1,73 @@
+// Package sqlstoretest готовит заказы в SQLite для тестов сервисов приложения
+//
+// Сервисы хранят заказы в sqlstore.OrderRepository, и их тесты проверяют
+// поведение поверх настоящих миграций, а не заглушек. Здесь собраны база в
+// памяти и сборка заказов, которые иначе копировались бы в каждый пакет.
+package sqlstoretest
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"pipeline-clean-architecture/internal/domain/order"
+	"pipeline-clean-architecture/internal/infrastructure/sqlstore"
+
+	"github.com/google/uuid"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// NewOrderRepository репозиторий заказов в SQLite в памяти с примененными миграциями
+func NewOrderRepository(t testing.TB) *sqlstore.OrderRepository {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Каждое соединение к :memory: - отдельная база
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	repo, err := sqlstore.NewOrderRepository(db, sqlstore.DialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return repo
+}
+
+// NewItem позиция заказа с ценой в рублях (копейках)
+func NewItem(t testing.TB, productID uuid.UUID, quantity int, price int64) order.OrderItem {
+	t.Helper()
+
+	item, err := order.NewOrderItem(productID, quantity, order.NewMoney(price, "RUB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return item
+}
+
+// NewOrder заказ покупателя с тестовым адресом доставки; не сохраняется
+func NewOrder(t testing.TB, customerID uuid.UUID, items ...order.OrderItem) *order.Order {
+	t.Helper()
+
+	ord, err := order.NewOrder(customerID, items, order.NewAddress("Тверская 1", "Москва", "125009", "RU", "+7"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ord
+}
+
+// MarkAsPaid проводит новый заказ через валидацию и оплату
+func MarkAsPaid(t testing.TB, ord *order.Order) {
+	t.Helper()
+
+	for _, transition := range []func() error{ord.MarkAsValidated, ord.MarkAsPaymentProcessing, ord.MarkAsPaid} {
+		if err := transition(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
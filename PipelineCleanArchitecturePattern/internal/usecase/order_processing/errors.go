@@ -0,0 +1,26 @@
+package order_processing
+
+import "errors"
+
+// ⚠️ ОШИБКИ USE CASE СЛОЯ
+// Delivery слой (REST, gRPC) переводит их в коды ответа через errors.Is
+
+var (
+	// ErrInvalidRequest запрос не прошел проверку
+	ErrInvalidRequest = errors.New("invalid request")
+
+	// ErrPipelineNotFound для заказа еще не запускался пайплайн
+	ErrPipelineNotFound = errors.New("pipeline not found")
+
+	// ErrPipelineRunning пайплайн заказа уже выполняется
+	ErrPipelineRunning = errors.New("pipeline is already running")
+
+	// ErrStepNotSupported шаг не зарегистрирован в движке пайплайнов
+	ErrStepNotSupported = errors.New("pipeline step is not supported")
+
+	// ErrPaymentFailed платеж отклонен или не прошел
+	ErrPaymentFailed = errors.New("payment failed")
+
+	// ErrNotConfigured для операции не подключен внешний сервис
+	ErrNotConfigured = errors.New("service is not configured")
+)
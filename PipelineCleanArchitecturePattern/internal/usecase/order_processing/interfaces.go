@@ -66,6 +66,10 @@ type PipelineExecutor interface {
 	
 	// GetPipelineStatus возвращает статус выполнения пайплайна
 	GetPipelineStatus(ctx context.Context, orderID uuid.UUID) (*PipelineStatus, error)
+	
+	// WatchPipelineStatus отправляет статус пайплайна при каждом изменении шага
+	// Канал закрывается после завершения пайплайна или отмены ctx
+	WatchPipelineStatus(ctx context.Context, orderID uuid.UUID) (<-chan PipelineStatus, error)
 }
 
 // 📊 REQUEST/RESPONSE СТРУКТУРЫ
@@ -131,6 +135,7 @@ type ProcessPaymentRequest struct {
 // ProcessPaymentResponse ответ обработки платежа
 type ProcessPaymentResponse struct {
 	Payment       *payment.Payment `json:"payment"`
+	PaymentID     uuid.UUID        `json:"payment_id"`
 	Status        payment.Status   `json:"status"`
 	RedirectURL   string          `json:"redirect_url,omitempty"`
 	QRCodeData    string          `json:"qr_code_data,omitempty"`
@@ -315,4 +320,21 @@ type PipelineStatus struct {
 	StartedAt      time.Time     `json:"started_at"`
 	UpdatedAt      time.Time     `json:"updated_at"`
 	EstimatedEnd   *time.Time    `json:"estimated_end,omitempty"`
+	State          PipelineState `json:"state"`
+	FailedStep     PipelineStep  `json:"failed_step,omitempty"`
+	Error          string        `json:"error,omitempty"`
+}
+
+// PipelineState состояние выполнения пайплайна
+type PipelineState string
+
+const (
+	PipelineStateRunning   PipelineState = "running"
+	PipelineStateCompleted PipelineState = "completed"
+	PipelineStateFailed    PipelineState = "failed"
+)
+
+// IsFinal завершено ли выполнение
+func (s PipelineState) IsFinal() bool {
+	return s == PipelineStateCompleted || s == PipelineStateFailed
 }
\ No newline at end of file
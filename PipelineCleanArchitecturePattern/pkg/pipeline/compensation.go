@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ↩️ КОМПЕНСАЦИЯ ШАГОВ (SAGA)
+//
+// ============================================================================
+// ЗАЧЕМ НУЖНА КОМПЕНСАЦИЯ:
+// ============================================================================
+//
+// Шаги пайплайна меняют внешний мир: списывают деньги, резервируют товары.
+// Если check_inventory упал после успешного process_payment, деньги клиента
+// остаются списанными, хотя заказ не будет выполнен.
+//
+// Saga-подход: каждый шаг с побочными эффектами умеет их отменить.
+// При падении пайплайна движок вызывает Compensate у завершенных шагов
+// в обратном порядке завершения:
+//
+//	validate_order → process_payment → check_inventory ✗
+//	                 refund ←──────────┘
+//
+// РЕЗУЛЬТАТ:
+// - Все компенсации успешны → StatusCompensated (Resume ничего не делает)
+// - Хотя бы одна упала      → StatusCompensationFailed, Resume повторит
+//   только неуспешные компенсации
+// - Компенсировать нечего   → StatusFailed, Resume продолжит выполнение
+//
+// ============================================================================
+
+// Compensator опциональный интерфейс шага, умеющего отменить свои эффекты
+type Compensator interface {
+	// Compensate отменяет результат успешно выполненного шага
+	Compensate(ctx context.Context, data *StepData, result *StepResult) error
+}
+
+// CompensationRecord результат компенсации шага
+type CompensationRecord struct {
+	// Step название компенсированного шага
+	Step string `json:"step"`
+
+	// Success успешна ли компенсация
+	Success bool `json:"success"`
+
+	// Error текст ошибки компенсации
+	Error string `json:"error,omitempty"`
+
+	// StartedAt время начала
+	StartedAt time.Time `json:"started_at"`
+
+	// CompletedAt время завершения
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// compensate вызывает компенсаторы завершенных шагов в обратном порядке
+//
+// Возвращает true, если был вызван хотя бы один компенсатор.
+// Шаги, уже успешно компенсированные ранее (до Resume), пропускаются.
+func (e *Engine) compensate(ctx context.Context, execCtx *ExecutionContext) bool {
+	compensated := make(map[string]bool, len(execCtx.Compensations))
+	for _, record := range execCtx.Compensations {
+		if record.Success {
+			compensated[record.Step] = true
+		}
+	}
+
+	attempted := false
+	for i := len(execCtx.CompletedSteps) - 1; i >= 0; i-- {
+		step := execCtx.CompletedSteps[i]
+		if compensated[step] {
+			attempted = true
+			continue
+		}
+
+		e.mu.RLock()
+		handler, exists := e.stepRegistry[step]
+		e.mu.RUnlock()
+
+		compensator, ok := handler.(Compensator)
+		if !exists || !ok {
+			continue
+		}
+
+		attempted = true
+		record := e.compensateStep(ctx, step, handler, compensator, execCtx)
+		execCtx.Compensations = append(execCtx.Compensations, record)
+
+		if err := e.checkpoint(ctx, execCtx); err != nil {
+			e.logger.Error("Failed to save compensation checkpoint", zap.Error(err))
+		}
+	}
+
+	return attempted
+}
+
+// compensateStep выполняет компенсацию одного шага
+//
+// Компенсация не должна прерываться таймаутом или отменой пайплайна,
+// поэтому контекст отвязывается от родителя и ограничивается таймаутом шага.
+func (e *Engine) compensateStep(ctx context.Context, step string, handler StepHandler, compensator Compensator, execCtx *ExecutionContext) CompensationRecord {
+	timeout := e.stepTimeout(step, handler, execCtx.Pipeline)
+	ctxWithTimeout, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+	defer cancel()
+
+	record := CompensationRecord{
+		Step:      step,
+		StartedAt: time.Now(),
+	}
+
+	err := compensator.Compensate(ctxWithTimeout, e.newStepData(step, execCtx), execCtx.Results[step])
+	record.CompletedAt = time.Now()
+
+	if err != nil {
+		record.Error = err.Error()
+		e.logger.Error("Step compensation failed",
+			zap.String("step", step),
+			zap.String("execution_id", execCtx.ID),
+			zap.Error(err))
+		return record
+	}
+
+	record.Success = true
+	e.logger.Info("Step compensated",
+		zap.String("step", step),
+		zap.String("execution_id", execCtx.ID))
+	return record
+}
+
+// failedCompensations возвращает шаги, компенсировать которые так и не удалось
+func failedCompensations(execCtx *ExecutionContext) []string {
+	succeeded := make(map[string]bool, len(execCtx.Compensations))
+	for _, record := range execCtx.Compensations {
+		if record.Success {
+			succeeded[record.Step] = true
+		}
+	}
+
+	failed := make([]string, 0)
+	for _, record := range execCtx.Compensations {
+		if !succeeded[record.Step] {
+			succeeded[record.Step] = true // не дублируем шаг в списке
+			failed = append(failed, record.Step)
+		}
+	}
+
+	return failed
+}
+
+// applyCompensation компенсирует упавшее выполнение и выставляет итоговый статус
+//
+// ExecutionContext.Error остается ошибкой самого пайплайна, детали
+// неуспешных компенсаций хранятся в ExecutionContext.Compensations.
+func (e *Engine) applyCompensation(ctx context.Context, execCtx *ExecutionContext) {
+	if !e.compensate(ctx, execCtx) {
+		return
+	}
+
+	if failed := failedCompensations(execCtx); len(failed) > 0 {
+		execCtx.Status = StatusCompensationFailed
+		return
+	}
+
+	execCtx.Status = StatusCompensated
+}
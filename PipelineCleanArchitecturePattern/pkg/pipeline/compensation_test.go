@@ -0,0 +1,100 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// compensatingStep шаг с компенсацией для тестов saga
+type compensatingStep struct {
+	*testStep
+	compensateErr error
+	compensated   *[]string
+}
+
+func (s *compensatingStep) Compensate(ctx context.Context, data *StepData, result *StepResult) error {
+	if s.compensateErr != nil {
+		return s.compensateErr
+	}
+	*s.compensated = append(*s.compensated, s.name)
+	return nil
+}
+
+func TestExecuteCompensatesCompletedStepsInReverseOrder(t *testing.T) {
+	var compensated []string
+	validate := &testStep{name: "validate_order"}
+	payment := &compensatingStep{testStep: &testStep{name: "process_payment"}, compensated: &compensated}
+	reserve := &compensatingStep{testStep: &testStep{name: "reserve_items"}, compensated: &compensated}
+	notify := &testStep{name: "send_notifications", err: errors.New("smtp unavailable")}
+
+	engine := newTestEngine(t, validate, notify)
+	for _, step := range []*compensatingStep{payment, reserve} {
+		if err := engine.RegisterStep(step.name, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	definition := PipelineDefinition{
+		Name:    "order_processing",
+		Steps:   []string{"validate_order", "process_payment", "reserve_items", "send_notifications"},
+		Timeout: 2 * time.Second,
+	}
+
+	execCtx, err := engine.Execute(context.Background(), definition, nil)
+	if err == nil {
+		t.Fatal("Execute() error = nil, want send_notifications failure")
+	}
+	if execCtx.Status != StatusCompensated {
+		t.Errorf("Status = %s, want %s", execCtx.Status, StatusCompensated)
+	}
+	if len(compensated) != 2 || compensated[0] != "reserve_items" || compensated[1] != "process_payment" {
+		t.Errorf("compensated = %v, want [reserve_items process_payment]", compensated)
+	}
+	if len(execCtx.Compensations) != 2 {
+		t.Errorf("Compensations = %+v, want 2 records", execCtx.Compensations)
+	}
+}
+
+func TestResumeRetriesFailedCompensation(t *testing.T) {
+	var compensated []string
+	payment := &compensatingStep{
+		testStep:      &testStep{name: "process_payment"},
+		compensateErr: errors.New("bank timeout"),
+		compensated:   &compensated,
+	}
+	inventory := &testStep{name: "check_inventory", err: errors.New("insufficient inventory")}
+
+	engine := newTestEngine(t, inventory)
+	if err := engine.RegisterStep(payment.name, payment); err != nil {
+		t.Fatal(err)
+	}
+	engine.SetExecutionStore(NewMemoryExecutionStore())
+
+	definition := PipelineDefinition{
+		Name:    "order_processing",
+		Steps:   []string{"process_payment", "check_inventory"},
+		Timeout: 2 * time.Second,
+	}
+
+	execCtx, _ := engine.Execute(context.Background(), definition, nil)
+	if execCtx.Status != StatusCompensationFailed {
+		t.Fatalf("Status = %s, want %s", execCtx.Status, StatusCompensationFailed)
+	}
+
+	payment.compensateErr = nil
+	resumed, err := engine.Resume(context.Background(), execCtx.ID)
+	if err != nil {
+		t.Fatalf("Resume() error = %v", err)
+	}
+	if resumed.Status != StatusCompensated {
+		t.Errorf("Status = %s, want %s", resumed.Status, StatusCompensated)
+	}
+	if inventory.Calls() != 1 || payment.Calls() != 1 {
+		t.Errorf("steps re-executed on resume: inventory = %d, payment = %d", inventory.Calls(), payment.Calls())
+	}
+	if len(compensated) != 1 {
+		t.Errorf("compensated = %v, want single refund", compensated)
+	}
+}
@@ -0,0 +1,507 @@
+package pipeline
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 🔀 ЯЗЫК УСЛОВИЙ ДЛЯ PipelineDefinition.Conditional
+//
+// ============================================================================
+// ОПЕРАТОРЫ:
+// ============================================================================
+//
+// eq, ne              - равенство (числа сравниваются по значению: 100 == 100.0)
+// gt, gte, lt, lte    - порядок для чисел, времени (time.Time или RFC3339) и строк
+// contains            - подстрока, элемент среза или ключ map
+// in, not_in          - значение поля входит в список Value
+// regex               - строка поля соответствует регулярному выражению Value
+// exists, not_exists  - поле присутствует / отсутствует
+//
+// ============================================================================
+// ПУТИ К ПОЛЯМ:
+// ============================================================================
+//
+// "total_amount"                - ключ GlobalData (или Output любого шага)
+// "validate_order.total_amount" - Output конкретного шага
+// "customer.address.city"       - вложенные map в GlobalData
+//
+// ============================================================================
+// ЛОГИКА:
+// ============================================================================
+//
+// Условие с Field сравнивает поле, Nested объединяются с ним через Logic
+// (and по умолчанию). Условие без Field и Operator - чистая группа Nested.
+//
+// Ошибки в определении (неизвестный оператор, неверное регулярное
+// выражение) отклоняются PipelineDefinition.Validate до запуска шагов,
+// а не превращаются в молча пропущенный шаг.
+//
+// ============================================================================
+
+// Операторы условий
+const (
+	OperatorEq        = "eq"
+	OperatorNe        = "ne"
+	OperatorGt        = "gt"
+	OperatorGte       = "gte"
+	OperatorLt        = "lt"
+	OperatorLte       = "lte"
+	OperatorContains  = "contains"
+	OperatorIn        = "in"
+	OperatorNotIn     = "not_in"
+	OperatorRegex     = "regex"
+	OperatorExists    = "exists"
+	OperatorNotExists = "not_exists"
+)
+
+// Логические операторы
+const (
+	LogicAnd = "and"
+	LogicOr  = "or"
+)
+
+// ErrInvalidCondition условие в определении пайплайна некорректно
+var ErrInvalidCondition = errors.New("invalid condition")
+
+// regexCache кеш скомпилированных регулярных выражений условий
+var regexCache sync.Map
+
+// Validate проверяет корректность определения пайплайна
+func (d PipelineDefinition) Validate() error {
+	switch d.Mode {
+	case "", ModeSequential, ModeDAG:
+	default:
+		return fmt.Errorf("unknown execution mode: %s", d.Mode)
+	}
+
+	for step, condition := range d.Conditional {
+		if err := condition.Validate(); err != nil {
+			return fmt.Errorf("condition for step %s: %w", step, err)
+		}
+	}
+
+	return nil
+}
+
+// Validate проверяет условие и все вложенные условия
+func (c Condition) Validate() error {
+	switch c.Logic {
+	case "", LogicAnd, LogicOr:
+	default:
+		return fmt.Errorf("%w: unknown logic %q", ErrInvalidCondition, c.Logic)
+	}
+
+	isGroup := c.Field == "" && c.Operator == ""
+	if isGroup && len(c.Nested) == 0 {
+		return fmt.Errorf("%w: field and operator are required", ErrInvalidCondition)
+	}
+
+	if !isGroup {
+		if c.Field == "" {
+			return fmt.Errorf("%w: field is required for operator %q", ErrInvalidCondition, c.Operator)
+		}
+		if err := validateOperator(c.Operator, c.Value); err != nil {
+			return fmt.Errorf("field %s: %w", c.Field, err)
+		}
+	}
+
+	for i, nested := range c.Nested {
+		if err := nested.Validate(); err != nil {
+			return fmt.Errorf("nested[%d]: %w", i, err)
+		}
+	}
+
+	return nil
+}
+
+// validateOperator проверяет оператор и соответствие ему значения
+func validateOperator(operator string, value interface{}) error {
+	switch operator {
+	case OperatorEq, OperatorNe, OperatorGt, OperatorGte, OperatorLt, OperatorLte, OperatorContains:
+		return nil
+	case OperatorExists, OperatorNotExists:
+		return nil
+	case OperatorIn, OperatorNotIn:
+		if _, ok := toSlice(value); !ok {
+			return fmt.Errorf("%w: operator %s requires a list value", ErrInvalidCondition, operator)
+		}
+		return nil
+	case OperatorRegex:
+		pattern, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w: operator regex requires a string pattern", ErrInvalidCondition)
+		}
+		if _, err := compileRegex(pattern); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCondition, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: unknown operator %q", ErrInvalidCondition, operator)
+	}
+}
+
+// shouldExecuteStep проверяет, нужно ли выполнить шаг
+func (e *Engine) shouldExecuteStep(step string, execCtx *ExecutionContext) bool {
+	condition, exists := execCtx.Pipeline.Conditional[step]
+	if !exists {
+		return true
+	}
+
+	return e.evaluateCondition(condition, execCtx)
+}
+
+// evaluateCondition оценивает условие
+func (e *Engine) evaluateCondition(condition Condition, execCtx *ExecutionContext) bool {
+	isGroup := condition.Field == "" && condition.Operator == ""
+
+	var result bool
+	if !isGroup {
+		// Получаем значение поля и сравниваем с условием
+		fieldValue, found := e.getFieldValue(condition.Field, execCtx)
+		result = compareValues(fieldValue, found, condition.Operator, condition.Value)
+	}
+
+	if len(condition.Nested) == 0 {
+		return result
+	}
+
+	// Применяем логический оператор к вложенным условиям
+	or := condition.Logic == LogicOr
+	if isGroup {
+		result = !or // нейтральный элемент: true для and, false для or
+	}
+
+	for _, nested := range condition.Nested {
+		if or {
+			result = result || e.evaluateCondition(nested, execCtx)
+		} else {
+			result = result && e.evaluateCondition(nested, execCtx)
+		}
+	}
+
+	return result
+}
+
+// getFieldValue получает значение поля из контекста
+//
+// Порядок поиска:
+// 1. Точный ключ в GlobalData
+// 2. "шаг.путь" - Output завершенного шага
+// 3. Путь по вложенным map в GlobalData
+// 4. Точный ключ в Output шагов (последний завершенный шаг побеждает)
+func (e *Engine) getFieldValue(field string, execCtx *ExecutionContext) (interface{}, bool) {
+	if val, exists := execCtx.GlobalData[field]; exists {
+		return val, true
+	}
+
+	path := strings.Split(field, ".")
+	if len(path) > 1 {
+		if result, exists := execCtx.Results[path[0]]; exists && result != nil {
+			if val, found := lookupPath(result.Output, path[1:]); found {
+				return val, true
+			}
+		}
+
+		if val, found := lookupPath(execCtx.GlobalData, path); found {
+			return val, true
+		}
+	}
+
+	for i := len(execCtx.CompletedSteps) - 1; i >= 0; i-- {
+		result := execCtx.Results[execCtx.CompletedSteps[i]]
+		if result == nil {
+			continue
+		}
+		if val, exists := result.Output[field]; exists {
+			return val, true
+		}
+	}
+
+	return nil, false
+}
+
+// lookupPath проходит по вложенным map по сегментам пути
+func lookupPath(data map[string]interface{}, path []string) (interface{}, bool) {
+	var current interface{} = data
+
+	for _, segment := range path {
+		var next interface{}
+		var exists bool
+
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, exists = node[segment]
+		case map[string]string:
+			next, exists = node[segment]
+		default:
+			return nil, false
+		}
+
+		if !exists {
+			return nil, false
+		}
+		current = next
+	}
+
+	return current, true
+}
+
+// compareValues сравнивает значение поля со значением условия
+func compareValues(fieldValue interface{}, found bool, operator string, conditionValue interface{}) bool {
+	switch operator {
+	case OperatorExists:
+		return found && fieldValue != nil
+	case OperatorNotExists:
+		return !found || fieldValue == nil
+	}
+
+	if !found {
+		return false
+	}
+
+	switch operator {
+	case OperatorEq:
+		return valuesEqual(fieldValue, conditionValue)
+	case OperatorNe:
+		return !valuesEqual(fieldValue, conditionValue)
+	case OperatorGt:
+		cmp, ok := compareOrdered(fieldValue, conditionValue)
+		return ok && cmp > 0
+	case OperatorGte:
+		cmp, ok := compareOrdered(fieldValue, conditionValue)
+		return ok && cmp >= 0
+	case OperatorLt:
+		cmp, ok := compareOrdered(fieldValue, conditionValue)
+		return ok && cmp < 0
+	case OperatorLte:
+		cmp, ok := compareOrdered(fieldValue, conditionValue)
+		return ok && cmp <= 0
+	case OperatorContains:
+		return containsValue(fieldValue, conditionValue)
+	case OperatorIn:
+		return inList(fieldValue, conditionValue)
+	case OperatorNotIn:
+		return !inList(fieldValue, conditionValue)
+	case OperatorRegex:
+		return matchesRegex(fieldValue, conditionValue)
+	default:
+		return false
+	}
+}
+
+// valuesEqual сравнивает значения с приведением типов
+func valuesEqual(a, b interface{}) bool {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		return ok && af == bf
+	}
+
+	if at, ok := toTime(a); ok {
+		if bt, ok := toTime(b); ok {
+			return at.Equal(bt)
+		}
+	}
+
+	if as, ok := toSlice(a); ok {
+		bs, ok := toSlice(b)
+		if !ok || len(as) != len(bs) {
+			return false
+		}
+		for i := range as {
+			if !valuesEqual(as[i], bs[i]) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// compareOrdered сравнивает упорядочиваемые значения: -1, 0, 1
+//
+// Время сравнивается раньше строк, чтобы "2024-01-02T00:00:00Z" и time.Time
+// сравнивались как моменты времени, а не лексикографически.
+func compareOrdered(a, b interface{}) (int, bool) {
+	if af, ok := toFloat(a); ok {
+		bf, ok := toFloat(b)
+		if !ok {
+			return 0, false
+		}
+		switch {
+		case af < bf:
+			return -1, true
+		case af > bf:
+			return 1, true
+		}
+		return 0, true
+	}
+
+	if at, ok := toTime(a); ok {
+		if bt, ok := toTime(b); ok {
+			return at.Compare(bt), true
+		}
+	}
+
+	as, aok := a.(string)
+	bs, bok := b.(string)
+	if aok && bok {
+		return strings.Compare(as, bs), true
+	}
+
+	return 0, false
+}
+
+// containsValue проверяет вхождение подстроки, элемента среза или ключа map
+func containsValue(container, value interface{}) bool {
+	if s, ok := container.(string); ok {
+		sub, ok := value.(string)
+		return ok && strings.Contains(s, sub)
+	}
+
+	if items, ok := toSlice(container); ok {
+		for _, item := range items {
+			if valuesEqual(item, value) {
+				return true
+			}
+		}
+		return false
+	}
+
+	key, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	rv := reflect.ValueOf(container)
+	if rv.Kind() == reflect.Map && rv.Type().Key().Kind() == reflect.String {
+		return rv.MapIndex(reflect.ValueOf(key).Convert(rv.Type().Key())).IsValid()
+	}
+
+	return false
+}
+
+// inList проверяет, входит ли значение в список
+func inList(value, list interface{}) bool {
+	items, ok := toSlice(list)
+	if !ok {
+		return false
+	}
+
+	for _, item := range items {
+		if valuesEqual(value, item) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesRegex проверяет строку на соответствие регулярному выражению
+func matchesRegex(value, pattern interface{}) bool {
+	s, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	p, ok := pattern.(string)
+	if !ok {
+		return false
+	}
+
+	re, err := compileRegex(p)
+	if err != nil {
+		return false
+	}
+	return re.MatchString(s)
+}
+
+// compileRegex компилирует регулярное выражение с кешированием
+func compileRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	regexCache.Store(pattern, re)
+	return re, nil
+}
+
+// toFloat приводит числовые типы к float64
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case int8:
+		return float64(n), true
+	case int16:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case uint:
+		return float64(n), true
+	case uint8:
+		return float64(n), true
+	case uint16:
+		return float64(n), true
+	case uint32:
+		return float64(n), true
+	case uint64:
+		return float64(n), true
+	case float32:
+		return float64(n), true
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// toTime приводит time.Time и строки RFC3339 ко времени
+func toTime(v interface{}) (time.Time, bool) {
+	switch t := v.(type) {
+	case time.Time:
+		return t, true
+	case *time.Time:
+		if t == nil {
+			return time.Time{}, false
+		}
+		return *t, true
+	case string:
+		parsed, err := time.Parse(time.RFC3339Nano, t)
+		return parsed, err == nil
+	}
+	return time.Time{}, false
+}
+
+// toSlice приводит любой срез или массив к []interface{}
+func toSlice(v interface{}) ([]interface{}, bool) {
+	if items, ok := v.([]interface{}); ok {
+		return items, true
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		return nil, false
+	}
+
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
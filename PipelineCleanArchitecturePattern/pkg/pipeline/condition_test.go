@@ -0,0 +1,168 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEvaluateCondition(t *testing.T) {
+	deadline := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	execCtx := &ExecutionContext{
+		GlobalData: map[string]interface{}{
+			"customer_tier": "gold",
+			"items_count":   3,
+			"created_at":    deadline.Add(-time.Hour),
+			"tags":          []string{"express", "gift"},
+			"customer": map[string]interface{}{
+				"address": map[string]interface{}{"country": "RU"},
+			},
+		},
+		Results: map[string]*StepResult{
+			"validate_order": {Output: map[string]interface{}{
+				"total_amount": int64(150000),
+				"currency":     "RUB",
+				"email":        "buyer@example.com",
+			}},
+		},
+		CompletedSteps: []string{"validate_order"},
+	}
+
+	tests := []struct {
+		name      string
+		condition Condition
+		want      bool
+	}{
+		{"numeric eq across types", Condition{Field: "items_count", Operator: OperatorEq, Value: 3.0}, true},
+		{"numeric gt on step output path", Condition{Field: "validate_order.total_amount", Operator: OperatorGt, Value: 100000}, true},
+		{"numeric lte", Condition{Field: "validate_order.total_amount", Operator: OperatorLte, Value: 149999}, false},
+		{"gte equal", Condition{Field: "items_count", Operator: OperatorGte, Value: 3}, true},
+		{"string lt", Condition{Field: "customer_tier", Operator: OperatorLt, Value: "silver"}, true},
+		{"time before RFC3339", Condition{Field: "created_at", Operator: OperatorLt, Value: deadline.Format(time.RFC3339)}, true},
+		{"time after", Condition{Field: "created_at", Operator: OperatorGt, Value: deadline}, false},
+		{"in list", Condition{Field: "customer_tier", Operator: OperatorIn, Value: []interface{}{"gold", "platinum"}}, true},
+		{"not_in list", Condition{Field: "validate_order.currency", Operator: OperatorNotIn, Value: []string{"USD", "EUR"}}, true},
+		{"contains slice", Condition{Field: "tags", Operator: OperatorContains, Value: "gift"}, true},
+		{"contains substring", Condition{Field: "validate_order.email", Operator: OperatorContains, Value: "@example"}, true},
+		{"regex", Condition{Field: "validate_order.email", Operator: OperatorRegex, Value: `^[^@]+@example\.com$`}, true},
+		{"nested map path", Condition{Field: "customer.address.country", Operator: OperatorEq, Value: "RU"}, true},
+		{"bare output key", Condition{Field: "currency", Operator: OperatorEq, Value: "RUB"}, true},
+		{"exists", Condition{Field: "validate_order.email", Operator: OperatorExists}, true},
+		{"not_exists", Condition{Field: "validate_order.discount", Operator: OperatorNotExists}, true},
+		{"missing field compares false", Condition{Field: "missing", Operator: OperatorNe, Value: "x"}, false},
+		{"incomparable types", Condition{Field: "customer_tier", Operator: OperatorGt, Value: 1}, false},
+		{
+			"or with nested",
+			Condition{
+				Field: "customer_tier", Operator: OperatorEq, Value: "bronze",
+				Logic:  LogicOr,
+				Nested: []Condition{{Field: "items_count", Operator: OperatorGt, Value: 2}},
+			},
+			true,
+		},
+		{
+			"and group",
+			Condition{Nested: []Condition{
+				{Field: "customer_tier", Operator: OperatorEq, Value: "gold"},
+				{Field: "items_count", Operator: OperatorGt, Value: 5},
+			}},
+			false,
+		},
+		{
+			"or group",
+			Condition{Logic: LogicOr, Nested: []Condition{
+				{Field: "customer_tier", Operator: OperatorEq, Value: "bronze"},
+				{Field: "items_count", Operator: OperatorGt, Value: 2},
+			}},
+			true,
+		},
+	}
+
+	engine := newTestEngine(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.condition.Validate(); err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if got := engine.evaluateCondition(tt.condition, execCtx); got != tt.want {
+				t.Errorf("evaluateCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConditionValidate(t *testing.T) {
+	tests := []struct {
+		name      string
+		condition Condition
+	}{
+		{"unknown operator", Condition{Field: "total", Operator: "greater"}},
+		{"missing field", Condition{Operator: OperatorEq, Value: 1}},
+		{"empty condition", Condition{}},
+		{"unknown logic", Condition{Field: "total", Operator: OperatorEq, Logic: "xor"}},
+		{"in without list", Condition{Field: "tier", Operator: OperatorIn, Value: "gold"}},
+		{"invalid regex", Condition{Field: "email", Operator: OperatorRegex, Value: "(["}},
+		{"invalid nested", Condition{Nested: []Condition{{Field: "total", Operator: "between"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.condition.Validate(); !errors.Is(err, ErrInvalidCondition) {
+				t.Errorf("Validate() error = %v, want %v", err, ErrInvalidCondition)
+			}
+		})
+	}
+}
+
+func TestExecuteRejectsInvalidConditionBeforeRunningSteps(t *testing.T) {
+	validate := &testStep{name: "validate_order"}
+	payment := &testStep{name: "process_payment"}
+
+	engine := newTestEngine(t, validate, payment)
+	definition := PipelineDefinition{
+		Name:  "order_processing",
+		Steps: []string{"validate_order", "process_payment"},
+		Conditional: map[string]Condition{
+			"process_payment": {Field: "validate_order.total_amount", Operator: "above", Value: 0},
+		},
+	}
+
+	_, err := engine.Execute(context.Background(), definition, nil)
+	if !errors.Is(err, ErrInvalidCondition) {
+		t.Fatalf("Execute() error = %v, want %v", err, ErrInvalidCondition)
+	}
+	if validate.Calls() != 0 {
+		t.Errorf("validate_order called %d times, want 0", validate.Calls())
+	}
+}
+
+func TestExecuteConditionOnStepOutputPath(t *testing.T) {
+	validate := &testStep{name: "validate_order", output: map[string]interface{}{"total_amount": int64(5000)}}
+	fraud := &testStep{name: "fraud_check"}
+	payment := &testStep{name: "process_payment"}
+
+	engine := newTestEngine(t, validate, fraud, payment)
+	definition := PipelineDefinition{
+		Name:  "order_processing",
+		Steps: []string{"validate_order", "fraud_check", "process_payment"},
+		Conditional: map[string]Condition{
+			"fraud_check": {Field: "validate_order.total_amount", Operator: OperatorGte, Value: 100000},
+		},
+	}
+
+	execCtx, err := engine.Execute(context.Background(), definition, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if fraud.Calls() != 0 {
+		t.Errorf("fraud_check called %d times, want 0", fraud.Calls())
+	}
+	if payment.Calls() != 1 {
+		t.Errorf("process_payment called %d times, want 1", payment.Calls())
+	}
+	if execCtx.Status != StatusCompleted {
+		t.Errorf("Status = %s, want %s", execCtx.Status, StatusCompleted)
+	}
+}
@@ -2,10 +2,13 @@ package pipeline
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 )
 
@@ -75,6 +78,8 @@ type Engine struct {
 	middleware    []Middleware
 	errorHandler  ErrorHandler
 	metrics       MetricsCollector
+	store         ExecutionStore
+	scheduler     *scheduler
 	config        Config
 	mu            sync.RWMutex
 }
@@ -201,9 +206,15 @@ type PipelineDefinition struct {
 	Description string `json:"description"`
 	
 	// Steps шаги пайплайна в порядке выполнения
+	// В режиме ModeDAG - только целевые шаги, порядок задают зависимости
 	Steps []string `json:"steps"`
 	
+	// Mode режим планирования шагов (по умолчанию ModeSequential)
+	Mode ExecutionMode `json:"mode,omitempty"`
+	
 	// Parallel параллельные шаги (группы)
+	// Группа выполняется целиком на месте первого своего шага в Steps;
+	// группы, ни один шаг которых не указан в Steps, выполняются после Steps
 	Parallel [][]string `json:"parallel,omitempty"`
 	
 	// Conditional условные шаги
@@ -212,6 +223,9 @@ type PipelineDefinition struct {
 	// Timeout общий таймаут пайплайна
 	Timeout time.Duration `json:"timeout"`
 	
+	// StepTimeouts таймауты отдельных шагов, переопределяют StepHandler.Timeout()
+	StepTimeouts map[string]time.Duration `json:"step_timeouts,omitempty"`
+	
 	// Retry настройки повторов
 	Retry RetryConfig `json:"retry"`
 	
@@ -221,11 +235,11 @@ type PipelineDefinition struct {
 
 // Condition условие для выполнения шага
 type Condition struct {
-	// Field поле для проверки
+	// Field поле для проверки, допускает путь "шаг.поле" (см. condition.go)
 	Field string `json:"field"`
 	
 	// Operator оператор сравнения
-	Operator string `json:"operator"` // eq, ne, gt, lt, contains, exists
+	Operator string `json:"operator"` // eq, ne, gt, gte, lt, lte, contains, in, not_in, regex, exists, not_exists
 	
 	// Value значение для сравнения
 	Value interface{} `json:"value"`
@@ -284,6 +298,9 @@ type ExecutionContext struct {
 	// Status статус выполнения
 	Status ExecutionStatus `json:"status"`
 	
+	// Compensations результаты компенсации шагов после падения (saga)
+	Compensations []CompensationRecord `json:"compensations,omitempty"`
+	
 	// Error общая ошибка пайплайна
 	Error error `json:"error,omitempty"`
 }
@@ -297,6 +314,12 @@ const (
 	StatusCompleted  ExecutionStatus = "completed"
 	StatusFailed     ExecutionStatus = "failed"
 	StatusCancelled  ExecutionStatus = "cancelled"
+	
+	// StatusCompensated пайплайн упал, эффекты завершенных шагов отменены
+	StatusCompensated ExecutionStatus = "compensated"
+	
+	// StatusCompensationFailed пайплайн упал, часть эффектов отменить не удалось
+	StatusCompensationFailed ExecutionStatus = "compensation_failed"
 )
 
 // Config конфигурация движка
@@ -310,26 +333,39 @@ type Config struct {
 	// EnableMetrics включить сбор метрик
 	EnableMetrics bool `json:"enable_metrics"`
 	
-	// EnableTracing включить трассировку
+	// EnableTracing включить трассировку (подключает TracingMiddleware)
 	EnableTracing bool `json:"enable_tracing"`
 	
 	// RetryConfig настройки повторов по умолчанию
 	RetryConfig RetryConfig `json:"retry_config"`
 	
 	// BufferSize размер буфера для каналов
+	// и емкость очереди ожидания при MaxConcurrentPipelines > 0
 	BufferSize int `json:"buffer_size"`
+	
+	// QueuePolicy поведение при заполненной очереди (см. scheduler.go)
+	QueuePolicy QueuePolicy `json:"queue_policy"`
+	
+	// PipelinePriorities приоритеты пайплайнов в очереди по имени
+	PipelinePriorities map[string]int `json:"pipeline_priorities,omitempty"`
 }
 
 // 🏗️ КОНСТРУКТОР
 
 // NewEngine создает новый движок пайплайнов
 func NewEngine(logger *zap.Logger, config Config) *Engine {
-	return &Engine{
+	engine := &Engine{
 		logger:       logger,
 		stepRegistry: make(map[string]StepHandler),
 		middleware:   make([]Middleware, 0),
 		config:       config,
 	}
+	
+	if config.MaxConcurrentPipelines > 0 {
+		engine.scheduler = newScheduler(engine, config)
+	}
+	
+	return engine
 }
 
 // 📝 РЕГИСТРАЦИЯ КОМПОНЕНТОВ
@@ -349,10 +385,18 @@ func (e *Engine) RegisterStep(name string, handler StepHandler) error {
 }
 
 // RegisterMiddleware регистрирует промежуточное ПО
+//
+// Middleware трассировки (TracingMiddleware) пропускается,
+// если Config.EnableTracing выключен.
 func (e *Engine) RegisterMiddleware(middleware Middleware) {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 	
+	if _, ok := middleware.(TracingMiddleware); ok && !e.config.EnableTracing {
+		e.logger.Info("Tracing is disabled, skipping tracing middleware")
+		return
+	}
+	
 	e.middleware = append(e.middleware, middleware)
 	e.logger.Info("Registered pipeline middleware")
 }
@@ -375,15 +419,35 @@ func (e *Engine) SetMetricsCollector(collector MetricsCollector) {
 	e.logger.Info("Set metrics collector")
 }
 
+// SetExecutionStore устанавливает хранилище checkpoint'ов выполнения
+func (e *Engine) SetExecutionStore(store ExecutionStore) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	
+	e.store = store
+	e.logger.Info("Set execution store")
+}
+
 // 🚀 ВЫПОЛНЕНИЕ ПАЙПЛАЙНОВ
 
-// Execute выполняет пайплайн
+// Execute выполняет пайплайн и ждет его завершения
+//
+// При MaxConcurrentPipelines > 0 пайплайн проходит через очередь
+// (см. scheduler.go) и может быть отклонен с ErrQueueFull.
 func (e *Engine) Execute(ctx context.Context, definition PipelineDefinition, initialData map[string]interface{}) (*ExecutionContext, error) {
 	e.logger.Info("Starting pipeline execution", 
 		zap.String("pipeline", definition.Name))
 	
-	// Создаем контекст выполнения
-	execCtx := &ExecutionContext{
+	return e.dispatch(ctx, newExecutionContext(definition, initialData))
+}
+
+// newExecutionContext создает контекст нового выполнения
+func newExecutionContext(definition PipelineDefinition, initialData map[string]interface{}) *ExecutionContext {
+	if initialData == nil {
+		initialData = make(map[string]interface{})
+	}
+	
+	return &ExecutionContext{
 		ID:             generateID(),
 		Pipeline:       definition,
 		StartedAt:      time.Now(),
@@ -393,6 +457,73 @@ func (e *Engine) Execute(ctx context.Context, definition PipelineDefinition, ini
 		GlobalData:     initialData,
 		Status:         StatusRunning,
 	}
+}
+
+// Resume продолжает выполнение из последнего checkpoint'а
+//
+// Шаги из CompletedSteps не выполняются повторно, их выходные данные
+// уже содержатся в GlobalData. Завершенное или компенсированное выполнение
+// возвращается как есть, для StatusCompensationFailed повторяются только
+// неуспешные компенсации.
+func (e *Engine) Resume(ctx context.Context, executionID string) (*ExecutionContext, error) {
+	store := e.executionStore()
+	if store == nil {
+		return nil, errors.New("execution store is not configured")
+	}
+	
+	execCtx, err := store.Get(ctx, executionID)
+	if err != nil {
+		return nil, err
+	}
+	
+	switch execCtx.Status {
+	case StatusCompleted, StatusCompensated:
+		return execCtx, nil
+	case StatusCompensationFailed:
+		return e.resumeCompensation(ctx, execCtx)
+	}
+	
+	e.logger.Info("Resuming pipeline execution",
+		zap.String("pipeline", execCtx.Pipeline.Name),
+		zap.String("execution_id", execCtx.ID),
+		zap.Strings("completed_steps", execCtx.CompletedSteps))
+	
+	if execCtx.Results == nil {
+		execCtx.Results = make(map[string]*StepResult)
+	}
+	if execCtx.GlobalData == nil {
+		execCtx.GlobalData = make(map[string]interface{})
+	}
+	execCtx.Status = StatusRunning
+	execCtx.Error = nil
+	execCtx.CompletedAt = nil
+	
+	return e.dispatch(ctx, execCtx)
+}
+
+// resumeCompensation повторяет компенсации, которые не удались ранее
+func (e *Engine) resumeCompensation(ctx context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+	e.logger.Info("Retrying pipeline compensation",
+		zap.String("pipeline", execCtx.Pipeline.Name),
+		zap.String("execution_id", execCtx.ID))
+	
+	e.applyCompensation(ctx, execCtx)
+	
+	var err error
+	if failed := failedCompensations(execCtx); len(failed) > 0 {
+		err = fmt.Errorf("compensation failed for steps %v", failed)
+	}
+	
+	if saveErr := e.checkpoint(ctx, execCtx); saveErr != nil && err == nil {
+		err = saveErr
+	}
+	
+	return execCtx, err
+}
+
+// run выполняет пайплайн для подготовленного контекста и сохраняет итог
+func (e *Engine) run(ctx context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+	definition := execCtx.Pipeline
 	
 	// Создаем контекст с таймаутом
 	timeout := definition.Timeout
@@ -400,12 +531,17 @@ func (e *Engine) Execute(ctx context.Context, definition PipelineDefinition, ini
 		timeout = e.config.DefaultTimeout
 	}
 	
+	ctx = withExecution(ctx, execCtx)
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 	
 	// Выполняем пайплайн
+	e.pipelineStarted(definition.Name)
 	startTime := time.Now()
-	err := e.executePipeline(ctxWithTimeout, execCtx)
+	err := e.checkpoint(ctx, execCtx)
+	if err == nil {
+		err = e.executePipeline(ctxWithTimeout, execCtx)
+	}
 	duration := time.Since(startTime)
 	
 	// Завершаем контекст
@@ -419,6 +555,9 @@ func (e *Engine) Execute(ctx context.Context, definition PipelineDefinition, ini
 			zap.String("pipeline", definition.Name),
 			zap.Error(err),
 			zap.Duration("duration", duration))
+		
+		// Отменяем эффекты завершенных шагов
+		e.applyCompensation(ctx, execCtx)
 	} else {
 		execCtx.Status = StatusCompleted
 		e.logger.Info("Pipeline execution completed",
@@ -426,18 +565,75 @@ func (e *Engine) Execute(ctx context.Context, definition PipelineDefinition, ini
 			zap.Duration("duration", duration))
 	}
 	
-	// Записываем метрики
-	if e.metrics != nil {
-		e.metrics.RecordPipelineDuration(definition.Name, duration)
+	// Сохраняем итоговое состояние
+	if saveErr := e.checkpoint(ctx, execCtx); saveErr != nil {
+		e.logger.Error("Failed to save final checkpoint", zap.Error(saveErr))
+		if err == nil {
+			err = saveErr
+		}
 	}
 	
+	// Записываем метрики
+	e.pipelineFinished(definition.Name, execCtx.Status, duration)
+	
 	return execCtx, err
 }
 
+// checkpoint сохраняет состояние выполнения в хранилище (если оно настроено)
+//
+// Сохранение не отменяется вместе с контекстом пайплайна, чтобы
+// таймаут или отмена тоже попадали в хранилище.
+func (e *Engine) checkpoint(ctx context.Context, execCtx *ExecutionContext) error {
+	store := e.executionStore()
+	if store == nil {
+		return nil
+	}
+	
+	if err := store.Save(context.WithoutCancel(ctx), execCtx); err != nil {
+		return fmt.Errorf("failed to save checkpoint: %w", err)
+	}
+	return nil
+}
+
+// executionStore возвращает хранилище выполнений
+func (e *Engine) executionStore() ExecutionStore {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	
+	return e.store
+}
+
 // executePipeline выполняет шаги пайплайна
+//
+// Перед запуском граф зависимостей проверяется на циклы и незарегистрированные
+// шаги. В режиме ModeDAG шаги выполняются по готовности зависимостей (см. executeDAG),
+// иначе - этапами (см. buildStages): одиночный шаг из Steps или целая группа
+// из Parallel, шаги которой запускаются одновременно. Этапы, нарушающие
+// зависимости шагов, отклоняются (см. checkStageOrder).
 func (e *Engine) executePipeline(ctx context.Context, execCtx *ExecutionContext) error {
-	// Выполняем шаги последовательно
-	for _, step := range execCtx.Pipeline.Steps {
+	if err := execCtx.Pipeline.Validate(); err != nil {
+		return fmt.Errorf("invalid pipeline %s: %w", execCtx.Pipeline.Name, err)
+	}
+
+	graph, err := e.buildDependencyGraph(graphTargets(execCtx.Pipeline))
+	if err != nil {
+		return fmt.Errorf("invalid pipeline %s: %w", execCtx.Pipeline.Name, err)
+	}
+	
+	switch execCtx.Pipeline.Mode {
+	case ModeDAG:
+		return e.executeDAG(ctx, execCtx, graph)
+	case "", ModeSequential:
+	default:
+		return fmt.Errorf("unknown execution mode: %s", execCtx.Pipeline.Mode)
+	}
+	
+	stages := buildStages(execCtx.Pipeline)
+	if err := graph.checkStageOrder(stages); err != nil {
+		return fmt.Errorf("invalid pipeline %s: %w", execCtx.Pipeline.Name, err)
+	}
+	
+	for _, stage := range stages {
 		// Проверяем контекст
 		select {
 		case <-ctx.Done():
@@ -445,48 +641,196 @@ func (e *Engine) executePipeline(ctx context.Context, execCtx *ExecutionContext)
 		default:
 		}
 		
-		// Проверяем условие выполнения шага
+		var err error
+		if len(stage) == 1 {
+			err = e.executeSequentialStep(ctx, stage[0], execCtx)
+		} else {
+			err = e.executeParallelGroup(ctx, stage, execCtx)
+		}
+		
+		if err != nil {
+			return err
+		}
+	}
+	
+	return nil
+}
+
+// executeSequentialStep выполняет одиночный шаг этапа
+func (e *Engine) executeSequentialStep(ctx context.Context, step string, execCtx *ExecutionContext) error {
+	// Шаг уже выполнен до Resume
+	if execCtx.isStepCompleted(step) {
+		return nil
+	}
+	
+	// Проверяем условие выполнения шага
+	if !e.shouldExecuteStep(step, execCtx) {
+		e.logger.Debug("Skipping step due to condition",
+			zap.String("step", step))
+		return nil
+	}
+	
+	// Выполняем шаг
+	execCtx.CurrentStep = step
+	result, err := e.executeStep(ctx, step, e.newStepData(step, execCtx), execCtx.Pipeline)
+	
+	return e.completeStep(ctx, step, result, err, execCtx)
+}
+
+// stepOutcome результат шага, выполненного в отдельной горутине
+type stepOutcome struct {
+	result *StepResult
+	err    error
+}
+
+// executeParallelGroup выполняет группу шагов одновременно
+//
+// ПРАВИЛА:
+// - Условия и входные данные вычисляются до запуска, поэтому все шаги
+//   группы видят одно и то же состояние GlobalData
+// - Результаты сливаются в GlobalData в порядке объявления шагов в группе,
+//   при совпадении ключей побеждает шаг, объявленный позже
+// - Если ошибка шага останавливает пайплайн (см. ErrorHandler.ShouldContinue),
+//   остальные шаги группы отменяются через контекст
+func (e *Engine) executeParallelGroup(ctx context.Context, group []string, execCtx *ExecutionContext) error {
+	steps := make([]string, 0, len(group))
+	for _, step := range group {
+		if execCtx.isStepCompleted(step) {
+			continue
+		}
 		if !e.shouldExecuteStep(step, execCtx) {
 			e.logger.Debug("Skipping step due to condition",
 				zap.String("step", step))
 			continue
 		}
+		steps = append(steps, step)
+	}
+	
+	if len(steps) == 0 {
+		return nil
+	}
+	
+	e.logger.Debug("Executing parallel group", zap.Strings("steps", steps))
+	execCtx.CurrentStep = strings.Join(steps, ",")
+	
+	groupCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	
+	outcomes := make([]stepOutcome, len(steps))
+	var wg sync.WaitGroup
+	
+	for i, step := range steps {
+		stepData := e.newStepData(step, execCtx)
 		
-		// Выполняем шаг
-		execCtx.CurrentStep = step
-		result, err := e.executeStep(ctx, step, execCtx)
-		
-		// Сохраняем результат
-		execCtx.Results[step] = result
-		
-		if err != nil {
-			// Обрабатываем ошибку
-			if e.errorHandler != nil {
-				if handleErr := e.errorHandler.Handle(ctx, step, err); handleErr != nil {
-					e.logger.Error("Error handler failed", zap.Error(handleErr))
-				}
-				
-				// Проверяем, нужно ли продолжить
-				if !e.errorHandler.ShouldContinue(err) {
-					return fmt.Errorf("pipeline stopped at step %s: %w", step, err)
-				}
-			} else {
-				return fmt.Errorf("step %s failed: %w", step, err)
+		wg.Add(1)
+		go func(i int, step string, stepData *StepData) {
+			defer wg.Done()
+			
+			result, err := e.executeStep(groupCtx, step, stepData, execCtx.Pipeline)
+			outcomes[i] = stepOutcome{result: result, err: err}
+			
+			// Фатальная ошибка - нет смысла ждать остальные шаги группы
+			if err != nil && !e.shouldContinue(err) {
+				cancel()
+			}
+		}(i, step, stepData)
+	}
+	
+	wg.Wait()
+	
+	// Группу отменила фатальная ошибка одного из шагов (а не внешний контекст):
+	// шаги, прерванные этой отменой, не считаются упавшими, где бы в группе
+	// ни был объявлен виновник
+	siblingFailed := false
+	if ctx.Err() == nil {
+		for _, outcome := range outcomes {
+			if outcome.err != nil && !errors.Is(outcome.err, context.Canceled) && !e.shouldContinue(outcome.err) {
+				siblingFailed = true
+				break
 			}
 		}
+	}
+	
+	// Сливаем результаты в детерминированном порядке
+	var firstErr error
+	for i, step := range steps {
+		outcome := outcomes[i]
 		
-		// Добавляем в завершенные шаги
-		execCtx.CompletedSteps = append(execCtx.CompletedSteps, step)
+		// Шаг прерван из-за ошибки соседа - только сохраняем результат
+		if siblingFailed && errors.Is(outcome.err, context.Canceled) {
+			execCtx.Results[step] = outcome.result
+			continue
+		}
 		
-		// Обновляем глобальные данные из результата
-		e.updateGlobalData(execCtx, result)
+		if err := e.completeStep(ctx, step, outcome.result, outcome.err, execCtx); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
 	
-	return nil
+	return firstErr
+}
+
+// completeStep сохраняет результат шага и применяет политику обработки ошибок
+func (e *Engine) completeStep(ctx context.Context, step string, result *StepResult, err error, execCtx *ExecutionContext) error {
+	// Сохраняем результат
+	execCtx.Results[step] = result
+	
+	if err != nil {
+		// Обрабатываем ошибку
+		if e.errorHandler != nil {
+			if handleErr := e.errorHandler.Handle(ctx, step, err); handleErr != nil {
+				e.logger.Error("Error handler failed", zap.Error(handleErr))
+			}
+			
+			// Проверяем, нужно ли продолжить
+			if !e.errorHandler.ShouldContinue(err) {
+				return fmt.Errorf("pipeline stopped at step %s: %w", step, err)
+			}
+		} else {
+			return fmt.Errorf("step %s failed: %w", step, err)
+		}
+	}
+	
+	// Добавляем в завершенные шаги
+	execCtx.CompletedSteps = append(execCtx.CompletedSteps, step)
+	
+	// Обновляем глобальные данные из результата
+	e.updateGlobalData(execCtx, result)
+	
+	// Фиксируем прогресс, чтобы Resume не выполнил шаг повторно
+	return e.checkpoint(ctx, execCtx)
+}
+
+// shouldContinue определяет, продолжится ли пайплайн после ошибки
+func (e *Engine) shouldContinue(err error) bool {
+	return e.errorHandler != nil && e.errorHandler.ShouldContinue(err)
+}
+
+// newStepData подготавливает данные для шага
+//
+// Context получает копию GlobalData, чтобы шаги, выполняемые параллельно,
+// не разделяли одну и ту же map.
+func (e *Engine) newStepData(stepName string, execCtx *ExecutionContext) *StepData {
+	stepContext := make(map[string]interface{}, len(execCtx.GlobalData))
+	for k, v := range execCtx.GlobalData {
+		stepContext[k] = v
+	}
+	
+	return &StepData{
+		ID:        generateID(),
+		Step:      stepName,
+		Input:     e.prepareStepInput(stepName, execCtx),
+		Context:   stepContext,
+		Metadata:  execCtx.Pipeline.Metadata,
+		StartedAt: time.Now(),
+	}
 }
 
 // executeStep выполняет отдельный шаг
-func (e *Engine) executeStep(ctx context.Context, stepName string, execCtx *ExecutionContext) (*StepResult, error) {
+//
+// Не обращается к ExecutionContext, поэтому безопасен для вызова
+// из нескольких горутин одновременно.
+func (e *Engine) executeStep(ctx context.Context, stepName string, stepData *StepData, definition PipelineDefinition) (*StepResult, error) {
 	e.logger.Debug("Executing step", zap.String("step", stepName))
 	
 	// Получаем обработчик шага
@@ -498,16 +842,6 @@ func (e *Engine) executeStep(ctx context.Context, stepName string, execCtx *Exec
 		return nil, fmt.Errorf("step handler not found: %s", stepName)
 	}
 	
-	// Подготавливаем данные для шага
-	stepData := &StepData{
-		ID:        generateID(),
-		Step:      stepName,
-		Input:     e.prepareStepInput(stepName, execCtx),
-		Context:   execCtx.GlobalData,
-		Metadata:  execCtx.Pipeline.Metadata,
-		StartedAt: time.Now(),
-	}
-	
 	// Валидируем входные данные
 	if err := handler.Validate(stepData); err != nil {
 		return &StepResult{
@@ -520,26 +854,22 @@ func (e *Engine) executeStep(ctx context.Context, stepName string, execCtx *Exec
 	}
 	
 	// Выполняем middleware Before
-	for _, mw := range e.middleware {
-		if err := mw.Before(ctx, stepName, stepData); err != nil {
-			return nil, fmt.Errorf("middleware before failed: %w", err)
-		}
+	ctx, err := e.runBeforeMiddleware(ctx, stepName, stepData)
+	if err != nil {
+		return nil, err
 	}
 	
-	// Создаем контекст с таймаутом для шага
-	stepTimeout := handler.Timeout()
-	if stepTimeout == 0 {
-		stepTimeout = e.config.DefaultTimeout
-	}
+	e.stepStarted(definition.Name, stepName)
+	defer e.stepFinished(definition.Name, stepName)
 	
-	ctxWithTimeout, cancel := context.WithTimeout(ctx, stepTimeout)
+	// Создаем контекст с таймаутом для шага
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, e.stepTimeout(stepName, handler, definition))
 	defer cancel()
 	
 	// Выполняем шаг с повторами
 	var result *StepResult
-	var err error
 	
-	retryConfig := execCtx.Pipeline.Retry
+	retryConfig := definition.Retry
 	if retryConfig.MaxAttempts == 0 {
 		retryConfig = e.config.RetryConfig
 	}
@@ -549,6 +879,13 @@ func (e *Engine) executeStep(ctx context.Context, stepName string, execCtx *Exec
 		result, err = handler.Execute(ctxWithTimeout, stepData)
 		duration := time.Since(startTime)
 		
+		// Шаг может сообщить о неуспехе через StepResult.Error, не возвращая error
+		reportedByResult := false
+		if err == nil && result != nil && result.Error != nil {
+			err = result.Error
+			reportedByResult = true
+		}
+		
 		if result == nil {
 			result = &StepResult{
 				Step:        stepName,
@@ -561,17 +898,16 @@ func (e *Engine) executeStep(ctx context.Context, stepName string, execCtx *Exec
 		}
 		
 		// Записываем метрики
-		if e.metrics != nil {
-			e.metrics.RecordStepDuration(stepName, duration)
-			if err == nil {
-				e.metrics.RecordStepSuccess(stepName)
-			} else {
-				e.metrics.RecordStepError(stepName, err)
-			}
+		e.recordStepAttempt(definition.Name, stepName, duration, err)
+		
+		// Для ошибок из StepResult учитываем и решение самого шага
+		canRetry := err != nil && handler.CanRetry(err)
+		if reportedByResult {
+			canRetry = canRetry && result.Retryable
 		}
 		
 		// Если успешно или нельзя повторить - выходим
-		if err == nil || !handler.CanRetry(err) || attempt == retryConfig.MaxAttempts {
+		if err == nil || !canRetry || attempt == retryConfig.MaxAttempts {
 			break
 		}
 		
@@ -587,9 +923,17 @@ func (e *Engine) executeStep(ctx context.Context, stepName string, execCtx *Exec
 		select {
 		case <-ctxWithTimeout.Done():
 			timer.Stop()
-			return result, ctxWithTimeout.Err()
+			err = ctxWithTimeout.Err()
 		case <-timer.C:
 		}
+		if ctxWithTimeout.Err() != nil {
+			break
+		}
+	}
+	
+	// Результат всегда содержит ошибку шага, чтобы middleware After видел неуспех
+	if err != nil && result.Error == nil {
+		result.Error = err
 	}
 	
 	// Выполняем middleware After
@@ -611,79 +955,79 @@ func (e *Engine) executeStep(ctx context.Context, stepName string, execCtx *Exec
 	return result, err
 }
 
-// 🔧 ВСПОМОГАТЕЛЬНЫЕ МЕТОДЫ
-
-// shouldExecuteStep проверяет, нужно ли выполнить шаг
-func (e *Engine) shouldExecuteStep(step string, execCtx *ExecutionContext) bool {
-	condition, exists := execCtx.Pipeline.Conditional[step]
-	if !exists {
-		return true
-	}
-	
-	return e.evaluateCondition(condition, execCtx)
-}
-
-// evaluateCondition оценивает условие
-func (e *Engine) evaluateCondition(condition Condition, execCtx *ExecutionContext) bool {
-	// Получаем значение поля
-	fieldValue := e.getFieldValue(condition.Field, execCtx)
-	
-	// Сравниваем с условием
-	result := e.compareValues(fieldValue, condition.Operator, condition.Value)
-	
-	// Обрабатываем вложенные условия
-	if len(condition.Nested) > 0 {
-		nestedResults := make([]bool, len(condition.Nested))
-		for i, nested := range condition.Nested {
-			nestedResults[i] = e.evaluateCondition(nested, execCtx)
+// runBeforeMiddleware выполняет Before всех middleware
+//
+// ContextMiddleware может дополнить контекст шага. Если middleware вернул
+// ошибку, уже выполненные middleware получают OnError, чтобы закрыть
+// открытые в Before ресурсы (например, span'ы).
+func (e *Engine) runBeforeMiddleware(ctx context.Context, stepName string, stepData *StepData) (context.Context, error) {
+	for i, mw := range e.middleware {
+		var err error
+		if contextual, ok := mw.(ContextMiddleware); ok {
+			var stepCtx context.Context
+			stepCtx, err = contextual.BeforeContext(ctx, stepName, stepData)
+			if err == nil {
+				ctx = stepCtx
+			}
+		} else {
+			err = mw.Before(ctx, stepName, stepData)
 		}
 		
-		// Применяем логический оператор
-		switch condition.Logic {
-		case "and":
-			for _, nr := range nestedResults {
-				result = result && nr
-			}
-		case "or":
-			for _, nr := range nestedResults {
-				result = result || nr
+		if err != nil {
+			err = fmt.Errorf("middleware before failed: %w", err)
+			for _, started := range e.middleware[:i] {
+				if mwErr := started.OnError(ctx, stepName, err); mwErr != nil {
+					e.logger.Error("Middleware onError failed", zap.Error(mwErr))
+				}
 			}
+			return ctx, err
 		}
 	}
 	
-	return result
+	return ctx, nil
 }
 
-// getFieldValue получает значение поля из контекста
-func (e *Engine) getFieldValue(field string, execCtx *ExecutionContext) interface{} {
-	// Поиск в глобальных данных
-	if val, exists := execCtx.GlobalData[field]; exists {
-		return val
+// 🔧 ВСПОМОГАТЕЛЬНЫЕ МЕТОДЫ
+
+// buildStages разбивает определение пайплайна на этапы выполнения
+//
+// Пример: Steps = [validate, payment, inventory, notify],
+// Parallel = [[payment, inventory]] дает этапы
+// [validate] → [payment, inventory] → [notify]
+func buildStages(definition PipelineDefinition) [][]string {
+	groupOf := make(map[string]int)
+	for i, group := range definition.Parallel {
+		for _, step := range group {
+			if _, exists := groupOf[step]; !exists {
+				groupOf[step] = i
+			}
+		}
 	}
 	
-	// Поиск в результатах шагов
-	for _, result := range execCtx.Results {
-		if val, exists := result.Output[field]; exists {
-			return val
+	stages := make([][]string, 0, len(definition.Steps)+len(definition.Parallel))
+	scheduled := make(map[int]bool, len(definition.Parallel))
+	
+	for _, step := range definition.Steps {
+		groupIdx, inGroup := groupOf[step]
+		if !inGroup {
+			stages = append(stages, []string{step})
+			continue
+		}
+		
+		if !scheduled[groupIdx] {
+			scheduled[groupIdx] = true
+			stages = append(stages, definition.Parallel[groupIdx])
 		}
 	}
 	
-	return nil
-}
-
-// compareValues сравнивает значения
-func (e *Engine) compareValues(fieldValue interface{}, operator string, conditionValue interface{}) bool {
-	switch operator {
-	case "eq":
-		return fieldValue == conditionValue
-	case "ne":
-		return fieldValue != conditionValue
-	case "exists":
-		return fieldValue != nil
-	// Дополнительные операторы можно добавить здесь
-	default:
-		return false
+	// Группы, не упомянутые в Steps, выполняются в конце
+	for i, group := range definition.Parallel {
+		if !scheduled[i] && len(group) > 0 {
+			stages = append(stages, group)
+		}
 	}
+	
+	return stages
 }
 
 // prepareStepInput подготавливает входные данные для шага
@@ -714,6 +1058,19 @@ func (e *Engine) updateGlobalData(execCtx *ExecutionContext, result *StepResult)
 	}
 }
 
+// stepTimeout возвращает таймаут шага: из определения, от обработчика или по умолчанию
+func (e *Engine) stepTimeout(stepName string, handler StepHandler, definition PipelineDefinition) time.Duration {
+	if timeout, exists := definition.StepTimeouts[stepName]; exists && timeout > 0 {
+		return timeout
+	}
+	
+	if timeout := handler.Timeout(); timeout > 0 {
+		return timeout
+	}
+	
+	return e.config.DefaultTimeout
+}
+
 // calculateRetryDelay вычисляет задержку для повтора
 func (e *Engine) calculateRetryDelay(attempt int, config RetryConfig) time.Duration {
 	delay := config.BaseDelay
@@ -736,7 +1093,20 @@ func (e *Engine) calculateRetryDelay(attempt int, config RetryConfig) time.Durat
 	return delay
 }
 
+// isStepCompleted проверяет, завершен ли шаг в рамках выполнения
+func (c *ExecutionContext) isStepCompleted(step string) bool {
+	for _, completed := range c.CompletedSteps {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
 // generateID генерирует уникальный ID
+//
+// UnixNano не годится: при всплеске Submit ID совпадали бы,
+// и выполнения перезаписывали бы друг друга в ExecutionStore.
 func generateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	return uuid.NewString()
 }
\ No newline at end of file
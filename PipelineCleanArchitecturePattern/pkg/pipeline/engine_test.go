@@ -0,0 +1,187 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// testStep настраиваемый шаг для тестов движка
+type testStep struct {
+	name    string
+	deps    []string
+	output  map[string]interface{}
+	err     error
+	execute func(ctx context.Context, data *StepData) error
+
+	mu    sync.Mutex
+	calls int
+}
+
+func (s *testStep) Execute(ctx context.Context, data *StepData) (*StepResult, error) {
+	s.mu.Lock()
+	s.calls++
+	s.mu.Unlock()
+
+	if s.execute != nil {
+		if err := s.execute(ctx, data); err != nil {
+			return nil, err
+		}
+	}
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &StepResult{Step: s.name, Success: true, Output: s.output}, nil
+}
+
+func (s *testStep) Name() string                  { return s.name }
+func (s *testStep) Validate(data *StepData) error { return nil }
+func (s *testStep) Timeout() time.Duration        { return time.Second }
+func (s *testStep) Dependencies() []string        { return s.deps }
+func (s *testStep) CanRetry(err error) bool       { return false }
+func (s *testStep) Calls() int                    { s.mu.Lock(); defer s.mu.Unlock(); return s.calls }
+
+func newTestEngine(t *testing.T, steps ...*testStep) *Engine {
+	t.Helper()
+
+	engine := NewEngine(zap.NewNop(), Config{
+		DefaultTimeout: 5 * time.Second,
+		RetryConfig:    RetryConfig{MaxAttempts: 1},
+	})
+	for _, step := range steps {
+		if err := engine.RegisterStep(step.name, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return engine
+}
+
+// barrier блокирует шаг, пока все n шагов не дойдут до него
+func barrier(n int) func(ctx context.Context, data *StepData) error {
+	var wg sync.WaitGroup
+	wg.Add(n)
+	return func(ctx context.Context, data *StepData) error {
+		wg.Done()
+		done := make(chan struct{})
+		go func() { wg.Wait(); close(done) }()
+		select {
+		case <-done:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func TestExecuteParallelGroupRunsConcurrently(t *testing.T) {
+	wait := barrier(2)
+	inventory := &testStep{name: "check_inventory", execute: wait,
+		output: map[string]interface{}{"inventory_ok": true, "checked_by": "inventory"}}
+	payment := &testStep{name: "validate_payment_method", execute: wait,
+		output: map[string]interface{}{"payment_ok": true, "checked_by": "payment"}}
+	validate := &testStep{name: "validate_order", output: map[string]interface{}{"is_valid": true}}
+	notify := &testStep{name: "send_notifications"}
+
+	engine := newTestEngine(t, validate, inventory, payment, notify)
+	definition := PipelineDefinition{
+		Name:     "order_processing",
+		Steps:    []string{"validate_order", "check_inventory", "send_notifications"},
+		Parallel: [][]string{{"check_inventory", "validate_payment_method"}},
+		Timeout:  2 * time.Second,
+	}
+
+	execCtx, err := engine.Execute(context.Background(), definition, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	want := []string{"validate_order", "check_inventory", "validate_payment_method", "send_notifications"}
+	if len(execCtx.CompletedSteps) != len(want) {
+		t.Fatalf("CompletedSteps = %v, want %v", execCtx.CompletedSteps, want)
+	}
+	for i := range want {
+		if execCtx.CompletedSteps[i] != want[i] {
+			t.Fatalf("CompletedSteps = %v, want %v", execCtx.CompletedSteps, want)
+		}
+	}
+
+	// Последний шаг группы побеждает при конфликте ключей
+	if got := execCtx.GlobalData["checked_by"]; got != "payment" {
+		t.Errorf("GlobalData[checked_by] = %v, want payment", got)
+	}
+	if execCtx.GlobalData["inventory_ok"] != true || execCtx.GlobalData["payment_ok"] != true {
+		t.Errorf("GlobalData = %v, want outputs of both parallel steps", execCtx.GlobalData)
+	}
+}
+
+func TestExecuteParallelGroupFailureCancelsSiblings(t *testing.T) {
+	// Виновник отмены объявлен в группе и первым, и последним: прерванный
+	// сосед не должен стать ошибкой пайплайна
+	groups := [][]string{
+		{"check_inventory", "validate_payment_method"},
+		{"validate_payment_method", "check_inventory"},
+	}
+
+	for _, group := range groups {
+		failing := &testStep{name: "check_inventory", err: errors.New("warehouse unavailable")}
+		slow := &testStep{name: "validate_payment_method", execute: func(ctx context.Context, data *StepData) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}}
+		notify := &testStep{name: "send_notifications"}
+
+		engine := newTestEngine(t, failing, slow, notify)
+		definition := PipelineDefinition{
+			Name:     "order_processing",
+			Steps:    []string{"send_notifications"},
+			Parallel: [][]string{group},
+			Timeout:  2 * time.Second,
+		}
+
+		execCtx, err := engine.Execute(context.Background(), definition, nil)
+		if err == nil {
+			t.Fatalf("group %v: Execute() error = nil, want failure of check_inventory", group)
+		}
+		if want := "step check_inventory failed: warehouse unavailable"; !strings.Contains(err.Error(), want) {
+			t.Errorf("group %v: Execute() error = %q, want it to contain %q", group, err, want)
+		}
+		if execCtx.Status != StatusFailed {
+			t.Errorf("group %v: Status = %s, want %s", group, execCtx.Status, StatusFailed)
+		}
+		if notify.Calls() != 1 {
+			t.Errorf("group %v: send_notifications calls = %d, want 1 (it runs before the trailing group)", group, notify.Calls())
+		}
+		if len(execCtx.CompletedSteps) != 1 {
+			t.Errorf("group %v: CompletedSteps = %v, want only send_notifications", group, execCtx.CompletedSteps)
+		}
+	}
+}
+
+func TestBuildStages(t *testing.T) {
+	definition := PipelineDefinition{
+		Steps:    []string{"a", "b", "c", "d"},
+		Parallel: [][]string{{"c", "b"}, {"x", "y"}},
+	}
+
+	got := buildStages(definition)
+	want := [][]string{{"a"}, {"c", "b"}, {"d"}, {"x", "y"}}
+
+	if len(got) != len(want) {
+		t.Fatalf("buildStages() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if len(got[i]) != len(want[i]) {
+			t.Fatalf("buildStages() = %v, want %v", got, want)
+		}
+		for j := range want[i] {
+			if got[i][j] != want[i][j] {
+				t.Fatalf("buildStages() = %v, want %v", got, want)
+			}
+		}
+	}
+}
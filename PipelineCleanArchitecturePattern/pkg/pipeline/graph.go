@@ -0,0 +1,321 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// 🕸️ ГРАФ ЗАВИСИМОСТЕЙ ШАГОВ
+//
+// ============================================================================
+// DAG-ПЛАНИРОВАНИЕ:
+// ============================================================================
+//
+// Каждый StepHandler сообщает о своих зависимостях через Dependencies().
+// Движок строит из них ориентированный граф и:
+//
+// 1. 🔍 ВАЛИДИРУЕТ его при каждом Execute:
+//    - все шаги и их зависимости зарегистрированы
+//    - в графе нет циклов
+//    - в режиме ModeSequential каждая зависимость, упомянутая в определении,
+//      выполняется на более раннем этапе, чем зависимый шаг
+//
+// 2. 🚀 В РЕЖИМЕ ModeDAG выполняет шаги по готовности:
+//    - в определении достаточно перечислить целевые шаги,
+//      их зависимости добавляются автоматически
+//    - шаг запускается, как только завершились все его зависимости
+//    - независимые ветки выполняются одновременно
+//
+// ПРИМЕР: Steps = [send_notifications] в режиме ModeDAG выполнит
+// validate_order → process_payment → check_inventory → send_notifications
+//
+// ============================================================================
+
+// ExecutionMode режим планирования шагов пайплайна
+type ExecutionMode string
+
+const (
+	// ModeSequential шаги выполняются в порядке Steps (с учетом групп Parallel)
+	ModeSequential ExecutionMode = "sequential"
+
+	// ModeDAG порядок определяется графом зависимостей StepHandler.Dependencies()
+	ModeDAG ExecutionMode = "dag"
+)
+
+// Ошибки валидации графа зависимостей
+var (
+	ErrStepNotRegistered = errors.New("step is not registered")
+	ErrMissingDependency = errors.New("missing step dependency")
+	ErrDependencyCycle   = errors.New("step dependency cycle")
+	ErrDependencyOrder   = errors.New("step is scheduled before its dependency")
+)
+
+// dependencyGraph граф зависимостей шагов пайплайна
+type dependencyGraph struct {
+	// order шаги в топологическом порядке (зависимости раньше зависимых)
+	order []string
+
+	// dependencies зависимости каждого шага
+	dependencies map[string][]string
+
+	// dependents шаги, зависящие от данного, в топологическом порядке
+	dependents map[string][]string
+}
+
+// buildDependencyGraph строит граф для целевых шагов и их транзитивных зависимостей
+//
+// Порядок обхода детерминирован: цели обходятся в порядке объявления,
+// зависимости - в порядке, который вернул Dependencies().
+func (e *Engine) buildDependencyGraph(targets []string) (*dependencyGraph, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	graph := &dependencyGraph{
+		order:        make([]string, 0, len(targets)),
+		dependencies: make(map[string][]string),
+		dependents:   make(map[string][]string),
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make(map[string]int)
+	path := make([]string, 0)
+
+	var visit func(step, requiredBy string) error
+	visit = func(step, requiredBy string) error {
+		switch state[step] {
+		case visited:
+			return nil
+		case visiting:
+			// Восстанавливаем цикл из текущего пути обхода
+			start := 0
+			for i, s := range path {
+				if s == step {
+					start = i
+					break
+				}
+			}
+			cycle := append(append([]string{}, path[start:]...), step)
+			return fmt.Errorf("%w: %s", ErrDependencyCycle, strings.Join(cycle, " -> "))
+		}
+
+		handler, exists := e.stepRegistry[step]
+		if !exists {
+			if requiredBy != "" {
+				return fmt.Errorf("%w: step %s depends on %s", ErrMissingDependency, requiredBy, step)
+			}
+			return fmt.Errorf("%w: %s", ErrStepNotRegistered, step)
+		}
+
+		state[step] = visiting
+		path = append(path, step)
+
+		deps := handler.Dependencies()
+		for _, dep := range deps {
+			if err := visit(dep, step); err != nil {
+				return err
+			}
+		}
+
+		path = path[:len(path)-1]
+		state[step] = visited
+		graph.dependencies[step] = deps
+		graph.order = append(graph.order, step)
+		return nil
+	}
+
+	for _, target := range targets {
+		if err := visit(target, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, step := range graph.order {
+		for _, dep := range graph.dependencies[step] {
+			graph.dependents[dep] = append(graph.dependents[dep], step)
+		}
+	}
+
+	return graph, nil
+}
+
+// checkStageOrder проверяет, что этапы последовательного режима не нарушают граф
+//
+// Зависимость, которой нет в определении, не проверяется: ее выполняют
+// отдельно (так orderservice запускает по одному шагу пайплайна).
+// Шаг не может зависеть от шага своей же группы Parallel.
+func (g *dependencyGraph) checkStageOrder(stages [][]string) error {
+	stageOf := make(map[string]int)
+	for i, stage := range stages {
+		for _, step := range stage {
+			if _, exists := stageOf[step]; !exists {
+				stageOf[step] = i
+			}
+		}
+	}
+
+	for i, stage := range stages {
+		for _, step := range stage {
+			for _, dep := range g.dependencies[step] {
+				if depStage, listed := stageOf[dep]; listed && depStage >= i {
+					return fmt.Errorf("%w: step %s depends on %s", ErrDependencyOrder, step, dep)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// graphTargets возвращает все шаги, упомянутые в определении пайплайна
+func graphTargets(definition PipelineDefinition) []string {
+	targets := make([]string, 0, len(definition.Steps))
+	seen := make(map[string]bool)
+
+	add := func(step string) {
+		if !seen[step] {
+			seen[step] = true
+			targets = append(targets, step)
+		}
+	}
+
+	for _, step := range definition.Steps {
+		add(step)
+	}
+	for _, group := range definition.Parallel {
+		for _, step := range group {
+			add(step)
+		}
+	}
+
+	return targets
+}
+
+// executeDAG выполняет шаги по мере готовности их зависимостей
+//
+// ПРАВИЛА:
+//   - Шаг, пропущенный по условию, считается завершенным для зависимых шагов
+//   - Все обращения к ExecutionContext происходят в этой горутине,
+//     шаги получают подготовленные StepData
+//   - GlobalData пересобирается в топологическом порядке после каждого шага,
+//     поэтому итог не зависит от того, какая из независимых веток финишировала раньше
+func (e *Engine) executeDAG(ctx context.Context, execCtx *ExecutionContext, graph *dependencyGraph) error {
+	dagCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	initialData := make(map[string]interface{}, len(execCtx.GlobalData))
+	for k, v := range execCtx.GlobalData {
+		initialData[k] = v
+	}
+
+	pending := make(map[string]int, len(graph.order))
+	queue := make([]string, 0)
+	for _, step := range graph.order {
+		pending[step] = len(graph.dependencies[step])
+		if pending[step] == 0 {
+			queue = append(queue, step)
+		}
+	}
+
+	// release отмечает шаг завершенным и возвращает шаги, ставшие готовыми
+	release := func(step string) []string {
+		ready := make([]string, 0)
+		for _, dependent := range graph.dependents[step] {
+			pending[dependent]--
+			if pending[dependent] == 0 {
+				ready = append(ready, dependent)
+			}
+		}
+		return ready
+	}
+
+	type dagOutcome struct {
+		step string
+		stepOutcome
+	}
+
+	done := make(chan dagOutcome)
+	running := 0
+	var fatalErr error
+
+	for {
+		for len(queue) > 0 && fatalErr == nil {
+			step := queue[0]
+			queue = queue[1:]
+
+			// Шаг уже выполнен до Resume
+			if execCtx.isStepCompleted(step) {
+				queue = append(queue, release(step)...)
+				continue
+			}
+
+			if !e.shouldExecuteStep(step, execCtx) {
+				e.logger.Debug("Skipping step due to condition",
+					zap.String("step", step))
+				queue = append(queue, release(step)...)
+				continue
+			}
+
+			execCtx.CurrentStep = step
+			stepData := e.newStepData(step, execCtx)
+			running++
+
+			go func(step string, stepData *StepData) {
+				result, err := e.executeStep(dagCtx, step, stepData, execCtx.Pipeline)
+				done <- dagOutcome{step: step, stepOutcome: stepOutcome{result: result, err: err}}
+			}(step, stepData)
+		}
+
+		if running == 0 {
+			break
+		}
+
+		outcome := <-done
+		running--
+
+		// После фатальной ошибки только дожидаемся уже запущенных шагов
+		if fatalErr != nil {
+			execCtx.Results[outcome.step] = outcome.result
+			continue
+		}
+
+		if err := e.completeStep(ctx, outcome.step, outcome.result, outcome.err, execCtx); err != nil {
+			fatalErr = err
+			cancel()
+			continue
+		}
+
+		e.rebuildGlobalData(execCtx, initialData, graph.order)
+		queue = append(queue, release(outcome.step)...)
+	}
+
+	return fatalErr
+}
+
+// rebuildGlobalData собирает GlobalData из начальных данных и выходов
+// завершенных шагов в топологическом порядке
+func (e *Engine) rebuildGlobalData(execCtx *ExecutionContext, initialData map[string]interface{}, order []string) {
+	completed := make(map[string]bool, len(execCtx.CompletedSteps))
+	for _, step := range execCtx.CompletedSteps {
+		completed[step] = true
+	}
+
+	globalData := make(map[string]interface{}, len(initialData))
+	for k, v := range initialData {
+		globalData[k] = v
+	}
+
+	execCtx.GlobalData = globalData
+	for _, step := range order {
+		if completed[step] {
+			e.updateGlobalData(execCtx, execCtx.Results[step])
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestExecuteDAGRunsIndependentBranchesConcurrently(t *testing.T) {
+	wait := barrier(2)
+	validate := &testStep{name: "validate_order", output: map[string]interface{}{"is_valid": true}}
+	payment := &testStep{name: "process_payment", deps: []string{"validate_order"}, execute: wait,
+		output: map[string]interface{}{"payment_status": "succeeded", "source": "payment"}}
+	inventory := &testStep{name: "check_inventory", deps: []string{"validate_order"}, execute: wait,
+		output: map[string]interface{}{"all_available": true, "source": "inventory"}}
+	notify := &testStep{name: "send_notifications", deps: []string{"process_payment", "check_inventory"},
+		execute: func(ctx context.Context, data *StepData) error {
+			if data.Input["payment_status"] != "succeeded" || data.Input["all_available"] != true {
+				return errors.New("dependencies outputs are not visible")
+			}
+			return nil
+		}}
+
+	engine := newTestEngine(t, validate, payment, inventory, notify)
+	definition := PipelineDefinition{
+		Name:    "order_processing",
+		Mode:    ModeDAG,
+		Steps:   []string{"send_notifications"},
+		Timeout: 2 * time.Second,
+	}
+
+	execCtx, err := engine.Execute(context.Background(), definition, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if len(execCtx.CompletedSteps) != 4 {
+		t.Fatalf("CompletedSteps = %v, want all 4 steps", execCtx.CompletedSteps)
+	}
+	if execCtx.CompletedSteps[0] != "validate_order" || execCtx.CompletedSteps[3] != "send_notifications" {
+		t.Errorf("CompletedSteps = %v, want validate_order first and send_notifications last", execCtx.CompletedSteps)
+	}
+
+	// Конфликт ключей решается топологическим порядком, а не временем завершения
+	if got := execCtx.GlobalData["source"]; got != "inventory" {
+		t.Errorf("GlobalData[source] = %v, want inventory", got)
+	}
+}
+
+func TestExecuteDAGSkipsConditionalStepWithoutBlockingDependents(t *testing.T) {
+	validate := &testStep{name: "validate_order"}
+	fraud := &testStep{name: "fraud_check", deps: []string{"validate_order"}}
+	payment := &testStep{name: "process_payment", deps: []string{"fraud_check"}}
+
+	engine := newTestEngine(t, validate, fraud, payment)
+	definition := PipelineDefinition{
+		Name:  "order_processing",
+		Mode:  ModeDAG,
+		Steps: []string{"process_payment"},
+		Conditional: map[string]Condition{
+			"fraud_check": {Field: "high_risk", Operator: "exists"},
+		},
+		Timeout: 2 * time.Second,
+	}
+
+	if _, err := engine.Execute(context.Background(), definition, nil); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if fraud.Calls() != 0 || payment.Calls() != 1 {
+		t.Errorf("calls fraud_check = %d, process_payment = %d, want 0 and 1", fraud.Calls(), payment.Calls())
+	}
+}
+
+func TestExecuteRejectsInvalidDependencyGraph(t *testing.T) {
+	tests := []struct {
+		name  string
+		steps []*testStep
+		want  error
+	}{
+		{
+			name: "cycle",
+			steps: []*testStep{
+				{name: "a", deps: []string{"c"}},
+				{name: "b", deps: []string{"a"}},
+				{name: "c", deps: []string{"b"}},
+			},
+			want: ErrDependencyCycle,
+		},
+		{
+			name:  "missing dependency",
+			steps: []*testStep{{name: "c", deps: []string{"unknown"}}},
+			want:  ErrMissingDependency,
+		},
+		{
+			name:  "unregistered target",
+			steps: []*testStep{{name: "a"}},
+			want:  ErrStepNotRegistered,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := newTestEngine(t, tt.steps...)
+			definition := PipelineDefinition{Name: "broken", Mode: ModeDAG, Steps: []string{"c"}, Timeout: time.Second}
+
+			execCtx, err := engine.Execute(context.Background(), definition, nil)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Execute() error = %v, want %v", err, tt.want)
+			}
+			if execCtx.Status != StatusFailed {
+				t.Errorf("Status = %s, want %s", execCtx.Status, StatusFailed)
+			}
+			for _, step := range tt.steps {
+				if step.Calls() != 0 {
+					t.Errorf("step %s executed before graph validation", step.name)
+				}
+			}
+		})
+	}
+}
+
+func TestExecuteSequentialRejectsStepsOrderedBeforeDependencies(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition PipelineDefinition
+		wantErr    bool
+	}{
+		{
+			name:       "dependent listed first",
+			definition: PipelineDefinition{Steps: []string{"check_inventory", "process_payment"}},
+			wantErr:    true,
+		},
+		{
+			name: "dependency in the same parallel group",
+			definition: PipelineDefinition{
+				Steps:    []string{"process_payment"},
+				Parallel: [][]string{{"process_payment", "check_inventory"}},
+			},
+			wantErr: true,
+		},
+		{
+			name:       "dependency order respected",
+			definition: PipelineDefinition{Steps: []string{"process_payment", "check_inventory"}},
+		},
+		{
+			// Шаг пайплайна можно запустить отдельно, без его зависимостей
+			name:       "dependency not listed",
+			definition: PipelineDefinition{Steps: []string{"check_inventory"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			payment := &testStep{name: "process_payment"}
+			inventory := &testStep{name: "check_inventory", deps: []string{"process_payment"}}
+			engine := newTestEngine(t, payment, inventory)
+
+			definition := tt.definition
+			definition.Name = "order_processing"
+			definition.Timeout = time.Second
+
+			_, err := engine.Execute(context.Background(), definition, nil)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrDependencyOrder) {
+				t.Fatalf("Execute() error = %v, want %v", err, ErrDependencyOrder)
+			}
+			if payment.Calls() != 0 || inventory.Calls() != 0 {
+				t.Error("steps executed before order validation")
+			}
+		})
+	}
+}
@@ -0,0 +1,625 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+// 📂 ЗАГРУЗКА ОПРЕДЕЛЕНИЙ ПАЙПЛАЙНОВ ИЗ ФАЙЛОВ
+//
+// ============================================================================
+// ФОРМАТ ФАЙЛА (YAML или JSON, по одному пайплайну на файл):
+// ============================================================================
+//
+//	name: order_processing
+//	description: Полный пайплайн обработки заказа
+//	mode: sequential            # sequential | dag
+//	timeout: 10m
+//	steps: [validate_order, process_payment, check_inventory, send_notifications]
+//	parallel:
+//	  - [check_inventory, validate_payment_method]  # шаги группы не зависят друг от друга
+//	conditional:
+//	  process_payment:
+//	    field: validate_order.total_amount
+//	    operator: gt
+//	    value: 0
+//	step_timeouts:
+//	  process_payment: 30s
+//	retry:
+//	  max_attempts: 3
+//	  base_delay: 2s
+//	  max_delay: 30s
+//	  multiplier: 2
+//	  jitter: true
+//	metadata:
+//	  owner: order_processing_team
+//
+// Длительности задаются строками time.ParseDuration ("500ms", "10m").
+// JSON разбирается тем же парсером, поэтому ошибки в обоих форматах
+// указывают на файл и строку: "pipelines/orders.yaml:7: step is not registered".
+//
+// ============================================================================
+// HOT-RELOAD:
+// ============================================================================
+//
+// DefinitionLoader.Watch опрашивает каталог и перечитывает его при изменении
+// файлов. Перезагрузка атомарна: если хотя бы один файл невалиден, остается
+// предыдущий набор определений, а ошибка пишется в лог.
+//
+// ============================================================================
+
+// definitionExtensions расширения файлов с определениями пайплайнов
+var definitionExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+// DefinitionError ошибка в файле определения пайплайна
+type DefinitionError struct {
+	// File путь к файлу
+	File string
+
+	// Line номер строки (0, если строка неизвестна)
+	Line int
+
+	// Err причина ошибки
+	Err error
+}
+
+// Error возвращает ошибку в формате "файл:строка: причина"
+func (e *DefinitionError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s: %v", e.File, e.Err)
+}
+
+// Unwrap возвращает причину ошибки
+func (e *DefinitionError) Unwrap() error {
+	return e.Err
+}
+
+// DefinitionLoader загружает и хранит определения пайплайнов из файлов
+type DefinitionLoader struct {
+	engine *Engine
+	logger *zap.Logger
+
+	mu          sync.RWMutex
+	definitions map[string]PipelineDefinition
+}
+
+// NewDefinitionLoader создает загрузчик, проверяющий шаги по реестру движка
+func NewDefinitionLoader(engine *Engine, logger *zap.Logger) *DefinitionLoader {
+	return &DefinitionLoader{
+		engine:      engine,
+		logger:      logger,
+		definitions: make(map[string]PipelineDefinition),
+	}
+}
+
+// LoadFile читает и проверяет определение из одного файла
+//
+// Загруженное определение не добавляется в набор загрузчика,
+// для этого используется LoadDir.
+func (l *DefinitionLoader) LoadFile(path string) (PipelineDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return PipelineDefinition{}, &DefinitionError{File: path, Err: err}
+	}
+
+	return l.Parse(path, data)
+}
+
+// Parse разбирает и проверяет определение; file используется в сообщениях об ошибках
+func (l *DefinitionLoader) Parse(file string, data []byte) (PipelineDefinition, error) {
+	definition, _, err := l.parse(file, data)
+	return definition, err
+}
+
+// LoadDir загружает все определения каталога и заменяет ими текущий набор
+//
+// Если хотя бы один файл невалиден, текущий набор не меняется,
+// а возвращаются ошибки всех невалидных файлов.
+func (l *DefinitionLoader) LoadDir(dir string) error {
+	files, err := definitionFiles(dir)
+	if err != nil {
+		return err
+	}
+
+	definitions := make(map[string]PipelineDefinition, len(files))
+	sources := make(map[string]string, len(files))
+	var errs []error
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			errs = append(errs, &DefinitionError{File: file, Err: err})
+			continue
+		}
+
+		definition, nameLine, err := l.parse(file, data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		if previous, exists := sources[definition.Name]; exists {
+			errs = append(errs, &DefinitionError{
+				File: file,
+				Line: nameLine,
+				Err:  fmt.Errorf("pipeline %s is already defined in %s", definition.Name, previous),
+			})
+			continue
+		}
+
+		definitions[definition.Name] = definition
+		sources[definition.Name] = file
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	l.mu.Lock()
+	l.definitions = definitions
+	l.mu.Unlock()
+
+	l.logger.Info("Loaded pipeline definitions",
+		zap.String("dir", dir),
+		zap.Int("count", len(definitions)))
+	return nil
+}
+
+// Get возвращает загруженное определение по имени
+func (l *DefinitionLoader) Get(name string) (PipelineDefinition, bool) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	definition, exists := l.definitions[name]
+	return definition, exists
+}
+
+// Definitions возвращает все загруженные определения, отсортированные по имени
+func (l *DefinitionLoader) Definitions() []PipelineDefinition {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	definitions := make([]PipelineDefinition, 0, len(l.definitions))
+	for _, definition := range l.definitions {
+		definitions = append(definitions, definition)
+	}
+
+	sort.Slice(definitions, func(i, j int) bool {
+		return definitions[i].Name < definitions[j].Name
+	})
+	return definitions
+}
+
+// Watch перезагружает каталог при изменении файлов, пока не отменен контекст
+//
+// Первый опрос загружает каталог целиком, поэтому изменения, сделанные между
+// LoadDir и запуском Watch, не теряются. Ошибки перезагрузки пишутся в лог.
+func (l *DefinitionLoader) Watch(ctx context.Context, dir string, interval time.Duration) error {
+	var snapshot dirSnapshot
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		current, err := scanDefinitionFiles(dir)
+		if err != nil {
+			l.logger.Error("Failed to scan pipeline definitions", zap.String("dir", dir), zap.Error(err))
+			continue
+		}
+
+		if current.equal(snapshot) {
+			continue
+		}
+		snapshot = current
+
+		if err := l.LoadDir(dir); err != nil {
+			l.logger.Error("Failed to reload pipeline definitions, keeping previous set",
+				zap.String("dir", dir),
+				zap.Error(err))
+		}
+	}
+}
+
+// ValidateDefinition проверяет определение относительно зарегистрированных шагов
+//
+// Помимо PipelineDefinition.Validate проверяется граф зависимостей:
+// все шаги зарегистрированы, зависимости существуют, циклов нет.
+func (e *Engine) ValidateDefinition(definition PipelineDefinition) error {
+	if definition.Name == "" {
+		return errors.New("pipeline name is required")
+	}
+
+	if err := definition.Validate(); err != nil {
+		return err
+	}
+
+	targets := graphTargets(definition)
+	targets = append(targets, sortedKeys(definition.Conditional)...)
+	targets = append(targets, sortedKeys(definition.StepTimeouts)...)
+
+	_, err := e.buildDependencyGraph(targets)
+	return err
+}
+
+// isStepRegistered проверяет, зарегистрирован ли шаг
+func (e *Engine) isStepRegistered(step string) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	_, exists := e.stepRegistry[step]
+	return exists
+}
+
+// 🧾 СХЕМА ФАЙЛА
+//
+// Промежуточные типы запоминают строки узлов YAML, чтобы ошибки
+// валидации указывали на конкретную запись, а не на файл целиком.
+
+// definitionDocument документ с определением пайплайна
+type definitionDocument struct {
+	Name         stringNode                   `yaml:"name"`
+	Description  string                       `yaml:"description"`
+	Mode         stringNode                   `yaml:"mode"`
+	Steps        stepList                     `yaml:"steps"`
+	Parallel     []stepList                   `yaml:"parallel"`
+	Conditional  map[string]conditionDocument `yaml:"conditional"`
+	Timeout      durationNode                 `yaml:"timeout"`
+	StepTimeouts map[string]durationNode      `yaml:"step_timeouts"`
+	Retry        retryDocument                `yaml:"retry"`
+	Metadata     map[string]string            `yaml:"metadata"`
+}
+
+// retryDocument настройки повторов в файле
+type retryDocument struct {
+	MaxAttempts int          `yaml:"max_attempts"`
+	BaseDelay   durationNode `yaml:"base_delay"`
+	MaxDelay    durationNode `yaml:"max_delay"`
+	Multiplier  float64      `yaml:"multiplier"`
+	Jitter      bool         `yaml:"jitter"`
+}
+
+// conditionDocument условие в файле
+type conditionDocument struct {
+	Field    string              `yaml:"field"`
+	Operator string              `yaml:"operator"`
+	Value    interface{}         `yaml:"value"`
+	Logic    string              `yaml:"logic"`
+	Nested   []conditionDocument `yaml:"nested"`
+
+	line int
+}
+
+// conditionKeys допустимые ключи условия
+var conditionKeys = map[string]bool{
+	"field": true, "operator": true, "value": true, "logic": true, "nested": true,
+}
+
+// UnmarshalYAML запоминает строку условия и отклоняет неизвестные ключи
+func (c *conditionDocument) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.MappingNode {
+		return &lineError{line: node.Line, err: errors.New("condition must be a mapping")}
+	}
+
+	// node.Decode не наследует KnownFields декодера, проверяем ключи сами
+	for i := 0; i < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !conditionKeys[key.Value] {
+			return &lineError{line: key.Line, err: fmt.Errorf("unknown condition field %q", key.Value)}
+		}
+	}
+
+	type plain conditionDocument
+	if err := node.Decode((*plain)(c)); err != nil {
+		return err
+	}
+
+	c.line = node.Line
+	return nil
+}
+
+// condition преобразует документ в Condition
+func (c conditionDocument) condition() Condition {
+	condition := Condition{
+		Field:    c.Field,
+		Operator: c.Operator,
+		Value:    c.Value,
+		Logic:    c.Logic,
+	}
+
+	for _, nested := range c.Nested {
+		condition.Nested = append(condition.Nested, nested.condition())
+	}
+	return condition
+}
+
+// validate проверяет условие, указывая строку самого глубокого неверного узла
+func (c conditionDocument) validate() *lineError {
+	for _, nested := range c.Nested {
+		if err := nested.validate(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.condition().Validate(); err != nil {
+		return &lineError{line: c.line, err: err}
+	}
+	return nil
+}
+
+// stringNode строковое значение со строкой в файле
+type stringNode struct {
+	Value string
+	Line  int
+}
+
+// UnmarshalYAML разбирает скалярное значение
+func (n *stringNode) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.ScalarNode {
+		return &lineError{line: node.Line, err: errors.New("expected a string")}
+	}
+
+	n.Value = node.Value
+	n.Line = node.Line
+	return nil
+}
+
+// stepList список шагов со строкой начала списка
+type stepList struct {
+	Steps []stringNode
+	Line  int
+}
+
+// UnmarshalYAML разбирает последовательность названий шагов
+func (l *stepList) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind != yaml.SequenceNode {
+		return &lineError{line: node.Line, err: errors.New("expected a list of steps")}
+	}
+
+	l.Line = node.Line
+	return node.Decode(&l.Steps)
+}
+
+// names возвращает названия шагов
+func (l stepList) names() []string {
+	names := make([]string, len(l.Steps))
+	for i, step := range l.Steps {
+		names[i] = step.Value
+	}
+	return names
+}
+
+// durationNode длительность со строкой в файле
+type durationNode struct {
+	Value time.Duration
+	Line  int
+}
+
+// UnmarshalYAML разбирает длительность в формате time.ParseDuration
+func (n *durationNode) UnmarshalYAML(node *yaml.Node) error {
+	n.Line = node.Line
+
+	if node.Kind != yaml.ScalarNode || node.Tag != "!!str" {
+		return &lineError{line: node.Line, err: fmt.Errorf("invalid duration %q: expected a string like \"30s\"", node.Value)}
+	}
+
+	d, err := time.ParseDuration(node.Value)
+	if err != nil {
+		return &lineError{line: node.Line, err: err}
+	}
+	if d < 0 {
+		return &lineError{line: node.Line, err: fmt.Errorf("negative duration %s", node.Value)}
+	}
+
+	n.Value = d
+	return nil
+}
+
+// lineError ошибка, привязанная к строке файла
+type lineError struct {
+	line int
+	err  error
+}
+
+func (e *lineError) Error() string { return fmt.Sprintf("line %d: %v", e.line, e.err) }
+func (e *lineError) Unwrap() error { return e.err }
+
+// yamlLinePattern номер строки в сообщениях yaml.v3
+var yamlLinePattern = regexp.MustCompile(`line (\d+)`)
+
+// parse разбирает документ и проверяет его по реестру шагов движка
+//
+// Возвращает также строку поля name для сообщений о дубликатах.
+func (l *DefinitionLoader) parse(file string, data []byte) (PipelineDefinition, int, error) {
+	fail := func(line int, err error) (PipelineDefinition, int, error) {
+		return PipelineDefinition{}, 0, &DefinitionError{File: file, Line: line, Err: err}
+	}
+
+	var doc definitionDocument
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&doc); err != nil {
+		var le *lineError
+		if errors.As(err, &le) {
+			return fail(le.line, le.err)
+		}
+		return fail(yamlErrorLine(err), err)
+	}
+
+	if doc.Name.Value == "" {
+		return fail(doc.Name.Line, errors.New("pipeline name is required"))
+	}
+
+	definition := PipelineDefinition{
+		Name:         doc.Name.Value,
+		Description:  doc.Description,
+		Mode:         ExecutionMode(doc.Mode.Value),
+		Steps:        doc.Steps.names(),
+		Timeout:      doc.Timeout.Value,
+		Conditional:  make(map[string]Condition, len(doc.Conditional)),
+		StepTimeouts: make(map[string]time.Duration, len(doc.StepTimeouts)),
+		Retry: RetryConfig{
+			MaxAttempts: doc.Retry.MaxAttempts,
+			BaseDelay:   doc.Retry.BaseDelay.Value,
+			MaxDelay:    doc.Retry.MaxDelay.Value,
+			Multiplier:  doc.Retry.Multiplier,
+			Jitter:      doc.Retry.Jitter,
+		},
+		Metadata: doc.Metadata,
+	}
+
+	switch definition.Mode {
+	case "", ModeSequential, ModeDAG:
+	default:
+		return fail(doc.Mode.Line, fmt.Errorf("unknown execution mode: %s", definition.Mode))
+	}
+
+	// Каждый шаг проверяется отдельно, чтобы указать его строку
+	for _, step := range doc.Steps.Steps {
+		if !l.engine.isStepRegistered(step.Value) {
+			return fail(step.Line, fmt.Errorf("%w: %s", ErrStepNotRegistered, step.Value))
+		}
+	}
+
+	for _, group := range doc.Parallel {
+		for _, step := range group.Steps {
+			if !l.engine.isStepRegistered(step.Value) {
+				return fail(step.Line, fmt.Errorf("%w: %s", ErrStepNotRegistered, step.Value))
+			}
+		}
+		definition.Parallel = append(definition.Parallel, group.names())
+	}
+
+	for _, step := range sortedKeys(doc.Conditional) {
+		condition := doc.Conditional[step]
+		if !l.engine.isStepRegistered(step) {
+			return fail(condition.line, fmt.Errorf("%w: condition for %s", ErrStepNotRegistered, step))
+		}
+		if err := condition.validate(); err != nil {
+			return fail(err.line, fmt.Errorf("condition for step %s: %w", step, err.err))
+		}
+		definition.Conditional[step] = condition.condition()
+	}
+
+	for _, step := range sortedKeys(doc.StepTimeouts) {
+		timeout := doc.StepTimeouts[step]
+		if !l.engine.isStepRegistered(step) {
+			return fail(timeout.Line, fmt.Errorf("%w: timeout for %s", ErrStepNotRegistered, step))
+		}
+		definition.StepTimeouts[step] = timeout.Value
+	}
+
+	// Ошибки графа (недостающие зависимости, циклы) относятся к списку шагов целиком
+	if err := l.engine.ValidateDefinition(definition); err != nil {
+		return fail(doc.Steps.Line, err)
+	}
+
+	return definition, doc.Name.Line, nil
+}
+
+// yamlErrorLine извлекает номер строки из ошибки yaml.v3
+func yamlErrorLine(err error) int {
+	match := yamlLinePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+
+	line, _ := strconv.Atoi(match[1])
+	return line
+}
+
+// sortedKeys возвращает ключи map в детерминированном порядке
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// definitionFiles возвращает файлы определений каталога в алфавитном порядке
+func definitionFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pipeline definitions dir %s: %w", dir, err)
+	}
+
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || !definitionExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+		files = append(files, filepath.Join(dir, entry.Name()))
+	}
+
+	return files, nil
+}
+
+// fileState состояние файла для обнаружения изменений
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+// dirSnapshot состояние файлов определений каталога
+type dirSnapshot map[string]fileState
+
+// scanDefinitionFiles снимает состояние файлов определений каталога
+func scanDefinitionFiles(dir string) (dirSnapshot, error) {
+	files, err := definitionFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := make(dirSnapshot, len(files))
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			// Файл удален между ReadDir и Stat - увидим это при следующем опросе
+			continue
+		}
+		snapshot[file] = fileState{modTime: info.ModTime(), size: info.Size()}
+	}
+
+	return snapshot, nil
+}
+
+// equal сравнивает два снимка каталога
+func (s dirSnapshot) equal(other dirSnapshot) bool {
+	if len(s) != len(other) {
+		return false
+	}
+
+	for file, state := range s {
+		otherState, exists := other[file]
+		if !exists || !otherState.modTime.Equal(state.modTime) || otherState.size != state.size {
+			return false
+		}
+	}
+	return true
+}
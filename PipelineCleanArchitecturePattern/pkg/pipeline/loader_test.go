@@ -0,0 +1,274 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const orderPipelineYAML = `name: order_processing
+description: Обработка заказа
+mode: sequential
+timeout: 10m
+steps:
+  - validate_order
+  - process_payment
+  - check_inventory
+  - send_notifications
+parallel:
+  - [check_inventory, send_notifications]
+conditional:
+  process_payment:
+    logic: or
+    nested:
+      - field: validate_order.total_amount
+        operator: gt
+        value: 0
+      - field: customer_tier
+        operator: in
+        value: [gold, platinum]
+step_timeouts:
+  process_payment: 30s
+retry:
+  max_attempts: 3
+  base_delay: 2s
+  max_delay: 30s
+  multiplier: 2
+  jitter: true
+metadata:
+  owner: order_processing_team
+`
+
+const orderPipelineJSON = `{
+	"name": "order_processing",
+	"description": "Обработка заказа",
+	"mode": "sequential",
+	"timeout": "10m",
+	"steps": ["validate_order", "process_payment", "check_inventory", "send_notifications"],
+	"parallel": [["check_inventory", "send_notifications"]],
+	"conditional": {
+		"process_payment": {
+			"logic": "or",
+			"nested": [
+				{"field": "validate_order.total_amount", "operator": "gt", "value": 0},
+				{"field": "customer_tier", "operator": "in", "value": ["gold", "platinum"]}
+			]
+		}
+	},
+	"step_timeouts": {"process_payment": "30s"},
+	"retry": {"max_attempts": 3, "base_delay": "2s", "max_delay": "30s", "multiplier": 2, "jitter": true},
+	"metadata": {"owner": "order_processing_team"}
+}
+`
+
+func newTestLoader(t *testing.T) *DefinitionLoader {
+	t.Helper()
+
+	engine := newTestEngine(t,
+		&testStep{name: "validate_order"},
+		&testStep{name: "process_payment", deps: []string{"validate_order"}},
+		&testStep{name: "check_inventory"},
+		&testStep{name: "send_notifications"},
+		&testStep{name: "cycle_a", deps: []string{"cycle_b"}},
+		&testStep{name: "cycle_b", deps: []string{"cycle_a"}},
+	)
+	return NewDefinitionLoader(engine, zap.NewNop())
+}
+
+func writeDefinition(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoaderParsesYAMLAndJSON(t *testing.T) {
+	loader := newTestLoader(t)
+
+	fromYAML, err := loader.Parse("order.yaml", []byte(orderPipelineYAML))
+	if err != nil {
+		t.Fatalf("Parse(yaml) error = %v", err)
+	}
+	fromJSON, err := loader.Parse("order.json", []byte(orderPipelineJSON))
+	if err != nil {
+		t.Fatalf("Parse(json) error = %v", err)
+	}
+
+	if !reflect.DeepEqual(fromYAML, fromJSON) {
+		t.Errorf("YAML and JSON definitions differ:\nyaml: %+v\njson: %+v", fromYAML, fromJSON)
+	}
+
+	if fromYAML.Timeout != 10*time.Minute || fromYAML.StepTimeouts["process_payment"] != 30*time.Second {
+		t.Errorf("timeouts = %v / %v, want 10m / 30s", fromYAML.Timeout, fromYAML.StepTimeouts)
+	}
+	if fromYAML.Retry.MaxAttempts != 3 || fromYAML.Retry.BaseDelay != 2*time.Second || !fromYAML.Retry.Jitter {
+		t.Errorf("Retry = %+v", fromYAML.Retry)
+	}
+	if len(fromYAML.Parallel) != 1 || len(fromYAML.Conditional["process_payment"].Nested) != 2 {
+		t.Errorf("Parallel = %v, Conditional = %+v", fromYAML.Parallel, fromYAML.Conditional)
+	}
+}
+
+func TestLoaderErrorsPointToFileAndLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		want    error
+	}{
+		{
+			name:    "unregistered step",
+			content: "name: p\nsteps:\n  - validate_order\n  - charge_card\n",
+			line:    4,
+			want:    ErrStepNotRegistered,
+		},
+		{
+			name:    "unregistered parallel step",
+			content: "name: p\nsteps: [validate_order]\nparallel:\n  - [check_inventory,\n     ship_order]\n",
+			line:    5,
+			want:    ErrStepNotRegistered,
+		},
+		{
+			name: "unknown operator in nested condition",
+			content: "name: p\nsteps: [validate_order, process_payment]\nconditional:\n  process_payment:\n" +
+				"    nested:\n      - field: total\n        operator: gt\n        value: 1\n" +
+				"      - field: tier\n        operator: between\n",
+			line: 9,
+			want: ErrInvalidCondition,
+		},
+		{
+			name:    "unknown condition field",
+			content: "name: p\nsteps: [process_payment]\nconditional:\n  process_payment:\n    field: total\n    op: gt\n",
+			line:    6,
+		},
+		{
+			name:    "invalid duration",
+			content: "name: p\nsteps: [validate_order]\nstep_timeouts:\n  validate_order: 30\n",
+			line:    4,
+		},
+		{
+			name:    "unknown field",
+			content: "name: p\nsteps: [validate_order]\nretries: 3\n",
+			line:    3,
+		},
+		{
+			name:    "dependency cycle",
+			content: "name: p\n\nsteps:\n  - cycle_a\n",
+			line:    4,
+			want:    ErrDependencyCycle,
+		},
+		{
+			name:    "syntax error",
+			content: "name: p\nsteps:\n  - validate_order\n - process_payment\n",
+			line:    3,
+		},
+	}
+
+	loader := newTestLoader(t)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loader.Parse("pipelines/p.yaml", []byte(tt.content))
+
+			var defErr *DefinitionError
+			if !errors.As(err, &defErr) {
+				t.Fatalf("Parse() error = %v, want *DefinitionError", err)
+			}
+			if defErr.File != "pipelines/p.yaml" || defErr.Line != tt.line {
+				t.Errorf("error location = %s:%d, want pipelines/p.yaml:%d (%v)", defErr.File, defErr.Line, tt.line, err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadDirKeepsPreviousSetOnError(t *testing.T) {
+	dir := t.TempDir()
+	writeDefinition(t, dir, "order.yaml", orderPipelineYAML)
+	writeDefinition(t, dir, "README.md", "не определение")
+
+	loader := newTestLoader(t)
+	if err := loader.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if _, ok := loader.Get("order_processing"); !ok {
+		t.Fatal("order_processing is not loaded")
+	}
+
+	// Дубликат имени делает весь набор невалидным
+	duplicate := writeDefinition(t, dir, "order_copy.json", orderPipelineJSON)
+	err := loader.LoadDir(dir)
+	if err == nil || !strings.Contains(err.Error(), duplicate+":2:") {
+		t.Fatalf("LoadDir() error = %v, want duplicate error at %s:2", err, duplicate)
+	}
+	if got := loader.Definitions(); len(got) != 1 {
+		t.Errorf("Definitions() = %d, want previous set of 1", len(got))
+	}
+}
+
+func TestWatchReloadsChangedDefinitions(t *testing.T) {
+	dir := t.TempDir()
+	path := writeDefinition(t, dir, "order.yaml", "name: order_processing\nsteps: [validate_order]\n")
+
+	loader := newTestLoader(t)
+	if err := loader.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- loader.Watch(ctx, dir, 10*time.Millisecond) }()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	waitFor := func(condition func() bool) bool {
+		deadline := time.Now().Add(2 * time.Second)
+		for time.Now().Before(deadline) {
+			if condition() {
+				return true
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		return false
+	}
+
+	writeDefinition(t, dir, "order.yaml", "name: order_processing\nsteps: [validate_order, process_payment]\n")
+	reloaded := waitFor(func() bool {
+		definition, _ := loader.Get("order_processing")
+		return len(definition.Steps) == 2
+	})
+	if !reloaded {
+		t.Fatal("definition was not reloaded after file change")
+	}
+
+	// Невалидное изменение не затирает рабочее определение
+	writeDefinition(t, dir, "order.yaml", "name: order_processing\nsteps: [validate_order, unknown_step, other]\n")
+	writeDefinition(t, dir, "refund.yaml", "name: refund\nsteps: [process_payment]\n")
+	time.Sleep(100 * time.Millisecond)
+	if _, ok := loader.Get("refund"); ok {
+		t.Error("refund was loaded together with an invalid file")
+	}
+	if definition, _ := loader.Get("order_processing"); len(definition.Steps) != 2 {
+		t.Errorf("Steps = %v, want previous valid definition", definition.Steps)
+	}
+
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if !waitFor(func() bool { _, ok := loader.Get("refund"); return ok }) {
+		t.Error("refund was not loaded after invalid file was removed")
+	}
+}
@@ -0,0 +1,196 @@
+package metrics
+
+import (
+	"time"
+
+	"pipeline-clean-architecture/pkg/pipeline"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// 📈 PROMETHEUS СБОРЩИК МЕТРИК ПАЙПЛАЙНОВ
+//
+// ============================================================================
+// МЕТРИКИ (namespace по умолчанию "pipeline"):
+// ============================================================================
+//
+// pipeline_step_duration_seconds{pipeline,step}        - гистограмма попыток шагов
+// pipeline_step_success_total{pipeline,step}           - успешные попытки
+// pipeline_step_errors_total{pipeline,step}            - неуспешные попытки
+// pipeline_steps_in_flight{pipeline,step}              - шаги в работе
+// pipeline_executions_in_flight{pipeline}              - пайплайны в работе
+// pipeline_execution_duration_seconds{pipeline,status} - гистограмма пайплайнов
+// pipeline_queue_depth / pipeline_queue_running         - состояние очереди движка
+// pipeline_queue_rejected_total{pipeline}              - отказы из-за заполненной очереди
+//
+// ИСПОЛЬЗОВАНИЕ:
+//
+//	collector, err := metrics.NewPrometheusCollector("pipeline", prometheus.DefaultRegisterer)
+//	engine.SetMetricsCollector(collector)
+//	http.Handle("/metrics", promhttp.Handler())
+//
+// ============================================================================
+
+// Проверка реализации интерфейсов движка
+var (
+	_ pipeline.MetricsCollector          = (*PrometheusCollector)(nil)
+	_ pipeline.ExecutionMetricsCollector = (*PrometheusCollector)(nil)
+	_ pipeline.QueueMetricsCollector     = (*PrometheusCollector)(nil)
+)
+
+// unknownLabel значение метки, которую не передает базовый MetricsCollector
+const unknownLabel = "unknown"
+
+// PrometheusCollector сборщик метрик пайплайнов для Prometheus
+type PrometheusCollector struct {
+	stepDuration      *prometheus.HistogramVec
+	stepSuccess       *prometheus.CounterVec
+	stepErrors        *prometheus.CounterVec
+	stepsInFlight     *prometheus.GaugeVec
+	pipelinesInFlight *prometheus.GaugeVec
+	pipelineDuration  *prometheus.HistogramVec
+	queueDepth        prometheus.Gauge
+	queueRunning      prometheus.Gauge
+	queueRejected     *prometheus.CounterVec
+}
+
+// NewPrometheusCollector создает сборщик и регистрирует метрики в registerer
+func NewPrometheusCollector(namespace string, registerer prometheus.Registerer) (*PrometheusCollector, error) {
+	if namespace == "" {
+		namespace = "pipeline"
+	}
+
+	c := &PrometheusCollector{
+		stepDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "step_duration_seconds",
+			Help:      "Duration of pipeline step attempts.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"pipeline", "step"}),
+		stepSuccess: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "step_success_total",
+			Help:      "Number of successful pipeline step attempts.",
+		}, []string{"pipeline", "step"}),
+		stepErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "step_errors_total",
+			Help:      "Number of failed pipeline step attempts.",
+		}, []string{"pipeline", "step"}),
+		stepsInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "steps_in_flight",
+			Help:      "Number of pipeline steps currently executing.",
+		}, []string{"pipeline", "step"}),
+		pipelinesInFlight: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "executions_in_flight",
+			Help:      "Number of pipelines currently executing.",
+		}, []string{"pipeline"}),
+		pipelineDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "execution_duration_seconds",
+			Help:      "Duration of pipeline executions by final status.",
+			Buckets:   prometheus.ExponentialBuckets(0.01, 2, 14),
+		}, []string{"pipeline", "status"}),
+		queueDepth: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_depth",
+			Help:      "Number of pipelines waiting in the engine queue.",
+		}),
+		queueRunning: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "queue_running",
+			Help:      "Number of pipelines taken from the queue by workers.",
+		}),
+		queueRejected: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "queue_rejected_total",
+			Help:      "Number of pipelines rejected because the queue was full.",
+		}, []string{"pipeline"}),
+	}
+
+	collectors := []prometheus.Collector{
+		c.stepDuration, c.stepSuccess, c.stepErrors, c.stepsInFlight,
+		c.pipelinesInFlight, c.pipelineDuration,
+		c.queueDepth, c.queueRunning, c.queueRejected,
+	}
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+// 🔌 pipeline.ExecutionMetricsCollector
+
+// RecordStepAttempt записывает попытку шага
+func (c *PrometheusCollector) RecordStepAttempt(pipelineName, step string, duration time.Duration, err error) {
+	c.stepDuration.WithLabelValues(pipelineName, step).Observe(duration.Seconds())
+	if err != nil {
+		c.stepErrors.WithLabelValues(pipelineName, step).Inc()
+		return
+	}
+	c.stepSuccess.WithLabelValues(pipelineName, step).Inc()
+}
+
+// StepStarted увеличивает число шагов в работе
+func (c *PrometheusCollector) StepStarted(pipelineName, step string) {
+	c.stepsInFlight.WithLabelValues(pipelineName, step).Inc()
+}
+
+// StepFinished уменьшает число шагов в работе
+func (c *PrometheusCollector) StepFinished(pipelineName, step string) {
+	c.stepsInFlight.WithLabelValues(pipelineName, step).Dec()
+}
+
+// PipelineStarted увеличивает число пайплайнов в работе
+func (c *PrometheusCollector) PipelineStarted(pipelineName string) {
+	c.pipelinesInFlight.WithLabelValues(pipelineName).Inc()
+}
+
+// PipelineFinished уменьшает число пайплайнов в работе и записывает длительность
+func (c *PrometheusCollector) PipelineFinished(pipelineName string, status pipeline.ExecutionStatus, duration time.Duration) {
+	c.pipelinesInFlight.WithLabelValues(pipelineName).Dec()
+	c.pipelineDuration.WithLabelValues(pipelineName, string(status)).Observe(duration.Seconds())
+}
+
+// 🔌 pipeline.QueueMetricsCollector
+
+// RecordQueueDepth записывает состояние очереди
+func (c *PrometheusCollector) RecordQueueDepth(queued, running int) {
+	c.queueDepth.Set(float64(queued))
+	c.queueRunning.Set(float64(running))
+}
+
+// RecordQueueRejected записывает отказ в постановке в очередь
+func (c *PrometheusCollector) RecordQueueRejected(pipelineName string) {
+	c.queueRejected.WithLabelValues(pipelineName).Inc()
+}
+
+// 🔌 pipeline.MetricsCollector
+//
+// Движок использует ExecutionMetricsCollector, базовые методы нужны для
+// совместимости с кодом, который вызывает сборщик напрямую.
+
+// RecordStepDuration записывает длительность шага без имени пайплайна
+func (c *PrometheusCollector) RecordStepDuration(step string, duration time.Duration) {
+	c.stepDuration.WithLabelValues(unknownLabel, step).Observe(duration.Seconds())
+}
+
+// RecordStepSuccess записывает успех шага без имени пайплайна
+func (c *PrometheusCollector) RecordStepSuccess(step string) {
+	c.stepSuccess.WithLabelValues(unknownLabel, step).Inc()
+}
+
+// RecordStepError записывает ошибку шага без имени пайплайна
+func (c *PrometheusCollector) RecordStepError(step string, err error) {
+	c.stepErrors.WithLabelValues(unknownLabel, step).Inc()
+}
+
+// RecordPipelineDuration записывает длительность пайплайна без статуса
+func (c *PrometheusCollector) RecordPipelineDuration(pipelineName string, duration time.Duration) {
+	c.pipelineDuration.WithLabelValues(pipelineName, unknownLabel).Observe(duration.Seconds())
+}
@@ -0,0 +1,135 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/pkg/pipeline"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap"
+)
+
+// fakeStep шаг с настраиваемым поведением
+type fakeStep struct {
+	name    string
+	execute func(ctx context.Context) error
+}
+
+func (s *fakeStep) Execute(ctx context.Context, data *pipeline.StepData) (*pipeline.StepResult, error) {
+	if err := s.execute(ctx); err != nil {
+		return nil, err
+	}
+	return &pipeline.StepResult{Step: s.name, Success: true}, nil
+}
+
+func (s *fakeStep) Name() string                           { return s.name }
+func (s *fakeStep) Validate(data *pipeline.StepData) error { return nil }
+func (s *fakeStep) Timeout() time.Duration                 { return time.Second }
+func (s *fakeStep) Dependencies() []string                 { return nil }
+func (s *fakeStep) CanRetry(err error) bool                { return true }
+
+func TestPrometheusCollectorRecordsEngineMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector, err := NewPrometheusCollector("", registry)
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+
+	inFlight := make(chan [2]float64, 1)
+	attempts := 0
+
+	engine := pipeline.NewEngine(zap.NewNop(), pipeline.Config{
+		DefaultTimeout: 5 * time.Second,
+		RetryConfig:    pipeline.RetryConfig{MaxAttempts: 2, BaseDelay: time.Millisecond},
+	})
+	engine.SetMetricsCollector(collector)
+
+	steps := []*fakeStep{
+		{name: "validate_order", execute: func(ctx context.Context) error {
+			inFlight <- [2]float64{
+				testutil.ToFloat64(collector.stepsInFlight.WithLabelValues("orders", "validate_order")),
+				testutil.ToFloat64(collector.pipelinesInFlight.WithLabelValues("orders")),
+			}
+			return nil
+		}},
+		{name: "process_payment", execute: func(ctx context.Context) error {
+			attempts++
+			return errors.New("gateway unavailable")
+		}},
+	}
+	for _, step := range steps {
+		if err := engine.RegisterStep(step.name, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	definition := pipeline.PipelineDefinition{Name: "orders", Steps: []string{"validate_order", "process_payment"}}
+	if _, err := engine.Execute(context.Background(), definition, nil); err == nil {
+		t.Fatal("Execute() error = nil, want payment failure")
+	}
+
+	if got := <-inFlight; got != [2]float64{1, 1} {
+		t.Errorf("in-flight during step = %v, want step 1 and pipeline 1", got)
+	}
+
+	checks := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{"validate success", testutil.ToFloat64(collector.stepSuccess.WithLabelValues("orders", "validate_order")), 1},
+		{"payment errors", testutil.ToFloat64(collector.stepErrors.WithLabelValues("orders", "process_payment")), float64(attempts)},
+		{"steps in flight after run", testutil.ToFloat64(collector.stepsInFlight.WithLabelValues("orders", "process_payment")), 0},
+		{"pipelines in flight after run", testutil.ToFloat64(collector.pipelinesInFlight.WithLabelValues("orders")), 0},
+	}
+	for _, check := range checks {
+		if check.got != check.want {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+		}
+	}
+	if attempts != 2 {
+		t.Errorf("payment attempts = %d, want 2", attempts)
+	}
+
+	if count := testutil.CollectAndCount(collector.stepDuration); count != 2 {
+		t.Errorf("step duration series = %d, want 2", count)
+	}
+	if count := testutil.CollectAndCount(collector.pipelineDuration, "pipeline_execution_duration_seconds"); count != 1 {
+		t.Errorf("pipeline duration series = %d, want 1", count)
+	}
+}
+
+func TestPrometheusCollectorRecordsQueueMetrics(t *testing.T) {
+	collector, err := NewPrometheusCollector("orders", prometheus.NewRegistry())
+	if err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+
+	collector.RecordQueueDepth(3, 2)
+	collector.RecordQueueRejected("order_processing")
+
+	if got := testutil.ToFloat64(collector.queueDepth); got != 3 {
+		t.Errorf("queue depth = %v, want 3", got)
+	}
+	if got := testutil.ToFloat64(collector.queueRunning); got != 2 {
+		t.Errorf("queue running = %v, want 2", got)
+	}
+	if got := testutil.ToFloat64(collector.queueRejected.WithLabelValues("order_processing")); got != 1 {
+		t.Errorf("queue rejected = %v, want 1", got)
+	}
+}
+
+func TestNewPrometheusCollectorRejectsDuplicateRegistration(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	if _, err := NewPrometheusCollector("pipeline", registry); err != nil {
+		t.Fatalf("NewPrometheusCollector() error = %v", err)
+	}
+
+	if _, err := NewPrometheusCollector("pipeline", registry); err == nil {
+		t.Error("second NewPrometheusCollector() error = nil, want duplicate registration error")
+	}
+}
@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"time"
+)
+
+// 🔭 МЕТРИКИ И ТРАССИРОВКА
+//
+// ============================================================================
+// РАСШИРЕНИЯ БАЗОВЫХ ИНТЕРФЕЙСОВ:
+// ============================================================================
+//
+// MetricsCollector и Middleware остаются минимальными, а дополнительные
+// возможности подключаются опциональными интерфейсами - движок проверяет
+// их через type assertion:
+//
+// - ExecutionMetricsCollector: метрики шагов с именем пайплайна и
+//   счетчики выполняющихся (in-flight) пайплайнов и шагов
+// - ContextMiddleware: Before возвращает новый контекст (например, со span'ом),
+//   который получают шаг, After и OnError
+// - TracingMiddleware: middleware трассировки, подключается только
+//   при Config.EnableTracing
+//
+// Реализации: pkg/pipeline/metrics (Prometheus), pkg/pipeline/tracing (OpenTelemetry).
+//
+// ============================================================================
+
+// ExecutionMetricsCollector опциональное расширение MetricsCollector
+//
+// Если сборщик реализует этот интерфейс, движок вызывает его методы вместо
+// RecordStepDuration/RecordStepSuccess/RecordStepError/RecordPipelineDuration.
+type ExecutionMetricsCollector interface {
+	// RecordStepAttempt записывает попытку шага (err == nil - успех)
+	RecordStepAttempt(pipeline, step string, duration time.Duration, err error)
+
+	// StepStarted отмечает начало выполнения шага
+	StepStarted(pipeline, step string)
+
+	// StepFinished отмечает завершение шага (после всех повторов)
+	StepFinished(pipeline, step string)
+
+	// PipelineStarted отмечает начало выполнения пайплайна
+	PipelineStarted(pipeline string)
+
+	// PipelineFinished отмечает завершение пайплайна с итоговым статусом
+	PipelineFinished(pipeline string, status ExecutionStatus, duration time.Duration)
+}
+
+// ContextMiddleware опциональное расширение Middleware
+//
+// Движок вызывает BeforeContext вместо Before и передает возвращенный
+// контекст в шаг, а также в After и OnError этого шага.
+type ContextMiddleware interface {
+	Middleware
+
+	// BeforeContext выполняется перед шагом и может дополнить контекст
+	BeforeContext(ctx context.Context, step string, data *StepData) (context.Context, error)
+}
+
+// TracingMiddleware middleware трассировки
+//
+// RegisterMiddleware пропускает его, если Config.EnableTracing выключен.
+type TracingMiddleware interface {
+	Middleware
+
+	// Tracing маркер middleware трассировки
+	Tracing()
+}
+
+// 🏷️ ДАННЫЕ ВЫПОЛНЕНИЯ В КОНТЕКСТЕ
+//
+// Middleware и шаги получают ID выполнения и имя пайплайна из контекста,
+// поскольку StepData.ID уникален для каждого шага.
+
+type contextKey int
+
+const (
+	executionIDKey contextKey = iota
+	pipelineNameKey
+)
+
+// withExecution добавляет в контекст ID выполнения и имя пайплайна
+func withExecution(ctx context.Context, execCtx *ExecutionContext) context.Context {
+	ctx = context.WithValue(ctx, executionIDKey, execCtx.ID)
+	return context.WithValue(ctx, pipelineNameKey, execCtx.Pipeline.Name)
+}
+
+// ExecutionIDFromContext возвращает ID выполнения пайплайна из контекста шага
+func ExecutionIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(executionIDKey).(string)
+	return id
+}
+
+// PipelineNameFromContext возвращает имя пайплайна из контекста шага
+func PipelineNameFromContext(ctx context.Context) string {
+	name, _ := ctx.Value(pipelineNameKey).(string)
+	return name
+}
+
+// 📊 ЗАПИСЬ МЕТРИК
+
+// metricsCollector возвращает текущий сборщик метрик
+func (e *Engine) metricsCollector() MetricsCollector {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.metrics
+}
+
+// recordStepAttempt записывает метрики попытки шага
+func (e *Engine) recordStepAttempt(pipeline, step string, duration time.Duration, err error) {
+	collector := e.metricsCollector()
+	if collector == nil {
+		return
+	}
+
+	if extended, ok := collector.(ExecutionMetricsCollector); ok {
+		extended.RecordStepAttempt(pipeline, step, duration, err)
+		return
+	}
+
+	collector.RecordStepDuration(step, duration)
+	if err == nil {
+		collector.RecordStepSuccess(step)
+	} else {
+		collector.RecordStepError(step, err)
+	}
+}
+
+// stepStarted отмечает шаг в работе
+func (e *Engine) stepStarted(pipeline, step string) {
+	if extended, ok := e.metricsCollector().(ExecutionMetricsCollector); ok {
+		extended.StepStarted(pipeline, step)
+	}
+}
+
+// stepFinished снимает отметку шага в работе
+func (e *Engine) stepFinished(pipeline, step string) {
+	if extended, ok := e.metricsCollector().(ExecutionMetricsCollector); ok {
+		extended.StepFinished(pipeline, step)
+	}
+}
+
+// pipelineStarted отмечает пайплайн в работе
+func (e *Engine) pipelineStarted(pipeline string) {
+	if extended, ok := e.metricsCollector().(ExecutionMetricsCollector); ok {
+		extended.PipelineStarted(pipeline)
+	}
+}
+
+// pipelineFinished записывает итог пайплайна
+func (e *Engine) pipelineFinished(pipeline string, status ExecutionStatus, duration time.Duration) {
+	collector := e.metricsCollector()
+	if collector == nil {
+		return
+	}
+
+	if extended, ok := collector.(ExecutionMetricsCollector); ok {
+		extended.PipelineFinished(pipeline, status, duration)
+		return
+	}
+
+	collector.RecordPipelineDuration(pipeline, duration)
+}
@@ -0,0 +1,456 @@
+package pipeline
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// 🚦 ОЧЕРЕДЬ И ОГРАНИЧЕНИЕ ПАРАЛЛЕЛЬНОСТИ
+//
+// ============================================================================
+// ЗАЧЕМ НУЖНА ОЧЕРЕДЬ:
+// ============================================================================
+//
+// Без ограничения всплеск заказов запускает столько пайплайнов, сколько
+// пришло запросов: платежный шлюз и склад получают неконтролируемую нагрузку.
+//
+// При Config.MaxConcurrentPipelines > 0 движок запускает фиксированный пул
+// воркеров и очередь допуска перед ним:
+//
+//	Submit/Execute → [ очередь (BufferSize) ] → воркеры (MaxConcurrentPipelines)
+//
+// ПРАВИЛА:
+// - Пайплайны с большим приоритетом выходят из очереди раньше,
+//   при равном приоритете сохраняется порядок поступления
+// - Приоритет берется из Config.PipelinePriorities по имени пайплайна
+//   или передается явно через SubmitWithPriority
+// - При заполненной очереди Config.QueuePolicy определяет поведение:
+//   QueuePolicyReject - сразу ErrQueueFull, QueuePolicyWait - ждать места
+// - Execute и Resume проходят через ту же очередь, но ждут результата
+//
+// При MaxConcurrentPipelines == 0 ограничения нет, как и раньше.
+//
+// ============================================================================
+
+// QueuePolicy поведение при заполненной очереди
+type QueuePolicy string
+
+const (
+	// QueuePolicyReject отклонять пайплайн с ErrQueueFull (по умолчанию)
+	QueuePolicyReject QueuePolicy = "reject"
+
+	// QueuePolicyWait ждать освобождения места или отмены контекста
+	QueuePolicyWait QueuePolicy = "wait"
+)
+
+// Ошибки очереди
+var (
+	ErrQueueFull      = errors.New("pipeline queue is full")
+	ErrEngineShutdown = errors.New("pipeline engine is shut down")
+)
+
+// QueueStats состояние очереди и пула воркеров
+type QueueStats struct {
+	// Workers количество воркеров (MaxConcurrentPipelines)
+	Workers int `json:"workers"`
+
+	// Capacity емкость очереди ожидания (BufferSize)
+	Capacity int `json:"capacity"`
+
+	// Queued количество пайплайнов в очереди
+	Queued int `json:"queued"`
+
+	// Running количество выполняющихся пайплайнов
+	Running int `json:"running"`
+
+	// QueuedByPipeline глубина очереди по имени пайплайна
+	QueuedByPipeline map[string]int `json:"queued_by_pipeline"`
+
+	// Rejected количество отклоненных пайплайнов с момента запуска
+	Rejected uint64 `json:"rejected"`
+}
+
+// QueueMetricsCollector опциональное расширение MetricsCollector для метрик очереди
+type QueueMetricsCollector interface {
+	// RecordQueueDepth записывает текущую глубину очереди и число выполняющихся пайплайнов
+	RecordQueueDepth(queued, running int)
+
+	// RecordQueueRejected записывает отклонение пайплайна из-за заполненной очереди
+	RecordQueueRejected(pipeline string)
+}
+
+// 🚀 ПУБЛИЧНЫЙ API
+
+// Submit ставит пайплайн в очередь и сразу возвращает ID выполнения
+//
+// Приоритет берется из Config.PipelinePriorities. Выполнение не зависит
+// от отмены ctx (он используется только для ожидания места в очереди),
+// а ход выполнения доступен через ExecutionStore: до запуска пайплайн
+// сохраняется со статусом StatusPending.
+func (e *Engine) Submit(ctx context.Context, definition PipelineDefinition, initialData map[string]interface{}) (string, error) {
+	return e.SubmitWithPriority(ctx, definition, initialData, e.pipelinePriority(definition.Name))
+}
+
+// SubmitWithPriority ставит пайплайн в очередь с явным приоритетом
+func (e *Engine) SubmitWithPriority(ctx context.Context, definition PipelineDefinition, initialData map[string]interface{}, priority int) (string, error) {
+	execCtx := newExecutionContext(definition, initialData)
+	execCtx.Status = StatusPending
+
+	if err := e.checkpoint(ctx, execCtx); err != nil {
+		return "", err
+	}
+
+	runCtx := context.WithoutCancel(ctx)
+	if e.scheduler == nil {
+		go e.startQueued(runCtx, execCtx)
+		return execCtx.ID, nil
+	}
+
+	if _, err := e.scheduler.admit(ctx, runCtx, execCtx, priority); err != nil {
+		// Фиксируем отказ, чтобы ID не остался навсегда в статусе pending
+		execCtx.Status = StatusCancelled
+		execCtx.Error = err
+		if saveErr := e.checkpoint(ctx, execCtx); saveErr != nil {
+			e.logger.Error("Failed to save rejected execution", zap.Error(saveErr))
+		}
+		return "", err
+	}
+
+	return execCtx.ID, nil
+}
+
+// QueueStats возвращает состояние очереди
+func (e *Engine) QueueStats() QueueStats {
+	if e.scheduler == nil {
+		return QueueStats{QueuedByPipeline: map[string]int{}}
+	}
+
+	e.scheduler.mu.Lock()
+	defer e.scheduler.mu.Unlock()
+
+	return e.scheduler.statsLocked()
+}
+
+// Shutdown прекращает прием пайплайнов и ждет завершения очереди
+//
+// Уже принятые пайплайны выполняются до конца. Если ctx отменен раньше,
+// возвращается ошибка контекста, а воркеры продолжают дорабатывать очередь.
+func (e *Engine) Shutdown(ctx context.Context) error {
+	if e.scheduler == nil {
+		return nil
+	}
+
+	return e.scheduler.shutdown(ctx)
+}
+
+// pipelinePriority возвращает приоритет пайплайна по имени
+func (e *Engine) pipelinePriority(name string) int {
+	return e.config.PipelinePriorities[name]
+}
+
+// dispatch выполняет пайплайн через очередь и ждет результата
+func (e *Engine) dispatch(ctx context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+	if e.scheduler == nil {
+		return e.run(ctx, execCtx)
+	}
+
+	j, err := e.scheduler.admit(ctx, ctx, execCtx, e.pipelinePriority(execCtx.Pipeline.Name))
+	if err != nil {
+		return nil, err
+	}
+
+	select {
+	case result := <-j.done:
+		return result.execCtx, result.err
+	case <-ctx.Done():
+		if e.scheduler.remove(j) {
+			return nil, ctx.Err()
+		}
+		// Воркер уже взял пайплайн, run сам завершится по отмене контекста
+		result := <-j.done
+		return result.execCtx, result.err
+	}
+}
+
+// startQueued запускает принятый через Submit пайплайн
+func (e *Engine) startQueued(ctx context.Context, execCtx *ExecutionContext) (*ExecutionContext, error) {
+	if execCtx.Status == StatusPending {
+		execCtx.Status = StatusRunning
+	}
+	return e.run(ctx, execCtx)
+}
+
+// recordQueueDepth передает глубину очереди в сборщик метрик
+func (e *Engine) recordQueueDepth(stats QueueStats) {
+	if collector, ok := e.metricsCollector().(QueueMetricsCollector); ok {
+		collector.RecordQueueDepth(stats.Queued, stats.Running)
+	}
+}
+
+// recordQueueRejected передает отказ в сборщик метрик
+func (e *Engine) recordQueueRejected(pipeline string) {
+	if collector, ok := e.metricsCollector().(QueueMetricsCollector); ok {
+		collector.RecordQueueRejected(pipeline)
+	}
+}
+
+// 🧵 ПУЛ ВОРКЕРОВ
+
+// queuedJob пайплайн в очереди
+type queuedJob struct {
+	ctx      context.Context
+	execCtx  *ExecutionContext
+	priority int
+	seq      uint64
+	index    int
+	done     chan jobResult
+}
+
+// jobResult результат выполнения пайплайна из очереди
+type jobResult struct {
+	execCtx *ExecutionContext
+	err     error
+}
+
+// jobQueue очередь с приоритетом (container/heap)
+type jobQueue []*queuedJob
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool {
+	if q[i].priority != q[j].priority {
+		return q[i].priority > q[j].priority
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q jobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *jobQueue) Push(x interface{}) {
+	j := x.(*queuedJob)
+	j.index = len(*q)
+	*q = append(*q, j)
+}
+
+func (q *jobQueue) Pop() interface{} {
+	old := *q
+	n := len(old)
+	j := old[n-1]
+	old[n-1] = nil
+	j.index = -1
+	*q = old[:n-1]
+	return j
+}
+
+// scheduler пул воркеров с очередью допуска
+type scheduler struct {
+	engine   *Engine
+	workers  int
+	capacity int
+	policy   QueuePolicy
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	space    chan struct{} // закрывается, когда в очереди освобождается место
+	queue    jobQueue
+	seq      uint64
+	running  int
+	rejected uint64
+	closed   bool
+
+	start sync.Once
+	wg    sync.WaitGroup
+}
+
+// newScheduler создает пул по конфигурации движка
+func newScheduler(engine *Engine, config Config) *scheduler {
+	policy := config.QueuePolicy
+	if policy == "" {
+		policy = QueuePolicyReject
+	}
+
+	s := &scheduler{
+		engine:   engine,
+		workers:  config.MaxConcurrentPipelines,
+		capacity: config.BufferSize,
+		policy:   policy,
+		space:    make(chan struct{}),
+	}
+	s.cond = sync.NewCond(&s.mu)
+	return s
+}
+
+// admit ставит пайплайн в очередь согласно политике
+//
+// waitCtx ограничивает ожидание места, runCtx передается в выполнение.
+func (s *scheduler) admit(waitCtx, runCtx context.Context, execCtx *ExecutionContext, priority int) (*queuedJob, error) {
+	s.start.Do(s.startWorkers)
+
+	s.mu.Lock()
+	for {
+		if s.closed {
+			s.mu.Unlock()
+			return nil, ErrEngineShutdown
+		}
+
+		if s.hasSpaceLocked() {
+			break
+		}
+
+		if s.policy != QueuePolicyWait {
+			s.rejected++
+			s.mu.Unlock()
+
+			s.engine.recordQueueRejected(execCtx.Pipeline.Name)
+			s.engine.logger.Warn("Pipeline rejected, queue is full",
+				zap.String("pipeline", execCtx.Pipeline.Name),
+				zap.String("execution_id", execCtx.ID))
+			return nil, ErrQueueFull
+		}
+
+		space := s.space
+		s.mu.Unlock()
+
+		select {
+		case <-space:
+		case <-waitCtx.Done():
+			return nil, waitCtx.Err()
+		}
+		s.mu.Lock()
+	}
+
+	s.seq++
+	j := &queuedJob{
+		ctx:      runCtx,
+		execCtx:  execCtx,
+		priority: priority,
+		seq:      s.seq,
+		done:     make(chan jobResult, 1),
+	}
+	heap.Push(&s.queue, j)
+	s.cond.Signal()
+	stats := s.statsLocked()
+	s.mu.Unlock()
+
+	s.engine.recordQueueDepth(stats)
+	return j, nil
+}
+
+// hasSpaceLocked есть ли место в очереди
+//
+// Свободные воркеры сразу забирают пайплайны из очереди, поэтому
+// они увеличивают число мест сверх BufferSize.
+func (s *scheduler) hasSpaceLocked() bool {
+	idle := s.workers - s.running
+	return len(s.queue) < s.capacity+idle
+}
+
+// remove удаляет еще не запущенный пайплайн из очереди
+func (s *scheduler) remove(j *queuedJob) bool {
+	s.mu.Lock()
+	if j.index < 0 {
+		s.mu.Unlock()
+		return false
+	}
+
+	heap.Remove(&s.queue, j.index)
+	s.notifySpaceLocked()
+	stats := s.statsLocked()
+	s.mu.Unlock()
+
+	s.engine.recordQueueDepth(stats)
+	return true
+}
+
+// startWorkers запускает воркеры при первом обращении к очереди
+func (s *scheduler) startWorkers() {
+	s.wg.Add(s.workers)
+	for i := 0; i < s.workers; i++ {
+		go s.work()
+	}
+}
+
+// work цикл воркера: забирает пайплайны, пока очередь не закрыта и не пуста
+func (s *scheduler) work() {
+	defer s.wg.Done()
+
+	for {
+		s.mu.Lock()
+		for len(s.queue) == 0 && !s.closed {
+			s.cond.Wait()
+		}
+		if len(s.queue) == 0 {
+			s.mu.Unlock()
+			return
+		}
+
+		j := heap.Pop(&s.queue).(*queuedJob)
+		s.running++
+		stats := s.statsLocked()
+		s.mu.Unlock()
+		s.engine.recordQueueDepth(stats)
+
+		execCtx, err := s.engine.startQueued(j.ctx, j.execCtx)
+		j.done <- jobResult{execCtx: execCtx, err: err}
+
+		s.mu.Lock()
+		s.running--
+		s.notifySpaceLocked()
+		stats = s.statsLocked()
+		s.mu.Unlock()
+		s.engine.recordQueueDepth(stats)
+	}
+}
+
+// notifySpaceLocked будит ожидающих места в очереди
+func (s *scheduler) notifySpaceLocked() {
+	close(s.space)
+	s.space = make(chan struct{})
+}
+
+// shutdown закрывает очередь и ждет воркеров
+func (s *scheduler) shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Broadcast()
+	s.notifySpaceLocked()
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// statsLocked собирает состояние очереди
+func (s *scheduler) statsLocked() QueueStats {
+	byPipeline := make(map[string]int)
+	for _, j := range s.queue {
+		byPipeline[j.execCtx.Pipeline.Name]++
+	}
+
+	return QueueStats{
+		Workers:          s.workers,
+		Capacity:         s.capacity,
+		Queued:           len(s.queue),
+		Running:          s.running,
+		QueuedByPipeline: byPipeline,
+		Rejected:         s.rejected,
+	}
+}
@@ -0,0 +1,353 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func newQueuedEngine(t *testing.T, config Config, steps ...*testStep) *Engine {
+	t.Helper()
+
+	config.DefaultTimeout = 5 * time.Second
+	config.RetryConfig = RetryConfig{MaxAttempts: 1}
+
+	engine := NewEngine(zap.NewNop(), config)
+	for _, step := range steps {
+		if err := engine.RegisterStep(step.name, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := engine.Shutdown(ctx); err != nil {
+			t.Errorf("Shutdown() error = %v", err)
+		}
+	})
+	return engine
+}
+
+// gate блокирует шаги, пока тест не откроет его
+type gate struct {
+	open    chan struct{}
+	entered chan string
+}
+
+func newGate() *gate {
+	return &gate{open: make(chan struct{}), entered: make(chan string, 100)}
+}
+
+func (g *gate) execute(ctx context.Context, data *StepData) error {
+	label, _ := data.Input["label"].(string)
+	g.entered <- label
+	select {
+	case <-g.open:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// queueMetrics сборщик метрик очереди для тестов
+type queueMetrics struct {
+	mu       sync.Mutex
+	maxDepth int
+	rejected []string
+}
+
+func (m *queueMetrics) RecordStepDuration(step string, duration time.Duration)         {}
+func (m *queueMetrics) RecordStepSuccess(step string)                                  {}
+func (m *queueMetrics) RecordStepError(step string, err error)                         {}
+func (m *queueMetrics) RecordPipelineDuration(pipeline string, duration time.Duration) {}
+
+func (m *queueMetrics) RecordQueueDepth(queued, running int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if queued > m.maxDepth {
+		m.maxDepth = queued
+	}
+}
+
+func (m *queueMetrics) RecordQueueRejected(pipeline string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rejected = append(m.rejected, pipeline)
+}
+
+func waitForStats(t *testing.T, engine *Engine, ok func(QueueStats) bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if ok(engine.QueueStats()) {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatalf("queue did not reach expected state: %+v", engine.QueueStats())
+}
+
+func TestExecuteRespectsMaxConcurrentPipelines(t *testing.T) {
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+
+	step := &testStep{name: "work", execute: func(ctx context.Context, data *StepData) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		running--
+		mu.Unlock()
+		return nil
+	}}
+
+	engine := newQueuedEngine(t, Config{MaxConcurrentPipelines: 2, BufferSize: 2, QueuePolicy: QueuePolicyWait}, step)
+	definition := PipelineDefinition{Name: "burst", Steps: []string{"work"}}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := engine.Execute(context.Background(), definition, nil); err != nil {
+				errs <- err
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("Execute() error = %v", err)
+	}
+	if step.Calls() != 10 {
+		t.Errorf("step called %d times, want 10", step.Calls())
+	}
+	if maxRunning > 2 {
+		t.Errorf("max concurrent pipelines = %d, want <= 2", maxRunning)
+	}
+}
+
+func TestSubmitRejectsWhenQueueIsFull(t *testing.T) {
+	g := newGate()
+	step := &testStep{name: "work", execute: g.execute}
+	metrics := &queueMetrics{}
+	store := NewMemoryExecutionStore()
+
+	engine := newQueuedEngine(t, Config{MaxConcurrentPipelines: 1, BufferSize: 1}, step)
+	engine.SetMetricsCollector(metrics)
+	engine.SetExecutionStore(store)
+	definition := PipelineDefinition{Name: "orders", Steps: []string{"work"}}
+
+	runningID, err := engine.Submit(context.Background(), definition, nil)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-g.entered
+
+	queuedID, err := engine.Submit(context.Background(), definition, nil)
+	if err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+
+	if _, err := engine.Submit(context.Background(), definition, nil); !errors.Is(err, ErrQueueFull) {
+		t.Fatalf("Submit() error = %v, want %v", err, ErrQueueFull)
+	}
+
+	stats := engine.QueueStats()
+	if stats.Running != 1 || stats.Queued != 1 || stats.QueuedByPipeline["orders"] != 1 || stats.Rejected != 1 {
+		t.Errorf("QueueStats() = %+v, want 1 running, 1 queued, 1 rejected", stats)
+	}
+
+	queued, err := store.Get(context.Background(), queuedID)
+	if err != nil || queued.Status != StatusPending {
+		t.Errorf("queued execution = %v, %v, want status %s", queued, err, StatusPending)
+	}
+
+	close(g.open)
+	waitForStats(t, engine, func(s QueueStats) bool { return s.Running == 0 && s.Queued == 0 })
+
+	for _, id := range []string{runningID, queuedID} {
+		execCtx, err := store.Get(context.Background(), id)
+		if err != nil || execCtx.Status != StatusCompleted {
+			t.Errorf("execution %s = %v, %v, want status %s", id, execCtx, err, StatusCompleted)
+		}
+	}
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+	if metrics.maxDepth != 1 || len(metrics.rejected) != 1 || metrics.rejected[0] != "orders" {
+		t.Errorf("metrics maxDepth = %d, rejected = %v", metrics.maxDepth, metrics.rejected)
+	}
+}
+
+func TestSubmitWaitPolicyWaitsForSpace(t *testing.T) {
+	g := newGate()
+	step := &testStep{name: "work", execute: g.execute}
+
+	engine := newQueuedEngine(t, Config{MaxConcurrentPipelines: 1, QueuePolicy: QueuePolicyWait}, step)
+	definition := PipelineDefinition{Name: "orders", Steps: []string{"work"}}
+
+	if _, err := engine.Submit(context.Background(), definition, nil); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-g.entered
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	if _, err := engine.Submit(ctx, definition, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Submit() error = %v, want %v", err, context.DeadlineExceeded)
+	}
+
+	admitted := make(chan error, 1)
+	go func() {
+		_, err := engine.Submit(context.Background(), definition, nil)
+		admitted <- err
+	}()
+
+	close(g.open)
+	select {
+	case err := <-admitted:
+		if err != nil {
+			t.Errorf("Submit() error = %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Submit() did not return after space was freed")
+	}
+}
+
+func TestQueueDequeuesByPriority(t *testing.T) {
+	g := newGate()
+	step := &testStep{name: "work", execute: g.execute}
+
+	engine := newQueuedEngine(t, Config{
+		MaxConcurrentPipelines: 1,
+		BufferSize:             10,
+		PipelinePriorities:     map[string]int{"urgent_orders": 3, "bulk_import": -1},
+	}, step)
+
+	submit := func(name, label string, priority *int) {
+		t.Helper()
+		definition := PipelineDefinition{Name: name, Steps: []string{"work"}}
+		data := map[string]interface{}{"label": label}
+
+		var err error
+		if priority != nil {
+			_, err = engine.SubmitWithPriority(context.Background(), definition, data, *priority)
+		} else {
+			_, err = engine.Submit(context.Background(), definition, data)
+		}
+		if err != nil {
+			t.Fatalf("Submit(%s) error = %v", label, err)
+		}
+	}
+
+	submit("orders", "blocker", nil)
+	if got := <-g.entered; got != "blocker" {
+		t.Fatalf("first started = %s, want blocker", got)
+	}
+
+	high := 2
+	submit("bulk_import", "bulk", nil)
+	submit("orders", "normal-1", nil)
+	submit("urgent_orders", "urgent", nil)
+	submit("orders", "high", &high)
+	submit("orders", "normal-2", nil)
+
+	close(g.open)
+
+	want := []string{"urgent", "high", "normal-1", "normal-2", "bulk"}
+	for i, label := range want {
+		select {
+		case got := <-g.entered:
+			if got != label {
+				t.Errorf("started[%d] = %s, want %s", i, got, label)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("pipeline %s did not start", label)
+		}
+	}
+}
+
+func TestExecuteCancelledWhileQueuedLeavesQueue(t *testing.T) {
+	g := newGate()
+	step := &testStep{name: "work", execute: g.execute}
+
+	engine := newQueuedEngine(t, Config{MaxConcurrentPipelines: 1, BufferSize: 1}, step)
+	definition := PipelineDefinition{Name: "orders", Steps: []string{"work"}}
+
+	if _, err := engine.Submit(context.Background(), definition, nil); err != nil {
+		t.Fatalf("Submit() error = %v", err)
+	}
+	<-g.entered
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan error, 1)
+	go func() {
+		_, err := engine.Execute(ctx, definition, nil)
+		result <- err
+	}()
+
+	waitForStats(t, engine, func(s QueueStats) bool { return s.Queued == 1 })
+	cancel()
+
+	if err := <-result; !errors.Is(err, context.Canceled) {
+		t.Fatalf("Execute() error = %v, want %v", err, context.Canceled)
+	}
+	if stats := engine.QueueStats(); stats.Queued != 0 {
+		t.Errorf("Queued = %d, want 0", stats.Queued)
+	}
+
+	close(g.open)
+	waitForStats(t, engine, func(s QueueStats) bool { return s.Running == 0 })
+	if step.Calls() != 1 {
+		t.Errorf("step called %d times, want 1", step.Calls())
+	}
+}
+
+func TestShutdownDrainsQueueAndRejectsNewPipelines(t *testing.T) {
+	step := &testStep{name: "work", execute: func(ctx context.Context, data *StepData) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}}
+
+	engine := NewEngine(zap.NewNop(), Config{
+		MaxConcurrentPipelines: 1,
+		BufferSize:             5,
+		DefaultTimeout:         5 * time.Second,
+		RetryConfig:            RetryConfig{MaxAttempts: 1},
+	})
+	if err := engine.RegisterStep(step.name, step); err != nil {
+		t.Fatal(err)
+	}
+	definition := PipelineDefinition{Name: "orders", Steps: []string{"work"}}
+
+	for i := 0; i < 4; i++ {
+		if _, err := engine.Submit(context.Background(), definition, nil); err != nil {
+			t.Fatalf("Submit() error = %v", err)
+		}
+	}
+
+	if err := engine.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if step.Calls() != 4 {
+		t.Errorf("step called %d times, want all 4 queued pipelines", step.Calls())
+	}
+
+	if _, err := engine.Submit(context.Background(), definition, nil); !errors.Is(err, ErrEngineShutdown) {
+		t.Errorf("Submit() after Shutdown error = %v, want %v", err, ErrEngineShutdown)
+	}
+}
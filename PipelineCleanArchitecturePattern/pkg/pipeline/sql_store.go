@@ -0,0 +1,132 @@
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 🗄️ SQL ХРАНИЛИЩЕ ВЫПОЛНЕНИЙ
+//
+// Работает через database/sql, поэтому драйвер подключает вызывающий код:
+//
+//	import _ "github.com/lib/pq"           // PostgreSQL
+//	import _ "github.com/mattn/go-sqlite3" // SQLite
+//
+// Checkpoint хранится одной строкой на выполнение: служебные колонки для
+// выборок (pipeline, status, current_step) и полный ExecutionContext в JSON.
+
+// SQLDialect диалект SQL для хранилища выполнений
+type SQLDialect string
+
+const (
+	DialectPostgres SQLDialect = "postgres"
+	DialectSQLite   SQLDialect = "sqlite"
+)
+
+// SQLExecutionStore хранилище выполнений в SQL базе данных
+type SQLExecutionStore struct {
+	db      *sql.DB
+	dialect SQLDialect
+	table   string
+}
+
+// NewSQLExecutionStore создает SQL хранилище выполнений
+func NewSQLExecutionStore(db *sql.DB, dialect SQLDialect) (*SQLExecutionStore, error) {
+	switch dialect {
+	case DialectPostgres, DialectSQLite:
+	default:
+		return nil, fmt.Errorf("unsupported SQL dialect: %s", dialect)
+	}
+
+	return &SQLExecutionStore{
+		db:      db,
+		dialect: dialect,
+		table:   "pipeline_executions",
+	}, nil
+}
+
+// Migrate создает таблицу выполнений, если ее еще нет
+func (s *SQLExecutionStore) Migrate(ctx context.Context) error {
+	dataType, timeType := "JSONB", "TIMESTAMPTZ"
+	if s.dialect == DialectSQLite {
+		dataType, timeType = "TEXT", "TIMESTAMP"
+	}
+
+	statements := []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+			id           TEXT PRIMARY KEY,
+			pipeline     TEXT NOT NULL,
+			status       TEXT NOT NULL,
+			current_step TEXT NOT NULL DEFAULT '',
+			data         %s NOT NULL,
+			started_at   %s NOT NULL,
+			updated_at   %s NOT NULL
+		)`, s.table, dataType, timeType, timeType),
+		fmt.Sprintf(`CREATE INDEX IF NOT EXISTS idx_%s_status ON %s (status)`, s.table, s.table),
+	}
+
+	for _, stmt := range statements {
+		if _, err := s.db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("failed to migrate %s: %w", s.table, err)
+		}
+	}
+
+	return nil
+}
+
+// Save сохраняет checkpoint выполнения (upsert по ID)
+func (s *SQLExecutionStore) Save(ctx context.Context, execCtx *ExecutionContext) error {
+	data, err := json.Marshal(execCtx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution %s: %w", execCtx.ID, err)
+	}
+
+	query := fmt.Sprintf(`
+		INSERT INTO %s (id, pipeline, status, current_step, data, started_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			status       = excluded.status,
+			current_step = excluded.current_step,
+			data         = excluded.data,
+			updated_at   = excluded.updated_at`, s.table)
+
+	_, err = s.db.ExecContext(ctx, query,
+		execCtx.ID,
+		execCtx.Pipeline.Name,
+		string(execCtx.Status),
+		execCtx.CurrentStep,
+		string(data),
+		execCtx.StartedAt.UTC(),
+		time.Now().UTC(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save execution %s: %w", execCtx.ID, err)
+	}
+
+	return nil
+}
+
+// Get загружает checkpoint выполнения
+func (s *SQLExecutionStore) Get(ctx context.Context, executionID string) (*ExecutionContext, error) {
+	query := fmt.Sprintf(`SELECT data FROM %s WHERE id = $1`, s.table)
+
+	var data string
+	err := s.db.QueryRowContext(ctx, query, executionID).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s", ErrExecutionNotFound, executionID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get execution %s: %w", executionID, err)
+	}
+
+	var execCtx ExecutionContext
+	if err := json.Unmarshal([]byte(data), &execCtx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal execution %s: %w", executionID, err)
+	}
+
+	return &execCtx, nil
+}
@@ -0,0 +1,165 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// 💾 ХРАНИЛИЩЕ ВЫПОЛНЕНИЙ ПАЙПЛАЙНОВ
+//
+// ============================================================================
+// ЗАЧЕМ НУЖНЫ CHECKPOINTS:
+// ============================================================================
+//
+// Без хранилища ExecutionContext живет только в памяти во время Execute.
+// Если процесс упадет между шагами process_payment и check_inventory,
+// повторный запуск пайплайна спишет деньги с клиента второй раз.
+//
+// С ExecutionStore движок сохраняет checkpoint:
+// - при старте выполнения
+// - после каждого завершенного шага
+// - при завершении пайплайна (успешном или нет)
+//
+// Engine.Resume загружает последний checkpoint, пропускает шаги из
+// CompletedSteps и продолжает выполнение с упавшего шага.
+//
+// ОГРАНИЧЕНИЯ:
+// - Checkpoint сериализуется в JSON, поэтому после Resume числа в GlobalData
+//   и Output становятся float64, а срезы - []interface{}
+// - Ошибки восстанавливаются только как текст (errors.New)
+//
+// ============================================================================
+
+// ErrExecutionNotFound выполнение не найдено в хранилище
+var ErrExecutionNotFound = errors.New("execution not found")
+
+// ExecutionStore интерфейс хранилища состояний выполнения
+type ExecutionStore interface {
+	// Save сохраняет checkpoint выполнения (создает или перезаписывает)
+	Save(ctx context.Context, execCtx *ExecutionContext) error
+
+	// Get загружает последний checkpoint выполнения
+	Get(ctx context.Context, executionID string) (*ExecutionContext, error)
+}
+
+// MemoryExecutionStore хранилище выполнений в памяти процесса
+//
+// Хранит сериализованные копии, поэтому изменения ExecutionContext после
+// Save не влияют на сохраненный checkpoint - так же, как в SQL хранилище.
+type MemoryExecutionStore struct {
+	mu         sync.RWMutex
+	executions map[string][]byte
+}
+
+// NewMemoryExecutionStore создает хранилище выполнений в памяти
+func NewMemoryExecutionStore() *MemoryExecutionStore {
+	return &MemoryExecutionStore{
+		executions: make(map[string][]byte),
+	}
+}
+
+// Save сохраняет checkpoint выполнения
+func (s *MemoryExecutionStore) Save(ctx context.Context, execCtx *ExecutionContext) error {
+	data, err := json.Marshal(execCtx)
+	if err != nil {
+		return fmt.Errorf("failed to marshal execution %s: %w", execCtx.ID, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.executions[execCtx.ID] = data
+	return nil
+}
+
+// Get загружает checkpoint выполнения
+func (s *MemoryExecutionStore) Get(ctx context.Context, executionID string) (*ExecutionContext, error) {
+	s.mu.RLock()
+	data, exists := s.executions[executionID]
+	s.mu.RUnlock()
+
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrExecutionNotFound, executionID)
+	}
+
+	var execCtx ExecutionContext
+	if err := json.Unmarshal(data, &execCtx); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal execution %s: %w", executionID, err)
+	}
+
+	return &execCtx, nil
+}
+
+// 🔄 JSON СЕРИАЛИЗАЦИЯ
+//
+// Поле error не сериализуется encoding/json (получается "{}"),
+// поэтому ошибки сохраняются как текст сообщения.
+
+// MarshalJSON сериализует результат шага, сохраняя текст ошибки
+func (r StepResult) MarshalJSON() ([]byte, error) {
+	type plain StepResult
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain: plain(r), Error: errorMessage(r.Error)})
+}
+
+// UnmarshalJSON восстанавливает результат шага
+func (r *StepResult) UnmarshalJSON(data []byte) error {
+	type plain StepResult
+	aux := struct {
+		*plain
+		Error string `json:"error,omitempty"`
+	}{plain: (*plain)(r)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	r.Error = messageError(aux.Error)
+	return nil
+}
+
+// MarshalJSON сериализует контекст выполнения, сохраняя текст ошибки
+func (c ExecutionContext) MarshalJSON() ([]byte, error) {
+	type plain ExecutionContext
+	return json.Marshal(struct {
+		plain
+		Error string `json:"error,omitempty"`
+	}{plain: plain(c), Error: errorMessage(c.Error)})
+}
+
+// UnmarshalJSON восстанавливает контекст выполнения
+func (c *ExecutionContext) UnmarshalJSON(data []byte) error {
+	type plain ExecutionContext
+	aux := struct {
+		*plain
+		Error string `json:"error,omitempty"`
+	}{plain: (*plain)(c)}
+
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+
+	c.Error = messageError(aux.Error)
+	return nil
+}
+
+// errorMessage возвращает текст ошибки или пустую строку
+func errorMessage(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+// messageError восстанавливает ошибку из текста
+func messageError(message string) error {
+	if message == "" {
+		return nil
+	}
+	return errors.New(message)
+}
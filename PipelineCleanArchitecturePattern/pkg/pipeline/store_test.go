@@ -0,0 +1,107 @@
+package pipeline
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func newSQLiteExecutionStore(t *testing.T) *SQLExecutionStore {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Каждое соединение к :memory: - отдельная база
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	store, err := NewSQLExecutionStore(db, DialectSQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Migrate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestResumeSkipsCompletedSteps(t *testing.T) {
+	stores := map[string]func(t *testing.T) ExecutionStore{
+		"memory": func(t *testing.T) ExecutionStore { return NewMemoryExecutionStore() },
+		"sqlite": func(t *testing.T) ExecutionStore { return newSQLiteExecutionStore(t) },
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			warehouseDown := true
+			payment := &testStep{name: "process_payment",
+				output: map[string]interface{}{"payment_status": "succeeded"}}
+			inventory := &testStep{name: "check_inventory", execute: func(ctx context.Context, data *StepData) error {
+				if warehouseDown {
+					return errors.New("warehouse unavailable")
+				}
+				if data.Input["payment_status"] != "succeeded" {
+					return errors.New("payment output lost after resume")
+				}
+				return nil
+			}}
+
+			engine := newTestEngine(t, payment, inventory)
+			store := newStore(t)
+			engine.SetExecutionStore(store)
+
+			definition := PipelineDefinition{
+				Name:    "order_processing",
+				Steps:   []string{"process_payment", "check_inventory"},
+				Timeout: 2 * time.Second,
+			}
+
+			execCtx, err := engine.Execute(context.Background(), definition, map[string]interface{}{"order_id": "42"})
+			if err == nil {
+				t.Fatal("Execute() error = nil, want inventory failure")
+			}
+
+			saved, err := store.Get(context.Background(), execCtx.ID)
+			if err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+			if saved.Status != StatusFailed || saved.Error == nil {
+				t.Fatalf("saved status = %s, error = %v, want failed with error", saved.Status, saved.Error)
+			}
+
+			warehouseDown = false
+			resumed, err := engine.Resume(context.Background(), execCtx.ID)
+			if err != nil {
+				t.Fatalf("Resume() error = %v", err)
+			}
+
+			if resumed.Status != StatusCompleted {
+				t.Errorf("Status = %s, want %s", resumed.Status, StatusCompleted)
+			}
+			if payment.Calls() != 1 {
+				t.Errorf("process_payment calls = %d, want 1 (customer charged twice)", payment.Calls())
+			}
+			if inventory.Calls() != 2 {
+				t.Errorf("check_inventory calls = %d, want 2", inventory.Calls())
+			}
+			if resumed.GlobalData["order_id"] != "42" {
+				t.Errorf("GlobalData[order_id] = %v, want 42", resumed.GlobalData["order_id"])
+			}
+		})
+	}
+}
+
+func TestResumeUnknownExecution(t *testing.T) {
+	engine := newTestEngine(t)
+	engine.SetExecutionStore(NewMemoryExecutionStore())
+
+	if _, err := engine.Resume(context.Background(), "missing"); !errors.Is(err, ErrExecutionNotFound) {
+		t.Fatalf("Resume() error = %v, want %v", err, ErrExecutionNotFound)
+	}
+}
@@ -0,0 +1,118 @@
+package tracing
+
+import (
+	"context"
+
+	"pipeline-clean-architecture/pkg/pipeline"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// 🛰️ OPENTELEMETRY MIDDLEWARE
+//
+// ============================================================================
+// SPAN НА КАЖДЫЙ ШАГ:
+// ============================================================================
+//
+// BeforeContext открывает span "pipeline.step <шаг>" и кладет его в контекст
+// шага, поэтому вызовы внешних сервисов внутри шага становятся дочерними
+// span'ами. After закрывает span успешного шага, OnError - упавшего.
+//
+// Атрибуты span'а:
+//
+//	pipeline.name          - имя пайплайна
+//	pipeline.execution_id  - ID выполнения (ExecutionContext.ID)
+//	pipeline.step          - имя шага
+//	pipeline.step.success  - итог шага
+//
+// ИСПОЛЬЗОВАНИЕ (работает только при Config.EnableTracing):
+//
+//	engine.RegisterMiddleware(tracing.NewMiddleware(otel.GetTracerProvider()))
+//
+// ============================================================================
+
+// instrumentationName имя инструментирующей библиотеки для трейсера
+const instrumentationName = "pipeline-clean-architecture/pkg/pipeline"
+
+// Атрибуты span'ов шагов
+const (
+	AttrPipelineName = attribute.Key("pipeline.name")
+	AttrExecutionID  = attribute.Key("pipeline.execution_id")
+	AttrStep         = attribute.Key("pipeline.step")
+	AttrStepSuccess  = attribute.Key("pipeline.step.success")
+)
+
+// Проверка реализации интерфейсов движка
+var (
+	_ pipeline.ContextMiddleware = (*Middleware)(nil)
+	_ pipeline.TracingMiddleware = (*Middleware)(nil)
+)
+
+// Middleware middleware трассировки шагов пайплайна
+type Middleware struct {
+	tracer trace.Tracer
+}
+
+// NewMiddleware создает middleware; nil provider - глобальный TracerProvider
+func NewMiddleware(provider trace.TracerProvider) *Middleware {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+
+	return &Middleware{
+		tracer: provider.Tracer(instrumentationName),
+	}
+}
+
+// Tracing отмечает middleware как трассировку (см. pipeline.TracingMiddleware)
+func (m *Middleware) Tracing() {}
+
+// BeforeContext открывает span шага
+func (m *Middleware) BeforeContext(ctx context.Context, step string, data *pipeline.StepData) (context.Context, error) {
+	ctx, _ = m.tracer.Start(ctx, "pipeline.step "+step,
+		trace.WithSpanKind(trace.SpanKindInternal),
+		trace.WithAttributes(
+			AttrPipelineName.String(pipeline.PipelineNameFromContext(ctx)),
+			AttrExecutionID.String(pipeline.ExecutionIDFromContext(ctx)),
+			AttrStep.String(step),
+		))
+	return ctx, nil
+}
+
+// Before не используется: движок вызывает BeforeContext
+func (m *Middleware) Before(ctx context.Context, step string, data *pipeline.StepData) error {
+	return nil
+}
+
+// After закрывает span шага, завершившегося без ошибки
+//
+// Span упавшего шага (result.Error != nil) закрывает OnError.
+func (m *Middleware) After(ctx context.Context, step string, result *pipeline.StepResult) error {
+	span := trace.SpanFromContext(ctx)
+	if result == nil {
+		return nil
+	}
+
+	span.SetAttributes(AttrStepSuccess.Bool(result.Success && result.Error == nil))
+	if result.Error != nil {
+		return nil
+	}
+
+	if !result.Success {
+		span.SetStatus(codes.Error, "step reported failure")
+	}
+	span.End()
+	return nil
+}
+
+// OnError записывает ошибку и закрывает span шага
+func (m *Middleware) OnError(ctx context.Context, step string, err error) error {
+	span := trace.SpanFromContext(ctx)
+	span.RecordError(err)
+	span.SetStatus(codes.Error, err.Error())
+	span.End()
+	return nil
+}
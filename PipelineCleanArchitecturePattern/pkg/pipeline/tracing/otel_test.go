@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pipeline-clean-architecture/pkg/pipeline"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.uber.org/zap"
+)
+
+// fakeStep шаг с настраиваемым поведением
+type fakeStep struct {
+	name    string
+	execute func(ctx context.Context) error
+}
+
+func (s *fakeStep) Execute(ctx context.Context, data *pipeline.StepData) (*pipeline.StepResult, error) {
+	if err := s.execute(ctx); err != nil {
+		return nil, err
+	}
+	return &pipeline.StepResult{Step: s.name, Success: true}, nil
+}
+
+func (s *fakeStep) Name() string                           { return s.name }
+func (s *fakeStep) Validate(data *pipeline.StepData) error { return nil }
+func (s *fakeStep) Timeout() time.Duration                 { return time.Second }
+func (s *fakeStep) Dependencies() []string                 { return nil }
+func (s *fakeStep) CanRetry(err error) bool                { return false }
+
+func newTracedEngine(t *testing.T, enableTracing bool, provider *sdktrace.TracerProvider, steps ...*fakeStep) *pipeline.Engine {
+	t.Helper()
+
+	engine := pipeline.NewEngine(zap.NewNop(), pipeline.Config{
+		DefaultTimeout: 5 * time.Second,
+		EnableTracing:  enableTracing,
+		RetryConfig:    pipeline.RetryConfig{MaxAttempts: 1},
+	})
+	for _, step := range steps {
+		if err := engine.RegisterStep(step.name, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+	engine.RegisterMiddleware(NewMiddleware(provider))
+	return engine
+}
+
+func spanAttribute(span tracetest.SpanStub, key attribute.Key) attribute.Value {
+	for _, kv := range span.Attributes {
+		if kv.Key == key {
+			return kv.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+func TestMiddlewareCreatesSpanPerStep(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	tracer := provider.Tracer("payment-gateway")
+
+	engine := newTracedEngine(t, true, provider,
+		&fakeStep{name: "validate_order", execute: func(ctx context.Context) error {
+			// Вызов внешнего сервиса внутри шага становится дочерним span'ом
+			_, span := tracer.Start(ctx, "db.query")
+			span.End()
+			return nil
+		}},
+		&fakeStep{name: "process_payment", execute: func(ctx context.Context) error {
+			return errors.New("card declined")
+		}},
+	)
+
+	definition := pipeline.PipelineDefinition{Name: "order_processing", Steps: []string{"validate_order", "process_payment"}}
+	execCtx, err := engine.Execute(context.Background(), definition, nil)
+	if err == nil {
+		t.Fatal("Execute() error = nil, want payment failure")
+	}
+
+	spans := exporter.GetSpans()
+	byName := make(map[string]tracetest.SpanStub, len(spans))
+	for _, span := range spans {
+		byName[span.Name] = span
+	}
+	if len(spans) != 3 {
+		t.Fatalf("exported %d spans, want 3: %v", len(spans), byName)
+	}
+
+	validate, payment, query := byName["pipeline.step validate_order"], byName["pipeline.step process_payment"], byName["db.query"]
+
+	for _, span := range []tracetest.SpanStub{validate, payment} {
+		if got := spanAttribute(span, AttrExecutionID).AsString(); got != execCtx.ID {
+			t.Errorf("%s execution_id = %q, want %q", span.Name, got, execCtx.ID)
+		}
+		if got := spanAttribute(span, AttrPipelineName).AsString(); got != "order_processing" {
+			t.Errorf("%s pipeline.name = %q, want order_processing", span.Name, got)
+		}
+	}
+
+	if validate.Status.Code == codes.Error || !spanAttribute(validate, AttrStepSuccess).AsBool() {
+		t.Errorf("validate_order span status = %v, want success", validate.Status)
+	}
+	if payment.Status.Code != codes.Error || payment.Status.Description != "card declined" {
+		t.Errorf("process_payment span status = %+v, want error card declined", payment.Status)
+	}
+	if len(payment.Events) == 0 || payment.Events[0].Name != "exception" {
+		t.Errorf("process_payment span events = %v, want recorded exception", payment.Events)
+	}
+	if query.Parent.SpanID() != validate.SpanContext.SpanID() {
+		t.Error("db.query span is not a child of validate_order step span")
+	}
+}
+
+func TestMiddlewareSkippedWhenTracingDisabled(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+
+	engine := newTracedEngine(t, false, provider,
+		&fakeStep{name: "validate_order", execute: func(ctx context.Context) error { return nil }},
+	)
+
+	definition := pipeline.PipelineDefinition{Name: "order_processing", Steps: []string{"validate_order"}}
+	if _, err := engine.Execute(context.Background(), definition, nil); err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("exported %d spans with tracing disabled, want 0", len(spans))
+	}
+}